@@ -29,14 +29,43 @@ const (
 	// config map.
 	LastConfigMapKey = "foundationdb.org/last-applied-config-map"
 
+	// LastRestartOptionsKey provides the annotation name we use to store the
+	// hash of the process restart options that fdbmonitor used when the
+	// processes in the pod were last restarted.
+	LastRestartOptionsKey = "foundationdb.org/last-applied-restart-options"
+
 	// OutdatedConfigMapKey provides the annotation name we use to store the
 	// timestamp when we saw an outdated config map.
 	OutdatedConfigMapKey = "foundationdb.org/outdated-config-map-seen"
 
+	// CurrentConfigurationAnnotation is an annotation key that the kubernetes
+	// monitor sets to the process configuration it has loaded.
+	CurrentConfigurationAnnotation = "foundationdb.org/launcher-current-configuration"
+
+	// EnvironmentAnnotation is an annotation key that the kubernetes monitor
+	// sets to the environment variables it uses for the process arguments.
+	EnvironmentAnnotation = "foundationdb.org/launcher-environment"
+
 	// BackupDeploymentLabel provides the label we use to connect backup
 	// deployments to a cluster.
 	BackupDeploymentLabel = "foundationdb.org/backup-for"
 
+	// BlobCredentialsHashAnnotation is an annotation key that stores the
+	// hash of the blob credentials on the pods of the backup agents.
+	BlobCredentialsHashAnnotation = "foundationdb.org/blob-credentials-hash"
+
+	// MetricsExporterDeploymentLabel provides the label we use to connect
+	// metrics exporter deployments to a cluster.
+	MetricsExporterDeploymentLabel = "foundationdb.org/metrics-exporter-for"
+
+	// ConsistencyCheckDeploymentLabel provides the label we use to connect
+	// consistency checker deployments to a cluster.
+	ConsistencyCheckDeploymentLabel = "foundationdb.org/consistency-check-for"
+
+	// DRDeploymentLabel provides the label we use to connect DR agent
+	// deployments to a DR replication.
+	DRDeploymentLabel = "foundationdb.org/dr-for"
+
 	// PublicIPSourceAnnotation is an annotation key that specifies where a pod
 	// gets its public IP from.
 	PublicIPSourceAnnotation = "foundationdb.org/public-ip-source"
@@ -45,6 +74,65 @@ const (
 	// IP for a pod.
 	PublicIPAnnotation = "foundationdb.org/public-ip"
 
+	// NodePortAnnotation is an annotation key that specifies the node port
+	// that a pod advertises for its non-TLS address.
+	NodePortAnnotation = "foundationdb.org/node-port"
+
+	// NodePortTLSAnnotation is an annotation key that specifies the node port
+	// that a pod advertises for its TLS address.
+	NodePortTLSAnnotation = "foundationdb.org/node-port-tls"
+
+	// ForceCoordinatorRecoveryAnnotation is an annotation key that allows the
+	// operator to force a new connection string when the coordinator quorum
+	// is lost.
+	ForceCoordinatorRecoveryAnnotation = "foundationdb.org/force-coordinator-recovery"
+
+	// ConfirmDestructiveDeleteAnnotation is an annotation key that allows the
+	// operator to delete a cluster that still has data when data protection
+	// is enabled.
+	ConfirmDestructiveDeleteAnnotation = "foundationdb.org/confirm-destructive-delete"
+
+	// ApprovePodUpdatesAnnotation is an annotation key that approves the pod
+	// updates for the generation of the cluster in the annotation value when
+	// the cluster uses the Manual pod update strategy.
+	ApprovePodUpdatesAnnotation = "foundationdb.org/approve-pod-updates"
+
+	// FdbcliCommandAnnotation is an annotation key that requests the operator
+	// to run the fdbcli command in the annotation value against the cluster.
+	// The operator removes the annotation once the command has run.
+	FdbcliCommandAnnotation = "foundationdb.org/fdbcli-command"
+
+	// ReplaceProcessGroupsAnnotation is an annotation key that requests the
+	// operator to replace the process groups in the comma-separated list in
+	// the annotation value. The operator removes the annotation once the
+	// process groups are marked for removal.
+	ReplaceProcessGroupsAnnotation = "foundationdb.org/replace-process-groups"
+
+	// RestartProcessesAnnotation is an annotation key that requests the
+	// operator to restart the processes of the process groups in the
+	// comma-separated list in the annotation value, or all processes if the
+	// value is RestartAllProcesses. The operator removes the annotation once
+	// the process groups are marked with the RestartRequested condition.
+	RestartProcessesAnnotation = "foundationdb.org/restart-processes"
+
+	// RestartAllProcesses is the value for the RestartProcessesAnnotation that
+	// restarts all processes in the cluster.
+	RestartAllProcesses = "all"
+
+	// ClusterFinalizer is the finalizer the operator adds to clusters that
+	// use graceful deletion.
+	ClusterFinalizer = "foundationdb.org/fdb-cluster"
+
+	// RetainedFromClusterLabel is a label key that the operator adds to the
+	// PVCs and services that it retains when it removes a process group or
+	// deletes a cluster. The value is the name of the cluster.
+	RetainedFromClusterLabel = "foundationdb.org/retained-from-cluster"
+
+	// RetainedProcessGroupIDAnnotation is an annotation key that the operator
+	// adds to retained PVCs and services to record the ID of the process
+	// group they belonged to.
+	RetainedProcessGroupIDAnnotation = "foundationdb.org/retained-process-group-id"
+
 	// FDBProcessGroupIDLabel represents the label that is used to represent a instance ID
 	FDBProcessGroupIDLabel = "foundationdb.org/fdb-process-group-id"
 
@@ -27,6 +27,10 @@ type FoundationDBStatusLocalClientInfo struct {
 type FoundationDBStatusCoordinatorInfo struct {
 	// Coordinators provides a list with coordinator details.
 	Coordinators []FoundationDBStatusCoordinator `json:"coordinators,omitempty"`
+
+	// QuorumReachable indicates whether a majority of the coordinators is
+	// reachable.
+	QuorumReachable bool `json:"quorum_reachable,omitempty"`
 }
 
 // FoundationDBStatusCoordinator contains information about one of the
@@ -67,6 +71,135 @@ type FoundationDBStatusClusterInfo struct {
 	// FaultTolerance provides information about the fault tolerance status
 	// of the cluster.
 	FaultTolerance FaultTolerance `json:"fault_tolerance,omitempty"`
+
+	// Generation provides the current generation of the transaction system.
+	Generation int `json:"generation,omitempty"`
+
+	// Qos provides information about the rate limiting of the cluster.
+	Qos FoundationDBStatusQosInfo `json:"qos,omitempty"`
+
+	// StorageWiggler provides information about the perpetual storage wiggle.
+	StorageWiggler FoundationDBStatusStorageWiggler `json:"storage_wiggler,omitempty"`
+
+	// RecoveryState provides information about the recovery of the
+	// transaction system.
+	RecoveryState FoundationDBStatusRecoveryState `json:"recovery_state,omitempty"`
+
+	// ActivePrimaryDC provides the data center that is currently serving as
+	// the primary. This is only reported by newer versions of FDB.
+	ActivePrimaryDC string `json:"active_primary_dc,omitempty"`
+
+	// Workload provides information about the load on the database.
+	Workload FoundationDBStatusWorkload `json:"workload,omitempty"`
+}
+
+// FoundationDBStatusWorkload provides information about the load on the
+// database.
+type FoundationDBStatusWorkload struct {
+	// Transactions provides the rates of the transactions.
+	Transactions FoundationDBStatusTransactionRates `json:"transactions,omitempty"`
+
+	// Operations provides the rates of the read and write operations.
+	Operations FoundationDBStatusOperationRates `json:"operations,omitempty"`
+}
+
+// FoundationDBStatusTransactionRates provides the rates of the transactions
+// in the database.
+type FoundationDBStatusTransactionRates struct {
+	// Started provides the rate of transactions that were started.
+	Started FoundationDBStatusRate `json:"started,omitempty"`
+
+	// Committed provides the rate of transactions that were committed.
+	Committed FoundationDBStatusRate `json:"committed,omitempty"`
+
+	// Conflicted provides the rate of transactions that failed with a
+	// conflict.
+	Conflicted FoundationDBStatusRate `json:"conflicted,omitempty"`
+}
+
+// FoundationDBStatusOperationRates provides the rates of the read and write
+// operations in the database.
+type FoundationDBStatusOperationRates struct {
+	// Reads provides the rate of read operations.
+	Reads FoundationDBStatusRate `json:"reads,omitempty"`
+
+	// Writes provides the rate of write operations.
+	Writes FoundationDBStatusRate `json:"writes,omitempty"`
+}
+
+// FoundationDBStatusRate provides the rate of an event in the database.
+type FoundationDBStatusRate struct {
+	// Hz provides the number of events per second.
+	Hz float64 `json:"hz,omitempty"`
+}
+
+// FoundationDBStatusRecoveryState provides information about the recovery of
+// the transaction system.
+type FoundationDBStatusRecoveryState struct {
+	// Name provides the name of the recovery state, e.g. fully_recovered.
+	Name string `json:"name,omitempty"`
+
+	// Description provides a description of the recovery state.
+	Description string `json:"description,omitempty"`
+}
+
+// FoundationDBStatusStorageWiggler provides information about the perpetual
+// storage wiggle.
+type FoundationDBStatusStorageWiggler struct {
+	// WiggleServerAddresses provides the addresses of the storage servers
+	// that are currently being wiggled.
+	WiggleServerAddresses []string `json:"wiggle_server_addresses,omitempty"`
+
+	// Primary provides the wiggle metrics for the primary region.
+	Primary FoundationDBStatusStorageWiggleMetrics `json:"primary,omitempty"`
+
+	// Remote provides the wiggle metrics for the remote region.
+	Remote FoundationDBStatusStorageWiggleMetrics `json:"remote,omitempty"`
+}
+
+// FoundationDBStatusStorageWiggleMetrics provides metrics about the progress
+// of the perpetual storage wiggle in a region.
+type FoundationDBStatusStorageWiggleMetrics struct {
+	// FinishedRound provides the number of rounds that have been completed.
+	FinishedRound int `json:"finished_round,omitempty"`
+
+	// FinishedWiggle provides the number of storage servers that have been
+	// wiggled.
+	FinishedWiggle int `json:"finished_wiggle,omitempty"`
+
+	// LastRoundStartTimestamp provides the time when the last round started.
+	LastRoundStartTimestamp float64 `json:"last_round_start_timestamp,omitempty"`
+
+	// LastRoundFinishTimestamp provides the time when the last round finished.
+	LastRoundFinishTimestamp float64 `json:"last_round_finish_timestamp,omitempty"`
+
+	// SmoothedRoundSeconds provides the smoothed duration of a round.
+	SmoothedRoundSeconds float64 `json:"smoothed_round_seconds,omitempty"`
+}
+
+// FoundationDBStatusQosInfo provides information about the rate limiting of
+// the cluster.
+type FoundationDBStatusQosInfo struct {
+	// WorstDataLagStorageServer provides the highest lag of any storage server
+	// behind the transaction logs.
+	WorstDataLagStorageServer FoundationDBStatusLagInfo `json:"worst_data_lag_storage_server,omitempty"`
+
+	// WorstDurabilityLagStorageServer provides the highest lag of any storage
+	// server in making data durable.
+	WorstDurabilityLagStorageServer FoundationDBStatusLagInfo `json:"worst_durability_lag_storage_server,omitempty"`
+
+	// WorstQueueBytesLogServer provides the largest queue of any log server
+	// in bytes.
+	WorstQueueBytesLogServer int64 `json:"worst_queue_bytes_log_server,omitempty"`
+}
+
+// FoundationDBStatusLagInfo provides information about the lag of a process.
+type FoundationDBStatusLagInfo struct {
+	// Seconds provides the lag in seconds.
+	Seconds float64 `json:"seconds,omitempty"`
+
+	// Versions provides the lag in versions.
+	Versions int64 `json:"versions,omitempty"`
 }
 
 // FaultTolerance provides information about the fault tolerance status
@@ -104,6 +237,43 @@ type FoundationDBStatusProcessInfo struct {
 
 	// Roles contains a slice of all roles of the process
 	Roles []FoundationDBStatusProcessRoleInfo `json:"roles,omitempty"`
+
+	// Disk provides information about the disk of the process.
+	Disk FoundationDBStatusProcessDiskInfo `json:"disk,omitempty"`
+
+	// CPU provides information about the CPU usage of the process.
+	CPU FoundationDBStatusProcessCPUInfo `json:"cpu,omitempty"`
+
+	// Messages contains the messages that the process reports, e.g. about
+	// IO errors.
+	Messages []FoundationDBStatusProcessMessage `json:"messages,omitempty"`
+}
+
+// FoundationDBStatusProcessMessage represents a message reported by a
+// process.
+type FoundationDBStatusProcessMessage struct {
+	// Name provides the name of the message, e.g. io_error.
+	Name string `json:"name,omitempty"`
+
+	// Description provides a human-readable description of the message.
+	Description string `json:"description,omitempty"`
+}
+
+// FoundationDBStatusProcessDiskInfo contains information about the disk of a
+// process.
+type FoundationDBStatusProcessDiskInfo struct {
+	// FreeBytes provides the number of bytes that are free on the disk.
+	FreeBytes int64 `json:"free_bytes,omitempty"`
+
+	// TotalBytes provides the size of the disk in bytes.
+	TotalBytes int64 `json:"total_bytes,omitempty"`
+}
+
+// FoundationDBStatusProcessCPUInfo contains information about the CPU usage
+// of a process.
+type FoundationDBStatusProcessCPUInfo struct {
+	// UsageCores provides the number of cores that the process uses.
+	UsageCores float64 `json:"usage_cores,omitempty"`
 }
 
 // FoundationDBStatusProcessRoleInfo contains the minimal information from the process status
@@ -111,6 +281,12 @@ type FoundationDBStatusProcessInfo struct {
 type FoundationDBStatusProcessRoleInfo struct {
 	// Role defines the role a process currently has
 	Role string `json:"role,omitempty"`
+
+	// DataLag provides the data lag of a storage server.
+	DataLag FoundationDBStatusLagInfo `json:"data_lag,omitempty"`
+
+	// DurabilityLag provides the durability lag of a storage server.
+	DurabilityLag FoundationDBStatusLagInfo `json:"durability_lag,omitempty"`
 }
 
 // FoundationDBStatusDataStatistics provides information about the data in
@@ -250,4 +426,18 @@ type ProcessRole string
 const (
 	// ProcessRoleCoordinator model for FDB coordinator role
 	ProcessRoleCoordinator ProcessRole = "coordinator"
+	// ProcessRoleStorage model for FDB storage role
+	ProcessRoleStorage ProcessRole = "storage"
+	// ProcessRoleLog model for FDB log role
+	ProcessRoleLog ProcessRole = "log"
+	// ProcessRoleProxy model for FDB proxy role
+	ProcessRoleProxy ProcessRole = "proxy"
+	// ProcessRoleCommitProxy model for FDB commit_proxy role
+	ProcessRoleCommitProxy ProcessRole = "commit_proxy"
+	// ProcessRoleGrvProxy model for FDB grv_proxy role
+	ProcessRoleGrvProxy ProcessRole = "grv_proxy"
+	// ProcessRoleResolver model for FDB resolver role
+	ProcessRoleResolver ProcessRole = "resolver"
+	// ProcessRoleMaster model for FDB master role
+	ProcessRoleMaster ProcessRole = "master"
 )
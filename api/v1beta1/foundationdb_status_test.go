@@ -62,6 +62,7 @@ var _ = Describe("FoundationDBStatus", func() {
 								Reachable: true,
 							},
 						},
+						QuorumReachable: true,
 					},
 					DatabaseStatus: FoundationDBStatusClientDBStatus{Available: true, Healthy: true},
 				},
@@ -102,8 +103,24 @@ var _ = Describe("FoundationDBStatus", func() {
 								},
 								{
 									Role: "storage",
+									DataLag: FoundationDBStatusLagInfo{
+										Seconds:  0.031546,
+										Versions: 31546,
+									},
+									DurabilityLag: FoundationDBStatusLagInfo{
+										Seconds:  5,
+										Versions: 5000000,
+									},
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0400427,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"f9efa90fc104f4e277b140baf89aab66": {
 							Address: ProcessAddress{
@@ -130,8 +147,24 @@ var _ = Describe("FoundationDBStatus", func() {
 								},
 								{
 									Role: "storage",
+									DataLag: FoundationDBStatusLagInfo{
+										Seconds:  0.031546,
+										Versions: 31546,
+									},
+									DurabilityLag: FoundationDBStatusLagInfo{
+										Seconds:  5.51298,
+										Versions: 5512985,
+									},
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.07871399999999999,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"5a633d7f4e98a6c938c84b97ec4aedbf": {
 							Address: ProcessAddress{
@@ -154,6 +187,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "log",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.022008399999999997,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"5c1b68147a0ef34ce005a38245851270": {
 							Address: ProcessAddress{
@@ -176,6 +217,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "proxy",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0334954,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"653defde43cf1fdef131e2fb82bd192d": {
 							Address: ProcessAddress{
@@ -198,6 +247,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "log",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0204177,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"9c93d3b70118f16c72f7cb3f53e49f4c": {
 							Address: ProcessAddress{
@@ -218,11 +275,27 @@ var _ = Describe("FoundationDBStatus", func() {
 							Roles: []FoundationDBStatusProcessRoleInfo{
 								{
 									Role: "storage",
+									DataLag: FoundationDBStatusLagInfo{
+										Seconds:  0.521547,
+										Versions: 521547,
+									},
+									DurabilityLag: FoundationDBStatusLagInfo{
+										Seconds:  5,
+										Versions: 5000000,
+									},
 								},
 								{
 									Role: "resolver",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0255388,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"b9c25278c0fa207bc2a73bda2300d0a9": {
 							Address: ProcessAddress{
@@ -251,6 +324,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "log",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7177306112,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.030122399999999997,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 					},
 					Data: FoundationDBStatusDataStatistics{
@@ -337,6 +418,24 @@ var _ = Describe("FoundationDBStatus", func() {
 							},
 						},
 					},
+					Generation: 2,
+					RecoveryState: FoundationDBStatusRecoveryState{
+						Name:        "fully_recovered",
+						Description: "Recovery complete.",
+					},
+					Qos: FoundationDBStatusQosInfo{
+						WorstQueueBytesLogServer: 44,
+					},
+					Workload: FoundationDBStatusWorkload{
+						Transactions: FoundationDBStatusTransactionRates{
+							Started:   FoundationDBStatusRate{Hz: 3.39987},
+							Committed: FoundationDBStatusRate{Hz: 0.199992},
+						},
+						Operations: FoundationDBStatusOperationRates{
+							Reads:  FoundationDBStatusRate{Hz: 7.39967},
+							Writes: FoundationDBStatusRate{Hz: 0.399985},
+						},
+					},
 				},
 			}))
 		})
@@ -377,6 +476,7 @@ var _ = Describe("FoundationDBStatus", func() {
 								Reachable: true,
 							},
 						},
+						QuorumReachable: true,
 					},
 					DatabaseStatus: FoundationDBStatusClientDBStatus{Available: true, Healthy: true},
 				},
@@ -415,6 +515,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "log",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0370445,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"c813e585043a7ab55a4905f465c4aa52": {
 							Address: ProcessAddress{
@@ -438,8 +546,24 @@ var _ = Describe("FoundationDBStatus", func() {
 								},
 								{
 									Role: "storage",
+									DataLag: FoundationDBStatusLagInfo{
+										Seconds:  0.46506699999999995,
+										Versions: 465067,
+									},
+									DurabilityLag: FoundationDBStatusLagInfo{
+										Seconds:  5.46507,
+										Versions: 5465067,
+									},
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0494183,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"f9efa90fc104f4e277b140baf89aab66": {
 							Address: ProcessAddress{
@@ -463,8 +587,24 @@ var _ = Describe("FoundationDBStatus", func() {
 								},
 								{
 									Role: "storage",
+									DataLag: FoundationDBStatusLagInfo{
+										Seconds:  0.268138,
+										Versions: 268138,
+									},
+									DurabilityLag: FoundationDBStatusLagInfo{
+										Seconds:  5.26814,
+										Versions: 5268138,
+									},
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0496311,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"5a633d7f4e98a6c938c84b97ec4aedbf": {
 							Address: ProcessAddress{
@@ -490,6 +630,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "log",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0553955,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"5c1b68147a0ef34ce005a38245851270": {
 							Address: ProcessAddress{
@@ -515,6 +663,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "resolver",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0185648,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"653defde43cf1fdef131e2fb82bd192d": {
 							Address: ProcessAddress{
@@ -549,6 +705,14 @@ var _ = Describe("FoundationDBStatus", func() {
 									Role: "log",
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.0932934,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 						"9c93d3b70118f16c72f7cb3f53e49f4c": {
 							Address: ProcessAddress{
@@ -575,8 +739,24 @@ var _ = Describe("FoundationDBStatus", func() {
 								},
 								{
 									Role: "storage",
+									DataLag: FoundationDBStatusLagInfo{
+										Seconds:  0.7441559999999999,
+										Versions: 744156,
+									},
+									DurabilityLag: FoundationDBStatusLagInfo{
+										Seconds:  5,
+										Versions: 5000000,
+									},
 								},
 							},
+							Disk: FoundationDBStatusProcessDiskInfo{
+								FreeBytes:  7176683520,
+								TotalBytes: 8396963840,
+							},
+							CPU: FoundationDBStatusProcessCPUInfo{
+								UsageCores: 0.057441799999999994,
+							},
+							Messages: []FoundationDBStatusProcessMessage{},
 						},
 					},
 					Data: FoundationDBStatusDataStatistics{
@@ -762,6 +942,28 @@ var _ = Describe("FoundationDBStatus", func() {
 							},
 						},
 					},
+					Generation: 62,
+					RecoveryState: FoundationDBStatusRecoveryState{
+						Name:        "fully_recovered",
+						Description: "Recovery complete.",
+					},
+					Qos: FoundationDBStatusQosInfo{
+						WorstDurabilityLagStorageServer: FoundationDBStatusLagInfo{
+							Seconds:  14.1156,
+							Versions: 14115618,
+						},
+						WorstQueueBytesLogServer: 190,
+					},
+					Workload: FoundationDBStatusWorkload{
+						Transactions: FoundationDBStatusTransactionRates{
+							Started:   FoundationDBStatusRate{Hz: 3.39987},
+							Committed: FoundationDBStatusRate{Hz: 0.19999499999999998},
+						},
+						Operations: FoundationDBStatusOperationRates{
+							Reads:  FoundationDBStatusRate{Hz: 10.1989},
+							Writes: FoundationDBStatusRate{Hz: 0.399989},
+						},
+					},
 				},
 			}))
 		})
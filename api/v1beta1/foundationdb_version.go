@@ -159,6 +159,64 @@ func (version FdbVersion) HasNonBlockingExcludes(useNonBlockingExcludes bool) bo
 	return version.IsAtLeast(FdbVersion{Major: 6, Minor: 3, Patch: 5}) && useNonBlockingExcludes
 }
 
+// HasPerpetualStorageWiggle determines if a version has support for the
+// perpetual storage wiggle and the storage migration type in the database
+// configuration.
+func (version FdbVersion) HasPerpetualStorageWiggle() bool {
+	return version.IsAtLeast(FdbVersion{Major: 7, Minor: 0, Patch: 0})
+}
+
+// HasTagThrottling determines if a version has support for throttling
+// transactions by their tags.
+func (version FdbVersion) HasTagThrottling() bool {
+	return version.IsAtLeast(FdbVersion{Major: 6, Minor: 3, Patch: 0})
+}
+
+// HasTagQuotas determines if a version has support for throughput quotas
+// on transaction tags.
+func (version FdbVersion) HasTagQuotas() bool {
+	return version.IsAtLeast(FdbVersion{Major: 7, Minor: 2, Patch: 0})
+}
+
+// HasGrvProxies determines if a version has dedicated GRV proxies, which
+// split the proxy role into commit proxies and GRV proxies.
+func (version FdbVersion) HasGrvProxies() bool {
+	return version.IsAtLeast(FdbVersion{Major: 7, Minor: 0, Patch: 0})
+}
+
+// SupportsLocalityBasedExclusions determines if a version has support for
+// excluding all processes of a locality.
+func (version FdbVersion) SupportsLocalityBasedExclusions() bool {
+	return version.IsAtLeast(FdbVersion{Major: 7, Minor: 0, Patch: 0})
+}
+
+// SupportsConfigurationDatabase determines if a version supports setting
+// knobs through the configuration database.
+func (version FdbVersion) SupportsConfigurationDatabase() bool {
+	return version.IsAtLeast(FdbVersion{Major: 7, Minor: 1, Patch: 0})
+}
+
+// storageEngineVersions defines the first version that supports each storage
+// engine.
+var storageEngineVersions = map[string]FdbVersion{
+	"ssd":                        {Major: 6, Minor: 1, Patch: 0},
+	"ssd-1":                      {Major: 6, Minor: 1, Patch: 0},
+	"ssd-2":                      {Major: 6, Minor: 1, Patch: 0},
+	"memory":                     {Major: 6, Minor: 1, Patch: 0},
+	"memory-1":                   {Major: 6, Minor: 1, Patch: 0},
+	"memory-2":                   {Major: 6, Minor: 1, Patch: 0},
+	"memory-radixtree-beta":      {Major: 6, Minor: 3, Patch: 0},
+	"ssd-redwood-experimental":   {Major: 6, Minor: 3, Patch: 0},
+	"ssd-redwood-1-experimental": {Major: 7, Minor: 0, Patch: 0},
+	"ssd-rocksdb-experimental":   {Major: 7, Minor: 0, Patch: 0},
+}
+
+// SupportsStorageEngine determines if a version supports a storage engine.
+func (version FdbVersion) SupportsStorageEngine(storageEngine string) bool {
+	minimumVersion, ok := storageEngineVersions[storageEngine]
+	return ok && version.IsAtLeast(minimumVersion)
+}
+
 // NextMajorVersion returns the next major version of FoundationDB.
 func (version FdbVersion) NextMajorVersion() FdbVersion {
 	return FdbVersion{Major: version.Major + 1, Minor: 0, Patch: 0}
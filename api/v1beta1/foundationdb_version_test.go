@@ -66,10 +66,28 @@ var _ = Describe("[api] FDBVersion", func() {
 			version := FdbVersion{Major: 6, Minor: 2, Patch: 0}
 			Expect(version.HasInstanceIDInSidecarSubstitutions()).To(BeFalse())
 			Expect(version.PrefersCommandLineArgumentsInSidecar()).To(BeFalse())
+			Expect(version.HasPerpetualStorageWiggle()).To(BeFalse())
+			Expect(version.HasTagThrottling()).To(BeFalse())
+			Expect(version.HasGrvProxies()).To(BeFalse())
+			Expect(version.SupportsLocalityBasedExclusions()).To(BeFalse())
+			Expect(version.SupportsConfigurationDatabase()).To(BeFalse())
 
 			version = FdbVersion{Major: 7, Minor: 0, Patch: 0}
 			Expect(version.HasInstanceIDInSidecarSubstitutions()).To(BeTrue())
 			Expect(version.PrefersCommandLineArgumentsInSidecar()).To(BeTrue())
+			Expect(version.HasPerpetualStorageWiggle()).To(BeTrue())
+			Expect(version.HasTagThrottling()).To(BeTrue())
+			Expect(version.HasGrvProxies()).To(BeTrue())
+			Expect(version.SupportsLocalityBasedExclusions()).To(BeTrue())
+			Expect(version.SupportsConfigurationDatabase()).To(BeFalse())
+			Expect(version.HasTagQuotas()).To(BeFalse())
+
+			version = FdbVersion{Major: 7, Minor: 1, Patch: 0}
+			Expect(version.SupportsConfigurationDatabase()).To(BeTrue())
+			Expect(version.HasTagQuotas()).To(BeFalse())
+
+			version = FdbVersion{Major: 7, Minor: 2, Patch: 0}
+			Expect(version.HasTagQuotas()).To(BeTrue())
 		})
 	})
 
@@ -131,4 +149,17 @@ var _ = Describe("[api] FDBVersion", func() {
 		)
 
 	})
+
+	DescribeTable("checking if the version supports a storage engine",
+		func(version FdbVersion, storageEngine string, expected bool) {
+			Expect(version.SupportsStorageEngine(storageEngine)).To(Equal(expected))
+		},
+		Entry("with the ssd engine", FdbVersion{Major: 6, Minor: 2, Patch: 20}, "ssd-2", true),
+		Entry("with the memory engine", FdbVersion{Major: 6, Minor: 2, Patch: 20}, "memory", true),
+		Entry("with the radix tree engine before 6.3", FdbVersion{Major: 6, Minor: 2, Patch: 20}, "memory-radixtree-beta", false),
+		Entry("with the radix tree engine in 6.3", FdbVersion{Major: 6, Minor: 3, Patch: 0}, "memory-radixtree-beta", true),
+		Entry("with the RocksDB engine in 6.3", FdbVersion{Major: 6, Minor: 3, Patch: 0}, "ssd-rocksdb-experimental", false),
+		Entry("with the RocksDB engine in 7.0", FdbVersion{Major: 7, Minor: 0, Patch: 0}, "ssd-rocksdb-experimental", true),
+		Entry("with an unknown engine", FdbVersion{Major: 7, Minor: 0, Patch: 0}, "random", false),
+	)
 })
@@ -22,6 +22,7 @@ package v1beta1
 
 import (
 	"fmt"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -54,6 +55,7 @@ type FoundationDBBackupList struct {
 // FoundationDBBackupSpec describes the desired state of the backup for a cluster.
 type FoundationDBBackupSpec struct {
 	// The version of FoundationDB that the backup agents should run.
+	// +kubebuilder:validation:Pattern:=^(\d+)\.(\d+)\.(\d+)$
 	Version string `json:"version"`
 
 	// The cluster this backup is for.
@@ -78,12 +80,25 @@ type FoundationDBBackupSpec struct {
 
 	// AgentCount defines the number of backup agents to run.
 	// The default is run 2 agents.
+	// +kubebuilder:validation:Minimum=0
 	AgentCount *int `json:"agentCount,omitempty"`
 
+	// +kubebuilder:validation:Enum=Continuous;Snapshot
+	// BackupMode defines whether the backup continuously backs up new
+	// mutations and takes new snapshots, or whether it stops after it has
+	// taken a single snapshot.
+	// The default is Continuous.
+	BackupMode BackupMode `json:"backupMode,omitempty"`
+
 	// The time window between new snapshots.
 	// This is measured in seconds. The default is 864,000, or 10 days.
+	// +kubebuilder:validation:Minimum=1
 	SnapshotPeriodSeconds *int `json:"snapshotPeriodSeconds,omitempty"`
 
+	// ExpirationPolicy defines how the operator deletes old data from the
+	// backup. If this is not set, the operator does not delete any data.
+	ExpirationPolicy *BackupExpirationPolicy `json:"expirationPolicy,omitempty"`
+
 	// BackupDeploymentMetadata allows customizing labels and annotations on the
 	// deployment for the backup agents.
 	BackupDeploymentMetadata *metav1.ObjectMeta `json:"backupDeploymentMetadata,omitempty"`
@@ -92,6 +107,12 @@ type FoundationDBBackupSpec struct {
 	// agents.
 	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
 
+	// BlobCredentialsSecret defines the name of a secret that contains the
+	// credentials file for the object store in the key "credentials".
+	// The operator mounts this secret into the backup agents, and restarts
+	// the backup agents when the credentials change.
+	BlobCredentialsSecret string `json:"blobCredentialsSecret,omitempty"`
+
 	// CustomParameters defines additional parameters to pass to the backup
 	// agents.
 	CustomParameters []string `json:"customParameters,omitempty"`
@@ -102,6 +123,14 @@ type FoundationDBBackupSpec struct {
 	// with the given version in your custom image.
 	// +kubebuilder:default:=false
 	AllowTagOverride *bool `json:"allowTagOverride,omitempty"`
+
+	// ImageConfigs allows customizing the image that we use for the backup
+	// agents.
+	ImageConfigs []ImageConfig `json:"imageConfigs,omitempty"`
+
+	// SidecarImageConfigs allows customizing the image that we use for the
+	// init container of the backup agents.
+	SidecarImageConfigs []ImageConfig `json:"sidecarImageConfigs,omitempty"`
 }
 
 // FoundationDBBackupStatus describes the current status of the backup for a cluster.
@@ -121,6 +150,10 @@ type FoundationDBBackupStatus struct {
 	// Generations provides information about the latest generation to be
 	// reconciled, or to reach other stages in reconciliation.
 	Generations BackupGenerationStatus `json:"generations,omitempty"`
+
+	// LastExpirationTime provides the last time that the operator expired
+	// old data from the backup.
+	LastExpirationTime *metav1.Time `json:"lastExpirationTime,omitempty"`
 }
 
 // FoundationDBBackupStatusBackupDetails provides information about the state
@@ -129,9 +162,31 @@ type FoundationDBBackupStatusBackupDetails struct {
 	URL                   string `json:"url,omitempty"`
 	Running               bool   `json:"running,omitempty"`
 	Paused                bool   `json:"paused,omitempty"`
+	Completed             bool   `json:"completed,omitempty"`
 	SnapshotPeriodSeconds int    `json:"snapshotTime,omitempty"`
 }
 
+// BackupExpirationPolicy defines how the operator deletes old data from a
+// backup.
+type BackupExpirationPolicy struct {
+	// DeleteBeforeDays defines the age in days after which the operator
+	// deletes the data from the backup.
+	// +kubebuilder:validation:Minimum=1
+	DeleteBeforeDays int `json:"deleteBeforeDays"`
+
+	// MinRestorableDays defines the number of days for which the backup must
+	// stay restorable. The operator will not delete data that is needed to
+	// restore to any point in time in this window, even if the data is older
+	// than DeleteBeforeDays.
+	// +kubebuilder:validation:Minimum=0
+	MinRestorableDays *int `json:"minRestorableDays,omitempty"`
+
+	// IntervalSeconds defines the time between runs of the expiration.
+	// The default is 86,400, or 1 day.
+	// +kubebuilder:validation:Minimum=1
+	IntervalSeconds *int `json:"intervalSeconds,omitempty"`
+}
+
 // BackupGenerationStatus stores information on which generations have reached
 // different stages in reconciliation for the backup.
 type BackupGenerationStatus struct {
@@ -172,11 +227,54 @@ const (
 	BackupStateStopped BackupState = "Stopped"
 )
 
+// BackupMode defines whether a backup runs continuously or only takes a
+// single snapshot.
+type BackupMode string
+
+const (
+	// BackupModeContinuous defines a backup that continuously backs up new
+	// mutations and takes new snapshots.
+	BackupModeContinuous BackupMode = "Continuous"
+	// BackupModeSnapshot defines a backup that stops after it has taken a
+	// single snapshot.
+	BackupModeSnapshot BackupMode = "Snapshot"
+)
+
 // ShouldRun determines whether a backup should be running.
 func (backup *FoundationDBBackup) ShouldRun() bool {
 	return backup.Spec.BackupState == "" || backup.Spec.BackupState == BackupStateRunning || backup.Spec.BackupState == BackupStatePaused
 }
 
+// GetBackupMode returns the mode of the backup.
+// This will fill in a default value if the mode in the spec is empty.
+func (backup *FoundationDBBackup) GetBackupMode() BackupMode {
+	if backup.Spec.BackupMode == "" {
+		return BackupModeContinuous
+	}
+	return backup.Spec.BackupMode
+}
+
+// NeedsStart determines whether the operator needs to start a backup, based
+// on the state of the backup in the status.
+// A backup in the snapshot mode does not need to be started again once its
+// snapshot is complete.
+func (backup *FoundationDBBackup) NeedsStart() bool {
+	if !backup.ShouldRun() {
+		return false
+	}
+
+	details := backup.Status.BackupDetails
+	if details == nil {
+		return true
+	}
+
+	if details.Running {
+		return false
+	}
+
+	return !(backup.GetBackupMode() == BackupModeSnapshot && details.Completed)
+}
+
 // ShouldBePaused determines whether the backups should be paused.
 func (backup *FoundationDBBackup) ShouldBePaused() bool {
 	return backup.Spec.BackupState == BackupStatePaused
@@ -213,6 +311,39 @@ func (backup *FoundationDBBackup) SnapshotPeriodSeconds() int {
 	return 864000
 }
 
+// GetExpirationInterval gets the time between runs of the expiration of old
+// data from the backup. This returns 0 if the backup does not have an
+// expiration policy.
+func (backup *FoundationDBBackup) GetExpirationInterval() time.Duration {
+	if backup.Spec.ExpirationPolicy == nil {
+		return 0
+	}
+
+	if backup.Spec.ExpirationPolicy.IntervalSeconds != nil {
+		return time.Duration(*backup.Spec.ExpirationPolicy.IntervalSeconds) * time.Second
+	}
+
+	return 24 * time.Hour
+}
+
+// GetNextExpirationTime gets the time when the operator should next expire
+// old data from the backup. This returns nil if the backup does not have an
+// expiration policy, and the current time if the operator has never expired
+// data from the backup.
+func (backup *FoundationDBBackup) GetNextExpirationTime() *time.Time {
+	interval := backup.GetExpirationInterval()
+	if interval == 0 {
+		return nil
+	}
+
+	next := time.Now()
+	if backup.Status.LastExpirationTime != nil {
+		next = backup.Status.LastExpirationTime.Add(interval)
+	}
+
+	return &next
+}
+
 // FoundationDBLiveBackupStatus describes the live status of the backup for a
 // cluster, as provided by the backup status command.
 type FoundationDBLiveBackupStatus struct {
@@ -229,11 +360,44 @@ type FoundationDBLiveBackupStatus struct {
 	BackupAgentsPaused bool `json:"BackupAgentsPaused,omitempty"`
 }
 
+// FoundationDBBackupDescription describes the data in a backup, as provided
+// by the backup describe command.
+type FoundationDBBackupDescription struct {
+	// URL provides the URL of the backup.
+	URL string `json:"URL,omitempty"`
+
+	// Restorable determines whether the backup contains enough data to
+	// restore it.
+	Restorable bool `json:"Restorable,omitempty"`
+
+	// MinRestorablePoint provides the earliest version that the backup can
+	// be restored to.
+	MinRestorablePoint *FoundationDBBackupDescriptionVersion `json:"MinRestorablePoint,omitempty"`
+
+	// MaxRestorablePoint provides the latest version that the backup can be
+	// restored to.
+	MaxRestorablePoint *FoundationDBBackupDescriptionVersion `json:"MaxRestorablePoint,omitempty"`
+}
+
+// FoundationDBBackupDescriptionVersion describes a version in the
+// description of a backup.
+type FoundationDBBackupDescriptionVersion struct {
+	// Version provides the version.
+	Version int64 `json:"Version"`
+
+	// EpochSeconds provides the time of the version, if the backup command
+	// could determine it.
+	EpochSeconds *float64 `json:"EpochSeconds,omitempty"`
+}
+
 // FoundationDBLiveBackupStatusState provides the state of a backup in the
 // backup status.
 type FoundationDBLiveBackupStatusState struct {
 	// Running determines whether the backup is currently running.
 	Running bool `json:"Running,omitempty"`
+
+	// Completed determines whether the backup has completed.
+	Completed bool `json:"Completed,omitempty"`
 }
 
 // GetDesiredAgentCount determines how many backup agents we should run
@@ -259,7 +423,7 @@ func (backup *FoundationDBBackup) CheckReconciliation() (bool, error) {
 	isRunning := backup.Status.BackupDetails != nil && backup.Status.BackupDetails.Running
 	isPaused := backup.Status.BackupDetails != nil && backup.Status.BackupDetails.Paused
 
-	if backup.ShouldRun() && !isRunning {
+	if backup.NeedsStart() {
 		backup.Status.Generations.NeedsBackupStart = backup.ObjectMeta.Generation
 		reconciled = false
 	}
@@ -21,6 +21,8 @@
 package v1beta1
 
 import (
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	. "github.com/onsi/ginkgo"
@@ -192,6 +194,28 @@ var _ = Describe("[api] FoundationDBBackup", func() {
 				NeedsBackupReconfiguration: 2,
 			}))
 			backup.Spec.SnapshotPeriodSeconds = nil
+
+			backup = createBackup()
+			backup.Spec.BackupMode = BackupModeSnapshot
+			backup.Status.BackupDetails.Running = false
+			backup.Status.BackupDetails.Completed = true
+			result, err = backup.CheckReconciliation()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeTrue())
+			Expect(backup.Status.Generations).To(Equal(BackupGenerationStatus{
+				Reconciled: 2,
+			}))
+
+			backup = createBackup()
+			backup.Status.BackupDetails.Running = false
+			backup.Status.BackupDetails.Completed = true
+			result, err = backup.CheckReconciliation()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(BeFalse())
+			Expect(backup.Status.Generations).To(Equal(BackupGenerationStatus{
+				Reconciled:       1,
+				NeedsBackupStart: 2,
+			}))
 		})
 
 	})
@@ -254,4 +278,38 @@ var _ = Describe("[api] FoundationDBBackup", func() {
 			Expect(backup.SnapshotPeriodSeconds()).To(Equal(60))
 		})
 	})
+
+	When("getting the next expiration time", func() {
+		It("should not expire the backup without an expiration policy", func() {
+			Expect(backup.GetNextExpirationTime()).To(BeNil())
+		})
+
+		When("the backup has an expiration policy", func() {
+			BeforeEach(func() {
+				backup.Spec.ExpirationPolicy = &BackupExpirationPolicy{DeleteBeforeDays: 30}
+			})
+
+			It("should expire the backup immediately if it was never expired", func() {
+				next := backup.GetNextExpirationTime()
+				Expect(next).NotTo(BeNil())
+				Expect(*next).To(BeTemporally("~", time.Now(), time.Second))
+			})
+
+			It("should expire the backup one day after the last expiration", func() {
+				lastExpiration := metav1.NewTime(time.Date(2021, 3, 22, 10, 0, 0, 0, time.UTC))
+				backup.Status.LastExpirationTime = &lastExpiration
+				next := time.Date(2021, 3, 23, 10, 0, 0, 0, time.UTC)
+				Expect(backup.GetNextExpirationTime()).To(Equal(&next))
+			})
+
+			It("should use the interval from the expiration policy", func() {
+				interval := 3600
+				backup.Spec.ExpirationPolicy.IntervalSeconds = &interval
+				lastExpiration := metav1.NewTime(time.Date(2021, 3, 22, 10, 0, 0, 0, time.UTC))
+				backup.Status.LastExpirationTime = &lastExpiration
+				next := time.Date(2021, 3, 22, 11, 0, 0, 0, time.UTC)
+				Expect(backup.GetNextExpirationTime()).To(Equal(&next))
+			})
+		})
+	})
 })
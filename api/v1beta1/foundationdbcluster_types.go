@@ -17,11 +17,12 @@ limitations under the License.
 package v1beta1
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"html/template"
 	"math"
-	"math/rand"
+	"math/big"
 	"net"
 	"reflect"
 	"regexp"
@@ -34,17 +35,23 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // +kubebuilder:object:root=true
 // +kubebuilder:resource:shortName=fdb
 // +kubebuilder:subresource:status
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".metadata.generation",description="Latest generation of the spec",priority=0
 // +kubebuilder:printcolumn:name="Reconciled",type="integer",JSONPath=".status.generations.reconciled",description="Last reconciled generation of the spec",priority=0
 // +kubebuilder:printcolumn:name="Available",type="boolean",JSONPath=".status.health.available",description="Database available",priority=0
 // +kubebuilder:printcolumn:name="FullReplication",type="boolean",JSONPath=".status.health.fullReplication",description="Database fully replicated",priority=0
+// +kubebuilder:printcolumn:name="Healthy",type="boolean",JSONPath=".status.health.healthy",description="Database healthy",priority=1
 // +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.runningVersion",description="Running version",priority=0
+// +kubebuilder:printcolumn:name="Redundancy",type="string",JSONPath=".status.databaseConfiguration.redundancy_mode",description="Running redundancy mode",priority=0
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".status.desiredProcessGroups",description="Desired process groups",priority=0
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyProcessGroups",description="Ready process groups",priority=0
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // FoundationDBCluster is the Schema for the foundationdbclusters API
@@ -67,18 +74,21 @@ type FoundationDBClusterList struct {
 
 var conditionsThatNeedReplacement = []ProcessGroupConditionType{MissingProcesses, PodFailing}
 
+var undesiredProcessConditions = []ProcessGroupConditionType{ProcessHasIOError, ProcessIsLagging, ProcessHasLowDiskSpace}
+
 func init() {
 	SchemeBuilder.Register(
 		&FoundationDBCluster{}, &FoundationDBClusterList{},
 		&FoundationDBBackup{}, &FoundationDBBackupList{},
 		&FoundationDBRestore{}, &FoundationDBRestoreList{},
+		&FoundationDBDR{}, &FoundationDBDRList{},
 	)
 }
 
 // FoundationDBClusterSpec defines the desired state of a cluster.
 type FoundationDBClusterSpec struct {
 	// Version defines the version of FoundationDB the cluster should run.
-	// +kubebuilder:validation:Pattern:=(\d+)\.(\d+)\.(\d+)
+	// +kubebuilder:validation:Pattern:=^(\d+)\.(\d+)\.(\d+)$
 	Version string `json:"version"`
 
 	// SidecarVersions defines the build version of the sidecar to run. This
@@ -92,6 +102,12 @@ type FoundationDBClusterSpec struct {
 	// Processes defines process-level settings.
 	Processes map[ProcessClass]ProcessSettings `json:"processes,omitempty"`
 
+	// ProcessGroupOverrides defines settings for the pods of individual
+	// process groups, keyed by the process group ID. These settings take
+	// precedence over the settings of the process class, so a single pod can
+	// be pinned to a node or resized without changing the whole class.
+	ProcessGroupOverrides map[string]ProcessGroupOverride `json:"processGroupOverrides,omitempty"`
+
 	// ProcessCounts defines the number of processes to configure for each
 	// process class. You can generally omit this, to allow the operator to
 	// infer the process counts based on the database configuration.
@@ -101,6 +117,7 @@ type FoundationDBClusterSpec struct {
 	// reconciliation.
 	//
 	// After the initial reconciliation, this will not be used.
+	// +kubebuilder:validation:Pattern:=`^[A-Za-z0-9_]+:[A-Za-z0-9]+@([0-9A-Za-z.-]+|\[[0-9A-Fa-f:.]+\]):[0-9]+(:tls)?(,([0-9A-Za-z.-]+|\[[0-9A-Fa-f:.]+\]):[0-9]+(:tls)?)*$`
 	SeedConnectionString string `json:"seedConnectionString,omitempty"`
 
 	// PartialConnectionString provides a way to specify part of the
@@ -168,6 +185,7 @@ type FoundationDBClusterSpec struct {
 
 	// UpdatePodsByReplacement determines whether we should update pod config
 	// by replacing the pods rather than deleting them.
+	// Deprecated: Use the PodUpdateStrategy field instead.
 	UpdatePodsByReplacement bool `json:"updatePodsByReplacement,omitempty"`
 
 	// LockOptions allows customizing how we manage locks for global operations.
@@ -290,8 +308,18 @@ type FoundationDBClusterSpec struct {
 	// in one Pod whereas the ProcessCounts defines the number of Pods created.
 	// This means that you end up with ProcessCounts["storage"] * StorageServersPerPod
 	// storage processes
+	// +kubebuilder:validation:Minimum=0
 	StorageServersPerPod int `json:"storageServersPerPod,omitempty"`
 
+	// LogServersPerPod defines how many Log Servers should run in
+	// a single Instance (Pod). This applies to processes with the log and
+	// transaction class. Every process in the Pod gets its own port and data
+	// directory on the same disk.
+	// This means that you end up with ProcessCounts["log"] * LogServersPerPod
+	// log processes
+	// +kubebuilder:validation:Minimum=0
+	LogServersPerPod int `json:"logServersPerPod,omitempty"`
+
 	// MinimumUptimeSecondsForBounce defines the minimum time, in seconds, that the
 	// processes in the cluster must have been up for before the operator can
 	// execute a bounce.
@@ -299,6 +327,25 @@ type FoundationDBClusterSpec struct {
 	// +kubebuilder:default:=600
 	MinimumUptimeSecondsForBounce int `json:"minimumUptimeSecondsForBounce,omitempty"`
 
+	// MaximumTotalClientsForBounce defines the maximum number of clients
+	// that can be connected to the database when the operator executes a
+	// bounce. This counts every connected client, whether or not it has
+	// transactions in flight, since the database status does not report the
+	// transactions of each client. If more clients are connected, the
+	// operator waits for them to disconnect, up to the
+	// MaximumClientWaitSecondsForBounce. By default the operator does not
+	// check the connected clients.
+	// +kubebuilder:validation:Minimum=0
+	MaximumTotalClientsForBounce *int `json:"maximumTotalClientsForBounce,omitempty"`
+
+	// MaximumClientWaitSecondsForBounce defines the maximum time, in seconds,
+	// that the operator waits for clients to disconnect before it executes a
+	// bounce anyway. The time is measured from when the processes were first
+	// seen with an incorrect command line.
+	// The default is 600 seconds, or 10 minutes.
+	// +kubebuilder:validation:Minimum=0
+	MaximumClientWaitSecondsForBounce *int `json:"maximumClientWaitSecondsForBounce,omitempty"`
+
 	// ReplaceInstancesWhenResourcesChange defines if an instance should be replaced
 	// when the resource requirements are increased. This can be useful with the combination of
 	// local storage.
@@ -306,10 +353,20 @@ type FoundationDBClusterSpec struct {
 	ReplaceInstancesWhenResourcesChange *bool `json:"replaceInstancesWhenResourcesChange,omitempty"`
 
 	// Skip defines if the cluster should be skipped for reconciliation. This can be useful for
-	// investigating in issues or if the environment is unstable.
+	// investigating in issues or if the environment is unstable. The operator
+	// will still update the status of a skipped cluster.
 	// +kubebuilder:default:=false
 	Skip bool `json:"skip,omitempty"`
 
+	// ObserveOnly defines that the operator does not manage the cluster. The
+	// operator connects to an existing database through the
+	// SeedConnectionString and only updates the status of the cluster,
+	// without managing any resources or changing the database. This can be
+	// used to monitor existing clusters before migrating them. A cluster that
+	// was observed cannot be switched to being managed by the operator.
+	// +kubebuilder:default:=false
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+
 	// CoordinatorSelection defines which process classes are eligible for coordinator selection.
 	// If empty all stateful processes classes are equally eligible.
 	// A higher priority means that a process class is preferred over another process class.
@@ -318,12 +375,127 @@ type FoundationDBClusterSpec struct {
 	// the coordinator selection process could conflict.
 	CoordinatorSelection []CoordinatorSelectionSetting `json:"coordinatorSelection,omitempty"`
 
+	// CoordinatorCount defines the number of coordinators the operator should
+	// recruit. This can only be used to increase the number of coordinators,
+	// values below the number required for the redundancy mode will be
+	// ignored.
+	// +kubebuilder:validation:Minimum=1
+	CoordinatorCount *int `json:"coordinatorCount,omitempty"`
+
 	// LabelConfig allows customizing labels used by the operator.
 	LabelConfig LabelConfig `json:"labels,omitempty"`
 
 	// UseExplicitListenAddress determines if we should add a listen address
 	// that is separate from the public address.
 	UseExplicitListenAddress *bool `json:"useExplicitListenAddress,omitempty"`
+
+	// StatusSummaryIntervalSeconds defines how often the operator publishes a
+	// summary of the database status into the `<cluster>-status` ConfigMap.
+	// If this is not set, the summary will not be published.
+	// +kubebuilder:validation:Minimum=1
+	StatusSummaryIntervalSeconds *int `json:"statusSummaryIntervalSeconds,omitempty"`
+
+	// MetricsExporter allows configuring a deployment that exports metrics
+	// for the cluster in the Prometheus format.
+	MetricsExporter MetricsExporterConfig `json:"metricsExporter,omitempty"`
+
+	// TraceLogs allows configuring how the FoundationDB processes write
+	// their trace logs, and how those logs are shipped.
+	TraceLogs TraceLogConfig `json:"traceLogs,omitempty"`
+
+	// DeletionOptions defines how the operator tears down the cluster when
+	// the cluster resource is deleted.
+	DeletionOptions DeletionOptions `json:"deletionOptions,omitempty"`
+
+	// ImageType defines the type of images that the pods use.
+	//
+	// With the `split` type the main container runs fdbmonitor and a sidecar
+	// container provides the configuration. With the `unified` type the main
+	// container runs fdbserver through the kubernetes monitor, which reads
+	// the process arguments from the ConfigMap.
+	// The default is `split`.
+	// +kubebuilder:validation:Enum=split;unified
+	ImageType *ImageType `json:"imageType,omitempty"`
+
+	// PodUpdateStrategy defines how the operator applies changes to the pod
+	// spec.
+	//
+	// With the `Recreate` strategy the operator deletes the pods zone by zone
+	// and recreates them with the new spec. With the `Replacement` strategy
+	// the operator replaces the process groups with new process groups, and
+	// excludes the old processes before removing them. With the `Manual`
+	// strategy the operator recreates the pods like the `Recreate` strategy,
+	// but only once the update has been approved through the
+	// foundationdb.org/approve-pod-updates annotation.
+	// The default is `Recreate`.
+	// +kubebuilder:validation:Enum=Recreate;Replacement;Manual
+	PodUpdateStrategy *PodUpdateStrategy `json:"podUpdateStrategy,omitempty"`
+
+	// ClientConfig defines the config map and secret that the operator
+	// publishes for client applications.
+	ClientConfig ClientConfig `json:"clientConfig,omitempty"`
+
+	// TagThrottles defines the throttles that the operator should apply to
+	// transactions with specific tags. This is only supported on FDB 6.3
+	// and later.
+	TagThrottles []TagThrottle `json:"tagThrottles,omitempty"`
+
+	// TagQuotas defines the throughput quotas that the operator should set
+	// for transactions with specific tags. This is only supported on FDB 7.2
+	// and later.
+	TagQuotas []TagQuota `json:"tagQuotas,omitempty"`
+
+	// DatabaseKnobs defines the server knobs that the operator should set
+	// in the configuration database, in the form of knob name to value.
+	// The knob names must not have a knob_ prefix and may only contain
+	// lowercase letters, digits and underscores. The values must not contain
+	// whitespace, quotes or semicolons. This is only supported on FDB 7.1
+	// and later.
+	DatabaseKnobs map[string]string `json:"databaseKnobs,omitempty"`
+
+	// ConsistencyCheck defines when the operator lets the consistency
+	// checker verify the data in the database.
+	ConsistencyCheck ConsistencyCheckConfig `json:"consistencyCheck,omitempty"`
+
+	// AuditLog defines whether the operator records the actions it takes
+	// on the cluster in an audit log.
+	AuditLog AuditLogConfig `json:"auditLog,omitempty"`
+
+	// BinaryChecksums defines the expected SHA-256 checksums of the binaries
+	// that the sidecar copies into the shared volume, in the form of version
+	// to binary name to the hex-encoded checksum. When checksums are defined
+	// for the version the cluster is running, the operator verifies them
+	// before it considers a pod ready to be bounced.
+	BinaryChecksums map[string]BinaryChecksums `json:"binaryChecksums,omitempty"`
+
+	// ProcessRestartOptions defines how fdbmonitor restarts the fdbserver
+	// processes when they exit.
+	ProcessRestartOptions ProcessRestartOptions `json:"processRestartOptions,omitempty"`
+
+	// SeedBackup defines a backup that the operator restores into the
+	// cluster once the database is configured. This allows creating a new
+	// cluster as a copy of an existing cluster, e.g. for a staging
+	// environment.
+	//
+	// This must be set when the cluster is created. Once the operator has
+	// started the restore, this will not be used.
+	SeedBackup *SeedBackup `json:"seedBackup,omitempty"`
+
+	// ExcludedLocalities defines localities whose processes the operator
+	// excludes from the database, e.g. to evacuate a zone before a planned
+	// maintenance. The processes are included again once their locality is
+	// removed from this list.
+	//
+	// This requires FoundationDB 7.0 or newer.
+	ExcludedLocalities []LocalityExclusion `json:"excludedLocalities,omitempty"`
+
+	// PrimaryDataCenter defines the data center that should serve as the
+	// primary in a multi-region configuration. When this is set, the
+	// operator gives the region of this data center the highest priority in
+	// the database configuration, and waits until the database has failed
+	// over to it. This must be set to the same value in the clusters for all
+	// data centers of the database.
+	PrimaryDataCenter string `json:"primaryDataCenter,omitempty"`
 }
 
 // FoundationDBClusterStatus defines the observed state of FoundationDBCluster
@@ -397,6 +569,11 @@ type FoundationDBClusterStatus struct {
 	// Configured defines whether we have configured the database yet.
 	Configured bool `json:"configured,omitempty"`
 
+	// ObservedOnly defines whether the operator has only observed the
+	// database because ObserveOnly was set. The operator does not take over
+	// the management of a database that it has only observed.
+	ObservedOnly bool `json:"observedOnly,omitempty"`
+
 	// HasListenIPsForAllPods defines whether every pod has an environment
 	// variable for its listen address.
 	HasListenIPsForAllPods bool `json:"hasListenIPsForAllPods,omitempty"`
@@ -415,12 +592,162 @@ type FoundationDBClusterStatus struct {
 	// If there are more than one value in the slice the reconcile phase is not finished.
 	StorageServersPerDisk []int `json:"storageServersPerDisk,omitempty"`
 
+	// LogServersPerDisk defines the logServersPerPod observed in the cluster.
+	// If there are more than one value in the slice the reconcile phase is not finished.
+	LogServersPerDisk []int `json:"logServersPerDisk,omitempty"`
+
 	// ProcessGroups contain information about a process group.
 	// This information is used in multiple places to trigger the according action.
 	ProcessGroups []*ProcessGroupStatus `json:"processGroups,omitempty"`
 
+	// DesiredProcessGroups provides the number of process groups that the
+	// cluster should have, based on the process counts in the spec.
+	DesiredProcessGroups int `json:"desiredProcessGroups,omitempty"`
+
+	// ReadyProcessGroups provides the number of process groups that are not
+	// marked for removal and have no conditions.
+	ReadyProcessGroups int `json:"readyProcessGroups,omitempty"`
+
+	// OutdatedProcessGroups provides the number of process groups that are
+	// not marked for removal and are running with an outdated configuration,
+	// pod spec or command line, and still need to be updated or bounced.
+	OutdatedProcessGroups int `json:"outdatedProcessGroups,omitempty"`
+
+	// OutdatedProcessGroupIDs provides the IDs of the process groups that are
+	// counted in OutdatedProcessGroups.
+	OutdatedProcessGroupIDs []string `json:"outdatedProcessGroupIDs,omitempty"`
+
+	// PendingExclusion provides the exclusion that the operator has started
+	// but not yet confirmed, so it can be resumed after an operator restart.
+	PendingExclusion *PendingProcessOperation `json:"pendingExclusion,omitempty"`
+
 	// Locks contains information about the locking system.
 	Locks LockSystemStatus `json:"locks,omitempty"`
+
+	// StorageWiggle provides information about the progress of the perpetual
+	// storage wiggle. This is only set while the wiggle is enabled.
+	StorageWiggle *StorageWiggleStatus `json:"storageWiggle,omitempty"`
+
+	// RecommendedRoleCounts provides the role counts that the operator
+	// recommends based on the load of the proxies, resolvers and logs. This
+	// is only set while role count recommendations are enabled.
+	RecommendedRoleCounts *RoleCounts `json:"recommendedRoleCounts,omitempty"`
+
+	// ManagedTagThrottles provides the tags that the operator has throttled
+	// based on the TagThrottles in the cluster spec.
+	ManagedTagThrottles []string `json:"managedTagThrottles,omitempty"`
+
+	// ManagedTagQuotas provides the tags that the operator has set quotas
+	// for based on the TagQuotas in the cluster spec.
+	ManagedTagQuotas []string `json:"managedTagQuotas,omitempty"`
+
+	// ManagedDatabaseKnobs provides the knobs that the operator has set in
+	// the configuration database based on the DatabaseKnobs in the cluster
+	// spec.
+	ManagedDatabaseKnobs []string `json:"managedDatabaseKnobs,omitempty"`
+
+	// ConsistencyCheck provides information about the consistency check
+	// windows that the operator has scheduled. This is only set while the
+	// operator manages the consistency check.
+	ConsistencyCheck *ConsistencyCheckStatus `json:"consistencyCheck,omitempty"`
+
+	// SeedRestore provides the name of the restore that the operator created
+	// for the seed backup.
+	SeedRestore string `json:"seedRestore,omitempty"`
+
+	// SeedRestorePending indicates that the database was configured with a
+	// seed backup, and the operator has not created the restore for it yet.
+	SeedRestorePending bool `json:"seedRestorePending,omitempty"`
+
+	// LocalityExclusions provides the progress of evacuating the processes
+	// for the localities that the operator has excluded.
+	LocalityExclusions []LocalityExclusionStatus `json:"localityExclusions,omitempty"`
+
+	// ActivePrimaryDataCenter provides the data center that is currently
+	// serving as the primary in a multi-region configuration.
+	ActivePrimaryDataCenter string `json:"activePrimaryDataCenter,omitempty"`
+
+	// ProcessPorts provides the base port and port stride that the processes
+	// of the cluster use. The operator rejects changes to these settings in
+	// the routing config, since they would move the processes away from the
+	// addresses in the connection string.
+	ProcessPorts *ProcessPortsStatus `json:"processPorts,omitempty"`
+
+	// ReconciliationProgress provides how far the operator got in the last
+	// reconciliation of the cluster, so that the progress of a
+	// reconciliation that has not completed yet is visible.
+	ReconciliationProgress *ReconciliationProgress `json:"reconciliationProgress,omitempty"`
+
+	// Conditions provides the conditions of the cluster, following the
+	// Kubernetes API conventions. The condition types are Available,
+	// FullyReconciled, ReplacingInstances, UpgradeInProgress,
+	// ConfigurationPending, FaultTolerance, QuotaExceeded,
+	// ReconciliationStalled and DatabaseUnavailable.
+	// +listType=map
+	// +listMapKey=type
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	Conditions []metav1.Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+const (
+	// ClusterConditionAvailable indicates whether the database is available.
+	ClusterConditionAvailable = "Available"
+
+	// ClusterConditionFullyReconciled indicates whether the latest
+	// generation of the spec has been reconciled.
+	ClusterConditionFullyReconciled = "FullyReconciled"
+
+	// ClusterConditionReplacingInstances indicates whether process groups
+	// are marked for removal, e.g. because they are being replaced.
+	ClusterConditionReplacingInstances = "ReplacingInstances"
+
+	// ClusterConditionUpgradeInProgress indicates whether the running
+	// version of the database differs from the version in the spec.
+	ClusterConditionUpgradeInProgress = "UpgradeInProgress"
+
+	// ClusterConditionConfigurationPending indicates whether the database
+	// configuration differs from the configuration in the spec.
+	ClusterConditionConfigurationPending = "ConfigurationPending"
+
+	// ClusterConditionFaultTolerance indicates whether the cluster can lose
+	// another fault domain without losing data or availability. While it is
+	// false, the operator does not bounce processes.
+	ClusterConditionFaultTolerance = "FaultTolerance"
+
+	// ClusterConditionQuotaExceeded indicates whether the operator failed to
+	// create pods or PVCs because they would exceed a resource quota or
+	// violate a limit range in the namespace.
+	ClusterConditionQuotaExceeded = "QuotaExceeded"
+
+	// ClusterConditionReconciliationStalled indicates whether the cluster has
+	// failed to reconcile the latest generation for longer than the
+	// reconciliation stalled timeout.
+	ClusterConditionReconciliationStalled = "ReconciliationStalled"
+
+	// ClusterConditionDatabaseUnavailable indicates whether the operator is
+	// skipping changes to the database because the database is unavailable.
+	ClusterConditionDatabaseUnavailable = "DatabaseUnavailable"
+)
+
+// StorageWiggleStatus provides information about the progress of the
+// perpetual storage wiggle in the primary region.
+type StorageWiggleStatus struct {
+	// FinishedRounds provides the number of times the wiggle has replaced
+	// every storage server.
+	FinishedRounds int `json:"finishedRounds,omitempty"`
+
+	// FinishedWiggles provides the number of storage servers the wiggle has
+	// replaced.
+	FinishedWiggles int `json:"finishedWiggles,omitempty"`
+
+	// LastRoundFinishTimestamp provides the time when the wiggle last replaced
+	// every storage server, as a Unix timestamp.
+	LastRoundFinishTimestamp int64 `json:"lastRoundFinishTimestamp,omitempty"`
+
+	// WigglingAddresses provides the addresses of the storage servers that
+	// are currently being replaced.
+	WigglingAddresses []string `json:"wigglingAddresses,omitempty"`
 }
 
 // LockSystemStatus provides a summary of the status of the locking system.
@@ -430,6 +757,38 @@ type LockSystemStatus struct {
 	DenyList []string `json:"lockDenyList,omitempty"`
 }
 
+// PendingProcessOperation describes a command that the operator has started
+// for a set of processes.
+type PendingProcessOperation struct {
+	// Addresses provides the addresses of the processes that the command was
+	// issued for.
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Timestamp provides the time when the operator recorded the command, in
+	// seconds since the epoch.
+	Timestamp int64 `json:"timestamp,omitempty"`
+}
+
+// NewPendingProcessOperation records a command for a set of processes that
+// is started now.
+func NewPendingProcessOperation(addresses []ProcessAddress) *PendingProcessOperation {
+	operation := &PendingProcessOperation{
+		Addresses: make([]string, 0, len(addresses)),
+		Timestamp: time.Now().Unix(),
+	}
+
+	for _, address := range addresses {
+		operation.Addresses = append(operation.Addresses, address.String())
+	}
+
+	return operation
+}
+
+// GetProcessAddresses parses the addresses of the processes.
+func (operation *PendingProcessOperation) GetProcessAddresses() ([]ProcessAddress, error) {
+	return ParseProcessAddresses(operation.Addresses)
+}
+
 // ProcessGroupStatus represents a the status of a ProcessGroup.
 type ProcessGroupStatus struct {
 	// ProcessGroupID represents the ID of the process group
@@ -438,6 +797,9 @@ type ProcessGroupStatus struct {
 	ProcessClass ProcessClass `json:"processClass,omitempty"`
 	// Addresses represents the list of addresses the process group has been known to have.
 	Addresses []string `json:"addresses,omitempty"`
+	// FaultDomain represents the last zone that the processes of the process group have reported.
+	// This is kept when the processes stop reporting, e.g. because the pod is terminating.
+	FaultDomain string `json:"faultDomain,omitempty"`
 	// Remove defines if the process group is marked for removal.
 	Remove bool `json:"remove,omitempty"`
 	// Excluded defines if the process group has been fully excluded.
@@ -445,14 +807,46 @@ type ProcessGroupStatus struct {
 	Excluded bool `json:"excluded,omitempty"`
 	// ExclusionSkipped determines if exclusion has been skipped for a process, which will allow the process group to be removed without exclusion.
 	ExclusionSkipped bool `json:"exclusionSkipped,omitempty"`
+	// ExclusionTimestamp defines when the operator started excluding the processes of the process group.
+	// This is reset when a stuck exclusion is rolled back.
+	ExclusionTimestamp int64 `json:"exclusionTimestamp,omitempty"`
 	// ProcessGroupConditions represents a list of degraded conditions that the process group is in.
 	ProcessGroupConditions []*ProcessGroupCondition `json:"processGroupConditions,omitempty"`
 }
 
-// NeedsReplacement checks if the ProcessGroupStatus has conditions so that it should be removed
+// NeedsReplacement checks if the ProcessGroupStatus has conditions so that it should be removed.
+// Process groups with the NodeTaintReplacing condition are replaced without waiting for the
+// failure time, since the condition is only set once the taint has been present for the
+// configured duration.
 func (processGroupStatus *ProcessGroupStatus) NeedsReplacement(failureTime int) (bool, int64) {
+	if processGroupStatus.Remove {
+		return false, 0
+	}
+
+	taintTime := processGroupStatus.GetConditionTime(NodeTaintReplacing)
+	if taintTime != nil {
+		return true, *taintTime
+	}
+
+	return processGroupStatus.hasConditionsForLongerThan(conditionsThatNeedReplacement, failureTime)
+}
+
+// IsUndesired checks if the ProcessGroupStatus has had one of the
+// undesired process conditions, e.g. ProcessHasIOError, for longer than the
+// failure time.
+func (processGroupStatus *ProcessGroupStatus) IsUndesired(failureTime int) (bool, int64) {
+	if processGroupStatus.Remove {
+		return false, 0
+	}
+
+	return processGroupStatus.hasConditionsForLongerThan(undesiredProcessConditions, failureTime)
+}
+
+// hasConditionsForLongerThan checks if any of the conditions has been present
+// for longer than the failure time and returns the earliest condition time.
+func (processGroupStatus *ProcessGroupStatus) hasConditionsForLongerThan(conditions []ProcessGroupConditionType, failureTime int) (bool, int64) {
 	var missingTime *int64
-	for _, condition := range conditionsThatNeedReplacement {
+	for _, condition := range conditions {
 		conditionTime := processGroupStatus.GetConditionTime(condition)
 		if conditionTime != nil && (missingTime == nil || *missingTime > *conditionTime) {
 			missingTime = conditionTime
@@ -527,6 +921,12 @@ func (processGroupStatus *ProcessGroupStatus) IsExcluded(remainingMap map[string
 	return true, nil
 }
 
+// WasExcluded determines whether the processes of the process group were
+// excluded from the database, as opposed to being removed without exclusion.
+func (processGroupStatus *ProcessGroupStatus) WasExcluded() bool {
+	return !processGroupStatus.ExclusionSkipped && processGroupStatus.ExclusionTimestamp != 0
+}
+
 // NewProcessGroupStatus returns a new GroupStatus for the given processGroupID and processClass.
 func NewProcessGroupStatus(processGroupID string, processClass ProcessClass, addresses []string) *ProcessGroupStatus {
 	return &ProcessGroupStatus{
@@ -765,6 +1165,37 @@ const (
 	SidecarUnreachable ProcessGroupConditionType = "SidecarUnreachable"
 	// PodPending represents a process group where the pod is in a pending state.
 	PodPending ProcessGroupConditionType = "PodPending"
+	// ExclusionStuck represents a process group whose exclusion has not
+	// completed within the exclusion timeout.
+	ExclusionStuck ProcessGroupConditionType = "ExclusionStuck"
+	// PodCrashLooping represents a process group where a container of the pod
+	// is in CrashLoopBackOff.
+	PodCrashLooping ProcessGroupConditionType = "PodCrashLooping"
+	// PodUnschedulable represents a process group where the pod cannot be
+	// scheduled, e.g. because its volume is bound to a different zone.
+	PodUnschedulable ProcessGroupConditionType = "PodUnschedulable"
+	// PodTerminating represents a process group that is not marked for
+	// removal where the pod is in terminating.
+	PodTerminating ProcessGroupConditionType = "PodTerminating"
+	// NodeTaintDetected represents a process group where the node of the pod
+	// has a taint that is defined in the taint replacement options.
+	NodeTaintDetected ProcessGroupConditionType = "NodeTaintDetected"
+	// NodeTaintReplacing represents a process group where the node of the
+	// pod has had a taint from the taint replacement options for longer
+	// than the configured duration, so the process group should be replaced.
+	NodeTaintReplacing ProcessGroupConditionType = "NodeTaintReplacing"
+	// ProcessHasIOError represents a process group where a process reports
+	// IO errors in the database status.
+	ProcessHasIOError ProcessGroupConditionType = "ProcessHasIOError"
+	// ProcessIsLagging represents a process group where a storage server has
+	// a durability lag above the configured threshold.
+	ProcessIsLagging ProcessGroupConditionType = "ProcessIsLagging"
+	// ProcessHasLowDiskSpace represents a process group where a process has
+	// less free disk space than the configured threshold.
+	ProcessHasLowDiskSpace ProcessGroupConditionType = "ProcessHasLowDiskSpace"
+	// RestartRequested represents a process group whose processes should be
+	// restarted because of the RestartProcessesAnnotation.
+	RestartRequested ProcessGroupConditionType = "RestartRequested"
 	// ReadyCondition is currently only used in the metrics.
 	ReadyCondition ProcessGroupConditionType = "Ready"
 )
@@ -782,6 +1213,16 @@ func AllProcessGroupConditionTypes() []ProcessGroupConditionType {
 		MissingProcesses,
 		SidecarUnreachable,
 		PodPending,
+		ExclusionStuck,
+		PodCrashLooping,
+		PodUnschedulable,
+		PodTerminating,
+		NodeTaintDetected,
+		NodeTaintReplacing,
+		ProcessHasIOError,
+		ProcessIsLagging,
+		ProcessHasLowDiskSpace,
+		RestartRequested,
 		ReadyCondition,
 	}
 }
@@ -809,6 +1250,26 @@ func GetProcessGroupConditionType(processGroupConditionType string) (ProcessGrou
 		return SidecarUnreachable, nil
 	case "PodPending":
 		return PodPending, nil
+	case "ExclusionStuck":
+		return ExclusionStuck, nil
+	case "PodCrashLooping":
+		return PodCrashLooping, nil
+	case "PodUnschedulable":
+		return PodUnschedulable, nil
+	case "PodTerminating":
+		return PodTerminating, nil
+	case "NodeTaintDetected":
+		return NodeTaintDetected, nil
+	case "NodeTaintReplacing":
+		return NodeTaintReplacing, nil
+	case "ProcessHasIOError":
+		return ProcessHasIOError, nil
+	case "ProcessIsLagging":
+		return ProcessIsLagging, nil
+	case "ProcessHasLowDiskSpace":
+		return ProcessHasLowDiskSpace, nil
+	case "RestartRequested":
+		return RestartRequested, nil
 	}
 
 	return "", fmt.Errorf("unknown process group condition type: %s", processGroupConditionType)
@@ -887,6 +1348,11 @@ type ClusterGenerationStatus struct {
 	// NeedsLockConfigurationChanges provides the last generation that is
 	// pending a change to the configuration of the locking system.
 	NeedsLockConfigurationChanges int64 `json:"needsLockConfigurationChanges,omitempty"`
+
+	// NeedsUpgradeStaging provides the last generation that could not
+	// complete an upgrade because the new version was not staged for all
+	// processes.
+	NeedsUpgradeStaging int64 `json:"needsUpgradeStaging,omitempty"`
 }
 
 // ClusterHealth represents different views into health in the cluster status.
@@ -904,6 +1370,11 @@ type ClusterHealth struct {
 	// DataMovementPriority reports the priority of the highest-priority data
 	// movement in the cluster.
 	DataMovementPriority int `json:"dataMovementPriority,omitempty"`
+
+	// FaultTolerance reports the number of fault domains the cluster can
+	// lose without losing data or availability. This is only set when the
+	// database is available and reports its fault tolerance.
+	FaultTolerance *int `json:"faultTolerance,omitempty"`
 }
 
 // PendingRemovalState holds information about a process that is being removed.
@@ -928,11 +1399,17 @@ type PendingRemovalState struct {
 
 // RoleCounts represents the roles whose counts can be customized.
 type RoleCounts struct {
-	Storage    int `json:"storage,omitempty"`
-	Logs       int `json:"logs,omitempty"`
-	Proxies    int `json:"proxies,omitempty"`
-	Resolvers  int `json:"resolvers,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Storage int `json:"storage,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Logs int `json:"logs,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Proxies int `json:"proxies,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Resolvers int `json:"resolvers,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
 	LogRouters int `json:"log_routers,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
 	RemoteLogs int `json:"remote_logs,omitempty"`
 }
 
@@ -975,26 +1452,58 @@ func (flags VersionFlags) Map() map[string]int {
 // GetProcessCountsWithDefaults for more information on the rules for inferring
 // process counts.
 type ProcessCounts struct {
-	Unset             int `json:"unset,omitempty"`
-	Storage           int `json:"storage,omitempty"`
-	Transaction       int `json:"transaction,omitempty"`
-	Resolution        int `json:"resolution,omitempty"`
-	Tester            int `json:"tester,omitempty"`
-	Proxy             int `json:"proxy,omitempty"`
-	Master            int `json:"master,omitempty"`
-	Stateless         int `json:"stateless,omitempty"`
-	Log               int `json:"log,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Unset int `json:"unset,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Storage int `json:"storage,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Transaction int `json:"transaction,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Resolution int `json:"resolution,omitempty"`
+	// Deprecated: fdbserver does not recognize this process class, and any
+	// processes with this process class will fail to start. Use Test instead.
+	// +kubebuilder:validation:Minimum=-1
+	Tester int `json:"tester,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Proxy int `json:"proxy,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Master int `json:"master,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Stateless int `json:"stateless,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Log int `json:"log,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
 	ClusterController int `json:"cluster_controller,omitempty"`
-	LogRouter         int `json:"router,omitempty"`
-	FastRestore       int `json:"fast_restore,omitempty"`
-	DataDistributor   int `json:"data_distributor,omitempty"`
-	Coordinator       int `json:"coordinator,omitempty"`
-	Ratekeeper        int `json:"ratekeeper,omitempty"`
-	StorageCache      int `json:"storage_cache,omitempty"`
-	BackupWorker      int `json:"backup,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	LogRouter int `json:"router,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	FastRestore int `json:"fast_restore,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	DataDistributor int `json:"data_distributor,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Coordinator int `json:"coordinator,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Ratekeeper int `json:"ratekeeper,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	StorageCache int `json:"storage_cache,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	BackupWorker int `json:"backup,omitempty"`
+	// +kubebuilder:validation:Minimum=-1
+	Test int `json:"test,omitempty"`
+
+	// CommitProxy defines the number of processes that only run commit
+	// proxies. This requires FoundationDB 7.0 or later.
+	// +kubebuilder:validation:Minimum=-1
+	CommitProxy int `json:"commit_proxy,omitempty"`
+
+	// GrvProxy defines the number of processes that only run GRV proxies.
+	// This requires FoundationDB 7.0 or later.
+	// +kubebuilder:validation:Minimum=-1
+	GrvProxy int `json:"grv_proxy,omitempty"`
 
 	// Deprecated: This is unsupported and any processes with this process class
 	// will fail to start.
+	// +kubebuilder:validation:Minimum=-1
 	Resolver int `json:"resolver,omitempty"`
 }
 
@@ -1012,6 +1521,15 @@ func (counts ProcessCounts) Map() map[ProcessClass]int {
 	return countMap
 }
 
+// Total returns the total number of processes across all process classes.
+func (counts ProcessCounts) Total() int {
+	total := 0
+	for _, value := range counts.Map() {
+		total += value
+	}
+	return total
+}
+
 // IncreaseCount adds to one of the process counts based on the name.
 func (counts *ProcessCounts) IncreaseCount(name ProcessClass, amount int) {
 	index, present := processClassIndices[name]
@@ -1112,6 +1630,148 @@ type FoundationDBClusterAutomationOptions struct {
 	// UseNonBlockingExcludes defines whether the operator is allowed to use non blocking exclude commands.
 	// The default is false.
 	UseNonBlockingExcludes *bool `json:"useNonBlockingExcludes,omitempty"`
+
+	// ExclusionTimeoutSeconds defines how long an exclusion can run without
+	// completing before the operator marks the process group with the
+	// ExclusionStuck condition and emits a warning event.
+	// The default is 3600 seconds, or 1 hour.
+	// +kubebuilder:validation:Minimum=1
+	ExclusionTimeoutSeconds *int `json:"exclusionTimeoutSeconds,omitempty"`
+
+	// RollbackStuckExclusions defines whether the operator should include
+	// the processes of a stuck exclusion again, so that they can keep
+	// serving while the cause is investigated. The operator retries the
+	// exclusion once another exclusion timeout has passed.
+	// The default is false.
+	RollbackStuckExclusions *bool `json:"rollbackStuckExclusions,omitempty"`
+
+	// ReconciliationStalledTimeoutSeconds defines how long the cluster can
+	// fail to reconcile the latest generation before the operator sets the
+	// ReconciliationStalled condition and emits a warning event.
+	// The default is 3600 seconds, or 1 hour.
+	// +kubebuilder:validation:Minimum=1
+	ReconciliationStalledTimeoutSeconds *int `json:"reconciliationStalledTimeoutSeconds,omitempty"`
+
+	// MaxConcurrentExclusions defines how many process groups the operator
+	// excludes at the same time. The operator starts excluding more process
+	// groups once the exclusions in progress have completed and the process
+	// groups have been removed.
+	// The default is to exclude all process groups that are marked for
+	// removal at once.
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentExclusions *int `json:"maxConcurrentExclusions,omitempty"`
+
+	// MinimumFreeSpacePercentage defines the percentage of the disk space
+	// of the remaining processes that must still be free after the data of
+	// the excluded processes has been moved to them. The operator will not
+	// start an exclusion that would bring the free space below this
+	// percentage.
+	// The default is to not check the free space.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinimumFreeSpacePercentage *int `json:"minimumFreeSpacePercentage,omitempty"`
+
+	// MaxZonesWithUnavailablePods defines how many fault domains can have
+	// pods that are being removed at the same time. Process groups in a
+	// fault domain that already has pods that are being removed can always
+	// be removed, since FoundationDB already treats that fault domain as
+	// failed.
+	// The default is to not limit the number of fault domains.
+	// +kubebuilder:validation:Minimum=1
+	MaxZonesWithUnavailablePods *int `json:"maxZonesWithUnavailablePods,omitempty"`
+
+	// RecreateStuckPods defines whether the operator should recreate pods
+	// that are crash looping, unschedulable or stuck in terminating for
+	// longer than the StuckPodTimeoutSeconds. Pods that are stuck in
+	// terminating are only force deleted if their node is not ready.
+	// The default is false.
+	RecreateStuckPods *bool `json:"recreateStuckPods,omitempty"`
+
+	// StuckPodTimeoutSeconds defines how long a pod must be crash looping,
+	// unschedulable or terminating before the operator recreates it.
+	// The default is 600 seconds, or 10 minutes.
+	// +kubebuilder:validation:Minimum=1
+	StuckPodTimeoutSeconds *int `json:"stuckPodTimeoutSeconds,omitempty"`
+
+	// AllowFdbcliCommands defines whether the operator runs fdbcli commands
+	// that are requested through the foundationdb.org/fdbcli-command
+	// annotation on the cluster. This is intended for break-glass operations.
+	// The default is false.
+	AllowFdbcliCommands *bool `json:"allowFdbcliCommands,omitempty"`
+
+	// ProcessHealthChecks defines options for detecting processes that are
+	// running but report problems in the database status.
+	ProcessHealthChecks ProcessHealthCheckOptions `json:"processHealthChecks,omitempty"`
+
+	// RoleCountRecommendations defines options for recommending role counts
+	// based on the load of the database.
+	RoleCountRecommendations RoleCountRecommendationOptions `json:"roleCountRecommendations,omitempty"`
+}
+
+// RoleCountRecommendationOptions controls the recommendations for the
+// number of proxies, resolvers and logs, based on the CPU usage of the
+// proxies and resolvers and the queue size of the logs.
+type RoleCountRecommendationOptions struct {
+	// Enabled controls whether the operator publishes recommended role
+	// counts in the recommendedRoleCounts field in the cluster status. The
+	// recommendations are reset when this is disabled.
+	// The default is false.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Apply controls whether the operator configures the database with the
+	// recommended role counts when they are higher than the role counts in
+	// the spec. This requires recommendations to be enabled.
+	// The default is false.
+	Apply *bool `json:"apply,omitempty"`
+
+	// MaxCPUUsagePercentage defines the average CPU usage of the proxies or
+	// resolvers, as a percentage of one core, above which the operator
+	// recommends more of them.
+	// The default is 70.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	MaxCPUUsagePercentage *int `json:"maxCPUUsagePercentage,omitempty"`
+
+	// MaxLogQueueBytes defines the queue size of the worst log server above
+	// which the operator recommends another log.
+	// The default is 1000000000 bytes, or 1 GB.
+	// +kubebuilder:validation:Minimum=1
+	MaxLogQueueBytes *int64 `json:"maxLogQueueBytes,omitempty"`
+
+	// MaxRoleCounts defines the highest number of logs, proxies and
+	// resolvers that the operator recommends. A count of 0 uses the default,
+	// which is twice the role count from the database configuration.
+	MaxRoleCounts RoleCounts `json:"maxRoleCounts,omitempty"`
+}
+
+// ProcessHealthCheckOptions controls the detection of processes that are
+// running but are undesired, e.g. because they report IO errors, have a
+// high durability lag or are running out of disk space.
+type ProcessHealthCheckOptions struct {
+	// Enabled controls whether the operator sets the ProcessHasIOError,
+	// ProcessIsLagging and ProcessHasLowDiskSpace conditions on the process
+	// groups.
+	// The default is false.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MaxDurabilityLagSeconds defines the durability lag of a storage server
+	// above which the process is considered to be lagging.
+	// The default is 300 seconds, or 5 minutes.
+	// +kubebuilder:validation:Minimum=1
+	MaxDurabilityLagSeconds *int `json:"maxDurabilityLagSeconds,omitempty"`
+
+	// MinimumFreeDiskPercentage defines the percentage of free disk space
+	// below which a process is considered to be running out of disk space.
+	// The default is 5.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	MinimumFreeDiskPercentage *int `json:"minimumFreeDiskPercentage,omitempty"`
+
+	// ReplaceUndesiredProcesses defines whether process groups with one of
+	// these conditions are replaced by the automatic replacements once the
+	// condition has been present for longer than the failure detection time.
+	// The default is false.
+	ReplaceUndesiredProcesses *bool `json:"replaceUndesiredProcesses,omitempty"`
 }
 
 // AutomaticReplacementOptions controls options for automatically replacing
@@ -1135,6 +1795,47 @@ type AutomaticReplacementOptions struct {
 	// +kubebuilder:default:=1
 	// +kubebuilder:validation:Minimum=0
 	MaxConcurrentReplacements *int `json:"maxConcurrentReplacements,omitempty"`
+
+	// TaintReplacementOptions defines the node taints that should cause the
+	// process groups on that node to be replaced. This can be used to
+	// replace process groups on cordoned nodes, on nodes that are not ready,
+	// or on nodes that are tainted because of a hardware failure.
+	TaintReplacementOptions []TaintReplacementOption `json:"taintReplacementOptions,omitempty"`
+}
+
+// TaintReplacementOption defines a node taint that causes the process groups
+// on that node to be replaced.
+type TaintReplacementOption struct {
+	// Key defines the key of the taint. The key "*" matches all taints.
+	// +kubebuilder:validation:MinLength=1
+	Key string `json:"key"`
+
+	// DurationInSeconds defines how long the taint must be present on the
+	// node before the process groups are replaced.
+	// +kubebuilder:validation:Minimum=0
+	DurationInSeconds int `json:"durationInSeconds,omitempty"`
+}
+
+// Matches checks if the option applies to a taint with the given key.
+func (option TaintReplacementOption) Matches(key string) bool {
+	return option.Key == "*" || option.Key == key
+}
+
+// ProcessGroupOverride defines settings that only apply to the pod of a
+// single process group.
+type ProcessGroupOverride struct {
+	// NodeName pins the pod to the node with this name, through a node
+	// selector on the kubernetes.io/hostname label.
+	NodeName string `json:"nodeName,omitempty"`
+
+	// Labels defines additional labels for the pod. These cannot replace the
+	// labels that the operator uses to identify the pod.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Resources defines the resource requests and limits for the main
+	// container. Each resource that is set here replaces the value from the
+	// pod template of the process class.
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // ProcessSettings defines process-level settings.
@@ -1223,18 +1924,18 @@ func (cluster *FoundationDBCluster) GetProcessSettings(processClass ProcessClass
 // the UsableRegions is greater than 1. It will be equal to -1 when the
 // UsableRegions is less than or equal to 1.
 func (cluster *FoundationDBCluster) GetRoleCountsWithDefaults() RoleCounts {
-	counts := cluster.Spec.DatabaseConfiguration.RoleCounts.DeepCopy()
-	if counts.Storage == 0 {
-		counts.Storage = 2*cluster.DesiredFaultTolerance() + 1
-	}
-	if counts.Logs == 0 {
-		counts.Logs = 3
-	}
-	if counts.Proxies == 0 {
-		counts.Proxies = 3
-	}
-	if counts.Resolvers == 0 {
-		counts.Resolvers = 1
+	counts := cluster.GetConfiguredRoleCounts()
+	if cluster.GetApplyRoleCountRecommendations() && cluster.Status.RecommendedRoleCounts != nil {
+		recommended := cluster.Status.RecommendedRoleCounts
+		if recommended.Logs > counts.Logs {
+			counts.Logs = recommended.Logs
+		}
+		if recommended.Proxies > counts.Proxies {
+			counts.Proxies = recommended.Proxies
+		}
+		if recommended.Resolvers > counts.Resolvers {
+			counts.Resolvers = recommended.Resolvers
+		}
 	}
 	if counts.RemoteLogs == 0 {
 		if cluster.Spec.DatabaseConfiguration.UsableRegions > 1 {
@@ -1250,6 +1951,27 @@ func (cluster *FoundationDBCluster) GetRoleCountsWithDefaults() RoleCounts {
 			counts.LogRouters = -1
 		}
 	}
+	return counts
+}
+
+// GetConfiguredRoleCounts gets the role counts from the database
+// configuration, with the defaults for the storage servers, logs, proxies and
+// resolvers filled in. Unlike GetRoleCountsWithDefaults, this does not
+// include the recommended role counts.
+func (cluster *FoundationDBCluster) GetConfiguredRoleCounts() RoleCounts {
+	counts := cluster.Spec.DatabaseConfiguration.RoleCounts.DeepCopy()
+	if counts.Storage == 0 {
+		counts.Storage = 2*cluster.DesiredFaultTolerance() + 1
+	}
+	if counts.Logs == 0 {
+		counts.Logs = 3
+	}
+	if counts.Proxies == 0 {
+		counts.Proxies = 3
+	}
+	if counts.Resolvers == 0 {
+		counts.Resolvers = 1
+	}
 	return *counts
 }
 
@@ -1359,9 +2081,16 @@ func (cluster *FoundationDBCluster) GetProcessCountsWithDefaults() (ProcessCount
 		)
 	}
 	if processCounts.Stateless == 0 {
+		proxyCount := cluster.calculateProcessCountFromRole(roleCounts.Proxies, processCounts.Proxy)
+		// The proxies only run on dedicated processes if there are processes
+		// for both kinds of proxies.
+		if version.HasGrvProxies() && processCounts.CommitProxy > 0 && processCounts.GrvProxy > 0 {
+			proxyCount = 0
+		}
+
 		primaryStatelessCount := cluster.calculateProcessCountFromRole(1, processCounts.Master) +
 			cluster.calculateProcessCountFromRole(1, processCounts.ClusterController) +
-			cluster.calculateProcessCountFromRole(roleCounts.Proxies, processCounts.Proxy) +
+			proxyCount +
 			cluster.calculateProcessCountFromRole(roleCounts.Resolvers, processCounts.Resolution, processCounts.Resolver)
 		if version.HasRatekeeperRole() {
 			primaryStatelessCount += cluster.calculateProcessCountFromRole(1, processCounts.Ratekeeper) +
@@ -1420,11 +2149,16 @@ func (cluster *FoundationDBCluster) MinimumFaultDomains() int {
 // DesiredCoordinatorCount returns the number of coordinators to recruit for
 // a cluster.
 func (cluster *FoundationDBCluster) DesiredCoordinatorCount() int {
+	count := cluster.MinimumFaultDomains() + cluster.DesiredFaultTolerance()
 	if cluster.Spec.DatabaseConfiguration.UsableRegions > 1 {
-		return 9
+		count = 9
+	}
+
+	if cluster.Spec.CoordinatorCount != nil && *cluster.Spec.CoordinatorCount > count {
+		return *cluster.Spec.CoordinatorCount
 	}
 
-	return cluster.MinimumFaultDomains() + cluster.DesiredFaultTolerance()
+	return count
 }
 
 // CheckReconciliation compares the spec and the status to determine if
@@ -1571,6 +2305,29 @@ func (cluster *FoundationDBCluster) GetStorageServersPerPod() int {
 	return cluster.Spec.StorageServersPerPod
 }
 
+// GetLogServersPerPod returns the Log Servers per Pod.
+func (cluster *FoundationDBCluster) GetLogServersPerPod() int {
+	if cluster.Spec.LogServersPerPod <= 1 {
+		return 1
+	}
+
+	return cluster.Spec.LogServersPerPod
+}
+
+// GetDesiredServersPerPod returns the number of fdbserver processes that
+// should run in a single Pod of the given process class.
+func (cluster *FoundationDBCluster) GetDesiredServersPerPod(pClass ProcessClass) int {
+	if pClass == ProcessClassStorage {
+		return cluster.GetStorageServersPerPod()
+	}
+
+	if pClass.IsLogProcess() {
+		return cluster.GetLogServersPerPod()
+	}
+
+	return 1
+}
+
 // CountsAreSatisfied checks whether the current counts of processes satisfy
 // a desired set of counts.
 func (counts ProcessCounts) CountsAreSatisfied(currentCounts ProcessCounts) bool {
@@ -1604,10 +2361,12 @@ var connectionStringPattern = regexp.MustCompile("(?m)^([^#][^:@]+):([^:@]+)@(.*
 type ConnectionString struct {
 	// DatabaseName provides an identifier for the database which persists
 	// across coordinator changes.
+	// +kubebuilder:validation:Pattern:=`^[A-Za-z0-9_]+$`
 	DatabaseName string `json:"databaseName,omitempty"`
 
 	// GenerationID provides a unique ID for the current generation of
 	// coordinators.
+	// +kubebuilder:validation:Pattern:=`^[A-Za-z0-9]+$`
 	GenerationID string `json:"generationID,omitempty"`
 
 	// Coordinators provides the addresses of the current coordinators.
@@ -1622,14 +2381,13 @@ func ParseConnectionString(str string) (ConnectionString, error) {
 		return ConnectionString{}, fmt.Errorf("invalid connection string %s", str)
 	}
 
-	coordinatorsStrings := strings.Split(components[3], ",")
-	coordinators := make([]string, len(coordinatorsStrings))
-	for idx, coordinatorsString := range coordinatorsStrings {
-		coordinatorAddress, err := ParseProcessAddress(coordinatorsString)
-		if err != nil {
-			return ConnectionString{}, err
-		}
+	coordinatorAddresses, err := ParseProcessAddresses(strings.Split(components[3], ","))
+	if err != nil {
+		return ConnectionString{}, err
+	}
 
+	coordinators := make([]string, len(coordinatorAddresses))
+	for idx, coordinatorAddress := range coordinatorAddresses {
 		coordinators[idx] = coordinatorAddress.String()
 	}
 
@@ -1640,6 +2398,11 @@ func ParseConnectionString(str string) (ConnectionString, error) {
 	}, nil
 }
 
+// CoordinatorAddresses returns the structured addresses of the coordinators.
+func (str *ConnectionString) CoordinatorAddresses() ([]ProcessAddress, error) {
+	return ParseProcessAddresses(str.Coordinators)
+}
+
 // String formats a connection string as a string
 func (str *ConnectionString) String() string {
 	return fmt.Sprintf("%s:%s@%s", str.DatabaseName, str.GenerationID, strings.Join(str.Coordinators, ","))
@@ -1647,9 +2410,16 @@ func (str *ConnectionString) String() string {
 
 // GenerateNewGenerationID builds a new generation ID
 func (str *ConnectionString) GenerateNewGenerationID() error {
+	// The IDs are generated from a cryptographic source, so they do not
+	// depend on a seed and do not repeat when the operator restarts.
 	id := strings.Builder{}
+	charCount := big.NewInt(int64(len(alphanum)))
 	for i := 0; i < 32; i++ {
-		err := id.WriteByte(alphanum[rand.Intn(len(alphanum))])
+		index, err := rand.Int(rand.Reader, charCount)
+		if err != nil {
+			return err
+		}
+		err = id.WriteByte(alphanum[index.Int64()])
 		if err != nil {
 			return err
 		}
@@ -1778,6 +2548,16 @@ func ParseProcessAddress(address string) (ProcessAddress, error) {
 		return result, nil
 	}
 
+	// IPv6 addresses without a port can be enclosed in brackets e.g. when they
+	// are read from the substitutions of the sidecar.
+	if strings.HasPrefix(address, "[") && strings.HasSuffix(address, "]") {
+		ip = net.ParseIP(address[1 : len(address)-1])
+		if ip != nil {
+			result.IPAddress = ip
+			return result, nil
+		}
+	}
+
 	// In order to find the address port pair we will go over the address stored in a tmp String.
 	// The idea is to split from the right to the left. If we find a Substring that is not a valid host port pair
 	// we can trim the last part and store it as a flag e.g. ":tls" and try the next substring with the flag removed.
@@ -1822,7 +2602,10 @@ func ParseProcessAddress(address string) (ProcessAddress, error) {
 	return result, nil
 }
 
-func parseAddresses(addrs []string) ([]ProcessAddress, error) {
+// ParseProcessAddresses parses a list of addresses from their string
+// representation. Every address can contain a port and flags like the tls
+// flag.
+func ParseProcessAddresses(addrs []string) ([]ProcessAddress, error) {
 	pAddresses := make([]ProcessAddress, len(addrs))
 
 	for idx, addr := range addrs {
@@ -1850,7 +2633,7 @@ func ParseProcessAddressesFromCmdline(cmdline string) ([]ProcessAddress, error)
 		return nil, fmt.Errorf("invalid cmdline with missing public_address: %s", cmdline)
 	}
 
-	return parseAddresses(strings.Split(res[1], ","))
+	return ParseProcessAddresses(strings.Split(res[1], ","))
 }
 
 // String gets the string representation of an address.
@@ -1897,8 +2680,8 @@ func (address ProcessAddress) StringWithoutFlags() string {
 
 // GetFullAddress gets the full public address we should use for a process.
 // This will include the IP address, the port, and any additional flags.
-func (cluster *FoundationDBCluster) GetFullAddress(ipAddress string, processNumber int) ProcessAddress {
-	addresses := cluster.GetFullAddressList(ipAddress, true, processNumber)
+func (cluster *FoundationDBCluster) GetFullAddress(ipAddress string, processClass ProcessClass, processNumber int) ProcessAddress {
+	addresses := cluster.GetFullAddressList(ipAddress, true, processClass, processNumber)
 	if len(addresses) < 1 {
 		return ProcessAddress{}
 	}
@@ -1907,14 +2690,151 @@ func (cluster *FoundationDBCluster) GetFullAddress(ipAddress string, processNumb
 	return addresses[0]
 }
 
+// ProcessPortsStatus provides the ports that the processes of a cluster use.
+type ProcessPortsStatus struct {
+	// BasePort provides the TLS port of the first process in a pod.
+	BasePort int `json:"basePort"`
+
+	// PortStride provides the distance between the ports of consecutive
+	// processes in a pod.
+	PortStride int `json:"portStride"`
+}
+
+// ReconciliationProgress describes how far the operator got in a
+// reconciliation of the cluster.
+type ReconciliationProgress struct {
+	// Generation provides the generation of the spec that the operator
+	// reconciled.
+	Generation int64 `json:"generation,omitempty"`
+
+	// CompletedSubReconcilers provides the number of sub-reconcilers that
+	// completed their work.
+	CompletedSubReconcilers int `json:"completedSubReconcilers,omitempty"`
+
+	// TotalSubReconcilers provides the number of sub-reconcilers in the
+	// reconciliation.
+	TotalSubReconcilers int `json:"totalSubReconcilers,omitempty"`
+
+	// DelayedSubReconcilers provides the sub-reconcilers that could not
+	// complete their work yet, but let the reconciliation continue.
+	DelayedSubReconcilers []string `json:"delayedSubReconcilers,omitempty"`
+
+	// BlockingSubReconciler provides the sub-reconciler that ended the
+	// reconciliation early.
+	BlockingSubReconciler string `json:"blockingSubReconciler,omitempty"`
+
+	// Message provides the reason why the blocking sub-reconciler ended the
+	// reconciliation.
+	Message string `json:"message,omitempty"`
+}
+
+// DefaultBasePort defines the TLS port of the first process in a pod if no
+// base port is set in the routing config.
+const DefaultBasePort = 4500
+
+// DefaultPortStride defines the distance between the ports of consecutive
+// processes in a pod if no port stride is set in the routing config.
+const DefaultPortStride = 2
+
 // GetProcessPort returns the expected port for a given process number
-// and the tls setting.
+// and the tls setting, with the default base port and port stride.
 func GetProcessPort(processNumber int, tls bool) int {
-	if tls {
-		return 4498 + 2*processNumber
+	return getProcessPort(DefaultBasePort, DefaultPortStride, processNumber, tls)
+}
+
+// getProcessPort returns the port for a given process number and the tls
+// setting.
+func getProcessPort(basePort int, portStride int, processNumber int, tls bool) int {
+	port := basePort + portStride*(processNumber-1)
+	if !tls {
+		port++
+	}
+
+	return port
+}
+
+// GetBasePort returns the TLS port of the first process in a pod.
+func (cluster *FoundationDBCluster) GetBasePort() int {
+	if cluster.Spec.Routing.BasePort == nil {
+		return DefaultBasePort
+	}
+
+	return *cluster.Spec.Routing.BasePort
+}
+
+// GetPortStride returns the distance between the ports of consecutive
+// processes in a pod.
+func (cluster *FoundationDBCluster) GetPortStride() int {
+	if cluster.Spec.Routing.PortStride == nil {
+		return DefaultPortStride
+	}
+
+	return *cluster.Spec.Routing.PortStride
+}
+
+// GetProcessPortForClass returns the port for a given process number of a
+// process class and the tls setting. This takes the base port and port stride
+// from the routing config, and the port range of the process class when the
+// pods use the host network, into account.
+func (cluster *FoundationDBCluster) GetProcessPortForClass(processClass ProcessClass, processNumber int, tls bool) int {
+	return getProcessPort(cluster.GetBasePort(), cluster.GetPortStride(), processNumber, tls) + cluster.GetProcessPortOffset(processClass)
+}
+
+// hostNetworkPortRangeSize defines the number of ports that are reserved for
+// the processes of a process class when the pods use the host network.
+const hostNetworkPortRangeSize = 100
+
+// hostNetworkPortRanges defines the index of the port range for each process
+// class when the pods use the host network.
+var hostNetworkPortRanges = map[ProcessClass]int{
+	ProcessClassStorage:           0,
+	ProcessClassLog:               1,
+	ProcessClassTransaction:       2,
+	ProcessClassStateless:         3,
+	ProcessClassGeneral:           4,
+	ProcessClassClusterController: 5,
+	ProcessClassCoordinator:       6,
+	"resolution":                  7,
+	"tester":                      8,
+	"proxy":                       9,
+	"master":                      10,
+	"router":                      11,
+	"fast_restore":                12,
+	"data_distributor":            13,
+	"ratekeeper":                  14,
+	"storage_cache":               15,
+	"backup":                      16,
+	ProcessClassTest:              17,
+	ProcessClassCommitProxy:       18,
+	ProcessClassGrvProxy:          19,
+	"resolver":                    20,
+}
+
+// HasHostNetworkPortRange determines whether a process class has a reserved
+// range of ports for running with the host network.
+func (pClass ProcessClass) HasHostNetworkPortRange() bool {
+	_, ok := hostNetworkPortRanges[pClass]
+	return ok
+}
+
+// GetMaxHostNetworkServersPerPod returns the maximum number of processes
+// that fit into the port range of a process class.
+func (cluster *FoundationDBCluster) GetMaxHostNetworkServersPerPod() int {
+	// The last process uses the port after its TLS port, so it needs two
+	// ports of the range.
+	return (hostNetworkPortRangeSize-2)/cluster.GetPortStride() + 1
+}
+
+// GetProcessPortOffset returns the offset that is added to the ports of the
+// processes of a process class. When the pods use the host network each
+// process class gets its own range of ports, to prevent collisions between
+// pods that run on the same node.
+func (cluster *FoundationDBCluster) GetProcessPortOffset(processClass ProcessClass) int {
+	if !cluster.UseHostNetwork() {
+		return 0
 	}
 
-	return 4499 + 2*processNumber
+	return hostNetworkPortRanges[processClass] * hostNetworkPortRangeSize
 }
 
 // GetFullAddressList gets the full list of public addresses we should use for a
@@ -1925,7 +2845,7 @@ func GetProcessPort(processNumber int, tls bool) int {
 // If a process needs multiple addresses, this will include all of them,
 // separated by commas. If you pass false for primaryOnly, this will return only
 // the primary address.
-func (cluster *FoundationDBCluster) GetFullAddressList(address string, primaryOnly bool, processNumber int) []ProcessAddress {
+func (cluster *FoundationDBCluster) GetFullAddressList(address string, primaryOnly bool, processClass ProcessClass, processNumber int) []ProcessAddress {
 	addrs := make([]ProcessAddress, 0, 2)
 
 	// If the address is already enclosed in brackets, remove them since they
@@ -1935,7 +2855,7 @@ func (cluster *FoundationDBCluster) GetFullAddressList(address string, primaryOn
 	// When a TLS address is provided the TLS address will always be the primary address
 	// see: https://github.com/apple/foundationdb/blob/master/fdbrpc/FlowTransport.h#L49-L56
 	if cluster.Status.RequiredAddresses.TLS {
-		pAddr := NewProcessAddress(nil, address, GetProcessPort(processNumber, true), map[string]bool{"tls": true})
+		pAddr := NewProcessAddress(nil, address, cluster.GetProcessPortForClass(processClass, processNumber, true), map[string]bool{"tls": true})
 		addrs = append(addrs, pAddr)
 
 		if cluster.Status.RequiredAddresses.TLS && primaryOnly {
@@ -1944,7 +2864,7 @@ func (cluster *FoundationDBCluster) GetFullAddressList(address string, primaryOn
 	}
 
 	if cluster.Status.RequiredAddresses.NonTLS {
-		pAddr := NewProcessAddress(nil, address, GetProcessPort(processNumber, false), nil)
+		pAddr := NewProcessAddress(nil, address, cluster.GetProcessPortForClass(processClass, processNumber, false), nil)
 		if !cluster.Status.RequiredAddresses.TLS && primaryOnly {
 			return []ProcessAddress{pAddr}
 		}
@@ -1998,14 +2918,52 @@ type FoundationDBClusterFaultDomain struct {
 	// ZoneCount provides the number of fault domains in the data center where
 	// these processes are running. This is only used in the
 	// `kubernetes-cluster` fault domain strategy.
+	// +kubebuilder:validation:Minimum=0
 	ZoneCount int `json:"zoneCount,omitempty"`
 
 	// ZoneIndex provides the index of this Kubernetes cluster in the list of
 	// KCs in the data center. This is only used in the `kubernetes-cluster`
 	// fault domain strategy.
+	// +kubebuilder:validation:Minimum=0
 	ZoneIndex int `json:"zoneIndex,omitempty"`
+
+	// SpreadMode defines how the operator spreads the pods of a process class
+	// across the fault domains.
+	//
+	// With the `Preferred` mode the operator adds a preferred pod
+	// anti-affinity for the fault domain key. With the `Required` mode the
+	// anti-affinity is required, so pods stay pending when there are not
+	// enough fault domains. With the `TopologySpread` mode the operator adds a
+	// topology spread constraint for the fault domain key instead. With the
+	// `None` mode the operator does not add any rules, which allows defining
+	// custom rules in the pod template.
+	// The default is `Preferred`.
+	// +kubebuilder:validation:Enum=Preferred;Required;TopologySpread;None
+	SpreadMode *FaultDomainSpreadMode `json:"spreadMode,omitempty"`
 }
 
+// FaultDomainSpreadMode models how the pods are spread across fault domains.
+type FaultDomainSpreadMode string
+
+const (
+	// FaultDomainSpreadModePreferred specifies that pods of the same process
+	// class should preferably run in different fault domains.
+	FaultDomainSpreadModePreferred FaultDomainSpreadMode = "Preferred"
+
+	// FaultDomainSpreadModeRequired specifies that pods of the same process
+	// class must run in different fault domains.
+	FaultDomainSpreadModeRequired FaultDomainSpreadMode = "Required"
+
+	// FaultDomainSpreadModeTopologySpread specifies that pods of the same
+	// process class are spread evenly across the fault domains with a
+	// topology spread constraint.
+	FaultDomainSpreadModeTopologySpread FaultDomainSpreadMode = "TopologySpread"
+
+	// FaultDomainSpreadModeNone specifies that the operator does not add any
+	// rules for spreading the pods.
+	FaultDomainSpreadModeNone FaultDomainSpreadMode = "None"
+)
+
 // RedundancyMode defines the core replication factor for the database
 type RedundancyMode string
 
@@ -2020,7 +2978,13 @@ const (
 	RedundancyModeUnset RedundancyMode = ""
 )
 
-// DatabaseConfiguration represents the configuration of the database
+// DatabaseConfiguration represents the configuration of the database.
+//
+// This type is used for the desired configuration in the spec and for the
+// running configuration in the status, so the string fields do not restrict
+// their values. A running database can use values that the operator does not
+// know about, and these must not prevent status updates. The operator
+// rejects unknown values in the spec when it reconciles the cluster.
 type DatabaseConfiguration struct {
 	// RedundancyMode defines the core replication factor for the database.
 	RedundancyMode RedundancyMode `json:"redundancy_mode,omitempty"`
@@ -2029,6 +2993,8 @@ type DatabaseConfiguration struct {
 	StorageEngine string `json:"storage_engine,omitempty"`
 
 	// UsableRegions defines how many regions the database should store data in.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=2
 	UsableRegions int `json:"usable_regions,omitempty"`
 
 	// Regions defines the regions that the database can replicate in.
@@ -2041,14 +3007,47 @@ type DatabaseConfiguration struct {
 	// VersionFlags defines internal flags for testing new features in the
 	// database.
 	VersionFlags `json:""`
+
+	// PerpetualStorageWiggle defines whether the database should continuously
+	// recruit new storage servers to replace the existing ones, one at a time.
+	// A value of 1 enables the wiggle, and a value of 0 disables it.
+	// This is only supported on FoundationDB 7.0 and later.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1
+	PerpetualStorageWiggle *int `json:"perpetual_storage_wiggle,omitempty"`
+
+	// StorageMigrationType defines how the database migrates storage servers
+	// to a new storage engine. With the gradual migration type, storage
+	// servers are migrated by the perpetual storage wiggle.
+	// This is only supported on FoundationDB 7.0 and later.
+	StorageMigrationType StorageMigrationType `json:"storage_migration_type,omitempty"`
 }
 
+// StorageMigrationType describes how the database migrates storage servers to
+// a new storage engine.
+type StorageMigrationType string
+
+const (
+	// StorageMigrationTypeDisabled means that storage servers are not migrated
+	// to a new storage engine.
+	StorageMigrationTypeDisabled StorageMigrationType = "disabled"
+
+	// StorageMigrationTypeAggressive means that storage servers are migrated
+	// to a new storage engine as soon as possible.
+	StorageMigrationTypeAggressive StorageMigrationType = "aggressive"
+
+	// StorageMigrationTypeGradual means that storage servers are migrated to
+	// a new storage engine by the perpetual storage wiggle.
+	StorageMigrationTypeGradual StorageMigrationType = "gradual"
+)
+
 // Region represents a region in the database configuration
 type Region struct {
 	// The data centers in this region.
 	DataCenters []DataCenter `json:"datacenters,omitempty"`
 
 	// The number of satellite logs that we should recruit.
+	// +kubebuilder:validation:Minimum=0
 	SatelliteLogs int `json:"satellite_logs,omitempty"`
 
 	// The replication strategy for satellite logs.
@@ -2127,6 +3126,7 @@ type ContainerOverrides struct {
 type ImageConfig struct {
 	// Version is the version of FoundationDB this policy applies to. If this is
 	// blank, the policy applies to all FDB versions.
+	// +kubebuilder:validation:Pattern:=^((\d+)\.(\d+)\.(\d+))?$
 	Version string `json:"version,omitempty"`
 
 	// BaseImage specifies the part of the image before the tag.
@@ -2201,6 +3201,14 @@ func (configuration DatabaseConfiguration) GetConfigurationString() (string, err
 
 	configurationString += " regions=" + regionString
 
+	if configuration.PerpetualStorageWiggle != nil {
+		configurationString += fmt.Sprintf(" perpetual_storage_wiggle=%d", *configuration.PerpetualStorageWiggle)
+	}
+
+	if configuration.StorageMigrationType != "" {
+		configurationString += fmt.Sprintf(" storage_migration_type=%s", configuration.StorageMigrationType)
+	}
+
 	return configurationString, nil
 }
 
@@ -2217,14 +3225,63 @@ func (cluster *FoundationDBCluster) DesiredDatabaseConfiguration() DatabaseConfi
 	if configuration.StorageEngine == "memory" {
 		configuration.StorageEngine = "memory-2"
 	}
+
+	version, err := ParseFdbVersion(cluster.Spec.Version)
+	if err == nil && !version.HasPerpetualStorageWiggle() {
+		configuration.PerpetualStorageWiggle = nil
+		configuration.StorageMigrationType = ""
+	}
+
+	if cluster.Spec.PrimaryDataCenter != "" && configuration.HasMainDataCenter(cluster.Spec.PrimaryDataCenter) {
+		configuration = configuration.WithPrimaryDataCenter(cluster.Spec.PrimaryDataCenter)
+	}
+
 	return configuration
 }
 
-// ClearMissingVersionFlags clears any version flags in the given configuration that are not
-// set in the configuration in the cluster spec.
-//
+// HasMainDataCenter determines whether a data center is the main data center
+// of one of the regions, which means that it can serve as the primary.
+func (configuration DatabaseConfiguration) HasMainDataCenter(id string) bool {
+	for _, region := range configuration.Regions {
+		mainID, _ := getMainDataCenter(region)
+		if mainID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithPrimaryDataCenter returns a copy of the configuration where the main
+// data center of the given data center's region has the highest priority.
+// The main data centers of the other regions get a priority of 0, unless they
+// have a negative priority, which prevents them from becoming the primary.
+func (configuration DatabaseConfiguration) WithPrimaryDataCenter(id string) DatabaseConfiguration {
+	result := configuration.DeepCopy()
+
+	for _, region := range result.Regions {
+		for index, dataCenter := range region.DataCenters {
+			if dataCenter.Satellite != 0 {
+				continue
+			}
+
+			if dataCenter.ID == id {
+				region.DataCenters[index].Priority = 1
+			} else if dataCenter.Priority > 0 {
+				region.DataCenters[index].Priority = 0
+			}
+		}
+	}
+
+	return result.NormalizeConfiguration()
+}
+
+// ClearMissingVersionFlags clears any version flags in the given configuration that are not
+// set in the configuration in the cluster spec.
+//
 // This allows us to compare the spec to the live configuration while ignoring
-// version flags that are unset in the spec.
+// version flags that are unset in the spec. This also clears the storage
+// wiggle settings when they are unset in the spec.
 func (cluster *FoundationDBCluster) ClearMissingVersionFlags(configuration *DatabaseConfiguration) {
 	if cluster.Spec.DatabaseConfiguration.LogVersion == 0 {
 		configuration.LogVersion = 0
@@ -2232,6 +3289,12 @@ func (cluster *FoundationDBCluster) ClearMissingVersionFlags(configuration *Data
 	if cluster.Spec.DatabaseConfiguration.LogSpill == 0 {
 		configuration.LogSpill = 0
 	}
+	if cluster.Spec.DatabaseConfiguration.PerpetualStorageWiggle == nil {
+		configuration.PerpetualStorageWiggle = nil
+	}
+	if cluster.Spec.DatabaseConfiguration.StorageMigrationType == "" {
+		configuration.StorageMigrationType = ""
+	}
 }
 
 // IsBeingUpgraded determines whether the cluster has a pending upgrade.
@@ -2317,16 +3380,49 @@ func (cluster *FoundationDBCluster) GetLockID() string {
 	return cluster.Spec.InstanceIDPrefix
 }
 
+// GetStatusSummaryInterval returns the interval in which the operator
+// publishes the status summary. If this is zero, the summary should not be
+// published.
+func (cluster *FoundationDBCluster) GetStatusSummaryInterval() time.Duration {
+	if cluster.Spec.StatusSummaryIntervalSeconds == nil {
+		return 0
+	}
+
+	return time.Duration(*cluster.Spec.StatusSummaryIntervalSeconds) * time.Second
+}
+
 // NeedsExplicitListenAddress determines whether we pass a listen address
 // parameter to fdbserver.
 func (cluster *FoundationDBCluster) NeedsExplicitListenAddress() bool {
 	source := cluster.Spec.Routing.PublicIPSource
-	requiredForSource := source != nil && *source == PublicIPSourceService
+	requiredForSource := source != nil && (*source == PublicIPSourceService || *source == PublicIPSourceNode)
 	flag := cluster.Spec.UseExplicitListenAddress
 	requiredForFlag := flag != nil && *flag
 	return requiredForSource || requiredForFlag
 }
 
+// UsesConfigurationDatabase determines whether the fdbserver processes must
+// start with the configuration database enabled, so the operator can manage
+// the database knobs. This stays enabled while the status still lists knobs
+// that the operator has to clear.
+func (cluster *FoundationDBCluster) UsesConfigurationDatabase() bool {
+	if len(cluster.Spec.DatabaseKnobs) == 0 && len(cluster.Status.ManagedDatabaseKnobs) == 0 {
+		return false
+	}
+
+	versionString := cluster.Status.RunningVersion
+	if versionString == "" {
+		versionString = cluster.Spec.Version
+	}
+
+	version, err := ParseFdbVersion(versionString)
+	if err != nil {
+		return false
+	}
+
+	return version.SupportsConfigurationDatabase()
+}
+
 // GetPublicIPSource returns the set PublicIPSource or the default PublicIPSourcePod
 func (cluster *FoundationDBCluster) GetPublicIPSource() PublicIPSource {
 	source := cluster.Spec.Routing.PublicIPSource
@@ -2337,6 +3433,90 @@ func (cluster *FoundationDBCluster) GetPublicIPSource() PublicIPSource {
 	return *source
 }
 
+// GetImageType returns the image type of the cluster, which defaults to split.
+func (cluster *FoundationDBCluster) GetImageType() ImageType {
+	if cluster.Spec.ImageType == nil {
+		return ImageTypeSplit
+	}
+
+	return *cluster.Spec.ImageType
+}
+
+// UseUnifiedImage returns true if the pods should use the unified image.
+func (cluster *FoundationDBCluster) UseUnifiedImage() bool {
+	return cluster.GetImageType() == ImageTypeUnified
+}
+
+// GetPodUpdateStrategy returns the strategy for applying changes to the pod
+// spec. This defaults to Replacement if the deprecated updatePodsByReplacement
+// field is set, and to Recreate otherwise.
+func (cluster *FoundationDBCluster) GetPodUpdateStrategy() PodUpdateStrategy {
+	if cluster.Spec.PodUpdateStrategy != nil {
+		return *cluster.Spec.PodUpdateStrategy
+	}
+
+	if cluster.Spec.UpdatePodsByReplacement {
+		return PodUpdateStrategyReplacement
+	}
+
+	return PodUpdateStrategyRecreate
+}
+
+// GetFaultDomainSpreadMode returns the mode for spreading the pods across
+// the fault domains, which defaults to Preferred.
+func (cluster *FoundationDBCluster) GetFaultDomainSpreadMode() FaultDomainSpreadMode {
+	if cluster.Spec.FaultDomain.SpreadMode != nil {
+		return *cluster.Spec.FaultDomain.SpreadMode
+	}
+
+	return FaultDomainSpreadModePreferred
+}
+
+// PodUpdatesApproved checks if the pod updates for the current generation of
+// the cluster have been approved through the approve-pod-updates annotation.
+func (cluster *FoundationDBCluster) PodUpdatesApproved() bool {
+	approvedGeneration, ok := cluster.Annotations[ApprovePodUpdatesAnnotation]
+	if !ok {
+		return false
+	}
+
+	return approvedGeneration == strconv.FormatInt(cluster.Generation, 10)
+}
+
+// GetPublicServiceType returns the type of the services that are used to
+// provide the public IPs. The default is ClusterIP.
+func (cluster *FoundationDBCluster) GetPublicServiceType() corev1.ServiceType {
+	serviceType := cluster.Spec.Routing.PublicServiceType
+	if serviceType == nil {
+		return corev1.ServiceTypeClusterIP
+	}
+
+	return *serviceType
+}
+
+// GetPublicServiceTypeForClass returns the type of the per-pod services for
+// the processes of a class, based on the PublicServiceScope.
+func (cluster *FoundationDBCluster) GetPublicServiceTypeForClass(processClass ProcessClass) corev1.ServiceType {
+	scope := cluster.Spec.Routing.PublicServiceScope
+	if scope != nil && *scope == PublicServiceScopeCoordinators && !cluster.IsEligibleAsCandidate(processClass) {
+		return corev1.ServiceTypeClusterIP
+	}
+
+	return cluster.GetPublicServiceType()
+}
+
+// UsesNodePorts determines whether the processes of a class advertise the IP
+// of their node and the node port of their service as their public address.
+func (cluster *FoundationDBCluster) UsesNodePorts(processClass ProcessClass) bool {
+	return cluster.GetPublicIPSource() == PublicIPSourceService && cluster.GetPublicServiceTypeForClass(processClass) == corev1.ServiceTypeNodePort
+}
+
+// UseHostNetwork determines whether the pods should use the host network.
+func (cluster *FoundationDBCluster) UseHostNetwork() bool {
+	flag := cluster.Spec.Routing.UseHostNetwork
+	return flag != nil && *flag
+}
+
 // FillInDefaultsFromStatus adds in missing fields from the database
 // configuration in the database status to make sure they match the fields that
 // will appear in the cluster spec.
@@ -2735,14 +3915,67 @@ type RoutingConfig struct {
 	// PublicIPSource specifies what source a process should use to get its
 	// public IPs.
 	//
-	// This supports the values `pod` and `service`.
+	// This supports the values `pod`, `service` and `node`. When using
+	// `service` or `node` the processes will listen on the pod IP and
+	// advertise the service IP or the node IP as their public address.
 	PublicIPSource *PublicIPSource `json:"publicIPSource,omitempty"`
 
+	// PublicServiceType defines the type of the per-pod services that are
+	// created when the PublicIPSource is `service`.
+	//
+	// This supports the values `ClusterIP`, `LoadBalancer` and `NodePort`.
+	// When using `LoadBalancer` the processes will advertise the IP of the
+	// load balancer ingress. When using `NodePort` the processes will
+	// advertise the IP of their node and the node port of their service,
+	// which is only supported with one process per pod.
+	// +kubebuilder:validation:Enum=ClusterIP;LoadBalancer;NodePort
+	PublicServiceType *corev1.ServiceType `json:"publicServiceType,omitempty"`
+
+	// PublicServiceScope defines which processes get a per-pod service of
+	// the PublicServiceType. With `Coordinators` only the processes of the
+	// classes that are eligible as coordinators get such a service, and the
+	// other processes get a service of the type `ClusterIP`. This allows
+	// exposing only the coordinators outside of the Kubernetes cluster.
+	// The default is `All`.
+	// +kubebuilder:validation:Enum=All;Coordinators
+	PublicServiceScope *PublicServiceScope `json:"publicServiceScope,omitempty"`
+
 	// PodIPFamily tells the pod which family of IP addresses to use.
 	// You can use 4 to represent IPv4, and 6 to represent IPv6.
 	// This feature is only supported in FDB 7.0 or later, and requires
 	// dual-stack support in your Kubernetes environment.
+	// +kubebuilder:validation:Enum=4;6
 	PodIPFamily *int `json:"podIPFamily,omitempty"`
+
+	// UseHostNetwork determines whether the pods should use the network of
+	// the node they are running on.
+	//
+	// When this is enabled every process class gets its own range of ports,
+	// so pods of different process classes can run on the same node. This is
+	// only supported with the unified image.
+	UseHostNetwork *bool `json:"useHostNetwork,omitempty"`
+
+	// BasePort defines the TLS port of the first process in a pod. The
+	// non-TLS port of a process is always one above its TLS port.
+	// This can only be set when the cluster is created, since changing the
+	// ports of running processes would change the addresses of the
+	// coordinators.
+	// The default is 4500.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65534
+	BasePort *int `json:"basePort,omitempty"`
+
+	// PortStride defines the distance between the ports of consecutive
+	// processes in a pod.
+	// This can only be set when the cluster is created, for the same reason
+	// as the base port.
+	// The default is 2.
+	// +kubebuilder:validation:Minimum=2
+	PortStride *int `json:"portStride,omitempty"`
+
+	// ServiceMetadata allows customizing labels and annotations on the
+	// services that the operator creates.
+	ServiceMetadata *metav1.ObjectMeta `json:"serviceMetadata,omitempty"`
 }
 
 // RequiredAddressSet provides settings for which addresses we need to listen
@@ -2780,6 +4013,10 @@ type LabelConfig struct {
 	// resources it creates.
 	ResourceLabels map[string]string `json:"resourceLabels,omitempty"`
 
+	// ResourceAnnotations provides additional annotations that the operator
+	// should apply to resources it creates.
+	ResourceAnnotations map[string]string `json:"resourceAnnotations,omitempty"`
+
 	// ProcessGroupIDLabels provides the labels that we use for the instance ID
 	// field. The first label will be used by the operator when filtering
 	// resources.
@@ -2798,6 +4035,36 @@ type LabelConfig struct {
 	FilterOnOwnerReferences *bool `json:"filterOnOwnerReference,omitempty"`
 }
 
+// ImageType models the type of images that the pods of a cluster use.
+type ImageType string
+
+const (
+	// ImageTypeSplit specifies that the pods use a main image with fdbmonitor
+	// and a separate sidecar image.
+	ImageTypeSplit ImageType = "split"
+
+	// ImageTypeUnified specifies that the pods use a single image that runs
+	// fdbserver through the kubernetes monitor.
+	ImageTypeUnified ImageType = "unified"
+)
+
+// PodUpdateStrategy models how the operator applies changes to the pod spec.
+type PodUpdateStrategy string
+
+const (
+	// PodUpdateStrategyRecreate specifies that pods are deleted and recreated
+	// with the new spec.
+	PodUpdateStrategyRecreate PodUpdateStrategy = "Recreate"
+
+	// PodUpdateStrategyReplacement specifies that process groups are
+	// replaced with new process groups that use the new spec.
+	PodUpdateStrategyReplacement PodUpdateStrategy = "Replacement"
+
+	// PodUpdateStrategyManual specifies that pods are only recreated once the
+	// update has been approved.
+	PodUpdateStrategyManual PodUpdateStrategy = "Manual"
+)
+
 // PublicIPSource models options for how a pod gets its public IP.
 type PublicIPSource string
 
@@ -2807,9 +4074,29 @@ const (
 
 	// PublicIPSourceService specifies that a pod gets its IP from a service.
 	PublicIPSourceService PublicIPSource = "service"
+
+	// PublicIPSourceNode specifies that a pod gets its IP from the node it is
+	// running on.
+	PublicIPSourceNode PublicIPSource = "node"
+)
+
+// PublicServiceScope models which processes get a per-pod service of the
+// public service type.
+type PublicServiceScope string
+
+const (
+	// PublicServiceScopeAll specifies that all processes get a service of
+	// the public service type.
+	PublicServiceScopeAll PublicServiceScope = "All"
+
+	// PublicServiceScopeCoordinators specifies that only the processes of
+	// the classes that are eligible as coordinators get a service of the
+	// public service type.
+	PublicServiceScopeCoordinators PublicServiceScope = "Coordinators"
 )
 
 // ProcessClass models the class of a pod
+// +kubebuilder:validation:Enum=unset;storage;transaction;resolution;tester;proxy;master;stateless;log;cluster_controller;router;fast_restore;data_distributor;coordinator;ratekeeper;storage_cache;backup;test;commit_proxy;grv_proxy;resolver;general;blob_manager;blob_worker;encrypt_key_proxy
 type ProcessClass string
 
 const (
@@ -2825,22 +4112,60 @@ const (
 	ProcessClassGeneral ProcessClass = "general"
 	// ProcessClassClusterController model for FDB class cluster_controller
 	ProcessClassClusterController ProcessClass = "cluster_controller"
+	// ProcessClassCoordinator model for FDB class coordinator
+	ProcessClassCoordinator ProcessClass = "coordinator"
+	// ProcessClassTest model for FDB class test
+	ProcessClassTest ProcessClass = "test"
+	// ProcessClassCommitProxy model for FDB class commit_proxy
+	ProcessClassCommitProxy ProcessClass = "commit_proxy"
+	// ProcessClassGrvProxy model for FDB class grv_proxy
+	ProcessClassGrvProxy ProcessClass = "grv_proxy"
 )
 
 // IsStateful determines whether a process class should store data.
 func (pClass ProcessClass) IsStateful() bool {
-	return pClass == ProcessClassStorage || pClass == ProcessClassLog || pClass == ProcessClassTransaction
+	return pClass == ProcessClassStorage || pClass == ProcessClassLog || pClass == ProcessClassTransaction || pClass == ProcessClassCoordinator
+}
+
+// IsLogProcess determines whether a process class runs log servers.
+func (pClass ProcessClass) IsLogProcess() bool {
+	return pClass == ProcessClassLog || pClass == ProcessClassTransaction
 }
 
 // AddStorageServerPerDisk adds serverPerDisk to the status field to keep track which ConfigMaps should be kept
 func (clusterStatus *FoundationDBClusterStatus) AddStorageServerPerDisk(serversPerDisk int) {
-	for _, curServersPerDisk := range clusterStatus.StorageServersPerDisk {
+	clusterStatus.StorageServersPerDisk = addServersPerDisk(clusterStatus.StorageServersPerDisk, serversPerDisk)
+}
+
+// AddLogServerPerDisk adds serverPerDisk to the status field to keep track which ConfigMaps should be kept
+func (clusterStatus *FoundationDBClusterStatus) AddLogServerPerDisk(serversPerDisk int) {
+	clusterStatus.LogServersPerDisk = addServersPerDisk(clusterStatus.LogServersPerDisk, serversPerDisk)
+}
+
+// AddServersPerDisk adds serverPerDisk to the status field of the given
+// process class. Process classes that only run a single process per Pod are
+// ignored.
+func (clusterStatus *FoundationDBClusterStatus) AddServersPerDisk(serversPerDisk int, pClass ProcessClass) {
+	if pClass == ProcessClassStorage {
+		clusterStatus.AddStorageServerPerDisk(serversPerDisk)
+		return
+	}
+
+	if pClass.IsLogProcess() {
+		clusterStatus.AddLogServerPerDisk(serversPerDisk)
+	}
+}
+
+// addServersPerDisk adds serversPerDisk to the list if it is not already
+// present.
+func addServersPerDisk(current []int, serversPerDisk int) []int {
+	for _, curServersPerDisk := range current {
 		if curServersPerDisk == serversPerDisk {
-			return
+			return current
 		}
 	}
 
-	clusterStatus.StorageServersPerDisk = append(clusterStatus.StorageServersPerDisk, serversPerDisk)
+	return append(current, serversPerDisk)
 }
 
 // GetMaxConcurrentReplacements returns the cluster setting for MaxConcurrentReplacements, defaults to 1 if unset.
@@ -2855,8 +4180,13 @@ func (cluster *FoundationDBCluster) GetMaxConcurrentReplacements() int {
 // CoordinatorSelectionSetting defines the process class and the priority of it.
 // A higher priority means that the process class is preferred over another.
 type CoordinatorSelectionSetting struct {
+	// ProcessClass defines the process class that is eligible for
+	// coordinator selection.
 	ProcessClass ProcessClass `json:"processClass,omitempty"`
-	Priority     int          `json:"priority,omitempty"`
+
+	// Priority defines the priority of the process class. Process classes
+	// with a higher priority will be chosen first.
+	Priority int `json:"priority,omitempty"`
 }
 
 // IsEligibleAsCandidate checks if the given process has the right process class to be considered a valid coordinator.
@@ -2879,7 +4209,8 @@ func (cluster *FoundationDBCluster) IsEligibleAsCandidate(pClass ProcessClass) b
 	return false
 }
 
-// GetClassCandidatePriority returns the priority for a class. This will be used to sort the processes for coordinator selection
+// GetClassCandidatePriority returns the priority for a class. This will be used to sort the processes for coordinator selection.
+// Processes with the coordinator class are preferred over all other classes, unless the class is part of the CoordinatorSelection.
 func (cluster *FoundationDBCluster) GetClassCandidatePriority(pClass ProcessClass) int {
 	for _, setting := range cluster.Spec.CoordinatorSelection {
 		if pClass == setting.ProcessClass {
@@ -2887,6 +4218,10 @@ func (cluster *FoundationDBCluster) GetClassCandidatePriority(pClass ProcessClas
 		}
 	}
 
+	if pClass == ProcessClassCoordinator {
+		return math.MaxInt64
+	}
+
 	return math.MinInt64
 }
 
@@ -2937,6 +4272,194 @@ func (cluster *FoundationDBCluster) GetUseNonBlockingExcludes() bool {
 	return *cluster.Spec.AutomationOptions.UseNonBlockingExcludes
 }
 
+// GetExclusionTimeout returns the time after which an exclusion that has not
+// completed is considered stuck.
+func (cluster *FoundationDBCluster) GetExclusionTimeout() time.Duration {
+	if cluster.Spec.AutomationOptions.ExclusionTimeoutSeconds == nil {
+		return time.Hour
+	}
+
+	return time.Duration(*cluster.Spec.AutomationOptions.ExclusionTimeoutSeconds) * time.Second
+}
+
+// GetMaxConcurrentExclusions returns the maximum number of process groups
+// that are excluded at the same time, or 0 if this is unlimited.
+func (cluster *FoundationDBCluster) GetMaxConcurrentExclusions() int {
+	if cluster.Spec.AutomationOptions.MaxConcurrentExclusions == nil {
+		return 0
+	}
+
+	return *cluster.Spec.AutomationOptions.MaxConcurrentExclusions
+}
+
+// GetMaxZonesWithUnavailablePods returns the maximum number of fault domains
+// that can have pods that are being removed at the same time, or 0 if this
+// is unlimited.
+func (cluster *FoundationDBCluster) GetMaxZonesWithUnavailablePods() int {
+	if cluster.Spec.AutomationOptions.MaxZonesWithUnavailablePods == nil {
+		return 0
+	}
+
+	return *cluster.Spec.AutomationOptions.MaxZonesWithUnavailablePods
+}
+
+// GetRollbackStuckExclusions returns the value of rollbackStuckExclusions or false if unset.
+func (cluster *FoundationDBCluster) GetRollbackStuckExclusions() bool {
+	if cluster.Spec.AutomationOptions.RollbackStuckExclusions == nil {
+		return false
+	}
+
+	return *cluster.Spec.AutomationOptions.RollbackStuckExclusions
+}
+
+// GetReconciliationStalledTimeout returns the time after which a cluster that
+// has not reconciled the latest generation is considered stalled.
+func (cluster *FoundationDBCluster) GetReconciliationStalledTimeout() time.Duration {
+	if cluster.Spec.AutomationOptions.ReconciliationStalledTimeoutSeconds == nil {
+		return time.Hour
+	}
+
+	return time.Duration(*cluster.Spec.AutomationOptions.ReconciliationStalledTimeoutSeconds) * time.Second
+}
+
+// GetRecreateStuckPods returns the value of recreateStuckPods or false if unset.
+func (cluster *FoundationDBCluster) GetRecreateStuckPods() bool {
+	if cluster.Spec.AutomationOptions.RecreateStuckPods == nil {
+		return false
+	}
+
+	return *cluster.Spec.AutomationOptions.RecreateStuckPods
+}
+
+// GetProcessHealthChecksEnabled returns the value of
+// processHealthChecks.enabled or false if unset.
+func (cluster *FoundationDBCluster) GetProcessHealthChecksEnabled() bool {
+	if cluster.Spec.AutomationOptions.ProcessHealthChecks.Enabled == nil {
+		return false
+	}
+
+	return *cluster.Spec.AutomationOptions.ProcessHealthChecks.Enabled
+}
+
+// GetMaxDurabilityLag returns the durability lag above which a storage
+// server is considered to be lagging. The default is 5 minutes.
+func (cluster *FoundationDBCluster) GetMaxDurabilityLag() time.Duration {
+	if cluster.Spec.AutomationOptions.ProcessHealthChecks.MaxDurabilityLagSeconds == nil {
+		return 5 * time.Minute
+	}
+
+	return time.Duration(*cluster.Spec.AutomationOptions.ProcessHealthChecks.MaxDurabilityLagSeconds) * time.Second
+}
+
+// GetMinimumFreeDiskPercentage returns the percentage of free disk space
+// below which a process is considered to be running out of disk space,
+// defaults to 5 if unset.
+func (cluster *FoundationDBCluster) GetMinimumFreeDiskPercentage() int {
+	if cluster.Spec.AutomationOptions.ProcessHealthChecks.MinimumFreeDiskPercentage == nil {
+		return 5
+	}
+
+	return *cluster.Spec.AutomationOptions.ProcessHealthChecks.MinimumFreeDiskPercentage
+}
+
+// GetReplaceUndesiredProcesses returns the value of
+// processHealthChecks.replaceUndesiredProcesses or false if unset.
+func (cluster *FoundationDBCluster) GetReplaceUndesiredProcesses() bool {
+	if cluster.Spec.AutomationOptions.ProcessHealthChecks.ReplaceUndesiredProcesses == nil {
+		return false
+	}
+
+	return *cluster.Spec.AutomationOptions.ProcessHealthChecks.ReplaceUndesiredProcesses
+}
+
+// GetRoleCountRecommendationsEnabled returns the value of
+// roleCountRecommendations.enabled or false if unset.
+func (cluster *FoundationDBCluster) GetRoleCountRecommendationsEnabled() bool {
+	if cluster.Spec.AutomationOptions.RoleCountRecommendations.Enabled == nil {
+		return false
+	}
+
+	return *cluster.Spec.AutomationOptions.RoleCountRecommendations.Enabled
+}
+
+// GetApplyRoleCountRecommendations returns whether the operator configures
+// the database with the recommended role counts. This is false if the
+// recommendations are disabled.
+func (cluster *FoundationDBCluster) GetApplyRoleCountRecommendations() bool {
+	apply := cluster.Spec.AutomationOptions.RoleCountRecommendations.Apply
+	return cluster.GetRoleCountRecommendationsEnabled() && apply != nil && *apply
+}
+
+// GetMaxCPUUsagePercentageForRecommendations returns the average CPU usage
+// of the proxies or resolvers above which the operator recommends more of
+// them, defaults to 70 if unset.
+func (cluster *FoundationDBCluster) GetMaxCPUUsagePercentageForRecommendations() int {
+	if cluster.Spec.AutomationOptions.RoleCountRecommendations.MaxCPUUsagePercentage == nil {
+		return 70
+	}
+
+	return *cluster.Spec.AutomationOptions.RoleCountRecommendations.MaxCPUUsagePercentage
+}
+
+// GetMaxLogQueueBytesForRecommendations returns the queue size of the worst
+// log server above which the operator recommends another log, defaults to
+// 1 GB if unset.
+func (cluster *FoundationDBCluster) GetMaxLogQueueBytesForRecommendations() int64 {
+	if cluster.Spec.AutomationOptions.RoleCountRecommendations.MaxLogQueueBytes == nil {
+		return 1000000000
+	}
+
+	return *cluster.Spec.AutomationOptions.RoleCountRecommendations.MaxLogQueueBytes
+}
+
+// GetMaxRoleCountsForRecommendations returns the highest number of logs,
+// proxies and resolvers that the operator recommends, defaults to twice the
+// configured role counts if unset.
+func (cluster *FoundationDBCluster) GetMaxRoleCountsForRecommendations() RoleCounts {
+	configured := cluster.GetConfiguredRoleCounts()
+	counts := cluster.Spec.AutomationOptions.RoleCountRecommendations.MaxRoleCounts
+	if counts.Logs == 0 {
+		counts.Logs = 2 * configured.Logs
+	}
+	if counts.Proxies == 0 {
+		counts.Proxies = 2 * configured.Proxies
+	}
+	if counts.Resolvers == 0 {
+		counts.Resolvers = 2 * configured.Resolvers
+	}
+	return counts
+}
+
+// GetStuckPodTimeout returns the time a pod must be stuck before the
+// operator recreates it. The default is 10 minutes.
+func (cluster *FoundationDBCluster) GetStuckPodTimeout() time.Duration {
+	if cluster.Spec.AutomationOptions.StuckPodTimeoutSeconds == nil {
+		return 10 * time.Minute
+	}
+
+	return time.Duration(*cluster.Spec.AutomationOptions.StuckPodTimeoutSeconds) * time.Second
+}
+
+// GetMaximumClientWaitForBounce returns the maximum time the operator waits
+// for clients to disconnect before a bounce, or 10 minutes if unset.
+func (cluster *FoundationDBCluster) GetMaximumClientWaitForBounce() time.Duration {
+	if cluster.Spec.MaximumClientWaitSecondsForBounce == nil {
+		return 10 * time.Minute
+	}
+
+	return time.Duration(*cluster.Spec.MaximumClientWaitSecondsForBounce) * time.Second
+}
+
+// GetAllowFdbcliCommands returns the value of allowFdbcliCommands or false if
+// unset.
+func (cluster *FoundationDBCluster) GetAllowFdbcliCommands() bool {
+	if cluster.Spec.AutomationOptions.AllowFdbcliCommands == nil {
+		return false
+	}
+
+	return *cluster.Spec.AutomationOptions.AllowFdbcliCommands
+}
+
 // GetProcessClassLabel provides the label that this cluster is using for the
 // process class when identifying resources.
 func (cluster *FoundationDBCluster) GetProcessClassLabel() string {
@@ -2956,3 +4479,652 @@ func (cluster *FoundationDBCluster) GetProcessGroupIDLabel() string {
 	}
 	return labels[0]
 }
+
+// MetricsExporterConfig allows configuring a deployment that exports metrics
+// for the cluster in the Prometheus format.
+type MetricsExporterConfig struct {
+	// Enabled determines whether the operator should run the metrics
+	// exporter.
+	// The default is false.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// PodTemplateSpec allows customizing the pod template for the metrics
+	// exporter. The template must contain a container named `exporter` that
+	// defines the image of the exporter.
+	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
+}
+
+// ShouldRunMetricsExporter determines whether the operator should run the
+// metrics exporter for the cluster.
+func (cluster *FoundationDBCluster) ShouldRunMetricsExporter() bool {
+	enabled := cluster.Spec.MetricsExporter.Enabled
+	return enabled != nil && *enabled
+}
+
+// ClientConfig defines the config map and secret that the operator
+// publishes for client applications.
+type ClientConfig struct {
+	// Enabled determines whether the operator should publish a config map
+	// named `<cluster>-client-config` with the cluster file for client
+	// applications. The operator keeps the config map up to date when the
+	// coordinators change.
+	// The default is false.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// TLSSecretName defines the name of a secret in the namespace of the
+	// cluster that contains the certificate and key for client applications
+	// in the tls.crt and tls.key keys. When this is set, the operator
+	// publishes a secret named `<cluster>-client-tls` with these files and
+	// the trusted CAs of the cluster.
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// TagThrottle defines a throttle on the transactions with a tag.
+type TagThrottle struct {
+	// Tag defines the transaction tag that is throttled. This may only
+	// contain letters, digits, dashes and underscores.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=16
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_\-]+$`
+	Tag string `json:"tag"`
+
+	// Rate defines the maximum number of transactions per second that are
+	// started with the tag.
+	// +kubebuilder:validation:Minimum=0
+	Rate int `json:"rate"`
+
+	// Priority defines the highest priority of the transactions that are
+	// throttled.
+	// The default is default.
+	// +kubebuilder:validation:Enum=default;batch;immediate
+	Priority TagThrottlePriority `json:"priority,omitempty"`
+}
+
+// TagThrottlePriority models the priority of the transactions that a tag
+// throttle applies to.
+type TagThrottlePriority string
+
+const (
+	// TagThrottlePriorityDefault throttles the transactions with default
+	// and batch priority.
+	TagThrottlePriorityDefault TagThrottlePriority = "default"
+
+	// TagThrottlePriorityBatch throttles the transactions with batch
+	// priority.
+	TagThrottlePriorityBatch TagThrottlePriority = "batch"
+
+	// TagThrottlePriorityImmediate throttles the transactions with any
+	// priority.
+	TagThrottlePriorityImmediate TagThrottlePriority = "immediate"
+)
+
+// GetPriority returns the priority of the throttle, or the default priority
+// if unset.
+func (throttle TagThrottle) GetPriority() TagThrottlePriority {
+	if throttle.Priority == "" {
+		return TagThrottlePriorityDefault
+	}
+
+	return throttle.Priority
+}
+
+// TagThrottleStatus describes a tag throttle that is active in the database.
+type TagThrottleStatus struct {
+	TagThrottle `json:",inline"`
+
+	// ExpirationSeconds provides the remaining time before the throttle
+	// expires.
+	ExpirationSeconds int `json:"expirationSeconds,omitempty"`
+
+	// Manual reports whether the throttle was set manually, rather than
+	// automatically by the ratekeeper.
+	Manual bool `json:"manual,omitempty"`
+}
+
+// TagQuota defines the throughput quota for the transactions with a tag.
+type TagQuota struct {
+	// Tag defines the transaction tag that the quota applies to. This may only
+	// contain letters, digits, dashes and underscores.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=16
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9_\-]+$`
+	Tag string `json:"tag"`
+
+	// ReservedThroughput defines the throughput, in bytes per second, that
+	// the database reserves for the transactions with the tag.
+	// +kubebuilder:validation:Minimum=0
+	ReservedThroughput int64 `json:"reservedThroughput,omitempty"`
+
+	// TotalThroughput defines the maximum throughput, in bytes per second,
+	// of the transactions with the tag. This must not be lower than the
+	// reserved throughput.
+	// +kubebuilder:validation:Minimum=0
+	TotalThroughput int64 `json:"totalThroughput"`
+}
+
+// ShouldPublishClientConfig determines whether the operator should publish
+// the client config map for the cluster.
+func (cluster *FoundationDBCluster) ShouldPublishClientConfig() bool {
+	enabled := cluster.Spec.ClientConfig.Enabled
+	return enabled != nil && *enabled
+}
+
+// ConsistencyCheckConfig defines when the consistency checker is allowed to
+// run against the database.
+//
+// The consistency check is run by processes with the consistencycheck role,
+// which check the data while the check is not suspended in the database. The
+// operator runs such a process in a deployment and controls the suspension,
+// so the check only runs within the scheduled windows.
+type ConsistencyCheckConfig struct {
+	// Enabled determines whether the operator should manage the suspension
+	// of the consistency check. When this is disabled after being enabled,
+	// the operator resumes the consistency check and stops managing it.
+	// The default is false.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Suspend defines whether the operator should keep the consistency check
+	// suspended, regardless of the schedule.
+	// The default is false.
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// IntervalSeconds defines the time between the start of two consistency
+	// check windows.
+	// The default is 86400 (one day).
+	// +kubebuilder:validation:Minimum=60
+	IntervalSeconds *int `json:"intervalSeconds,omitempty"`
+
+	// WindowSeconds defines how long the consistency check is allowed to
+	// run in every window.
+	// The default is 3600 (one hour).
+	// +kubebuilder:validation:Minimum=60
+	WindowSeconds *int `json:"windowSeconds,omitempty"`
+
+	// StartTime defines the time of day when the consistency check windows
+	// start, in UTC and in the format HH:MM. The windows start at this time
+	// and repeat every interval. By default the first window starts when the
+	// operator starts managing the consistency check.
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	StartTime *string `json:"startTime,omitempty"`
+
+	// RunChecker determines whether the operator should run the consistency
+	// checker in a deployment named `<cluster>-consistency-check`. You can
+	// disable this if you run your own consistencycheck processes.
+	// The default is true.
+	RunChecker *bool `json:"runChecker,omitempty"`
+
+	// PodTemplateSpec allows customizing the pod template for the
+	// consistency checker. The checker runs in a container named
+	// `foundationdb`.
+	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
+}
+
+// ConsistencyCheckStatus provides information about the consistency check
+// windows that the operator has scheduled.
+type ConsistencyCheckStatus struct {
+	// Active reports whether the consistency check is currently allowed to
+	// run.
+	Active bool `json:"active,omitempty"`
+
+	// LastWindowStartTimestamp provides the time when the last window
+	// started, as a Unix timestamp.
+	LastWindowStartTimestamp int64 `json:"lastWindowStartTimestamp,omitempty"`
+
+	// LastWindowEndTimestamp provides the time when the last window ended,
+	// as a Unix timestamp.
+	LastWindowEndTimestamp int64 `json:"lastWindowEndTimestamp,omitempty"`
+
+	// CompletedWindows provides the number of windows that have ended.
+	CompletedWindows int `json:"completedWindows,omitempty"`
+
+	// LastWindowOutcome provides how the last window ended. The results of
+	// the consistency check itself are reported in the trace logs of the
+	// consistency check processes.
+	LastWindowOutcome ConsistencyCheckOutcome `json:"lastWindowOutcome,omitempty"`
+
+	// CheckerAvailable reports whether the consistency checker that the
+	// operator runs is available.
+	CheckerAvailable bool `json:"checkerAvailable,omitempty"`
+}
+
+// ConsistencyCheckOutcome describes how a consistency check window ended.
+type ConsistencyCheckOutcome string
+
+const (
+	// ConsistencyCheckOutcomeCompleted means that the consistency check was
+	// allowed to run for the full window.
+	ConsistencyCheckOutcomeCompleted ConsistencyCheckOutcome = "Completed"
+
+	// ConsistencyCheckOutcomeInterrupted means that the consistency check was
+	// suspended in the spec before the window ended.
+	ConsistencyCheckOutcomeInterrupted ConsistencyCheckOutcome = "Interrupted"
+)
+
+// ShouldManageConsistencyCheck determines whether the operator should manage
+// the suspension of the consistency check.
+func (cluster *FoundationDBCluster) ShouldManageConsistencyCheck() bool {
+	enabled := cluster.Spec.ConsistencyCheck.Enabled
+	return enabled != nil && *enabled
+}
+
+// ShouldRunConsistencyChecker determines whether the operator should run
+// the consistency checker for the cluster.
+func (cluster *FoundationDBCluster) ShouldRunConsistencyChecker() bool {
+	runChecker := cluster.Spec.ConsistencyCheck.RunChecker
+	return cluster.ShouldManageConsistencyCheck() && (runChecker == nil || *runChecker)
+}
+
+// ShouldSuspendConsistencyCheck determines whether the operator should keep
+// the consistency check suspended regardless of the schedule.
+func (cluster *FoundationDBCluster) ShouldSuspendConsistencyCheck() bool {
+	suspend := cluster.Spec.ConsistencyCheck.Suspend
+	return suspend != nil && *suspend
+}
+
+// GetConsistencyCheckInterval returns the time between the start of two
+// consistency check windows.
+func (cluster *FoundationDBCluster) GetConsistencyCheckInterval() time.Duration {
+	if cluster.Spec.ConsistencyCheck.IntervalSeconds == nil {
+		return 24 * time.Hour
+	}
+
+	return time.Duration(*cluster.Spec.ConsistencyCheck.IntervalSeconds) * time.Second
+}
+
+// GetConsistencyCheckScheduledStart returns the start of the latest
+// consistency check window that is scheduled at or before the given time,
+// based on the start time and the interval. This returns a zero time if no
+// start time is configured.
+func (cluster *FoundationDBCluster) GetConsistencyCheckScheduledStart(now time.Time) (time.Time, error) {
+	if cluster.Spec.ConsistencyCheck.StartTime == nil {
+		return time.Time{}, nil
+	}
+
+	startTime, err := time.Parse("15:04", *cluster.Spec.ConsistencyCheck.StartTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// The windows are counted from the start time on the first day of the
+	// epoch, so the schedule does not depend on when the operator started.
+	anchor := time.Unix(int64(startTime.Hour()*3600+startTime.Minute()*60), 0)
+	interval := cluster.GetConsistencyCheckInterval()
+
+	return anchor.Add(now.Sub(anchor) / interval * interval), nil
+}
+
+// GetConsistencyCheckWindow returns how long the consistency check is
+// allowed to run in every window.
+func (cluster *FoundationDBCluster) GetConsistencyCheckWindow() time.Duration {
+	if cluster.Spec.ConsistencyCheck.WindowSeconds == nil {
+		return time.Hour
+	}
+
+	return time.Duration(*cluster.Spec.ConsistencyCheck.WindowSeconds) * time.Second
+}
+
+// AuditLogConfig defines how the operator records the actions it takes on a
+// cluster.
+type AuditLogConfig struct {
+	// Enabled determines whether the operator records its actions in the
+	// audit log config map for the cluster.
+	// The default is false.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// MaxEntries defines how many entries the operator keeps in the audit
+	// log. Older entries are removed when the limit is reached.
+	// The default is 1000.
+	// +kubebuilder:validation:Minimum=1
+	MaxEntries *int `json:"maxEntries,omitempty"`
+}
+
+// ShouldWriteAuditLog determines whether the operator should record its
+// actions in the audit log.
+func (cluster *FoundationDBCluster) ShouldWriteAuditLog() bool {
+	enabled := cluster.Spec.AuditLog.Enabled
+	return enabled != nil && *enabled
+}
+
+// GetAuditLogMaxEntries returns how many entries the operator keeps in the
+// audit log.
+func (cluster *FoundationDBCluster) GetAuditLogMaxEntries() int {
+	if cluster.Spec.AuditLog.MaxEntries == nil {
+		return 1000
+	}
+
+	return *cluster.Spec.AuditLog.MaxEntries
+}
+
+// BinaryChecksums defines the expected checksums of the binaries for a
+// version, in the form of binary name to the hex-encoded SHA-256 checksum.
+type BinaryChecksums map[string]string
+
+// GetBinaryChecksums returns the expected checksums of the binaries for a
+// version.
+func (cluster *FoundationDBCluster) GetBinaryChecksums(version string) BinaryChecksums {
+	return cluster.Spec.BinaryChecksums[version]
+}
+
+// ProcessRestartOptions defines how fdbmonitor restarts the fdbserver
+// processes when they exit. These options are written into the general
+// section of the monitor conf, and a change to them bounces the processes.
+// They are not supported with the unified image.
+type ProcessRestartOptions struct {
+	// RestartDelaySeconds defines the maximum time that fdbmonitor waits
+	// before restarting a process that has exited.
+	// The default is 60.
+	// +kubebuilder:validation:Minimum=0
+	RestartDelaySeconds *int `json:"restartDelaySeconds,omitempty"`
+
+	// InitialRestartDelaySeconds defines the time that fdbmonitor waits
+	// before restarting a process the first time it exits.
+	// If this is not set, fdbmonitor will use its default of 0.
+	// +kubebuilder:validation:Minimum=0
+	InitialRestartDelaySeconds *int `json:"initialRestartDelaySeconds,omitempty"`
+
+	// RestartBackoff defines the factor by which fdbmonitor increases the
+	// restart delay every time a process exits again, up to the
+	// RestartDelaySeconds.
+	// If this is not set, fdbmonitor will use the RestartDelaySeconds as
+	// the factor, which means the delay is increased to the maximum after
+	// the first restart.
+	// +kubebuilder:validation:Minimum=1
+	RestartBackoff *int `json:"restartBackoff,omitempty"`
+
+	// RestartDelayResetIntervalSeconds defines how long a process has to run
+	// before fdbmonitor resets its restart delay to the initial delay.
+	// If this is not set, fdbmonitor will use the RestartDelaySeconds.
+	// +kubebuilder:validation:Minimum=0
+	RestartDelayResetIntervalSeconds *int `json:"restartDelayResetIntervalSeconds,omitempty"`
+}
+
+// GetRestartDelaySeconds returns the maximum time that fdbmonitor waits
+// before restarting a process, or 60 if unset.
+func (cluster *FoundationDBCluster) GetRestartDelaySeconds() int {
+	if cluster.Spec.ProcessRestartOptions.RestartDelaySeconds == nil {
+		return 60
+	}
+
+	return *cluster.Spec.ProcessRestartOptions.RestartDelaySeconds
+}
+
+// SeedBackup defines a backup that the operator restores into a new cluster.
+type SeedBackup struct {
+	// BackupURL provides the URL for the backup.
+	BackupURL string `json:"backupURL"`
+
+	// KeyRanges defines the key ranges to restore.
+	// If this is empty, the whole backup is restored.
+	KeyRanges []FoundationDBKeyRange `json:"keyRanges,omitempty"`
+
+	// TargetVersion defines the version of the database that the restore
+	// should restore to.
+	// +kubebuilder:validation:Minimum=0
+	TargetVersion *int64 `json:"targetVersion,omitempty"`
+
+	// TargetTimestamp defines the point in time that the restore should
+	// restore to.
+	TargetTimestamp *metav1.Time `json:"targetTimestamp,omitempty"`
+
+	// AgentCount defines the number of backup agents that the operator runs
+	// for the restore.
+	// The default is 2.
+	AgentCount *int `json:"agentCount,omitempty"`
+
+	// PodTemplateSpec allows customizing the pods of the backup agents that
+	// the operator runs for the restore.
+	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
+
+	// BlobCredentialsSecret defines the secret with the credentials that
+	// the backup agents use to access the object store.
+	BlobCredentialsSecret string `json:"blobCredentialsSecret,omitempty"`
+}
+
+// NeedsSeedRestore determines whether the operator should restore the seed
+// backup into the cluster.
+func (cluster *FoundationDBCluster) NeedsSeedRestore() bool {
+	return cluster.Spec.SeedBackup != nil && cluster.Status.SeedRestore == ""
+}
+
+// LocalityExclusion defines a locality whose processes are excluded from the
+// database.
+type LocalityExclusion struct {
+	// Key provides the locality key, which is zoneid, dcid or data_hall.
+	// +kubebuilder:validation:Enum=zoneid;dcid;data_hall
+	Key string `json:"key"`
+
+	// Value provides the value of the locality. This may only contain
+	// letters, digits, dots, dashes and underscores.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_.\-]+$`
+	Value string `json:"value"`
+}
+
+// String formats the locality exclusion in the format that fdbcli uses.
+func (exclusion LocalityExclusion) String() string {
+	return fmt.Sprintf("locality_%s:%s", exclusion.Key, exclusion.Value)
+}
+
+// Matches determines whether a process with the given localities is part of
+// the excluded locality.
+func (exclusion LocalityExclusion) Matches(locality map[string]string) bool {
+	return locality[exclusion.Key] == exclusion.Value
+}
+
+// LocalityExclusionStatus provides the progress of a locality exclusion.
+type LocalityExclusionStatus struct {
+	LocalityExclusion `json:",inline"`
+
+	// Processes provides the number of processes in the locality.
+	Processes int `json:"processes,omitempty"`
+
+	// RemainingProcesses provides the number of processes in the locality
+	// that are not excluded yet or still have roles in the database.
+	RemainingProcesses int `json:"remainingProcesses,omitempty"`
+}
+
+// DefaultTraceLogDirectory provides the default directory for the trace logs
+// of the FoundationDB processes.
+const DefaultTraceLogDirectory = "/var/log/fdb-trace-logs"
+
+// TraceLogConfig allows configuring the trace logs of the FoundationDB
+// processes.
+type TraceLogConfig struct {
+	// Directory defines the directory inside the main container where the
+	// processes write their trace logs.
+	// The default is /var/log/fdb-trace-logs.
+	Directory string `json:"directory,omitempty"`
+
+	// Format defines the format of the trace log files.
+	// If this is not set, fdbserver will use its default format.
+	// +kubebuilder:validation:Enum=xml;json
+	Format string `json:"format,omitempty"`
+
+	// RollSizeBytes defines the size of a trace log file in bytes at which
+	// the process rolls over to a new file.
+	// If this is not set, fdbserver will use its default roll size.
+	// +kubebuilder:validation:Minimum=1
+	RollSizeBytes *int64 `json:"rollSizeBytes,omitempty"`
+
+	// MaxLogsSizeBytes defines the total size of all trace log files in bytes
+	// at which the process deletes the oldest files.
+	// If this is not set, fdbserver will use its default retention.
+	// +kubebuilder:validation:Minimum=1
+	MaxLogsSizeBytes *int64 `json:"maxLogsSizeBytes,omitempty"`
+
+	// LogForwarder defines a container that runs next to the FoundationDB
+	// processes and ships the trace logs to a logging pipeline. The operator
+	// mounts the trace log directory into this container.
+	// If this is not set, no log forwarder will be run.
+	LogForwarder *corev1.Container `json:"logForwarder,omitempty"`
+
+	// VolumeSizeLimit defines the maximum size of the emptyDir volume that
+	// holds the trace logs. The kubelet evicts the pod when the trace logs
+	// grow beyond this size.
+	// This is ignored when a VolumeClaimTemplate is set.
+	// If this is not set, the volume has no size limit.
+	VolumeSizeLimit *resource.Quantity `json:"volumeSizeLimit,omitempty"`
+
+	// VolumeClaimTemplate defines a template for a volume claim that holds
+	// the trace logs, so they are kept on a different disk than the data.
+	// The claim is created through a generic ephemeral volume, so it is
+	// created and deleted together with the pod.
+	// If this is not set, the trace logs are stored in an emptyDir volume.
+	VolumeClaimTemplate *corev1.PersistentVolumeClaimTemplate `json:"volumeClaimTemplate,omitempty"`
+}
+
+// GetTraceLogDirectory provides the directory where the FoundationDB
+// processes write their trace logs.
+func (cluster *FoundationDBCluster) GetTraceLogDirectory() string {
+	if cluster.Spec.TraceLogs.Directory == "" {
+		return DefaultTraceLogDirectory
+	}
+
+	return cluster.Spec.TraceLogs.Directory
+}
+
+// GetTraceLogVolumeSource provides the source for the volume that holds the
+// trace logs.
+func (cluster *FoundationDBCluster) GetTraceLogVolumeSource() corev1.VolumeSource {
+	if cluster.Spec.TraceLogs.VolumeClaimTemplate != nil {
+		return corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{
+			VolumeClaimTemplate: cluster.Spec.TraceLogs.VolumeClaimTemplate.DeepCopy(),
+		}}
+	}
+
+	emptyDir := &corev1.EmptyDirVolumeSource{}
+	if cluster.Spec.TraceLogs.VolumeSizeLimit != nil {
+		sizeLimit := cluster.Spec.TraceLogs.VolumeSizeLimit.DeepCopy()
+		emptyDir.SizeLimit = &sizeLimit
+	}
+
+	return corev1.VolumeSource{EmptyDir: emptyDir}
+}
+
+// DeletionOptions defines how the operator tears down a cluster when the
+// cluster resource is deleted, and which resources it keeps when process
+// groups are removed.
+type DeletionOptions struct {
+	// Graceful defines whether the operator should add a finalizer to the
+	// cluster and delete the pods, services, PVCs, and ConfigMaps in a safe
+	// order when the cluster is deleted.
+	// The default is false.
+	Graceful *bool `json:"graceful,omitempty"`
+
+	// ProtectData defines whether the operator should refuse to delete a
+	// cluster that still has data unless the cluster has the
+	// foundationdb.org/confirm-destructive-delete annotation.
+	// Enabling this also enables graceful deletion.
+	// The default is false.
+	ProtectData *bool `json:"protectData,omitempty"`
+
+	// PVCRetentionPolicy defines whether the operator deletes or retains the
+	// PVCs of process groups that are removed and of the cluster when it is
+	// deleted.
+	// With the RetainUnexcluded policy the operator only deletes the PVCs of
+	// process groups that were fully excluded before they were removed, and
+	// deletes all PVCs when the cluster is deleted.
+	// Retained PVCs are released from the cluster and get the
+	// foundationdb.org/retained-from-cluster label.
+	// Retaining PVCs when the cluster is deleted also enables graceful
+	// deletion.
+	// The default is RetainUnexcluded.
+	PVCRetentionPolicy *ResourceRetentionPolicy `json:"pvcRetentionPolicy,omitempty"`
+
+	// ServiceRetentionPolicy defines whether the operator deletes or retains
+	// the per-pod services of process groups that are removed and of the
+	// cluster when it is deleted.
+	// The default is Delete.
+	ServiceRetentionPolicy *ResourceRetentionPolicy `json:"serviceRetentionPolicy,omitempty"`
+}
+
+// ResourceRetentionPolicy defines whether the operator deletes or retains
+// the resources of a process group when the process group is removed.
+// +kubebuilder:validation:Enum=Delete;Retain;RetainUnexcluded
+type ResourceRetentionPolicy string
+
+const (
+	// ResourceRetentionPolicyDelete deletes the resources.
+	ResourceRetentionPolicyDelete ResourceRetentionPolicy = "Delete"
+
+	// ResourceRetentionPolicyRetain retains the resources.
+	ResourceRetentionPolicyRetain ResourceRetentionPolicy = "Retain"
+
+	// ResourceRetentionPolicyRetainUnexcluded retains the resources of
+	// process groups that were removed without being excluded, and deletes
+	// the resources of all other process groups.
+	ResourceRetentionPolicyRetainUnexcluded ResourceRetentionPolicy = "RetainUnexcluded"
+)
+
+// GetPVCRetentionPolicy returns the retention policy for the PVCs of the
+// cluster.
+func (cluster *FoundationDBCluster) GetPVCRetentionPolicy() ResourceRetentionPolicy {
+	policy := cluster.Spec.DeletionOptions.PVCRetentionPolicy
+	if policy == nil {
+		return ResourceRetentionPolicyRetainUnexcluded
+	}
+
+	return *policy
+}
+
+// GetServiceRetentionPolicy returns the retention policy for the per-pod
+// services of the cluster.
+func (cluster *FoundationDBCluster) GetServiceRetentionPolicy() ResourceRetentionPolicy {
+	policy := cluster.Spec.DeletionOptions.ServiceRetentionPolicy
+	if policy == nil {
+		return ResourceRetentionPolicyDelete
+	}
+
+	return *policy
+}
+
+// RetainsRemovedResource determines whether the policy retains the resources
+// of a process group that is being removed.
+func (policy ResourceRetentionPolicy) RetainsRemovedResource(processGroup *ProcessGroupStatus) bool {
+	switch policy {
+	case ResourceRetentionPolicyRetain:
+		return true
+	case ResourceRetentionPolicyRetainUnexcluded:
+		return processGroup == nil || !processGroup.WasExcluded()
+	default:
+		return false
+	}
+}
+
+// RetainsResourceOnDeletion determines whether the policy retains the
+// resources of the cluster when the cluster is deleted.
+func (policy ResourceRetentionPolicy) RetainsResourceOnDeletion() bool {
+	return policy == ResourceRetentionPolicyRetain
+}
+
+// UseGracefulDeletion determines whether the operator should use a finalizer
+// to tear down the cluster when it is deleted.
+func (cluster *FoundationDBCluster) UseGracefulDeletion() bool {
+	// The operator does not own the resources of clusters that it only
+	// observes, so there is nothing to tear down on deletion.
+	if cluster.Spec.ObserveOnly {
+		return false
+	}
+
+	graceful := cluster.Spec.DeletionOptions.Graceful
+	if graceful != nil && *graceful {
+		return true
+	}
+
+	return cluster.ProtectDataOnDeletion() ||
+		cluster.GetPVCRetentionPolicy().RetainsResourceOnDeletion() ||
+		cluster.GetServiceRetentionPolicy().RetainsResourceOnDeletion()
+}
+
+// ProtectDataOnDeletion determines whether the operator should refuse to
+// delete a cluster that still has data.
+func (cluster *FoundationDBCluster) ProtectDataOnDeletion() bool {
+	protectData := cluster.Spec.DeletionOptions.ProtectData
+	return protectData != nil && *protectData
+}
+
+// IsDestructiveDeleteConfirmed determines whether the user has confirmed
+// that the cluster can be deleted even if it still has data.
+func (cluster *FoundationDBCluster) IsDestructiveDeleteConfirmed() bool {
+	return cluster.Annotations[ConfirmDestructiveDeleteAnnotation] == "true"
+}
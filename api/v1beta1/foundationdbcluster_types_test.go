@@ -29,7 +29,9 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -131,6 +133,37 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 				LogRouters: 6,
 			}))
 		})
+
+		It("should apply the recommended role counts", func() {
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					DatabaseConfiguration: DatabaseConfiguration{
+						RedundancyMode: RedundancyModeDouble,
+						RoleCounts: RoleCounts{
+							Proxies: 5,
+						},
+					},
+				},
+				Status: FoundationDBClusterStatus{
+					RecommendedRoleCounts: &RoleCounts{
+						Logs:    4,
+						Proxies: 4,
+					},
+				},
+			}
+
+			Expect(cluster.GetRoleCountsWithDefaults().Logs).To(Equal(3))
+
+			enabled := true
+			cluster.Spec.AutomationOptions.RoleCountRecommendations.Apply = &enabled
+			Expect(cluster.GetRoleCountsWithDefaults().Logs).To(Equal(3))
+
+			cluster.Spec.AutomationOptions.RoleCountRecommendations.Enabled = &enabled
+			counts := cluster.GetRoleCountsWithDefaults()
+			Expect(counts.Logs).To(Equal(4))
+			Expect(counts.Proxies).To(Equal(5))
+			Expect(counts.Resolvers).To(Equal(1))
+		})
 	})
 
 	When("getting the default process counts", func() {
@@ -166,6 +199,7 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 				ProcessClassLog:       4,
 				ProcessClassStateless: 9,
 			}))
+			Expect(counts.Total()).To(Equal(18))
 			Expect(cluster.Spec.ProcessCounts).To(Equal(ProcessCounts{}))
 
 			cluster.Spec.ProcessCounts = ProcessCounts{
@@ -207,6 +241,27 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 			Expect(counts.Resolution).To(Equal(1))
 			Expect(counts.Resolver).To(Equal(0))
 
+			cluster.Spec.ProcessCounts = ProcessCounts{
+				CommitProxy: 3,
+				GrvProxy:    1,
+			}
+			counts, err = cluster.GetProcessCountsWithDefaults()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counts.Stateless).To(Equal(9))
+
+			cluster.Spec.Version = Versions.NextMajorVersion.String()
+			counts, err = cluster.GetProcessCountsWithDefaults()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(counts.Stateless).To(Equal(6))
+			Expect(counts.Map()).To(Equal(map[ProcessClass]int{
+				ProcessClassStorage:     5,
+				ProcessClassLog:         4,
+				ProcessClassStateless:   6,
+				ProcessClassCommitProxy: 3,
+				ProcessClassGrvProxy:    1,
+			}))
+			cluster.Spec.Version = Versions.Default.String()
+
 			cluster.Spec.ProcessCounts = ProcessCounts{
 				Log: 2,
 			}
@@ -462,6 +517,29 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 			Expect(cluster.MinimumFaultDomains()).To(Equal(2))
 			Expect(cluster.DesiredCoordinatorCount()).To(Equal(9))
 		})
+
+		It("should respect the coordinator count override", func() {
+			cluster := &FoundationDBCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "default",
+				},
+			}
+
+			coordinatorCount := 5
+			cluster.Spec.CoordinatorCount = &coordinatorCount
+			Expect(cluster.DesiredCoordinatorCount()).To(Equal(5))
+
+			coordinatorCount = 1
+			Expect(cluster.DesiredCoordinatorCount()).To(Equal(3))
+
+			cluster.Spec.DatabaseConfiguration.UsableRegions = 2
+			coordinatorCount = 11
+			Expect(cluster.DesiredCoordinatorCount()).To(Equal(11))
+
+			coordinatorCount = 7
+			Expect(cluster.DesiredCoordinatorCount()).To(Equal(9))
+		})
 	})
 
 	When("parsing the backup status for 6.2", func() {
@@ -483,6 +561,27 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 		})
 	})
 
+	When("parsing the backup description for 6.3", func() {
+		It("should be parsed correctly", func() {
+			descriptionFile, err := os.OpenFile(filepath.Join("testdata", "fdbbackup_describe_6_3.json"), os.O_RDONLY, os.ModePerm)
+			Expect(err).NotTo(HaveOccurred())
+			defer descriptionFile.Close()
+			descriptionDecoder := json.NewDecoder(descriptionFile)
+			description := FoundationDBBackupDescription{}
+			err = descriptionDecoder.Decode(&description)
+			Expect(err).NotTo(HaveOccurred())
+
+			minTimestamp := float64(1616407320)
+			maxTimestamp := float64(1616410800)
+			Expect(description).To(Equal(FoundationDBBackupDescription{
+				URL:                "blobstore://minio@minio-service:9000/sample-cluster?bucket=fdb-backups",
+				Restorable:         true,
+				MinRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1000120000000, EpochSeconds: &minTimestamp},
+				MaxRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1004000000000, EpochSeconds: &maxTimestamp},
+			}))
+		})
+	})
+
 	coordinators := []ProcessAddress{
 		{
 			IPAddress: net.ParseIP("127.0.0.1"),
@@ -518,6 +617,34 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 		})
 	})
 
+	When("parsing a connection string with TLS coordinators", func() {
+		It("should keep the flags of the coordinators", func() {
+			str, err := ParseConnectionString("test:abcd@127.0.0.1:4500:tls,127.0.0.2:4500:tls,[::1]:4500:tls")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(str.Coordinators).To(Equal([]string{
+				"127.0.0.1:4500:tls",
+				"127.0.0.2:4500:tls",
+				"[::1]:4500:tls",
+			}))
+
+			addresses, err := str.CoordinatorAddresses()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(addresses).To(HaveLen(3))
+			Expect(addresses[0].IPAddress.String()).To(Equal("127.0.0.1"))
+			Expect(addresses[0].Port).To(Equal(4500))
+			Expect(addresses[0].Flags).To(Equal(map[string]bool{"tls": true}))
+			Expect(addresses[2].IPAddress.String()).To(Equal("::1"))
+			Expect(addresses[2].StringWithoutFlags()).To(Equal("[::1]:4500"))
+		})
+	})
+
+	When("parsing a connection string with an invalid coordinator", func() {
+		It("should return an error", func() {
+			_, err := ParseConnectionString("test:abcd@127.0.0.1:4500,127.0.0.2:bad")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	When("formatting the connection string", func() {
 		It("should be formatted correctly", func() {
 			str := ConnectionString{
@@ -540,6 +667,18 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(len(str.GenerationID)).To(Equal(32))
 		})
+
+		It("should generate a different ID every time", func() {
+			str := ConnectionString{}
+			ids := make(map[string]bool)
+			for i := 0; i < 100; i++ {
+				err := str.GenerateNewGenerationID()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(str.GenerationID).To(MatchRegexp("^[A-Za-z0-9]{32}$"))
+				ids[str.GenerationID] = true
+			}
+			Expect(ids).To(HaveLen(100))
+		})
 	})
 
 	When("checking the coordinators for the connection string", func() {
@@ -620,6 +759,37 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 		})
 	})
 
+	When("getting the default database configuration with storage wiggle settings", func() {
+		var cluster *FoundationDBCluster
+
+		BeforeEach(func() {
+			perpetualStorageWiggle := 1
+			cluster = &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					DatabaseConfiguration: DatabaseConfiguration{
+						PerpetualStorageWiggle: &perpetualStorageWiggle,
+						StorageMigrationType:   StorageMigrationTypeGradual,
+					},
+				},
+			}
+		})
+
+		It("should keep the settings for versions that support them", func() {
+			cluster.Spec.Version = "7.0.0"
+			configuration := cluster.DesiredDatabaseConfiguration()
+			Expect(configuration.PerpetualStorageWiggle).NotTo(BeNil())
+			Expect(*configuration.PerpetualStorageWiggle).To(Equal(1))
+			Expect(configuration.StorageMigrationType).To(Equal(StorageMigrationTypeGradual))
+		})
+
+		It("should clear the settings for versions that do not support them", func() {
+			cluster.Spec.Version = "6.3.12"
+			configuration := cluster.DesiredDatabaseConfiguration()
+			Expect(configuration.PerpetualStorageWiggle).To(BeNil())
+			Expect(configuration.StorageMigrationType).To(BeEmpty())
+		})
+	})
+
 	When("getting the  configuration string", func() {
 		It("should be parsed correctly", func() {
 			configuration := DatabaseConfiguration{
@@ -645,6 +815,12 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 
 			configuration.VersionFlags.LogSpill = 3
 			Expect(configuration.GetConfigurationString()).To(Equal("double ssd usable_regions=1 logs=5 proxies=0 resolvers=0 log_routers=0 remote_logs=0 log_spill:=3 regions=[]"))
+			configuration.VersionFlags.LogSpill = 0
+
+			perpetualStorageWiggle := 1
+			configuration.PerpetualStorageWiggle = &perpetualStorageWiggle
+			configuration.StorageMigrationType = StorageMigrationTypeGradual
+			Expect(configuration.GetConfigurationString()).To(Equal("double ssd usable_regions=1 logs=5 proxies=0 resolvers=0 log_routers=0 remote_logs=0 regions=[] perpetual_storage_wiggle=1 storage_migration_type=gradual"))
 		})
 	})
 
@@ -2319,6 +2495,17 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 					expectedStr: "::1",
 					err:         nil,
 				}),
+			Entry("IPv6 in brackets without port",
+				testCase{
+					input: "[2001:db8::ff00:42:8329]",
+					expectedAddr: ProcessAddress{
+						IPAddress: net.ParseIP("2001:db8::ff00:42:8329"),
+						Port:      0,
+						Flags:     nil,
+					},
+					expectedStr: "2001:db8::ff00:42:8329",
+					err:         nil,
+				}),
 			Entry("IPv6 with bad port",
 				testCase{
 					input: "[::1]:bad",
@@ -2828,6 +3015,82 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 		)
 	})
 
+	When("getting the process port offset", func() {
+		var cluster *FoundationDBCluster
+
+		BeforeEach(func() {
+			cluster = &FoundationDBCluster{
+				Status: FoundationDBClusterStatus{
+					RequiredAddresses: RequiredAddressSet{NonTLS: true},
+				},
+			}
+		})
+
+		It("should not use an offset without the host network", func() {
+			Expect(cluster.UseHostNetwork()).To(BeFalse())
+			Expect(cluster.GetProcessPortOffset(ProcessClassLog)).To(Equal(0))
+			Expect(cluster.GetFullAddress("1.1.1.1", ProcessClassLog, 1).String()).To(Equal("1.1.1.1:4501"))
+		})
+
+		When("using the host network", func() {
+			BeforeEach(func() {
+				enabled := true
+				cluster.Spec.Routing.UseHostNetwork = &enabled
+			})
+
+			It("should use a separate port range for each process class", func() {
+				Expect(cluster.UseHostNetwork()).To(BeTrue())
+				Expect(cluster.GetProcessPortOffset(ProcessClassStorage)).To(Equal(0))
+				Expect(cluster.GetProcessPortOffset(ProcessClassLog)).To(Equal(100))
+				Expect(cluster.GetProcessPortOffset(ProcessClassStateless)).To(Equal(300))
+				Expect(cluster.GetFullAddress("1.1.1.1", ProcessClassStorage, 1).String()).To(Equal("1.1.1.1:4501"))
+				Expect(cluster.GetFullAddress("1.1.1.1", ProcessClassLog, 2).String()).To(Equal("1.1.1.1:4603"))
+			})
+
+			It("should only have port ranges for the known process classes", func() {
+				Expect(ProcessClassCoordinator.HasHostNetworkPortRange()).To(BeTrue())
+				Expect(ProcessClass("custom").HasHostNetworkPortRange()).To(BeFalse())
+			})
+
+			It("should have a separate port range for every process class in the process counts", func() {
+				offsets := map[int]ProcessClass{}
+				for processClass := range processClassIndices {
+					if processClass == "unset" {
+						continue
+					}
+
+					Expect(processClass.HasHostNetworkPortRange()).To(BeTrue(), string(processClass))
+					offset := cluster.GetProcessPortOffset(processClass)
+					Expect(offsets).NotTo(HaveKey(offset), string(processClass))
+					offsets[offset] = processClass
+				}
+			})
+
+			It("should fit fewer processes into the port range with a larger port stride", func() {
+				Expect(cluster.GetMaxHostNetworkServersPerPod()).To(Equal(50))
+				stride := 3
+				cluster.Spec.Routing.PortStride = &stride
+				Expect(cluster.GetMaxHostNetworkServersPerPod()).To(Equal(33))
+			})
+		})
+
+		When("setting a base port and a port stride", func() {
+			BeforeEach(func() {
+				basePort := 5000
+				stride := 4
+				cluster.Spec.Routing.BasePort = &basePort
+				cluster.Spec.Routing.PortStride = &stride
+			})
+
+			It("should use the base port and port stride for the processes", func() {
+				Expect(cluster.GetProcessPortForClass(ProcessClassStorage, 1, true)).To(Equal(5000))
+				Expect(cluster.GetProcessPortForClass(ProcessClassStorage, 1, false)).To(Equal(5001))
+				Expect(cluster.GetProcessPortForClass(ProcessClassStorage, 3, true)).To(Equal(5008))
+				Expect(cluster.GetFullAddress("1.1.1.1", ProcessClassLog, 2).String()).To(Equal("1.1.1.1:5005"))
+			})
+		})
+	})
+
 	When("adding StorageServerPerDisk", func() {
 		type testCase struct {
 			ValuesToAdd                   []int
@@ -2869,6 +3132,40 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 		)
 	})
 
+	When("adding servers per disk for a process class", func() {
+		It("should only add the value for the matching process class", func() {
+			status := FoundationDBClusterStatus{}
+
+			status.AddServersPerDisk(2, ProcessClassLog)
+			status.AddServersPerDisk(3, ProcessClassTransaction)
+			status.AddServersPerDisk(2, ProcessClassLog)
+			status.AddServersPerDisk(4, ProcessClassStorage)
+			status.AddServersPerDisk(5, ProcessClassStateless)
+
+			Expect(status.LogServersPerDisk).To(Equal([]int{2, 3}))
+			Expect(status.StorageServersPerDisk).To(Equal([]int{4}))
+		})
+	})
+
+	When("getting the desired servers per Pod", func() {
+		It("should return the setting for the process class", func() {
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					StorageServersPerPod: 2,
+					LogServersPerPod:     3,
+				},
+			}
+
+			Expect(cluster.GetDesiredServersPerPod(ProcessClassStorage)).To(Equal(2))
+			Expect(cluster.GetDesiredServersPerPod(ProcessClassLog)).To(Equal(3))
+			Expect(cluster.GetDesiredServersPerPod(ProcessClassTransaction)).To(Equal(3))
+			Expect(cluster.GetDesiredServersPerPod(ProcessClassStateless)).To(Equal(1))
+
+			cluster.Spec.LogServersPerPod = 0
+			Expect(cluster.GetDesiredServersPerPod(ProcessClassLog)).To(Equal(1))
+		})
+	})
+
 	When("adding addresses to a process group", func() {
 		type testCase struct {
 			initialProcessGroup  ProcessGroupStatus
@@ -3021,6 +3318,12 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 					pClass:   ProcessClassTransaction,
 					expected: true,
 				}),
+			Entry("coordinator class without any configuration is eligible",
+				testCase{
+					cluster:  &FoundationDBCluster{},
+					pClass:   ProcessClassCoordinator,
+					expected: true,
+				}),
 			Entry("stateless class without any configuration is not eligible",
 				testCase{
 					cluster:  &FoundationDBCluster{},
@@ -3095,6 +3398,12 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 					pClass:   ProcessClassTransaction,
 					expected: math.MinInt64,
 				}),
+			Entry("coordinator class without any configuration returns the highest priority",
+				testCase{
+					cluster:  &FoundationDBCluster{},
+					pClass:   ProcessClassCoordinator,
+					expected: math.MaxInt64,
+				}),
 			Entry("stateless class without any configuration highest priority",
 				testCase{
 					cluster:  &FoundationDBCluster{},
@@ -3137,6 +3446,25 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 					pClass:   ProcessClassLog,
 					expected: math.MinInt64,
 				}),
+			Entry("coordinator class with a configured priority returns the configured priority",
+				testCase{
+					cluster: &FoundationDBCluster{
+						Spec: FoundationDBClusterSpec{
+							CoordinatorSelection: []CoordinatorSelectionSetting{
+								{
+									ProcessClass: ProcessClassStorage,
+									Priority:     10,
+								},
+								{
+									ProcessClass: ProcessClassCoordinator,
+									Priority:     1,
+								},
+							},
+						},
+					},
+					pClass:   ProcessClassCoordinator,
+					expected: 1,
+				}),
 		)
 	})
 
@@ -3157,6 +3485,12 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 			Expect(cluster.NeedsExplicitListenAddress()).To(BeTrue())
 		})
 
+		It("is required with a node as the public IP", func() {
+			source := PublicIPSourceNode
+			cluster.Spec.Routing.PublicIPSource = &source
+			Expect(cluster.NeedsExplicitListenAddress()).To(BeTrue())
+		})
+
 		It("is not required with a pod as the public IP", func() {
 			source := PublicIPSourcePod
 			cluster.Spec.Routing.PublicIPSource = &source
@@ -3176,6 +3510,200 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 		})
 	})
 
+	When("getting the public service type", func() {
+		It("should return ClusterIP per default", func() {
+			cluster := &FoundationDBCluster{}
+			Expect(cluster.GetPublicServiceType()).To(Equal(corev1.ServiceTypeClusterIP))
+		})
+
+		It("should return the configured service type", func() {
+			serviceType := corev1.ServiceTypeLoadBalancer
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					Routing: RoutingConfig{
+						PublicServiceType: &serviceType,
+					},
+				},
+			}
+			Expect(cluster.GetPublicServiceType()).To(Equal(corev1.ServiceTypeLoadBalancer))
+		})
+	})
+
+	When("getting the public service type for a process class", func() {
+		var cluster *FoundationDBCluster
+
+		BeforeEach(func() {
+			source := PublicIPSourceService
+			serviceType := corev1.ServiceTypeNodePort
+			cluster = &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					Routing: RoutingConfig{
+						PublicIPSource:    &source,
+						PublicServiceType: &serviceType,
+					},
+				},
+			}
+		})
+
+		It("should use the public service type for all classes per default", func() {
+			Expect(cluster.GetPublicServiceTypeForClass(ProcessClassStorage)).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(cluster.GetPublicServiceTypeForClass(ProcessClassStateless)).To(Equal(corev1.ServiceTypeNodePort))
+			Expect(cluster.UsesNodePorts(ProcessClassStateless)).To(BeTrue())
+		})
+
+		When("only exposing the coordinators", func() {
+			BeforeEach(func() {
+				scope := PublicServiceScopeCoordinators
+				cluster.Spec.Routing.PublicServiceScope = &scope
+				cluster.Spec.CoordinatorSelection = []CoordinatorSelectionSetting{{ProcessClass: ProcessClassLog}}
+			})
+
+			It("should only use the public service type for the coordinator classes", func() {
+				Expect(cluster.GetPublicServiceTypeForClass(ProcessClassLog)).To(Equal(corev1.ServiceTypeNodePort))
+				Expect(cluster.GetPublicServiceTypeForClass(ProcessClassStorage)).To(Equal(corev1.ServiceTypeClusterIP))
+				Expect(cluster.GetPublicServiceTypeForClass(ProcessClassStateless)).To(Equal(corev1.ServiceTypeClusterIP))
+				Expect(cluster.UsesNodePorts(ProcessClassLog)).To(BeTrue())
+				Expect(cluster.UsesNodePorts(ProcessClassStorage)).To(BeFalse())
+			})
+		})
+
+		It("should not use node ports with the pod IP source", func() {
+			source := PublicIPSourcePod
+			cluster.Spec.Routing.PublicIPSource = &source
+			Expect(cluster.UsesNodePorts(ProcessClassStorage)).To(BeFalse())
+		})
+	})
+
+	When("getting the fault domain spread mode", func() {
+		It("should return Preferred per default", func() {
+			cluster := &FoundationDBCluster{}
+			Expect(cluster.GetFaultDomainSpreadMode()).To(Equal(FaultDomainSpreadModePreferred))
+		})
+
+		It("should return the configured spread mode", func() {
+			mode := FaultDomainSpreadModeTopologySpread
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					FaultDomain: FoundationDBClusterFaultDomain{
+						SpreadMode: &mode,
+					},
+				},
+			}
+			Expect(cluster.GetFaultDomainSpreadMode()).To(Equal(FaultDomainSpreadModeTopologySpread))
+		})
+	})
+
+	When("getting the image type", func() {
+		It("should use the split image per default", func() {
+			cluster := &FoundationDBCluster{}
+			Expect(cluster.GetImageType()).To(Equal(ImageTypeSplit))
+			Expect(cluster.UseUnifiedImage()).To(BeFalse())
+		})
+
+		It("should return the configured image type", func() {
+			imageType := ImageTypeUnified
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					ImageType: &imageType,
+				},
+			}
+			Expect(cluster.GetImageType()).To(Equal(ImageTypeUnified))
+			Expect(cluster.UseUnifiedImage()).To(BeTrue())
+		})
+	})
+
+	When("getting the pod update strategy", func() {
+		It("should recreate pods per default", func() {
+			cluster := &FoundationDBCluster{}
+			Expect(cluster.GetPodUpdateStrategy()).To(Equal(PodUpdateStrategyRecreate))
+		})
+
+		It("should replace pods when the deprecated field is set", func() {
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					UpdatePodsByReplacement: true,
+				},
+			}
+			Expect(cluster.GetPodUpdateStrategy()).To(Equal(PodUpdateStrategyReplacement))
+		})
+
+		It("should return the configured strategy", func() {
+			strategy := PodUpdateStrategyManual
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					UpdatePodsByReplacement: true,
+					PodUpdateStrategy:       &strategy,
+				},
+			}
+			Expect(cluster.GetPodUpdateStrategy()).To(Equal(PodUpdateStrategyManual))
+		})
+	})
+
+	When("getting the trace log volume source", func() {
+		It("should use an emptyDir volume per default", func() {
+			cluster := &FoundationDBCluster{}
+			Expect(cluster.GetTraceLogVolumeSource()).To(Equal(corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}))
+		})
+
+		It("should set the size limit on the emptyDir volume", func() {
+			sizeLimit := resource.MustParse("1Gi")
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					TraceLogs: TraceLogConfig{
+						VolumeSizeLimit: &sizeLimit,
+					},
+				},
+			}
+			Expect(cluster.GetTraceLogVolumeSource()).To(Equal(corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}}))
+		})
+
+		It("should use an ephemeral volume when a claim template is set", func() {
+			sizeLimit := resource.MustParse("1Gi")
+			template := &corev1.PersistentVolumeClaimTemplate{
+				Spec: corev1.PersistentVolumeClaimSpec{
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+					},
+				},
+			}
+			cluster := &FoundationDBCluster{
+				Spec: FoundationDBClusterSpec{
+					TraceLogs: TraceLogConfig{
+						VolumeSizeLimit:     &sizeLimit,
+						VolumeClaimTemplate: template,
+					},
+				},
+			}
+			Expect(cluster.GetTraceLogVolumeSource()).To(Equal(corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{VolumeClaimTemplate: template}}))
+		})
+	})
+
+	When("checking if pod updates are approved", func() {
+		var cluster *FoundationDBCluster
+
+		BeforeEach(func() {
+			cluster = &FoundationDBCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 3,
+				},
+			}
+		})
+
+		It("should not be approved without the annotation", func() {
+			Expect(cluster.PodUpdatesApproved()).To(BeFalse())
+		})
+
+		It("should be approved for the current generation", func() {
+			cluster.Annotations = map[string]string{ApprovePodUpdatesAnnotation: "3"}
+			Expect(cluster.PodUpdatesApproved()).To(BeTrue())
+		})
+
+		It("should not be approved for an older generation", func() {
+			cluster.Annotations = map[string]string{ApprovePodUpdatesAnnotation: "2"}
+			Expect(cluster.PodUpdatesApproved()).To(BeFalse())
+		})
+	})
+
 	When("checking whether the process group should be skipped or not", func() {
 		type testCase struct {
 			cluster  *FoundationDBCluster
@@ -3385,6 +3913,27 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 			})
 		})
 
+		Context("with a process group on a tainted node", func() {
+			BeforeEach(func() {
+				processGroup.UpdateCondition(NodeTaintReplacing, true, nil, "")
+			})
+
+			It("should need replacement without waiting for the failure time", func() {
+				Expect(needsReplacement).To(BeTrue())
+				Expect(timestamp).To(Equal(processGroup.ProcessGroupConditions[0].Timestamp))
+			})
+
+			Context("when the process group is already marked for removal", func() {
+				BeforeEach(func() {
+					processGroup.Remove = true
+				})
+
+				It("should not need replacement", func() {
+					Expect(needsReplacement).To(BeFalse())
+				})
+			})
+		})
+
 		Context("with a process group that had the wrong command line", func() {
 			BeforeEach(func() {
 				processGroup.UpdateCondition(IncorrectCommandLine, true, nil, "")
@@ -3396,4 +3945,176 @@ var _ = Describe("[api] FoundationDBCluster", func() {
 			})
 		})
 	})
+
+	When("checking for instances that are undesired", func() {
+		var processGroup *ProcessGroupStatus
+		var undesired bool
+		var timestamp int64
+		var oldTimestamp int64
+
+		BeforeEach(func() {
+			processGroup = &ProcessGroupStatus{ProcessGroupID: "storage-1", ProcessClass: "storage"}
+			oldTimestamp = time.Now().Add(-1 * time.Hour).Unix()
+		})
+
+		JustBeforeEach(func() {
+			undesired, timestamp = processGroup.IsUndesired(60)
+		})
+
+		Context("with a process group that went missing before the window", func() {
+			BeforeEach(func() {
+				processGroup.UpdateCondition(MissingProcesses, true, nil, "")
+				processGroup.ProcessGroupConditions[0].Timestamp = oldTimestamp
+			})
+
+			It("should not be undesired", func() {
+				Expect(undesired).To(BeFalse())
+			})
+		})
+
+		Context("with a process group that reported IO errors after the window", func() {
+			BeforeEach(func() {
+				processGroup.UpdateCondition(ProcessHasIOError, true, nil, "")
+			})
+
+			It("should not be undesired", func() {
+				Expect(undesired).To(BeFalse())
+			})
+		})
+
+		Context("with a process group that has been lagging before the window", func() {
+			BeforeEach(func() {
+				processGroup.UpdateCondition(ProcessIsLagging, true, nil, "")
+				processGroup.ProcessGroupConditions[0].Timestamp = oldTimestamp
+			})
+
+			It("should be undesired", func() {
+				Expect(undesired).To(BeTrue())
+				Expect(timestamp).To(Equal(oldTimestamp))
+			})
+
+			Context("when the process group is already marked for removal", func() {
+				BeforeEach(func() {
+					processGroup.Remove = true
+				})
+
+				It("should not be undesired", func() {
+					Expect(undesired).To(BeFalse())
+				})
+			})
+		})
+	})
+
+	When("checking if a retention policy retains the resources of a removed process group", func() {
+		type testCase struct {
+			policy       ResourceRetentionPolicy
+			processGroup *ProcessGroupStatus
+			expected     bool
+		}
+
+		DescribeTable("should return if the resources are retained",
+			func(tc testCase) {
+				Expect(tc.policy.RetainsRemovedResource(tc.processGroup)).To(Equal(tc.expected))
+			},
+			Entry("Delete with an excluded process group",
+				testCase{
+					policy:       ResourceRetentionPolicyDelete,
+					processGroup: &ProcessGroupStatus{ExclusionTimestamp: 1},
+					expected:     false,
+				}),
+			Entry("Delete with a process group that was not excluded",
+				testCase{
+					policy:       ResourceRetentionPolicyDelete,
+					processGroup: &ProcessGroupStatus{},
+					expected:     false,
+				}),
+			Entry("Retain with an excluded process group",
+				testCase{
+					policy:       ResourceRetentionPolicyRetain,
+					processGroup: &ProcessGroupStatus{ExclusionTimestamp: 1},
+					expected:     true,
+				}),
+			Entry("RetainUnexcluded with an excluded process group",
+				testCase{
+					policy:       ResourceRetentionPolicyRetainUnexcluded,
+					processGroup: &ProcessGroupStatus{ExclusionTimestamp: 1},
+					expected:     false,
+				}),
+			Entry("RetainUnexcluded with a process group that was not excluded",
+				testCase{
+					policy:       ResourceRetentionPolicyRetainUnexcluded,
+					processGroup: &ProcessGroupStatus{},
+					expected:     true,
+				}),
+			Entry("RetainUnexcluded with a process group that skipped the exclusion",
+				testCase{
+					policy:       ResourceRetentionPolicyRetainUnexcluded,
+					processGroup: &ProcessGroupStatus{ExclusionTimestamp: 1, ExclusionSkipped: true},
+					expected:     true,
+				}),
+		)
+
+		It("should retain the PVCs of process groups that were not excluded by default", func() {
+			cluster := &FoundationDBCluster{}
+			Expect(cluster.GetPVCRetentionPolicy()).To(Equal(ResourceRetentionPolicyRetainUnexcluded))
+			Expect(cluster.GetServiceRetentionPolicy()).To(Equal(ResourceRetentionPolicyDelete))
+			Expect(cluster.UseGracefulDeletion()).To(BeFalse())
+		})
+	})
+
+	When("getting the scheduled start of a consistency check window", func() {
+		type testCase struct {
+			startTime       *string
+			intervalSeconds *int
+			now             time.Time
+			expected        time.Time
+		}
+
+		DescribeTable("should return the latest scheduled start",
+			func(tc testCase) {
+				cluster := &FoundationDBCluster{
+					Spec: FoundationDBClusterSpec{
+						ConsistencyCheck: ConsistencyCheckConfig{
+							StartTime:       tc.startTime,
+							IntervalSeconds: tc.intervalSeconds,
+						},
+					},
+				}
+				scheduledStart, err := cluster.GetConsistencyCheckScheduledStart(tc.now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(scheduledStart.Equal(tc.expected)).To(BeTrue())
+			},
+			Entry("without a start time",
+				testCase{
+					now:      time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+					expected: time.Time{},
+				}),
+			Entry("after the start time",
+				testCase{
+					startTime: pointer.String("02:30"),
+					now:       time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+					expected:  time.Date(2021, 6, 1, 2, 30, 0, 0, time.UTC),
+				}),
+			Entry("before the start time",
+				testCase{
+					startTime: pointer.String("02:30"),
+					now:       time.Date(2021, 6, 1, 1, 0, 0, 0, time.UTC),
+					expected:  time.Date(2021, 5, 31, 2, 30, 0, 0, time.UTC),
+				}),
+			Entry("with a shorter interval",
+				testCase{
+					startTime:       pointer.String("02:30"),
+					intervalSeconds: pointer.Int(12 * 3600),
+					now:             time.Date(2021, 6, 1, 15, 0, 0, 0, time.UTC),
+					expected:        time.Date(2021, 6, 1, 14, 30, 0, 0, time.UTC),
+				}),
+		)
+
+		It("should reject an invalid start time", func() {
+			cluster := &FoundationDBCluster{}
+			cluster.Spec.ConsistencyCheck.StartTime = pointer.String("2:30pm")
+			_, err := cluster.GetConsistencyCheckScheduledStart(time.Now())
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -0,0 +1,246 @@
+/*
+ * foundationdbdr_types.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=fdbdr
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".metadata.generation",description="Latest generation of the spec",priority=0
+// +kubebuilder:printcolumn:name="Reconciled",type="integer",JSONPath=".status.generations.reconciled",description="Last reconciled generation of the spec",priority=0
+
+// FoundationDBDR is the Schema for the FoundationDB DR API
+type FoundationDBDR struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FoundationDBDRSpec   `json:"spec,omitempty"`
+	Status FoundationDBDRStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FoundationDBDRList contains a list of FoundationDBDR
+type FoundationDBDRList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FoundationDBDR `json:"items"`
+}
+
+// FoundationDBDRSpec describes the desired state of the DR replication
+// between two clusters.
+type FoundationDBDRSpec struct {
+	// The version of FoundationDB that the DR agents should run.
+	// +kubebuilder:validation:Pattern:=^(\d+)\.(\d+)\.(\d+)$
+	Version string `json:"version"`
+
+	// SourceClusterName defines the primary cluster that is replicated.
+	// Swapping the source and the destination cluster of a running DR will
+	// switch the replication over to the other cluster.
+	// +kubebuilder:validation:Required
+	SourceClusterName string `json:"sourceClusterName"`
+
+	// DestinationClusterName defines the secondary cluster that receives the
+	// replicated data.
+	// +kubebuilder:validation:Required
+	DestinationClusterName string `json:"destinationClusterName"`
+
+	// Tag defines the tag for the DR replication.
+	// The default is "default".
+	Tag string `json:"tag,omitempty"`
+
+	// AgentCount defines the number of DR agents to run.
+	// The default is run 2 agents.
+	// +kubebuilder:validation:Minimum=0
+	AgentCount *int `json:"agentCount,omitempty"`
+
+	// DRDeploymentMetadata allows customizing labels and annotations on the
+	// deployment for the DR agents.
+	DRDeploymentMetadata *metav1.ObjectMeta `json:"drDeploymentMetadata,omitempty"`
+
+	// PodTemplateSpec allows customizing the pod template for the DR agents.
+	PodTemplateSpec *corev1.PodTemplateSpec `json:"podTemplateSpec,omitempty"`
+
+	// CustomParameters defines additional parameters to pass to the DR
+	// agents.
+	CustomParameters []string `json:"customParameters,omitempty"`
+
+	// This setting defines if a user provided image can have it's own tag
+	// rather than getting the provided version appended.
+	// You have to ensure that the specified version in the Spec is compatible
+	// with the given version in your custom image.
+	// +kubebuilder:default:=false
+	AllowTagOverride *bool `json:"allowTagOverride,omitempty"`
+
+	// ImageConfigs allows customizing the image that we use for the DR
+	// agents.
+	ImageConfigs []ImageConfig `json:"imageConfigs,omitempty"`
+
+	// SidecarImageConfigs allows customizing the image that we use for the
+	// init container of the DR agents.
+	SidecarImageConfigs []ImageConfig `json:"sidecarImageConfigs,omitempty"`
+}
+
+// FoundationDBDRStatus describes the current status of the DR replication.
+type FoundationDBDRStatus struct {
+	// AgentCount provides the number of agents that are up-to-date, ready,
+	// and not terminated.
+	AgentCount int `json:"agentCount,omitempty"`
+
+	// DeploymentConfigured indicates whether the deployment is correctly
+	// configured.
+	DeploymentConfigured bool `json:"deploymentConfigured,omitempty"`
+
+	// DRDetails provides information about the state of the DR replication.
+	DRDetails *FoundationDBDRStatusDRDetails `json:"drDetails,omitempty"`
+
+	// Generations provides information about the latest generation to be
+	// reconciled, or to reach other stages in reconciliation.
+	Generations DRGenerationStatus `json:"generations,omitempty"`
+}
+
+// FoundationDBDRStatusDRDetails provides information about the state of the
+// DR replication.
+type FoundationDBDRStatusDRDetails struct {
+	// SourceClusterName provides the cluster that is currently replicated.
+	SourceClusterName string `json:"sourceClusterName,omitempty"`
+
+	// DestinationClusterName provides the cluster that currently receives
+	// the replicated data.
+	DestinationClusterName string `json:"destinationClusterName,omitempty"`
+
+	// Running indicates whether the DR replication is running.
+	Running bool `json:"running,omitempty"`
+
+	// CompleteCopy indicates whether the destination cluster contains a
+	// complete copy of the source cluster.
+	CompleteCopy bool `json:"completeCopy,omitempty"`
+
+	// Lag provides the time the destination cluster is behind the source
+	// cluster.
+	Lag *metav1.Duration `json:"lag,omitempty"`
+}
+
+// DRGenerationStatus stores information on which generations have reached
+// different stages in reconciliation for the DR replication.
+type DRGenerationStatus struct {
+	// Reconciled provides the last generation that was fully reconciled.
+	Reconciled int64 `json:"reconciled,omitempty"`
+
+	// NeedsDRAgentUpdate provides the last generation that could not
+	// complete reconciliation because the DR agent deployment needs to be
+	// updated.
+	NeedsDRAgentUpdate int64 `json:"needsDRAgentUpdate,omitempty"`
+
+	// NeedsDRStart provides the last generation that could not complete
+	// reconciliation because we need to start the DR replication.
+	NeedsDRStart int64 `json:"needsDRStart,omitempty"`
+
+	// NeedsDRSwitch provides the last generation that could not complete
+	// reconciliation because we need to switch the DR replication to the
+	// other cluster.
+	NeedsDRSwitch int64 `json:"needsDRSwitch,omitempty"`
+}
+
+// FoundationDBLiveDRStatus describes the live status of the DR replication,
+// as provided by the fdbdr status command.
+type FoundationDBLiveDRStatus struct {
+	// Running determines whether the DR replication is currently running.
+	Running bool
+
+	// CompleteCopy determines whether the destination contains a complete
+	// copy of the source.
+	CompleteCopy bool
+
+	// SecondsBehind provides the lag of the destination in seconds.
+	SecondsBehind float64
+}
+
+// DRTag gets the tag for the DR replication.
+// This will fill in a default value if the tag in the spec is empty.
+func (dr *FoundationDBDR) DRTag() string {
+	if dr.Spec.Tag == "" {
+		return "default"
+	}
+	return dr.Spec.Tag
+}
+
+// GetDesiredAgentCount determines how many DR agents we should run.
+func (dr *FoundationDBDR) GetDesiredAgentCount() int {
+	if dr.Spec.AgentCount == nil {
+		return 2
+	}
+	return *dr.Spec.AgentCount
+}
+
+// NeedsSwitch determines whether the DR replication is running in the
+// opposite direction of the spec.
+func (dr *FoundationDBDR) NeedsSwitch() bool {
+	details := dr.Status.DRDetails
+	return details != nil && details.Running &&
+		details.SourceClusterName == dr.Spec.DestinationClusterName &&
+		details.DestinationClusterName == dr.Spec.SourceClusterName
+}
+
+// CheckReconciliation compares the spec and the status to determine if
+// reconciliation is complete.
+func (dr *FoundationDBDR) CheckReconciliation() (bool, error) {
+	var reconciled = true
+
+	desiredAgentCount := dr.GetDesiredAgentCount()
+	if dr.Status.AgentCount != desiredAgentCount || !dr.Status.DeploymentConfigured {
+		dr.Status.Generations.NeedsDRAgentUpdate = dr.ObjectMeta.Generation
+		reconciled = false
+	}
+
+	isRunning := dr.Status.DRDetails != nil && dr.Status.DRDetails.Running
+
+	if !isRunning {
+		dr.Status.Generations.NeedsDRStart = dr.ObjectMeta.Generation
+		reconciled = false
+	}
+
+	if dr.NeedsSwitch() {
+		dr.Status.Generations.NeedsDRSwitch = dr.ObjectMeta.Generation
+		reconciled = false
+	}
+
+	if reconciled {
+		dr.Status.Generations = DRGenerationStatus{
+			Reconciled: dr.ObjectMeta.Generation,
+		}
+	}
+
+	return reconciled, nil
+}
+
+// GetAllowTagOverride returns the bool value for AllowTagOverride
+func (foundationDBDRSpec *FoundationDBDRSpec) GetAllowTagOverride() bool {
+	if foundationDBDRSpec.AllowTagOverride == nil {
+		return false
+	}
+
+	return *foundationDBDRSpec.AllowTagOverride
+}
@@ -21,6 +21,9 @@
 package v1beta1
 
 import (
+	"fmt"
+	"time"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -57,12 +60,98 @@ type FoundationDBRestoreSpec struct {
 
 	// The key ranges to restore.
 	KeyRanges []FoundationDBKeyRange `json:"keyRanges,omitempty"`
+
+	// TargetVersion defines the version of the database that the restore
+	// should restore to.
+	// If neither this nor the target timestamp is set, the restore restores
+	// to the latest restorable version in the backup.
+	// +kubebuilder:validation:Minimum=0
+	TargetVersion *int64 `json:"targetVersion,omitempty"`
+
+	// TargetTimestamp defines the point in time that the restore should
+	// restore to.
+	// The operator converts the timestamp into a version based on the
+	// timestamps of the restorable versions in the backup, so the restored
+	// version can differ slightly from the timestamp.
+	// This cannot be set together with the target version.
+	TargetTimestamp *metav1.Time `json:"targetTimestamp,omitempty"`
 }
 
 // FoundationDBRestoreStatus describes the current status of the restore for a cluster.
 type FoundationDBRestoreStatus struct {
 	// Running describes whether the restore is currently running.
 	Running bool `json:"running,omitempty"`
+
+	// TargetVersion provides the version that the restore was started with.
+	TargetVersion *int64 `json:"targetVersion,omitempty"`
+
+	// Message provides the reason why the restore could not be started.
+	Message string `json:"message,omitempty"`
+}
+
+// versionsPerSecond provides the rate at which the database advances its
+// versions.
+const versionsPerSecond = 1e6
+
+// HasTarget determines whether the restore should restore to a specific
+// version or timestamp, rather than the latest restorable version.
+func (restore *FoundationDBRestore) HasTarget() bool {
+	return restore.Spec.TargetVersion != nil || restore.Spec.TargetTimestamp != nil
+}
+
+// GetTargetVersion determines the version that the restore should restore
+// to, based on the restorable versions in the backup. This returns an error
+// if the target version or timestamp is not restorable.
+func (restore *FoundationDBRestore) GetTargetVersion(description *FoundationDBBackupDescription) (int64, error) {
+	if restore.Spec.TargetVersion != nil && restore.Spec.TargetTimestamp != nil {
+		return 0, fmt.Errorf("only one of targetVersion and targetTimestamp can be set")
+	}
+
+	if !description.Restorable || description.MinRestorablePoint == nil || description.MaxRestorablePoint == nil {
+		return 0, fmt.Errorf("backup %s is not restorable", restore.Spec.BackupURL)
+	}
+
+	minVersion := description.MinRestorablePoint
+	maxVersion := description.MaxRestorablePoint
+
+	if restore.Spec.TargetVersion != nil {
+		version := *restore.Spec.TargetVersion
+		if version < minVersion.Version || version > maxVersion.Version {
+			return 0, fmt.Errorf("version %d is not restorable, the restorable versions in the backup are %d to %d", version, minVersion.Version, maxVersion.Version)
+		}
+
+		return version, nil
+	}
+
+	if restore.Spec.TargetTimestamp == nil {
+		return maxVersion.Version, nil
+	}
+
+	if minVersion.EpochSeconds == nil || maxVersion.EpochSeconds == nil {
+		return 0, fmt.Errorf("backup %s does not provide timestamps for the restorable versions", restore.Spec.BackupURL)
+	}
+
+	timestamp := float64(restore.Spec.TargetTimestamp.Unix())
+	if timestamp < *minVersion.EpochSeconds || timestamp > *maxVersion.EpochSeconds {
+		return 0, fmt.Errorf("timestamp %s is not restorable, the restorable timestamps in the backup are %s to %s",
+			restore.Spec.TargetTimestamp.UTC().Format(time.RFC3339),
+			time.Unix(int64(*minVersion.EpochSeconds), 0).UTC().Format(time.RFC3339),
+			time.Unix(int64(*maxVersion.EpochSeconds), 0).UTC().Format(time.RFC3339),
+		)
+	}
+
+	// Versions advance by about one million per second and jump forward
+	// during recoveries, so counting back from the latest restorable point
+	// gives a version that is not after the target timestamp.
+	version := maxVersion.Version - int64((*maxVersion.EpochSeconds-timestamp)*versionsPerSecond)
+	if version < minVersion.Version {
+		return minVersion.Version, nil
+	}
+	if version > maxVersion.Version {
+		return maxVersion.Version, nil
+	}
+
+	return version, nil
 }
 
 // FoundationDBKeyRange describes a range of keys for a command.
@@ -0,0 +1,147 @@
+/*
+ * foundationdbrestore_types_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package v1beta1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("[api] FoundationDBRestore", func() {
+	When("getting the target version", func() {
+		minTimestamp := float64(time.Date(2021, 3, 22, 10, 0, 0, 0, time.UTC).Unix())
+		maxTimestamp := float64(time.Date(2021, 3, 22, 11, 0, 0, 0, time.UTC).Unix())
+
+		description := &FoundationDBBackupDescription{
+			Restorable:         true,
+			MinRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1000000000000, EpochSeconds: &minTimestamp},
+			MaxRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1004000000000, EpochSeconds: &maxTimestamp},
+		}
+
+		type testCase struct {
+			targetVersion   *int64
+			targetTimestamp *time.Time
+			description     *FoundationDBBackupDescription
+			expected        int64
+			expectedError   string
+		}
+
+		version := func(version int64) *int64 {
+			return &version
+		}
+
+		timestamp := func(minute int) *time.Time {
+			result := time.Date(2021, 3, 22, 10, minute, 0, 0, time.UTC)
+			return &result
+		}
+
+		DescribeTable("should validate the target against the backup",
+			func(tc testCase) {
+				restore := &FoundationDBRestore{
+					Spec: FoundationDBRestoreSpec{
+						BackupURL:     "blobstore://test@test-service/test-backup",
+						TargetVersion: tc.targetVersion,
+					},
+				}
+				if tc.targetTimestamp != nil {
+					targetTimestamp := metav1.NewTime(*tc.targetTimestamp)
+					restore.Spec.TargetTimestamp = &targetTimestamp
+				}
+
+				result, err := restore.GetTargetVersion(tc.description)
+				if tc.expectedError != "" {
+					Expect(err).To(MatchError(tc.expectedError))
+					return
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(tc.expected))
+			},
+			Entry("without a target",
+				testCase{
+					description: description,
+					expected:    1004000000000,
+				}),
+			Entry("with a restorable version",
+				testCase{
+					targetVersion: version(1002000000000),
+					description:   description,
+					expected:      1002000000000,
+				}),
+			Entry("with a version before the restorable versions",
+				testCase{
+					targetVersion: version(100),
+					description:   description,
+					expectedError: "version 100 is not restorable, the restorable versions in the backup are 1000000000000 to 1004000000000",
+				}),
+			Entry("with a restorable timestamp",
+				testCase{
+					targetTimestamp: timestamp(15),
+					description:     description,
+					expected:        1001300000000,
+				}),
+			Entry("with a restorable timestamp that is estimated before the restorable versions",
+				testCase{
+					targetTimestamp: timestamp(15),
+					description: &FoundationDBBackupDescription{
+						Restorable:         true,
+						MinRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1000000000000, EpochSeconds: &minTimestamp},
+						MaxRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1001000000000, EpochSeconds: &maxTimestamp},
+					},
+					expected: 1000000000000,
+				}),
+			Entry("with a timestamp after the restorable timestamps",
+				testCase{
+					targetTimestamp: timestamp(61),
+					description:     description,
+					expectedError:   "timestamp 2021-03-22T11:01:00Z is not restorable, the restorable timestamps in the backup are 2021-03-22T10:00:00Z to 2021-03-22T11:00:00Z",
+				}),
+			Entry("with a timestamp and no timestamps in the backup",
+				testCase{
+					targetTimestamp: timestamp(15),
+					description: &FoundationDBBackupDescription{
+						Restorable:         true,
+						MinRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 1000000},
+						MaxRestorablePoint: &FoundationDBBackupDescriptionVersion{Version: 3000000},
+					},
+					expectedError: "backup blobstore://test@test-service/test-backup does not provide timestamps for the restorable versions",
+				}),
+			Entry("with a backup that is not restorable",
+				testCase{
+					targetVersion: version(1002000000000),
+					description:   &FoundationDBBackupDescription{},
+					expectedError: "backup blobstore://test@test-service/test-backup is not restorable",
+				}),
+			Entry("with a target version and a target timestamp",
+				testCase{
+					targetVersion:   version(1002000000000),
+					targetTimestamp: timestamp(15),
+					description:     description,
+					expectedError:   "only one of targetVersion and targetTimestamp can be set",
+				}),
+		)
+	})
+})
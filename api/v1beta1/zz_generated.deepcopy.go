@@ -27,6 +27,31 @@ import (
 	netx "net"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLogConfig) DeepCopyInto(out *AuditLogConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxEntries != nil {
+		in, out := &in.MaxEntries, &out.MaxEntries
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLogConfig.
+func (in *AuditLogConfig) DeepCopy() *AuditLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutomaticReplacementOptions) DeepCopyInto(out *AutomaticReplacementOptions) {
 	*out = *in
@@ -45,6 +70,11 @@ func (in *AutomaticReplacementOptions) DeepCopyInto(out *AutomaticReplacementOpt
 		*out = new(int)
 		**out = **in
 	}
+	if in.TaintReplacementOptions != nil {
+		in, out := &in.TaintReplacementOptions, &out.TaintReplacementOptions
+		*out = make([]TaintReplacementOption, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutomaticReplacementOptions.
@@ -57,6 +87,31 @@ func (in *AutomaticReplacementOptions) DeepCopy() *AutomaticReplacementOptions {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupExpirationPolicy) DeepCopyInto(out *BackupExpirationPolicy) {
+	*out = *in
+	if in.MinRestorableDays != nil {
+		in, out := &in.MinRestorableDays, &out.MinRestorableDays
+		*out = new(int)
+		**out = **in
+	}
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupExpirationPolicy.
+func (in *BackupExpirationPolicy) DeepCopy() *BackupExpirationPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupExpirationPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupGenerationStatus) DeepCopyInto(out *BackupGenerationStatus) {
 	*out = *in
@@ -72,6 +127,27 @@ func (in *BackupGenerationStatus) DeepCopy() *BackupGenerationStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in BinaryChecksums) DeepCopyInto(out *BinaryChecksums) {
+	{
+		in := &in
+		*out = make(BinaryChecksums, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BinaryChecksums.
+func (in BinaryChecksums) DeepCopy() BinaryChecksums {
+	if in == nil {
+		return nil
+	}
+	out := new(BinaryChecksums)
+	in.DeepCopyInto(out)
+	return *out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BuggifyConfig) DeepCopyInto(out *BuggifyConfig) {
 	*out = *in
@@ -97,6 +173,26 @@ func (in *BuggifyConfig) DeepCopy() *BuggifyConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientConfig) DeepCopyInto(out *ClientConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientConfig.
+func (in *ClientConfig) DeepCopy() *ClientConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterGenerationStatus) DeepCopyInto(out *ClusterGenerationStatus) {
 	*out = *in
@@ -115,6 +211,11 @@ func (in *ClusterGenerationStatus) DeepCopy() *ClusterGenerationStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterHealth) DeepCopyInto(out *ClusterHealth) {
 	*out = *in
+	if in.FaultTolerance != nil {
+		in, out := &in.FaultTolerance, &out.FaultTolerance
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterHealth.
@@ -147,6 +248,71 @@ func (in *ConnectionString) DeepCopy() *ConnectionString {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyCheckConfig) DeepCopyInto(out *ConsistencyCheckConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IntervalSeconds != nil {
+		in, out := &in.IntervalSeconds, &out.IntervalSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.WindowSeconds != nil {
+		in, out := &in.WindowSeconds, &out.WindowSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = new(string)
+		**out = **in
+	}
+	if in.RunChecker != nil {
+		in, out := &in.RunChecker, &out.RunChecker
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PodTemplateSpec != nil {
+		in, out := &in.PodTemplateSpec, &out.PodTemplateSpec
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyCheckConfig.
+func (in *ConsistencyCheckConfig) DeepCopy() *ConsistencyCheckConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyCheckConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsistencyCheckStatus) DeepCopyInto(out *ConsistencyCheckStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsistencyCheckStatus.
+func (in *ConsistencyCheckStatus) DeepCopy() *ConsistencyCheckStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsistencyCheckStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ContainerOverrides) DeepCopyInto(out *ContainerOverrides) {
 	*out = *in
@@ -211,6 +377,21 @@ func (in *CoordinatorSelectionSetting) DeepCopy() *CoordinatorSelectionSetting {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DRGenerationStatus) DeepCopyInto(out *DRGenerationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DRGenerationStatus.
+func (in *DRGenerationStatus) DeepCopy() *DRGenerationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DRGenerationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataCenter) DeepCopyInto(out *DataCenter) {
 	*out = *in
@@ -238,6 +419,11 @@ func (in *DatabaseConfiguration) DeepCopyInto(out *DatabaseConfiguration) {
 	}
 	out.RoleCounts = in.RoleCounts
 	out.VersionFlags = in.VersionFlags
+	if in.PerpetualStorageWiggle != nil {
+		in, out := &in.PerpetualStorageWiggle, &out.PerpetualStorageWiggle
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseConfiguration.
@@ -250,6 +436,41 @@ func (in *DatabaseConfiguration) DeepCopy() *DatabaseConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeletionOptions) DeepCopyInto(out *DeletionOptions) {
+	*out = *in
+	if in.Graceful != nil {
+		in, out := &in.Graceful, &out.Graceful
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ProtectData != nil {
+		in, out := &in.ProtectData, &out.ProtectData
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PVCRetentionPolicy != nil {
+		in, out := &in.PVCRetentionPolicy, &out.PVCRetentionPolicy
+		*out = new(ResourceRetentionPolicy)
+		**out = **in
+	}
+	if in.ServiceRetentionPolicy != nil {
+		in, out := &in.ServiceRetentionPolicy, &out.ServiceRetentionPolicy
+		*out = new(ResourceRetentionPolicy)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeletionOptions.
+func (in *DeletionOptions) DeepCopy() *DeletionOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(DeletionOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FaultTolerance) DeepCopyInto(out *FaultTolerance) {
 	*out = *in
@@ -307,6 +528,51 @@ func (in *FoundationDBBackup) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBBackupDescription) DeepCopyInto(out *FoundationDBBackupDescription) {
+	*out = *in
+	if in.MinRestorablePoint != nil {
+		in, out := &in.MinRestorablePoint, &out.MinRestorablePoint
+		*out = new(FoundationDBBackupDescriptionVersion)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxRestorablePoint != nil {
+		in, out := &in.MaxRestorablePoint, &out.MaxRestorablePoint
+		*out = new(FoundationDBBackupDescriptionVersion)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBBackupDescription.
+func (in *FoundationDBBackupDescription) DeepCopy() *FoundationDBBackupDescription {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBBackupDescription)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBBackupDescriptionVersion) DeepCopyInto(out *FoundationDBBackupDescriptionVersion) {
+	*out = *in
+	if in.EpochSeconds != nil {
+		in, out := &in.EpochSeconds, &out.EpochSeconds
+		*out = new(float64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBBackupDescriptionVersion.
+func (in *FoundationDBBackupDescriptionVersion) DeepCopy() *FoundationDBBackupDescriptionVersion {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBBackupDescriptionVersion)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FoundationDBBackupList) DeepCopyInto(out *FoundationDBBackupList) {
 	*out = *in
@@ -352,6 +618,11 @@ func (in *FoundationDBBackupSpec) DeepCopyInto(out *FoundationDBBackupSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.ExpirationPolicy != nil {
+		in, out := &in.ExpirationPolicy, &out.ExpirationPolicy
+		*out = new(BackupExpirationPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.BackupDeploymentMetadata != nil {
 		in, out := &in.BackupDeploymentMetadata, &out.BackupDeploymentMetadata
 		*out = new(v1.ObjectMeta)
@@ -372,6 +643,16 @@ func (in *FoundationDBBackupSpec) DeepCopyInto(out *FoundationDBBackupSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.ImageConfigs != nil {
+		in, out := &in.ImageConfigs, &out.ImageConfigs
+		*out = make([]ImageConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.SidecarImageConfigs != nil {
+		in, out := &in.SidecarImageConfigs, &out.SidecarImageConfigs
+		*out = make([]ImageConfig, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBBackupSpec.
@@ -393,6 +674,10 @@ func (in *FoundationDBBackupStatus) DeepCopyInto(out *FoundationDBBackupStatus)
 		**out = **in
 	}
 	out.Generations = in.Generations
+	if in.LastExpirationTime != nil {
+		in, out := &in.LastExpirationTime, &out.LastExpirationTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBBackupStatus.
@@ -476,6 +761,53 @@ func (in *FoundationDBClusterAutomationOptions) DeepCopyInto(out *FoundationDBCl
 		*out = new(bool)
 		**out = **in
 	}
+	if in.ExclusionTimeoutSeconds != nil {
+		in, out := &in.ExclusionTimeoutSeconds, &out.ExclusionTimeoutSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.RollbackStuckExclusions != nil {
+		in, out := &in.RollbackStuckExclusions, &out.RollbackStuckExclusions
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReconciliationStalledTimeoutSeconds != nil {
+		in, out := &in.ReconciliationStalledTimeoutSeconds, &out.ReconciliationStalledTimeoutSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxConcurrentExclusions != nil {
+		in, out := &in.MaxConcurrentExclusions, &out.MaxConcurrentExclusions
+		*out = new(int)
+		**out = **in
+	}
+	if in.MinimumFreeSpacePercentage != nil {
+		in, out := &in.MinimumFreeSpacePercentage, &out.MinimumFreeSpacePercentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxZonesWithUnavailablePods != nil {
+		in, out := &in.MaxZonesWithUnavailablePods, &out.MaxZonesWithUnavailablePods
+		*out = new(int)
+		**out = **in
+	}
+	if in.RecreateStuckPods != nil {
+		in, out := &in.RecreateStuckPods, &out.RecreateStuckPods
+		*out = new(bool)
+		**out = **in
+	}
+	if in.StuckPodTimeoutSeconds != nil {
+		in, out := &in.StuckPodTimeoutSeconds, &out.StuckPodTimeoutSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.AllowFdbcliCommands != nil {
+		in, out := &in.AllowFdbcliCommands, &out.AllowFdbcliCommands
+		*out = new(bool)
+		**out = **in
+	}
+	in.ProcessHealthChecks.DeepCopyInto(&out.ProcessHealthChecks)
+	in.RoleCountRecommendations.DeepCopyInto(&out.RoleCountRecommendations)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBClusterAutomationOptions.
@@ -491,6 +823,11 @@ func (in *FoundationDBClusterAutomationOptions) DeepCopy() *FoundationDBClusterA
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FoundationDBClusterFaultDomain) DeepCopyInto(out *FoundationDBClusterFaultDomain) {
 	*out = *in
+	if in.SpreadMode != nil {
+		in, out := &in.SpreadMode, &out.SpreadMode
+		*out = new(FaultDomainSpreadMode)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBClusterFaultDomain.
@@ -553,9 +890,16 @@ func (in *FoundationDBClusterSpec) DeepCopyInto(out *FoundationDBClusterSpec) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.ProcessGroupOverrides != nil {
+		in, out := &in.ProcessGroupOverrides, &out.ProcessGroupOverrides
+		*out = make(map[string]ProcessGroupOverride, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	out.ProcessCounts = in.ProcessCounts
 	in.PartialConnectionString.DeepCopyInto(&out.PartialConnectionString)
-	out.FaultDomain = in.FaultDomain
+	in.FaultDomain.DeepCopyInto(&out.FaultDomain)
 	if in.InstancesToRemove != nil {
 		in, out := &in.InstancesToRemove, &out.InstancesToRemove
 		*out = make([]string, len(*in))
@@ -658,6 +1002,16 @@ func (in *FoundationDBClusterSpec) DeepCopyInto(out *FoundationDBClusterSpec) {
 			(*out)[key] = val
 		}
 	}
+	if in.MaximumTotalClientsForBounce != nil {
+		in, out := &in.MaximumTotalClientsForBounce, &out.MaximumTotalClientsForBounce
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaximumClientWaitSecondsForBounce != nil {
+		in, out := &in.MaximumClientWaitSecondsForBounce, &out.MaximumClientWaitSecondsForBounce
+		*out = new(int)
+		**out = **in
+	}
 	if in.ReplaceInstancesWhenResourcesChange != nil {
 		in, out := &in.ReplaceInstancesWhenResourcesChange, &out.ReplaceInstancesWhenResourcesChange
 		*out = new(bool)
@@ -668,34 +1022,105 @@ func (in *FoundationDBClusterSpec) DeepCopyInto(out *FoundationDBClusterSpec) {
 		*out = make([]CoordinatorSelectionSetting, len(*in))
 		copy(*out, *in)
 	}
+	if in.CoordinatorCount != nil {
+		in, out := &in.CoordinatorCount, &out.CoordinatorCount
+		*out = new(int)
+		**out = **in
+	}
 	in.LabelConfig.DeepCopyInto(&out.LabelConfig)
 	if in.UseExplicitListenAddress != nil {
 		in, out := &in.UseExplicitListenAddress, &out.UseExplicitListenAddress
 		*out = new(bool)
 		**out = **in
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBClusterSpec.
-func (in *FoundationDBClusterSpec) DeepCopy() *FoundationDBClusterSpec {
-	if in == nil {
-		return nil
+	if in.StatusSummaryIntervalSeconds != nil {
+		in, out := &in.StatusSummaryIntervalSeconds, &out.StatusSummaryIntervalSeconds
+		*out = new(int)
+		**out = **in
 	}
-	out := new(FoundationDBClusterSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FoundationDBClusterStatus) DeepCopyInto(out *FoundationDBClusterStatus) {
-	*out = *in
-	out.ProcessCounts = in.ProcessCounts
-	if in.IncorrectProcesses != nil {
-		in, out := &in.IncorrectProcesses, &out.IncorrectProcesses
-		*out = make(map[string]int64, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	in.MetricsExporter.DeepCopyInto(&out.MetricsExporter)
+	in.TraceLogs.DeepCopyInto(&out.TraceLogs)
+	in.DeletionOptions.DeepCopyInto(&out.DeletionOptions)
+	if in.ImageType != nil {
+		in, out := &in.ImageType, &out.ImageType
+		*out = new(ImageType)
+		**out = **in
+	}
+	if in.PodUpdateStrategy != nil {
+		in, out := &in.PodUpdateStrategy, &out.PodUpdateStrategy
+		*out = new(PodUpdateStrategy)
+		**out = **in
+	}
+	in.ClientConfig.DeepCopyInto(&out.ClientConfig)
+	if in.TagThrottles != nil {
+		in, out := &in.TagThrottles, &out.TagThrottles
+		*out = make([]TagThrottle, len(*in))
+		copy(*out, *in)
+	}
+	if in.TagQuotas != nil {
+		in, out := &in.TagQuotas, &out.TagQuotas
+		*out = make([]TagQuota, len(*in))
+		copy(*out, *in)
+	}
+	if in.DatabaseKnobs != nil {
+		in, out := &in.DatabaseKnobs, &out.DatabaseKnobs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.ConsistencyCheck.DeepCopyInto(&out.ConsistencyCheck)
+	in.AuditLog.DeepCopyInto(&out.AuditLog)
+	if in.BinaryChecksums != nil {
+		in, out := &in.BinaryChecksums, &out.BinaryChecksums
+		*out = make(map[string]BinaryChecksums, len(*in))
+		for key, val := range *in {
+			var outVal map[string]string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make(BinaryChecksums, len(*in))
+				for key, val := range *in {
+					(*out)[key] = val
+				}
+			}
+			(*out)[key] = outVal
+		}
+	}
+	in.ProcessRestartOptions.DeepCopyInto(&out.ProcessRestartOptions)
+	if in.SeedBackup != nil {
+		in, out := &in.SeedBackup, &out.SeedBackup
+		*out = new(SeedBackup)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExcludedLocalities != nil {
+		in, out := &in.ExcludedLocalities, &out.ExcludedLocalities
+		*out = make([]LocalityExclusion, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBClusterSpec.
+func (in *FoundationDBClusterSpec) DeepCopy() *FoundationDBClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBClusterStatus) DeepCopyInto(out *FoundationDBClusterStatus) {
+	*out = *in
+	out.ProcessCounts = in.ProcessCounts
+	if in.IncorrectProcesses != nil {
+		in, out := &in.IncorrectProcesses, &out.IncorrectProcesses
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 	if in.IncorrectPods != nil {
 		in, out := &in.IncorrectPods, &out.IncorrectPods
@@ -716,7 +1141,7 @@ func (in *FoundationDBClusterStatus) DeepCopyInto(out *FoundationDBClusterStatus
 	}
 	in.DatabaseConfiguration.DeepCopyInto(&out.DatabaseConfiguration)
 	out.Generations = in.Generations
-	out.Health = in.Health
+	in.Health.DeepCopyInto(&out.Health)
 	out.RequiredAddresses = in.RequiredAddresses
 	if in.PendingRemovals != nil {
 		in, out := &in.PendingRemovals, &out.PendingRemovals
@@ -730,6 +1155,11 @@ func (in *FoundationDBClusterStatus) DeepCopyInto(out *FoundationDBClusterStatus
 		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.LogServersPerDisk != nil {
+		in, out := &in.LogServersPerDisk, &out.LogServersPerDisk
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
 	if in.ProcessGroups != nil {
 		in, out := &in.ProcessGroups, &out.ProcessGroups
 		*out = make([]*ProcessGroupStatus, len(*in))
@@ -741,7 +1171,69 @@ func (in *FoundationDBClusterStatus) DeepCopyInto(out *FoundationDBClusterStatus
 			}
 		}
 	}
+	if in.OutdatedProcessGroupIDs != nil {
+		in, out := &in.OutdatedProcessGroupIDs, &out.OutdatedProcessGroupIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingExclusion != nil {
+		in, out := &in.PendingExclusion, &out.PendingExclusion
+		*out = new(PendingProcessOperation)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Locks.DeepCopyInto(&out.Locks)
+	if in.StorageWiggle != nil {
+		in, out := &in.StorageWiggle, &out.StorageWiggle
+		*out = new(StorageWiggleStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RecommendedRoleCounts != nil {
+		in, out := &in.RecommendedRoleCounts, &out.RecommendedRoleCounts
+		*out = new(RoleCounts)
+		**out = **in
+	}
+	if in.ManagedTagThrottles != nil {
+		in, out := &in.ManagedTagThrottles, &out.ManagedTagThrottles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedTagQuotas != nil {
+		in, out := &in.ManagedTagQuotas, &out.ManagedTagQuotas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ManagedDatabaseKnobs != nil {
+		in, out := &in.ManagedDatabaseKnobs, &out.ManagedDatabaseKnobs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ConsistencyCheck != nil {
+		in, out := &in.ConsistencyCheck, &out.ConsistencyCheck
+		*out = new(ConsistencyCheckStatus)
+		**out = **in
+	}
+	if in.LocalityExclusions != nil {
+		in, out := &in.LocalityExclusions, &out.LocalityExclusions
+		*out = make([]LocalityExclusionStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.ProcessPorts != nil {
+		in, out := &in.ProcessPorts, &out.ProcessPorts
+		*out = new(ProcessPortsStatus)
+		**out = **in
+	}
+	if in.ReconciliationProgress != nil {
+		in, out := &in.ReconciliationProgress, &out.ReconciliationProgress
+		*out = new(ReconciliationProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBClusterStatus.
@@ -754,6 +1246,156 @@ func (in *FoundationDBClusterStatus) DeepCopy() *FoundationDBClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBDR) DeepCopyInto(out *FoundationDBDR) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBDR.
+func (in *FoundationDBDR) DeepCopy() *FoundationDBDR {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBDR)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FoundationDBDR) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBDRList) DeepCopyInto(out *FoundationDBDRList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]FoundationDBDR, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBDRList.
+func (in *FoundationDBDRList) DeepCopy() *FoundationDBDRList {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBDRList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *FoundationDBDRList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBDRSpec) DeepCopyInto(out *FoundationDBDRSpec) {
+	*out = *in
+	if in.AgentCount != nil {
+		in, out := &in.AgentCount, &out.AgentCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.DRDeploymentMetadata != nil {
+		in, out := &in.DRDeploymentMetadata, &out.DRDeploymentMetadata
+		*out = new(v1.ObjectMeta)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PodTemplateSpec != nil {
+		in, out := &in.PodTemplateSpec, &out.PodTemplateSpec
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomParameters != nil {
+		in, out := &in.CustomParameters, &out.CustomParameters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowTagOverride != nil {
+		in, out := &in.AllowTagOverride, &out.AllowTagOverride
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ImageConfigs != nil {
+		in, out := &in.ImageConfigs, &out.ImageConfigs
+		*out = make([]ImageConfig, len(*in))
+		copy(*out, *in)
+	}
+	if in.SidecarImageConfigs != nil {
+		in, out := &in.SidecarImageConfigs, &out.SidecarImageConfigs
+		*out = make([]ImageConfig, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBDRSpec.
+func (in *FoundationDBDRSpec) DeepCopy() *FoundationDBDRSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBDRSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBDRStatus) DeepCopyInto(out *FoundationDBDRStatus) {
+	*out = *in
+	if in.DRDetails != nil {
+		in, out := &in.DRDetails, &out.DRDetails
+		*out = new(FoundationDBDRStatusDRDetails)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Generations = in.Generations
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBDRStatus.
+func (in *FoundationDBDRStatus) DeepCopy() *FoundationDBDRStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBDRStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBDRStatusDRDetails) DeepCopyInto(out *FoundationDBDRStatusDRDetails) {
+	*out = *in
+	if in.Lag != nil {
+		in, out := &in.Lag, &out.Lag
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBDRStatusDRDetails.
+func (in *FoundationDBDRStatusDRDetails) DeepCopy() *FoundationDBDRStatusDRDetails {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBDRStatusDRDetails)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FoundationDBKeyRange) DeepCopyInto(out *FoundationDBKeyRange) {
 	*out = *in
@@ -800,13 +1442,28 @@ func (in *FoundationDBLiveBackupStatusState) DeepCopy() *FoundationDBLiveBackupS
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBLiveDRStatus) DeepCopyInto(out *FoundationDBLiveDRStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBLiveDRStatus.
+func (in *FoundationDBLiveDRStatus) DeepCopy() *FoundationDBLiveDRStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBLiveDRStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FoundationDBRestore) DeepCopyInto(out *FoundationDBRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBRestore.
@@ -867,6 +1524,15 @@ func (in *FoundationDBRestoreSpec) DeepCopyInto(out *FoundationDBRestoreSpec) {
 		*out = make([]FoundationDBKeyRange, len(*in))
 		copy(*out, *in)
 	}
+	if in.TargetVersion != nil {
+		in, out := &in.TargetVersion, &out.TargetVersion
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TargetTimestamp != nil {
+		in, out := &in.TargetTimestamp, &out.TargetTimestamp
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBRestoreSpec.
@@ -882,6 +1548,11 @@ func (in *FoundationDBRestoreSpec) DeepCopy() *FoundationDBRestoreSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FoundationDBRestoreStatus) DeepCopyInto(out *FoundationDBRestoreStatus) {
 	*out = *in
+	if in.TargetVersion != nil {
+		in, out := &in.TargetVersion, &out.TargetVersion
+		*out = new(int64)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBRestoreStatus.
@@ -1000,6 +1671,10 @@ func (in *FoundationDBStatusClusterInfo) DeepCopyInto(out *FoundationDBStatusClu
 	in.Clients.DeepCopyInto(&out.Clients)
 	in.Layers.DeepCopyInto(&out.Layers)
 	out.FaultTolerance = in.FaultTolerance
+	out.Qos = in.Qos
+	in.StorageWiggler.DeepCopyInto(&out.StorageWiggler)
+	out.RecoveryState = in.RecoveryState
+	out.Workload = in.Workload
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusClusterInfo.
@@ -1097,6 +1772,21 @@ func (in *FoundationDBStatusDataStatistics) DeepCopy() *FoundationDBStatusDataSt
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusLagInfo) DeepCopyInto(out *FoundationDBStatusLagInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusLagInfo.
+func (in *FoundationDBStatusLagInfo) DeepCopy() *FoundationDBStatusLagInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusLagInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *FoundationDBStatusLayerInfo) DeepCopyInto(out *FoundationDBStatusLayerInfo) {
 	*out = *in
@@ -1146,65 +1836,220 @@ func (in *FoundationDBStatusMovingData) DeepCopy() *FoundationDBStatusMovingData
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FoundationDBStatusProcessInfo) DeepCopyInto(out *FoundationDBStatusProcessInfo) {
+func (in *FoundationDBStatusOperationRates) DeepCopyInto(out *FoundationDBStatusOperationRates) {
 	*out = *in
-	in.Address.DeepCopyInto(&out.Address)
-	if in.Locality != nil {
-		in, out := &in.Locality, &out.Locality
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Roles != nil {
-		in, out := &in.Roles, &out.Roles
-		*out = make([]FoundationDBStatusProcessRoleInfo, len(*in))
-		copy(*out, *in)
-	}
+	out.Reads = in.Reads
+	out.Writes = in.Writes
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessInfo.
-func (in *FoundationDBStatusProcessInfo) DeepCopy() *FoundationDBStatusProcessInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusOperationRates.
+func (in *FoundationDBStatusOperationRates) DeepCopy() *FoundationDBStatusOperationRates {
 	if in == nil {
 		return nil
 	}
-	out := new(FoundationDBStatusProcessInfo)
+	out := new(FoundationDBStatusOperationRates)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FoundationDBStatusProcessRoleInfo) DeepCopyInto(out *FoundationDBStatusProcessRoleInfo) {
+func (in *FoundationDBStatusProcessCPUInfo) DeepCopyInto(out *FoundationDBStatusProcessCPUInfo) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessRoleInfo.
-func (in *FoundationDBStatusProcessRoleInfo) DeepCopy() *FoundationDBStatusProcessRoleInfo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessCPUInfo.
+func (in *FoundationDBStatusProcessCPUInfo) DeepCopy() *FoundationDBStatusProcessCPUInfo {
 	if in == nil {
 		return nil
 	}
-	out := new(FoundationDBStatusProcessRoleInfo)
+	out := new(FoundationDBStatusProcessCPUInfo)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *FoundationDBStatusSupportedVersion) DeepCopyInto(out *FoundationDBStatusSupportedVersion) {
+func (in *FoundationDBStatusProcessDiskInfo) DeepCopyInto(out *FoundationDBStatusProcessDiskInfo) {
 	*out = *in
-	if in.ConnectedClients != nil {
-		in, out := &in.ConnectedClients, &out.ConnectedClients
-		*out = make([]FoundationDBStatusConnectedClient, len(*in))
-		copy(*out, *in)
-	}
-	if in.MaxProtocolClients != nil {
-		in, out := &in.MaxProtocolClients, &out.MaxProtocolClients
-		*out = make([]FoundationDBStatusConnectedClient, len(*in))
-		copy(*out, *in)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusSupportedVersion.
-func (in *FoundationDBStatusSupportedVersion) DeepCopy() *FoundationDBStatusSupportedVersion {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessDiskInfo.
+func (in *FoundationDBStatusProcessDiskInfo) DeepCopy() *FoundationDBStatusProcessDiskInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusProcessDiskInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusProcessInfo) DeepCopyInto(out *FoundationDBStatusProcessInfo) {
+	*out = *in
+	in.Address.DeepCopyInto(&out.Address)
+	if in.Locality != nil {
+		in, out := &in.Locality, &out.Locality
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]FoundationDBStatusProcessRoleInfo, len(*in))
+		copy(*out, *in)
+	}
+	out.Disk = in.Disk
+	out.CPU = in.CPU
+	if in.Messages != nil {
+		in, out := &in.Messages, &out.Messages
+		*out = make([]FoundationDBStatusProcessMessage, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessInfo.
+func (in *FoundationDBStatusProcessInfo) DeepCopy() *FoundationDBStatusProcessInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusProcessInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusProcessMessage) DeepCopyInto(out *FoundationDBStatusProcessMessage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessMessage.
+func (in *FoundationDBStatusProcessMessage) DeepCopy() *FoundationDBStatusProcessMessage {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusProcessMessage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusProcessRoleInfo) DeepCopyInto(out *FoundationDBStatusProcessRoleInfo) {
+	*out = *in
+	out.DataLag = in.DataLag
+	out.DurabilityLag = in.DurabilityLag
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusProcessRoleInfo.
+func (in *FoundationDBStatusProcessRoleInfo) DeepCopy() *FoundationDBStatusProcessRoleInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusProcessRoleInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusQosInfo) DeepCopyInto(out *FoundationDBStatusQosInfo) {
+	*out = *in
+	out.WorstDataLagStorageServer = in.WorstDataLagStorageServer
+	out.WorstDurabilityLagStorageServer = in.WorstDurabilityLagStorageServer
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusQosInfo.
+func (in *FoundationDBStatusQosInfo) DeepCopy() *FoundationDBStatusQosInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusQosInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusRate) DeepCopyInto(out *FoundationDBStatusRate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusRate.
+func (in *FoundationDBStatusRate) DeepCopy() *FoundationDBStatusRate {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusRate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusRecoveryState) DeepCopyInto(out *FoundationDBStatusRecoveryState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusRecoveryState.
+func (in *FoundationDBStatusRecoveryState) DeepCopy() *FoundationDBStatusRecoveryState {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusRecoveryState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusStorageWiggleMetrics) DeepCopyInto(out *FoundationDBStatusStorageWiggleMetrics) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusStorageWiggleMetrics.
+func (in *FoundationDBStatusStorageWiggleMetrics) DeepCopy() *FoundationDBStatusStorageWiggleMetrics {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusStorageWiggleMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusStorageWiggler) DeepCopyInto(out *FoundationDBStatusStorageWiggler) {
+	*out = *in
+	if in.WiggleServerAddresses != nil {
+		in, out := &in.WiggleServerAddresses, &out.WiggleServerAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Primary = in.Primary
+	out.Remote = in.Remote
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusStorageWiggler.
+func (in *FoundationDBStatusStorageWiggler) DeepCopy() *FoundationDBStatusStorageWiggler {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusStorageWiggler)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusSupportedVersion) DeepCopyInto(out *FoundationDBStatusSupportedVersion) {
+	*out = *in
+	if in.ConnectedClients != nil {
+		in, out := &in.ConnectedClients, &out.ConnectedClients
+		*out = make([]FoundationDBStatusConnectedClient, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxProtocolClients != nil {
+		in, out := &in.MaxProtocolClients, &out.MaxProtocolClients
+		*out = make([]FoundationDBStatusConnectedClient, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusSupportedVersion.
+func (in *FoundationDBStatusSupportedVersion) DeepCopy() *FoundationDBStatusSupportedVersion {
 	if in == nil {
 		return nil
 	}
@@ -1213,6 +2058,41 @@ func (in *FoundationDBStatusSupportedVersion) DeepCopy() *FoundationDBStatusSupp
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusTransactionRates) DeepCopyInto(out *FoundationDBStatusTransactionRates) {
+	*out = *in
+	out.Started = in.Started
+	out.Committed = in.Committed
+	out.Conflicted = in.Conflicted
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusTransactionRates.
+func (in *FoundationDBStatusTransactionRates) DeepCopy() *FoundationDBStatusTransactionRates {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusTransactionRates)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FoundationDBStatusWorkload) DeepCopyInto(out *FoundationDBStatusWorkload) {
+	*out = *in
+	out.Transactions = in.Transactions
+	out.Operations = in.Operations
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FoundationDBStatusWorkload.
+func (in *FoundationDBStatusWorkload) DeepCopy() *FoundationDBStatusWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(FoundationDBStatusWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageConfig) DeepCopyInto(out *ImageConfig) {
 	*out = *in
@@ -1245,6 +2125,13 @@ func (in *LabelConfig) DeepCopyInto(out *LabelConfig) {
 			(*out)[key] = val
 		}
 	}
+	if in.ResourceAnnotations != nil {
+		in, out := &in.ResourceAnnotations, &out.ResourceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.ProcessGroupIDLabels != nil {
 		in, out := &in.ProcessGroupIDLabels, &out.ProcessGroupIDLabels
 		*out = make([]string, len(*in))
@@ -1272,6 +2159,37 @@ func (in *LabelConfig) DeepCopy() *LabelConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalityExclusion) DeepCopyInto(out *LocalityExclusion) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalityExclusion.
+func (in *LocalityExclusion) DeepCopy() *LocalityExclusion {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalityExclusion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalityExclusionStatus) DeepCopyInto(out *LocalityExclusionStatus) {
+	*out = *in
+	out.LocalityExclusion = in.LocalityExclusion
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalityExclusionStatus.
+func (in *LocalityExclusionStatus) DeepCopy() *LocalityExclusionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalityExclusionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LockDenyListEntry) DeepCopyInto(out *LockDenyListEntry) {
 	*out = *in
@@ -1337,6 +2255,51 @@ func (in *LockSystemStatus) DeepCopy() *LockSystemStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsExporterConfig) DeepCopyInto(out *MetricsExporterConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PodTemplateSpec != nil {
+		in, out := &in.PodTemplateSpec, &out.PodTemplateSpec
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsExporterConfig.
+func (in *MetricsExporterConfig) DeepCopy() *MetricsExporterConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsExporterConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingProcessOperation) DeepCopyInto(out *PendingProcessOperation) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingProcessOperation.
+func (in *PendingProcessOperation) DeepCopy() *PendingProcessOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingProcessOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PendingRemovalState) DeepCopyInto(out *PendingRemovalState) {
 	*out = *in
@@ -1409,6 +2372,33 @@ func (in *ProcessGroupCondition) DeepCopy() *ProcessGroupCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessGroupOverride) DeepCopyInto(out *ProcessGroupOverride) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessGroupOverride.
+func (in *ProcessGroupOverride) DeepCopy() *ProcessGroupOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessGroupOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProcessGroupStatus) DeepCopyInto(out *ProcessGroupStatus) {
 	*out = *in
@@ -1440,6 +2430,91 @@ func (in *ProcessGroupStatus) DeepCopy() *ProcessGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessHealthCheckOptions) DeepCopyInto(out *ProcessHealthCheckOptions) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxDurabilityLagSeconds != nil {
+		in, out := &in.MaxDurabilityLagSeconds, &out.MaxDurabilityLagSeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.MinimumFreeDiskPercentage != nil {
+		in, out := &in.MinimumFreeDiskPercentage, &out.MinimumFreeDiskPercentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.ReplaceUndesiredProcesses != nil {
+		in, out := &in.ReplaceUndesiredProcesses, &out.ReplaceUndesiredProcesses
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessHealthCheckOptions.
+func (in *ProcessHealthCheckOptions) DeepCopy() *ProcessHealthCheckOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessHealthCheckOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessPortsStatus) DeepCopyInto(out *ProcessPortsStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessPortsStatus.
+func (in *ProcessPortsStatus) DeepCopy() *ProcessPortsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessPortsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProcessRestartOptions) DeepCopyInto(out *ProcessRestartOptions) {
+	*out = *in
+	if in.RestartDelaySeconds != nil {
+		in, out := &in.RestartDelaySeconds, &out.RestartDelaySeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.InitialRestartDelaySeconds != nil {
+		in, out := &in.InitialRestartDelaySeconds, &out.InitialRestartDelaySeconds
+		*out = new(int)
+		**out = **in
+	}
+	if in.RestartBackoff != nil {
+		in, out := &in.RestartBackoff, &out.RestartBackoff
+		*out = new(int)
+		**out = **in
+	}
+	if in.RestartDelayResetIntervalSeconds != nil {
+		in, out := &in.RestartDelayResetIntervalSeconds, &out.RestartDelayResetIntervalSeconds
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProcessRestartOptions.
+func (in *ProcessRestartOptions) DeepCopy() *ProcessRestartOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(ProcessRestartOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProcessSettings) DeepCopyInto(out *ProcessSettings) {
 	*out = *in
@@ -1484,6 +2559,26 @@ func (in *ProcessSettings) DeepCopy() *ProcessSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconciliationProgress) DeepCopyInto(out *ReconciliationProgress) {
+	*out = *in
+	if in.DelayedSubReconcilers != nil {
+		in, out := &in.DelayedSubReconcilers, &out.DelayedSubReconcilers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconciliationProgress.
+func (in *ReconciliationProgress) DeepCopy() *ReconciliationProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconciliationProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Region) DeepCopyInto(out *Region) {
 	*out = *in
@@ -1519,6 +2614,42 @@ func (in *RequiredAddressSet) DeepCopy() *RequiredAddressSet {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleCountRecommendationOptions) DeepCopyInto(out *RoleCountRecommendationOptions) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Apply != nil {
+		in, out := &in.Apply, &out.Apply
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxCPUUsagePercentage != nil {
+		in, out := &in.MaxCPUUsagePercentage, &out.MaxCPUUsagePercentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.MaxLogQueueBytes != nil {
+		in, out := &in.MaxLogQueueBytes, &out.MaxLogQueueBytes
+		*out = new(int64)
+		**out = **in
+	}
+	out.MaxRoleCounts = in.MaxRoleCounts
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleCountRecommendationOptions.
+func (in *RoleCountRecommendationOptions) DeepCopy() *RoleCountRecommendationOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleCountRecommendationOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RoleCounts) DeepCopyInto(out *RoleCounts) {
 	*out = *in
@@ -1547,11 +2678,41 @@ func (in *RoutingConfig) DeepCopyInto(out *RoutingConfig) {
 		*out = new(PublicIPSource)
 		**out = **in
 	}
+	if in.PublicServiceType != nil {
+		in, out := &in.PublicServiceType, &out.PublicServiceType
+		*out = new(corev1.ServiceType)
+		**out = **in
+	}
+	if in.PublicServiceScope != nil {
+		in, out := &in.PublicServiceScope, &out.PublicServiceScope
+		*out = new(PublicServiceScope)
+		**out = **in
+	}
 	if in.PodIPFamily != nil {
 		in, out := &in.PodIPFamily, &out.PodIPFamily
 		*out = new(int)
 		**out = **in
 	}
+	if in.UseHostNetwork != nil {
+		in, out := &in.UseHostNetwork, &out.UseHostNetwork
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BasePort != nil {
+		in, out := &in.BasePort, &out.BasePort
+		*out = new(int)
+		**out = **in
+	}
+	if in.PortStride != nil {
+		in, out := &in.PortStride, &out.PortStride
+		*out = new(int)
+		**out = **in
+	}
+	if in.ServiceMetadata != nil {
+		in, out := &in.ServiceMetadata, &out.ServiceMetadata
+		*out = new(v1.ObjectMeta)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutingConfig.
@@ -1564,6 +2725,45 @@ func (in *RoutingConfig) DeepCopy() *RoutingConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SeedBackup) DeepCopyInto(out *SeedBackup) {
+	*out = *in
+	if in.KeyRanges != nil {
+		in, out := &in.KeyRanges, &out.KeyRanges
+		*out = make([]FoundationDBKeyRange, len(*in))
+		copy(*out, *in)
+	}
+	if in.TargetVersion != nil {
+		in, out := &in.TargetVersion, &out.TargetVersion
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TargetTimestamp != nil {
+		in, out := &in.TargetTimestamp, &out.TargetTimestamp
+		*out = (*in).DeepCopy()
+	}
+	if in.AgentCount != nil {
+		in, out := &in.AgentCount, &out.AgentCount
+		*out = new(int)
+		**out = **in
+	}
+	if in.PodTemplateSpec != nil {
+		in, out := &in.PodTemplateSpec, &out.PodTemplateSpec
+		*out = new(corev1.PodTemplateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SeedBackup.
+func (in *SeedBackup) DeepCopy() *SeedBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(SeedBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceConfig) DeepCopyInto(out *ServiceConfig) {
 	*out = *in
@@ -1589,6 +2789,127 @@ func (in *ServiceConfig) DeepCopy() *ServiceConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageWiggleStatus) DeepCopyInto(out *StorageWiggleStatus) {
+	*out = *in
+	if in.WigglingAddresses != nil {
+		in, out := &in.WigglingAddresses, &out.WigglingAddresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageWiggleStatus.
+func (in *StorageWiggleStatus) DeepCopy() *StorageWiggleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageWiggleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagQuota) DeepCopyInto(out *TagQuota) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagQuota.
+func (in *TagQuota) DeepCopy() *TagQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(TagQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagThrottle) DeepCopyInto(out *TagThrottle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagThrottle.
+func (in *TagThrottle) DeepCopy() *TagThrottle {
+	if in == nil {
+		return nil
+	}
+	out := new(TagThrottle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TagThrottleStatus) DeepCopyInto(out *TagThrottleStatus) {
+	*out = *in
+	out.TagThrottle = in.TagThrottle
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TagThrottleStatus.
+func (in *TagThrottleStatus) DeepCopy() *TagThrottleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TagThrottleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaintReplacementOption) DeepCopyInto(out *TaintReplacementOption) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaintReplacementOption.
+func (in *TaintReplacementOption) DeepCopy() *TaintReplacementOption {
+	if in == nil {
+		return nil
+	}
+	out := new(TaintReplacementOption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TraceLogConfig) DeepCopyInto(out *TraceLogConfig) {
+	*out = *in
+	if in.RollSizeBytes != nil {
+		in, out := &in.RollSizeBytes, &out.RollSizeBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxLogsSizeBytes != nil {
+		in, out := &in.MaxLogsSizeBytes, &out.MaxLogsSizeBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LogForwarder != nil {
+		in, out := &in.LogForwarder, &out.LogForwarder
+		*out = new(corev1.Container)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeSizeLimit != nil {
+		in, out := &in.VolumeSizeLimit, &out.VolumeSizeLimit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.VolumeClaimTemplate != nil {
+		in, out := &in.VolumeClaimTemplate, &out.VolumeClaimTemplate
+		*out = new(corev1.PersistentVolumeClaimTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TraceLogConfig.
+func (in *TraceLogConfig) DeepCopy() *TraceLogConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TraceLogConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VersionFlags) DeepCopyInto(out *VersionFlags) {
 	*out = *in
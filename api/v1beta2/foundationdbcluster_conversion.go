@@ -0,0 +1,218 @@
+/*
+Copyright 2021 FoundationDB project authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// DeprecatedFieldsAnnotation is the annotation that holds the fields from the
+// v1beta1 spec that have no equivalent in v1beta2, so that they survive a
+// round trip through v1beta2.
+const DeprecatedFieldsAnnotation = "foundationdb.org/v1beta1-deprecated-fields"
+
+// ConvertTo converts this cluster to the hub version.
+func (cluster *FoundationDBCluster) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1beta1.FoundationDBCluster)
+	dst.ObjectMeta = *cluster.ObjectMeta.DeepCopy()
+	dst.Status = *cluster.Status.DeepCopy()
+
+	spec := cluster.Spec.DeepCopy()
+	dst.Spec = v1beta1.FoundationDBClusterSpec{}
+
+	annotation, present := dst.Annotations[DeprecatedFieldsAnnotation]
+	if present {
+		err := json.Unmarshal([]byte(annotation), &dst.Spec)
+		if err != nil {
+			return err
+		}
+
+		delete(dst.Annotations, DeprecatedFieldsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+
+	dst.Spec.Version = spec.Version
+	dst.Spec.DatabaseConfiguration = spec.DatabaseConfiguration
+	dst.Spec.Processes = spec.Processes
+	dst.Spec.ProcessCounts = spec.ProcessCounts
+	dst.Spec.SeedConnectionString = spec.SeedConnectionString
+	dst.Spec.PartialConnectionString = spec.PartialConnectionString
+	dst.Spec.FaultDomain = spec.FaultDomain
+	dst.Spec.InstancesToRemove = spec.ProcessGroupsToRemove
+	dst.Spec.InstancesToRemoveWithoutExclusion = spec.ProcessGroupsToRemoveWithoutExclusion
+	dst.Spec.ConfigMap = spec.ConfigMap
+	dst.Spec.MainContainer = spec.MainContainer
+	dst.Spec.SidecarContainer = spec.SidecarContainer
+	dst.Spec.TrustedCAs = spec.TrustedCAs
+	dst.Spec.SidecarVariables = spec.SidecarVariables
+	dst.Spec.LogGroup = spec.LogGroup
+	dst.Spec.DataCenter = spec.DataCenter
+	dst.Spec.DataHall = spec.DataHall
+	dst.Spec.AutomationOptions = spec.AutomationOptions
+	dst.Spec.InstanceIDPrefix = spec.ProcessGroupIDPrefix
+	dst.Spec.LockOptions = spec.LockOptions
+	dst.Spec.Routing = spec.Routing
+	dst.Spec.IgnoreUpgradabilityChecks = spec.IgnoreUpgradabilityChecks
+	dst.Spec.Buggify = spec.Buggify
+	dst.Spec.StorageServersPerPod = spec.StorageServersPerPod
+	dst.Spec.LogServersPerPod = spec.LogServersPerPod
+	dst.Spec.MinimumUptimeSecondsForBounce = spec.MinimumUptimeSecondsForBounce
+	dst.Spec.MaximumTotalClientsForBounce = spec.MaximumTotalClientsForBounce
+	dst.Spec.MaximumClientWaitSecondsForBounce = spec.MaximumClientWaitSecondsForBounce
+	dst.Spec.ReplaceInstancesWhenResourcesChange = spec.ReplaceProcessGroupsWhenResourcesChange
+	dst.Spec.Skip = spec.Skip
+	dst.Spec.CoordinatorSelection = spec.CoordinatorSelection
+	dst.Spec.CoordinatorCount = spec.CoordinatorCount
+	dst.Spec.LabelConfig = spec.LabelConfig
+	dst.Spec.UseExplicitListenAddress = spec.UseExplicitListenAddress
+	dst.Spec.StatusSummaryIntervalSeconds = spec.StatusSummaryIntervalSeconds
+	dst.Spec.MetricsExporter = spec.MetricsExporter
+	dst.Spec.TraceLogs = spec.TraceLogs
+	dst.Spec.DeletionOptions = spec.DeletionOptions
+	dst.Spec.ImageType = spec.ImageType
+	dst.Spec.PodUpdateStrategy = spec.PodUpdateStrategy
+	dst.Spec.ClientConfig = spec.ClientConfig
+	dst.Spec.TagThrottles = spec.TagThrottles
+	dst.Spec.TagQuotas = spec.TagQuotas
+	dst.Spec.ConsistencyCheck = spec.ConsistencyCheck
+	dst.Spec.ProcessRestartOptions = spec.ProcessRestartOptions
+	dst.Spec.SeedBackup = spec.SeedBackup
+	dst.Spec.ExcludedLocalities = spec.ExcludedLocalities
+	dst.Spec.PrimaryDataCenter = spec.PrimaryDataCenter
+	dst.Spec.DatabaseKnobs = spec.DatabaseKnobs
+	dst.Spec.AuditLog = spec.AuditLog
+	dst.Spec.BinaryChecksums = spec.BinaryChecksums
+	dst.Spec.ObserveOnly = spec.ObserveOnly
+	dst.Spec.ProcessGroupOverrides = spec.ProcessGroupOverrides
+
+	return nil
+}
+
+// ConvertFrom converts the hub version to this version.
+func (cluster *FoundationDBCluster) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1beta1.FoundationDBCluster)
+	cluster.ObjectMeta = *src.ObjectMeta.DeepCopy()
+	cluster.Status = *src.Status.DeepCopy()
+
+	spec := src.Spec.DeepCopy()
+	cluster.Spec = FoundationDBClusterSpec{
+		Version:                                 spec.Version,
+		DatabaseConfiguration:                   spec.DatabaseConfiguration,
+		Processes:                               spec.Processes,
+		ProcessCounts:                           spec.ProcessCounts,
+		SeedConnectionString:                    spec.SeedConnectionString,
+		PartialConnectionString:                 spec.PartialConnectionString,
+		FaultDomain:                             spec.FaultDomain,
+		ProcessGroupsToRemove:                   spec.InstancesToRemove,
+		ProcessGroupsToRemoveWithoutExclusion:   spec.InstancesToRemoveWithoutExclusion,
+		ConfigMap:                               spec.ConfigMap,
+		MainContainer:                           spec.MainContainer,
+		SidecarContainer:                        spec.SidecarContainer,
+		TrustedCAs:                              spec.TrustedCAs,
+		SidecarVariables:                        spec.SidecarVariables,
+		LogGroup:                                spec.LogGroup,
+		DataCenter:                              spec.DataCenter,
+		DataHall:                                spec.DataHall,
+		AutomationOptions:                       spec.AutomationOptions,
+		ProcessGroupIDPrefix:                    spec.InstanceIDPrefix,
+		LockOptions:                             spec.LockOptions,
+		Routing:                                 spec.Routing,
+		IgnoreUpgradabilityChecks:               spec.IgnoreUpgradabilityChecks,
+		Buggify:                                 spec.Buggify,
+		StorageServersPerPod:                    spec.StorageServersPerPod,
+		LogServersPerPod:                        spec.LogServersPerPod,
+		MinimumUptimeSecondsForBounce:           spec.MinimumUptimeSecondsForBounce,
+		MaximumTotalClientsForBounce:            spec.MaximumTotalClientsForBounce,
+		MaximumClientWaitSecondsForBounce:       spec.MaximumClientWaitSecondsForBounce,
+		ReplaceProcessGroupsWhenResourcesChange: spec.ReplaceInstancesWhenResourcesChange,
+		Skip:                                    spec.Skip,
+		CoordinatorSelection:                    spec.CoordinatorSelection,
+		CoordinatorCount:                        spec.CoordinatorCount,
+		LabelConfig:                             spec.LabelConfig,
+		UseExplicitListenAddress:                spec.UseExplicitListenAddress,
+		StatusSummaryIntervalSeconds:            spec.StatusSummaryIntervalSeconds,
+		MetricsExporter:                         spec.MetricsExporter,
+		TraceLogs:                               spec.TraceLogs,
+		DeletionOptions:                         spec.DeletionOptions,
+		ImageType:                               spec.ImageType,
+		PodUpdateStrategy:                       spec.PodUpdateStrategy,
+		ClientConfig:                            spec.ClientConfig,
+		TagThrottles:                            spec.TagThrottles,
+		TagQuotas:                               spec.TagQuotas,
+		ConsistencyCheck:                        spec.ConsistencyCheck,
+		ProcessRestartOptions:                   spec.ProcessRestartOptions,
+		SeedBackup:                              spec.SeedBackup,
+		ExcludedLocalities:                      spec.ExcludedLocalities,
+		PrimaryDataCenter:                       spec.PrimaryDataCenter,
+		DatabaseKnobs:                           spec.DatabaseKnobs,
+		AuditLog:                                spec.AuditLog,
+		BinaryChecksums:                         spec.BinaryChecksums,
+		ObserveOnly:                             spec.ObserveOnly,
+		ProcessGroupOverrides:                   spec.ProcessGroupOverrides,
+	}
+
+	deprecatedFields := getDeprecatedFields(spec)
+	if reflect.DeepEqual(deprecatedFields, v1beta1.FoundationDBClusterSpec{}) {
+		return nil
+	}
+
+	annotation, err := json.Marshal(deprecatedFields)
+	if err != nil {
+		return err
+	}
+
+	if cluster.Annotations == nil {
+		cluster.Annotations = make(map[string]string)
+	}
+	cluster.Annotations[DeprecatedFieldsAnnotation] = string(annotation)
+
+	return nil
+}
+
+// getDeprecatedFields returns a spec that only contains the fields from the
+// v1beta1 spec that were removed in v1beta2.
+func getDeprecatedFields(spec *v1beta1.FoundationDBClusterSpec) v1beta1.FoundationDBClusterSpec {
+	return v1beta1.FoundationDBClusterSpec{
+		SidecarVersions:              spec.SidecarVersions,
+		UpdatePodsByReplacement:      spec.UpdatePodsByReplacement,
+		Services:                     spec.Services,
+		SidecarVersion:               spec.SidecarVersion,
+		PodLabels:                    spec.PodLabels,
+		Resources:                    spec.Resources,
+		InitContainers:               spec.InitContainers,
+		Containers:                   spec.Containers,
+		Volumes:                      spec.Volumes,
+		PodSecurityContext:           spec.PodSecurityContext,
+		AutomountServiceAccountToken: spec.AutomountServiceAccountToken,
+		NextInstanceID:               spec.NextInstanceID,
+		StorageClass:                 spec.StorageClass,
+		VolumeSize:                   spec.VolumeSize,
+		RunningVersion:               spec.RunningVersion,
+		ConnectionString:             spec.ConnectionString,
+		Configured:                   spec.Configured,
+		PodTemplate:                  spec.PodTemplate,
+		VolumeClaim:                  spec.VolumeClaim,
+		CustomParameters:             spec.CustomParameters,
+		PendingRemovals:              spec.PendingRemovals,
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2021 FoundationDB project authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+)
+
+var _ = Describe("[api] FoundationDBCluster conversion", func() {
+	var original *v1beta1.FoundationDBCluster
+
+	BeforeEach(func() {
+		original = &v1beta1.FoundationDBCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "sample-cluster",
+				Namespace:   "default",
+				Annotations: map[string]string{"custom": "value"},
+			},
+			Spec: v1beta1.FoundationDBClusterSpec{
+				Version: "6.2.30",
+				DatabaseConfiguration: v1beta1.DatabaseConfiguration{
+					RedundancyMode: v1beta1.RedundancyModeDouble,
+				},
+				ProcessCounts: v1beta1.ProcessCounts{
+					Storage: 5,
+				},
+				InstancesToRemove:                   []string{"storage-1"},
+				InstancesToRemoveWithoutExclusion:   []string{"storage-2"},
+				InstanceIDPrefix:                    "dc1",
+				ReplaceInstancesWhenResourcesChange: pointer.Bool(true),
+			},
+			Status: v1beta1.FoundationDBClusterStatus{
+				RunningVersion: "6.2.30",
+			},
+		}
+	})
+
+	When("converting a cluster without deprecated fields", func() {
+		var converted *FoundationDBCluster
+
+		BeforeEach(func() {
+			converted = &FoundationDBCluster{}
+			Expect(converted.ConvertFrom(original)).To(Succeed())
+		})
+
+		It("should rename the fields", func() {
+			Expect(converted.Spec.Version).To(Equal("6.2.30"))
+			Expect(converted.Spec.DatabaseConfiguration.RedundancyMode).To(Equal(v1beta1.RedundancyModeDouble))
+			Expect(converted.Spec.ProcessCounts.Storage).To(Equal(5))
+			Expect(converted.Spec.ProcessGroupsToRemove).To(Equal([]string{"storage-1"}))
+			Expect(converted.Spec.ProcessGroupsToRemoveWithoutExclusion).To(Equal([]string{"storage-2"}))
+			Expect(converted.Spec.ProcessGroupIDPrefix).To(Equal("dc1"))
+			Expect(converted.Spec.ReplaceProcessGroupsWhenResourcesChange).To(Equal(pointer.Bool(true)))
+			Expect(converted.Status.RunningVersion).To(Equal("6.2.30"))
+		})
+
+		It("should not add the deprecated fields annotation", func() {
+			Expect(converted.Annotations).To(Equal(map[string]string{"custom": "value"}))
+		})
+
+		It("should convert back to the original cluster", func() {
+			roundTrip := &v1beta1.FoundationDBCluster{}
+			Expect(converted.ConvertTo(roundTrip)).To(Succeed())
+			Expect(roundTrip).To(Equal(original))
+		})
+	})
+
+	When("converting a cluster with deprecated fields", func() {
+		var converted *FoundationDBCluster
+
+		BeforeEach(func() {
+			original.Spec.PodLabels = map[string]string{"app": "fdb"}
+			original.Spec.VolumeSize = "16G"
+			original.Spec.PodTemplate = &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "foundationdb"}},
+				},
+			}
+			converted = &FoundationDBCluster{}
+			Expect(converted.ConvertFrom(original)).To(Succeed())
+		})
+
+		It("should store the deprecated fields in an annotation", func() {
+			Expect(converted.Annotations).To(HaveKey(DeprecatedFieldsAnnotation))
+			Expect(converted.Annotations).To(HaveKeyWithValue("custom", "value"))
+		})
+
+		It("should not modify the original cluster", func() {
+			Expect(original.Annotations).To(Equal(map[string]string{"custom": "value"}))
+		})
+
+		It("should restore the deprecated fields when converting back", func() {
+			roundTrip := &v1beta1.FoundationDBCluster{}
+			Expect(converted.ConvertTo(roundTrip)).To(Succeed())
+			Expect(roundTrip).To(Equal(original))
+		})
+
+		When("the cluster only has the deprecated fields annotation", func() {
+			BeforeEach(func() {
+				original.Annotations = nil
+				converted = &FoundationDBCluster{}
+				Expect(converted.ConvertFrom(original)).To(Succeed())
+			})
+
+			It("should remove the annotations when converting back", func() {
+				roundTrip := &v1beta1.FoundationDBCluster{}
+				Expect(converted.ConvertTo(roundTrip)).To(Succeed())
+				Expect(roundTrip.Annotations).To(BeNil())
+				Expect(roundTrip).To(Equal(original))
+			})
+		})
+	})
+
+	When("checking the scheme", func() {
+		It("should be convertible", func() {
+			scheme := runtime.NewScheme()
+			Expect(v1beta1.AddToScheme(scheme)).To(Succeed())
+			Expect(AddToScheme(scheme)).To(Succeed())
+
+			convertible, err := conversion.IsConvertible(scheme, &FoundationDBCluster{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(convertible).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,351 @@
+/*
+Copyright 2021 FoundationDB project authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta2
+
+import (
+	"github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=fdb
+// +kubebuilder:subresource:status
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Generation",type="integer",JSONPath=".metadata.generation",description="Latest generation of the spec",priority=0
+// +kubebuilder:printcolumn:name="Reconciled",type="integer",JSONPath=".status.generations.reconciled",description="Last reconciled generation of the spec",priority=0
+// +kubebuilder:printcolumn:name="Available",type="boolean",JSONPath=".status.health.available",description="Database available",priority=0
+// +kubebuilder:printcolumn:name="FullReplication",type="boolean",JSONPath=".status.health.fullReplication",description="Database fully replicated",priority=0
+// +kubebuilder:printcolumn:name="Healthy",type="boolean",JSONPath=".status.health.healthy",description="Database healthy",priority=1
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.runningVersion",description="Running version",priority=0
+// +kubebuilder:printcolumn:name="Redundancy",type="string",JSONPath=".status.databaseConfiguration.redundancy_mode",description="Running redundancy mode",priority=0
+// +kubebuilder:printcolumn:name="Desired",type="integer",JSONPath=".status.desiredProcessGroups",description="Desired process groups",priority=0
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyProcessGroups",description="Ready process groups",priority=0
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+
+// FoundationDBCluster is the Schema for the foundationdbclusters API
+type FoundationDBCluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FoundationDBClusterSpec           `json:"spec,omitempty"`
+	Status v1beta1.FoundationDBClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FoundationDBClusterList contains a list of FoundationDBCluster
+type FoundationDBClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FoundationDBCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FoundationDBCluster{}, &FoundationDBClusterList{})
+}
+
+// FoundationDBClusterSpec defines the desired state of a cluster.
+//
+// This removes the fields that are deprecated in v1beta1, and uses process
+// group IDs in place of instance IDs in the field names. The nested types are
+// shared with v1beta1.
+type FoundationDBClusterSpec struct {
+	// Version defines the version of FoundationDB the cluster should run.
+	// +kubebuilder:validation:Pattern:=^(\d+)\.(\d+)\.(\d+)$
+	Version string `json:"version"`
+
+	// DatabaseConfiguration defines the database configuration.
+	DatabaseConfiguration v1beta1.DatabaseConfiguration `json:"databaseConfiguration,omitempty"`
+
+	// Processes defines process-level settings, including the templates for
+	// the pods and the volume claims of each process class.
+	Processes map[v1beta1.ProcessClass]v1beta1.ProcessSettings `json:"processes,omitempty"`
+
+	// ProcessGroupOverrides defines settings for the pods of individual
+	// process groups, keyed by the process group ID. These settings take
+	// precedence over the settings of the process class, so a single pod can
+	// be pinned to a node or resized without changing the whole class.
+	ProcessGroupOverrides map[string]v1beta1.ProcessGroupOverride `json:"processGroupOverrides,omitempty"`
+
+	// ProcessCounts defines the number of processes to configure for each
+	// process class. You can generally omit this, to allow the operator to
+	// infer the process counts based on the database configuration.
+	ProcessCounts v1beta1.ProcessCounts `json:"processCounts,omitempty"`
+
+	// SeedConnectionString provides a connection string for the initial
+	// reconciliation.
+	//
+	// After the initial reconciliation, this will not be used.
+	// +kubebuilder:validation:Pattern:=`^[A-Za-z0-9_]+:[A-Za-z0-9]+@([0-9A-Za-z.-]+|\[[0-9A-Fa-f:.]+\]):[0-9]+(:tls)?(,([0-9A-Za-z.-]+|\[[0-9A-Fa-f:.]+\]):[0-9]+(:tls)?)*$`
+	SeedConnectionString string `json:"seedConnectionString,omitempty"`
+
+	// PartialConnectionString provides a way to specify part of the
+	// connection string (e.g. the database name and coordinator generation)
+	// without specifying the entire string. This does not allow for setting
+	// the coordinator IPs. If `SeedConnectionString` is set,
+	// `PartialConnectionString` will have no effect. They cannot be used
+	// together.
+	PartialConnectionString v1beta1.ConnectionString `json:"partialConnectionString,omitempty"`
+
+	// FaultDomain defines the rules for what fault domain to replicate across.
+	FaultDomain v1beta1.FoundationDBClusterFaultDomain `json:"faultDomain,omitempty"`
+
+	// ProcessGroupsToRemove defines the process groups that we should remove
+	// from the cluster. This list contains the process group IDs.
+	ProcessGroupsToRemove []string `json:"processGroupsToRemove,omitempty"`
+
+	// ProcessGroupsToRemoveWithoutExclusion defines the process groups that
+	// we should remove from the cluster without excluding them. This list
+	// contains the process group IDs.
+	//
+	// This should be used for cases where a pod does not have an IP address and
+	// you want to remove it and destroy its volume without confirming the data
+	// is fully replicated.
+	ProcessGroupsToRemoveWithoutExclusion []string `json:"processGroupsToRemoveWithoutExclusion,omitempty"`
+
+	// ConfigMap allows customizing the config map the operator creates.
+	ConfigMap *corev1.ConfigMap `json:"configMap,omitempty"`
+
+	// MainContainer defines customization for the foundationdb container.
+	MainContainer v1beta1.ContainerOverrides `json:"mainContainer,omitempty"`
+
+	// SidecarContainer defines customization for the
+	// foundationdb-kubernetes-sidecar container.
+	SidecarContainer v1beta1.ContainerOverrides `json:"sidecarContainer,omitempty"`
+
+	// TrustedCAs defines a list of root CAs the cluster should trust, in PEM
+	// format.
+	TrustedCAs []string `json:"trustedCAs,omitempty"`
+
+	// SidecarVariables defines Custom variables that the sidecar should make
+	// available for substitution in the monitor conf file.
+	SidecarVariables []string `json:"sidecarVariables,omitempty"`
+
+	// LogGroup defines the log group to use for the trace logs for the cluster.
+	LogGroup string `json:"logGroup,omitempty"`
+
+	// DataCenter defines the data center where these processes are running.
+	DataCenter string `json:"dataCenter,omitempty"`
+
+	// DataHall defines the data hall where these processes are running.
+	DataHall string `json:"dataHall,omitempty"`
+
+	// AutomationOptions defines customization for enabling or disabling certain
+	// operations in the operator.
+	AutomationOptions v1beta1.FoundationDBClusterAutomationOptions `json:"automationOptions,omitempty"`
+
+	// ProcessGroupIDPrefix defines a prefix to append to the process group
+	// IDs in the locality fields.
+	//
+	// This must be a valid Kubernetes label value. See
+	// https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#syntax-and-character-set
+	// for more details on that.
+	ProcessGroupIDPrefix string `json:"processGroupIDPrefix,omitempty"`
+
+	// LockOptions allows customizing how we manage locks for global operations.
+	LockOptions v1beta1.LockOptions `json:"lockOptions,omitempty"`
+
+	// Routing defines the configuration for routing to our pods.
+	Routing v1beta1.RoutingConfig `json:"routing,omitempty"`
+
+	// IgnoreUpgradabilityChecks determines whether we should skip the check for
+	// client compatibility when performing an upgrade.
+	IgnoreUpgradabilityChecks bool `json:"ignoreUpgradabilityChecks,omitempty"`
+
+	// Buggify defines settings for injecting faults into a cluster for testing.
+	Buggify v1beta1.BuggifyConfig `json:"buggify,omitempty"`
+
+	// StorageServersPerPod defines how many Storage Servers should run in
+	// a single process group (Pod). This number defines the number of
+	// processes running in one Pod whereas the ProcessCounts defines the
+	// number of Pods created. This means that you end up with
+	// ProcessCounts["storage"] * StorageServersPerPod storage processes
+	// +kubebuilder:validation:Minimum=0
+	StorageServersPerPod int `json:"storageServersPerPod,omitempty"`
+
+	// LogServersPerPod defines how many Log Servers should run in
+	// a single process group (Pod). This applies to processes with the log
+	// and transaction class. Every process in the Pod gets its own port and
+	// data directory on the same disk.
+	// This means that you end up with ProcessCounts["log"] * LogServersPerPod
+	// log processes
+	// +kubebuilder:validation:Minimum=0
+	LogServersPerPod int `json:"logServersPerPod,omitempty"`
+
+	// MinimumUptimeSecondsForBounce defines the minimum time, in seconds, that the
+	// processes in the cluster must have been up for before the operator can
+	// execute a bounce.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default:=600
+	MinimumUptimeSecondsForBounce int `json:"minimumUptimeSecondsForBounce,omitempty"`
+
+	// MaximumTotalClientsForBounce defines the maximum number of clients
+	// that can be connected to the database when the operator executes a
+	// bounce. This counts every connected client, whether or not it has
+	// transactions in flight, since the database status does not report the
+	// transactions of each client. If more clients are connected, the
+	// operator waits for them to disconnect, up to the
+	// MaximumClientWaitSecondsForBounce. By default the operator does not
+	// check the connected clients.
+	// +kubebuilder:validation:Minimum=0
+	MaximumTotalClientsForBounce *int `json:"maximumTotalClientsForBounce,omitempty"`
+
+	// MaximumClientWaitSecondsForBounce defines the maximum time, in seconds,
+	// that the operator waits for clients to disconnect before it executes a
+	// bounce anyway. The time is measured from when the processes were first
+	// seen with an incorrect command line.
+	// The default is 600 seconds, or 10 minutes.
+	// +kubebuilder:validation:Minimum=0
+	MaximumClientWaitSecondsForBounce *int `json:"maximumClientWaitSecondsForBounce,omitempty"`
+
+	// ReplaceProcessGroupsWhenResourcesChange defines if a process group
+	// should be replaced when the resource requirements are increased. This
+	// can be useful with the combination of local storage.
+	// +kubebuilder:default:=false
+	ReplaceProcessGroupsWhenResourcesChange *bool `json:"replaceProcessGroupsWhenResourcesChange,omitempty"`
+
+	// Skip defines if the cluster should be skipped for reconciliation. This can be useful for
+	// investigating in issues or if the environment is unstable. The operator
+	// will still update the status of a skipped cluster.
+	// +kubebuilder:default:=false
+	Skip bool `json:"skip,omitempty"`
+
+	// ObserveOnly defines that the operator does not manage the cluster. The
+	// operator connects to an existing database through the
+	// SeedConnectionString and only updates the status of the cluster,
+	// without managing any resources or changing the database. This can be
+	// used to monitor existing clusters before migrating them. A cluster that
+	// was observed cannot be switched to being managed by the operator.
+	// +kubebuilder:default:=false
+	ObserveOnly bool `json:"observeOnly,omitempty"`
+
+	// CoordinatorSelection defines which process classes are eligible for coordinator selection.
+	// If empty all stateful processes classes are equally eligible.
+	// A higher priority means that a process class is preferred over another process class.
+	// If the FoundationDB cluster is spans across multiple Kubernetes clusters or DCs the
+	// CoordinatorSelection must match in all FoundationDB cluster resources otherwise
+	// the coordinator selection process could conflict.
+	CoordinatorSelection []v1beta1.CoordinatorSelectionSetting `json:"coordinatorSelection,omitempty"`
+
+	// CoordinatorCount defines the number of coordinators the operator should
+	// recruit. This can only be used to increase the number of coordinators,
+	// values below the number required for the redundancy mode will be
+	// ignored.
+	// +kubebuilder:validation:Minimum=1
+	CoordinatorCount *int `json:"coordinatorCount,omitempty"`
+
+	// LabelConfig allows customizing labels used by the operator.
+	LabelConfig v1beta1.LabelConfig `json:"labels,omitempty"`
+
+	// UseExplicitListenAddress determines if we should add a listen address
+	// that is separate from the public address.
+	UseExplicitListenAddress *bool `json:"useExplicitListenAddress,omitempty"`
+
+	// StatusSummaryIntervalSeconds defines how often the operator publishes a
+	// summary of the database status into the `<cluster>-status` ConfigMap.
+	// If this is not set, the summary will not be published.
+	// +kubebuilder:validation:Minimum=1
+	StatusSummaryIntervalSeconds *int `json:"statusSummaryIntervalSeconds,omitempty"`
+
+	// MetricsExporter allows configuring a deployment that exports metrics
+	// for the cluster in the Prometheus format.
+	MetricsExporter v1beta1.MetricsExporterConfig `json:"metricsExporter,omitempty"`
+
+	// TraceLogs allows configuring how the FoundationDB processes write
+	// their trace logs, and how those logs are shipped.
+	TraceLogs v1beta1.TraceLogConfig `json:"traceLogs,omitempty"`
+
+	// DeletionOptions defines how the operator tears down the cluster when
+	// the cluster resource is deleted.
+	DeletionOptions v1beta1.DeletionOptions `json:"deletionOptions,omitempty"`
+
+	// ImageType defines the type of images that the pods use.
+	//
+	// With the `split` type the main container runs fdbmonitor and a sidecar
+	// container provides the configuration. With the `unified` type the main
+	// container runs fdbserver through the kubernetes monitor, which reads
+	// the process arguments from the ConfigMap.
+	// The default is `split`.
+	// +kubebuilder:validation:Enum=split;unified
+	ImageType *v1beta1.ImageType `json:"imageType,omitempty"`
+
+	// PodUpdateStrategy defines how the operator applies changes to the pod
+	// spec.
+	//
+	// With the `Recreate` strategy the operator deletes the pods zone by zone
+	// and recreates them with the new spec. With the `Replacement` strategy
+	// the operator replaces the process groups with new process groups, and
+	// excludes the old processes before removing them. With the `Manual`
+	// strategy the operator recreates the pods like the `Recreate` strategy,
+	// but only once the update has been approved through the
+	// foundationdb.org/approve-pod-updates annotation.
+	// The default is `Recreate`.
+	// +kubebuilder:validation:Enum=Recreate;Replacement;Manual
+	PodUpdateStrategy *v1beta1.PodUpdateStrategy `json:"podUpdateStrategy,omitempty"`
+
+	// ClientConfig defines the config map and secret that the operator
+	// publishes for client applications.
+	ClientConfig v1beta1.ClientConfig `json:"clientConfig,omitempty"`
+
+	// TagThrottles defines the throttles that the operator should apply to
+	// transactions with specific tags. This is only supported on FDB 6.3
+	// and later.
+	TagThrottles []v1beta1.TagThrottle `json:"tagThrottles,omitempty"`
+
+	// TagQuotas defines the throughput quotas that the operator should set
+	// for transactions with specific tags. This is only supported on FDB 7.2
+	// and later.
+	TagQuotas []v1beta1.TagQuota `json:"tagQuotas,omitempty"`
+
+	// ConsistencyCheck defines when the operator lets the consistency
+	// checker verify the data in the database.
+	ConsistencyCheck v1beta1.ConsistencyCheckConfig `json:"consistencyCheck,omitempty"`
+
+	// AuditLog defines whether the operator records the actions it takes
+	// on the cluster in an audit log.
+	AuditLog v1beta1.AuditLogConfig `json:"auditLog,omitempty"`
+
+	// BinaryChecksums defines the expected SHA-256 checksums of the binaries
+	// that the sidecar copies into the shared volume, in the form of version
+	// to binary name to the hex-encoded checksum. When checksums are defined
+	// for the version the cluster is running, the operator verifies them
+	// before it considers a pod ready to be bounced.
+	BinaryChecksums map[string]v1beta1.BinaryChecksums `json:"binaryChecksums,omitempty"`
+
+	// ProcessRestartOptions defines how fdbmonitor restarts the fdbserver
+	// processes when they exit.
+	ProcessRestartOptions v1beta1.ProcessRestartOptions `json:"processRestartOptions,omitempty"`
+
+	// SeedBackup defines a backup that the operator restores into the
+	// cluster once the database is configured.
+	SeedBackup *v1beta1.SeedBackup `json:"seedBackup,omitempty"`
+
+	// ExcludedLocalities defines localities whose processes the operator
+	// excludes from the database.
+	ExcludedLocalities []v1beta1.LocalityExclusion `json:"excludedLocalities,omitempty"`
+
+	// PrimaryDataCenter defines the data center that should serve as the
+	// primary in a multi-region configuration.
+	PrimaryDataCenter string `json:"primaryDataCenter,omitempty"`
+
+	// DatabaseKnobs defines the server knobs that the operator should set
+	// in the configuration database, in the form of knob name to value.
+	// The knob names must not have a knob_ prefix. This is only supported
+	// on FDB 7.1 and later.
+	DatabaseKnobs map[string]string `json:"databaseKnobs,omitempty"`
+}
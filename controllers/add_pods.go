@@ -23,6 +23,7 @@ package controllers
 import (
 	ctx "context"
 	"fmt"
+	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/podmanager"
 
@@ -40,7 +41,7 @@ type addPods struct{}
 // reconcile runs the reconciler's work.
 func (a addPods) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
 	configMap, err := internal.GetConfigMap(cluster)
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "addPods")
+	logger := getLogger(context, cluster, "addPods")
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -63,6 +64,10 @@ func (a addPods) reconcile(r *FoundationDBClusterReconciler, context ctx.Context
 
 	podMap := internal.CreatePodMap(cluster, pods)
 
+	var creations []func() error
+	// A pod that cannot be created yet does not hold back the other pods, so
+	// the requeue for it is only returned once the other pods are created.
+	var pendingRequeue *requeue
 	for _, processGroup := range cluster.Status.ProcessGroups {
 		_, podExists := podMap[processGroup.ProcessGroupID]
 		if !podExists && !processGroup.Remove {
@@ -77,7 +82,7 @@ func (a addPods) reconcile(r *FoundationDBClusterReconciler, context ctx.Context
 				return &requeue{curError: err}
 			}
 
-			serverPerPod, err := internal.GetStorageServersPerPodForPod(pod)
+			serverPerPod, err := internal.GetServersPerPodForPod(pod, processGroup.ProcessClass)
 			if err != nil {
 				return &requeue{curError: err}
 			}
@@ -95,20 +100,52 @@ func (a addPods) reconcile(r *FoundationDBClusterReconciler, context ctx.Context
 				if err != nil {
 					return &requeue{curError: err}
 				}
-				ip := service.Spec.ClusterIP
-				if ip == "" {
-					logger.Info("Service does not have an IP address", "processGroupID", processGroup.ProcessGroupID)
-					return &requeue{message: fmt.Sprintf("Service %s does not have an IP address", service.Name)}
+
+				if cluster.UsesNodePorts(processGroup.ProcessClass) {
+					nodePorts, hasNodePorts := internal.GetNodePortAnnotations(cluster, processGroup.ProcessClass, service)
+					if !hasNodePorts {
+						logger.Info("Service does not have node ports", "processGroupID", processGroup.ProcessGroupID)
+						if pendingRequeue == nil {
+							pendingRequeue = &requeue{message: fmt.Sprintf("Service %s does not have node ports", service.Name)}
+						}
+						continue
+					}
+
+					for key, value := range nodePorts {
+						pod.Annotations[key] = value
+					}
+				} else {
+					ip := internal.GetPublicIPFromService(service)
+					if ip == "" {
+						logger.Info("Service does not have an IP address", "processGroupID", processGroup.ProcessGroupID)
+						if pendingRequeue == nil {
+							if internal.HasOnlyHostnameIngress(service) {
+								pendingRequeue = &requeue{message: fmt.Sprintf("Load balancer for service %s only has a hostname, but the processes need an IP address to advertise", service.Name), delay: 1 * time.Minute}
+							} else {
+								pendingRequeue = &requeue{message: fmt.Sprintf("Service %s does not have an IP address", service.Name)}
+							}
+						}
+						continue
+					}
+					pod.Annotations[fdbtypes.PublicIPAnnotation] = ip
 				}
-				pod.Annotations[fdbtypes.PublicIPAnnotation] = ip
 			}
 
-			err = r.PodLifecycleManager.CreatePod(r, context, pod)
-			if err != nil {
-				return &requeue{curError: err}
-			}
+			creations = append(creations, func() error {
+				return r.PodLifecycleManager.CreatePod(r, context, pod)
+			})
+		}
+	}
+
+	err = internal.RunConcurrently(r.MaxConcurrentResourceCreations, creations)
+	if err != nil {
+		quotaRequeue := checkQuotaExceeded(r, context, cluster, "pods", err)
+		if quotaRequeue != nil {
+			return quotaRequeue
 		}
+
+		return &requeue{curError: err}
 	}
 
-	return nil
+	return pendingRequeue
 }
@@ -22,16 +22,34 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"sort"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/podmanager"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// quotaExceededPodLifecycleManager provides a pod lifecycle manager that
+// fails to create pods because of a resource quota.
+type quotaExceededPodLifecycleManager struct {
+	podmanager.StandardPodLifecycleManager
+}
+
+// CreatePod returns an error from the resource quota admission plugin.
+func (manager quotaExceededPodLifecycleManager) CreatePod(_ client.Client, _ context.Context, pod *corev1.Pod) error {
+	return k8serrors.NewForbidden(schema.GroupResource{Resource: "pods"}, pod.Name, fmt.Errorf("exceeded quota: compute-resources, requested: pods=1, used: pods=10, limited: pods=10"))
+}
+
 var _ = Describe("add_pods", func() {
 	var cluster *fdbtypes.FoundationDBCluster
 	var err error
@@ -102,6 +120,130 @@ var _ = Describe("add_pods", func() {
 			Expect(lastPod.OwnerReferences).To(Equal(internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)))
 		})
 
+		When("creating pods concurrently", func() {
+			BeforeEach(func() {
+				clusterReconciler.MaxConcurrentResourceCreations = 3
+				for _, id := range []string{"storage-10", "storage-11", "storage-12"} {
+					cluster.Status.ProcessGroups = append(cluster.Status.ProcessGroups, fdbtypes.NewProcessGroupStatus(id, "storage", nil))
+				}
+			})
+
+			AfterEach(func() {
+				clusterReconciler.MaxConcurrentResourceCreations = 0
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should create all pods", func() {
+				Expect(newPods.Items).To(HaveLen(len(initialPods.Items) + 4))
+			})
+		})
+
+		When("the pod exceeds a resource quota", func() {
+			BeforeEach(func() {
+				clusterReconciler.PodLifecycleManager = quotaExceededPodLifecycleManager{}
+			})
+
+			AfterEach(func() {
+				clusterReconciler.PodLifecycleManager = podmanager.StandardPodLifecycleManager{}
+			})
+
+			It("should requeue with a delay", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).NotTo(HaveOccurred())
+				Expect(requeue.delay).To(Equal(quotaExceededDelayDuration))
+				Expect(requeue.message).To(ContainSubstring("exceeded quota: compute-resources"))
+			})
+
+			It("should not create any pods", func() {
+				Expect(newPods.Items).To(HaveLen(len(initialPods.Items)))
+			})
+
+			It("should set the quota condition", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionQuotaExceeded)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("QuotaExceeded"))
+				Expect(condition.Message).To(ContainSubstring("limited: pods=10"))
+			})
+
+			It("should record an event", func() {
+				events := &corev1.EventList{}
+				err = k8sClient.List(context.TODO(), events)
+				Expect(err).NotTo(HaveOccurred())
+				matchingEvents := []corev1.Event{}
+				for _, event := range events.Items {
+					if event.InvolvedObject.UID == cluster.ObjectMeta.UID && event.Reason == "QuotaExceeded" {
+						matchingEvents = append(matchingEvents, event)
+					}
+				}
+				Expect(matchingEvents).To(HaveLen(1))
+				Expect(matchingEvents[0].Type).To(Equal(corev1.EventTypeWarning))
+			})
+		})
+
+		When("the processes advertise node ports", func() {
+			BeforeEach(func() {
+				source := fdbtypes.PublicIPSourceService
+				serviceType := corev1.ServiceTypeNodePort
+				cluster.Spec.Routing.PublicIPSource = &source
+				cluster.Spec.Routing.PublicServiceType = &serviceType
+				Expect(addServices{}.reconcile(clusterReconciler, context.TODO(), cluster)).To(BeNil())
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should set the node ports of the service on the pod", func() {
+				lastPod := newPods.Items[len(newPods.Items)-1]
+				Expect(lastPod.Name).To(Equal("operator-test-1-storage-9"))
+
+				service := &corev1.Service{}
+				err = k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: lastPod.Namespace, Name: lastPod.Name}, service)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+				Expect(service.Spec.Ports).To(HaveLen(2))
+
+				Expect(lastPod.Annotations).To(HaveKeyWithValue(fdbtypes.NodePortTLSAnnotation, fmt.Sprintf("%d", service.Spec.Ports[0].NodePort)))
+				Expect(lastPod.Annotations).To(HaveKeyWithValue(fdbtypes.NodePortAnnotation, fmt.Sprintf("%d", service.Spec.Ports[1].NodePort)))
+				Expect(lastPod.Annotations).NotTo(HaveKey(fdbtypes.PublicIPAnnotation))
+			})
+		})
+
+		When("one of the services does not have an IP address yet", func() {
+			BeforeEach(func() {
+				cluster.Status.ProcessGroups = append(cluster.Status.ProcessGroups, fdbtypes.NewProcessGroupStatus("storage-10", "storage", nil))
+				source := fdbtypes.PublicIPSourceService
+				serviceType := corev1.ServiceTypeLoadBalancer
+				cluster.Spec.Routing.PublicIPSource = &source
+				cluster.Spec.Routing.PublicServiceType = &serviceType
+				Expect(addServices{}.reconcile(clusterReconciler, context.TODO(), cluster)).To(BeNil())
+
+				service := &corev1.Service{}
+				err = k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: "operator-test-1-storage-9"}, service)
+				Expect(err).NotTo(HaveOccurred())
+				service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: "192.168.0.9"}}
+				err = k8sClient.Status().Update(context.TODO(), service)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should requeue for the service without an IP address", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).NotTo(HaveOccurred())
+				Expect(requeue.message).To(Equal("Service operator-test-1-storage-10 does not have an IP address"))
+			})
+
+			It("should create the pod for the service with an IP address", func() {
+				Expect(newPods.Items).To(HaveLen(len(initialPods.Items) + 1))
+				lastPod := newPods.Items[len(newPods.Items)-1]
+				Expect(lastPod.Name).To(Equal("operator-test-1-storage-9"))
+				Expect(lastPod.Annotations).To(HaveKeyWithValue(fdbtypes.PublicIPAnnotation, "192.168.0.9"))
+			})
+		})
+
 		Context("when the process group is being removed", func() {
 			BeforeEach(func() {
 				cluster.Status.ProcessGroups[len(cluster.Status.ProcessGroups)-1].Remove = true
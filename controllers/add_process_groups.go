@@ -29,6 +29,7 @@ import (
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 )
@@ -65,6 +66,26 @@ func (a addProcessGroups) reconcile(r *FoundationDBClusterReconciler, context ct
 		}
 	}
 
+	// The IDs of process groups whose resources were retained are not reused,
+	// so that new process groups don't pick up the retained resources.
+	retainedProcessGroupIDs, err := getRetainedProcessGroupIDs(r, context, cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	for _, processGroupID := range retainedProcessGroupIDs {
+		class, num, err := podmanager.ParseProcessGroupID(processGroupID)
+		if err != nil {
+			continue
+		}
+
+		if processGroupIDs[class] == nil {
+			processGroupIDs[class] = make(map[int]bool)
+		}
+
+		processGroupIDs[class][num] = true
+	}
+
 	hasNewProcessGroups := false
 	for _, processClass := range fdbtypes.ProcessClasses {
 		desiredCount := desiredCounts[processClass]
@@ -109,3 +130,34 @@ func (a addProcessGroups) reconcile(r *FoundationDBClusterReconciler, context ct
 
 	return nil
 }
+
+// getRetainedProcessGroupIDs returns the IDs of the process groups whose PVCs
+// or services were retained when they were removed.
+func getRetainedProcessGroupIDs(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) ([]string, error) {
+	listOptions := []client.ListOption{
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{fdbtypes.RetainedFromClusterLabel: cluster.Name},
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	err := r.List(context, pvcs, listOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	services := &corev1.ServiceList{}
+	err = r.List(context, services, listOptions...)
+	if err != nil {
+		return nil, err
+	}
+
+	processGroupIDs := make([]string, 0, len(pvcs.Items)+len(services.Items))
+	for _, pvc := range pvcs.Items {
+		processGroupIDs = append(processGroupIDs, pvc.Annotations[fdbtypes.RetainedProcessGroupIDAnnotation])
+	}
+	for _, service := range services.Items {
+		processGroupIDs = append(processGroupIDs, service.Annotations[fdbtypes.RetainedProcessGroupIDAnnotation])
+	}
+
+	return processGroupIDs, nil
+}
@@ -38,6 +38,7 @@ type addPVCs struct{}
 
 // reconcile runs the reconciler's work.
 func (a addPVCs) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	var creations []func() error
 	for _, processGroup := range cluster.Status.ProcessGroups {
 		if processGroup.Remove {
 			continue
@@ -66,12 +67,20 @@ func (a addPVCs) reconcile(r *FoundationDBClusterReconciler, context ctx.Context
 
 			owner := internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
 			pvc.ObjectMeta.OwnerReferences = owner
-			err = r.Create(context, pvc)
+			creations = append(creations, func() error {
+				return r.Create(context, pvc)
+			})
+		}
+	}
 
-			if err != nil {
-				return &requeue{curError: err}
-			}
+	err := internal.RunConcurrently(r.MaxConcurrentResourceCreations, creations)
+	if err != nil {
+		quotaRequeue := checkQuotaExceeded(r, context, cluster, "PVCs", err)
+		if quotaRequeue != nil {
+			return quotaRequeue
 		}
+
+		return &requeue{curError: err}
 	}
 
 	return nil
@@ -103,6 +103,27 @@ var _ = Describe("add_pvcs", func() {
 			Expect(lastPVC.OwnerReferences).To(Equal(internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)))
 		})
 
+		When("creating PVCs concurrently", func() {
+			BeforeEach(func() {
+				clusterReconciler.MaxConcurrentResourceCreations = 3
+				for _, id := range []string{"storage-10", "storage-11", "storage-12"} {
+					cluster.Status.ProcessGroups = append(cluster.Status.ProcessGroups, fdbtypes.NewProcessGroupStatus(id, "storage", nil))
+				}
+			})
+
+			AfterEach(func() {
+				clusterReconciler.MaxConcurrentResourceCreations = 0
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should create all PVCs", func() {
+				Expect(newPVCs.Items).To(HaveLen(len(initialPVCs.Items) + 4))
+			})
+		})
+
 		Context("when the process group is being removed", func() {
 			BeforeEach(func() {
 				cluster.Status.ProcessGroups[len(cluster.Status.ProcessGroups)-1].Remove = true
@@ -42,6 +42,7 @@ type addServices struct{}
 func (a addServices) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
 	service := internal.GetHeadlessService(cluster)
 	if service != nil {
+		service.ObjectMeta.OwnerReferences = internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
 		existingService := &corev1.Service{}
 		err := r.Get(context, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, existingService)
 		if err == nil {
@@ -54,9 +55,7 @@ func (a addServices) reconcile(r *FoundationDBClusterReconciler, context ctx.Con
 			if !k8serrors.IsNotFound(err) {
 				return &requeue{curError: err}
 			}
-			owner := internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
-			service.ObjectMeta.OwnerReferences = owner
-			err = r.Create(context, service)
+			err = internal.ApplyObject(r, context, service)
 			if err != nil {
 				return &requeue{curError: err}
 			}
@@ -90,7 +89,7 @@ func (a addServices) reconcile(r *FoundationDBClusterReconciler, context ctx.Con
 				}
 			} else if k8serrors.IsNotFound(err) {
 				// Create a new service
-				err = r.Create(context, service)
+				err = internal.ApplyObject(r, context, service)
 
 				if err != nil {
 					return &requeue{curError: err}
@@ -107,23 +106,18 @@ func (a addServices) reconcile(r *FoundationDBClusterReconciler, context ctx.Con
 // updateServices updates selected safe fields on a service based on a new
 // service definition.
 func updateService(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, currentService *corev1.Service, newService *corev1.Service) error {
-	serviceLog := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "service", currentService.Name)
-	originalSpec := currentService.Spec.DeepCopy()
+	serviceLog := getLogger(context, cluster, "addServices").WithValues("service", currentService.Name)
 
-	currentService.Spec.Selector = newService.Spec.Selector
-
-	needsUpdate := !equality.Semantic.DeepEqual(currentService.Spec, *originalSpec)
-	metadata := currentService.ObjectMeta
-	if mergeLabelsInMetadata(&metadata, newService.ObjectMeta) {
+	needsUpdate := !equality.Semantic.DeepEqual(currentService.Spec.Selector, newService.Spec.Selector)
+	if currentService.Spec.Type != newService.Spec.Type {
 		needsUpdate = true
 	}
-	if mergeAnnotations(&metadata, newService.ObjectMeta) {
+	if !metadataMatches(currentService.ObjectMeta, newService.ObjectMeta) {
 		needsUpdate = true
 	}
 	if needsUpdate {
-		currentService.ObjectMeta = metadata
 		serviceLog.Info("Updating service")
-		return r.Update(context, currentService)
+		return internal.ApplyObject(r, context, newService)
 	}
 	return nil
 }
@@ -30,6 +30,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
 
@@ -116,6 +117,24 @@ var _ = Describe("add_services", func() {
 				}
 			})
 		})
+
+		Context("with custom service metadata", func() {
+			BeforeEach(func() {
+				cluster.Spec.Routing.ServiceMetadata = &metav1.ObjectMeta{
+					Annotations: map[string]string{"fdb-test-annotation": "true"},
+				}
+			})
+
+			It("should not create any services", func() {
+				Expect(newServices.Items).To(HaveLen(len(initialServices.Items)))
+			})
+
+			It("should set the metadata on the services", func() {
+				for _, service := range newServices.Items {
+					Expect(service.Annotations["fdb-test-annotation"]).To(Equal("true"))
+				}
+			})
+		})
 	})
 
 	Context("with a process group with no service defined", func() {
@@ -23,9 +23,9 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"net"
-	"strings"
+	"sort"
 	"sync"
+	"time"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
@@ -45,7 +45,12 @@ type mockAdminClient struct {
 	KilledAddresses                          []string
 	frozenStatus                             *fdbtypes.FoundationDBStatus
 	Backups                                  map[string]fdbtypes.FoundationDBBackupStatusBackupDetails
+	backupMode                               fdbtypes.BackupMode
+	backupExpirations                        []string
 	restoreURL                               string
+	restoreTargetVersion                     *int64
+	backupDescriptions                       map[string]fdbtypes.FoundationDBBackupDescription
+	drs                                      map[string]mockDR
 	clientVersions                           map[string][]string
 	missingProcessGroups                     map[string]bool
 	additionalProcesses                      []fdbtypes.ProcessGroupStatus
@@ -53,6 +58,43 @@ type mockAdminClient struct {
 	incorrectCommandLines                    map[string]bool
 	maxZoneFailuresWithoutLosingData         *int
 	maxZoneFailuresWithoutLosingAvailability *int
+	commandErrors                            map[string]int
+	commandErrorValues                       map[string]error
+	partialFailures                          map[string]int
+	stuckExclusions                          bool
+	dataMovementChecks                       int
+	pendingDataMovement                      map[string]int
+	staleConnectionString                    string
+	liveConnectionString                     string
+	stickyFrozenStatus                       bool
+	kvBytes                                  int
+	storageWiggler                           fdbtypes.FoundationDBStatusStorageWiggler
+	connectedClients                         int
+	tagThrottles                             map[string]mockTagThrottle
+	tagQuotas                                map[string]fdbtypes.TagQuota
+	knobs                                    map[string]string
+	consistencyCheckSuspended                bool
+	diskInfo                                 map[string]fdbtypes.FoundationDBStatusProcessDiskInfo
+	processMessages                          map[string][]fdbtypes.FoundationDBStatusProcessMessage
+	durabilityLag                            map[string]float64
+	databaseUnavailable                      bool
+	excludedLocalities                       map[string]fdbtypes.LocalityExclusion
+	activePrimaryDC                          string
+	ExecutedCommands                         []string
+	commandOutputs                           map[string]string
+}
+
+// mockDR describes a DR replication into the cluster of a mock admin client.
+type mockDR struct {
+	sourceConnectionString string
+	status                 fdbtypes.FoundationDBLiveDRStatus
+}
+
+// mockTagThrottle describes a tag throttle in the mock admin client.
+type mockTagThrottle struct {
+	throttle   fdbtypes.TagThrottle
+	expiration time.Time
+	manual     bool
 }
 
 // adminClientCache provides a cache of mock admin clients.
@@ -80,6 +122,7 @@ func newMockAdminClientUncast(cluster *fdbtypes.FoundationDBCluster, kubeClient
 			ReincludedAddresses:  make(map[string]bool),
 			missingProcessGroups: make(map[string]bool),
 			localityInfo:         make(map[string]map[string]string),
+			drs:                  make(map[string]mockDR),
 		}
 		adminClientCache[cluster.Name] = client
 		client.Backups = make(map[string]fdbtypes.FoundationDBBackupStatusBackupDetails)
@@ -95,15 +138,20 @@ func clearMockAdminClients() {
 }
 
 // GetStatus gets the database's status
-func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error) {
+func (client *mockAdminClient) GetStatus(ctx context.Context) (*fdbtypes.FoundationDBStatus, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "GetStatus")
+	if err != nil {
+		return nil, err
+	}
+
 	if client.frozenStatus != nil {
 		return client.frozenStatus, nil
 	}
 	pods := &corev1.PodList{}
-	err := client.KubeClient.List(context.TODO(), pods)
+	err = client.KubeClient.List(context.TODO(), pods)
 	if err != nil {
 		return nil, err
 	}
@@ -114,15 +162,21 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 	}
 
 	coordinators := make(map[string]bool)
-	var coordinatorAddresses []string
-	if strings.Contains(client.Cluster.Status.ConnectionString, "@") {
-		coordinatorAddresses = strings.Split(strings.Split(client.Cluster.Status.ConnectionString, "@")[1], ",")
-	} else {
-		coordinatorAddresses = []string{}
-	}
+	connectionString := client.getMockConnectionString()
+	if connectionString != "" {
+		parsedConnectionString, err := fdbtypes.ParseConnectionString(connectionString)
+		if err != nil {
+			return nil, err
+		}
+
+		coordinatorAddresses, err := parsedConnectionString.CoordinatorAddresses()
+		if err != nil {
+			return nil, err
+		}
 
-	for _, address := range coordinatorAddresses {
-		coordinators[address] = false
+		for _, address := range coordinatorAddresses {
+			coordinators[address.String()] = false
+		}
 	}
 
 	exclusionMap := make(map[string]bool, len(client.ExcludedAddresses))
@@ -133,7 +187,8 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 	for _, pod := range pods.Items {
 		podClient, _ := internal.NewMockFdbPodClient(client.Cluster, &pod)
 
-		processCount, err := internal.GetStorageServersPerPodForPod(&pod)
+		pClass := internal.GetProcessClassFromMeta(client.Cluster, pod.ObjectMeta)
+		processCount, err := internal.GetServersPerPodForPod(&pod, pClass)
 		if err != nil {
 			return nil, err
 		}
@@ -157,19 +212,38 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 		for processIndex := 1; processIndex <= processCount; processIndex++ {
 			var fdbRoles []fdbtypes.FoundationDBStatusProcessRoleInfo
 
-			fullAddress := client.Cluster.GetFullAddress(processIP, processIndex)
-			_, ipExcluded := exclusionMap[processIP]
+			locality := map[string]string{
+				fdbtypes.FDBLocalityInstanceIDKey: instanceID,
+				fdbtypes.FDBLocalityZoneIDKey:     pod.Name,
+				fdbtypes.FDBLocalityDCIDKey:       client.Cluster.Spec.DataCenter,
+			}
+
+			for key, value := range client.localityInfo[instanceID] {
+				locality[key] = value
+			}
+
+			if processCount > 1 {
+				locality["process_id"] = fmt.Sprintf("%s-%d", instanceID, processIndex)
+			}
+
+			fullAddress := client.Cluster.GetFullAddress(processIP, pClass, processIndex)
+			_, ipExcluded := exclusionMap[fullAddress.IPAddress.String()]
 			_, addressExcluded := exclusionMap[fullAddress.String()]
-			excluded := ipExcluded || addressExcluded
+			excluded := ipExcluded || addressExcluded || client.isLocalityExcluded(locality)
 			_, isCoordinator := coordinators[fullAddress.String()]
-			if isCoordinator && !excluded {
+			if isCoordinator {
 				coordinators[fullAddress.String()] = true
+			}
+			if isCoordinator && !excluded {
 				fdbRoles = append(fdbRoles, fdbtypes.FoundationDBStatusProcessRoleInfo{Role: string(fdbtypes.ProcessRoleCoordinator)})
 			}
 
-			pClass, err := podmanager.GetProcessClass(client.Cluster, &pod)
-			if err != nil {
-				return nil, err
+			lag, hasLag := client.durabilityLag[instanceID]
+			if hasLag {
+				fdbRoles = append(fdbRoles, fdbtypes.FoundationDBStatusProcessRoleInfo{
+					Role:          string(fdbtypes.ProcessRoleStorage),
+					DurabilityLag: fdbtypes.FoundationDBStatusLagInfo{Seconds: lag},
+				})
 			}
 
 			command, err := internal.GetStartCommand(client.Cluster, pClass, podClient, processIndex, processCount)
@@ -180,20 +254,6 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 				command += " --locality_incorrect=1"
 			}
 
-			locality := map[string]string{
-				fdbtypes.FDBLocalityInstanceIDKey: instanceID,
-				fdbtypes.FDBLocalityZoneIDKey:     pod.Name,
-				fdbtypes.FDBLocalityDCIDKey:       client.Cluster.Spec.DataCenter,
-			}
-
-			for key, value := range client.localityInfo[instanceID] {
-				locality[key] = value
-			}
-
-			if processCount > 1 {
-				locality["process_id"] = fmt.Sprintf("%s-%d", instanceID, processIndex)
-			}
-
 			status.Cluster.Processes[fmt.Sprintf("%s-%d", pod.Name, processIndex)] = fdbtypes.FoundationDBStatusProcessInfo{
 				Address:       fullAddress,
 				ProcessClass:  internal.GetProcessClassFromMeta(client.Cluster, pod.ObjectMeta),
@@ -203,6 +263,8 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 				Version:       client.Cluster.Status.RunningVersion,
 				UptimeSeconds: 60000,
 				Roles:         fdbRoles,
+				Disk:          client.diskInfo[instanceID],
+				Messages:      client.processMessages[instanceID],
 			}
 		}
 
@@ -216,7 +278,7 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 				locality[key] = value
 			}
 
-			fullAddress := client.Cluster.GetFullAddress(processGroup.Addresses[0], 1)
+			fullAddress := client.Cluster.GetFullAddress(processGroup.Addresses[0], processGroup.ProcessClass, 1)
 			status.Cluster.Processes[processGroup.ProcessGroupID] = fdbtypes.FoundationDBStatusProcessInfo{
 				Address:       fullAddress,
 				ProcessClass:  processGroup.ProcessClass,
@@ -231,7 +293,7 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 	if client.clientVersions != nil {
 		supportedVersions := make([]fdbtypes.FoundationDBStatusSupportedVersion, 0, len(client.clientVersions))
 		for version, addresses := range client.clientVersions {
-			protocolVersion, err := client.GetProtocolVersion(version)
+			protocolVersion, err := client.GetProtocolVersion(ctx, version)
 			if err != nil {
 				return nil, err
 			}
@@ -252,6 +314,7 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 		status.Cluster.Clients.SupportedVersions = supportedVersions
 	}
 
+	reachableCoordinators := 0
 	for address, reachable := range coordinators {
 		pAddr, err := fdbtypes.ParseProcessAddress(address)
 		if err != nil {
@@ -262,10 +325,15 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 			Address:   pAddr,
 			Reachable: reachable,
 		})
+
+		if reachable {
+			reachableCoordinators++
+		}
 	}
+	status.Client.Coordinators.QuorumReachable = reachableCoordinators > len(coordinators)/2
 
-	status.Client.DatabaseStatus.Available = true
-	status.Client.DatabaseStatus.Healthy = true
+	status.Client.DatabaseStatus.Available = !client.databaseUnavailable
+	status.Client.DatabaseStatus.Healthy = !client.databaseUnavailable
 
 	if client.DatabaseConfiguration == nil {
 		status.Cluster.Layers.Error = "configurationMissing"
@@ -277,9 +345,25 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 		status.Cluster.DatabaseConfiguration.VersionFlags.LogSpill = 2
 	}
 
+	status.Cluster.RecoveryState.Name = "fully_recovered"
+	status.Cluster.ActivePrimaryDC = client.activePrimaryDC
+	if status.Cluster.ActivePrimaryDC == "" && len(status.Cluster.DatabaseConfiguration.Regions) > 0 {
+		// The mock fails over instantly to the region with the highest
+		// priority.
+		for _, dataCenter := range status.Cluster.DatabaseConfiguration.NormalizeConfiguration().Regions[0].DataCenters {
+			if dataCenter.Satellite == 0 {
+				status.Cluster.ActivePrimaryDC = dataCenter.ID
+				break
+			}
+		}
+	}
+
 	status.Cluster.FullReplication = true
 	status.Cluster.Data.State.Healthy = true
 	status.Cluster.Data.State.Name = "healthy"
+	status.Cluster.Data.KVBytes = client.kvBytes
+	status.Cluster.StorageWiggler = client.storageWiggler
+	status.Cluster.Clients.Count = client.connectedClients
 
 	if len(client.Backups) > 0 {
 		status.Cluster.Layers.Backup.Tags = make(map[string]fdbtypes.FoundationDBStatusBackupTag, len(client.Backups))
@@ -295,31 +379,52 @@ func (client *mockAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error)
 
 	if client.maxZoneFailuresWithoutLosingData == nil {
 		status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData = client.Cluster.DesiredFaultTolerance()
+	} else {
+		status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData = *client.maxZoneFailuresWithoutLosingData
 	}
 
 	if client.maxZoneFailuresWithoutLosingAvailability == nil {
 		status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability = client.Cluster.DesiredFaultTolerance()
+	} else {
+		status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability = *client.maxZoneFailuresWithoutLosingAvailability
 	}
 
 	return status, nil
 }
 
 // ConfigureDatabase changes the database configuration
-func (client *mockAdminClient) ConfigureDatabase(configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error {
+func (client *mockAdminClient) ConfigureDatabase(ctx context.Context, configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "ConfigureDatabase")
+	if err != nil {
+		return err
+	}
+
 	client.DatabaseConfiguration = configuration.DeepCopy()
 	return nil
 }
 
 // ExcludeInstances starts evacuating processes so that they can be removed
 // from the database.
-func (client *mockAdminClient) ExcludeInstances(addresses []fdbtypes.ProcessAddress) error {
+func (client *mockAdminClient) ExcludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "ExcludeInstances")
+	if err != nil {
+		return err
+	}
+
+	addresses, partialErr := client.checkPartialFailure("ExcludeInstances", addresses)
+
 	count := len(addresses) + len(client.ExcludedAddresses)
+	previousExclusions := make(map[string]bool, len(client.ExcludedAddresses))
+	for _, address := range client.ExcludedAddresses {
+		previousExclusions[address] = true
+	}
+
 	exclusionMap := make(map[string]bool, count)
 	newExclusions := make([]string, 0, count)
 	for _, pAddr := range addresses {
@@ -328,6 +433,10 @@ func (client *mockAdminClient) ExcludeInstances(addresses []fdbtypes.ProcessAddr
 			exclusionMap[address] = true
 			newExclusions = append(newExclusions, address)
 		}
+
+		if !previousExclusions[address] && client.dataMovementChecks > 0 {
+			client.pendingDataMovement[address] = client.dataMovementChecks
+		}
 	}
 	for _, address := range client.ExcludedAddresses {
 		if !exclusionMap[address] {
@@ -339,15 +448,78 @@ func (client *mockAdminClient) ExcludeInstances(addresses []fdbtypes.ProcessAddr
 		newExclusions = nil
 	}
 	client.ExcludedAddresses = newExclusions
+	return partialErr
+}
+
+// ExcludeLocalities starts evacuating all processes of the given localities.
+func (client *mockAdminClient) ExcludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "ExcludeLocalities")
+	if err != nil {
+		return err
+	}
+
+	_, err = internal.GetExcludeLocalitiesCommand(client.Cluster, localities)
+	if err != nil {
+		return err
+	}
+
+	if client.excludedLocalities == nil {
+		client.excludedLocalities = make(map[string]fdbtypes.LocalityExclusion, len(localities))
+	}
+
+	for _, locality := range localities {
+		client.excludedLocalities[locality.String()] = locality
+	}
+
+	return nil
+}
+
+// IncludeLocalities removes the given localities from the exclusion list and
+// allows their processes to take on roles again.
+func (client *mockAdminClient) IncludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "IncludeLocalities")
+	if err != nil {
+		return err
+	}
+
+	for _, locality := range localities {
+		delete(client.excludedLocalities, locality.String())
+	}
+
 	return nil
 }
 
+// isLocalityExcluded determines whether a process with the given localities
+// is part of an excluded locality.
+func (client *mockAdminClient) isLocalityExcluded(locality map[string]string) bool {
+	for _, exclusion := range client.excludedLocalities {
+		if exclusion.Matches(locality) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // IncludeInstances removes instances from the exclusion list and allows
 // them to take on roles again.
-func (client *mockAdminClient) IncludeInstances(addresses []fdbtypes.ProcessAddress) error {
+func (client *mockAdminClient) IncludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "IncludeInstances")
+	if err != nil {
+		return err
+	}
+
+	addresses, partialErr := client.checkPartialFailure("IncludeInstances", addresses)
+
 	newExclusions := make([]string, 0, len(client.ExcludedAddresses))
 	for _, excludedAddress := range client.ExcludedAddresses {
 		included := false
@@ -355,6 +527,7 @@ func (client *mockAdminClient) IncludeInstances(addresses []fdbtypes.ProcessAddr
 			if address.String() == excludedAddress {
 				included = true
 				client.ReincludedAddresses[address.String()] = true
+				delete(client.pendingDataMovement, address.String())
 				break
 			}
 		}
@@ -366,7 +539,7 @@ func (client *mockAdminClient) IncludeInstances(addresses []fdbtypes.ProcessAddr
 		newExclusions = nil
 	}
 	client.ExcludedAddresses = newExclusions
-	return nil
+	return partialErr
 }
 
 // CanSafelyRemove checks whether it is safe to remove instances from the
@@ -374,46 +547,102 @@ func (client *mockAdminClient) IncludeInstances(addresses []fdbtypes.ProcessAddr
 //
 // The list returned by this method will be the addresses that are *not*
 // safe to remove.
-func (client *mockAdminClient) CanSafelyRemove(addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error) {
-	return nil, nil
+func (client *mockAdminClient) CanSafelyRemove(ctx context.Context, addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "CanSafelyRemove")
+	if err != nil {
+		return nil, err
+	}
+
+	if client.stuckExclusions {
+		return addresses, nil
+	}
+
+	if client.dataMovementChecks == 0 {
+		return nil, nil
+	}
+
+	exclusionMap := make(map[string]bool, len(client.ExcludedAddresses))
+	for _, address := range client.ExcludedAddresses {
+		exclusionMap[address] = true
+	}
+
+	// Each check moves the data of the excluded addresses one step forward,
+	// until the addresses are safe to remove.
+	var remaining []fdbtypes.ProcessAddress
+	for _, address := range addresses {
+		if !exclusionMap[address.String()] && !exclusionMap[address.IPAddress.String()] {
+			remaining = append(remaining, address)
+			continue
+		}
+
+		if client.pendingDataMovement[address.String()] > 0 {
+			client.pendingDataMovement[address.String()]--
+			remaining = append(remaining, address)
+		}
+	}
+
+	return remaining, nil
 }
 
 // GetExclusions gets a list of the addresses currently excluded from the
 // database.
-func (client *mockAdminClient) GetExclusions() ([]fdbtypes.ProcessAddress, error) {
+func (client *mockAdminClient) GetExclusions(ctx context.Context) ([]fdbtypes.ProcessAddress, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
-	pAddrs := make([]fdbtypes.ProcessAddress, len(client.ExcludedAddresses))
+	err := client.checkMockError(ctx, "GetExclusions")
+	if err != nil {
+		return nil, err
+	}
+
+	pAddrs := make([]fdbtypes.ProcessAddress, 0, len(client.ExcludedAddresses))
 	for _, addr := range client.ExcludedAddresses {
-		pAddrs = append(pAddrs, fdbtypes.ProcessAddress{
-			IPAddress:   net.ParseIP(addr),
-			Placeholder: "",
-			Port:        0,
-			Flags:       nil,
-		})
+		pAddr, err := fdbtypes.ParseProcessAddress(addr)
+		if err != nil {
+			return nil, err
+		}
+		pAddrs = append(pAddrs, pAddr)
 	}
 
 	return pAddrs, nil
 }
 
 // KillInstances restarts processes
-func (client *mockAdminClient) KillInstances(addresses []fdbtypes.ProcessAddress) error {
+func (client *mockAdminClient) KillInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
 	adminClientMutex.Lock()
+	err := client.checkMockError(ctx, "KillInstances")
+	if err != nil {
+		adminClientMutex.Unlock()
+		return err
+	}
+
+	addresses, partialErr := client.checkPartialFailure("KillInstances", addresses)
+
 	for _, addr := range addresses {
 		client.KilledAddresses = append(client.KilledAddresses, addr.String())
 	}
+	stickyFrozenStatus := client.stickyFrozenStatus
 	adminClientMutex.Unlock()
 
-	client.UnfreezeStatus()
-	return nil
+	if !stickyFrozenStatus {
+		client.UnfreezeStatus()
+	}
+	return partialErr
 }
 
 // ChangeCoordinators changes the coordinator set
-func (client *mockAdminClient) ChangeCoordinators(addresses []fdbtypes.ProcessAddress) (string, error) {
+func (client *mockAdminClient) ChangeCoordinators(ctx context.Context, addresses []fdbtypes.ProcessAddress) (string, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "ChangeCoordinators")
+	if err != nil {
+		return "", err
+	}
+
 	connectionString, err := fdbtypes.ParseConnectionString(client.Cluster.Status.ConnectionString)
 	if err != nil {
 		return "", err
@@ -432,16 +661,38 @@ func (client *mockAdminClient) ChangeCoordinators(addresses []fdbtypes.ProcessAd
 }
 
 // GetConnectionString fetches the latest connection string.
-func (client *mockAdminClient) GetConnectionString() (string, error) {
+func (client *mockAdminClient) GetConnectionString(ctx context.Context) (string, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "GetConnectionString")
+	if err != nil {
+		return "", err
+	}
+
+	return client.getMockConnectionString(), nil
+}
+
+// GetLiveConnectionString reads the connection string that the database
+// stores in the \xff/coordinators key.
+func (client *mockAdminClient) GetLiveConnectionString(ctx context.Context) (string, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "GetLiveConnectionString")
+	if err != nil {
+		return "", err
+	}
+
+	if client.liveConnectionString != "" {
+		return client.liveConnectionString, nil
+	}
 	return client.Cluster.Status.ConnectionString, nil
 }
 
 // VersionSupported reports whether we can support a cluster with a given
 // version.
-func (client *mockAdminClient) VersionSupported(versionString string) (bool, error) {
+func (client *mockAdminClient) VersionSupported(ctx context.Context, versionString string) (bool, error) {
 	version, err := fdbtypes.ParseFdbVersion(versionString)
 	if err != nil {
 		return false, err
@@ -456,15 +707,32 @@ func (client *mockAdminClient) VersionSupported(versionString string) (bool, err
 
 // GetProtocolVersion determines the protocol version that is used by a
 // version of FDB.
-func (client *mockAdminClient) GetProtocolVersion(version string) (string, error) {
+func (client *mockAdminClient) GetProtocolVersion(ctx context.Context, version string) (string, error) {
 	return version, nil
 }
 
+// GetConnectedClients lists the client versions and protocol versions of the
+// clients that are connected to the database.
+func (client *mockAdminClient) GetConnectedClients(ctx context.Context) ([]fdbtypes.FoundationDBStatusSupportedVersion, error) {
+	status, err := client.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return status.Cluster.Clients.SupportedVersions, nil
+}
+
 // StartBackup starts a new backup.
-func (client *mockAdminClient) StartBackup(url string, snapshotPeriodSeconds int) error {
+func (client *mockAdminClient) StartBackup(ctx context.Context, url string, snapshotPeriodSeconds int, mode fdbtypes.BackupMode) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "StartBackup")
+	if err != nil {
+		return err
+	}
+
+	client.backupMode = mode
 	client.Backups["default"] = fdbtypes.FoundationDBBackupStatusBackupDetails{
 		URL:                   url,
 		Running:               true,
@@ -474,10 +742,15 @@ func (client *mockAdminClient) StartBackup(url string, snapshotPeriodSeconds int
 }
 
 // PauseBackups pauses backups.
-func (client *mockAdminClient) PauseBackups() error {
+func (client *mockAdminClient) PauseBackups(ctx context.Context) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "PauseBackups")
+	if err != nil {
+		return err
+	}
+
 	for tag, backup := range client.Backups {
 		backup.Paused = true
 		client.Backups[tag] = backup
@@ -486,10 +759,15 @@ func (client *mockAdminClient) PauseBackups() error {
 }
 
 // ResumeBackups resumes backups.
-func (client *mockAdminClient) ResumeBackups() error {
+func (client *mockAdminClient) ResumeBackups(ctx context.Context) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "ResumeBackups")
+	if err != nil {
+		return err
+	}
+
 	for tag, backup := range client.Backups {
 		backup.Paused = false
 		client.Backups[tag] = backup
@@ -498,10 +776,15 @@ func (client *mockAdminClient) ResumeBackups() error {
 }
 
 // ModifyBackup reconfigures the backup.
-func (client *mockAdminClient) ModifyBackup(snapshotPeriodSeconds int) error {
+func (client *mockAdminClient) ModifyBackup(ctx context.Context, snapshotPeriodSeconds int) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "ModifyBackup")
+	if err != nil {
+		return err
+	}
+
 	backup := client.Backups["default"]
 	backup.SnapshotPeriodSeconds = snapshotPeriodSeconds
 	client.Backups["default"] = backup
@@ -509,10 +792,15 @@ func (client *mockAdminClient) ModifyBackup(snapshotPeriodSeconds int) error {
 }
 
 // StopBackup stops a backup.
-func (client *mockAdminClient) StopBackup(url string) error {
+func (client *mockAdminClient) StopBackup(ctx context.Context, url string) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "StopBackup")
+	if err != nil {
+		return err
+	}
+
 	for tag, backup := range client.Backups {
 		if backup.URL == url {
 			backup.Running = false
@@ -524,10 +812,15 @@ func (client *mockAdminClient) StopBackup(url string) error {
 }
 
 // GetBackupStatus gets the status of the current backup.
-func (client *mockAdminClient) GetBackupStatus() (*fdbtypes.FoundationDBLiveBackupStatus, error) {
+func (client *mockAdminClient) GetBackupStatus(ctx context.Context) (*fdbtypes.FoundationDBLiveBackupStatus, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
+	err := client.checkMockError(ctx, "GetBackupStatus")
+	if err != nil {
+		return nil, err
+	}
+
 	status := &fdbtypes.FoundationDBLiveBackupStatus{}
 
 	tag := "default"
@@ -537,65 +830,224 @@ func (client *mockAdminClient) GetBackupStatus() (*fdbtypes.FoundationDBLiveBack
 		status.Status.Running = backup.Running
 		status.BackupAgentsPaused = backup.Paused
 		status.SnapshotIntervalSeconds = backup.SnapshotPeriodSeconds
+		status.Status.Completed = backup.Completed
 	}
 
 	return status, nil
 }
 
-// StartRestore starts a new restore.
-func (client *mockAdminClient) StartRestore(url string, keyRanges []fdbtypes.FoundationDBKeyRange) error {
+// ExpireBackup deletes old data from a backup.
+func (client *mockAdminClient) ExpireBackup(ctx context.Context, url string, policy fdbtypes.BackupExpirationPolicy) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
-	client.restoreURL = url
+	err := client.checkMockError(ctx, "ExpireBackup")
+	if err != nil {
+		return err
+	}
+
+	client.backupExpirations = append(client.backupExpirations, url)
 	return nil
 }
 
-// GetRestoreStatus gets the status of the current restore.
-func (client *mockAdminClient) GetRestoreStatus() (string, error) {
+// DescribeBackup describes the data in the backup at the URL.
+func (client *mockAdminClient) DescribeBackup(ctx context.Context, url string) (*fdbtypes.FoundationDBBackupDescription, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
-	return fmt.Sprintf("%s\n", client.restoreURL), nil
+	err := client.checkMockError(ctx, "DescribeBackup")
+	if err != nil {
+		return nil, err
+	}
+
+	description, present := client.backupDescriptions[url]
+	if !present {
+		return &fdbtypes.FoundationDBBackupDescription{URL: url}, nil
+	}
+
+	return description.DeepCopy(), nil
 }
 
-// MockClientVersion returns a mocked client version
-func (client *mockAdminClient) MockClientVersion(version string, clients []string) {
+// StartRestore starts a new restore.
+func (client *mockAdminClient) StartRestore(ctx context.Context, url string, keyRanges []fdbtypes.FoundationDBKeyRange, targetVersion *int64) error {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
-	if client.clientVersions == nil {
-		client.clientVersions = make(map[string][]string)
+	err := client.checkMockError(ctx, "StartRestore")
+	if err != nil {
+		return err
 	}
-	client.clientVersions[version] = clients
+
+	client.restoreURL = url
+	client.restoreTargetVersion = targetVersion
+	return nil
 }
 
-// MockAdditionalProcesses adds additional processes to the cluster status.
-func (client *mockAdminClient) MockAdditionalProcesses(processes []fdbtypes.ProcessGroupStatus) {
+// GetRestoreStatus gets the status of the current restore.
+func (client *mockAdminClient) GetRestoreStatus(ctx context.Context) (string, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 
-	client.additionalProcesses = append(client.additionalProcesses, processes...)
-}
+	err := client.checkMockError(ctx, "GetRestoreStatus")
+	if err != nil {
+		return "", err
+	}
 
-// MockMissingProcessGroup updates the mock for whether a process group should
-// be missing from the cluster status.
-func (client *mockAdminClient) MockMissingProcessGroup(instanceID string, missing bool) {
-	client.missingProcessGroups[instanceID] = missing
+	return fmt.Sprintf("%s\n", client.restoreURL), nil
 }
 
-// MockLocalityInfo sets mock locality information for a process.
-func (client *mockAdminClient) MockLocalityInfo(processGroupID string, locality map[string]string) {
-	client.localityInfo[processGroupID] = locality
-}
+// StartDR starts a new DR replication from the cluster with the provided
+// connection string into this cluster.
+func (client *mockAdminClient) StartDR(ctx context.Context, sourceConnectionString string, tag string) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
 
-// MockIncorrectCommandLine updates the mock for whether a process group should
-// be have an incorrect command-line.
-func (client *mockAdminClient) MockIncorrectCommandLine(instanceID string, incorrect bool) {
-	if client.incorrectCommandLines == nil {
-		client.incorrectCommandLines = make(map[string]bool)
+	err := client.checkMockError(ctx, "StartDR")
+	if err != nil {
+		return err
 	}
-	client.incorrectCommandLines[instanceID] = incorrect
+
+	client.drs[tag] = mockDR{
+		sourceConnectionString: sourceConnectionString,
+		status: fdbtypes.FoundationDBLiveDRStatus{
+			Running:      true,
+			CompleteCopy: true,
+		},
+	}
+	return nil
+}
+
+// SwitchDR switches the DR replication from the cluster with the provided
+// connection string over to this cluster.
+func (client *mockAdminClient) SwitchDR(ctx context.Context, sourceConnectionString string, tag string) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "SwitchDR")
+	if err != nil {
+		return err
+	}
+
+	dr, present := client.drs[tag]
+	if !present || dr.sourceConnectionString != sourceConnectionString {
+		return fmt.Errorf("no DR found for tag %s", tag)
+	}
+
+	if !dr.status.CompleteCopy {
+		return fmt.Errorf("DR for tag %s is not a complete copy", tag)
+	}
+
+	for _, sourceClient := range adminClientCache {
+		if sourceClient.Cluster.Status.ConnectionString != sourceConnectionString {
+			continue
+		}
+
+		delete(client.drs, tag)
+		sourceClient.drs[tag] = mockDR{
+			sourceConnectionString: client.Cluster.Status.ConnectionString,
+			status:                 dr.status,
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no cluster found for connection string %s", sourceConnectionString)
+}
+
+// GetDRStatus gets the status of the DR replication from the cluster with
+// the provided connection string into this cluster.
+func (client *mockAdminClient) GetDRStatus(ctx context.Context, sourceConnectionString string, tag string) (*fdbtypes.FoundationDBLiveDRStatus, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "GetDRStatus")
+	if err != nil {
+		return nil, err
+	}
+
+	status := &fdbtypes.FoundationDBLiveDRStatus{}
+	dr, present := client.drs[tag]
+	if present && dr.sourceConnectionString == sourceConnectionString {
+		*status = dr.status
+	}
+
+	return status, nil
+}
+
+// MockClientVersion returns a mocked client version
+func (client *mockAdminClient) MockClientVersion(version string, clients []string) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	if client.clientVersions == nil {
+		client.clientVersions = make(map[string][]string)
+	}
+	client.clientVersions[version] = clients
+}
+
+// MockAdditionalProcesses adds additional processes to the cluster status.
+func (client *mockAdminClient) MockAdditionalProcesses(processes []fdbtypes.ProcessGroupStatus) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.additionalProcesses = append(client.additionalProcesses, processes...)
+}
+
+// MockMissingProcessGroup updates the mock for whether a process group should
+// be missing from the cluster status.
+func (client *mockAdminClient) MockMissingProcessGroup(instanceID string, missing bool) {
+	client.missingProcessGroups[instanceID] = missing
+}
+
+// MockLocalityInfo sets mock locality information for a process.
+func (client *mockAdminClient) MockLocalityInfo(processGroupID string, locality map[string]string) {
+	client.localityInfo[processGroupID] = locality
+}
+
+// MockDiskInfo sets the disk information that the processes of a process
+// group report in the status.
+func (client *mockAdminClient) MockDiskInfo(instanceID string, freeBytes int64, totalBytes int64) {
+	if client.diskInfo == nil {
+		client.diskInfo = make(map[string]fdbtypes.FoundationDBStatusProcessDiskInfo)
+	}
+	client.diskInfo[instanceID] = fdbtypes.FoundationDBStatusProcessDiskInfo{FreeBytes: freeBytes, TotalBytes: totalBytes}
+}
+
+// MockProcessMessages sets the messages that the processes of a process
+// group report in the status.
+func (client *mockAdminClient) MockProcessMessages(instanceID string, messages ...string) {
+	if client.processMessages == nil {
+		client.processMessages = make(map[string][]fdbtypes.FoundationDBStatusProcessMessage)
+	}
+
+	processMessages := make([]fdbtypes.FoundationDBStatusProcessMessage, 0, len(messages))
+	for _, message := range messages {
+		processMessages = append(processMessages, fdbtypes.FoundationDBStatusProcessMessage{Name: message})
+	}
+	client.processMessages[instanceID] = processMessages
+}
+
+// MockDurabilityLag sets the durability lag that the storage role of a
+// process group reports in the status.
+func (client *mockAdminClient) MockDurabilityLag(instanceID string, seconds float64) {
+	if client.durabilityLag == nil {
+		client.durabilityLag = make(map[string]float64)
+	}
+	client.durabilityLag[instanceID] = seconds
+}
+
+// MockDatabaseUnavailable updates the mock for whether the database reports
+// that it is unavailable.
+func (client *mockAdminClient) MockDatabaseUnavailable(unavailable bool) {
+	client.databaseUnavailable = unavailable
+}
+
+// MockIncorrectCommandLine updates the mock for whether a process group should
+// be have an incorrect command-line.
+func (client *mockAdminClient) MockIncorrectCommandLine(instanceID string, incorrect bool) {
+	if client.incorrectCommandLines == nil {
+		client.incorrectCommandLines = make(map[string]bool)
+	}
+	client.incorrectCommandLines[instanceID] = incorrect
 }
 
 // Close shuts down any resources for the client once it is no longer
@@ -608,7 +1060,7 @@ func (client *mockAdminClient) Close() error {
 // UnfreezeStatus is called, or another method is called which would invalidate
 // the status.
 func (client *mockAdminClient) FreezeStatus() error {
-	status, err := client.GetStatus()
+	status, err := client.GetStatus(context.TODO())
 	if err != nil {
 		return err
 	}
@@ -627,11 +1079,451 @@ func (client *mockAdminClient) UnfreezeStatus() {
 	defer adminClientMutex.Unlock()
 
 	client.frozenStatus = nil
+	client.stickyFrozenStatus = false
+}
+
+// MockFrozenStatus freezes the status like FreezeStatus, but keeps the status
+// frozen when processes are killed. The status stays frozen until
+// UnfreezeStatus is called.
+func (client *mockAdminClient) MockFrozenStatus() error {
+	err := client.FreezeStatus()
+	if err != nil {
+		return err
+	}
+
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.stickyFrozenStatus = true
+	return nil
+}
+
+// MockCommandError causes the next count calls to the given command to fail.
+// The command is the name of the method on the admin client, e.g.
+// ExcludeInstances. A count of zero clears the mocked error.
+func (client *mockAdminClient) MockCommandError(command string, count int) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	if client.commandErrors == nil {
+		client.commandErrors = make(map[string]int)
+	}
+	client.commandErrors[command] = count
+	delete(client.commandErrorValues, command)
+}
+
+// MockCommandErrorValue causes the next count calls to the given command to
+// fail with the given error, e.g. to simulate a specific failure from fdbcli.
+func (client *mockAdminClient) MockCommandErrorValue(command string, count int, err error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	if client.commandErrors == nil {
+		client.commandErrors = make(map[string]int)
+	}
+	if client.commandErrorValues == nil {
+		client.commandErrorValues = make(map[string]error)
+	}
+	client.commandErrors[command] = count
+	client.commandErrorValues[command] = err
+}
+
+// MockCommandTimeout causes the next count calls to the given command to
+// fail with a timeout.
+func (client *mockAdminClient) MockCommandTimeout(command string, count int) {
+	client.MockCommandErrorValue(command, count, fmt.Errorf("mocked timeout in %s: %w", command, context.DeadlineExceeded))
+}
+
+// MockPartialFailure causes the next call to the given command to only apply
+// to the first applied addresses, and then fail. This is supported for
+// ExcludeInstances, IncludeInstances and KillInstances.
+func (client *mockAdminClient) MockPartialFailure(command string, applied int) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	if client.partialFailures == nil {
+		client.partialFailures = make(map[string]int)
+	}
+	client.partialFailures[command] = applied
+}
+
+// MockCommandOutput sets the output that RunCommand returns for the given
+// fdbcli command.
+func (client *mockAdminClient) MockCommandOutput(command string, output string) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	if client.commandOutputs == nil {
+		client.commandOutputs = make(map[string]string)
+	}
+	client.commandOutputs[command] = output
+}
+
+// MockStuckExclusions updates the mock for whether exclusions should never
+// complete. While the exclusions are stuck, CanSafelyRemove reports all
+// addresses as not safe to remove.
+func (client *mockAdminClient) MockStuckExclusions(stuck bool) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.stuckExclusions = stuck
+}
+
+// MockDataMovement updates the mock to require the given number of
+// CanSafelyRemove checks for newly excluded addresses before they are safe to
+// remove. While this is enabled, addresses that are not excluded are never
+// safe to remove. A count of 0 makes all addresses safe to remove
+// immediately.
+func (client *mockAdminClient) MockDataMovement(checks int) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.dataMovementChecks = checks
+	client.pendingDataMovement = make(map[string]int)
+}
+
+// GetPendingDataMovement returns the number of CanSafelyRemove checks that
+// remain before the data of an excluded address has been moved.
+func (client *mockAdminClient) GetPendingDataMovement(address string) int {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	return client.pendingDataMovement[address]
+}
+
+// MockStaleCoordinators causes the status and the connection string to report
+// the given connection string instead of the one from the cluster status.
+// An empty connection string clears the mock.
+func (client *mockAdminClient) MockStaleCoordinators(connectionString string) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.staleConnectionString = connectionString
+}
+
+// MockLiveConnectionString sets the connection string that the database
+// reports in the \xff/coordinators key, to simulate coordinators that were
+// changed outside of the operator. An empty connection string clears the
+// mock.
+func (client *mockAdminClient) MockLiveConnectionString(connectionString string) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.liveConnectionString = connectionString
+}
+
+// MockKVBytes sets the total key-value size that the status reports for the
+// database.
+func (client *mockAdminClient) MockKVBytes(kvBytes int) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.kvBytes = kvBytes
+}
+
+// MockConnectedClients sets the number of connected clients that the status
+// reports for the database.
+func (client *mockAdminClient) MockConnectedClients(count int) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.connectedClients = count
+}
+
+// MockStorageWiggler sets the storage wiggle metrics that the status reports
+// for the database.
+func (client *mockAdminClient) MockStorageWiggler(storageWiggler fdbtypes.FoundationDBStatusStorageWiggler) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	client.storageWiggler = storageWiggler
+}
+
+// checkMockError returns an error if the context is done or a failure has
+// been mocked for the command, and decrements the remaining failure count.
+//
+// This must be called while holding the adminClientMutex.
+func (client *mockAdminClient) checkMockError(ctx context.Context, command string) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	if client.commandErrors[command] <= 0 {
+		return nil
+	}
+
+	client.commandErrors[command]--
+	if client.commandErrorValues[command] != nil {
+		return client.commandErrorValues[command]
+	}
+	return fmt.Errorf("mocked error in %s", command)
+}
+
+// checkPartialFailure checks whether a partial failure has been mocked for a
+// command. If so, this returns the addresses that the command should still
+// apply, and the error it should return.
+//
+// This must be called while holding the adminClientMutex.
+func (client *mockAdminClient) checkPartialFailure(command string, addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error) {
+	applied, present := client.partialFailures[command]
+	if !present {
+		return addresses, nil
+	}
+
+	delete(client.partialFailures, command)
+	if applied >= len(addresses) {
+		return addresses, nil
+	}
+
+	return addresses[:applied], fmt.Errorf("mocked partial failure in %s after %d of %d addresses", command, applied, len(addresses))
+}
+
+// getMockConnectionString gets the connection string that the mock reports,
+// taking mocked stale coordinators into account.
+//
+// This must be called while holding the adminClientMutex.
+func (client *mockAdminClient) getMockConnectionString() string {
+	if client.staleConnectionString != "" {
+		return client.staleConnectionString
+	}
+	return client.Cluster.Status.ConnectionString
+}
+
+// RunCommand records the fdbcli command and returns the mocked output for it.
+func (client *mockAdminClient) RunCommand(ctx context.Context, command string) (string, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "RunCommand")
+	if err != nil {
+		return "", err
+	}
+
+	client.ExecutedCommands = append(client.ExecutedCommands, command)
+	return client.commandOutputs[command], nil
+}
+
+// GetTagThrottles lists the tag throttles in the mock database.
+func (client *mockAdminClient) GetTagThrottles(ctx context.Context) ([]fdbtypes.TagThrottleStatus, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "GetTagThrottles")
+	if err != nil {
+		return nil, err
+	}
+
+	throttles := make([]fdbtypes.TagThrottleStatus, 0, len(client.tagThrottles))
+	for _, throttle := range client.tagThrottles {
+		expiration := int(time.Until(throttle.expiration).Seconds())
+		if expiration <= 0 {
+			continue
+		}
+
+		throttles = append(throttles, fdbtypes.TagThrottleStatus{
+			TagThrottle:       throttle.throttle,
+			ExpirationSeconds: expiration,
+			Manual:            throttle.manual,
+		})
+	}
+
+	sort.Slice(throttles, func(i, j int) bool {
+		return throttles[i].Tag < throttles[j].Tag
+	})
+
+	return throttles, nil
+}
+
+// ThrottleTag sets a manual throttle on a tag in the mock database.
+func (client *mockAdminClient) ThrottleTag(ctx context.Context, throttle fdbtypes.TagThrottle, duration time.Duration) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "ThrottleTag")
+	if err != nil {
+		return err
+	}
+
+	if client.tagThrottles == nil {
+		client.tagThrottles = make(map[string]mockTagThrottle)
+	}
+
+	throttle.Priority = throttle.GetPriority()
+	client.tagThrottles[throttle.Tag] = mockTagThrottle{
+		throttle:   throttle,
+		expiration: time.Now().Add(duration),
+		manual:     true,
+	}
+
+	return nil
+}
+
+// UnthrottleTag removes the manual throttles on a tag in the mock database.
+func (client *mockAdminClient) UnthrottleTag(ctx context.Context, tag string) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "UnthrottleTag")
+	if err != nil {
+		return err
+	}
+
+	throttle, present := client.tagThrottles[tag]
+	if present && throttle.manual {
+		delete(client.tagThrottles, tag)
+	}
+
+	return nil
+}
+
+// GetTagQuotas reads the throughput quotas of the given tags from the mock
+// database.
+func (client *mockAdminClient) GetTagQuotas(ctx context.Context, tags []string) ([]fdbtypes.TagQuota, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "GetTagQuotas")
+	if err != nil {
+		return nil, err
+	}
+
+	quotas := make([]fdbtypes.TagQuota, 0, len(tags))
+	for _, tag := range tags {
+		quota, present := client.tagQuotas[tag]
+		if present {
+			quotas = append(quotas, quota)
+		}
+	}
+
+	return quotas, nil
+}
+
+// SetTagQuota sets the throughput quota of a tag in the mock database.
+func (client *mockAdminClient) SetTagQuota(ctx context.Context, quota fdbtypes.TagQuota) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "SetTagQuota")
+	if err != nil {
+		return err
+	}
+
+	if client.tagQuotas == nil {
+		client.tagQuotas = make(map[string]fdbtypes.TagQuota)
+	}
+
+	client.tagQuotas[quota.Tag] = quota
+
+	return nil
+}
+
+// ClearTagQuota removes the throughput quota of a tag in the mock database.
+func (client *mockAdminClient) ClearTagQuota(ctx context.Context, tag string) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "ClearTagQuota")
+	if err != nil {
+		return err
+	}
+
+	delete(client.tagQuotas, tag)
+
+	return nil
+}
+
+// GetKnobs reads the given knobs from the mock configuration database.
+func (client *mockAdminClient) GetKnobs(ctx context.Context, names []string) (map[string]string, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "GetKnobs")
+	if err != nil {
+		return nil, err
+	}
+
+	knobs := make(map[string]string, len(names))
+	for _, name := range names {
+		value, present := client.knobs[name]
+		if present {
+			knobs[name] = value
+		}
+	}
+
+	return knobs, nil
+}
+
+// SetKnobs sets the given knobs in the mock configuration database.
+func (client *mockAdminClient) SetKnobs(ctx context.Context, knobs map[string]string) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "SetKnobs")
+	if err != nil {
+		return err
+	}
+
+	if client.knobs == nil {
+		client.knobs = make(map[string]string)
+	}
+
+	for name, value := range knobs {
+		client.knobs[name] = value
+	}
+
+	return nil
+}
+
+// ClearKnobs removes the given knobs from the mock configuration database.
+func (client *mockAdminClient) ClearKnobs(ctx context.Context, names []string) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "ClearKnobs")
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		delete(client.knobs, name)
+	}
+
+	return nil
+}
+
+// GetConsistencyCheckSuspended determines whether the consistency check is
+// suspended in the database.
+func (client *mockAdminClient) GetConsistencyCheckSuspended(ctx context.Context) (bool, error) {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "GetConsistencyCheckSuspended")
+	if err != nil {
+		return false, err
+	}
+
+	return client.consistencyCheckSuspended, nil
+}
+
+// SetConsistencyCheckSuspended suspends or resumes the consistency check in
+// the database.
+func (client *mockAdminClient) SetConsistencyCheckSuspended(ctx context.Context, suspended bool) error {
+	adminClientMutex.Lock()
+	defer adminClientMutex.Unlock()
+
+	err := client.checkMockError(ctx, "SetConsistencyCheckSuspended")
+	if err != nil {
+		return err
+	}
+
+	client.consistencyCheckSuspended = suspended
+	return nil
 }
 
 // GetCoordinatorSet gets the current coordinators from the status
-func (client *mockAdminClient) GetCoordinatorSet() (map[string]struct{}, error) {
-	status, err := client.GetStatus()
+func (client *mockAdminClient) GetCoordinatorSet(ctx context.Context) (map[string]struct{}, error) {
+	status, err := client.GetStatus(ctx)
 	if err != nil {
 		return nil, err
 	}
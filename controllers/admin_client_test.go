@@ -22,10 +22,12 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"k8s.io/utils/pointer"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -59,7 +61,7 @@ var _ = Describe("admin_client_test", func() {
 	Describe("JSON status", func() {
 		var status *fdbtypes.FoundationDBStatus
 		JustBeforeEach(func() {
-			status, err = client.GetStatus()
+			status, err = client.GetStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -136,7 +138,7 @@ var _ = Describe("admin_client_test", func() {
 
 		Context("with a backup running", func() {
 			BeforeEach(func() {
-				err = client.StartBackup("blobstore://test@test-service/test-backup", 10)
+				err = client.StartBackup(context.TODO(), "blobstore://test@test-service/test-backup", 10, fdbtypes.BackupModeContinuous)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -153,7 +155,7 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with a paused backup", func() {
 				BeforeEach(func() {
-					err = client.PauseBackups()
+					err = client.PauseBackups(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -164,9 +166,9 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with an resume backup", func() {
 				BeforeEach(func() {
-					err = client.PauseBackups()
+					err = client.PauseBackups(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
-					err = client.ResumeBackups()
+					err = client.ResumeBackups(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -177,7 +179,7 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with a stopped backup", func() {
 				BeforeEach(func() {
-					err = client.StopBackup("blobstore://test@test-service/test-backup")
+					err = client.StopBackup(context.TODO(), "blobstore://test@test-service/test-backup")
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -197,7 +199,7 @@ var _ = Describe("admin_client_test", func() {
 	Describe("backup status", func() {
 		var status *fdbtypes.FoundationDBLiveBackupStatus
 		JustBeforeEach(func() {
-			status, err = client.GetBackupStatus()
+			status, err = client.GetBackupStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -211,7 +213,7 @@ var _ = Describe("admin_client_test", func() {
 
 		Context("with a backup running", func() {
 			BeforeEach(func() {
-				err = client.StartBackup("blobstore://test@test-service/test-backup", 10)
+				err = client.StartBackup(context.TODO(), "blobstore://test@test-service/test-backup", 10, fdbtypes.BackupModeContinuous)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -224,7 +226,7 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with a paused backup", func() {
 				BeforeEach(func() {
-					err = client.PauseBackups()
+					err = client.PauseBackups(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -235,9 +237,9 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with a resumed backup", func() {
 				BeforeEach(func() {
-					err = client.PauseBackups()
+					err = client.PauseBackups(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
-					err = client.ResumeBackups()
+					err = client.ResumeBackups(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -248,7 +250,7 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with a stopped backup", func() {
 				BeforeEach(func() {
-					err = client.StopBackup("blobstore://test@test-service/test-backup")
+					err = client.StopBackup(context.TODO(), "blobstore://test@test-service/test-backup")
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -259,7 +261,7 @@ var _ = Describe("admin_client_test", func() {
 
 			Context("with a modification to the snapshot time", func() {
 				BeforeEach(func() {
-					err = client.ModifyBackup(20)
+					err = client.ModifyBackup(context.TODO(), 20)
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -275,7 +277,7 @@ var _ = Describe("admin_client_test", func() {
 
 		Context("with no restore running", func() {
 			BeforeEach(func() {
-				status, err = client.GetRestoreStatus()
+				status, err = client.GetRestoreStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -286,10 +288,10 @@ var _ = Describe("admin_client_test", func() {
 
 		Context("with a restore running", func() {
 			BeforeEach(func() {
-				err = client.StartRestore("blobstore://test@test-service/test-backup", nil)
+				err = client.StartRestore(context.TODO(), "blobstore://test@test-service/test-backup", nil, nil)
 				Expect(err).NotTo(HaveOccurred())
 
-				status, err = client.GetRestoreStatus()
+				status, err = client.GetRestoreStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -298,4 +300,274 @@ var _ = Describe("admin_client_test", func() {
 			})
 		})
 	})
+
+	Describe("exclusions", func() {
+		var address fdbtypes.ProcessAddress
+
+		JustBeforeEach(func() {
+			address, err = fdbtypes.ParseProcessAddress(cluster.Status.ProcessGroups[13].Addresses[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client.ExcludeInstances(context.TODO(), []fdbtypes.ProcessAddress{address})).NotTo(HaveOccurred())
+		})
+
+		Context("with an IPv4 address", func() {
+			It("should report the exclusion", func() {
+				exclusions, err := client.GetExclusions(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exclusions).To(HaveLen(1))
+				Expect(exclusions[0].String()).To(Equal(address.String()))
+
+				status, err := client.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Cluster.Processes["operator-test-1-storage-1-1"].Excluded).To(BeTrue())
+			})
+		})
+
+		Context("with an IPv6 address", func() {
+			BeforeEach(func() {
+				cluster.Spec.Routing.PodIPFamily = pointer.Int(6)
+				err = k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := reconcileCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeFalse())
+
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				client, err = newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should report the exclusion", func() {
+				Expect(address.IPAddress.To4()).To(BeNil())
+
+				exclusions, err := client.GetExclusions(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exclusions).To(HaveLen(1))
+				Expect(exclusions[0].String()).To(Equal(address.String()))
+
+				status, err := client.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+
+				excluded := 0
+				for _, process := range status.Cluster.Processes {
+					if process.Excluded {
+						excluded++
+						Expect(process.Address.IPAddress.Equal(address.IPAddress)).To(BeTrue())
+					}
+				}
+				Expect(excluded).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("mocked failures", func() {
+		var addresses []fdbtypes.ProcessAddress
+
+		BeforeEach(func() {
+			addresses = []fdbtypes.ProcessAddress{{IPAddress: net.ParseIP("1.1.1.1"), Port: 4501}}
+		})
+
+		Context("with a mocked command error", func() {
+			BeforeEach(func() {
+				client.MockCommandError("ExcludeInstances", 2)
+			})
+
+			It("should fail the given number of times", func() {
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).To(HaveOccurred())
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).To(HaveOccurred())
+				Expect(client.ExcludedAddresses).To(BeNil())
+
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+				Expect(client.ExcludedAddresses).To(Equal([]string{"1.1.1.1:4501"}))
+			})
+
+			It("should not affect other commands", func() {
+				Expect(client.IncludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with a mocked error value", func() {
+			BeforeEach(func() {
+				client.MockCommandErrorValue("GetStatus", 1, fmt.Errorf("database unavailable"))
+			})
+
+			It("should return the mocked error", func() {
+				_, err := client.GetStatus(context.TODO())
+				Expect(err).To(MatchError("database unavailable"))
+
+				_, err = client.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should return the default error after mocking a plain command error", func() {
+				client.MockCommandError("GetStatus", 1)
+				_, err := client.GetStatus(context.TODO())
+				Expect(err).To(MatchError("mocked error in GetStatus"))
+			})
+		})
+
+		Context("with a mocked timeout", func() {
+			BeforeEach(func() {
+				client.MockCommandTimeout("GetBackupStatus", 1)
+			})
+
+			It("should return a timeout error", func() {
+				_, err := client.GetBackupStatus(context.TODO())
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			})
+		})
+
+		Context("with a cancelled context", func() {
+			It("should return the context error without applying the command", func() {
+				cancelledContext, cancel := context.WithCancel(context.TODO())
+				cancel()
+
+				Expect(client.ExcludeInstances(cancelledContext, addresses)).To(MatchError(context.Canceled))
+				Expect(client.ExcludedAddresses).To(BeNil())
+
+				_, err := client.GetStatus(cancelledContext)
+				Expect(err).To(MatchError(context.Canceled))
+			})
+		})
+
+		Context("with a mocked partial failure", func() {
+			BeforeEach(func() {
+				addresses = append(addresses, fdbtypes.ProcessAddress{IPAddress: net.ParseIP("1.1.1.2"), Port: 4501})
+				client.MockPartialFailure("ExcludeInstances", 1)
+			})
+
+			It("should only apply the command to the first addresses", func() {
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).To(HaveOccurred())
+				Expect(client.ExcludedAddresses).To(Equal([]string{"1.1.1.1:4501"}))
+			})
+
+			It("should apply the command to all addresses when it is retried", func() {
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).To(HaveOccurred())
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+				Expect(client.ExcludedAddresses).To(ConsistOf("1.1.1.1:4501", "1.1.1.2:4501"))
+			})
+		})
+
+		Context("with stuck exclusions", func() {
+			BeforeEach(func() {
+				client.MockStuckExclusions(true)
+			})
+
+			It("should report the addresses as not safe to remove", func() {
+				remaining, err := client.CanSafelyRemove(context.TODO(), addresses)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remaining).To(Equal(addresses))
+			})
+
+			It("should report the addresses as safe to remove after the exclusions complete", func() {
+				client.MockStuckExclusions(false)
+				remaining, err := client.CanSafelyRemove(context.TODO(), addresses)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remaining).To(BeEmpty())
+			})
+		})
+
+		Context("with data movement", func() {
+			BeforeEach(func() {
+				client.MockDataMovement(2)
+			})
+
+			It("should not report addresses that are not excluded as safe to remove", func() {
+				remaining, err := client.CanSafelyRemove(context.TODO(), addresses)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remaining).To(Equal(addresses))
+			})
+
+			It("should report excluded addresses as safe to remove after the configured number of checks", func() {
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+
+				for i := 0; i < 2; i++ {
+					remaining, err := client.CanSafelyRemove(context.TODO(), addresses)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(remaining).To(Equal(addresses))
+				}
+
+				remaining, err := client.CanSafelyRemove(context.TODO(), addresses)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(remaining).To(BeEmpty())
+				Expect(client.GetPendingDataMovement("1.1.1.1:4501")).To(BeZero())
+			})
+
+			It("should keep the exclusion until the address is included again", func() {
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+
+				exclusions, err := client.GetExclusions(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exclusions).To(Equal(addresses))
+
+				Expect(client.IncludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+				exclusions, err = client.GetExclusions(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exclusions).To(BeEmpty())
+				Expect(client.GetPendingDataMovement("1.1.1.1:4501")).To(BeZero())
+			})
+
+			It("should not restart the data movement when excluding an address again", func() {
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+				_, err := client.CanSafelyRemove(context.TODO(), addresses)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ExcludeInstances(context.TODO(), addresses)).NotTo(HaveOccurred())
+				Expect(client.GetPendingDataMovement("1.1.1.1:4501")).To(Equal(1))
+			})
+		})
+
+		Context("with stale coordinators", func() {
+			var staleConnectionString string
+
+			BeforeEach(func() {
+				staleConnectionString = "operator-test:asdfasf@1.1.1.1:4501,1.1.1.2:4501,1.1.1.3:4501"
+				client.MockStaleCoordinators(staleConnectionString)
+			})
+
+			It("should report the stale connection string", func() {
+				connectionString, err := client.GetConnectionString(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(connectionString).To(Equal(staleConnectionString))
+			})
+
+			It("should report the stale coordinators in the status", func() {
+				coordinators, err := client.GetCoordinatorSet(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(coordinators).To(Equal(map[string]struct{}{}))
+
+				status, err := client.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Client.Coordinators.QuorumReachable).To(BeFalse())
+			})
+
+			It("should report the current connection string after clearing the mock", func() {
+				client.MockStaleCoordinators("")
+				connectionString, err := client.GetConnectionString(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(connectionString).To(Equal(cluster.Status.ConnectionString))
+			})
+		})
+
+		Context("with a frozen status", func() {
+			BeforeEach(func() {
+				err = client.MockFrozenStatus()
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should keep the status frozen when processes are killed", func() {
+				err = client.KillInstances(context.TODO(), addresses)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.frozenStatus).NotTo(BeNil())
+			})
+
+			It("should unfreeze the status when requested", func() {
+				client.UnfreezeStatus()
+				Expect(client.frozenStatus).To(BeNil())
+			})
+		})
+	})
 })
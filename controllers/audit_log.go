@@ -0,0 +1,396 @@
+/*
+ * audit_log.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// auditedEventReasons provides the reasons of the events that the operator
+// emits before it changes the cluster. These are the events that are
+// recorded in the audit log, once the sub-reconciler that emitted them has
+// completed.
+var auditedEventReasons = map[string]bool{
+	"AddingProcesses":               true,
+	"BouncingInstances":             true,
+	"ChangingCoordinators":          true,
+	"ClearingDatabaseKnobs":         true,
+	"ConfiguringDatabase":           true,
+	"ExcludingLocalities":           true,
+	"ExcludingProcesses":            true,
+	"ExpandingVolume":               true,
+	"ForcingConnectionStringChange": true,
+	"IncludingInstances":            true,
+	"IncludingLocalities":           true,
+	"RecreatingStuckPod":            true,
+	"RemovingProcesses":             true,
+	"ReplacingProcessGroups":        true,
+	"RestartingProcesses":           true,
+	"ResumingConsistencyCheck":      true,
+	"RollingBackExclusion":          true,
+	"RunningFdbcliCommand":          true,
+	"SettingDatabaseKnobs":          true,
+	"ShrinkingProcesses":            true,
+	"StartingSeedRestore":           true,
+	"SuspendingConsistencyCheck":    true,
+	"ThrottlingTag":                 true,
+	"UnthrottlingTag":               true,
+	"UpdatingClientConfig":          true,
+	"UpdatingConfigMap":             true,
+	"UpdatingConnectionString":      true,
+	"UpdatingPods":                  true,
+}
+
+// auditEventRecorder provides an event recorder that also records the
+// actions of the operator in the audit log of the cluster.
+//
+// The entries for an action are collected while the sub-reconciler runs, and
+// are written together with the outcome of the sub-reconciler once it is
+// done.
+type auditEventRecorder struct {
+	record.EventRecorder
+
+	// reader is used to read the audit log config map without going through
+	// the cache, so no entries from other updates get lost.
+	reader client.Reader
+
+	// client is used to update the audit log config map.
+	client client.Client
+
+	// actor identifies this operator instance in the audit log.
+	actor string
+
+	// lock protects the pending entries.
+	lock sync.Mutex
+
+	// pendingEntries contains the entries that have not been written yet,
+	// by the namespace and name of the cluster.
+	pendingEntries map[client.ObjectKey][]internal.AuditLogEntry
+}
+
+// NewAuditEventRecorder creates an event recorder that records the actions
+// of the operator in the audit log config map of clusters that have the
+// audit log enabled, in addition to emitting the events. The reader should
+// read directly from the API server.
+func NewAuditEventRecorder(recorder record.EventRecorder, kubeClient client.Client, reader client.Reader) record.EventRecorder {
+	actor, err := os.Hostname()
+	if err != nil {
+		log.Error(err, "Could not determine the hostname for the audit log")
+		actor = "unknown"
+	}
+
+	return &auditEventRecorder{
+		EventRecorder:  recorder,
+		reader:         reader,
+		client:         kubeClient,
+		actor:          actor,
+		pendingEntries: make(map[client.ObjectKey][]internal.AuditLogEntry),
+	}
+}
+
+// Event emits an event and records it in the audit log.
+func (recorder *auditEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	recorder.EventRecorder.Event(object, eventtype, reason, message)
+	recorder.addEventEntry(object, reason, message)
+}
+
+// Eventf emits an event with a formatted message and records it in the audit
+// log.
+func (recorder *auditEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	recorder.EventRecorder.Eventf(object, eventtype, reason, messageFmt, args...)
+	recorder.addEventEntry(object, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// AnnotatedEventf emits an event with annotations and records it in the
+// audit log.
+func (recorder *auditEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	recorder.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+	recorder.addEventEntry(object, reason, fmt.Sprintf(messageFmt, args...))
+}
+
+// addEventEntry adds a pending entry to the audit log of the cluster if the
+// event describes an action of the operator.
+func (recorder *auditEventRecorder) addEventEntry(object runtime.Object, reason string, message string) {
+	cluster, isCluster := object.(*fdbtypes.FoundationDBCluster)
+	if !isCluster || !auditedEventReasons[reason] {
+		return
+	}
+
+	recorder.addEntry(cluster, reason, message, nil)
+}
+
+// addEntry adds a pending entry to the audit log of the cluster. If the
+// result is set, the action has already completed and the entry gets the
+// outcome of the result.
+func (recorder *auditEventRecorder) addEntry(cluster *fdbtypes.FoundationDBCluster, action string, message string, result *error) {
+	if !cluster.ShouldWriteAuditLog() {
+		return
+	}
+
+	entry := internal.AuditLogEntry{
+		Timestamp:  time.Now().UTC().Format(time.RFC3339),
+		Generation: cluster.ObjectMeta.Generation,
+		Actor:      recorder.actor,
+		Action:     action,
+		Message:    message,
+	}
+
+	if result != nil {
+		setAuditLogOutcome(&entry, *result)
+	}
+
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	key := client.ObjectKeyFromObject(cluster)
+	recorder.pendingEntries[key] = append(recorder.pendingEntries[key], entry)
+}
+
+// setAuditLogOutcome sets the outcome of an action in an audit log entry.
+func setAuditLogOutcome(entry *internal.AuditLogEntry, actionErr error) {
+	if actionErr == nil {
+		entry.Outcome = internal.AuditLogOutcomeSucceeded
+		return
+	}
+
+	entry.Outcome = internal.AuditLogOutcomeFailed
+	entry.Error = actionErr.Error()
+}
+
+// writeAuditLog writes the pending entries for the cluster to its audit log.
+// The entries that do not have an outcome yet get the outcome of actionErr.
+// Failures to update the audit log are logged, but do not block the
+// reconciliation.
+func (recorder *auditEventRecorder) writeAuditLog(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, actionErr error) {
+	key := client.ObjectKeyFromObject(cluster)
+
+	recorder.lock.Lock()
+	entries := recorder.pendingEntries[key]
+	delete(recorder.pendingEntries, key)
+	recorder.lock.Unlock()
+
+	if len(entries) == 0 || !cluster.ShouldWriteAuditLog() {
+		return
+	}
+
+	for index := range entries {
+		if entries[index].Outcome == "" {
+			setAuditLogOutcome(&entries[index], actionErr)
+		}
+	}
+
+	err := retry.OnError(retry.DefaultRetry, func(err error) bool {
+		return k8serrors.IsConflict(err) || k8serrors.IsAlreadyExists(err)
+	}, func() error {
+		return appendAuditLogEntries(context, recorder.reader, recorder.client, cluster, entries)
+	})
+	if err != nil {
+		getLogger(context, cluster, "auditLog").Error(err, "Could not update the audit log", "entries", len(entries))
+	}
+}
+
+// writeAuditLog writes the pending entries for the cluster to its audit log,
+// if the reconciler records an audit log.
+func (r *FoundationDBClusterReconciler) writeAuditLog(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, actionErr error) {
+	recorder, isAuditRecorder := r.Recorder.(*auditEventRecorder)
+	if !isAuditRecorder {
+		return
+	}
+
+	recorder.writeAuditLog(context, cluster, actionErr)
+}
+
+// appendAuditLogEntries adds entries to the audit log config map of the
+// cluster, and creates the config map if it does not exist yet.
+func appendAuditLogEntries(context ctx.Context, reader client.Reader, kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, entries []internal.AuditLogEntry) error {
+	configMap := &corev1.ConfigMap{}
+	configMapExists := true
+	err := reader.Get(context, client.ObjectKey{Namespace: cluster.Namespace, Name: internal.GetAuditLogConfigMapName(cluster)}, configMap)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return err
+		}
+		configMapExists = false
+		configMap = internal.GetAuditLogConfigMap(cluster)
+	}
+
+	for _, entry := range entries {
+		err = internal.AppendAuditLogEntry(configMap, entry, cluster.GetAuditLogMaxEntries())
+		if err != nil {
+			return err
+		}
+	}
+
+	if configMapExists {
+		return kubeClient.Update(context, configMap)
+	}
+
+	return kubeClient.Create(context, configMap)
+}
+
+// auditClientContextKey is the key for the cluster that is being reconciled
+// in the context of a reconciliation.
+type auditClientContextKey struct{}
+
+// withAuditedCluster returns a context in which the changes of the audit
+// client are recorded in the audit log of the cluster.
+func withAuditedCluster(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) ctx.Context {
+	return ctx.WithValue(context, auditClientContextKey{}, cluster)
+}
+
+// auditClient provides a client that records the changes that it makes to
+// a cluster and to its pods, PVCs and services in the audit log of the
+// cluster that is being reconciled.
+type auditClient struct {
+	client.Client
+
+	// recorder collects the audit log entries.
+	recorder *auditEventRecorder
+}
+
+// NewAuditClient creates a client that records the changes it makes in the
+// audit log through the recorder, if the recorder was created through
+// NewAuditEventRecorder.
+func NewAuditClient(kubeClient client.Client, recorder record.EventRecorder) client.Client {
+	auditRecorder, isAuditRecorder := recorder.(*auditEventRecorder)
+	if !isAuditRecorder {
+		return kubeClient
+	}
+
+	return &auditClient{Client: kubeClient, recorder: auditRecorder}
+}
+
+// recordChange adds an entry for a change to an object to the audit log of
+// the cluster in the context.
+func (auditClient *auditClient) recordChange(context ctx.Context, action string, object client.Object, result error) {
+	cluster, hasCluster := context.Value(auditClientContextKey{}).(*fdbtypes.FoundationDBCluster)
+	if !hasCluster {
+		return
+	}
+
+	auditClient.recorder.addEntry(cluster, action, fmt.Sprintf("%T %s/%s", object, object.GetNamespace(), object.GetName()), &result)
+}
+
+// Create creates an object and records the creation of pods, PVCs and
+// services.
+func (auditClient *auditClient) Create(context ctx.Context, object client.Object, options ...client.CreateOption) error {
+	err := auditClient.Client.Create(context, object, options...)
+
+	switch object.(type) {
+	case *corev1.Pod:
+		auditClient.recordChange(context, "CreatedPod", object, err)
+	case *corev1.PersistentVolumeClaim:
+		auditClient.recordChange(context, "CreatedPVC", object, err)
+	case *corev1.Service:
+		auditClient.recordChange(context, "CreatedService", object, err)
+	}
+
+	return err
+}
+
+// Delete deletes an object and records the deletion of pods, PVCs and
+// services.
+func (auditClient *auditClient) Delete(context ctx.Context, object client.Object, options ...client.DeleteOption) error {
+	err := auditClient.Client.Delete(context, object, options...)
+
+	switch object.(type) {
+	case *corev1.Pod:
+		auditClient.recordChange(context, "DeletedPod", object, err)
+	case *corev1.PersistentVolumeClaim:
+		auditClient.recordChange(context, "DeletedPVC", object, err)
+	case *corev1.Service:
+		auditClient.recordChange(context, "DeletedService", object, err)
+	}
+
+	return err
+}
+
+// Update updates an object and records updates to the cluster.
+func (auditClient *auditClient) Update(context ctx.Context, object client.Object, options ...client.UpdateOption) error {
+	err := auditClient.Client.Update(context, object, options...)
+	if _, isCluster := object.(*fdbtypes.FoundationDBCluster); isCluster {
+		auditClient.recordChange(context, "UpdatedClusterSpec", object, err)
+	}
+
+	return err
+}
+
+// Patch patches an object and records patches to the cluster.
+func (auditClient *auditClient) Patch(context ctx.Context, object client.Object, patch client.Patch, options ...client.PatchOption) error {
+	err := auditClient.Client.Patch(context, object, patch, options...)
+	if _, isCluster := object.(*fdbtypes.FoundationDBCluster); isCluster {
+		auditClient.recordChange(context, "UpdatedClusterMetadata", object, err)
+	}
+
+	return err
+}
+
+// Status returns a writer for updating the status that records updates to
+// the status of the cluster.
+func (auditClient *auditClient) Status() client.StatusWriter {
+	return auditStatusWriter{StatusWriter: auditClient.Client.Status(), auditClient: auditClient}
+}
+
+// auditStatusWriter provides a status writer that records updates to the
+// status of the cluster in the audit log.
+type auditStatusWriter struct {
+	client.StatusWriter
+
+	// auditClient records the changes.
+	auditClient *auditClient
+}
+
+// Update updates the status of an object and records updates to the status
+// of the cluster.
+func (writer auditStatusWriter) Update(context ctx.Context, object client.Object, options ...client.UpdateOption) error {
+	err := writer.StatusWriter.Update(context, object, options...)
+	if _, isCluster := object.(*fdbtypes.FoundationDBCluster); isCluster {
+		writer.auditClient.recordChange(context, "UpdatedClusterStatus", object, err)
+	}
+
+	return err
+}
+
+// Patch patches the status of an object and records patches to the status
+// of the cluster.
+func (writer auditStatusWriter) Patch(context ctx.Context, object client.Object, patch client.Patch, options ...client.PatchOption) error {
+	err := writer.StatusWriter.Patch(context, object, patch, options...)
+	if _, isCluster := object.(*fdbtypes.FoundationDBCluster); isCluster {
+		writer.auditClient.recordChange(context, "UpdatedClusterStatus", object, err)
+	}
+
+	return err
+}
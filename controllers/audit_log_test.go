@@ -0,0 +1,276 @@
+/*
+ * audit_log_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("audit_log", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		cluster.Spec.AuditLog.Enabled = pointer.Bool(true)
+	})
+
+	getAuditLogEntries := func() ([]internal.AuditLogEntry, error) {
+		configMap := &corev1.ConfigMap{}
+		err := k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: internal.GetAuditLogConfigMapName(cluster)}, configMap)
+		if err != nil {
+			return nil, err
+		}
+
+		var entries []internal.AuditLogEntry
+		for _, line := range strings.Split(strings.TrimSpace(configMap.Data[internal.AuditLogKey]), "\n") {
+			entry := internal.AuditLogEntry{}
+			err = json.Unmarshal([]byte(line), &entry)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+
+		return entries, nil
+	}
+
+	Context("when reconciling a new cluster", func() {
+		BeforeEach(func() {
+			err := k8sClient.Create(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := reconcileCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+		})
+
+		It("should record the actions of the operator", func() {
+			entries, err := getAuditLogEntries()
+			Expect(err).NotTo(HaveOccurred())
+
+			actions := make([]string, 0, len(entries))
+			for _, entry := range entries {
+				Expect(entry.Timestamp).NotTo(BeEmpty())
+				Expect(entry.Actor).NotTo(BeEmpty())
+				Expect(entry.Generation).To(Equal(int64(1)))
+				Expect(entry.Outcome).To(Equal(internal.AuditLogOutcomeSucceeded))
+				actions = append(actions, entry.Action)
+			}
+			Expect(actions).To(ContainElements("UpdatingConfigMap", "CreatedPVC", "CreatedPod", "ConfiguringDatabase", "UpdatedClusterStatus"))
+		})
+
+		When("the operator deletes a pod", func() {
+			BeforeEach(func() {
+				pods := &corev1.PodList{}
+				err := k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pods.Items).NotTo(BeEmpty())
+
+				auditContext := withAuditedCluster(context.TODO(), cluster)
+				err = clusterReconciler.Delete(auditContext, &pods.Items[0])
+				Expect(err).NotTo(HaveOccurred())
+				clusterReconciler.writeAuditLog(auditContext, cluster, nil)
+			})
+
+			It("should record the deletion", func() {
+				entries, err := getAuditLogEntries()
+				Expect(err).NotTo(HaveOccurred())
+				lastEntry := entries[len(entries)-1]
+				Expect(lastEntry.Action).To(Equal("DeletedPod"))
+				Expect(lastEntry.Outcome).To(Equal(internal.AuditLogOutcomeSucceeded))
+			})
+		})
+
+		When("the operator fails to create a pod", func() {
+			BeforeEach(func() {
+				pods := &corev1.PodList{}
+				err := k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pods.Items).NotTo(BeEmpty())
+
+				pod := pods.Items[0].DeepCopy()
+				pod.ResourceVersion = ""
+				auditContext := withAuditedCluster(context.TODO(), cluster)
+				err = clusterReconciler.Create(auditContext, pod)
+				Expect(k8serrors.IsAlreadyExists(err)).To(BeTrue())
+				clusterReconciler.writeAuditLog(auditContext, cluster, nil)
+			})
+
+			It("should record the failed creation", func() {
+				entries, err := getAuditLogEntries()
+				Expect(err).NotTo(HaveOccurred())
+				lastEntry := entries[len(entries)-1]
+				Expect(lastEntry.Action).To(Equal("CreatedPod"))
+				Expect(lastEntry.Outcome).To(Equal(internal.AuditLogOutcomeFailed))
+				Expect(lastEntry.Error).NotTo(BeEmpty())
+			})
+		})
+	})
+
+	Context("with an event for an action", func() {
+		BeforeEach(func() {
+			clusterReconciler.Recorder.Event(cluster, corev1.EventTypeNormal, "ExcludingProcesses", "Excluding [1.1.1.1:4501]")
+			clusterReconciler.Recorder.Eventf(cluster, corev1.EventTypeNormal, "RemovingProcesses", "Removing pods: %v", []string{"storage-1"})
+		})
+
+		It("should not record the actions before they are complete", func() {
+			_, err := getAuditLogEntries()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		When("the actions are complete", func() {
+			BeforeEach(func() {
+				clusterReconciler.writeAuditLog(context.TODO(), cluster, nil)
+			})
+
+			It("should record the actions in order", func() {
+				entries, err := getAuditLogEntries()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(HaveLen(2))
+				Expect(entries[0].Action).To(Equal("ExcludingProcesses"))
+				Expect(entries[0].Message).To(Equal("Excluding [1.1.1.1:4501]"))
+				Expect(entries[0].Outcome).To(Equal(internal.AuditLogOutcomeSucceeded))
+				Expect(entries[1].Action).To(Equal("RemovingProcesses"))
+				Expect(entries[1].Message).To(Equal("Removing pods: [storage-1]"))
+				Expect(entries[1].Outcome).To(Equal(internal.AuditLogOutcomeSucceeded))
+			})
+		})
+
+		When("the actions have failed", func() {
+			BeforeEach(func() {
+				clusterReconciler.writeAuditLog(context.TODO(), cluster, fmt.Errorf("exclusion failed"))
+			})
+
+			It("should record the error", func() {
+				entries, err := getAuditLogEntries()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(HaveLen(2))
+				for _, entry := range entries {
+					Expect(entry.Outcome).To(Equal(internal.AuditLogOutcomeFailed))
+					Expect(entry.Error).To(Equal("exclusion failed"))
+				}
+			})
+		})
+
+		When("the audit log has reached the maximum number of entries", func() {
+			BeforeEach(func() {
+				cluster.Spec.AuditLog.MaxEntries = pointer.Int(2)
+				clusterReconciler.Recorder.Event(cluster, corev1.EventTypeNormal, "IncludingInstances", "Including [1.1.1.1:4501]")
+				clusterReconciler.writeAuditLog(context.TODO(), cluster, nil)
+			})
+
+			It("should remove the oldest entry", func() {
+				entries, err := getAuditLogEntries()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(entries).To(HaveLen(2))
+				Expect(entries[0].Action).To(Equal("RemovingProcesses"))
+				Expect(entries[1].Action).To(Equal("IncludingInstances"))
+			})
+		})
+	})
+
+	When("the status of the cluster cannot be patched after the reconciliation", func() {
+		var reconcileErr error
+
+		BeforeEach(func() {
+			err := k8sClient.Create(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			reconciler := createTestClusterReconciler()
+			reconciler.Client = NewAuditClient(failingStatusPatchClient{Client: k8sClient}, reconciler.Recorder)
+			_, reconcileErr = reconciler.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cluster)})
+		})
+
+		It("should not fail the reconciliation", func() {
+			Expect(reconcileErr).NotTo(HaveOccurred())
+		})
+
+		It("should record the failed status update in the audit log", func() {
+			entries, err := getAuditLogEntries()
+			Expect(err).NotTo(HaveOccurred())
+			lastEntry := entries[len(entries)-1]
+			Expect(lastEntry.Action).To(Equal("UpdatedClusterStatus"))
+			Expect(lastEntry.Outcome).To(Equal(internal.AuditLogOutcomeFailed))
+			Expect(lastEntry.Error).To(Equal("status patch failed"))
+		})
+	})
+
+	Context("with an event that does not describe an action", func() {
+		BeforeEach(func() {
+			clusterReconciler.Recorder.Event(cluster, corev1.EventTypeNormal, "ReconciliationComplete", "Reconciled generation 1")
+			clusterReconciler.writeAuditLog(context.TODO(), cluster, nil)
+		})
+
+		It("should not create the audit log", func() {
+			_, err := getAuditLogEntries()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	When("the audit log is disabled", func() {
+		BeforeEach(func() {
+			cluster.Spec.AuditLog.Enabled = nil
+			clusterReconciler.Recorder.Event(cluster, corev1.EventTypeNormal, "ExcludingProcesses", "Excluding [1.1.1.1:4501]")
+			clusterReconciler.writeAuditLog(context.TODO(), cluster, nil)
+		})
+
+		It("should not create the audit log", func() {
+			_, err := getAuditLogEntries()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
+
+// failingStatusPatchClient provides a client that fails to patch the status
+// of any object.
+type failingStatusPatchClient struct {
+	client.Client
+}
+
+// Status returns a writer that fails to patch the status.
+func (failingClient failingStatusPatchClient) Status() client.StatusWriter {
+	return failingStatusPatchWriter{StatusWriter: failingClient.Client.Status()}
+}
+
+// failingStatusPatchWriter provides a status writer that fails to patch the
+// status of any object.
+type failingStatusPatchWriter struct {
+	client.StatusWriter
+}
+
+// Patch returns an error without patching the status.
+func (failingStatusPatchWriter) Patch(context.Context, client.Object, client.Patch, ...client.PatchOption) error {
+	return fmt.Errorf("status patch failed")
+}
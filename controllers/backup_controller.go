@@ -22,17 +22,23 @@ package controllers
 
 import (
 	ctx "context"
+	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
 
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	"golang.org/x/net/context"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -47,6 +53,11 @@ type FoundationDBBackupReconciler struct {
 	Log                    logr.Logger
 	InSimulation           bool
 	DatabaseClientProvider DatabaseClientProvider
+
+	// SecretReader reads the blob credentials from the API server. The
+	// controller only caches the metadata of secrets, so the secrets of
+	// other applications are not kept in memory.
+	SecretReader client.Reader
 }
 
 // +kubebuilder:rbac:groups=apps.foundationdb.org,resources=foundationdbbackups,verbs=get;list;watch;create;update;patch;delete
@@ -80,6 +91,7 @@ func (r *FoundationDBBackupReconciler) Reconcile(ctx context.Context, request ct
 		stopBackup{},
 		toggleBackupPaused{},
 		modifyBackup{},
+		expireBackup{},
 		updateBackupStatus{},
 	}
 
@@ -99,6 +111,18 @@ func (r *FoundationDBBackupReconciler) Reconcile(ctx context.Context, request ct
 
 	backupLog.Info("Reconciliation complete")
 
+	nextExpiration := backup.GetNextExpirationTime()
+	if nextExpiration != nil {
+		delay := time.Until(*nextExpiration)
+		if delay <= 0 {
+			// The expiration is overdue, e.g. because the backup has no
+			// URL yet, so check again with the controller's backoff.
+			return ctrl.Result{Requeue: true}, nil
+		}
+
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
@@ -130,17 +154,58 @@ func (r *FoundationDBBackupReconciler) SetupWithManager(mgr ctrl.Manager, maxCon
 		return err
 	}
 
+	// Only react on generation changes or annotation changes
+	changePredicate := predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: maxConcurrentReconciles},
 		).
-		For(&fdbtypes.FoundationDBBackup{}).
-		Owns(&appsv1.Deployment{}).
-		// Only react on generation changes or annotation changes
-		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+		For(&fdbtypes.FoundationDBBackup{}, builder.WithPredicates(changePredicate)).
+		Owns(&appsv1.Deployment{}, builder.WithPredicates(changePredicate)).
+		// Secrets don't have a generation, so we react on every change to
+		// the blob credentials. We only watch the metadata, since the
+		// contents are read through the SecretReader.
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.findBackupsForSecret), builder.OnlyMetadata, builder.WithPredicates(predicate.ResourceVersionChangedPredicate{})).
 		Complete(r)
 }
 
+// findBackupsForSecret finds the backups that use a secret as their blob
+// credentials.
+func (r *FoundationDBBackupReconciler) findBackupsForSecret(secret client.Object) []reconcile.Request {
+	backups := &fdbtypes.FoundationDBBackupList{}
+	err := r.List(context.Background(), backups, client.InNamespace(secret.GetNamespace()))
+	if err != nil {
+		log.Error(err, "Error listing backups for secret", "namespace", secret.GetNamespace(), "secret", secret.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, backup := range backups.Items {
+		if backup.Spec.BlobCredentialsSecret == secret.GetName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: backup.Namespace, Name: backup.Name}})
+		}
+	}
+
+	return requests
+}
+
+// getBlobCredentials fetches the blob credentials secret for a backup.
+// This returns nil if the backup does not have a blob credentials secret.
+func (r *FoundationDBBackupReconciler) getBlobCredentials(context ctx.Context, backup *fdbtypes.FoundationDBBackup) (*corev1.Secret, error) {
+	if backup.Spec.BlobCredentialsSecret == "" {
+		return nil, nil
+	}
+
+	secret := &corev1.Secret{}
+	err := r.SecretReader.Get(context, client.ObjectKey{Namespace: backup.Namespace, Name: backup.Spec.BlobCredentialsSecret}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
 // backupSubReconciler describes a class that does part of the work of
 // reconciliation for a backup.
 type backupSubReconciler interface {
@@ -22,6 +22,7 @@ package controllers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
@@ -31,6 +32,7 @@ import (
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"golang.org/x/net/context"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -144,7 +146,7 @@ var _ = Describe("backup_controller", func() {
 			})
 
 			It("should start a backup", func() {
-				status, err := adminClient.GetBackupStatus()
+				status, err := adminClient.GetBackupStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.DestinationURL).To(Equal("blobstore://test@test-service/test-backup?bucket=fdb-backups"))
 				Expect(status.Status.Running).To(BeTrue())
@@ -192,7 +194,7 @@ var _ = Describe("backup_controller", func() {
 			})
 
 			It("should stop the backup", func() {
-				status, err := adminClient.GetBackupStatus()
+				status, err := adminClient.GetBackupStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.Status.Running).To(BeFalse())
 			})
@@ -206,7 +208,7 @@ var _ = Describe("backup_controller", func() {
 			})
 
 			It("should pause the backup", func() {
-				status, err := adminClient.GetBackupStatus()
+				status, err := adminClient.GetBackupStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.BackupAgentsPaused).To(BeTrue())
 			})
@@ -214,7 +216,7 @@ var _ = Describe("backup_controller", func() {
 
 		Context("when resuming a backup", func() {
 			BeforeEach(func() {
-				err = adminClient.PauseBackups()
+				err = adminClient.PauseBackups(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 
 				backup.Spec.BackupState = ""
@@ -223,7 +225,7 @@ var _ = Describe("backup_controller", func() {
 			})
 
 			It("should resume the backup", func() {
-				status, err := adminClient.GetBackupStatus()
+				status, err := adminClient.GetBackupStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.BackupAgentsPaused).To(BeFalse())
 			})
@@ -238,12 +240,76 @@ var _ = Describe("backup_controller", func() {
 			})
 
 			It("should modify the backup", func() {
-				status, err := adminClient.GetBackupStatus()
+				status, err := adminClient.GetBackupStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.SnapshotIntervalSeconds).To(Equal(100000))
 			})
 		})
 
+		Context("with the snapshot backup mode", func() {
+			BeforeEach(func() {
+				backup.Spec.BackupMode = fdbtypes.BackupModeSnapshot
+				err = k8sClient.Update(context.TODO(), backup)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			When("the backup is not running", func() {
+				BeforeEach(func() {
+					details := adminClient.Backups["default"]
+					details.Running = false
+					adminClient.Backups["default"] = details
+				})
+
+				It("should start a snapshot backup", func() {
+					status, err := adminClient.GetBackupStatus(context.TODO())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(status.Status.Running).To(BeTrue())
+					Expect(adminClient.backupMode).To(Equal(fdbtypes.BackupModeSnapshot))
+				})
+			})
+
+			When("the snapshot is completed", func() {
+				BeforeEach(func() {
+					details := adminClient.Backups["default"]
+					details.Running = false
+					details.Completed = true
+					adminClient.Backups["default"] = details
+				})
+
+				It("should not start a new backup", func() {
+					status, err := adminClient.GetBackupStatus(context.TODO())
+					Expect(err).NotTo(HaveOccurred())
+					Expect(status.Status.Running).To(BeFalse())
+					Expect(backup.Status.BackupDetails.Completed).To(BeTrue())
+				})
+			})
+		})
+
+		Context("with an expiration policy", func() {
+			BeforeEach(func() {
+				backup.Spec.ExpirationPolicy = &fdbtypes.BackupExpirationPolicy{DeleteBeforeDays: 30}
+				err = k8sClient.Update(context.TODO(), backup)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should expire the backup", func() {
+				Expect(adminClient.backupExpirations).To(Equal([]string{"blobstore://test@test-service/test-backup?bucket=fdb-backups"}))
+				Expect(backup.Status.LastExpirationTime).NotTo(BeNil())
+			})
+
+			When("reconciling the backup again", func() {
+				JustBeforeEach(func() {
+					result, err := reconcileBackup(backup)
+					Expect(err).NotTo((HaveOccurred()))
+					Expect(result.RequeueAfter).To(BeNumerically(">", 23*time.Hour))
+				})
+
+				It("should not expire the backup again", func() {
+					Expect(adminClient.backupExpirations).To(HaveLen(1))
+				})
+			})
+		})
+
 		Context("when changing labels", func() {
 			BeforeEach(func() {
 				backup.Spec.BackupDeploymentMetadata = &metav1.ObjectMeta{
@@ -265,6 +331,59 @@ var _ = Describe("backup_controller", func() {
 			})
 		})
 
+		Context("with a blob credentials secret", func() {
+			var secret *corev1.Secret
+
+			BeforeEach(func() {
+				secret = &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: backup.Namespace, Name: "blob-credentials"},
+					Data: map[string][]byte{
+						internal.BlobCredentialsKey: []byte("credentials-1"),
+					},
+				}
+				err = k8sClient.Create(context.TODO(), secret)
+				Expect(err).NotTo(HaveOccurred())
+
+				backup.Spec.BlobCredentialsSecret = secret.Name
+				err = k8sClient.Update(context.TODO(), backup)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			getDeployment := func() appsv1.Deployment {
+				deployments := &appsv1.DeploymentList{}
+				err = k8sClient.List(context.TODO(), deployments)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(deployments.Items)).To(Equal(1))
+				return deployments.Items[0]
+			}
+
+			It("should mount the credentials in the deployment", func() {
+				deployment := getDeployment()
+				Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "FDB_BLOB_CREDENTIALS", Value: "/var/blob-credentials/credentials"}))
+				Expect(deployment.Spec.Template.ObjectMeta.Annotations).To(HaveKey(fdbtypes.BlobCredentialsHashAnnotation))
+			})
+
+			When("the credentials change", func() {
+				var originalHash string
+
+				JustBeforeEach(func() {
+					originalHash = getDeployment().Spec.Template.ObjectMeta.Annotations[fdbtypes.BlobCredentialsHashAnnotation]
+
+					secret.Data[internal.BlobCredentialsKey] = []byte("credentials-2")
+					err = k8sClient.Update(context.TODO(), secret)
+					Expect(err).NotTo(HaveOccurred())
+
+					result, err := reconcileBackup(backup)
+					Expect(err).NotTo((HaveOccurred()))
+					Expect(result.Requeue).To(BeFalse())
+				})
+
+				It("should roll the deployment", func() {
+					Expect(getDeployment().Spec.Template.ObjectMeta.Annotations[fdbtypes.BlobCredentialsHashAnnotation]).NotTo(Equal(originalHash))
+				})
+			})
+		})
+
 		Context("when changing annotations", func() {
 			BeforeEach(func() {
 				deployments := &appsv1.DeploymentList{}
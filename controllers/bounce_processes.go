@@ -42,14 +42,14 @@ type bounceProcesses struct{}
 
 // reconcile runs the reconciler's work.
 func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "bounceProcesses")
+	logger := getLogger(context, cluster, "bounceProcesses")
 	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
 	if err != nil {
 		return &requeue{curError: err}
 	}
 	defer adminClient.Close()
 
-	status, err := adminClient.GetStatus()
+	status, err := adminClient.GetStatus(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -64,13 +64,24 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 		}
 	}
 
-	processesToBounce := fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.IncorrectCommandLine, true)
+	upgrading := cluster.Status.RunningVersion != cluster.Spec.Version
+	requireStaging, err := upgradeRequiresStaging(cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	processesToBounce := getProcessGroupsToBounce(cluster)
 	addresses := make([]fdbtypes.ProcessAddress, 0, len(processesToBounce))
+	bounced := make([]string, 0, len(processesToBounce))
 	allSynced := true
 	var missingAddress []string
+	var unstaged []string
 
 	for _, process := range processesToBounce {
 		if cluster.SkipProcessGroup(fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, process)) {
+			if requireStaging {
+				unstaged = append(unstaged, process)
+			}
 			continue
 		}
 
@@ -80,6 +91,7 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 		}
 
 		addresses = append(addresses, addressMap[process]...)
+		bounced = append(bounced, process)
 
 		instanceID := podmanager.GetProcessGroupIDFromProcessID(process)
 		pod, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetSinglePodListOptions(cluster, instanceID)...)
@@ -93,6 +105,7 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 		synced, err := r.updatePodDynamicConf(cluster, pod[0])
 		if !synced {
 			allSynced = false
+			unstaged = append(unstaged, instanceID)
 			logger.Info("Update dynamic Pod config", "processGroupID", instanceID, "synced", synced, "error", err)
 		}
 	}
@@ -101,12 +114,26 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 		return &requeue{curError: fmt.Errorf("could not find address for processes: %s", missingAddress)}
 	}
 
+	if requireStaging {
+		unstaged = append(unstaged, getProcessGroupsWithoutPendingBounce(cluster, status, processesToBounce)...)
+		if len(unstaged) > 0 {
+			message := fmt.Sprintf("Upgrade to version %s is not staged for process groups: %v", cluster.Spec.Version, unstaged)
+			logger.Info("Aborting upgrade until all processes are staged", "version", cluster.Spec.Version, "unstagedProcessGroups", unstaged)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "UpgradeStagingFailed", message)
+			cluster.Status.Generations.NeedsUpgradeStaging = cluster.ObjectMeta.Generation
+			err = r.Status().Update(context, cluster)
+			if err != nil {
+				logger.Error(err, "Error updating cluster status")
+			}
+
+			return &requeue{message: message}
+		}
+	}
+
 	if !allSynced {
 		return &requeue{message: "Waiting for config map to sync to all pods"}
 	}
 
-	upgrading := cluster.Status.RunningVersion != cluster.Spec.Version
-
 	if len(addresses) > 0 {
 		var enabled = cluster.Spec.AutomationOptions.KillProcesses
 		if enabled != nil && !*enabled {
@@ -137,6 +164,52 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 			}
 		}
 
+		hasMinimumFaultTolerance, err := internal.HasMinimumFaultTolerance(cluster, status)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if !hasMinimumFaultTolerance {
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, "NeedsBounce",
+				"Spec require a bounce of some processes, but the cluster cannot lose any fault domain without losing data or availability")
+			cluster.Status.Generations.NeedsBounce = cluster.ObjectMeta.Generation
+			err = r.Status().Update(context, cluster)
+			if err != nil {
+				logger.Error(err, "Error updating cluster status")
+			}
+
+			return &requeue{
+				message: "Bounces cannot proceed because cluster has no remaining fault tolerance",
+				delay:   30 * time.Second,
+			}
+		}
+
+		if cluster.Spec.MaximumTotalClientsForBounce != nil && status.Cluster.Clients.Count > *cluster.Spec.MaximumTotalClientsForBounce {
+			remainingWait := cluster.GetMaximumClientWaitForBounce() - time.Since(getBounceWaitStart(cluster, processesToBounce))
+			if remainingWait > 0 {
+				r.Recorder.Event(cluster, corev1.EventTypeNormal, "NeedsBounce",
+					fmt.Sprintf("Spec require a bounce of some processes, but %d clients are connected to the database", status.Cluster.Clients.Count))
+				cluster.Status.Generations.NeedsBounce = cluster.ObjectMeta.Generation
+				err = r.Status().Update(context, cluster)
+				if err != nil {
+					logger.Error(err, "Error updating cluster status")
+				}
+
+				delay := time.Minute
+				if remainingWait < delay {
+					delay = remainingWait
+				}
+
+				return &requeue{
+					message: fmt.Sprintf("Waiting for %d connected clients to disconnect before bouncing", status.Cluster.Clients.Count),
+					delay:   delay,
+				}
+			}
+
+			logger.Info("Bouncing processes with connected clients after waiting for them to disconnect",
+				"connectedClients", status.Cluster.Clients.Count)
+		}
+
 		var lockClient fdbadminclient.LockClient
 		useLocks := cluster.ShouldUseLocks()
 		if useLocks {
@@ -161,14 +234,14 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 			}
 		}
 
-		hasLock, err := r.takeLock(cluster, fmt.Sprintf("bouncing processes: %v", addresses))
+		hasLock, err := r.takeLock(context, cluster, fmt.Sprintf("bouncing processes: %v", addresses))
 		if !hasLock {
 			return &requeue{curError: err}
 		}
 
 		if useLocks && upgrading {
 			var req *requeue
-			addresses, req = getAddressesForUpgrade(r, adminClient, lockClient, cluster, version)
+			addresses, req = getAddressesForUpgrade(r, context, adminClient, lockClient, cluster, version)
 			if req != nil {
 				return req
 			}
@@ -179,7 +252,21 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 
 		logger.Info("Bouncing instances", "addresses", addresses, "upgrading", upgrading)
 		r.Recorder.Event(cluster, corev1.EventTypeNormal, "BouncingInstances", fmt.Sprintf("Bouncing processes: %v", addresses))
-		err = adminClient.KillInstances(addresses)
+
+		err = adminClient.KillInstances(context, addresses)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		for _, processGroupID := range bounced {
+			fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, processGroupID).UpdateCondition(fdbtypes.RestartRequested, false, nil, "")
+		}
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		err = updateRestartOptionsAnnotations(r, context, cluster, bounced)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -196,16 +283,155 @@ func (bounceProcesses) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 	return nil
 }
 
+// updateRestartOptionsAnnotations records the current process restart options
+// on the pods of the process groups that were bounced, since fdbmonitor has
+// restarted their processes with these options.
+func updateRestartOptionsAnnotations(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, processGroupIDs []string) error {
+	restartOptionsHash, err := internal.GetProcessRestartOptionsHash(cluster)
+	if err != nil {
+		return err
+	}
+
+	for _, processGroupID := range processGroupIDs {
+		instanceID := podmanager.GetProcessGroupIDFromProcessID(processGroupID)
+		pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetSinglePodListOptions(cluster, instanceID)...)
+		if err != nil {
+			return err
+		}
+
+		for _, pod := range pods {
+			if pod.ObjectMeta.Annotations[fdbtypes.LastRestartOptionsKey] == restartOptionsHash {
+				continue
+			}
+
+			if restartOptionsHash == "" {
+				delete(pod.ObjectMeta.Annotations, fdbtypes.LastRestartOptionsKey)
+			} else {
+				if pod.ObjectMeta.Annotations == nil {
+					pod.ObjectMeta.Annotations = make(map[string]string, 1)
+				}
+				pod.ObjectMeta.Annotations[fdbtypes.LastRestartOptionsKey] = restartOptionsHash
+			}
+
+			err = r.PodLifecycleManager.UpdateMetadata(r, context, cluster, pod)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// getProcessGroupsToBounce returns the process groups that have an incorrect
+// command line or where a restart was requested.
+func getProcessGroupsToBounce(cluster *fdbtypes.FoundationDBCluster) []string {
+	processGroupIDs := fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.IncorrectCommandLine, true)
+	incorrect := make(map[string]bool, len(processGroupIDs))
+	for _, processGroupID := range processGroupIDs {
+		incorrect[processGroupID] = true
+	}
+
+	for _, processGroupID := range fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.RestartRequested, true) {
+		if !incorrect[processGroupID] {
+			processGroupIDs = append(processGroupIDs, processGroupID)
+		}
+	}
+
+	return processGroupIDs
+}
+
+// getBounceWaitStart returns the time at which the first of the processes
+// that need a bounce was seen with an incorrect command line or had a restart
+// requested.
+func getBounceWaitStart(cluster *fdbtypes.FoundationDBCluster, processesToBounce []string) time.Time {
+	waitStart := time.Now()
+	for _, processGroupID := range processesToBounce {
+		processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, processGroupID)
+		if processGroup == nil {
+			continue
+		}
+
+		for _, conditionType := range []fdbtypes.ProcessGroupConditionType{fdbtypes.IncorrectCommandLine, fdbtypes.RestartRequested} {
+			conditionTime := processGroup.GetConditionTime(conditionType)
+			if conditionTime != nil && time.Unix(*conditionTime, 0).Before(waitStart) {
+				waitStart = time.Unix(*conditionTime, 0)
+			}
+		}
+	}
+
+	return waitStart
+}
+
+// upgradeRequiresStaging determines whether the cluster is being upgraded to a
+// version that is not protocol compatible with the running version. These
+// upgrades require all processes to be restarted at the same time, so the new
+// binaries and configuration must be staged for every process before any of
+// them can be killed.
+func upgradeRequiresStaging(cluster *fdbtypes.FoundationDBCluster) (bool, error) {
+	if !cluster.IsBeingUpgraded() {
+		return false, nil
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return false, err
+	}
+
+	runningVersion, err := fdbtypes.ParseFdbVersion(cluster.Status.RunningVersion)
+	if err != nil {
+		return false, err
+	}
+
+	return !version.IsProtocolCompatible(runningVersion), nil
+}
+
+// getProcessGroupsWithoutPendingBounce returns the process groups that are
+// still running an old version of fdbserver but are not marked as needing a
+// bounce. Killing the other processes would leave these processes unable to
+// talk to the rest of the cluster.
+func getProcessGroupsWithoutPendingBounce(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus, processesToBounce []string) []string {
+	pendingBounce := make(map[string]bool, len(processesToBounce))
+	for _, processGroupID := range processesToBounce {
+		pendingBounce[processGroupID] = true
+	}
+
+	managed := make(map[string]bool, len(cluster.Status.ProcessGroups))
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if !processGroup.Remove {
+			managed[processGroup.ProcessGroupID] = true
+		}
+	}
+
+	var processGroupIDs []string
+	for _, process := range status.Cluster.Processes {
+		if process.Version == cluster.Spec.Version {
+			continue
+		}
+
+		processGroupID := podmanager.GetProcessGroupIDFromProcessID(process.Locality["instance_id"])
+		if !managed[processGroupID] || pendingBounce[processGroupID] {
+			continue
+		}
+
+		// Only report each process group once.
+		pendingBounce[processGroupID] = true
+		processGroupIDs = append(processGroupIDs, processGroupID)
+	}
+
+	return processGroupIDs
+}
+
 // getAddressesForUpgrade checks that all processes in a cluster are ready to be
 // upgraded and returns the full list of addresses.
-func getAddressesForUpgrade(r *FoundationDBClusterReconciler, adminClient fdbadminclient.AdminClient, lockClient fdbadminclient.LockClient, cluster *fdbtypes.FoundationDBCluster, version fdbtypes.FdbVersion) ([]fdbtypes.ProcessAddress, *requeue) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "bounceProcesses")
+func getAddressesForUpgrade(r *FoundationDBClusterReconciler, context ctx.Context, adminClient fdbadminclient.AdminClient, lockClient fdbadminclient.LockClient, cluster *fdbtypes.FoundationDBCluster, version fdbtypes.FdbVersion) ([]fdbtypes.ProcessAddress, *requeue) {
+	logger := getLogger(context, cluster, "bounceProcesses")
 	pendingUpgrades, err := lockClient.GetPendingUpgrades(version)
 	if err != nil {
 		return nil, &requeue{curError: err}
 	}
 
-	databaseStatus, err := adminClient.GetStatus()
+	databaseStatus, err := adminClient.GetStatus(context)
 	if err != nil {
 		return nil, &requeue{curError: err}
 	}
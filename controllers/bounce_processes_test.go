@@ -22,6 +22,8 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"sort"
 	"time"
@@ -29,6 +31,7 @@ import (
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 )
@@ -97,6 +100,104 @@ var _ = Describe("bounceProcesses", func() {
 			Expect(len(adminClient.KilledAddresses)).To(Equal(len(addresses)))
 			Expect(adminClient.KilledAddresses).To(ContainElements(addresses))
 		})
+
+		Context("when killing the processes fails", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("KillInstances", 1)
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).To(HaveOccurred())
+			})
+
+			It("should keep the processes marked for a bounce", func() {
+				Expect(getProcessGroupsToBounce(cluster)).To(ConsistOf("storage-1", "storage-2"))
+			})
+		})
+
+		Context("with no remaining fault tolerance", func() {
+			BeforeEach(func() {
+				adminClient.maxZoneFailuresWithoutLosingAvailability = pointer.Int(0)
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(Equal("Bounces cannot proceed because cluster has no remaining fault tolerance"))
+			})
+
+			It("should not kill any processes", func() {
+				Expect(adminClient.KilledAddresses).To(BeEmpty())
+			})
+
+			It("should mark the cluster as needing a bounce", func() {
+				generation, err := reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.Generations.NeedsBounce).To(Equal(generation))
+			})
+		})
+
+		Context("with more connected clients than allowed", func() {
+			BeforeEach(func() {
+				maxClients := 2
+				cluster.Spec.MaximumTotalClientsForBounce = &maxClients
+				adminClient.MockConnectedClients(5)
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(Equal("Waiting for 5 connected clients to disconnect before bouncing"))
+			})
+
+			It("should not kill any processes", func() {
+				Expect(adminClient.KilledAddresses).To(BeEmpty())
+			})
+
+			It("should mark the cluster as needing a bounce", func() {
+				generation, err := reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.Generations.NeedsBounce).To(Equal(generation))
+			})
+
+			Context("when the processes have waited longer than the maximum wait", func() {
+				BeforeEach(func() {
+					maxWait := 60
+					cluster.Spec.MaximumClientWaitSecondsForBounce = &maxWait
+					for _, processGroupID := range []string{"storage-1", "storage-2"} {
+						processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, processGroupID)
+						for _, condition := range processGroup.ProcessGroupConditions {
+							if condition.ProcessGroupConditionType == fdbtypes.IncorrectCommandLine {
+								condition.Timestamp = time.Now().Add(-2 * time.Minute).Unix()
+							}
+						}
+					}
+				})
+
+				It("should not requeue", func() {
+					Expect(requeue).To(BeNil())
+				})
+
+				It("should kill the targeted processes", func() {
+					Expect(len(adminClient.KilledAddresses)).To(Equal(2))
+				})
+			})
+		})
+
+		Context("with fewer connected clients than allowed", func() {
+			BeforeEach(func() {
+				maxClients := 2
+				cluster.Spec.MaximumTotalClientsForBounce = &maxClients
+				adminClient.MockConnectedClients(1)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should kill the targeted processes", func() {
+				Expect(len(adminClient.KilledAddresses)).To(Equal(2))
+			})
+		})
 	})
 
 	Context("with pod in pending state", func() {
@@ -197,6 +298,101 @@ var _ = Describe("bounceProcesses", func() {
 			Expect(lockClient.pendingUpgrades[fdbtypes.Versions.NextMajorVersion]).To(Equal(expectedUpgrades))
 		})
 
+		Context("with a pending pod", func() {
+			BeforeEach(func() {
+				processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-1")
+				processGroup.UpdateCondition(fdbtypes.PodPending, true, nil, "")
+				cluster.Spec.AutomationOptions.IgnorePendingPodsDuration = 1 * time.Nanosecond
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(Equal("Upgrade to version 7.0.0 is not staged for process groups: [storage-1]"))
+			})
+
+			It("should not kill any processes", func() {
+				Expect(adminClient.KilledAddresses).To(BeEmpty())
+			})
+
+			It("should mark the cluster as needing upgrade staging", func() {
+				generation, err := reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.Generations.NeedsUpgradeStaging).To(Equal(generation))
+				Expect(cluster.Status.RunningVersion).To(Equal(fdbtypes.Versions.Default.String()))
+			})
+
+			Context("with a protocol compatible upgrade", func() {
+				BeforeEach(func() {
+					cluster.Spec.Version = fdbtypes.Versions.NextPatchVersion.String()
+				})
+
+				It("should not requeue", func() {
+					Expect(requeue).To(BeNil())
+				})
+
+				It("should kill the other processes", func() {
+					Expect(adminClient.KilledAddresses).NotTo(BeEmpty())
+				})
+			})
+		})
+
+		Context("with binary checksums that match the staged binaries", func() {
+			BeforeEach(func() {
+				hash := sha256.Sum256([]byte(fmt.Sprintf("bin/%s/fdbserver", cluster.Spec.Version)))
+				cluster.Spec.BinaryChecksums = map[string]fdbtypes.BinaryChecksums{
+					cluster.Spec.Version: {"fdbserver": hex.EncodeToString(hash[:])},
+				}
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should kill all the processes", func() {
+				Expect(adminClient.KilledAddresses).To(HaveLen(len(cluster.Status.ProcessGroups)))
+			})
+		})
+
+		Context("with binary checksums that do not match the staged binaries", func() {
+			BeforeEach(func() {
+				hash := sha256.Sum256([]byte("partially copied"))
+				cluster.Spec.BinaryChecksums = map[string]fdbtypes.BinaryChecksums{
+					cluster.Spec.Version: {"fdbserver": hex.EncodeToString(hash[:])},
+				}
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(HavePrefix("Upgrade to version 7.0.0 is not staged for process groups:"))
+			})
+
+			It("should not kill any processes", func() {
+				Expect(adminClient.KilledAddresses).To(BeEmpty())
+			})
+
+			It("should not update the running version in the status", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.RunningVersion).To(Equal(fdbtypes.Versions.Default.String()))
+			})
+		})
+
+		Context("with a process that is not marked for a bounce", func() {
+			BeforeEach(func() {
+				processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-1")
+				processGroup.UpdateCondition(fdbtypes.IncorrectCommandLine, false, nil, "")
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(Equal("Upgrade to version 7.0.0 is not staged for process groups: [storage-1]"))
+			})
+
+			It("should not kill any processes", func() {
+				Expect(adminClient.KilledAddresses).To(BeEmpty())
+			})
+		})
+
 		Context("with an unknown process", func() {
 			BeforeEach(func() {
 				adminClient.MockAdditionalProcesses([]fdbtypes.ProcessGroupStatus{{
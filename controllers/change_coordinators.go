@@ -35,7 +35,7 @@ type changeCoordinators struct{}
 
 // reconcile runs the reconciler's work.
 func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "changeCoordinators")
+	logger := getLogger(context, cluster, "changeCoordinators")
 	if !cluster.Status.Configured {
 		return nil
 	}
@@ -46,7 +46,7 @@ func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context
 	}
 	defer adminClient.Close()
 
-	connectionString, err := adminClient.GetConnectionString()
+	connectionString, err := adminClient.GetConnectionString(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -62,7 +62,7 @@ func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context
 		}
 	}
 
-	status, err := adminClient.GetStatus()
+	status, err := adminClient.GetStatus(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -72,7 +72,7 @@ func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context
 		coordinatorStatus[coordinator.Address.String()] = false
 	}
 
-	hasValidCoordinators, allAddressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+	hasValidCoordinators, allAddressesValid, err := checkCoordinatorValidity(context, cluster, status, coordinatorStatus)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -87,7 +87,7 @@ func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context
 		return nil
 	}
 
-	hasLock, err := r.takeLock(cluster, "changing coordinators")
+	hasLock, err := r.takeLock(context, cluster, "changing coordinators")
 	if !hasLock {
 		return &requeue{curError: err}
 	}
@@ -95,7 +95,7 @@ func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context
 	logger.Info("Changing coordinators")
 	r.Recorder.Event(cluster, corev1.EventTypeNormal, "ChangingCoordinators", "Choosing new coordinators")
 
-	coordinators, err := selectCoordinators(cluster, status)
+	coordinators, err := selectCoordinators(context, cluster, status)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -106,7 +106,7 @@ func (c changeCoordinators) reconcile(r *FoundationDBClusterReconciler, context
 	}
 
 	logger.Info("Final coordinators candidates", "coordinators", coordinatorAddresses)
-	connectionString, err = adminClient.ChangeCoordinators(coordinatorAddresses)
+	connectionString, err = adminClient.ChangeCoordinators(context, coordinatorAddresses)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -146,8 +146,8 @@ func selectCandidates(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.Fo
 	return candidates, nil
 }
 
-func selectCoordinators(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus) ([]localityInfo, error) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "changeCoordinators")
+func selectCoordinators(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus) ([]localityInfo, error) {
+	logger := getLogger(context, cluster, "changeCoordinators")
 	var err error
 	coordinatorCount := cluster.DesiredCoordinatorCount()
 
@@ -170,7 +170,7 @@ func selectCoordinators(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.
 		coordinatorStatus[coordinator.Address.String()] = false
 	}
 
-	hasValidCoordinators, allAddressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+	hasValidCoordinators, allAddressesValid, err := checkCoordinatorValidity(context, cluster, status, coordinatorStatus)
 	if err != nil {
 		return coordinators, err
 	}
@@ -21,6 +21,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"net"
@@ -64,10 +65,10 @@ var _ = Describe("Change coordinators", func() {
 
 		JustBeforeEach(func() {
 			var err error
-			status, err = adminClient.GetStatus()
+			status, err = adminClient.GetStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 
-			candidates, err = selectCoordinators(cluster, status)
+			candidates, err = selectCoordinators(context.TODO(), cluster, status)
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -167,7 +168,7 @@ var _ = Describe("Change coordinators", func() {
 				initialCandidates := candidates
 
 				for i := 0; i < 100; i++ {
-					newCandidates, err := selectCoordinators(cluster, status)
+					newCandidates, err := selectCoordinators(context.TODO(), cluster, status)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(newCandidates).To(Equal(initialCandidates))
 				}
@@ -219,13 +220,13 @@ var _ = Describe("Change coordinators", func() {
 			cluster.Spec.InstancesToRemove = removals
 
 			var err error
-			status, err = adminClient.GetStatus()
+			status, err = adminClient.GetStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 
 			// generate status for 2 dcs and 1 sate
 			status.Cluster.Processes = generateProcessInfo(dcCnt, satCnt, excludes)
 
-			candidates, err = selectCoordinators(cluster, status)
+			candidates, err = selectCoordinators(context.TODO(), cluster, status)
 			if shouldFail {
 				Expect(err).To(HaveOccurred())
 			} else {
@@ -397,7 +398,7 @@ var _ = Describe("Change coordinators", func() {
 					initialCandidates := candidates
 
 					for i := 0; i < 100; i++ {
-						newCandidates, err := selectCoordinators(cluster, status)
+						newCandidates, err := selectCoordinators(context.TODO(), cluster, status)
 						Expect(err).NotTo(HaveOccurred())
 						Expect(newCandidates).To(Equal(initialCandidates))
 					}
@@ -595,7 +596,7 @@ var _ = Describe("Change coordinators", func() {
 					initialCandidates := candidates
 
 					for i := 0; i < 100; i++ {
-						newCandidates, err := selectCoordinators(cluster, status)
+						newCandidates, err := selectCoordinators(context.TODO(), cluster, status)
 						Expect(err).NotTo(HaveOccurred())
 						Expect(newCandidates).To(Equal(initialCandidates))
 					}
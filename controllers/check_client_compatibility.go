@@ -23,6 +23,7 @@ package controllers
 import (
 	ctx "context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -37,7 +38,7 @@ type checkClientCompatibility struct{}
 
 // reconcile runs the reconciler's work.
 func (c checkClientCompatibility) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "checkClientCompatibility")
+	logger := getLogger(context, cluster, "checkClientCompatibility")
 	if !cluster.Status.Configured {
 		return nil
 	}
@@ -66,66 +67,82 @@ func (c checkClientCompatibility) reconcile(r *FoundationDBClusterReconciler, co
 		return nil
 	}
 
-	status, err := adminClient.GetStatus()
+	connectedClients, err := adminClient.GetConnectedClients(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
 
-	protocolVersion, err := adminClient.GetProtocolVersion(cluster.Spec.Version)
+	protocolVersion, err := adminClient.GetProtocolVersion(context, cluster.Spec.Version)
 	if err != nil {
 		return &requeue{curError: err}
 	}
 
-	if !cluster.Spec.IgnoreUpgradabilityChecks {
-		var unsupportedClients []string
-		if runningVersion.HasMaxProtocolClientsInStatus() {
-			unsupportedClients = make([]string, 0)
-			for _, versionInfo := range status.Cluster.Clients.SupportedVersions {
-				if versionInfo.ProtocolVersion == "Unknown" {
-					continue
-				}
-				match := versionInfo.ProtocolVersion == protocolVersion
+	unsupportedClients := getUnsupportedClients(connectedClients, protocolVersion, runningVersion.HasMaxProtocolClientsInStatus())
+	if len(unsupportedClients) == 0 {
+		return nil
+	}
 
-				if !match {
-					for _, client := range versionInfo.MaxProtocolClients {
-						unsupportedClients = append(unsupportedClients, client.Description())
-					}
-				}
+	message := fmt.Sprintf(
+		"%d clients do not support version %s: %s", len(unsupportedClients),
+		cluster.Spec.Version, strings.Join(unsupportedClients, ", "),
+	)
+
+	if cluster.Spec.IgnoreUpgradabilityChecks {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "IgnoringUnsupportedClient", message)
+		logger.Info("Ignoring unsupported clients", "message", message)
+		return nil
+	}
+
+	r.Recorder.Event(cluster, corev1.EventTypeWarning, "UnsupportedClient", message)
+	logger.Info("Deferring reconciliation due to unsupported clients", "message", message)
+	return &requeue{message: message, delay: 1 * time.Minute}
+}
+
+// getUnsupportedClients determines which of the connected clients do not
+// support the given protocol version. If useMaxProtocolClients is true, this
+// will check the max protocol version of every client, otherwise this will
+// check if any of the client libraries a client has loaded supports the
+// protocol version. The result is sorted.
+func getUnsupportedClients(connectedClients []fdbtypes.FoundationDBStatusSupportedVersion, protocolVersion string, useMaxProtocolClients bool) []string {
+	var unsupportedClients []string
+	if useMaxProtocolClients {
+		unsupportedClients = make([]string, 0)
+		for _, versionInfo := range connectedClients {
+			if versionInfo.ProtocolVersion == "Unknown" {
+				continue
 			}
-		} else {
-			clientsSupported := make(map[string]bool)
-			for _, versionInfo := range status.Cluster.Clients.SupportedVersions {
-				if versionInfo.ProtocolVersion == "Unknown" {
-					continue
-				}
-				match := versionInfo.ProtocolVersion == protocolVersion
-				for _, client := range versionInfo.ConnectedClients {
-					description := client.Description()
-					if match {
-						clientsSupported[description] = true
-					} else if !clientsSupported[description] {
-						clientsSupported[description] = false
-					}
+			match := versionInfo.ProtocolVersion == protocolVersion
+
+			if !match {
+				for _, client := range versionInfo.MaxProtocolClients {
+					unsupportedClients = append(unsupportedClients, client.Description())
 				}
 			}
-			unsupportedClients = make([]string, 0, len(clientsSupported))
-			for client, supported := range clientsSupported {
-				if !supported {
-					unsupportedClients = append(unsupportedClients, client)
+		}
+	} else {
+		clientsSupported := make(map[string]bool)
+		for _, versionInfo := range connectedClients {
+			if versionInfo.ProtocolVersion == "Unknown" {
+				continue
+			}
+			match := versionInfo.ProtocolVersion == protocolVersion
+			for _, client := range versionInfo.ConnectedClients {
+				description := client.Description()
+				if match {
+					clientsSupported[description] = true
+				} else if !clientsSupported[description] {
+					clientsSupported[description] = false
 				}
 			}
 		}
-
-		if len(unsupportedClients) > 0 {
-			message := fmt.Sprintf(
-				"%d clients do not support version %s: %s", len(unsupportedClients),
-				cluster.Spec.Version, strings.Join(unsupportedClients, ", "),
-			)
-			r.Recorder.Event(cluster, corev1.EventTypeNormal, "UnsupportedClient", message)
-			logger.Info("Deferring reconciliation due to unsupported clients", "message", message)
-			return &requeue{message: message, delay: 1 * time.Minute}
+		unsupportedClients = make([]string, 0, len(clientsSupported))
+		for client, supported := range clientsSupported {
+			if !supported {
+				unsupportedClients = append(unsupportedClients, client)
+			}
 		}
 	}
 
-	return nil
+	sort.Strings(unsupportedClients)
+	return unsupportedClients
 }
@@ -0,0 +1,87 @@
+/*
+ * check_client_compatibility_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("checkClientCompatibility", func() {
+	connectedClients := []fdbtypes.FoundationDBStatusSupportedVersion{
+		{
+			ClientVersion:   "6.2.30",
+			ProtocolVersion: "fdb00b062010001",
+			ConnectedClients: []fdbtypes.FoundationDBStatusConnectedClient{
+				{Address: "127.0.0.2:3687"},
+				{Address: "127.0.0.3:85891", LogGroup: "app"},
+			},
+			MaxProtocolClients: []fdbtypes.FoundationDBStatusConnectedClient{
+				{Address: "127.0.0.3:85891", LogGroup: "app"},
+			},
+		},
+		{
+			ClientVersion:   "6.3.15",
+			ProtocolVersion: "fdb00b063010001",
+			ConnectedClients: []fdbtypes.FoundationDBStatusConnectedClient{
+				{Address: "127.0.0.2:3687"},
+			},
+			MaxProtocolClients: []fdbtypes.FoundationDBStatusConnectedClient{
+				{Address: "127.0.0.2:3687"},
+			},
+		},
+		{
+			ClientVersion:   "Unknown",
+			ProtocolVersion: "Unknown",
+			ConnectedClients: []fdbtypes.FoundationDBStatusConnectedClient{
+				{Address: "127.0.0.4:4500"},
+			},
+			MaxProtocolClients: []fdbtypes.FoundationDBStatusConnectedClient{
+				{Address: "127.0.0.4:4500"},
+			},
+		},
+	}
+
+	table.DescribeTable("getting the unsupported clients",
+		func(protocolVersion string, useMaxProtocolClients bool, expected []string) {
+			Expect(getUnsupportedClients(connectedClients, protocolVersion, useMaxProtocolClients)).To(Equal(expected))
+		},
+		table.Entry("with the max protocol clients and a supported version",
+			"fdb00b063010001", true,
+			[]string{"127.0.0.3:85891 (app)"},
+		),
+		table.Entry("with the max protocol clients and an unknown version",
+			"fdb00b070010001", true,
+			[]string{"127.0.0.2:3687", "127.0.0.3:85891 (app)"},
+		),
+		table.Entry("with the connected clients and a supported version",
+			"fdb00b063010001", false,
+			[]string{"127.0.0.3:85891 (app)"},
+		),
+		table.Entry("with the connected clients and an unknown version",
+			"fdb00b070010001", false,
+			[]string{"127.0.0.2:3687", "127.0.0.3:85891 (app)"},
+		),
+	)
+})
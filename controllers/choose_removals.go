@@ -34,7 +34,7 @@ type chooseRemovals struct{}
 
 // reconcile runs the reconciler's work.
 func (c chooseRemovals) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "chooseRemovals")
+	logger := getLogger(context, cluster, "chooseRemovals")
 	hasNewRemovals := false
 
 	var removals = make(map[string]bool)
@@ -55,7 +55,7 @@ func (c chooseRemovals) reconcile(r *FoundationDBClusterReconciler, context ctx.
 	if err != nil {
 		return &requeue{curError: err}
 	}
-	status, err := adminClient.GetStatus()
+	status, err := adminClient.GetStatus(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -22,11 +22,16 @@ package controllers
 
 import (
 	ctx "context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
+	"reflect"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/podmanager"
@@ -40,11 +45,17 @@ import (
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // FoundationDBClusterReconciler reconciles a FoundationDBCluster object
@@ -57,6 +68,19 @@ type FoundationDBClusterReconciler struct {
 	PodClientProvider      func(*fdbtypes.FoundationDBCluster, *corev1.Pod) (internal.FdbPodClient, error)
 	DatabaseClientProvider DatabaseClientProvider
 	DeprecationOptions     internal.DeprecationOptions
+	// ClientLibraryVersions contains the versions of the client libraries
+	// that are available to the operator. These are tried when the operator
+	// cannot connect to a cluster with the running version or the version
+	// from the spec.
+	ClientLibraryVersions []string
+	// MaxConcurrentResourceCreations defines how many pods and PVCs the
+	// operator creates at the same time for a cluster. A value of 1 or
+	// less creates them one after another.
+	MaxConcurrentResourceCreations int
+	// ConnectionStringCheckInterval defines how often the operator reconciles
+	// a configured cluster to check if the coordinators were changed outside
+	// of the operator. A value of 0 disables the periodic check.
+	ConnectionStringCheckInterval time.Duration
 }
 
 // NewFoundationDBClusterReconciler creates a new FoundationDBClusterReconciler with defaults.
@@ -70,6 +94,9 @@ func NewFoundationDBClusterReconciler(podLifecycleManager podmanager.PodLifecycl
 // +kubebuilder:rbac:groups=apps.foundationdb.org,resources=foundationdbclusters,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps.foundationdb.org,resources=foundationdbclusters/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="",resources=pods;configmaps;persistentvolumeclaims;events;secrets;services,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get
+// +kubebuilder:rbac:groups=storage.k8s.io,resources=storageclasses,verbs=get
 
 // Reconcile runs the reconciliation logic.
 func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
@@ -78,6 +105,8 @@ func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request c
 	err := r.Get(ctx, request.NamespacedName, cluster)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
+			r.invalidateStatusCache(request.NamespacedName)
+			databaseMetrics.remove(request.NamespacedName)
 			return ctrl.Result{}, nil
 
 		}
@@ -85,12 +114,40 @@ func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request c
 		return ctrl.Result{}, err
 	}
 
-	clusterLog := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name)
+	clusterLog := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconcileID", rand.String(reconcileIDLength))
+	ctx = logr.NewContext(ctx, clusterLog)
+	ctx = withAuditedCluster(ctx, cluster)
 
-	if cluster.Spec.Skip {
-		clusterLog.Info("Skipping cluster with skip value true", "skip", cluster.Spec.Skip)
-		// Don't requeue
-		return ctrl.Result{}, nil
+	progress := &fdbtypes.ReconciliationProgress{Generation: cluster.ObjectMeta.Generation}
+	result, err := r.runReconciliation(ctx, cluster, clusterLog, progress)
+
+	progressErr := r.updateReconciliationProgress(ctx, cluster, progress)
+	if progressErr != nil {
+		clusterLog.Error(progressErr, "Error updating the reconciliation progress")
+	}
+
+	// The stall is checked after every reconciliation, including the ones
+	// that fail before the status is updated, so a cluster that can never be
+	// reconciled is still reported as stalled.
+	// A failure to update the condition is only logged, so that the audit
+	// log is still written and the result of the reconciliation is kept. The
+	// condition is updated again in the next reconciliation.
+	stalledErr := r.updateReconciliationStalledCondition(ctx, cluster)
+	if stalledErr != nil {
+		clusterLog.Error(stalledErr, "Error updating the reconciliation stalled condition")
+	}
+
+	r.writeAuditLog(ctx, cluster, err)
+
+	return result, err
+}
+
+// runReconciliation runs the sub-reconcilers for a cluster.
+// The progress is updated as the sub-reconcilers run.
+func (r *FoundationDBClusterReconciler) runReconciliation(ctx context.Context, cluster *fdbtypes.FoundationDBCluster, clusterLog logr.Logger, progress *fdbtypes.ReconciliationProgress) (ctrl.Result, error) {
+	err := r.updateFinalizer(ctx, cluster)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	err = internal.NormalizeClusterSpec(cluster, r.DeprecationOptions)
@@ -104,7 +161,7 @@ func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request c
 	}
 	defer adminClient.Close()
 
-	supportedVersion, err := adminClient.VersionSupported(cluster.Spec.Version)
+	supportedVersion, err := adminClient.VersionSupported(ctx, cluster.Spec.Version)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -113,25 +170,40 @@ func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request c
 	}
 
 	subReconcilers := []clusterSubReconciler{
+		runFdbcliCommand{},
+		runOneTimeOperations{},
 		updateStatus{},
+		recoverCoordinators{},
 		updateLockConfiguration{},
 		updateConfigMap{},
+		updateClientConfig{},
+		updateStatusSummary{},
+		updateMetricsExporter{},
 		checkClientCompatibility{},
 		replaceMisconfiguredProcessGroups{},
 		replaceFailedProcessGroups{},
+		recreateStuckPods{},
 		deletePodsForBuggification{},
 		addProcessGroups{},
 		addServices{},
 		addPVCs{},
+		expandPVCs{},
 		addPods{},
 		generateInitialClusterFile{},
 		updateSidecarVersions{},
 		updatePodConfig{},
 		updateLabels{},
 		updateDatabaseConfiguration{},
+		startSeedRestore{},
+		updateTagThrottles{},
+		updateTagQuotas{},
+		updateDatabaseKnobs{},
+		updateConsistencyCheck{},
 		chooseRemovals{},
 		excludeInstances{},
+		excludeLocalities{},
 		changeCoordinators{},
+		updateClientConfig{},
 		bounceProcesses{},
 		updatePods{},
 		removeServices{},
@@ -139,32 +211,113 @@ func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request c
 		updateStatus{},
 	}
 
+	// If the cluster is skipped we only update the status, so that the
+	// cluster can be observed while it is under manual control.
+	if cluster.Spec.Skip {
+		clusterLog.Info("Skipping cluster with skip value true, only updating the status", "skip", cluster.Spec.Skip)
+		subReconcilers = []clusterSubReconciler{
+			updateStatus{},
+			updateStatusSummary{},
+		}
+	}
+
+	// If the cluster is only observed we only update the status, because the
+	// operator does not own its resources or its database.
+	if cluster.Spec.ObserveOnly {
+		clusterLog.Info("Observing cluster, only updating the status", "observeOnly", cluster.Spec.ObserveOnly)
+		subReconcilers = []clusterSubReconciler{
+			updateStatus{},
+			updateStatusSummary{},
+		}
+	}
+
+	// If the cluster is marked for deletion we only tear down its resources.
+	isDeleted := !cluster.ObjectMeta.DeletionTimestamp.IsZero()
+	if isDeleted && !cluster.Spec.Skip && !cluster.Spec.ObserveOnly {
+		subReconcilers = []clusterSubReconciler{
+			deleteCluster{},
+		}
+	}
+
 	originalGeneration := cluster.ObjectMeta.Generation
 	normalizedSpec := cluster.Spec.DeepCopy()
+	progress.TotalSubReconcilers = len(subReconcilers)
 	delayedRequeue := false
+	recordedUnavailability := false
+	// The health in the status is measured before the database is configured,
+	// so it only gates the sub-reconcilers when the database was configured
+	// at the start of the reconciliation.
+	wasConfigured := cluster.Status.Configured
 
 	for _, subReconciler := range subReconcilers {
 		// We have to set the normalized spec here again otherwise any call to Update() for the status of the cluster
 		// will reset all normalized fields...
 		cluster.Spec = *(normalizedSpec.DeepCopy())
+
+		if wasConfigured && needsAvailableDatabase(cluster, subReconciler) && !cluster.Status.Health.Available {
+			clusterLog.Info("Skipping sub-reconciler because the database is unavailable", "subReconciler", fmt.Sprintf("%T", subReconciler))
+			if !recordedUnavailability {
+				err = setDatabaseUnavailableCondition(r, ctx, cluster)
+				if err != nil {
+					return processRequeue(&requeue{curError: err}, subReconciler, cluster, r.Recorder, clusterLog)
+				}
+				recordedUnavailability = true
+			}
+			progress.DelayedSubReconcilers = append(progress.DelayedSubReconcilers, getSubReconcilerName(subReconciler))
+			delayedRequeue = true
+			continue
+		}
+
 		clusterLog.Info("Attempting to run sub-reconciler", "subReconciler", fmt.Sprintf("%T", subReconciler))
 
 		requeue := subReconciler.reconcile(r, ctx, cluster)
+		if changesProcesses(subReconciler) {
+			r.invalidateStatusCache(client.ObjectKeyFromObject(cluster))
+		}
+
 		if requeue == nil {
+			r.writeAuditLog(ctx, cluster, nil)
+			progress.CompletedSubReconcilers++
 			continue
 		}
 
+		r.writeAuditLog(ctx, cluster, requeue.curError)
+
 		if requeue.delayedRequeue {
 			clusterLog.Info("Delaying requeue for sub-reconciler",
 				"subReconciler", fmt.Sprintf("%T", subReconciler),
 				"message", requeue.message)
+			progress.DelayedSubReconcilers = append(progress.DelayedSubReconcilers, getSubReconcilerName(subReconciler))
 			delayedRequeue = true
 			continue
 		}
 
+		progress.BlockingSubReconciler = getSubReconcilerName(subReconciler)
+		progress.Message = requeue.message
+		if progress.Message == "" && requeue.curError != nil {
+			progress.Message = requeue.curError.Error()
+		}
+
 		return processRequeue(requeue, subReconciler, cluster, r.Recorder, clusterLog)
 	}
 
+	if isDeleted && !cluster.Spec.Skip && !cluster.Spec.ObserveOnly {
+		clusterLog.Info("Deletion of cluster complete")
+		return ctrl.Result{}, nil
+	}
+
+	if cluster.Spec.Skip {
+		clusterLog.Info("Updated the status of the skipped cluster")
+		return ctrl.Result{RequeueAfter: cluster.GetStatusSummaryInterval()}, nil
+	}
+
+	// Observed clusters do not trigger any events on their resources, so the
+	// status is refreshed periodically.
+	if cluster.Spec.ObserveOnly {
+		clusterLog.Info("Updated the status of the observed cluster")
+		return ctrl.Result{RequeueAfter: getShorterInterval(cluster.GetStatusSummaryInterval(), observeOnlyRequeueInterval)}, nil
+	}
+
 	if cluster.Status.Generations.Reconciled < originalGeneration || delayedRequeue {
 		clusterLog.Info("Cluster was not fully reconciled by reconciliation process", "status", cluster.Status.Generations)
 
@@ -174,7 +327,128 @@ func (r *FoundationDBClusterReconciler) Reconcile(ctx context.Context, request c
 	clusterLog.Info("Reconciliation complete", "generation", cluster.Status.Generations.Reconciled)
 	r.Recorder.Event(cluster, corev1.EventTypeNormal, "ReconciliationComplete", fmt.Sprintf("Reconciled generation %d", cluster.Status.Generations.Reconciled))
 
-	return ctrl.Result{}, nil
+	// Requeue the cluster to keep the status summary up to date, to renew
+	// the tag throttles and to open and close the consistency check windows.
+	requeueAfter := getPeriodicRequeueInterval(cluster)
+	if cluster.Status.Configured {
+		requeueAfter = getShorterInterval(requeueAfter, r.ConnectionStringCheckInterval)
+	}
+
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// needsAvailableDatabase checks if a sub-reconciler makes changes to the
+// database that can only be done while the database is available. Running
+// these against an unavailable database would hang until the commands time
+// out, so they are skipped until the database is available again.
+//
+// Before the database is configured it is expected to be unavailable, and
+// during an upgrade the bounce is what makes the database available again, so
+// the sub-reconcilers are not skipped in these cases.
+func needsAvailableDatabase(cluster *fdbtypes.FoundationDBCluster, subReconciler clusterSubReconciler) bool {
+	if !cluster.Status.Configured {
+		return false
+	}
+
+	switch subReconciler.(type) {
+	case updateLockConfiguration, updateDatabaseConfiguration, updateTagThrottles, updateTagQuotas, updateDatabaseKnobs, updateConsistencyCheck, excludeInstances, excludeLocalities, changeCoordinators:
+		return true
+	case bounceProcesses:
+		return cluster.Status.RunningVersion == cluster.Spec.Version
+	default:
+		return false
+	}
+}
+
+// changesProcesses determines whether a sub-reconciler can create, delete or
+// restart pods. These changes are not made through the admin client, so the
+// cached database status has to be invalidated after these sub-reconcilers.
+func changesProcesses(subReconciler clusterSubReconciler) bool {
+	switch subReconciler.(type) {
+	case addPods, replaceFailedProcessGroups, recreateStuckPods, deletePodsForBuggification, updatePods, removeProcessGroups, deleteCluster:
+		return true
+	default:
+		return false
+	}
+}
+
+// invalidateStatusCache removes the cached database status of a cluster, if
+// the client provider caches the status.
+func (r *FoundationDBClusterReconciler) invalidateStatusCache(key client.ObjectKey) {
+	invalidator, ok := r.DatabaseClientProvider.(statusCacheInvalidator)
+	if !ok {
+		return
+	}
+
+	invalidator.invalidateClusterStatus(key)
+}
+
+// setDatabaseUnavailableCondition sets the DatabaseUnavailable condition on
+// the cluster. The event is only recorded when the condition changes, so a
+// database that stays unavailable does not produce an event on every
+// reconciliation.
+func setDatabaseUnavailableCondition(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) error {
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, fdbtypes.ClusterConditionDatabaseUnavailable) {
+		return nil
+	}
+
+	message := "Skipping changes to the database until it is available"
+	r.Recorder.Event(cluster, corev1.EventTypeWarning, "DatabaseUnavailable", message)
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               fdbtypes.ClusterConditionDatabaseUnavailable,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cluster.ObjectMeta.Generation,
+		Reason:             "DatabaseUnavailable",
+		Message:            message,
+	})
+
+	return r.Status().Update(context, cluster)
+}
+
+// getPeriodicRequeueInterval returns the interval after which a reconciled
+// cluster should be reconciled again. A zero interval means the cluster is
+// only reconciled when it changes.
+func getPeriodicRequeueInterval(cluster *fdbtypes.FoundationDBCluster) time.Duration {
+	interval := cluster.GetStatusSummaryInterval()
+
+	// Check the tag throttles often enough to renew them before they expire.
+	if len(cluster.Spec.TagThrottles) > 0 {
+		interval = getShorterInterval(interval, tagThrottleDuration/4)
+	}
+
+	if cluster.ShouldManageConsistencyCheck() {
+		interval = getShorterInterval(interval, getConsistencyCheckRequeueDelay(cluster, time.Now()))
+	}
+
+	// Check the database knobs regularly to detect drift.
+	if len(cluster.Spec.DatabaseKnobs) > 0 {
+		interval = getShorterInterval(interval, databaseKnobCheckInterval)
+	}
+
+	return interval
+}
+
+// getShorterInterval returns the shorter of two requeue intervals, where a
+// zero interval means that no requeue is needed.
+func getShorterInterval(interval time.Duration, other time.Duration) time.Duration {
+	if interval == 0 || (other > 0 && other < interval) {
+		return other
+	}
+
+	return interval
+}
+
+// getLogger returns the logger for a sub-reconciler. The logger carries the
+// fields of the current reconciliation if it was stored in the context, so
+// every line can be correlated with the reconciliation that produced it.
+func getLogger(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, reconciler string) logr.Logger {
+	logger := logr.FromContext(context)
+	if logger == nil {
+		logger = log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name)
+	}
+
+	return logger.WithValues("reconciler", reconciler)
 }
 
 // SetupWithManager prepares a reconciler for use.
@@ -209,6 +483,7 @@ func (r *FoundationDBClusterReconciler) SetupWithManager(mgr ctrl.Manager, maxCo
 		Owns(&corev1.PersistentVolumeClaim{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&corev1.Service{}).
+		Owns(&appsv1.Deployment{}).
 		// Only react on generation changes or annotation changes
 		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{}))
 	for _, object := range watchedObjects {
@@ -227,18 +502,28 @@ func (r *FoundationDBClusterReconciler) updatePodDynamicConf(cluster *fdbtypes.F
 		return false, nil
 	}
 
-	serversPerPod := 1
-
 	processClass, err := podmanager.GetProcessClass(cluster, pod)
 	if err != nil {
 		return false, err
 	}
 
-	if processClass == fdbtypes.ProcessClassStorage {
-		serversPerPod, err = internal.GetStorageServersPerPodForPod(pod)
+	serversPerPod, err := internal.GetServersPerPodForPod(pod, processClass)
+	if err != nil {
+		return false, err
+	}
+
+	if cluster.UseUnifiedImage() {
+		configuration, err := internal.GetMonitorProcessConfiguration(cluster, processClass, serversPerPod)
 		if err != nil {
 			return false, err
 		}
+
+		configurationData, err := json.Marshal(configuration)
+		if err != nil {
+			return false, err
+		}
+
+		return internal.UpdateDynamicFiles(podClient, internal.MonitorConfigurationFile, string(configurationData), func(client internal.FdbPodClient) error { return client.GenerateMonitorConf() })
 	}
 
 	conf, err := internal.GetMonitorConf(cluster, processClass, podClient, serversPerPod)
@@ -262,7 +547,39 @@ func (r *FoundationDBClusterReconciler) updatePodDynamicConf(cluster *fdbtypes.F
 	}
 
 	if !version.SupportsUsingBinariesFromMainContainer() || cluster.IsBeingUpgraded() {
-		return internal.CheckDynamicFilePresent(podClient, fmt.Sprintf("bin/%s/fdbserver", cluster.Spec.Version))
+		present, err := internal.CheckDynamicFilePresent(podClient, fmt.Sprintf("bin/%s/fdbserver", cluster.Spec.Version))
+		if !present || err != nil {
+			return present, err
+		}
+
+		return r.checkBinaryChecksums(cluster, podClient)
+	}
+
+	return true, nil
+}
+
+// checkBinaryChecksums verifies the binaries that the sidecar has copied for
+// the desired version against the checksums in the cluster spec, so we do not
+// bounce processes into a binary that was only partially copied.
+func (r *FoundationDBClusterReconciler) checkBinaryChecksums(cluster *fdbtypes.FoundationDBCluster, podClient internal.FdbPodClient) (bool, error) {
+	checksums := cluster.GetBinaryChecksums(cluster.Spec.Version)
+	binaries := make([]string, 0, len(checksums))
+	for binary := range checksums {
+		binaries = append(binaries, binary)
+	}
+	sort.Strings(binaries)
+
+	for _, binary := range binaries {
+		filename := fmt.Sprintf("bin/%s/%s", cluster.Spec.Version, binary)
+		match, err := internal.CheckDynamicFileChecksum(podClient, filename, checksums[binary])
+		if err != nil {
+			return false, err
+		}
+
+		if !match {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "BinaryChecksumMismatch", fmt.Sprintf("Checksum of %s in pod %s does not match the expected checksum", filename, podClient.GetPod().Name))
+			return false, fmt.Errorf("checksum of %s does not match the expected checksum", filename)
+		}
 	}
 
 	return true, nil
@@ -295,8 +612,8 @@ func (r *FoundationDBClusterReconciler) getLockClient(cluster *fdbtypes.Foundati
 }
 
 // takeLock attempts to acquire a lock.
-func (r *FoundationDBClusterReconciler) takeLock(cluster *fdbtypes.FoundationDBCluster, action string) (bool, error) {
-	log.Info("Taking lock on cluster", "namespace", cluster.Namespace, "cluster", cluster.Name, "action", action)
+func (r *FoundationDBClusterReconciler) takeLock(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, action string) (bool, error) {
+	getLogger(context, cluster, "takeLock").Info("Taking lock on cluster", "action", action)
 	lockClient, err := r.getLockClient(cluster)
 	if err != nil {
 		return false, err
@@ -313,6 +630,73 @@ func (r *FoundationDBClusterReconciler) takeLock(cluster *fdbtypes.FoundationDBC
 	return hasLock, nil
 }
 
+// updateFinalizer adds the cluster finalizer when graceful deletion is enabled
+// and removes it when graceful deletion is disabled.
+func (r *FoundationDBClusterReconciler) updateFinalizer(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) error {
+	// Finalizers cannot be added once the cluster is marked for deletion, and
+	// the finalizer is removed by the deleteCluster reconciler.
+	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	hasFinalizer := controllerutil.ContainsFinalizer(cluster, fdbtypes.ClusterFinalizer)
+	if cluster.UseGracefulDeletion() == hasFinalizer {
+		return nil
+	}
+
+	return internal.UpdateFinalizer(r, context, cluster, fdbtypes.ClusterFinalizer, !hasFinalizer)
+}
+
+// updateReconciliationStalledCondition updates the ReconciliationStalled
+// condition at the end of a reconciliation, and patches the conditions in the
+// cluster status if they changed.
+func (r *FoundationDBClusterReconciler) updateReconciliationStalledCondition(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) error {
+	// The cluster is gone once the deletion is complete.
+	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	original := cluster.DeepCopy()
+	wasStalled := meta.IsStatusConditionTrue(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+
+	setReconciliationPendingCondition(cluster)
+	setReconciliationStalledCondition(cluster)
+	recordReconciliationStalled(r, context, cluster, wasStalled)
+
+	if equality.Semantic.DeepEqual(original.Status.Conditions, cluster.Status.Conditions) {
+		return nil
+	}
+
+	return r.Status().Patch(context, cluster.DeepCopy(), client.MergeFrom(original))
+}
+
+// updateReconciliationProgress records how far the reconciliation got in the
+// status of the cluster. The status is only updated when the progress has
+// changed, and not when the reconciliation failed before the sub-reconcilers
+// ran.
+func (r *FoundationDBClusterReconciler) updateReconciliationProgress(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, progress *fdbtypes.ReconciliationProgress) error {
+	// The cluster is gone once the deletion is complete.
+	if !cluster.ObjectMeta.DeletionTimestamp.IsZero() || progress.TotalSubReconcilers == 0 {
+		return nil
+	}
+
+	if equality.Semantic.DeepEqual(cluster.Status.ReconciliationProgress, progress) {
+		return nil
+	}
+
+	original := cluster.DeepCopy()
+	cluster.Status.ReconciliationProgress = progress
+
+	return r.Status().Patch(context, cluster.DeepCopy(), client.MergeFrom(original))
+}
+
+// getSubReconcilerName returns the name of a sub-reconciler, as it is used
+// in the status of the cluster.
+func getSubReconcilerName(subReconciler clusterSubReconciler) string {
+	name := reflect.TypeOf(subReconciler).Name()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
 // clearPendingRemovalsFromSpec removes the pending removals from the cluster spec.
 func (r *FoundationDBClusterReconciler) clearPendingRemovalsFromSpec(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) error {
 	modifiedCluster := cluster.DeepCopy()
@@ -417,7 +801,27 @@ func localityInfoFromSidecar(cluster *fdbtypes.FoundationDBCluster, client inter
 	// This locality information is only used during the initial cluster file generation.
 	// So it should be good to only use the first process address here.
 	// This has the implication that in the initial cluster file only the first processes will be used.
-	address := cluster.GetFullAddress(substitutions["FDB_PUBLIC_IP"], 1)
+	processClass, err := podmanager.GetProcessClass(cluster, client.GetPod())
+	if err != nil {
+		return localityInfo{}, err
+	}
+
+	address := cluster.GetFullAddress(substitutions["FDB_PUBLIC_IP"], processClass, 1)
+
+	// Processes that use node ports advertise the node port instead of the
+	// port they listen on.
+	portVariable := "FDB_NODE_PORT"
+	if address.Flags["tls"] {
+		portVariable = "FDB_NODE_PORT_TLS"
+	}
+
+	if nodePort, present := substitutions[portVariable]; present {
+		address.Port, err = strconv.Atoi(nodePort)
+		if err != nil {
+			return localityInfo{}, err
+		}
+	}
+
 	return localityInfo{
 		ID:      substitutions["FDB_INSTANCE_ID"],
 		Address: address,
@@ -562,11 +966,13 @@ func getHardLimits(cluster *fdbtypes.FoundationDBCluster) map[string]int {
 // matching the cluster spec.
 // The third return value will hold any errors encountered when checking the
 // coordinators.
-func checkCoordinatorValidity(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus, coordinatorStatus map[string]bool) (bool, bool, error) {
+func checkCoordinatorValidity(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus, coordinatorStatus map[string]bool) (bool, bool, error) {
 	if len(coordinatorStatus) == 0 {
 		return false, false, errors.New("unable to get coordinator status")
 	}
 
+	logger := getLogger(context, cluster, "checkCoordinatorValidity")
+
 	allAddressesValid := true
 	allEligible := true
 
@@ -585,9 +991,7 @@ func checkCoordinatorValidity(cluster *fdbtypes.FoundationDBCluster, status *fdb
 		processGroupStatus := processGroups[process.Locality["instance_id"]]
 		pendingRemoval := processGroupStatus != nil && processGroupStatus.Remove
 		if processGroupStatus != nil && cluster.SkipProcessGroup(processGroupStatus) {
-			log.Info("Skipping process group with pending Pod",
-				"namespace", cluster.Namespace,
-				"cluster", cluster.Name,
+			logger.Info("Skipping process group with pending Pod",
 				"processGroupID", process.Locality["instance_id"],
 				"class", process.ProcessClass)
 			continue
@@ -619,13 +1023,13 @@ func checkCoordinatorValidity(cluster *fdbtypes.FoundationDBCluster, status *fdb
 			coordinatorDCs[process.Locality[fdbtypes.FDBLocalityDCIDKey]]++
 
 			if !cluster.IsEligibleAsCandidate(process.ProcessClass) {
-				log.Info("Process class of process is not eligible as coordinator", "namespace", cluster.Namespace, "cluster", cluster.Name, "process", process.Locality[fdbtypes.FDBLocalityInstanceIDKey], "class", process.ProcessClass, "address", address)
+				logger.Info("Process class of process is not eligible as coordinator", "process", process.Locality[fdbtypes.FDBLocalityInstanceIDKey], "class", process.ProcessClass, "address", address)
 				allEligible = false
 			}
 		}
 
 		if address == "" {
-			log.Info("Process has invalid address", "namespace", cluster.Namespace, "cluster", cluster.Name, "process", process.Locality[fdbtypes.FDBLocalityInstanceIDKey], "address", address)
+			logger.Info("Process has invalid address", "process", process.Locality[fdbtypes.FDBLocalityInstanceIDKey], "address", address)
 			allAddressesValid = false
 		}
 	}
@@ -633,7 +1037,7 @@ func checkCoordinatorValidity(cluster *fdbtypes.FoundationDBCluster, status *fdb
 	desiredCount := cluster.DesiredCoordinatorCount()
 	hasEnoughZones := len(coordinatorZones) == desiredCount
 	if !hasEnoughZones {
-		log.Info("Cluster does not have coordinators in the correct number of zones", "namespace", cluster.Namespace, "cluster", cluster.Name, "desiredCount", desiredCount, "coordinatorZones", coordinatorZones)
+		logger.Info("Cluster does not have coordinators in the correct number of zones", "desiredCount", desiredCount, "coordinatorZones", coordinatorZones)
 	}
 
 	var maxCoordinatorsPerDC int
@@ -643,7 +1047,7 @@ func checkCoordinatorValidity(cluster *fdbtypes.FoundationDBCluster, status *fdb
 
 		for dc, count := range coordinatorDCs {
 			if count > maxCoordinatorsPerDC {
-				log.Info("Cluster has too many coordinators in a single DC", "namespace", cluster.Namespace, "cluster", cluster.Name, "DC", dc, "count", count, "max", maxCoordinatorsPerDC)
+				logger.Info("Cluster has too many coordinators in a single DC", "DC", dc, "count", count, "max", maxCoordinatorsPerDC)
 				hasEnoughDCs = false
 			}
 		}
@@ -654,7 +1058,7 @@ func checkCoordinatorValidity(cluster *fdbtypes.FoundationDBCluster, status *fdb
 		allHealthy = allHealthy && healthy
 
 		if !healthy {
-			log.Info("Cluster has an unhealthy coordinator", "namespace", cluster.Namespace, "cluster", cluster.Name, "address", address)
+			logger.Info("Cluster has an unhealthy coordinator", "address", address)
 		}
 	}
 
@@ -666,12 +1070,12 @@ func newFdbPodClient(cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod) (in
 	return internal.NewFdbPodClient(cluster, pod)
 }
 
-func (r *FoundationDBClusterReconciler) getCoordinatorSet(cluster *fdbtypes.FoundationDBCluster) (map[string]struct{}, error) {
+func (r *FoundationDBClusterReconciler) getCoordinatorSet(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (map[string]struct{}, error) {
 	adminClient, err := r.DatabaseClientProvider.GetAdminClient(cluster, r)
 	if err != nil {
 		return map[string]struct{}{}, err
 	}
 	defer adminClient.Close()
 
-	return adminClient.GetCoordinatorSet()
+	return adminClient.GetCoordinatorSet(context)
 }
@@ -36,6 +36,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/go-logr/logr"
 
 	"github.com/prometheus/common/expfmt"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
@@ -46,10 +47,12 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 )
@@ -228,6 +231,16 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				}))
 			})
 
+			It("should record the progress of the reconciliation", func() {
+				progress := cluster.Status.ReconciliationProgress
+				Expect(progress).NotTo(BeNil())
+				Expect(progress.Generation).To(Equal(int64(1)))
+				Expect(progress.TotalSubReconcilers).To(BeNumerically(">", 0))
+				Expect(progress.CompletedSubReconcilers).To(Equal(progress.TotalSubReconcilers))
+				Expect(progress.DelayedSubReconcilers).To(BeEmpty())
+				Expect(progress.BlockingSubReconciler).To(BeEmpty())
+			})
+
 			It("should update the status with the reconciliation result", func() {
 				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
 				Expect(err).NotTo(HaveOccurred())
@@ -248,7 +261,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				Expect(len(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.IncorrectCommandLine, false))).To(Equal(0))
 				Expect(len(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.MissingProcesses, false))).To(Equal(0))
 
-				status, err := adminClient.GetStatus()
+				status, err := adminClient.GetStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 
 				configuration := status.Cluster.DatabaseConfiguration.DeepCopy()
@@ -260,6 +273,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					Healthy:              true,
 					FullReplication:      true,
 					DataMovementPriority: 0,
+					FaultTolerance:       pointer.Int(1),
 				}))
 			})
 		})
@@ -324,7 +338,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				for _, processGroup := range cluster.Status.ProcessGroups {
 					for i, addr := range processGroup.Addresses {
 						// +1 since the process list uses 1-based indexing.
-						fullAddr := cluster.GetFullAddress(addr, i+1)
+						fullAddr := cluster.GetFullAddress(addr, processGroup.ProcessClass, i+1)
 						processes[fullAddr.String()] = struct{}{}
 					}
 				}
@@ -686,6 +700,13 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 							Expect(pods.Items[firstStorageIndex+i].Name).To(Equal(originalPods.Items[firstStorageIndex+i].Name))
 						}
 					})
+
+					It("should update the generation status", func() {
+						_, err = reloadClusterGenerations(cluster)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(cluster.Status.Generations.Reconciled).To(Equal(originalVersion))
+						Expect(cluster.Status.Generations.NeedsShrink).To(Equal(originalVersion + 1))
+					})
 				})
 			})
 
@@ -1083,7 +1104,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				It("should bounce the processes", func() {
 					addresses := make([]string, 0, len(originalPods.Items))
 					for _, pod := range originalPods.Items {
-						addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, 1).String())
+						addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, internal.ProcessClassFromLabels(cluster, pod.ObjectMeta.Labels), 1).String())
 					}
 
 					sort.Slice(adminClient.KilledAddresses, func(i, j int) bool {
@@ -1128,6 +1149,17 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					Expect(adminClient.KilledAddresses).To(BeNil())
 				})
 
+				It("should record the sub-reconciler that blocked the reconciliation", func() {
+					err = k8sClient.Get(context.TODO(), client.ObjectKeyFromObject(cluster), cluster)
+					Expect(err).NotTo(HaveOccurred())
+					progress := cluster.Status.ReconciliationProgress
+					Expect(progress).NotTo(BeNil())
+					Expect(progress.Generation).To(Equal(originalVersion + 1))
+					Expect(progress.BlockingSubReconciler).To(Equal("BounceProcesses"))
+					Expect(progress.Message).To(Equal("Kills are disabled"))
+					Expect(progress.CompletedSubReconcilers).To(BeNumerically("<", progress.TotalSubReconcilers))
+				})
+
 				It("should update the config map", func() {
 					configMap := &corev1.ConfigMap{}
 					configMapName := types.NamespacedName{Namespace: "my-ns", Name: fmt.Sprintf("%s-config", cluster.Name)}
@@ -1172,9 +1204,9 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				It("should bounce the processes", func() {
 					addresses := make([]string, 0, len(originalPods.Items))
 					for _, pod := range originalPods.Items {
-						addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, 1).String())
+						addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, internal.ProcessClassFromLabels(cluster, pod.ObjectMeta.Labels), 1).String())
 						if internal.ProcessClassFromLabels(cluster, pod.ObjectMeta.Labels) == fdbtypes.ProcessClassStorage {
-							addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, 2).String())
+							addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, fdbtypes.ProcessClassStorage, 2).String())
 						}
 					}
 
@@ -1195,13 +1227,13 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
 				Expect(err).NotTo(HaveOccurred())
 
-				status, err := adminClient.GetStatus()
+				status, err := adminClient.GetStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.Cluster.DatabaseConfiguration.RedundancyMode).To(Equal(fdbtypes.RedundancyModeDouble))
 
 				cluster.Spec.DatabaseConfiguration.RedundancyMode = fdbtypes.RedundancyModeTriple
 
-				status, err = adminClient.GetStatus()
+				status, err = adminClient.GetStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status.Cluster.DatabaseConfiguration.RedundancyMode).To(Equal(fdbtypes.RedundancyModeDouble))
 			})
@@ -1217,6 +1249,78 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				})
 			})
 
+			Context("with the database unavailable", func() {
+				BeforeEach(func() {
+					adminClient.MockDatabaseUnavailable(true)
+					shouldCompleteReconciliation = false
+
+					err = k8sClient.Update(context.TODO(), cluster)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should not change the database configuration", func() {
+					Expect(adminClient.DatabaseConfiguration.RedundancyMode).To(Equal(fdbtypes.RedundancyModeDouble))
+				})
+
+				It("should mark the database as unavailable", func() {
+					generations, err := reloadClusterGenerations(cluster)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(generations.DatabaseUnavailable).To(Equal(originalVersion + 1))
+				})
+
+				It("should set the database unavailable condition", func() {
+					_, err := reloadCluster(cluster)
+					Expect(err).NotTo(HaveOccurred())
+					condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionDatabaseUnavailable)
+					Expect(condition).NotTo(BeNil())
+					Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+					Expect(condition.Reason).To(Equal("DatabaseUnavailable"))
+				})
+
+				It("should not record another event while the database stays unavailable", func() {
+					countEvents := func() int {
+						events := &corev1.EventList{}
+						err := k8sClient.List(context.TODO(), events)
+						Expect(err).NotTo(HaveOccurred())
+						count := 0
+						for _, event := range events.Items {
+							if event.InvolvedObject.UID == cluster.ObjectMeta.UID && event.Reason == "DatabaseUnavailable" {
+								count++
+							}
+						}
+						return count
+					}
+
+					initialCount := countEvents()
+					Expect(initialCount).To(BeNumerically(">", 0))
+
+					_, err := reconcileCluster(cluster)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(countEvents()).To(Equal(initialCount))
+				})
+
+				When("the database becomes available again", func() {
+					JustBeforeEach(func() {
+						adminClient.MockDatabaseUnavailable(false)
+						_, err := reconcileCluster(cluster)
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					It("should configure the database", func() {
+						Expect(adminClient.DatabaseConfiguration.RedundancyMode).To(Equal(fdbtypes.RedundancyModeTriple))
+					})
+
+					It("should clear the database unavailable condition", func() {
+						_, err := reloadCluster(cluster)
+						Expect(err).NotTo(HaveOccurred())
+						condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionDatabaseUnavailable)
+						Expect(condition).NotTo(BeNil())
+						Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+						Expect(condition.Reason).To(Equal("DatabaseAvailable"))
+					})
+				})
+			})
+
 			Context("with a change to the log version", func() {
 				BeforeEach(func() {
 					cluster.Spec.DatabaseConfiguration.LogVersion = 3
@@ -1239,7 +1343,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 
 					configuration := cluster.DesiredDatabaseConfiguration()
 					configuration.LogVersion = 3
-					err = adminClient.ConfigureDatabase(configuration, false)
+					err = adminClient.ConfigureDatabase(context.TODO(), configuration, false)
 					Expect(err).NotTo(HaveOccurred())
 
 					generationGap = 1
@@ -1521,6 +1625,55 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with a change to the process restart options", func() {
+			var adminClient *mockAdminClient
+
+			BeforeEach(func() {
+				adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+
+				cluster.Spec.ProcessRestartOptions.InitialRestartDelaySeconds = pointer.Int(5)
+				cluster.Spec.ProcessRestartOptions.RestartBackoff = pointer.Int(2)
+				err = k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should update the config map", func() {
+				configMap := &corev1.ConfigMap{}
+				configMapName := types.NamespacedName{Namespace: "my-ns", Name: fmt.Sprintf("%s-config", cluster.Name)}
+				err = k8sClient.Get(context.TODO(), configMapName, configMap)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Data["fdbmonitor-conf-storage"]).To(ContainSubstring("initial_restart_delay = 5\nrestart_backoff = 2\n"))
+			})
+
+			It("should bounce the processes", func() {
+				addresses := make([]string, 0, len(originalPods.Items))
+				for _, pod := range originalPods.Items {
+					addresses = append(addresses, cluster.GetFullAddress(pod.Status.PodIP, internal.ProcessClassFromLabels(cluster, pod.ObjectMeta.Labels), 1).String())
+				}
+
+				Expect(adminClient.KilledAddresses).To(ConsistOf(addresses))
+			})
+
+			It("should record the restart options on the pods", func() {
+				hash, err := internal.GetProcessRestartOptionsHash(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(hash).NotTo(BeEmpty())
+
+				pods := &corev1.PodList{}
+				err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pods.Items).To(HaveLen(len(originalPods.Items)))
+				for _, pod := range pods.Items {
+					Expect(pod.ObjectMeta.Annotations[fdbtypes.LastRestartOptionsKey]).To(Equal(hash))
+				}
+			})
+
+			It("should mark the process groups as correct", func() {
+				Expect(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.IncorrectCommandLine, true)).To(BeEmpty())
+			})
+		})
+
 		Context("with a change to config map labels", func() {
 			BeforeEach(func() {
 
@@ -1700,6 +1853,63 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				})
 			})
 
+			Context("with the manual strategy", func() {
+				BeforeEach(func() {
+					strategy := fdbtypes.PodUpdateStrategyManual
+					cluster.Spec.PodUpdateStrategy = &strategy
+				})
+
+				Context("without an approval", func() {
+					BeforeEach(func() {
+						shouldCompleteReconciliation = false
+
+						err = k8sClient.Update(context.TODO(), cluster)
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					It("should mark the generation as needing pod deletion", func() {
+						generations, err := reloadClusterGenerations(cluster)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(generations.Reconciled).To(Equal(originalVersion))
+						Expect(generations.NeedsPodDeletion).To(Equal(originalVersion + 1))
+					})
+
+					It("should not set the environment variable on the pods", func() {
+						pods := &corev1.PodList{}
+						err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+						Expect(err).NotTo(HaveOccurred())
+
+						for _, pod := range pods.Items {
+							Expect(len(pod.Spec.Containers[0].Env)).To(Equal(1))
+							Expect(pod.Spec.Containers[0].Env[0].Name).To(Equal("FDB_CLUSTER_FILE"))
+						}
+					})
+				})
+
+				Context("with an approval for the new generation", func() {
+					BeforeEach(func() {
+						cluster.Annotations = map[string]string{
+							fdbtypes.ApprovePodUpdatesAnnotation: fmt.Sprintf("%d", originalVersion+1),
+						}
+
+						err = k8sClient.Update(context.TODO(), cluster)
+						Expect(err).NotTo(HaveOccurred())
+					})
+
+					It("should set the environment variable on the pods", func() {
+						pods := &corev1.PodList{}
+						err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+						Expect(err).NotTo(HaveOccurred())
+
+						for _, pod := range pods.Items {
+							Expect(len(pod.Spec.Containers[0].Env)).To(Equal(2))
+							Expect(pod.Spec.Containers[0].Env[0].Name).To(Equal("TEST_CHANGE"))
+							Expect(pod.Spec.Containers[0].Env[0].Value).To(Equal("1"))
+						}
+					})
+				})
+			})
+
 			Context("with deletion disabled", func() {
 				BeforeEach(func() {
 					var flag = false
@@ -1793,6 +2003,48 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with a change to the public IP source to the node", func() {
+			BeforeEach(func() {
+				source := fdbtypes.PublicIPSourceNode
+				cluster.Spec.Routing.PublicIPSource = &source
+				err = k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should use the host IP as public IP and listen on the pod IP", func() {
+				pods := &corev1.PodList{}
+				err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, pod := range pods.Items {
+					Expect(pod.Annotations[fdbtypes.PublicIPSourceAnnotation]).To(Equal("node"))
+
+					sidecarEnv := internal.GetEnvVars(pod.Spec.Containers[1])
+					Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.hostIP"))
+					Expect(sidecarEnv["FDB_POD_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.podIP"))
+				}
+			})
+
+			It("should not create services for the pods", func() {
+				services := &corev1.ServiceList{}
+				err = k8sClient.List(context.TODO(), services, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(services.Items).To(BeEmpty())
+			})
+
+			It("should replace the old processes", func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+
+				replacements := make(map[string]bool, len(originalPods.Items))
+				for _, pod := range originalPods.Items {
+					replacements[pod.Status.PodIP] = true
+				}
+
+				Expect(adminClient.ReincludedAddresses).To(Equal(replacements))
+			})
+		})
+
 		Context("when enabling explicit listen addresses", func() {
 			BeforeEach(func() {
 				enabled := false
@@ -1832,6 +2084,62 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with multiple log servers per Pod", func() {
+			BeforeEach(func() {
+				cluster.Spec.LogServersPerPod = 2
+				err = k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should replace the log processes", func() {
+				pods := &corev1.PodList{}
+				err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pods.Items).To(HaveLen(len(originalPods.Items)))
+
+				logPods := 0
+				for _, pod := range pods.Items {
+					processClass := internal.ProcessClassFromLabels(cluster, pod.Labels)
+					serversPerPod, err := internal.GetServersPerPodForPod(&pod, processClass)
+					Expect(err).NotTo(HaveOccurred())
+
+					if processClass == fdbtypes.ProcessClassLog {
+						logPods++
+						Expect(serversPerPod).To(Equal(2))
+					} else {
+						Expect(serversPerPod).To(Equal(1))
+					}
+				}
+				Expect(logPods).To(Equal(4))
+			})
+
+			It("should update the status and the config map", func() {
+				Expect(cluster.Status.LogServersPerDisk).To(Equal([]int{2}))
+				Expect(cluster.Status.StorageServersPerDisk).To(Equal([]int{1}))
+
+				configMap := &corev1.ConfigMap{}
+				err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: "my-ns", Name: fmt.Sprintf("%s-config", cluster.Name)}, configMap)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Data).To(HaveKey("fdbmonitor-conf-log-density-2"))
+			})
+
+			It("should run two log processes in every log Pod", func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+
+				status, err := adminClient.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+
+				logProcesses := 0
+				for _, process := range status.Cluster.Processes {
+					if process.ProcessClass == fdbtypes.ProcessClassLog {
+						logProcesses++
+					}
+				}
+				Expect(logProcesses).To(Equal(8))
+			})
+		})
+
 		Context("with a change to the public IP source and multiple storage servers per Pod", func() {
 			BeforeEach(func() {
 				source := fdbtypes.PublicIPSourceService
@@ -2074,46 +2382,153 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
-		Context("downgrade cluster", func() {
+		Context("with a newly created cluster with only log processes as coordinator", func() {
 			BeforeEach(func() {
-				shouldCompleteReconciliation = false
-				IncompatibleVersion := fdbtypes.Versions.Default
-				IncompatibleVersion.Patch--
-				cluster.Spec.Version = IncompatibleVersion.String()
-				err := k8sClient.Update(context.TODO(), cluster)
+				k8sClient.Clear()
+				clearMockAdminClients()
+				clearMockLockClients()
+
+				cluster = internal.CreateDefaultCluster()
+				cluster.Spec.CoordinatorSelection = []fdbtypes.CoordinatorSelectionSetting{
+					{
+						ProcessClass: fdbtypes.ProcessClassLog,
+						Priority:     0,
+					},
+				}
+
+				err = k8sClient.Create(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				result, err := reconcileCluster(cluster)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeFalse())
+
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				generationGap = 0
 			})
 
-			It("should not downgrade cluster", func() {
-				Expect(cluster.Status.Generations.Reconciled).To(Equal(originalVersion))
-				Expect(cluster.Status.RunningVersion).To(Equal(fdbtypes.Versions.Default.String()))
+			It("should only have log processes as coordinator", func() {
+				connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+
+				addressClassMap := map[string]fdbtypes.ProcessClass{}
+				for _, pGroup := range cluster.Status.ProcessGroups {
+					addressClassMap[fmt.Sprintf("%s:4501", pGroup.Addresses[0])] = pGroup.ProcessClass
+				}
+
+				Expect(connectionString.Coordinators).To(HaveLen(3))
+				for _, coordinator := range connectionString.Coordinators {
+					Expect(addressClassMap[coordinator]).To(Equal(fdbtypes.ProcessClassLog))
+				}
 			})
 		})
 
-		Context("with an upgrade", func() {
-			var adminClient *mockAdminClient
-
+		Context("with a newly created cluster with coordinator processes", func() {
 			BeforeEach(func() {
-				cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+				k8sClient.Clear()
+				clearMockAdminClients()
+				clearMockLockClients()
 
-				adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+				cluster = internal.CreateDefaultCluster()
+				cluster.Spec.ProcessCounts.Coordinator = 3
+
+				err = k8sClient.Create(context.TODO(), cluster)
 				Expect(err).NotTo(HaveOccurred())
-			})
 
-			Context("with the default strategy", func() {
-				BeforeEach(func() {
-					err = k8sClient.Update(context.TODO(), cluster)
-					Expect(err).NotTo(HaveOccurred())
-				})
+				result, err := reconcileCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeFalse())
 
-				It("should bounce the processes", func() {
-					addresses := make(map[string]bool, len(originalPods.Items))
-					for _, pod := range originalPods.Items {
-						addresses[fmt.Sprintf("%s:4501", pod.Status.PodIP)] = true
-					}
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
 
-					killedAddresses := make(map[string]bool, len(adminClient.KilledAddresses))
-					for _, address := range adminClient.KilledAddresses {
+				generationGap = 0
+			})
+
+			It("should create the coordinator processes with a volume", func() {
+				pods := &corev1.PodList{}
+				err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(pods.Items)).To(Equal(20))
+
+				pvcs := &corev1.PersistentVolumeClaimList{}
+				err = k8sClient.List(context.TODO(), pvcs, getListOptions(cluster)...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(pvcs.Items)).To(Equal(11))
+			})
+
+			It("should only have coordinator processes as coordinator", func() {
+				connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+
+				addressClassMap := map[string]fdbtypes.ProcessClass{}
+				for _, pGroup := range cluster.Status.ProcessGroups {
+					addressClassMap[fmt.Sprintf("%s:4501", pGroup.Addresses[0])] = pGroup.ProcessClass
+				}
+
+				Expect(connectionString.Coordinators).To(HaveLen(3))
+				for _, coordinator := range connectionString.Coordinators {
+					Expect(addressClassMap[coordinator]).To(Equal(fdbtypes.ProcessClassCoordinator))
+				}
+			})
+		})
+
+		Context("with an increased coordinator count", func() {
+			BeforeEach(func() {
+				cluster.Spec.CoordinatorCount = pointer.Int(5)
+				err := k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should recruit the requested number of coordinators", func() {
+				connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(connectionString.Coordinators).To(HaveLen(5))
+			})
+		})
+
+		Context("downgrade cluster", func() {
+			BeforeEach(func() {
+				shouldCompleteReconciliation = false
+				IncompatibleVersion := fdbtypes.Versions.Default
+				IncompatibleVersion.Patch--
+				cluster.Spec.Version = IncompatibleVersion.String()
+				err := k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not downgrade cluster", func() {
+				Expect(cluster.Status.Generations.Reconciled).To(Equal(originalVersion))
+				Expect(cluster.Status.RunningVersion).To(Equal(fdbtypes.Versions.Default.String()))
+			})
+		})
+
+		Context("with an upgrade", func() {
+			var adminClient *mockAdminClient
+
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+
+				adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			Context("with the default strategy", func() {
+				BeforeEach(func() {
+					err = k8sClient.Update(context.TODO(), cluster)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should bounce the processes", func() {
+					addresses := make(map[string]bool, len(originalPods.Items))
+					for _, pod := range originalPods.Items {
+						addresses[fmt.Sprintf("%s:4501", pod.Status.PodIP)] = true
+					}
+
+					killedAddresses := make(map[string]bool, len(adminClient.KilledAddresses))
+					for _, address := range adminClient.KilledAddresses {
 						killedAddresses[address] = true
 					}
 					Expect(killedAddresses).To(Equal(addresses))
@@ -2254,6 +2669,20 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						Expect(matchingEvents).To(BeEmpty())
 					})
 
+					It("should warn about the unsupported clients", func() {
+						events := &corev1.EventList{}
+						err = k8sClient.List(context.TODO(), events)
+						Expect(err).NotTo(HaveOccurred())
+						matchingEvents := []corev1.Event{}
+						for _, event := range events.Items {
+							if event.InvolvedObject.UID == cluster.ObjectMeta.UID && event.Reason == "IgnoringUnsupportedClient" {
+								matchingEvents = append(matchingEvents, event)
+							}
+						}
+						Expect(len(matchingEvents)).NotTo(Equal(0))
+						Expect(matchingEvents[0].Type).To(Equal(corev1.EventTypeWarning))
+					})
+
 					It("should update the running version", func() {
 						Expect(cluster.Status.RunningVersion).To(Equal(cluster.Spec.Version))
 					})
@@ -2647,6 +3076,41 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with the unified image", func() {
+			BeforeEach(func() {
+				imageType := fdbtypes.ImageTypeUnified
+				cluster.Spec.ImageType = &imageType
+			})
+
+			It("should have the process configuration instead of the monitor conf", func() {
+				expectedConfiguration, err := internal.GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(expectedConfiguration.ServerCount).To(Equal(1))
+
+				configuration := internal.ProcessConfiguration{}
+				err = json.Unmarshal([]byte(configMap.Data["fdbmonitor-conf-storage-json"]), &configuration)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configuration).To(Equal(expectedConfiguration))
+
+				_, present := configMap.Data["fdbmonitor-conf-storage"]
+				Expect(present).To(BeFalse())
+			})
+
+			Context("with an empty connection string", func() {
+				BeforeEach(func() {
+					cluster.Status.ConnectionString = ""
+				})
+
+				It("should not start any processes", func() {
+					configuration := internal.ProcessConfiguration{}
+					err = json.Unmarshal([]byte(configMap.Data["fdbmonitor-conf-storage-json"]), &configuration)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(configuration.ServerCount).To(Equal(0))
+					Expect(configuration.Arguments).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("with custom sidecar substitutions", func() {
 			BeforeEach(func() {
 				cluster.Spec.SidecarVariables = []string{"FAULT_DOMAIN", "ZONE"}
@@ -2784,6 +3248,31 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with process restart options", func() {
+			BeforeEach(func() {
+				cluster.Spec.ProcessRestartOptions = fdbtypes.ProcessRestartOptions{
+					RestartDelaySeconds:              pointer.Int(120),
+					InitialRestartDelaySeconds:       pointer.Int(5),
+					RestartBackoff:                   pointer.Int(2),
+					RestartDelayResetIntervalSeconds: pointer.Int(300),
+				}
+				conf, err = internal.GetMonitorConf(cluster, fdbtypes.ProcessClassStorage, nil, cluster.GetStorageServersPerPod())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should generate the general section with the restart options", func() {
+				Expect(strings.Split(conf, "\n")[0:7]).To(Equal([]string{
+					"[general]",
+					"kill_on_configuration_change = false",
+					"restart_delay = 120",
+					"initial_restart_delay = 5",
+					"restart_backoff = 2",
+					"restart_delay_reset_interval = 300",
+					"[fdbserver.1]",
+				}))
+			})
+		})
+
 		Context("with a basic storage instance with multiple storage servers per Pod", func() {
 			BeforeEach(func() {
 				cluster.Spec.StorageServersPerPod = 2
@@ -2855,6 +3344,24 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with node ports as public service type", func() {
+			BeforeEach(func() {
+				source := fdbtypes.PublicIPSourceService
+				serviceType := corev1.ServiceTypeNodePort
+				cluster.Spec.Routing.PublicIPSource = &source
+				cluster.Spec.Routing.PublicServiceType = &serviceType
+				cluster.Status.HasListenIPsForAllPods = true
+				conf, err = internal.GetMonitorConf(cluster, fdbtypes.ProcessClassStorage, nil, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should advertise the node port and listen on the process port", func() {
+				lines := strings.Split(conf, "\n")
+				Expect(lines).To(ContainElement("public_address = $FDB_PUBLIC_IP:$FDB_NODE_PORT"))
+				Expect(lines).To(ContainElement("listen_address = $FDB_POD_IP:4501"))
+			})
+		})
+
 		Context("with the public IP from the service", func() {
 			BeforeEach(func() {
 				source := fdbtypes.PublicIPSourceService
@@ -2914,6 +3421,44 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with custom trace log settings", func() {
+			BeforeEach(func() {
+				rollSize := int64(20 * 1024 * 1024)
+				maxLogsSize := int64(500 * 1024 * 1024)
+				cluster.Spec.TraceLogs = fdbtypes.TraceLogConfig{
+					Directory:        "/var/log/fdb",
+					Format:           "json",
+					RollSizeBytes:    &rollSize,
+					MaxLogsSizeBytes: &maxLogsSize,
+				}
+				conf, err = internal.GetMonitorConf(cluster, fdbtypes.ProcessClassStorage, nil, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should generate the storage conf", func() {
+				Expect(conf).To(Equal(strings.Join([]string{
+					"[general]",
+					"kill_on_configuration_change = false",
+					"restart_delay = 60",
+					"[fdbserver.1]",
+					"command = $BINARY_DIR/fdbserver",
+					"cluster_file = /var/fdb/data/fdb.cluster",
+					"seed_cluster_file = /var/dynamic-conf/fdb.cluster",
+					"public_address = $FDB_PUBLIC_IP:4501",
+					"class = storage",
+					"logdir = /var/log/fdb",
+					"loggroup = " + cluster.Name,
+					"trace_format = json",
+					"logsize = 20971520",
+					"maxlogssize = 524288000",
+					"datadir = /var/fdb/data",
+					"locality_instance_id = $FDB_INSTANCE_ID",
+					"locality_machineid = $FDB_MACHINE_ID",
+					"locality_zoneid = $FDB_ZONE_ID",
+				}, "\n")))
+			})
+		})
+
 		Context("with TLS enabled", func() {
 			BeforeEach(func() {
 				cluster.Spec.MainContainer.EnableTLS = true
@@ -3197,6 +3742,50 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with database knobs", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = "7.1.0"
+				cluster.Status.RunningVersion = "7.1.0"
+				cluster.Spec.DatabaseKnobs = map[string]string{"max_trace_lines": "1000000"}
+				conf, err = internal.GetMonitorConf(cluster, fdbtypes.ProcessClassStorage, nil, cluster.GetStorageServersPerPod())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should enable the configuration database", func() {
+				Expect(conf).To(Equal(strings.Join([]string{
+					"[general]",
+					"kill_on_configuration_change = false",
+					"restart_delay = 60",
+					"[fdbserver.1]",
+					"command = $BINARY_DIR/fdbserver",
+					"cluster_file = /var/fdb/data/fdb.cluster",
+					"seed_cluster_file = /var/dynamic-conf/fdb.cluster",
+					"public_address = $FDB_PUBLIC_IP:4501",
+					"class = storage",
+					"logdir = /var/log/fdb-trace-logs",
+					"loggroup = " + cluster.Name,
+					"datadir = /var/fdb/data",
+					"locality_instance_id = $FDB_INSTANCE_ID",
+					"locality_machineid = $FDB_MACHINE_ID",
+					"locality_zoneid = $FDB_ZONE_ID",
+					"config_db = simple",
+				}, "\n")))
+			})
+
+			Context("with a version that does not support the configuration database", func() {
+				BeforeEach(func() {
+					cluster.Spec.Version = fdbtypes.Versions.Default.String()
+					cluster.Status.RunningVersion = fdbtypes.Versions.Default.String()
+					conf, err = internal.GetMonitorConf(cluster, fdbtypes.ProcessClassStorage, nil, cluster.GetStorageServersPerPod())
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should not enable the configuration database", func() {
+					Expect(conf).NotTo(ContainSubstring("config_db"))
+				})
+			})
+		})
+
 		Context("with a custom log group", func() {
 			BeforeEach(func() {
 				cluster.Spec.LogGroup = "test-fdb-cluster"
@@ -3454,6 +4043,38 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with the unified image", func() {
+			BeforeEach(func() {
+				imageType := fdbtypes.ImageTypeUnified
+				cluster.Spec.ImageType = &imageType
+				podClient, err := internal.NewMockFdbPodClient(cluster, &pods.Items[firstStorageIndex])
+				Expect(err).NotTo(HaveOccurred())
+				pClass, err := podmanager.GetProcessClass(cluster, &pods.Items[firstStorageIndex])
+				Expect(err).NotTo(HaveOccurred())
+
+				command, err = internal.GetStartCommand(cluster, pClass, podClient, 1, 2)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should generate the start command from the process configuration", func() {
+				id := podmanager.GetProcessGroupID(cluster, &pods.Items[firstStorageIndex])
+				Expect(command).To(Equal(strings.Join([]string{
+					"/usr/bin/fdbserver",
+					"--class=storage",
+					"--cluster_file=/var/fdb/data/fdb.cluster",
+					"--datadir=/var/fdb/data/1",
+					fmt.Sprintf("--locality_instance_id=%s", id),
+					fmt.Sprintf("--locality_machineid=%s-%s", cluster.Name, id),
+					fmt.Sprintf("--locality_process_id=%s-1", id),
+					fmt.Sprintf("--locality_zoneid=%s-%s", cluster.Name, id),
+					"--logdir=/var/log/fdb-trace-logs",
+					"--loggroup=" + cluster.Name,
+					fmt.Sprintf("--public_address=%s:4501", address),
+					"--seed_cluster_file=/var/dynamic-conf/fdb.cluster",
+				}, " ")))
+			})
+		})
+
 		Context("with binaries from the sidecar container", func() {
 			BeforeEach(func() {
 				cluster.Spec.Version = fdbtypes.Versions.WithoutBinariesFromMainContainer.String()
@@ -3551,6 +4172,52 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 		})
 	})
 
+	Describe("needsAvailableDatabase", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+
+		BeforeEach(func() {
+			cluster = internal.CreateDefaultCluster()
+			cluster.Status.Configured = true
+			cluster.Status.RunningVersion = cluster.Spec.Version
+		})
+
+		It("should require an available database for changes to the database", func() {
+			Expect(needsAvailableDatabase(cluster, updateDatabaseConfiguration{})).To(BeTrue())
+			Expect(needsAvailableDatabase(cluster, excludeInstances{})).To(BeTrue())
+			Expect(needsAvailableDatabase(cluster, excludeLocalities{})).To(BeTrue())
+			Expect(needsAvailableDatabase(cluster, updateDatabaseKnobs{})).To(BeTrue())
+			Expect(needsAvailableDatabase(cluster, changeCoordinators{})).To(BeTrue())
+			Expect(needsAvailableDatabase(cluster, bounceProcesses{})).To(BeTrue())
+		})
+
+		It("should not require an available database for other sub-reconcilers", func() {
+			Expect(needsAvailableDatabase(cluster, updateStatus{})).To(BeFalse())
+			Expect(needsAvailableDatabase(cluster, recoverCoordinators{})).To(BeFalse())
+			Expect(needsAvailableDatabase(cluster, addPods{})).To(BeFalse())
+		})
+
+		When("the database is not configured", func() {
+			BeforeEach(func() {
+				cluster.Status.Configured = false
+			})
+
+			It("should not require an available database", func() {
+				Expect(needsAvailableDatabase(cluster, updateDatabaseConfiguration{})).To(BeFalse())
+			})
+		})
+
+		When("an upgrade is in progress", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+			})
+
+			It("should not require an available database for the bounce", func() {
+				Expect(needsAvailableDatabase(cluster, bounceProcesses{})).To(BeFalse())
+				Expect(needsAvailableDatabase(cluster, excludeInstances{})).To(BeTrue())
+			})
+		})
+	})
+
 	Describe("chooseDistributedProcesses", func() {
 		var candidates []localityInfo
 		var result []localityInfo
@@ -3691,7 +4358,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			adminClient, err = newMockAdminClient(cluster, k8sClient)
 			Expect(err).NotTo(HaveOccurred())
 
-			status, err = adminClient.GetStatus()
+			status, err = adminClient.GetStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 		})
 
@@ -3702,7 +4369,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					coordinatorStatus[coordinator.Address.String()] = false
 				}
 
-				coordinatorsValid, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+				coordinatorsValid, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 				Expect(coordinatorsValid).To(BeTrue())
 				Expect(addressesValid).To(BeTrue())
 				Expect(err).To(BeNil())
@@ -3720,7 +4387,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					coordinatorStatus[coordinator.Address.String()] = false
 				}
 
-				coordinatorsValid, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+				coordinatorsValid, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 				Expect(coordinatorsValid).To(BeFalse())
 				Expect(addressesValid).To(BeTrue())
 				Expect(err).To(BeNil())
@@ -3748,7 +4415,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					coordinatorStatus[coordinator.Address.String()] = false
 				}
 
-				coordinatorsValid, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+				coordinatorsValid, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 				Expect(coordinatorsValid).To(BeFalse())
 				Expect(addressesValid).To(BeTrue())
 				Expect(err).To(BeNil())
@@ -3771,7 +4438,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(generation).To(Equal(int64(2)))
 
-				status, err = adminClient.GetStatus()
+				status, err = adminClient.GetStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 
 				cluster.Spec.DatabaseConfiguration.UsableRegions = 2
@@ -3799,7 +4466,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						coordinatorStatus[coordinator.Address.String()] = false
 					}
 
-					coordinatorsValid, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+					coordinatorsValid, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 					Expect(coordinatorsValid).To(BeTrue())
 					Expect(addressesValid).To(BeTrue())
 					Expect(err).To(BeNil())
@@ -3820,7 +4487,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						coordinatorStatus[coordinator.Address.String()] = false
 					}
 
-					coordinatorsValid, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+					coordinatorsValid, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 					Expect(coordinatorsValid).To(BeFalse())
 					Expect(addressesValid).To(BeTrue())
 					Expect(err).To(BeNil())
@@ -3848,7 +4515,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					adminClient, err = newMockAdminClient(cluster, k8sClient)
 					Expect(err).NotTo(HaveOccurred())
 
-					status, err = adminClient.GetStatus()
+					status, err = adminClient.GetStatus(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -3858,7 +4525,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						coordinatorStatus[coordinator.Address.String()] = false
 					}
 
-					_, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+					_, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 					Expect(addressesValid).To(BeTrue())
 					Expect(err).To(BeNil())
 				})
@@ -3875,7 +4542,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						adminClient, err = newMockAdminClient(cluster, k8sClient)
 						Expect(err).NotTo(HaveOccurred())
 
-						status, err = adminClient.GetStatus()
+						status, err = adminClient.GetStatus(context.TODO())
 						Expect(err).NotTo(HaveOccurred())
 					})
 
@@ -3885,7 +4552,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 							coordinatorStatus[coordinator.Address.String()] = false
 						}
 
-						_, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+						_, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 						Expect(addressesValid).To(BeTrue())
 						Expect(err).To(BeNil())
 					})
@@ -3904,7 +4571,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 					adminClient, err = newMockAdminClient(cluster, k8sClient)
 					Expect(err).NotTo(HaveOccurred())
 
-					status, err = adminClient.GetStatus()
+					status, err = adminClient.GetStatus(context.TODO())
 					Expect(err).NotTo(HaveOccurred())
 				})
 
@@ -3914,7 +4581,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						coordinatorStatus[coordinator.Address.String()] = false
 					}
 
-					_, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+					_, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 					Expect(addressesValid).To(BeTrue())
 					Expect(err).To(BeNil())
 				})
@@ -3932,7 +4599,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 						adminClient, err = newMockAdminClient(cluster, k8sClient)
 						Expect(err).NotTo(HaveOccurred())
 
-						status, err = adminClient.GetStatus()
+						status, err = adminClient.GetStatus(context.TODO())
 						Expect(err).NotTo(HaveOccurred())
 					})
 
@@ -3942,7 +4609,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 							coordinatorStatus[coordinator.Address.String()] = false
 						}
 
-						_, addressesValid, err := checkCoordinatorValidity(cluster, status, coordinatorStatus)
+						_, addressesValid, err := checkCoordinatorValidity(context.TODO(), cluster, status, coordinatorStatus)
 						Expect(addressesValid).To(BeTrue())
 						Expect(err).To(BeNil())
 					})
@@ -3967,7 +4634,7 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 				adminClient, err = newMockAdminClient(cluster, k8sClient)
 				Expect(err).NotTo(HaveOccurred())
 
-				status, err = adminClient.GetStatus()
+				status, err = adminClient.GetStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -4135,6 +4802,62 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 			})
 		})
 
+		Context("with the node as the public IP source", func() {
+			BeforeEach(func() {
+				var err error
+				source := fdbtypes.PublicIPSourceNode
+				cluster.Spec.Routing.PublicIPSource = &source
+				pod, err = internal.GetPod(cluster, "storage", 1)
+				Expect(err).NotTo(HaveOccurred())
+				pod.Status.PodIP = "1.1.1.1"
+				pod.Status.HostIP = "2.2.2.2"
+			})
+
+			It("should be the IP of the node", func() {
+				result := podmanager.GetPublicIPs(pod)
+				Expect(result).To(Equal([]string{"2.2.2.2"}))
+			})
+
+			Context("with a pod that is not scheduled yet", func() {
+				BeforeEach(func() {
+					pod.Status.HostIP = ""
+				})
+
+				It("should be empty", func() {
+					result := podmanager.GetPublicIPs(pod)
+					Expect(result).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("with the service as the public IP source", func() {
+			BeforeEach(func() {
+				var err error
+				source := fdbtypes.PublicIPSourceService
+				cluster.Spec.Routing.PublicIPSource = &source
+				pod, err = internal.GetPod(cluster, "storage", 1)
+				Expect(err).NotTo(HaveOccurred())
+				pod.Status.PodIP = "1.1.1.1"
+				pod.Annotations[fdbtypes.PublicIPAnnotation] = "3.3.3.3"
+			})
+
+			It("should be the IP of the service", func() {
+				result := podmanager.GetPublicIPs(pod)
+				Expect(result).To(Equal([]string{"3.3.3.3"}))
+			})
+
+			Context("with a service IP that is not assigned yet", func() {
+				BeforeEach(func() {
+					delete(pod.Annotations, fdbtypes.PublicIPAnnotation)
+				})
+
+				It("should be empty", func() {
+					result := podmanager.GetPublicIPs(pod)
+					Expect(result).To(BeEmpty())
+				})
+			})
+		})
+
 		Context("with no pod", func() {
 			It("should be empty", func() {
 				result := podmanager.GetPublicIPs(nil)
@@ -4144,6 +4867,251 @@ var _ = Describe(string(fdbtypes.ProcessClassClusterController), func() {
 	})
 })
 
+var _ = Describe("connection string check interval", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var result reconcile.Result
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		result, err = reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		clusterReconciler.ConnectionStringCheckInterval = 0
+	})
+
+	When("the check is disabled", func() {
+		It("should not requeue the cluster", func() {
+			Expect(result.Requeue).To(BeFalse())
+			Expect(result.RequeueAfter).To(BeZero())
+		})
+	})
+
+	When("the check is enabled", func() {
+		BeforeEach(func() {
+			clusterReconciler.ConnectionStringCheckInterval = 5 * time.Minute
+		})
+
+		It("should requeue the cluster after the interval", func() {
+			Expect(result.Requeue).To(BeFalse())
+			Expect(result.RequeueAfter).To(Equal(5 * time.Minute))
+		})
+	})
+})
+
+var _ = Describe("reconciliation stalled", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the spec of a new generation cannot be normalized", func() {
+		var timeout int
+
+		BeforeEach(func() {
+			timeout = 3600
+		})
+
+		JustBeforeEach(func() {
+			cluster.Spec.AutomationOptions.ReconciliationStalledTimeoutSeconds = &timeout
+			cluster.Spec.Routing.BasePort = pointer.Int(4600)
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = reconcileCluster(cluster)
+			Expect(err).To(HaveOccurred())
+
+			_, err = reloadClusterGenerations(cluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should mark the new generation as not reconciled", func() {
+			condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("ReconciliationPending"))
+			Expect(condition.ObservedGeneration).To(Equal(cluster.ObjectMeta.Generation))
+		})
+
+		It("should not mark the reconciliation as stalled", func() {
+			Expect(meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)).To(BeNil())
+		})
+
+		When("the timeout has passed", func() {
+			BeforeEach(func() {
+				timeout = 0
+			})
+
+			It("should mark the reconciliation as stalled", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("ReconciliationTimeoutExceeded"))
+			})
+
+			It("should record an event", func() {
+				events := &corev1.EventList{}
+				err = k8sClient.List(context.TODO(), events)
+				Expect(err).NotTo(HaveOccurred())
+				matchingEvents := []corev1.Event{}
+				for _, event := range events.Items {
+					if event.InvolvedObject.UID == cluster.ObjectMeta.UID && event.Reason == "ReconciliationStalled" {
+						matchingEvents = append(matchingEvents, event)
+					}
+				}
+				Expect(matchingEvents).To(HaveLen(1))
+			})
+		})
+	})
+})
+
+var _ = Describe("observe-only clusters", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var result reconcile.Result
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		cluster.Spec.ObserveOnly = true
+		cluster.Spec.SeedConnectionString = "existing:abcd@127.0.0.1:4501"
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err = reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not create any resources", func() {
+		pods := &corev1.PodList{}
+		err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pods.Items).To(BeEmpty())
+
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		err = k8sClient.List(context.TODO(), pvcs, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pvcs.Items).To(BeEmpty())
+
+		configMaps := &corev1.ConfigMapList{}
+		err = k8sClient.List(context.TODO(), configMaps, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(configMaps.Items).To(BeEmpty())
+	})
+
+	It("should not change the database", func() {
+		adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(adminClient.DatabaseConfiguration).To(BeNil())
+		Expect(adminClient.ExcludedAddresses).To(BeEmpty())
+	})
+
+	It("should connect through the seed connection string", func() {
+		Expect(cluster.Status.ConnectionString).NotTo(BeEmpty())
+		Expect(cluster.Status.RunningVersion).To(Equal(cluster.Spec.Version))
+	})
+
+	It("should not add a finalizer", func() {
+		Expect(cluster.ObjectMeta.Finalizers).To(BeEmpty())
+	})
+
+	It("should report that the cluster is only observed", func() {
+		Expect(cluster.Status.DesiredProcessGroups).To(Equal(0))
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("ObserveOnly"))
+		Expect(meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionConfigurationPending)).To(BeNil())
+	})
+
+	It("should refresh the status periodically", func() {
+		Expect(result.Requeue).To(BeFalse())
+		Expect(result.RequeueAfter).To(Equal(observeOnlyRequeueInterval))
+	})
+
+	It("should record that the cluster was only observed", func() {
+		Expect(cluster.Status.ObservedOnly).To(BeTrue())
+	})
+
+	When("observeOnly is disabled", func() {
+		BeforeEach(func() {
+			cluster.Spec.ObserveOnly = false
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = reconcileCluster(cluster)
+		})
+
+		It("should reject the change", func() {
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("observeOnly cannot be disabled for a cluster that the operator has only observed"))
+		})
+
+		It("should not create any pods", func() {
+			pods := &corev1.PodList{}
+			err = k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("getLogger", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+	})
+
+	When("the context has a logger", func() {
+		It("should add the reconciler to the logger from the context", func() {
+			logger := &valuesLogger{}
+			result := getLogger(logr.NewContext(context.TODO(), logger.WithValues("reconcileID", "abc")), cluster, "updateStatus")
+			Expect(result.(*valuesLogger).values).To(Equal([]interface{}{"reconcileID", "abc", "reconciler", "updateStatus"}))
+		})
+	})
+
+	When("the context has no logger", func() {
+		It("should return a logger", func() {
+			Expect(getLogger(context.TODO(), cluster, "updateStatus")).NotTo(BeNil())
+		})
+	})
+})
+
+// valuesLogger is a logger that records the values that were added to it.
+type valuesLogger struct {
+	values []interface{}
+}
+
+func (l *valuesLogger) Enabled() bool { return false }
+
+func (l *valuesLogger) Info(string, ...interface{}) {}
+
+func (l *valuesLogger) Error(error, string, ...interface{}) {}
+
+func (l *valuesLogger) V(int) logr.Logger { return l }
+
+func (l *valuesLogger) WithName(string) logr.Logger { return l }
+
+func (l *valuesLogger) WithValues(keysAndValues ...interface{}) logr.Logger {
+	values := append(append([]interface{}{}, l.values...), keysAndValues...)
+	return &valuesLogger{values: values}
+}
+
 func getProcessClassMap(cluster *fdbtypes.FoundationDBCluster, pods []corev1.Pod) map[fdbtypes.ProcessClass]int {
 	counts := make(map[fdbtypes.ProcessClass]int)
 	for _, pod := range pods {
@@ -4160,7 +5128,7 @@ func getConfigMapHash(cluster *fdbtypes.FoundationDBCluster, pClass fdbtypes.Pro
 		return "", err
 	}
 
-	serversPerPod, err := internal.GetStorageServersPerPodForPod(pod)
+	serversPerPod, err := internal.GetServersPerPodForPod(pod, pClass)
 	if err != nil {
 		return "", err
 	}
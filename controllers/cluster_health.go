@@ -0,0 +1,175 @@
+/*
+ * cluster_health.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// ClusterHealthPath is the path on the metrics server that serves the
+// health of the clusters that the operator manages.
+const ClusterHealthPath = "/clusters"
+
+// clusterHealthReport describes the health of all clusters that the operator
+// manages.
+type clusterHealthReport struct {
+	// Clusters provides the health of each cluster.
+	Clusters []clusterHealth `json:"clusters"`
+}
+
+// clusterHealth summarizes the health of a single cluster.
+type clusterHealth struct {
+	// Namespace provides the namespace of the cluster.
+	Namespace string `json:"namespace"`
+
+	// Name provides the name of the cluster.
+	Name string `json:"name"`
+
+	// Generation provides the generation of the cluster spec.
+	Generation int64 `json:"generation"`
+
+	// ReconciledGeneration provides the last generation that the operator
+	// has fully reconciled.
+	ReconciledGeneration int64 `json:"reconciledGeneration"`
+
+	// Reconciled reports whether the operator has reconciled the latest
+	// generation.
+	Reconciled bool `json:"reconciled"`
+
+	// Available reports whether the database is available.
+	Available bool `json:"available"`
+
+	// Healthy reports whether the database is healthy.
+	Healthy bool `json:"healthy"`
+
+	// FullReplication reports whether the database is fully replicated.
+	FullReplication bool `json:"fullReplication"`
+
+	// PendingOperations provides the operations that prevent the operator
+	// from reconciling the cluster, named like the fields of the generation
+	// status.
+	PendingOperations []string `json:"pendingOperations,omitempty"`
+
+	// ProcessGroupsToRemove provides the number of process groups that are
+	// marked for removal.
+	ProcessGroupsToRemove int `json:"processGroupsToRemove,omitempty"`
+}
+
+// clusterHealthHandler serves the health of the clusters that the operator
+// manages as JSON.
+type clusterHealthHandler struct {
+	reconciler *FoundationDBClusterReconciler
+}
+
+// NewClusterHealthHandler creates an HTTP handler that serves the health of
+// the clusters that the reconciler manages, for fleet dashboards and
+// external watchdogs.
+func NewClusterHealthHandler(reconciler *FoundationDBClusterReconciler) http.Handler {
+	return clusterHealthHandler{reconciler: reconciler}
+}
+
+// ServeHTTP serves the health report.
+func (handler clusterHealthHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet {
+		writer.Header().Set("Allow", http.MethodGet)
+		http.Error(writer, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	clusters := &fdbtypes.FoundationDBClusterList{}
+	err := handler.reconciler.List(request.Context(), clusters)
+	if err != nil {
+		log.Error(err, "Could not list clusters for the health report")
+		http.Error(writer, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report := clusterHealthReport{Clusters: make([]clusterHealth, 0, len(clusters.Items))}
+	for index := range clusters.Items {
+		health, err := getClusterHealth(&clusters.Items[index])
+		if err != nil {
+			log.Error(err, "Could not build the health report", "namespace", clusters.Items[index].Namespace, "cluster", clusters.Items[index].Name)
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		report.Clusters = append(report.Clusters, health)
+	}
+
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		if report.Clusters[i].Namespace != report.Clusters[j].Namespace {
+			return report.Clusters[i].Namespace < report.Clusters[j].Namespace
+		}
+		return report.Clusters[i].Name < report.Clusters[j].Name
+	})
+
+	writer.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(writer).Encode(report)
+	if err != nil {
+		log.Error(err, "Could not write the health report")
+	}
+}
+
+// getClusterHealth summarizes the health of a cluster based on its status.
+func getClusterHealth(cluster *fdbtypes.FoundationDBCluster) (clusterHealth, error) {
+	health := clusterHealth{
+		Namespace:            cluster.Namespace,
+		Name:                 cluster.Name,
+		Generation:           cluster.ObjectMeta.Generation,
+		ReconciledGeneration: cluster.Status.Generations.Reconciled,
+		Reconciled:           cluster.ObjectMeta.Generation == cluster.Status.Generations.Reconciled,
+		Available:            cluster.Status.Health.Available,
+		Healthy:              cluster.Status.Health.Healthy,
+		FullReplication:      cluster.Status.Health.FullReplication,
+	}
+
+	// The generation status only contains the fields for the operations
+	// that are pending, so we use its JSON representation to get their
+	// names.
+	generations, err := json.Marshal(cluster.Status.Generations)
+	if err != nil {
+		return health, err
+	}
+
+	pendingGenerations := make(map[string]int64)
+	err = json.Unmarshal(generations, &pendingGenerations)
+	if err != nil {
+		return health, err
+	}
+
+	for operation := range pendingGenerations {
+		if operation != "reconciled" {
+			health.PendingOperations = append(health.PendingOperations, operation)
+		}
+	}
+	sort.Strings(health.PendingOperations)
+
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove {
+			health.ProcessGroupsToRemove++
+		}
+	}
+
+	return health, nil
+}
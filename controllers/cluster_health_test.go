@@ -0,0 +1,117 @@
+/*
+ * cluster_health_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cluster_health", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var recorder *httptest.ResponseRecorder
+	var method string
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err := k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		_, err = reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		method = http.MethodGet
+	})
+
+	JustBeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		NewClusterHealthHandler(clusterReconciler).ServeHTTP(recorder, httptest.NewRequest(method, ClusterHealthPath, nil))
+	})
+
+	getReport := func() clusterHealthReport {
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		report := clusterHealthReport{}
+		err := json.Unmarshal(recorder.Body.Bytes(), &report)
+		Expect(err).NotTo(HaveOccurred())
+		return report
+	}
+
+	Context("with a reconciled cluster", func() {
+		It("should report the cluster as healthy", func() {
+			Expect(getReport().Clusters).To(Equal([]clusterHealth{
+				{
+					Namespace:            cluster.Namespace,
+					Name:                 cluster.Name,
+					Generation:           1,
+					ReconciledGeneration: 1,
+					Reconciled:           true,
+					Available:            true,
+					Healthy:              true,
+					FullReplication:      true,
+				},
+			}))
+		})
+	})
+
+	Context("with pending operations", func() {
+		BeforeEach(func() {
+			cluster.Status.Generations = fdbtypes.ClusterGenerationStatus{
+				Reconciled:        1,
+				NeedsBounce:       2,
+				HasPendingRemoval: 2,
+			}
+			cluster.Status.ProcessGroups[0].Remove = true
+			err := k8sClient.Status().Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should report the pending operations", func() {
+			report := getReport()
+			Expect(report.Clusters).To(HaveLen(1))
+			Expect(report.Clusters[0].PendingOperations).To(Equal([]string{"hasPendingRemoval", "needsBounce"}))
+			Expect(report.Clusters[0].ProcessGroupsToRemove).To(Equal(1))
+		})
+	})
+
+	Context("with another method than GET", func() {
+		BeforeEach(func() {
+			method = http.MethodPost
+		})
+
+		It("should reject the request", func() {
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+})
@@ -21,12 +21,17 @@
 package controllers
 
 import (
+	ctx "context"
 	"fmt"
 	"time"
 
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -40,6 +45,19 @@ const (
 	// podSchedulingDelayDuration determines how long we should delay a requeue
 	// of reconciliation when a pod is not ready.
 	podSchedulingDelayDuration = 15 * time.Second
+
+	// quotaExceededDelayDuration determines how long we should delay a
+	// requeue of reconciliation when a resource could not be created because
+	// of a resource quota.
+	quotaExceededDelayDuration = 2 * time.Minute
+
+	// observeOnlyRequeueInterval determines how often the status of a cluster
+	// that is only observed is refreshed.
+	observeOnlyRequeueInterval = time.Minute
+
+	// reconcileIDLength determines the length of the random ID that is added
+	// to the log lines of a reconciliation.
+	reconcileIDLength = 8
 )
 
 // metadataMatches determines if the current metadata on an object matches the
@@ -53,6 +71,9 @@ func metadataMatches(currentMetadata metav1.ObjectMeta, desiredMetadata metav1.O
 //
 // This will return whether the target's labels have changed.
 func mergeLabelsInMetadata(target *metav1.ObjectMeta, desired metav1.ObjectMeta) bool {
+	if target.Labels == nil && len(desired.Labels) > 0 {
+		target.Labels = make(map[string]string, len(desired.Labels))
+	}
 	return mergeMap(target.Labels, desired.Labels)
 }
 
@@ -61,6 +82,9 @@ func mergeLabelsInMetadata(target *metav1.ObjectMeta, desired metav1.ObjectMeta)
 //
 // This will return whether the target's annotations have changed.
 func mergeAnnotations(target *metav1.ObjectMeta, desired metav1.ObjectMeta) bool {
+	if target.Annotations == nil && len(desired.Annotations) > 0 {
+		target.Annotations = make(map[string]string, len(desired.Annotations))
+	}
 	return mergeMap(target.Annotations, desired.Annotations)
 }
 
@@ -118,3 +142,32 @@ func processRequeue(requeue *requeue, subReconciler interface{}, object runtime.
 
 	return ctrl.Result{Requeue: true, RequeueAfter: requeue.delay}, nil
 }
+
+// checkQuotaExceeded checks whether the creation of a resource failed because
+// of a resource quota or limit range in the namespace. In that case it
+// records an event, sets the QuotaExceeded condition on the cluster and
+// returns a requeue with a delay, so the operator does not retry the creation
+// in a tight loop. Otherwise it returns nil.
+func checkQuotaExceeded(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, resource string, err error) *requeue {
+	if !internal.IsQuotaExceeded(err) {
+		return nil
+	}
+
+	message := fmt.Sprintf("Failed to create %s: %s", resource, err.Error())
+	r.Recorder.Event(cluster, corev1.EventTypeWarning, "QuotaExceeded", message)
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               fdbtypes.ClusterConditionQuotaExceeded,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: cluster.ObjectMeta.Generation,
+		Reason:             "QuotaExceeded",
+		Message:            message,
+	})
+
+	statusErr := r.Status().Update(context, cluster)
+	if statusErr != nil {
+		return &requeue{curError: statusErr}
+	}
+
+	return &requeue{message: message, delay: quotaExceededDelayDuration}
+}
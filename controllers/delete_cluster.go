@@ -0,0 +1,177 @@
+/*
+ * delete_cluster.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// deleteCluster provides a reconciliation step for tearing down a cluster
+// that has been marked for deletion.
+type deleteCluster struct{}
+
+// reconcile runs the reconciler's work.
+func (d deleteCluster) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	if !controllerutil.ContainsFinalizer(cluster, fdbtypes.ClusterFinalizer) {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "deleteCluster")
+
+	if cluster.ProtectDataOnDeletion() && !cluster.IsDestructiveDeleteConfirmed() {
+		hasData, err := clusterHasData(r, context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if hasData {
+			message := fmt.Sprintf("Cluster still has data, set the %s annotation to \"true\" to delete it", fdbtypes.ConfirmDestructiveDeleteAnnotation)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "DeletionBlocked", message)
+			return &requeue{message: message, delay: time.Minute}
+		}
+	}
+
+	// The pods are deleted first so that no process is running while its
+	// services and volumes go away, and the ConfigMap is deleted last so
+	// that it is available until the last process is gone.
+	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if len(pods) > 0 {
+		logger.Info("Deleting pods", "count", len(pods))
+		for _, pod := range pods {
+			if pod.ObjectMeta.DeletionTimestamp != nil {
+				continue
+			}
+
+			err = r.PodLifecycleManager.DeletePod(r, context, pod)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+
+		return &requeue{message: "Waiting for pods to be deleted", delay: 15 * time.Second}
+	}
+
+	resourceLists := []struct {
+		list   client.ObjectList
+		retain bool
+	}{
+		{&corev1.ServiceList{}, cluster.GetServiceRetentionPolicy().RetainsResourceOnDeletion()},
+		{&corev1.PersistentVolumeClaimList{}, cluster.GetPVCRetentionPolicy().RetainsResourceOnDeletion()},
+		{&corev1.ConfigMapList{}, false},
+	}
+
+	for _, resourceList := range resourceLists {
+		remaining, err := deleteClusterResources(r, context, cluster, resourceList.list, resourceList.retain)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if remaining > 0 {
+			logger.Info("Deleting resources", "kind", fmt.Sprintf("%T", resourceList.list), "count", remaining)
+			return &requeue{message: "Waiting for resources to be deleted", delay: 15 * time.Second}
+		}
+	}
+
+	logger.Info("Removing finalizer from cluster")
+	err = internal.UpdateFinalizer(r, context, cluster, fdbtypes.ClusterFinalizer, false)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
+
+// clusterHasData determines whether the database for a cluster still holds
+// any data.
+func clusterHasData(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (bool, error) {
+	if !cluster.Status.Configured {
+		return false, nil
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return false, err
+	}
+	defer adminClient.Close()
+
+	status, err := adminClient.GetStatus(context)
+	if err != nil {
+		return false, err
+	}
+
+	return status.Cluster.Data.KVBytes > 0, nil
+}
+
+// deleteClusterResources deletes all resources in a list kind that belong to
+// a cluster, and returns the number of resources that still exist. If retain
+// is true, the resources are released from the cluster instead of deleted.
+func deleteClusterResources(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, resourceList client.ObjectList, retain bool) (int, error) {
+	err := r.List(context, resourceList, internal.GetPodListOptions(cluster, "", "")...)
+	if err != nil {
+		return 0, err
+	}
+
+	resources, err := meta.ExtractList(resourceList)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := len(resources)
+	for _, resource := range resources {
+		resourceObject, ok := resource.(client.Object)
+		if !ok {
+			return 0, fmt.Errorf("unexpected resource type %T", resource)
+		}
+
+		if resourceObject.GetDeletionTimestamp() != nil {
+			continue
+		}
+
+		if retain {
+			err = retainResource(r, context, cluster, resourceObject)
+			if err != nil {
+				return 0, err
+			}
+			remaining--
+			continue
+		}
+
+		err = r.Delete(context, resourceObject)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return remaining, nil
+}
@@ -0,0 +1,253 @@
+/*
+ * delete_cluster_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("deleteCluster", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var result ctrl.Result
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+	})
+
+	JustBeforeEach(func() {
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	countResources := func() (int, int, int) {
+		pods := &corev1.PodList{}
+		err := k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		err = k8sClient.List(context.TODO(), pvcs, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+
+		configMaps := &corev1.ConfigMapList{}
+		err = k8sClient.List(context.TODO(), configMaps, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+
+		return len(pods.Items), len(pvcs.Items), len(configMaps.Items)
+	}
+
+	deleteAndReconcile := func() {
+		err = k8sClient.Delete(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err = reconcileCluster(cluster)
+	}
+
+	clusterExists := func() bool {
+		err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}, &fdbtypes.FoundationDBCluster{})
+		if k8serrors.IsNotFound(err) {
+			return false
+		}
+		Expect(err).NotTo(HaveOccurred())
+		return true
+	}
+
+	When("graceful deletion is disabled", func() {
+		It("should not add the finalizer", func() {
+			Expect(cluster.ObjectMeta.Finalizers).To(BeEmpty())
+		})
+	})
+
+	When("graceful deletion is enabled", func() {
+		BeforeEach(func() {
+			cluster.Spec.DeletionOptions.Graceful = pointer.Bool(true)
+		})
+
+		It("should add the finalizer", func() {
+			Expect(cluster.ObjectMeta.Finalizers).To(ConsistOf(fdbtypes.ClusterFinalizer))
+		})
+
+		When("graceful deletion is disabled again", func() {
+			JustBeforeEach(func() {
+				cluster.Spec.DeletionOptions.Graceful = pointer.Bool(false)
+				err = k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = reconcileCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = reloadClusterGenerations(cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should remove the finalizer", func() {
+				Expect(cluster.ObjectMeta.Finalizers).To(BeEmpty())
+			})
+		})
+
+		When("another controller has added a finalizer", func() {
+			BeforeEach(func() {
+				cluster.ObjectMeta.Finalizers = []string{"test/finalizer"}
+			})
+
+			It("should keep the other finalizer", func() {
+				Expect(cluster.ObjectMeta.Finalizers).To(ConsistOf("test/finalizer", fdbtypes.ClusterFinalizer))
+			})
+		})
+
+		When("the cluster is deleted", func() {
+			JustBeforeEach(func() {
+				deleteAndReconcile()
+			})
+
+			It("should delete the cluster and its resources", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Requeue).To(BeFalse())
+				Expect(clusterExists()).To(BeFalse())
+
+				pods, pvcs, configMaps := countResources()
+				Expect(pods).To(Equal(0))
+				Expect(pvcs).To(Equal(0))
+				Expect(configMaps).To(Equal(0))
+			})
+		})
+	})
+
+	When("the PVCs are retained", func() {
+		BeforeEach(func() {
+			policy := fdbtypes.ResourceRetentionPolicyRetain
+			cluster.Spec.DeletionOptions.PVCRetentionPolicy = &policy
+		})
+
+		It("should add the finalizer", func() {
+			Expect(cluster.ObjectMeta.Finalizers).To(ConsistOf(fdbtypes.ClusterFinalizer))
+		})
+
+		When("the cluster is deleted", func() {
+			JustBeforeEach(func() {
+				deleteAndReconcile()
+			})
+
+			It("should delete the cluster and retain the PVCs", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(clusterExists()).To(BeFalse())
+
+				pods, pvcs, configMaps := countResources()
+				Expect(pods).To(Equal(0))
+				Expect(pvcs).To(Equal(0))
+				Expect(configMaps).To(Equal(0))
+
+				retainedPVCs := &corev1.PersistentVolumeClaimList{}
+				err = k8sClient.List(context.TODO(), retainedPVCs, client.InNamespace(cluster.Namespace), client.MatchingLabels{fdbtypes.RetainedFromClusterLabel: cluster.Name})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(retainedPVCs.Items).To(HaveLen(8))
+				for _, pvc := range retainedPVCs.Items {
+					Expect(pvc.OwnerReferences).To(BeEmpty())
+				}
+			})
+		})
+	})
+
+	When("data protection is enabled", func() {
+		var adminClient *mockAdminClient
+
+		BeforeEach(func() {
+			cluster.Spec.DeletionOptions.ProtectData = pointer.Bool(true)
+		})
+
+		JustBeforeEach(func() {
+			adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should add the finalizer", func() {
+			Expect(cluster.ObjectMeta.Finalizers).To(ConsistOf(fdbtypes.ClusterFinalizer))
+		})
+
+		When("the cluster has no data", func() {
+			JustBeforeEach(func() {
+				deleteAndReconcile()
+			})
+
+			It("should delete the cluster", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(clusterExists()).To(BeFalse())
+			})
+		})
+
+		When("the cluster still has data", func() {
+			JustBeforeEach(func() {
+				adminClient.MockKVBytes(1024)
+				deleteAndReconcile()
+			})
+
+			It("should block the deletion", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(time.Minute))
+				Expect(clusterExists()).To(BeTrue())
+
+				pods, pvcs, configMaps := countResources()
+				Expect(pods).To(Equal(17))
+				Expect(pvcs).To(Equal(8))
+				Expect(configMaps).To(Equal(1))
+			})
+
+			When("the destructive delete is confirmed", func() {
+				JustBeforeEach(func() {
+					_, err = reloadClusterGenerations(cluster)
+					Expect(err).NotTo(HaveOccurred())
+
+					cluster.Annotations = map[string]string{
+						fdbtypes.ConfirmDestructiveDeleteAnnotation: "true",
+					}
+					err = k8sClient.Update(context.TODO(), cluster)
+					Expect(err).NotTo(HaveOccurred())
+
+					result, err = reconcileCluster(cluster)
+				})
+
+				It("should delete the cluster and its resources", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(clusterExists()).To(BeFalse())
+
+					pods, pvcs, configMaps := countResources()
+					Expect(pods).To(Equal(0))
+					Expect(pvcs).To(Equal(0))
+					Expect(configMaps).To(Equal(0))
+				})
+			})
+		})
+	})
+})
@@ -36,7 +36,7 @@ type deletePodsForBuggification struct{}
 
 // reconcile runs the reconciler's work.
 func (d deletePodsForBuggification) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "deletePodsForBuggification")
+	logger := getLogger(context, cluster, "deletePodsForBuggification")
 	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
 	if err != nil {
 		return &requeue{curError: err}
@@ -0,0 +1,162 @@
+/*
+ * dr_controller.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	"golang.org/x/net/context"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FoundationDBDRReconciler reconciles a FoundationDBDR object
+type FoundationDBDRReconciler struct {
+	client.Client
+	Recorder               record.EventRecorder
+	Log                    logr.Logger
+	InSimulation           bool
+	DatabaseClientProvider DatabaseClientProvider
+}
+
+// +kubebuilder:rbac:groups=apps.foundationdb.org,resources=foundationdbdrs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps.foundationdb.org,resources=foundationdbdrs/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile runs the reconciliation logic.
+func (r *FoundationDBDRReconciler) Reconcile(ctx context.Context, request ctrl.Request) (ctrl.Result, error) {
+	dr := &fdbtypes.FoundationDBDR{}
+
+	err := r.Get(ctx, request.NamespacedName, dr)
+
+	originalGeneration := dr.ObjectMeta.Generation
+
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// Object not found, return.  Created objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers.
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	drLog := log.WithValues("namespace", dr.Namespace, "dr", dr.Name)
+
+	subReconcilers := []drSubReconciler{
+		updateDRStatus{},
+		switchDR{},
+		updateDRAgents{},
+		startDR{},
+		updateDRStatus{},
+	}
+
+	for _, subReconciler := range subReconcilers {
+		requeue := subReconciler.reconcile(r, ctx, dr)
+		if requeue == nil {
+			continue
+		}
+
+		return processRequeue(requeue, subReconciler, dr, r.Recorder, drLog)
+	}
+
+	if dr.Status.Generations.Reconciled < originalGeneration {
+		drLog.Info("DR was not fully reconciled by reconciliation process")
+		return ctrl.Result{Requeue: true}, nil
+	}
+
+	drLog.Info("Reconciliation complete")
+
+	return ctrl.Result{}, nil
+}
+
+// getDatabaseClientProvider gets the client provider for a reconciler.
+func (r *FoundationDBDRReconciler) getDatabaseClientProvider() DatabaseClientProvider {
+	if r.DatabaseClientProvider != nil {
+		return r.DatabaseClientProvider
+	}
+	panic("DR reconciler does not have a DatabaseClientProvider defined")
+}
+
+// adminClientForDR provides an admin client for the destination cluster of a
+// DR replication, along with the connection string of the source cluster.
+func (r *FoundationDBDRReconciler) adminClientForDR(context ctx.Context, dr *fdbtypes.FoundationDBDR, sourceClusterName string, destinationClusterName string) (fdbadminclient.AdminClient, string, error) {
+	sourceCluster := &fdbtypes.FoundationDBCluster{}
+	err := r.Get(context, types.NamespacedName{Namespace: dr.ObjectMeta.Namespace, Name: sourceClusterName}, sourceCluster)
+	if err != nil {
+		return nil, "", err
+	}
+
+	destinationCluster := &fdbtypes.FoundationDBCluster{}
+	err = r.Get(context, types.NamespacedName{Namespace: dr.ObjectMeta.Namespace, Name: destinationClusterName}, destinationCluster)
+	if err != nil {
+		return nil, "", err
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(destinationCluster, r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return adminClient, sourceCluster.Status.ConnectionString, nil
+}
+
+// SetupWithManager prepares a reconciler for use.
+func (r *FoundationDBDRReconciler) SetupWithManager(mgr ctrl.Manager, maxConcurrentReconciles int) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: maxConcurrentReconciles},
+		).
+		For(&fdbtypes.FoundationDBDR{}).
+		Owns(&appsv1.Deployment{}).
+		// Only react on generation changes or annotation changes
+		WithEventFilter(predicate.Or(predicate.GenerationChangedPredicate{}, predicate.AnnotationChangedPredicate{})).
+		Complete(r)
+}
+
+// drSubReconciler describes a class that does part of the work of
+// reconciliation for a DR replication.
+type drSubReconciler interface {
+	/**
+	reconcile runs the reconciler's work.
+
+	If reconciliation can continue, this should return nil.
+
+	If reconciliation encounters an error, this should return a requeue object
+	with an `Error` field.
+
+	If reconciliation cannot proceed, this should return a requeue object with a
+	`Message` field.
+	*/
+	reconcile(r *FoundationDBDRReconciler, context ctx.Context, dr *fdbtypes.FoundationDBDR) *requeue
+}
@@ -0,0 +1,141 @@
+/*
+ * dr_controller_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"fmt"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"golang.org/x/net/context"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func reloadDR(dr *fdbtypes.FoundationDBDR) (int64, error) {
+	err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: dr.Namespace, Name: dr.Name}, dr)
+	if err != nil {
+		return 0, err
+	}
+	return dr.Status.Generations.Reconciled, err
+}
+
+var _ = Describe("dr_controller", func() {
+	var source *fdbtypes.FoundationDBCluster
+	var destination *fdbtypes.FoundationDBCluster
+	var dr *fdbtypes.FoundationDBDR
+	var sourceAdminClient *mockAdminClient
+	var destinationAdminClient *mockAdminClient
+	var err error
+
+	BeforeEach(func() {
+		source = internal.CreateDefaultCluster()
+		err = setupClusterForTest(source)
+		Expect(err).NotTo(HaveOccurred())
+
+		destination = internal.CreateDefaultCluster()
+		destination.Name = "operator-test-2"
+		err = setupClusterForTest(destination)
+		Expect(err).NotTo(HaveOccurred())
+
+		sourceAdminClient, err = newMockAdminClientUncast(source, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+		destinationAdminClient, err = newMockAdminClientUncast(destination, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		dr = internal.CreateDefaultDR(source, destination)
+		err = k8sClient.Create(context.TODO(), dr)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileDR(dr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadDR(dr)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(dr.ObjectMeta.Generation))
+	})
+
+	Context("when reconciling a new DR", func() {
+		It("should create the DR deployment", func() {
+			deployment := &appsv1.Deployment{}
+			deploymentName := fmt.Sprintf("%s-dr-agents", dr.Name)
+
+			err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: dr.Namespace, Name: deploymentName}, deployment)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+			Expect(deployment.Spec.Template.Spec.Containers[0].Command).To(Equal([]string{"dr_agent"}))
+		})
+
+		It("should start the DR", func() {
+			status, err := destinationAdminClient.GetDRStatus(context.TODO(), source.Status.ConnectionString, "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Running).To(BeTrue())
+		})
+
+		It("should update the status on the resource", func() {
+			Expect(dr.Status.AgentCount).To(Equal(3))
+			Expect(dr.Status.DeploymentConfigured).To(BeTrue())
+			Expect(dr.Status.DRDetails).NotTo(BeNil())
+			Expect(dr.Status.DRDetails.SourceClusterName).To(Equal(source.Name))
+			Expect(dr.Status.DRDetails.DestinationClusterName).To(Equal(destination.Name))
+			Expect(dr.Status.DRDetails.Running).To(BeTrue())
+			Expect(dr.Status.DRDetails.CompleteCopy).To(BeTrue())
+		})
+	})
+
+	Context("when swapping the source and the destination", func() {
+		BeforeEach(func() {
+			dr.Spec.SourceClusterName, dr.Spec.DestinationClusterName = dr.Spec.DestinationClusterName, dr.Spec.SourceClusterName
+			err = k8sClient.Update(context.TODO(), dr)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := reconcileDR(dr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+
+			generation, err := reloadDR(dr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(generation).To(Equal(dr.ObjectMeta.Generation))
+		})
+
+		It("should switch the DR to the other cluster", func() {
+			status, err := sourceAdminClient.GetDRStatus(context.TODO(), destination.Status.ConnectionString, "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Running).To(BeTrue())
+
+			status, err = destinationAdminClient.GetDRStatus(context.TODO(), source.Status.ConnectionString, "default")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.Running).To(BeFalse())
+		})
+
+		It("should update the status on the resource", func() {
+			Expect(dr.Status.DRDetails).NotTo(BeNil())
+			Expect(dr.Status.DRDetails.SourceClusterName).To(Equal(destination.Name))
+			Expect(dr.Status.DRDetails.DestinationClusterName).To(Equal(source.Name))
+			Expect(dr.Status.DRDetails.Running).To(BeTrue())
+		})
+	})
+})
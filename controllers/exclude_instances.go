@@ -24,12 +24,13 @@ import (
 	ctx "context"
 	"fmt"
 	"math"
-	"net"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
 )
 
 // The fraction of processes that must be present in order to start a new
@@ -48,6 +49,25 @@ func (e excludeInstances) reconcile(r *FoundationDBClusterReconciler, context ct
 	}
 	defer adminClient.Close()
 
+	hasStatusUpdate, err := checkStuckExclusions(r, context, cluster, adminClient)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if hasStatusUpdate {
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if cluster.Status.PendingExclusion != nil {
+		req := resumePendingExclusion(r, context, cluster, adminClient)
+		if req != nil {
+			return req
+		}
+	}
+
 	removalCount := 0
 	for _, processGroup := range cluster.Status.ProcessGroups {
 		if processGroup.Remove {
@@ -57,8 +77,10 @@ func (e excludeInstances) reconcile(r *FoundationDBClusterReconciler, context ct
 
 	addresses := make([]fdbtypes.ProcessAddress, 0, removalCount)
 	processClassesToExclude := make(map[fdbtypes.ProcessClass]internal.None)
+	processGroupsToExclude := make([]*fdbtypes.ProcessGroupStatus, 0, removalCount)
+	hasDeferredExclusions := false
 	if removalCount > 0 {
-		exclusions, err := adminClient.GetExclusions()
+		exclusions, err := adminClient.GetExclusions(context)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -68,10 +90,43 @@ func (e excludeInstances) reconcile(r *FoundationDBClusterReconciler, context ct
 			currentExclusionMap[address.String()] = true
 		}
 
+		// Exclusions that were started before the operator was restarted
+		// may not have been recorded in the status yet.
+		hasRecoveredExclusions, err := recoverExclusionTimestamps(cluster, currentExclusionMap)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if hasRecoveredExclusions {
+			err = r.Status().Update(context, cluster)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+
+		availableExclusions := getAvailableExclusionCount(cluster)
 		for _, processGroup := range cluster.Status.ProcessGroups {
-			for _, address := range processGroup.Addresses {
-				if processGroup.Remove && !processGroup.ExclusionSkipped && !currentExclusionMap[address] {
-					addresses = append(addresses, fdbtypes.ProcessAddress{IPAddress: net.ParseIP(address)})
+			if !processGroup.Remove || processGroup.ExclusionSkipped || isWaitingForExclusionRetry(cluster, processGroup) {
+				continue
+			}
+
+			if processGroup.ExclusionTimestamp == 0 && len(processGroup.Addresses) > 0 && availableExclusions >= 0 {
+				if availableExclusions == 0 {
+					hasDeferredExclusions = true
+					continue
+				}
+				availableExclusions--
+			}
+
+			processGroupsToExclude = append(processGroupsToExclude, processGroup)
+			processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			for _, address := range processGroupAddresses {
+				if !currentExclusionMap[address.String()] {
+					addresses = append(addresses, address)
 					processClassesToExclude[processGroup.ProcessClass] = internal.None{}
 				}
 			}
@@ -80,30 +135,350 @@ func (e excludeInstances) reconcile(r *FoundationDBClusterReconciler, context ct
 
 	if len(addresses) > 0 {
 		for processClass := range processClassesToExclude {
-			canExclude, missingProcesses := canExcludeNewInstances(cluster, processClass)
+			canExclude, missingProcesses := canExcludeNewInstances(context, cluster, processClass)
 			if !canExclude {
 				return &requeue{message: fmt.Sprintf("Waiting for missing processes: %v. Addresses to exclude: %v", missingProcesses, addresses)}
 			}
 		}
 
-		hasLock, err := r.takeLock(cluster, fmt.Sprintf("excluding instances: %v", addresses))
+		if cluster.Spec.AutomationOptions.MinimumFreeSpacePercentage != nil {
+			status, err := adminClient.GetStatus(context)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			for processClass := range processClassesToExclude {
+				if !processClass.IsStateful() {
+					continue
+				}
+
+				freeSpacePercentage := getFreeSpacePercentageAfterExclusion(status, processClass, addresses)
+				if freeSpacePercentage < float64(*cluster.Spec.AutomationOptions.MinimumFreeSpacePercentage) {
+					r.Recorder.Event(cluster, corev1.EventTypeWarning, "InsufficientFreeSpace",
+						fmt.Sprintf("Excluding %v would leave %.1f%% free space on the remaining %s processes", addresses, freeSpacePercentage, processClass))
+					return &requeue{message: fmt.Sprintf("Waiting for sufficient free space on the %s processes. Addresses to exclude: %v", processClass, addresses)}
+				}
+			}
+		}
+
+		hasLock, err := r.takeLock(context, cluster, fmt.Sprintf("excluding instances: %v", addresses))
 		if !hasLock {
 			return &requeue{curError: err}
 		}
 
+		// Record the exclusion before issuing it, so that an operator restart
+		// in between cannot lose track of the exclusion. If the exclusion
+		// fails, the next reconciliation issues it again.
+		exclusionTimestamp := time.Now().Unix()
+		for _, processGroup := range processGroupsToExclude {
+			if processGroup.ExclusionTimestamp != 0 || len(processGroup.Addresses) == 0 {
+				continue
+			}
+
+			processGroup.ExclusionTimestamp = exclusionTimestamp
+			processGroup.UpdateCondition(fdbtypes.ExclusionStuck, false, nil, "")
+		}
+
+		cluster.Status.PendingExclusion = fdbtypes.NewPendingProcessOperation(addresses)
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
 		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ExcludingProcesses", fmt.Sprintf("Excluding %v", addresses))
 
-		err = adminClient.ExcludeInstances(addresses)
+		err = adminClient.ExcludeInstances(context, addresses)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		cluster.Status.PendingExclusion = nil
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	// The remaining process groups are excluded once the current batch has
+	// been removed, so the other subreconcilers can still run.
+	if hasDeferredExclusions {
+		return &requeue{message: "Waiting for exclusions to complete before excluding more process groups", delayedRequeue: true}
+	}
+
+	return nil
+}
+
+// resumePendingExclusion issues the exclusion that was recorded in the status
+// before the operator was restarted. This only excludes the addresses that
+// still belong to process groups that are marked for removal, and clears the
+// pending exclusion once the command succeeded. Like any other exclusion, this
+// requires a lock.
+func resumePendingExclusion(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, adminClient fdbadminclient.AdminClient) *requeue {
+	logger := getLogger(context, cluster, "excludeInstances")
+
+	removalAddresses := make(map[string]bool)
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if !processGroup.Remove || processGroup.ExclusionSkipped {
+			continue
+		}
+
+		processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		for _, address := range processGroupAddresses {
+			removalAddresses[address.String()] = true
+		}
+	}
+
+	pendingAddresses, err := cluster.Status.PendingExclusion.GetProcessAddresses()
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	addresses := make([]fdbtypes.ProcessAddress, 0, len(pendingAddresses))
+	for _, address := range pendingAddresses {
+		if removalAddresses[address.String()] {
+			addresses = append(addresses, address)
+		}
+	}
+
+	if len(addresses) > 0 {
+		hasLock, err := r.takeLock(context, cluster, fmt.Sprintf("excluding instances: %v", addresses))
+		if !hasLock {
+			return &requeue{curError: err}
+		}
+
+		logger.Info("Resuming pending exclusion", "addresses", addresses)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ExcludingProcesses", fmt.Sprintf("Resuming exclusion of %v", addresses))
+
+		err = adminClient.ExcludeInstances(context, addresses)
 		if err != nil {
 			return &requeue{curError: err}
 		}
 	}
 
+	cluster.Status.PendingExclusion = nil
+	err = r.Status().Update(context, cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
 	return nil
 }
 
-func canExcludeNewInstances(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) (bool, []string) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "excludeInstances")
+// recoverExclusionTimestamps sets the exclusion timestamp for process groups
+// whose addresses are all excluded in the database but that have no
+// exclusion timestamp, so that they count against the in-progress
+// exclusions. This returns whether the status of any process group was
+// changed.
+func recoverExclusionTimestamps(cluster *fdbtypes.FoundationDBCluster, currentExclusionMap map[string]bool) (bool, error) {
+	hasStatusUpdate := false
+	exclusionTimestamp := time.Now().Unix()
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if !processGroup.Remove || processGroup.ExclusionSkipped || processGroup.ExclusionTimestamp != 0 || len(processGroup.Addresses) == 0 {
+			continue
+		}
+
+		processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+		if err != nil {
+			return false, err
+		}
+
+		excluded := true
+		for _, address := range processGroupAddresses {
+			if !currentExclusionMap[address.String()] {
+				excluded = false
+				break
+			}
+		}
+
+		if !excluded {
+			continue
+		}
+
+		processGroup.ExclusionTimestamp = exclusionTimestamp
+		hasStatusUpdate = true
+	}
+
+	return hasStatusUpdate, nil
+}
+
+// getAvailableExclusionCount returns the number of process groups that can
+// be excluded in addition to the exclusions that are in progress, or -1 if
+// the number of exclusions is not limited.
+func getAvailableExclusionCount(cluster *fdbtypes.FoundationDBCluster) int {
+	maxExclusions := cluster.GetMaxConcurrentExclusions()
+	if maxExclusions == 0 {
+		return -1
+	}
+
+	inProgress := 0
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove && !processGroup.ExclusionSkipped && processGroup.ExclusionTimestamp != 0 {
+			inProgress++
+		}
+	}
+
+	if inProgress >= maxExclusions {
+		return 0
+	}
+
+	return maxExclusions - inProgress
+}
+
+// getFreeSpacePercentageAfterExclusion estimates the percentage of the disk
+// space of the remaining processes of a process class that is free once the
+// data of the excluded processes has been moved to them. Processes that are
+// already excluded are treated like the processes that will be excluded.
+func getFreeSpacePercentageAfterExclusion(status *fdbtypes.FoundationDBStatus, processClass fdbtypes.ProcessClass, addresses []fdbtypes.ProcessAddress) float64 {
+	excludedIPs := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		excludedIPs[address.IPAddress.String()] = true
+	}
+
+	var usedBytes, remainingFreeBytes, remainingTotalBytes int64
+	for _, process := range status.Cluster.Processes {
+		if process.ProcessClass != processClass {
+			continue
+		}
+
+		if process.Excluded || excludedIPs[process.Address.IPAddress.String()] {
+			usedBytes += process.Disk.TotalBytes - process.Disk.FreeBytes
+			continue
+		}
+
+		remainingFreeBytes += process.Disk.FreeBytes
+		remainingTotalBytes += process.Disk.TotalBytes
+	}
+
+	// Without any disk information we cannot estimate the free space.
+	if remainingTotalBytes == 0 {
+		return 100
+	}
+
+	return 100 * float64(remainingFreeBytes-usedBytes) / float64(remainingTotalBytes)
+}
+
+// isWaitingForExclusionRetry determines whether the exclusion of a process
+// group has been rolled back, and the operator should wait before excluding
+// it again.
+func isWaitingForExclusionRetry(cluster *fdbtypes.FoundationDBCluster, processGroup *fdbtypes.ProcessGroupStatus) bool {
+	if processGroup.ExclusionTimestamp != 0 {
+		return false
+	}
+
+	stuckTime := processGroup.GetConditionTime(fdbtypes.ExclusionStuck)
+	if stuckTime == nil {
+		return false
+	}
+
+	return time.Since(time.Unix(*stuckTime, 0)) < cluster.GetExclusionTimeout()
+}
+
+// checkStuckExclusions marks the process groups whose exclusion has not
+// completed within the exclusion timeout with the ExclusionStuck condition,
+// and rolls back their exclusion if this is enabled.
+// This returns whether the status of any process group was changed.
+func checkStuckExclusions(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, adminClient fdbadminclient.AdminClient) (bool, error) {
+	logger := getLogger(context, cluster, "excludeInstances")
+	exclusionTimeout := cluster.GetExclusionTimeout()
+
+	candidates := make([]*fdbtypes.ProcessGroupStatus, 0)
+	addresses := make([]fdbtypes.ProcessAddress, 0)
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if !processGroup.Remove || processGroup.ExclusionSkipped || processGroup.ExclusionTimestamp == 0 {
+			continue
+		}
+
+		if time.Since(time.Unix(processGroup.ExclusionTimestamp, 0)) < exclusionTimeout {
+			continue
+		}
+
+		processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+		if err != nil {
+			return false, err
+		}
+
+		candidates = append(candidates, processGroup)
+		addresses = append(addresses, processGroupAddresses...)
+	}
+
+	if len(addresses) == 0 {
+		return false, nil
+	}
+
+	remaining, err := adminClient.CanSafelyRemove(context, addresses)
+	if err != nil {
+		return false, err
+	}
+
+	remainingMap := make(map[string]bool, len(remaining))
+	for _, address := range remaining {
+		remainingMap[address.String()] = true
+	}
+
+	hasStatusUpdate := false
+	rollbackAddresses := make([]fdbtypes.ProcessAddress, 0)
+	for _, processGroup := range candidates {
+		stuck := false
+		for _, address := range processGroup.Addresses {
+			if remainingMap[address] {
+				stuck = true
+				break
+			}
+		}
+
+		if !stuck {
+			if processGroup.GetConditionTime(fdbtypes.ExclusionStuck) != nil {
+				processGroup.UpdateCondition(fdbtypes.ExclusionStuck, false, nil, "")
+				hasStatusUpdate = true
+			}
+			continue
+		}
+
+		if processGroup.GetConditionTime(fdbtypes.ExclusionStuck) == nil {
+			logger.Info("Exclusion is stuck", "processGroupID", processGroup.ProcessGroupID, "addresses", processGroup.Addresses)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "ExclusionStuck",
+				fmt.Sprintf("Exclusion of process group %s has not completed after %s", processGroup.ProcessGroupID, exclusionTimeout))
+			processGroup.UpdateCondition(fdbtypes.ExclusionStuck, true, nil, processGroup.ProcessGroupID)
+			hasStatusUpdate = true
+		}
+
+		if !cluster.GetRollbackStuckExclusions() {
+			continue
+		}
+
+		processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+		if err != nil {
+			return false, err
+		}
+		rollbackAddresses = append(rollbackAddresses, processGroupAddresses...)
+
+		// Reset the condition so that its timestamp tells us when the
+		// exclusion was rolled back.
+		processGroup.UpdateCondition(fdbtypes.ExclusionStuck, false, nil, "")
+		processGroup.UpdateCondition(fdbtypes.ExclusionStuck, true, nil, processGroup.ProcessGroupID)
+		processGroup.ExclusionTimestamp = 0
+		hasStatusUpdate = true
+	}
+
+	if len(rollbackAddresses) > 0 {
+		logger.Info("Rolling back stuck exclusions", "addresses", rollbackAddresses)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "RollingBackExclusion", fmt.Sprintf("Including %v again", rollbackAddresses))
+
+		err = adminClient.IncludeInstances(context, rollbackAddresses)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return hasStatusUpdate, nil
+}
+
+func canExcludeNewInstances(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) (bool, []string) {
+	logger := getLogger(context, cluster, "excludeInstances")
 
 	// Block excludes on missing processes not marked for removal
 	missingProcesses := make([]string, 0)
@@ -22,12 +22,15 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("exclude_instances", func() {
@@ -52,7 +55,7 @@ var _ = Describe("exclude_instances", func() {
 		Context("with a small cluster", func() {
 			When("all processes are healthy", func() {
 				It("should allow the exclusion", func() {
-					canExclude, missing := canExcludeNewInstances(cluster, fdbtypes.ProcessClassStorage)
+					canExclude, missing := canExcludeNewInstances(context.TODO(), cluster, fdbtypes.ProcessClassStorage)
 					Expect(canExclude).To(BeTrue())
 					Expect(missing).To(BeNil())
 				})
@@ -64,7 +67,7 @@ var _ = Describe("exclude_instances", func() {
 				})
 
 				It("should allow the exclusion", func() {
-					canExclude, missing := canExcludeNewInstances(cluster, fdbtypes.ProcessClassStorage)
+					canExclude, missing := canExcludeNewInstances(context.TODO(), cluster, fdbtypes.ProcessClassStorage)
 					Expect(canExclude).To(BeTrue())
 					Expect(missing).To(BeNil())
 				})
@@ -76,7 +79,7 @@ var _ = Describe("exclude_instances", func() {
 				})
 
 				It("should not allow the exclusion", func() {
-					canExclude, missing := canExcludeNewInstances(cluster, fdbtypes.ProcessClassStorage)
+					canExclude, missing := canExcludeNewInstances(context.TODO(), cluster, fdbtypes.ProcessClassStorage)
 					Expect(canExclude).To(BeFalse())
 					Expect(missing).To(Equal([]string{"storage-1", "storage-2"}))
 				})
@@ -88,7 +91,7 @@ var _ = Describe("exclude_instances", func() {
 				})
 
 				It("should allow the exclusion", func() {
-					canExclude, missing := canExcludeNewInstances(cluster, fdbtypes.ProcessClassStorage)
+					canExclude, missing := canExcludeNewInstances(context.TODO(), cluster, fdbtypes.ProcessClassStorage)
 					Expect(canExclude).To(BeTrue())
 					Expect(missing).To(BeNil())
 				})
@@ -115,7 +118,7 @@ var _ = Describe("exclude_instances", func() {
 				})
 
 				It("should allow the exclusion", func() {
-					canExclude, missing := canExcludeNewInstances(cluster, fdbtypes.ProcessClassStorage)
+					canExclude, missing := canExcludeNewInstances(context.TODO(), cluster, fdbtypes.ProcessClassStorage)
 					Expect(canExclude).To(BeTrue())
 					Expect(missing).To(BeNil())
 				})
@@ -127,13 +130,302 @@ var _ = Describe("exclude_instances", func() {
 				})
 
 				It("should not allow the exclusion", func() {
-					canExclude, missing := canExcludeNewInstances(cluster, fdbtypes.ProcessClassStorage)
+					canExclude, missing := canExcludeNewInstances(context.TODO(), cluster, fdbtypes.ProcessClassStorage)
 					Expect(canExclude).To(BeFalse())
 					Expect(missing).To(Equal([]string{"storage-1", "storage-10", "storage-11", "storage-12", "storage-13"}))
 				})
 			})
 		})
 	})
+
+	Describe("stuck exclusions", func() {
+		var adminClient *mockAdminClient
+		var processGroup *fdbtypes.ProcessGroupStatus
+		var requeue *requeue
+
+		BeforeEach(func() {
+			adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, status := range cluster.Status.ProcessGroups {
+				if status.ProcessGroupID == "storage-1" {
+					processGroup = status
+				}
+			}
+			Expect(processGroup).NotTo(BeNil())
+			processGroup.Remove = true
+
+			requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should record when the exclusion started", func() {
+			Expect(adminClient.ExcludedAddresses).To(ConsistOf(processGroup.Addresses))
+			Expect(processGroup.ExclusionTimestamp).NotTo(BeZero())
+			Expect(processGroup.GetConditionTime(fdbtypes.ExclusionStuck)).To(BeNil())
+		})
+
+		When("the exclusion runs longer than the timeout", func() {
+			BeforeEach(func() {
+				processGroup.ExclusionTimestamp = time.Now().Add(-2 * time.Hour).Unix()
+			})
+
+			JustBeforeEach(func() {
+				requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			When("the exclusion is complete", func() {
+				It("should not mark the exclusion as stuck", func() {
+					Expect(requeue).To(BeNil())
+					Expect(processGroup.GetConditionTime(fdbtypes.ExclusionStuck)).To(BeNil())
+				})
+			})
+
+			When("the exclusion is stuck", func() {
+				BeforeEach(func() {
+					adminClient.MockStuckExclusions(true)
+				})
+
+				It("should mark the exclusion as stuck", func() {
+					Expect(requeue).To(BeNil())
+					Expect(processGroup.GetConditionTime(fdbtypes.ExclusionStuck)).NotTo(BeNil())
+					Expect(processGroup.ExclusionTimestamp).NotTo(BeZero())
+					Expect(adminClient.ExcludedAddresses).To(ConsistOf(processGroup.Addresses))
+				})
+
+				It("should persist the condition", func() {
+					_, err = reloadCluster(cluster)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.ExclusionStuck, false)).To(Equal([]string{"storage-1"}))
+				})
+
+				When("rolling back stuck exclusions is enabled", func() {
+					BeforeEach(func() {
+						cluster.Spec.AutomationOptions.RollbackStuckExclusions = pointer.Bool(true)
+					})
+
+					It("should include the processes again", func() {
+						Expect(requeue).To(BeNil())
+						Expect(processGroup.GetConditionTime(fdbtypes.ExclusionStuck)).NotTo(BeNil())
+						Expect(processGroup.ExclusionTimestamp).To(BeZero())
+						Expect(adminClient.ExcludedAddresses).To(BeEmpty())
+						Expect(adminClient.ReincludedAddresses).To(HaveKey(processGroup.Addresses[0]))
+					})
+
+					It("should not exclude the processes again before the timeout", func() {
+						requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+						Expect(requeue).To(BeNil())
+						Expect(adminClient.ExcludedAddresses).To(BeEmpty())
+					})
+
+					When("the timeout has passed again", func() {
+						JustBeforeEach(func() {
+							processGroup.ProcessGroupConditions[len(processGroup.ProcessGroupConditions)-1].Timestamp = time.Now().Add(-2 * time.Hour).Unix()
+							requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+						})
+
+						It("should retry the exclusion", func() {
+							Expect(requeue).To(BeNil())
+							Expect(adminClient.ExcludedAddresses).To(ConsistOf(processGroup.Addresses))
+							Expect(processGroup.ExclusionTimestamp).NotTo(BeZero())
+							Expect(processGroup.GetConditionTime(fdbtypes.ExclusionStuck)).To(BeNil())
+						})
+					})
+				})
+			})
+		})
+	})
+
+	Describe("batched exclusions", func() {
+		var adminClient *mockAdminClient
+		var requeue *requeue
+
+		getProcessGroup := func(processGroupID string) *fdbtypes.ProcessGroupStatus {
+			for _, status := range cluster.Status.ProcessGroups {
+				if status.ProcessGroupID == processGroupID {
+					return status
+				}
+			}
+			Fail(fmt.Sprintf("missing process group %s", processGroupID))
+			return nil
+		}
+
+		BeforeEach(func() {
+			adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.ProcessCounts.Storage = 2
+			cluster.Spec.AutomationOptions.MaxConcurrentExclusions = pointer.Int(1)
+			getProcessGroup("storage-1").Remove = true
+			getProcessGroup("storage-2").Remove = true
+		})
+
+		JustBeforeEach(func() {
+			requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		})
+
+		It("should only exclude the first batch", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.curError).NotTo(HaveOccurred())
+			Expect(requeue.delayedRequeue).To(BeTrue())
+			Expect(adminClient.ExcludedAddresses).To(ConsistOf(getProcessGroup("storage-1").Addresses))
+			Expect(getProcessGroup("storage-2").ExclusionTimestamp).To(BeZero())
+		})
+
+		It("should not exclude the next batch while the first batch is in progress", func() {
+			requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			Expect(requeue).NotTo(BeNil())
+			Expect(adminClient.ExcludedAddresses).To(ConsistOf(getProcessGroup("storage-1").Addresses))
+		})
+
+		When("the first batch has been removed", func() {
+			JustBeforeEach(func() {
+				processGroups := make([]*fdbtypes.ProcessGroupStatus, 0, len(cluster.Status.ProcessGroups))
+				for _, processGroup := range cluster.Status.ProcessGroups {
+					if processGroup.ProcessGroupID != "storage-1" {
+						processGroups = append(processGroups, processGroup)
+					}
+				}
+				cluster.Status.ProcessGroups = processGroups
+
+				requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should exclude the next batch", func() {
+				Expect(requeue).To(BeNil())
+				Expect(adminClient.ExcludedAddresses).To(ContainElements(getProcessGroup("storage-2").Addresses))
+				Expect(getProcessGroup("storage-2").ExclusionTimestamp).NotTo(BeZero())
+			})
+		})
+
+		When("the exclusion command fails", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("ExcludeInstances", 1)
+			})
+
+			It("should record the exclusion before issuing it", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).To(HaveOccurred())
+				Expect(adminClient.ExcludedAddresses).To(BeEmpty())
+				Expect(getProcessGroup("storage-1").ExclusionTimestamp).NotTo(BeZero())
+				Expect(getProcessGroup("storage-2").ExclusionTimestamp).To(BeZero())
+				Expect(cluster.Status.PendingExclusion).NotTo(BeNil())
+				Expect(cluster.Status.PendingExclusion.Addresses).To(ConsistOf(getProcessGroup("storage-1").Addresses))
+			})
+
+			It("should issue the exclusion again in the next reconciliation", func() {
+				requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).NotTo(HaveOccurred())
+				Expect(adminClient.ExcludedAddresses).To(ConsistOf(getProcessGroup("storage-1").Addresses))
+				Expect(getProcessGroup("storage-2").ExclusionTimestamp).To(BeZero())
+				Expect(cluster.Status.PendingExclusion).To(BeNil())
+			})
+		})
+
+		When("an exclusion was recorded but not confirmed", func() {
+			BeforeEach(func() {
+				cluster.Status.PendingExclusion = &fdbtypes.PendingProcessOperation{
+					Addresses: getProcessGroup("storage-2").Addresses,
+					Timestamp: time.Now().Unix(),
+				}
+			})
+
+			It("should resume the exclusion", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).NotTo(HaveOccurred())
+				Expect(adminClient.ExcludedAddresses).To(ConsistOf(getProcessGroup("storage-2").Addresses))
+				Expect(getProcessGroup("storage-2").ExclusionTimestamp).NotTo(BeZero())
+				Expect(cluster.Status.PendingExclusion).To(BeNil())
+			})
+
+			When("the process group is no longer marked for removal", func() {
+				BeforeEach(func() {
+					getProcessGroup("storage-2").Remove = false
+				})
+
+				It("should not exclude the process group", func() {
+					Expect(adminClient.ExcludedAddresses).To(ConsistOf(getProcessGroup("storage-1").Addresses))
+					Expect(cluster.Status.PendingExclusion).To(BeNil())
+				})
+			})
+		})
+
+		When("an exclusion was issued without recording it in the status", func() {
+			BeforeEach(func() {
+				adminClient.ExcludedAddresses = append(adminClient.ExcludedAddresses, getProcessGroup("storage-2").Addresses...)
+			})
+
+			It("should count the exclusion as in progress", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.curError).NotTo(HaveOccurred())
+				Expect(requeue.delayedRequeue).To(BeTrue())
+				Expect(adminClient.ExcludedAddresses).To(ConsistOf(getProcessGroup("storage-2").Addresses))
+				Expect(getProcessGroup("storage-1").ExclusionTimestamp).To(BeZero())
+				Expect(getProcessGroup("storage-2").ExclusionTimestamp).NotTo(BeZero())
+			})
+		})
+	})
+
+	Describe("free space checks", func() {
+		var adminClient *mockAdminClient
+		var processGroup *fdbtypes.ProcessGroupStatus
+		var requeue *requeue
+
+		BeforeEach(func() {
+			adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.AutomationOptions.MinimumFreeSpacePercentage = pointer.Int(20)
+			for _, status := range cluster.Status.ProcessGroups {
+				if status.ProcessGroupID == "storage-1" {
+					processGroup = status
+				}
+			}
+			Expect(processGroup).NotTo(BeNil())
+			processGroup.Remove = true
+			adminClient.MockDiskInfo("storage-1", 50, 100)
+		})
+
+		JustBeforeEach(func() {
+			requeue = excludeInstances{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		})
+
+		When("the remaining processes have enough free space", func() {
+			BeforeEach(func() {
+				for _, processGroupID := range []string{"storage-2", "storage-3", "storage-4"} {
+					adminClient.MockDiskInfo(processGroupID, 80, 100)
+				}
+			})
+
+			It("should exclude the processes", func() {
+				Expect(requeue).To(BeNil())
+				Expect(adminClient.ExcludedAddresses).To(ConsistOf(processGroup.Addresses))
+			})
+		})
+
+		When("the remaining processes do not have enough free space", func() {
+			BeforeEach(func() {
+				for _, processGroupID := range []string{"storage-2", "storage-3", "storage-4"} {
+					adminClient.MockDiskInfo(processGroupID, 30, 100)
+				}
+			})
+
+			It("should not exclude the processes", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(HavePrefix("Waiting for sufficient free space on the storage processes"))
+				Expect(adminClient.ExcludedAddresses).To(BeEmpty())
+				Expect(processGroup.ExclusionTimestamp).To(BeZero())
+			})
+		})
+	})
+
+	Describe("getFreeSpacePercentageAfterExclusion", func() {
+		It("should assume enough free space without disk information", func() {
+			status := &fdbtypes.FoundationDBStatus{}
+			Expect(getFreeSpacePercentageAfterExclusion(status, fdbtypes.ProcessClassStorage, nil)).To(BeNumerically("==", 100))
+		})
+	})
 })
 
 func createMissingProcesses(cluster *fdbtypes.FoundationDBCluster, count int, processClass fdbtypes.ProcessClass) {
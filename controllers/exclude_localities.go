@@ -0,0 +1,155 @@
+/*
+ * exclude_localities.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// excludeLocalities provides a reconciliation step for excluding and
+// including whole localities, e.g. a zone that goes into maintenance.
+type excludeLocalities struct{}
+
+// reconcile runs the reconciler's work.
+func (excludeLocalities) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	if len(cluster.Spec.ExcludedLocalities) == 0 && len(cluster.Status.LocalityExclusions) == 0 {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "excludeLocalities")
+
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !version.SupportsLocalityBasedExclusions() {
+		return &requeue{message: fmt.Sprintf("Version %s does not support locality-based exclusions", version), delayedRequeue: true}
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	desiredExclusions := make(map[string]bool, len(cluster.Spec.ExcludedLocalities))
+	for _, locality := range cluster.Spec.ExcludedLocalities {
+		desiredExclusions[locality.String()] = true
+	}
+
+	currentExclusions := make(map[string]bool, len(cluster.Status.LocalityExclusions))
+	var localitiesToInclude []fdbtypes.LocalityExclusion
+	for _, exclusionStatus := range cluster.Status.LocalityExclusions {
+		currentExclusions[exclusionStatus.String()] = true
+		if !desiredExclusions[exclusionStatus.String()] {
+			localitiesToInclude = append(localitiesToInclude, exclusionStatus.LocalityExclusion)
+		}
+	}
+
+	var localitiesToExclude []fdbtypes.LocalityExclusion
+	for _, locality := range cluster.Spec.ExcludedLocalities {
+		if !currentExclusions[locality.String()] {
+			localitiesToExclude = append(localitiesToExclude, locality)
+		}
+	}
+
+	if len(localitiesToInclude) > 0 || len(localitiesToExclude) > 0 {
+		hasLock, err := r.takeLock(context, cluster, "updating locality exclusions")
+		if !hasLock {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(localitiesToInclude) > 0 {
+		logger.Info("Including localities", "localities", localitiesToInclude)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "IncludingLocalities", fmt.Sprintf("Including %v", localitiesToInclude))
+		err = adminClient.IncludeLocalities(context, localitiesToInclude)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(localitiesToExclude) > 0 {
+		logger.Info("Excluding localities", "localities", localitiesToExclude)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ExcludingLocalities", fmt.Sprintf("Excluding %v", localitiesToExclude))
+		err = adminClient.ExcludeLocalities(context, localitiesToExclude)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	status, err := adminClient.GetStatus(context)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	exclusionStatuses := getLocalityExclusionStatuses(cluster.Spec.ExcludedLocalities, status)
+	if !equality.Semantic.DeepEqual(cluster.Status.LocalityExclusions, exclusionStatuses) {
+		cluster.Status.LocalityExclusions = exclusionStatuses
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	for _, exclusionStatus := range exclusionStatuses {
+		if exclusionStatus.RemainingProcesses > 0 {
+			return &requeue{message: fmt.Sprintf("Waiting for %d processes in %s to be evacuated", exclusionStatus.RemainingProcesses, exclusionStatus.String()), delayedRequeue: true}
+		}
+	}
+
+	return nil
+}
+
+// getLocalityExclusionStatuses determines the progress of the locality
+// exclusions based on the processes in the database status. A process is
+// evacuated once it is excluded and has no roles left.
+func getLocalityExclusionStatuses(localities []fdbtypes.LocalityExclusion, status *fdbtypes.FoundationDBStatus) []fdbtypes.LocalityExclusionStatus {
+	if len(localities) == 0 {
+		return nil
+	}
+
+	exclusionStatuses := make([]fdbtypes.LocalityExclusionStatus, 0, len(localities))
+	for _, locality := range localities {
+		exclusionStatus := fdbtypes.LocalityExclusionStatus{LocalityExclusion: locality}
+		for _, process := range status.Cluster.Processes {
+			if !locality.Matches(process.Locality) {
+				continue
+			}
+
+			exclusionStatus.Processes++
+			if !process.Excluded || len(process.Roles) > 0 {
+				exclusionStatus.RemainingProcesses++
+			}
+		}
+
+		exclusionStatuses = append(exclusionStatuses, exclusionStatus)
+	}
+
+	return exclusionStatuses
+}
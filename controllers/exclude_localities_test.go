@@ -0,0 +1,174 @@
+/*
+ * exclude_localities_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("exclude_localities", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+	var zone fdbtypes.LocalityExclusion
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+		// The mock admin client uses the pod name as the zone ID.
+		zone = fdbtypes.LocalityExclusion{Key: fdbtypes.FDBLocalityZoneIDKey, Value: "operator-test-1-storage-1"}
+	})
+
+	JustBeforeEach(func() {
+		requeue = excludeLocalities{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	getExcludedProcesses := func() []string {
+		status, err := adminClient.GetStatus(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+
+		var excluded []string
+		for name, process := range status.Cluster.Processes {
+			if process.Excluded {
+				excluded = append(excluded, name)
+			}
+		}
+
+		return excluded
+	}
+
+	Context("without excluded localities", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not exclude any processes", func() {
+			Expect(getExcludedProcesses()).To(BeEmpty())
+		})
+	})
+
+	Context("with an excluded zone", func() {
+		BeforeEach(func() {
+			cluster.Spec.ExcludedLocalities = []fdbtypes.LocalityExclusion{zone}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should exclude the processes in the zone", func() {
+			Expect(getExcludedProcesses()).To(Equal([]string{"operator-test-1-storage-1-1"}))
+		})
+
+		It("should report the progress in the status", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.LocalityExclusions).To(Equal([]fdbtypes.LocalityExclusionStatus{
+				{LocalityExclusion: zone, Processes: 1, RemainingProcesses: 0},
+			}))
+		})
+
+		When("the processes in the zone still have roles", func() {
+			BeforeEach(func() {
+				adminClient.durabilityLag = map[string]float64{"storage-1": 1}
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.delayedRequeue).To(BeTrue())
+				Expect(requeue.message).To(Equal("Waiting for 1 processes in locality_zoneid:operator-test-1-storage-1 to be evacuated"))
+			})
+
+			It("should report the remaining processes in the status", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.LocalityExclusions).To(Equal([]fdbtypes.LocalityExclusionStatus{
+					{LocalityExclusion: zone, Processes: 1, RemainingProcesses: 1},
+				}))
+			})
+		})
+
+		When("the version does not support locality-based exclusions", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.Default.String()
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.delayedRequeue).To(BeTrue())
+			})
+
+			It("should not exclude any processes", func() {
+				Expect(getExcludedProcesses()).To(BeEmpty())
+			})
+		})
+	})
+
+	Context("with a zone that is no longer excluded", func() {
+		BeforeEach(func() {
+			adminClient.excludedLocalities = map[string]fdbtypes.LocalityExclusion{zone.String(): zone}
+			cluster.Status.LocalityExclusions = []fdbtypes.LocalityExclusionStatus{
+				{LocalityExclusion: zone, Processes: 1},
+			}
+			err = k8sClient.Status().Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+			cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should include the processes again", func() {
+			Expect(getExcludedProcesses()).To(BeEmpty())
+		})
+
+		It("should clear the status", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.LocalityExclusions).To(BeEmpty())
+		})
+	})
+})
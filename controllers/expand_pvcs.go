@@ -0,0 +1,158 @@
+/*
+ * expand_pvcs.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/podmanager"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// expandPVCs provides a reconciliation step for increasing the size of
+// existing PVCs when the storage class allows volume expansion.
+type expandPVCs struct{}
+
+// reconcile runs the reconciler's work.
+func (e expandPVCs) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "expandPVCs")
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	err := r.List(context, pvcs, internal.GetPodListOptions(cluster, "", "")...)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	pvcMap := internal.CreatePVCMap(cluster, pvcs)
+
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove {
+			continue
+		}
+
+		pvc, ok := pvcMap[processGroup.ProcessGroupID]
+		if !ok {
+			continue
+		}
+
+		desiredPVC, err := getDesiredPVC(cluster, pvc)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if desiredPVC == nil || pvc.Annotations[fdbtypes.LastSpecKey] == desiredPVC.Annotations[fdbtypes.LastSpecKey] {
+			continue
+		}
+
+		canExpand, err := pvcCanBeExpanded(r, context, pvc, desiredPVC)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if !canExpand {
+			continue
+		}
+
+		currentSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		desiredSize := desiredPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+		logger.Info("Expanding PVC",
+			"processGroupID", processGroup.ProcessGroupID,
+			"pvc", pvc.Name,
+			"currentSize", currentSize.String(),
+			"desiredSize", desiredSize.String())
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ExpandingVolume",
+			fmt.Sprintf("Expanding PVC %s from %s to %s", pvc.Name, currentSize.String(), desiredSize.String()))
+
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = desiredSize
+		pvc.Annotations[fdbtypes.LastSpecKey] = desiredPVC.Annotations[fdbtypes.LastSpecKey]
+		err = r.Update(context, &pvc)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
+
+// getDesiredPVC builds the PVC that the operator would create for the
+// process group of an existing PVC.
+func getDesiredPVC(cluster *fdbtypes.FoundationDBCluster, pvc corev1.PersistentVolumeClaim) (*corev1.PersistentVolumeClaim, error) {
+	instanceID := internal.GetProcessGroupIDFromMeta(cluster, pvc.ObjectMeta)
+	_, idNum, err := podmanager.ParseProcessGroupID(instanceID)
+	if err != nil {
+		return nil, err
+	}
+
+	processClass := internal.GetProcessClassFromMeta(cluster, pvc.ObjectMeta)
+	return internal.GetPvc(cluster, processClass, idNum)
+}
+
+// pvcCanBeExpanded checks if the only change between the PVC and the desired
+// PVC is an increase in the requested storage, and if the storage class of
+// the PVC allows volume expansion.
+func pvcCanBeExpanded(r *FoundationDBClusterReconciler, context ctx.Context, pvc corev1.PersistentVolumeClaim, desiredPVC *corev1.PersistentVolumeClaim) (bool, error) {
+	if pvc.Name != desiredPVC.Name {
+		return false, nil
+	}
+
+	currentSize := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	desiredSize := desiredPVC.Spec.Resources.Requests[corev1.ResourceStorage]
+	if desiredSize.Cmp(currentSize) <= 0 {
+		return false, nil
+	}
+
+	// Compare the desired spec with the requested storage of the current
+	// PVC against the hash the current PVC was created with, to make sure
+	// nothing else has changed.
+	resizedSpec := desiredPVC.Spec.DeepCopy()
+	resizedSpec.Resources.Requests[corev1.ResourceStorage] = currentSize
+	resizedHash, err := internal.GetJSONHash(resizedSpec)
+	if err != nil {
+		return false, err
+	}
+
+	if pvc.Annotations[fdbtypes.LastSpecKey] != resizedHash {
+		return false, nil
+	}
+
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return false, nil
+	}
+
+	storageClass := &storagev1.StorageClass{}
+	err = r.Get(context, client.ObjectKey{Name: *pvc.Spec.StorageClassName}, storageClass)
+	if err != nil {
+		if k8serrors.IsNotFound(err) || k8serrors.IsForbidden(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return storageClass.AllowVolumeExpansion != nil && *storageClass.AllowVolumeExpansion, nil
+}
@@ -0,0 +1,132 @@
+/*
+ * expand_pvcs_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("expand_pvcs", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var err error
+	var requeue *requeue
+	var allowVolumeExpansion bool
+	var originalPVC *corev1.PersistentVolumeClaim
+	pvcName := types.NamespacedName{Namespace: "my-ns", Name: "operator-test-1-storage-1-data"}
+
+	getPVC := func() *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{}
+		err := k8sClient.Get(context.TODO(), pvcName, pvc)
+		Expect(err).NotTo(HaveOccurred())
+		return pvc
+	}
+
+	BeforeEach(func() {
+		storageClassName := "fdb-storage"
+		cluster = internal.CreateDefaultCluster()
+		cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{
+			fdbtypes.ProcessClassGeneral: {
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: &storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse("128G"),
+							},
+						},
+					},
+				},
+			},
+		}
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		originalPVC = getPVC()
+		cluster.Spec.Processes[fdbtypes.ProcessClassGeneral].VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("256G")
+	})
+
+	JustBeforeEach(func() {
+		storageClass := &storagev1.StorageClass{
+			ObjectMeta:           metav1.ObjectMeta{Name: "fdb-storage"},
+			AllowVolumeExpansion: &allowVolumeExpansion,
+		}
+		err = k8sClient.Create(context.TODO(), storageClass)
+		Expect(err).NotTo(HaveOccurred())
+
+		requeue = expandPVCs{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	Context("with a storage class that allows volume expansion", func() {
+		BeforeEach(func() {
+			allowVolumeExpansion = true
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should increase the requested storage", func() {
+			pvc := getPVC()
+			storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			Expect(storage.String()).To(Equal("256G"))
+		})
+
+		It("should update the spec hash", func() {
+			desiredPVC, err := internal.GetPvc(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(getPVC().Annotations[fdbtypes.LastSpecKey]).To(Equal(desiredPVC.Annotations[fdbtypes.LastSpecKey]))
+		})
+	})
+
+	Context("with a storage class that does not allow volume expansion", func() {
+		BeforeEach(func() {
+			allowVolumeExpansion = false
+		})
+
+		It("should not change the PVC", func() {
+			pvc := getPVC()
+			Expect(pvc.Spec).To(Equal(originalPVC.Spec))
+			Expect(pvc.Annotations).To(Equal(originalPVC.Annotations))
+		})
+	})
+})
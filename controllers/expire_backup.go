@@ -0,0 +1,68 @@
+/*
+ * expire_backup.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// expireBackup provides a reconciliation step for deleting old data from a
+// backup based on the expiration policy.
+type expireBackup struct{}
+
+// reconcile runs the reconciler's work.
+func (s expireBackup) reconcile(r *FoundationDBBackupReconciler, context ctx.Context, backup *fdbtypes.FoundationDBBackup) *requeue {
+	nextExpiration := backup.GetNextExpirationTime()
+	if nextExpiration == nil || time.Now().Before(*nextExpiration) {
+		return nil
+	}
+
+	if backup.Status.BackupDetails == nil || backup.Status.BackupDetails.URL == "" {
+		return nil
+	}
+
+	logger := log.WithValues("namespace", backup.Namespace, "backup", backup.Name, "reconciler", "expireBackup")
+
+	adminClient, err := r.adminClientForBackup(context, backup)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	logger.Info("Expiring old backup data", "url", backup.Status.BackupDetails.URL, "deleteBeforeDays", backup.Spec.ExpirationPolicy.DeleteBeforeDays)
+	err = adminClient.ExpireBackup(context, backup.Status.BackupDetails.URL, *backup.Spec.ExpirationPolicy)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	now := metav1.Now()
+	backup.Status.LastExpirationTime = &now
+	err = r.Status().Update(context, backup)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
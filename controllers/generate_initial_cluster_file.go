@@ -37,14 +37,14 @@ type generateInitialClusterFile struct{}
 
 // reconcile runs the reconciler's work.
 func (g generateInitialClusterFile) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "generateInitialClusterFile")
+	logger := getLogger(context, cluster, "generateInitialClusterFile")
 	if cluster.Status.ConnectionString != "" {
 		return nil
 	}
 
 	logger.Info("Generating initial cluster file")
 	r.Recorder.Event(cluster, corev1.EventTypeNormal, "ChangingCoordinators", "Choosing initial coordinators")
-	instances, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, fdbtypes.ProcessClassStorage, "")...)
+	instances, err := r.getInitialCoordinatorCandidates(context, cluster)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -84,6 +84,7 @@ func (g generateInitialClusterFile) reconcile(r *FoundationDBClusterReconciler,
 		if err != nil {
 			return &requeue{curError: err}
 		}
+		locality.Class = internal.GetProcessClassFromMeta(cluster, instances[indexOfProcess].ObjectMeta)
 		processLocality[indexOfProcess] = locality
 	}
 
@@ -104,3 +105,30 @@ func (g generateInitialClusterFile) reconcile(r *FoundationDBClusterReconciler,
 	}
 	return nil
 }
+
+// getInitialCoordinatorCandidates returns the Pods that can be used as
+// coordinators in the initial cluster file. Without a coordinator selection
+// only storage and coordinator Pods will be used, otherwise all Pods with an
+// eligible process class will be used.
+func (r *FoundationDBClusterReconciler) getInitialCoordinatorCandidates(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) ([]*corev1.Pod, error) {
+	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]*corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		processClass := internal.GetProcessClassFromMeta(cluster, pod.ObjectMeta)
+		if len(cluster.Spec.CoordinatorSelection) == 0 {
+			if processClass != fdbtypes.ProcessClassStorage && processClass != fdbtypes.ProcessClassCoordinator {
+				continue
+			}
+		} else if !cluster.IsEligibleAsCandidate(processClass) {
+			continue
+		}
+
+		candidates = append(candidates, pod)
+	}
+
+	return candidates, nil
+}
@@ -0,0 +1,112 @@
+/*
+ * generate_initial_cluster_file_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("generate_initial_cluster_file", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var err error
+	var requeue *requeue
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+	})
+
+	JustBeforeEach(func() {
+		requeue = generateInitialClusterFile{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	Context("with an existing connection string", func() {
+		var originalConnectionString string
+
+		BeforeEach(func() {
+			originalConnectionString = cluster.Status.ConnectionString
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should keep the connection string", func() {
+			Expect(cluster.Status.ConnectionString).To(Equal(originalConnectionString))
+		})
+	})
+
+	Context("without a connection string", func() {
+		BeforeEach(func() {
+			cluster.Status.ConnectionString = ""
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should generate a connection string from the pods", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(connectionString.DatabaseName).To(Equal("operator_test_1"))
+			Expect(connectionString.GenerationID).To(HaveLen(32))
+			Expect(connectionString.Coordinators).To(HaveLen(cluster.DesiredCoordinatorCount()))
+		})
+
+		Context("with a partial connection string", func() {
+			BeforeEach(func() {
+				cluster.Spec.PartialConnectionString = fdbtypes.ConnectionString{
+					DatabaseName: "sample",
+					GenerationID: "abcdefgh",
+				}
+			})
+
+			It("should use the database name and generation ID from the spec", func() {
+				connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(connectionString.DatabaseName).To(Equal("sample"))
+				Expect(connectionString.GenerationID).To(Equal("abcdefgh"))
+				Expect(connectionString.Coordinators).To(HaveLen(cluster.DesiredCoordinatorCount()))
+			})
+		})
+	})
+})
@@ -22,9 +22,13 @@ package controllers
 
 import (
 	"context"
+	"sync"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
@@ -73,14 +77,123 @@ var (
 		nil,
 	)
 
+	descClusterReconciliationStalled = prometheus.NewDesc(
+		"fdb_operator_cluster_reconciliation_stalled_status",
+		"status if the Fdb Cluster has not been reconciled within the reconciliation stalled timeout.",
+		descClusterDefaultLabels,
+		nil,
+	)
+
 	descProcessGroupStatus = prometheus.NewDesc(
 		"fdb_operator_process_group_total",
 		"the count of Fdb process groups in a specific condition.",
 		append(descClusterDefaultLabels, "process_class", "condition"),
 		nil,
 	)
+
+	descDatabaseRoles = prometheus.NewDesc(
+		"fdb_operator_database_role_processes",
+		"the count of Fdb processes that have a specific role in the database status.",
+		append(descClusterDefaultLabels, "role"),
+		nil,
+	)
+
+	descDatabaseLag = prometheus.NewDesc(
+		"fdb_operator_database_lag_seconds",
+		"the highest lag of any storage server in the database status.",
+		append(descClusterDefaultLabels, "lag_type"),
+		nil,
+	)
+
+	descDatabaseMovingData = prometheus.NewDesc(
+		"fdb_operator_database_moving_data_bytes",
+		"the number of bytes that data distribution is moving.",
+		append(descClusterDefaultLabels, "state"),
+		nil,
+	)
+
+	descDatabaseTransactions = prometheus.NewDesc(
+		"fdb_operator_database_transactions_per_second",
+		"the rate of transactions in the database status.",
+		append(descClusterDefaultLabels, "state"),
+		nil,
+	)
+
+	descDatabaseOperations = prometheus.NewDesc(
+		"fdb_operator_database_operations_per_second",
+		"the rate of read and write operations in the database status.",
+		append(descClusterDefaultLabels, "operation"),
+		nil,
+	)
+
+	descDatabaseStatusTimestamp = prometheus.NewDesc(
+		"fdb_operator_database_status_timestamp_seconds",
+		"the time in unix timestamp when the database status for the database metrics was fetched.",
+		descClusterDefaultLabels,
+		nil,
+	)
+
+	descProcessGroupDisk = prometheus.NewDesc(
+		"fdb_operator_process_group_disk_bytes",
+		"the disk usage of a Fdb process group in the database status.",
+		append(descClusterDefaultLabels, "process_group_id", "process_class", "disk_type"),
+		nil,
+	)
+
+	// databaseMetrics holds the metrics that were translated from the
+	// database status, keyed by the cluster.
+	databaseMetrics = databaseMetricsCache{entries: map[client.ObjectKey]databaseMetricsEntry{}}
 )
 
+// databaseMetricsEntry holds the metrics translated from the database status
+// of a single cluster.
+type databaseMetricsEntry struct {
+	// summary provides the summary of the database status.
+	summary internal.StatusSummary
+
+	// roleCounts provides the number of processes for each role.
+	roleCounts map[string]int
+
+	// workload provides the transaction and operation rates.
+	workload fdbtypes.FoundationDBStatusWorkload
+}
+
+// databaseMetricsCache holds the metrics from the latest database status
+// of every cluster.
+type databaseMetricsCache struct {
+	lock    sync.RWMutex
+	entries map[client.ObjectKey]databaseMetricsEntry
+}
+
+// set stores the metrics for a cluster, replacing the previous entry.
+func (cache *databaseMetricsCache) set(key client.ObjectKey, status *fdbtypes.FoundationDBStatus, summary internal.StatusSummary) {
+	roleCounts := map[string]int{}
+	for _, process := range status.Cluster.Processes {
+		for _, role := range process.Roles {
+			roleCounts[role.Role]++
+		}
+	}
+
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.entries[key] = databaseMetricsEntry{summary: summary, roleCounts: roleCounts, workload: status.Cluster.Workload}
+}
+
+// get returns the metrics for a cluster.
+func (cache *databaseMetricsCache) get(key client.ObjectKey) (databaseMetricsEntry, bool) {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+	entry, ok := cache.entries[key]
+	return entry, ok
+}
+
+// remove drops the metrics for a cluster.
+func (cache *databaseMetricsCache) remove(key client.ObjectKey) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	delete(cache.entries, key)
+}
+
 type fdbClusterCollector struct {
 	reconciler *FoundationDBClusterReconciler
 }
@@ -123,6 +236,7 @@ func collectMetrics(ch chan<- prometheus.Metric, cluster *fdbtypes.FoundationDBC
 	addGauge(descClusterStatus, float64(cluster.Status.Health.DataMovementPriority), "datamovementpriority")
 	addGauge(descClusterLastReconciled, float64(cluster.Status.Generations.Reconciled))
 	addGauge(descClusterReconciled, boolFloat64(cluster.ObjectMeta.Generation == cluster.Status.Generations.Reconciled))
+	addGauge(descClusterReconciliationStalled, boolFloat64(meta.IsStatusConditionTrue(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)))
 	addGauge(descInstancesToRemove, float64(len(cluster.Spec.InstancesToRemove)))
 	addGauge(descInstancesToRemoveWithoutExclusion, float64(len(cluster.Spec.InstancesToRemoveWithoutExclusion)))
 
@@ -132,6 +246,63 @@ func collectMetrics(ch chan<- prometheus.Metric, cluster *fdbtypes.FoundationDBC
 			addGauge(descProcessGroupStatus, float64(count), string(pclass), string(condition))
 		}
 	}
+
+	// Add the metrics translated from the database status that was fetched in
+	// the last reconciliation
+	entry, ok := databaseMetrics.get(client.ObjectKeyFromObject(cluster))
+	if !ok {
+		return
+	}
+
+	addGauge(descDatabaseStatusTimestamp, float64(entry.summary.Timestamp.Unix()))
+	for role, count := range entry.roleCounts {
+		addGauge(descDatabaseRoles, float64(count), role)
+	}
+	addGauge(descDatabaseLag, entry.summary.DataLagSeconds, "data")
+	addGauge(descDatabaseLag, entry.summary.DurabilityLagSeconds, "durability")
+	addGauge(descDatabaseMovingData, float64(entry.summary.MovingDataInFlightBytes), "in_flight")
+	addGauge(descDatabaseMovingData, float64(entry.summary.MovingDataInQueueBytes), "in_queue")
+	addGauge(descDatabaseTransactions, entry.workload.Transactions.Started.Hz, "started")
+	addGauge(descDatabaseTransactions, entry.workload.Transactions.Committed.Hz, "committed")
+	addGauge(descDatabaseTransactions, entry.workload.Transactions.Conflicted.Hz, "conflicted")
+	addGauge(descDatabaseOperations, entry.workload.Operations.Reads.Hz, "reads")
+	addGauge(descDatabaseOperations, entry.workload.Operations.Writes.Hz, "writes")
+
+	for processGroupID, disk := range getProcessGroupDiskMetrics(entry.summary) {
+		addGauge(descProcessGroupDisk, float64(disk.used), processGroupID, string(disk.processClass), "used")
+		addGauge(descProcessGroupDisk, float64(disk.total), processGroupID, string(disk.processClass), "total")
+	}
+}
+
+// processGroupDisk provides the disk usage of a process group.
+type processGroupDisk struct {
+	processClass fdbtypes.ProcessClass
+	used         int64
+	total        int64
+}
+
+// getProcessGroupDiskMetrics returns the disk usage of every process group in
+// the summary. The processes of a process group share the same disk, so this
+// uses the highest value that any of the processes reports.
+func getProcessGroupDiskMetrics(summary internal.StatusSummary) map[string]processGroupDisk {
+	disks := make(map[string]processGroupDisk)
+	for _, process := range summary.Processes {
+		if process.ProcessGroupID == "" {
+			continue
+		}
+
+		disk := disks[process.ProcessGroupID]
+		disk.processClass = process.ProcessClass
+		if used := process.DiskTotalBytes - process.DiskFreeBytes; used > disk.used {
+			disk.used = used
+		}
+		if process.DiskTotalBytes > disk.total {
+			disk.total = process.DiskTotalBytes
+		}
+		disks[process.ProcessGroupID] = disk
+	}
+
+	return disks
 }
 
 func getProcessGroupMetrics(cluster *fdbtypes.FoundationDBCluster) map[fdbtypes.ProcessClass]map[fdbtypes.ProcessGroupConditionType]int {
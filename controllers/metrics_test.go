@@ -22,6 +22,7 @@ package controllers
 
 import (
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
@@ -58,4 +59,20 @@ var _ = Describe("metrics", func() {
 			Expect(stats[fdbtypes.ProcessClassLog][fdbtypes.MissingProcesses]).To(BeNumerically("==", 1))
 		})
 	})
+
+	Context("Collecting the disk metrics", func() {
+		It("should report each process group once", func() {
+			disks := getProcessGroupDiskMetrics(internal.StatusSummary{
+				Processes: map[string]internal.ProcessSummary{
+					"storage-1-1": {ProcessGroupID: "storage-1", ProcessClass: fdbtypes.ProcessClassStorage, DiskTotalBytes: 100, DiskFreeBytes: 60},
+					"storage-1-2": {ProcessGroupID: "storage-1", ProcessClass: fdbtypes.ProcessClassStorage, DiskTotalBytes: 100, DiskFreeBytes: 50},
+					"log-1":       {ProcessGroupID: "log-1", ProcessClass: fdbtypes.ProcessClassLog, DiskTotalBytes: 200, DiskFreeBytes: 150},
+				},
+			})
+			Expect(disks).To(Equal(map[string]processGroupDisk{
+				"storage-1": {processClass: fdbtypes.ProcessClassStorage, used: 50, total: 100},
+				"log-1":     {processClass: fdbtypes.ProcessClassLog, used: 50, total: 200},
+			}))
+		})
+	})
 })
@@ -45,7 +45,7 @@ func (s modifyBackup) reconcile(r *FoundationDBBackupReconciler, context ctx.Con
 		}
 		defer adminClient.Close()
 
-		err = adminClient.ModifyBackup(snapshotPeriod)
+		err = adminClient.ModifyBackup(context, snapshotPeriod)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -0,0 +1,164 @@
+/*
+ * recover_coordinators.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// recoverCoordinators provides a reconciliation step for recovering the
+// cluster when a majority of the coordinators is unreachable.
+type recoverCoordinators struct{}
+
+// reconcile runs the reconciler's work.
+func (c recoverCoordinators) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "recoverCoordinators")
+	if !cluster.Status.Configured {
+		return nil
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	status, err := adminClient.GetStatus(context)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !coordinatorQuorumLost(status) {
+		return nil
+	}
+
+	if cluster.Annotations[fdbtypes.ForceCoordinatorRecoveryAnnotation] != "true" {
+		logger.Info("Coordinator quorum is lost")
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "CoordinatorQuorumLost",
+			fmt.Sprintf("A majority of the coordinators is unreachable, set the annotation %s=true to force a new connection string", fdbtypes.ForceCoordinatorRecoveryAnnotation))
+		return nil
+	}
+
+	connectionString, recoveredCoordinators, err := recoverConnectionString(cluster, status)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if recoveredCoordinators <= len(connectionString.Coordinators)/2 {
+		logger.Info("Not enough coordinators can be recovered", "recoveredCoordinators", recoveredCoordinators, "coordinators", connectionString.Coordinators)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "CoordinatorRecoveryBlocked",
+			fmt.Sprintf("Only %d of %d coordinators can be recovered from the existing process groups", recoveredCoordinators, len(connectionString.Coordinators)))
+		return nil
+	}
+
+	newConnectionString := connectionString.String()
+	if newConnectionString == cluster.Status.ConnectionString {
+		logger.Info("Waiting for recovered coordinators to become reachable")
+		return nil
+	}
+
+	// We don't take a lock here, since the lock is stored in the database
+	// and the database is not reachable without a coordinator quorum.
+	logger.Info("Forcing connection string change", "oldConnectionString", cluster.Status.ConnectionString, "newConnectionString", newConnectionString)
+	r.Recorder.Event(cluster, corev1.EventTypeNormal, "ForcingConnectionStringChange", fmt.Sprintf("Setting connection string to %s", newConnectionString))
+	cluster.Status.ConnectionString = newConnectionString
+	err = r.Status().Update(context, cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
+
+// coordinatorQuorumLost returns true if the status reports coordinators but
+// a majority of them is unreachable.
+func coordinatorQuorumLost(status *fdbtypes.FoundationDBStatus) bool {
+	return len(status.Client.Coordinators.Coordinators) > 0 && !status.Client.Coordinators.QuorumReachable
+}
+
+// recoverConnectionString builds a connection string where every unreachable
+// coordinator is replaced by the latest address of the process group that
+// was previously known at the coordinator's address. The description and the
+// generation ID are kept, since the coordinators keep their coordination state
+// on disk. This also returns the number of coordinators that are either
+// reachable or could be mapped to a new address.
+func recoverConnectionString(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus) (fdbtypes.ConnectionString, int, error) {
+	connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+	if err != nil {
+		return connectionString, 0, err
+	}
+
+	reachable := make(map[string]bool, len(status.Client.Coordinators.Coordinators))
+	for _, coordinator := range status.Client.Coordinators.Coordinators {
+		reachable[coordinator.Address.String()] = coordinator.Reachable
+	}
+
+	recoveredCoordinators := 0
+	newCoordinators := make([]string, len(connectionString.Coordinators))
+	for coordinatorIndex, coordinator := range connectionString.Coordinators {
+		newCoordinators[coordinatorIndex] = coordinator
+
+		if reachable[coordinator] {
+			recoveredCoordinators++
+			continue
+		}
+
+		coordinatorAddress, err := fdbtypes.ParseProcessAddress(coordinator)
+		if err != nil {
+			return connectionString, 0, err
+		}
+
+		for _, processGroup := range cluster.Status.ProcessGroups {
+			if processGroup.Remove || len(processGroup.Addresses) == 0 {
+				continue
+			}
+
+			latestAddress := processGroup.Addresses[len(processGroup.Addresses)-1]
+			if latestAddress == coordinatorAddress.IPAddress.String() {
+				continue
+			}
+
+			for _, address := range processGroup.Addresses {
+				if address != coordinatorAddress.IPAddress.String() {
+					continue
+				}
+
+				coordinatorAddress.IPAddress = net.ParseIP(latestAddress)
+				newCoordinators[coordinatorIndex] = coordinatorAddress.String()
+				recoveredCoordinators++
+				break
+			}
+
+			if newCoordinators[coordinatorIndex] != coordinator {
+				break
+			}
+		}
+	}
+
+	connectionString.Coordinators = newCoordinators
+	return connectionString, recoveredCoordinators, nil
+}
@@ -0,0 +1,150 @@
+/*
+ * recover_coordinators_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("recoverCoordinators", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var originalConnectionString string
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		originalConnectionString = cluster.Status.ConnectionString
+	})
+
+	JustBeforeEach(func() {
+		requeue = recoverCoordinators{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	Context("with a reconciled cluster", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not change the connection string", func() {
+			Expect(cluster.Status.ConnectionString).To(Equal(originalConnectionString))
+		})
+	})
+
+	When("a majority of the coordinators has new IPs", func() {
+		var newCoordinators []string
+
+		BeforeEach(func() {
+			connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+			Expect(err).NotTo(HaveOccurred())
+
+			pods := &corev1.PodList{}
+			err = k8sClient.List(context.TODO(), pods)
+			Expect(err).NotTo(HaveOccurred())
+
+			newCoordinators = append([]string{}, connectionString.Coordinators...)
+			for index, coordinator := range connectionString.Coordinators[:2] {
+				address, err := fdbtypes.ParseProcessAddress(coordinator)
+				Expect(err).NotTo(HaveOccurred())
+				oldIP := address.IPAddress.String()
+				newIP := fmt.Sprintf("1.1.255.%d", index+1)
+
+				for _, pod := range pods.Items {
+					if pod.Status.PodIP != oldIP {
+						continue
+					}
+
+					pod.Status.PodIP = newIP
+					err = k8sClient.Update(context.TODO(), &pod)
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, processGroup := range cluster.Status.ProcessGroups {
+						if processGroup.ProcessGroupID == pod.Labels[fdbtypes.FDBProcessGroupIDLabel] {
+							processGroup.AddAddresses([]string{newIP}, true)
+						}
+					}
+				}
+
+				newCoordinators[index] = cluster.GetFullAddress(newIP, fdbtypes.ProcessClassStorage, 1).String()
+			}
+		})
+
+		Context("without the recovery annotation", func() {
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should not change the connection string", func() {
+				Expect(cluster.Status.ConnectionString).To(Equal(originalConnectionString))
+			})
+
+			It("should report the lost quorum", func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				status, err := adminClient.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Client.Coordinators.QuorumReachable).To(BeFalse())
+			})
+		})
+
+		Context("with the recovery annotation", func() {
+			BeforeEach(func() {
+				if cluster.Annotations == nil {
+					cluster.Annotations = map[string]string{}
+				}
+				cluster.Annotations[fdbtypes.ForceCoordinatorRecoveryAnnotation] = "true"
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should update the coordinator addresses", func() {
+				connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+				originalString, err := fdbtypes.ParseConnectionString(originalConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(connectionString.DatabaseName).To(Equal(originalString.DatabaseName))
+				Expect(connectionString.GenerationID).To(Equal(originalString.GenerationID))
+				Expect(connectionString.Coordinators).To(Equal(newCoordinators))
+			})
+
+			It("should make the coordinator quorum reachable", func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				status, err := adminClient.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status.Client.Coordinators.QuorumReachable).To(BeTrue())
+			})
+		})
+	})
+})
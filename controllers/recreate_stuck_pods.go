@@ -0,0 +1,205 @@
+/*
+ * recreate_stuck_pods.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// recreateStuckPods provides a reconciliation step for recreating pods that
+// are crash looping, unschedulable or stuck in terminating.
+type recreateStuckPods struct{}
+
+// reconcile runs the reconciler's work.
+func (c recreateStuckPods) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "recreateStuckPods")
+	if !cluster.GetRecreateStuckPods() {
+		return nil
+	}
+
+	deletePodsEnabled := cluster.Spec.AutomationOptions.DeletePods
+	if deletePodsEnabled != nil && !*deletePodsEnabled {
+		logger.V(1).Info("Pod deletion is disabled, skipping recreation of stuck pods")
+		return nil
+	}
+
+	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	podMap := internal.CreatePodMap(cluster, pods)
+
+	crashLoopPods := make(map[string]bool, len(cluster.Spec.Buggify.CrashLoop))
+	for _, processGroupID := range cluster.Spec.Buggify.CrashLoop {
+		crashLoopPods[processGroupID] = true
+	}
+
+	stuckSince := time.Now().Add(-cluster.GetStuckPodTimeout()).Unix()
+	isStuck := func(processGroup *fdbtypes.ProcessGroupStatus, conditionType fdbtypes.ProcessGroupConditionType) bool {
+		conditionTime := processGroup.GetConditionTime(conditionType)
+		return conditionTime != nil && *conditionTime < stuckSince
+	}
+
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove {
+			continue
+		}
+
+		// Pods that are crash looping on purpose are left alone.
+		if crashLoopPods["*"] || crashLoopPods[processGroup.ProcessGroupID] {
+			continue
+		}
+
+		pod, ok := podMap[processGroup.ProcessGroupID]
+		if !ok || pod == nil {
+			continue
+		}
+
+		if isStuck(processGroup, fdbtypes.PodTerminating) {
+			if pod.ObjectMeta.DeletionTimestamp == nil {
+				continue
+			}
+
+			nodeReady, err := nodeIsReady(r, context, pod.Spec.NodeName)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			if nodeReady {
+				logger.Info("Pod is stuck in terminating on a ready node, skipping force deletion",
+					"processGroupID", processGroup.ProcessGroupID,
+					"node", pod.Spec.NodeName)
+				continue
+			}
+
+			logger.Info("Force deleting pod that is stuck in terminating",
+				"processGroupID", processGroup.ProcessGroupID,
+				"node", pod.Spec.NodeName)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "RecreatingStuckPod",
+				fmt.Sprintf("Force deleting pod %s that is stuck in terminating", pod.Name))
+
+			err = r.Delete(context, pod, client.GracePeriodSeconds(0))
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			return &requeue{message: "Pod that was stuck in terminating has been force deleted"}
+		}
+
+		if isStuck(processGroup, fdbtypes.PodUnschedulable) {
+			logger.Info("Recreating pod that is unschedulable",
+				"processGroupID", processGroup.ProcessGroupID)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "RecreatingStuckPod",
+				fmt.Sprintf("Recreating pod %s that is unschedulable", pod.Name))
+
+			err = r.PodLifecycleManager.DeletePod(r, context, pod)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			// A process group without addresses has never been running, so
+			// the volume holds no data and can be recreated as well. This
+			// allows the pod to be scheduled when the volume is bound to an
+			// unavailable zone.
+			if len(processGroup.Addresses) == 0 {
+				err = deleteProcessGroupPVC(r, context, cluster, processGroup.ProcessGroupID)
+				if err != nil {
+					return &requeue{curError: err}
+				}
+			}
+
+			return &requeue{message: "Pod that was unschedulable has been recreated"}
+		}
+
+		if isStuck(processGroup, fdbtypes.PodCrashLooping) {
+			logger.Info("Recreating pod that is crash looping",
+				"processGroupID", processGroup.ProcessGroupID)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "RecreatingStuckPod",
+				fmt.Sprintf("Recreating pod %s that is crash looping", pod.Name))
+
+			err = r.PodLifecycleManager.DeletePod(r, context, pod)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			return &requeue{message: "Pod that was crash looping has been recreated"}
+		}
+	}
+
+	return nil
+}
+
+// nodeIsReady checks if the node exists and reports a ready status. If the
+// operator is not allowed to read nodes this assumes the node is ready.
+func nodeIsReady(r *FoundationDBClusterReconciler, context ctx.Context, nodeName string) (bool, error) {
+	if nodeName == "" {
+		return false, nil
+	}
+
+	node := &corev1.Node{}
+	err := r.Get(context, client.ObjectKey{Name: nodeName}, node)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		if k8serrors.IsForbidden(err) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue, nil
+		}
+	}
+
+	return false, nil
+}
+
+// deleteProcessGroupPVC deletes the PVC for a process group, if one exists.
+func deleteProcessGroupPVC(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, processGroupID string) error {
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	err := r.List(context, pvcs, internal.GetSinglePodListOptions(cluster, processGroupID)...)
+	if err != nil {
+		return err
+	}
+
+	for index := range pvcs.Items {
+		err = r.Delete(context, &pvcs.Items[index])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+/*
+ * recreate_stuck_pods_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("recreate_stuck_pods", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var err error
+	var requeue *requeue
+	var podName types.NamespacedName
+
+	setCondition := func(processGroupID string, conditionType fdbtypes.ProcessGroupConditionType, age time.Duration) {
+		processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, processGroupID)
+		processGroup.ProcessGroupConditions = append(processGroup.ProcessGroupConditions, &fdbtypes.ProcessGroupCondition{
+			ProcessGroupConditionType: conditionType,
+			Timestamp:                 time.Now().Add(-age).Unix(),
+		})
+	}
+
+	getPod := func() (*corev1.Pod, error) {
+		pod := &corev1.Pod{}
+		err := k8sClient.Get(context.TODO(), podName, pod)
+		return pod, err
+	}
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		cluster.Spec.AutomationOptions.RecreateStuckPods = pointer.Bool(true)
+		podName = types.NamespacedName{Namespace: cluster.Namespace, Name: "operator-test-1-storage-1"}
+	})
+
+	JustBeforeEach(func() {
+		requeue = recreateStuckPods{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	Context("with a reconciled cluster", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not delete the pod", func() {
+			_, err = getPod()
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("with a pod that has been crash looping for a long time", func() {
+		BeforeEach(func() {
+			setCondition("storage-1", fdbtypes.PodCrashLooping, time.Hour)
+		})
+
+		It("should requeue", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.message).To(Equal("Pod that was crash looping has been recreated"))
+		})
+
+		It("should delete the pod", func() {
+			_, err = getPod()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		Context("with the recreation disabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.RecreateStuckPods = nil
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should not delete the pod", func() {
+				_, err = getPod()
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with pod deletion disabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.DeletePods = pointer.Bool(false)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+		})
+
+		Context("with a buggified crash loop", func() {
+			BeforeEach(func() {
+				cluster.Spec.Buggify.CrashLoop = []string{"storage-1"}
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should not delete the pod", func() {
+				_, err = getPod()
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with a process group marked for removal", func() {
+			BeforeEach(func() {
+				fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-1").Remove = true
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+		})
+	})
+
+	Context("with a pod that has recently started crash looping", func() {
+		BeforeEach(func() {
+			setCondition("storage-1", fdbtypes.PodCrashLooping, time.Minute)
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		Context("with a shorter timeout", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.StuckPodTimeoutSeconds = pointer.Int(30)
+			})
+
+			It("should delete the pod", func() {
+				Expect(requeue).NotTo(BeNil())
+				_, err = getPod()
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+	})
+
+	Context("with a pod that has been unschedulable for a long time", func() {
+		BeforeEach(func() {
+			setCondition("storage-1", fdbtypes.PodUnschedulable, time.Hour)
+		})
+
+		It("should requeue", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.message).To(Equal("Pod that was unschedulable has been recreated"))
+		})
+
+		It("should delete the pod", func() {
+			_, err = getPod()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		It("should keep the PVC", func() {
+			pvcs := &corev1.PersistentVolumeClaimList{}
+			err = k8sClient.List(context.TODO(), pvcs, internal.GetSinglePodListOptions(cluster, "storage-1")...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(len(pvcs.Items)).To(Equal(1))
+		})
+
+		Context("with a process group that has never been running", func() {
+			BeforeEach(func() {
+				fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-1").Addresses = nil
+			})
+
+			It("should delete the PVC", func() {
+				pvcs := &corev1.PersistentVolumeClaimList{}
+				err = k8sClient.List(context.TODO(), pvcs, internal.GetSinglePodListOptions(cluster, "storage-1")...)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(pvcs.Items)).To(Equal(0))
+			})
+		})
+	})
+
+	Context("with a pod that has been stuck in terminating for a long time", func() {
+		BeforeEach(func() {
+			setCondition("storage-1", fdbtypes.PodTerminating, time.Hour)
+
+			pod, err := getPod()
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.MockStuckTermination(pod, true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("with a missing node", func() {
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(Equal("Pod that was stuck in terminating has been force deleted"))
+			})
+
+			It("should delete the pod", func() {
+				_, err = getPod()
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		Context("with a ready node", func() {
+			BeforeEach(func() {
+				node := &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+					Status: corev1.NodeStatus{
+						Conditions: []corev1.NodeCondition{
+							{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+						},
+					},
+				}
+				err = k8sClient.Create(context.TODO(), node)
+				Expect(err).NotTo(HaveOccurred())
+
+				pod, err := getPod()
+				Expect(err).NotTo(HaveOccurred())
+				pod.Spec.NodeName = node.Name
+				err = k8sClient.Update(context.TODO(), pod)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should not delete the pod", func() {
+				pod, err := getPod()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pod.ObjectMeta.DeletionTimestamp).NotTo(BeNil())
+			})
+		})
+	})
+})
@@ -23,13 +23,13 @@ package controllers
 import (
 	ctx "context"
 	"fmt"
-	"net"
 	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // removeProcessGroups provides a reconciliation step for removing process groups as part of a
@@ -38,12 +38,12 @@ type removeProcessGroups struct{}
 
 // reconcile runs the reconciler's work.
 func (u removeProcessGroups) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	remainingMap, err := r.getRemainingMap(cluster)
+	remainingMap, err := r.getRemainingMap(context, cluster)
 	if err != nil {
 		return &requeue{curError: err}
 	}
 
-	allExcluded, processGroupsToRemove := r.getProcessGroupsToRemove(cluster, remainingMap)
+	allExcluded, processGroupsToRemove := r.getProcessGroupsToRemove(context, cluster, remainingMap)
 	// If no process groups are marked to remove we have to check if all process groups are excluded.
 	if len(processGroupsToRemove) == 0 {
 		if !allExcluded {
@@ -64,7 +64,7 @@ func (u removeProcessGroups) reconcile(r *FoundationDBClusterReconciler, context
 		}
 		defer adminClient.Close()
 
-		hasDesiredFaultTolerance, err := internal.HasDesiredFaultTolerance(adminClient, cluster)
+		hasDesiredFaultTolerance, err := internal.HasDesiredFaultTolerance(adminClient, context, cluster)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -77,15 +77,131 @@ func (u removeProcessGroups) reconcile(r *FoundationDBClusterReconciler, context
 		}
 	}
 
-	removedProcessGroups := r.removeProcessGroups(context, cluster, processGroupsToRemove)
-	err = includeInstance(r, context, cluster, removedProcessGroups)
-	if err != nil {
-		return &requeue{curError: err}
+	hasDeferredRemovals := false
+	if cluster.GetMaxZonesWithUnavailablePods() > 0 {
+		processGroupZones, zonesInProgress, err := r.getRemovalZones(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		var deferredProcessGroups []string
+		processGroupsToRemove, deferredProcessGroups = filterRemovalsByZone(processGroupsToRemove, processGroupZones, zonesInProgress, cluster.GetMaxZonesWithUnavailablePods())
+		if len(deferredProcessGroups) > 0 {
+			getLogger(context, cluster, "removeProcessGroups").Info("Deferring removals in other zones", "processGroupIDs", deferredProcessGroups)
+			hasDeferredRemovals = true
+		}
+	}
+
+	if len(processGroupsToRemove) > 0 {
+		removedProcessGroups := r.removeProcessGroups(context, cluster, processGroupsToRemove)
+		err = includeInstance(r, context, cluster, removedProcessGroups)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if hasDeferredRemovals {
+		return &requeue{message: "Waiting for removals in other zones to complete", delay: 15 * time.Second}
 	}
 
 	return nil
 }
 
+// getRemovalZones returns the zone of every process group, based on the
+// locality the processes report in the database status, and the zones that
+// have pods that are being removed. The processes of terminating pods no
+// longer report to the database, so the zone recorded in the process group
+// status is used for them. Process groups whose zone is unknown are treated
+// as their own zone.
+func (r *FoundationDBClusterReconciler) getRemovalZones(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (map[string]string, map[string]bool, error) {
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer adminClient.Close()
+
+	status, err := adminClient.GetStatus(context)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	processGroupZones := make(map[string]string, len(cluster.Status.ProcessGroups))
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.FaultDomain != "" {
+			processGroupZones[processGroup.ProcessGroupID] = processGroup.FaultDomain
+			continue
+		}
+
+		processGroupZones[processGroup.ProcessGroupID] = processGroup.ProcessGroupID
+	}
+
+	for _, process := range status.Cluster.Processes {
+		processGroupID := process.Locality[fdbtypes.FDBLocalityInstanceIDKey]
+		zone := process.Locality[fdbtypes.FDBLocalityZoneIDKey]
+		if processGroupID != "" && zone != "" {
+			processGroupZones[processGroupID] = zone
+		}
+	}
+
+	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	removals := make(map[string]bool)
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove {
+			removals[processGroup.ProcessGroupID] = true
+		}
+	}
+
+	zonesInProgress := make(map[string]bool)
+	for _, pod := range pods {
+		if pod.DeletionTimestamp == nil {
+			continue
+		}
+
+		processGroupID := internal.GetProcessGroupIDFromMeta(cluster, pod.ObjectMeta)
+		if removals[processGroupID] {
+			zonesInProgress[processGroupZones[processGroupID]] = true
+		}
+	}
+
+	return processGroupZones, zonesInProgress, nil
+}
+
+// filterRemovalsByZone splits the process groups to remove into the process
+// groups that can be removed now and the process groups whose removal has to
+// wait, so that no more than maxZones zones have pods that are being removed
+// at the same time.
+func filterRemovalsByZone(processGroupsToRemove []string, processGroupZones map[string]string, zonesInProgress map[string]bool, maxZones int) ([]string, []string) {
+	allowedZones := make(map[string]bool, len(zonesInProgress))
+	for zone := range zonesInProgress {
+		allowedZones[zone] = true
+	}
+
+	removals := make([]string, 0, len(processGroupsToRemove))
+	deferred := make([]string, 0)
+	for _, processGroupID := range processGroupsToRemove {
+		zone, ok := processGroupZones[processGroupID]
+		if !ok {
+			zone = processGroupID
+		}
+
+		if !allowedZones[zone] {
+			if len(allowedZones) >= maxZones {
+				deferred = append(deferred, processGroupID)
+				continue
+			}
+			allowedZones[zone] = true
+		}
+
+		removals = append(removals, processGroupID)
+	}
+
+	return removals, deferred
+}
+
 func removeProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, instanceID string) error {
 	instanceListOptions := internal.GetSinglePodListOptions(cluster, instanceID)
 	instances, err := r.PodLifecycleManager.GetPods(r, cluster, context, instanceListOptions...)
@@ -103,13 +219,19 @@ func removeProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context, c
 		return fmt.Errorf("multiple pods found for cluster %s, processGroup %s", cluster.Name, instanceID)
 	}
 
+	processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, instanceID)
+
 	pvcs := &corev1.PersistentVolumeClaimList{}
 	err = r.List(context, pvcs, instanceListOptions...)
 	if err != nil {
 		return err
 	}
 	if len(pvcs.Items) == 1 {
-		err = r.Delete(context, &pvcs.Items[0])
+		if cluster.GetPVCRetentionPolicy().RetainsRemovedResource(processGroup) {
+			err = retainResource(r, context, cluster, &pvcs.Items[0])
+		} else {
+			err = r.Delete(context, &pvcs.Items[0])
+		}
 		if err != nil {
 			return err
 		}
@@ -123,7 +245,11 @@ func removeProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context, c
 		return err
 	}
 	if len(services.Items) == 1 {
-		err = r.Delete(context, &services.Items[0])
+		if cluster.GetServiceRetentionPolicy().RetainsRemovedResource(processGroup) {
+			err = retainResource(r, context, cluster, &services.Items[0])
+		} else {
+			err = r.Delete(context, &services.Items[0])
+		}
 		if err != nil {
 			return err
 		}
@@ -134,8 +260,17 @@ func removeProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context, c
 	return nil
 }
 
+// retainResource releases a PVC or service from the cluster instead of
+// deleting it, so that it is kept after its process group is gone.
+func retainResource(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, resource client.Object) error {
+	getLogger(context, cluster, "removeProcessGroups").Info("Retaining resource", "kind", fmt.Sprintf("%T", resource), "name", resource.GetName())
+
+	internal.ReleaseFromCluster(cluster, resource)
+	return r.Update(context, resource)
+}
+
 func confirmRemoval(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, processGroupID string) (bool, bool, error) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "removeProcessGroups")
+	logger := getLogger(context, cluster, "removeProcessGroups")
 	canBeIncluded := true
 	instanceListOptions := internal.GetSinglePodListOptions(cluster, processGroupID)
 
@@ -207,9 +342,11 @@ func includeInstance(r *FoundationDBClusterReconciler, context ctx.Context, clus
 	processGroups := make([]*fdbtypes.ProcessGroupStatus, 0, len(cluster.Status.ProcessGroups))
 	for _, processGroup := range cluster.Status.ProcessGroups {
 		if processGroup.Remove && removedProcessGroups[processGroup.ProcessGroupID] {
-			for _, pAddr := range processGroup.Addresses {
-				addresses = append(addresses, fdbtypes.ProcessAddress{IPAddress: net.ParseIP(pAddr)})
+			processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+			if err != nil {
+				return err
 			}
+			addresses = append(addresses, processGroupAddresses...)
 			hasStatusUpdate = true
 		} else {
 			processGroups = append(processGroups, processGroup)
@@ -219,7 +356,7 @@ func includeInstance(r *FoundationDBClusterReconciler, context ctx.Context, clus
 	if len(addresses) > 0 {
 		r.Recorder.Event(cluster, corev1.EventTypeNormal, "IncludingInstances", fmt.Sprintf("Including removed processes: %v", addresses))
 
-		err = adminClient.IncludeInstances(addresses)
+		err = adminClient.IncludeInstances(context, addresses)
 		if err != nil {
 			return err
 		}
@@ -243,8 +380,8 @@ func includeInstance(r *FoundationDBClusterReconciler, context ctx.Context, clus
 	return nil
 }
 
-func (r *FoundationDBClusterReconciler) getRemainingMap(cluster *fdbtypes.FoundationDBCluster) (map[string]bool, error) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "removeProcessGroups")
+func (r *FoundationDBClusterReconciler) getRemainingMap(context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (map[string]bool, error) {
+	logger := getLogger(context, cluster, "removeProcessGroups")
 	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
 	if err != nil {
 		return map[string]bool{}, err
@@ -262,14 +399,16 @@ func (r *FoundationDBClusterReconciler) getRemainingMap(cluster *fdbtypes.Founda
 			continue
 		}
 
-		for _, pAddr := range processGroup.Addresses {
-			addresses = append(addresses, fdbtypes.ProcessAddress{IPAddress: net.ParseIP(pAddr)})
+		processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+		if err != nil {
+			return nil, err
 		}
+		addresses = append(addresses, processGroupAddresses...)
 	}
 
 	var remaining []fdbtypes.ProcessAddress
 	if len(addresses) > 0 {
-		remaining, err = adminClient.CanSafelyRemove(addresses)
+		remaining, err = adminClient.CanSafelyRemove(context, addresses)
 		if err != nil {
 			return map[string]bool{}, err
 		}
@@ -290,8 +429,8 @@ func (r *FoundationDBClusterReconciler) getRemainingMap(cluster *fdbtypes.Founda
 	return remainingMap, nil
 }
 
-func (r *FoundationDBClusterReconciler) getProcessGroupsToRemove(cluster *fdbtypes.FoundationDBCluster, remainingMap map[string]bool) (bool, []string) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "removeProcessGroups")
+func (r *FoundationDBClusterReconciler) getProcessGroupsToRemove(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, remainingMap map[string]bool) (bool, []string) {
+	logger := getLogger(context, cluster, "removeProcessGroups")
 	var cordSet map[string]struct{}
 	allExcluded := true
 	processGroupsToRemove := make([]string, 0, len(cluster.Status.ProcessGroups))
@@ -304,7 +443,7 @@ func (r *FoundationDBClusterReconciler) getProcessGroupsToRemove(cluster *fdbtyp
 		// Only query FDB if we have a pending removal otherwise don't query FDB
 		if len(cordSet) == 0 {
 			var err error
-			cordSet, err = r.getCoordinatorSet(cluster)
+			cordSet, err = r.getCoordinatorSet(context, cluster)
 
 			if err != nil {
 				logger.Error(err, "Fetching coordinator set for removal")
@@ -334,7 +473,7 @@ func (r *FoundationDBClusterReconciler) getProcessGroupsToRemove(cluster *fdbtyp
 }
 
 func (r *FoundationDBClusterReconciler) removeProcessGroups(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, processGroupsToRemove []string) map[string]bool {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "removeProcessGroups")
+	logger := getLogger(context, cluster, "removeProcessGroups")
 	r.Recorder.Event(cluster, corev1.EventTypeNormal, "RemovingProcesses", fmt.Sprintf("Removing pods: %v", processGroupsToRemove))
 
 	removedProcessGroups := make(map[string]bool)
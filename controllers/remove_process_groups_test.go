@@ -22,12 +22,17 @@ package controllers
 
 import (
 	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"k8s.io/utils/pointer"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
@@ -75,7 +80,7 @@ var _ = Describe("remove_process_groups", func() {
 				coordinatorIP: false,
 			}
 
-			allExcluded, processes := clusterReconciler.getProcessGroupsToRemove(cluster, remaining)
+			allExcluded, processes := clusterReconciler.getProcessGroupsToRemove(context.TODO(), cluster, remaining)
 			Expect(allExcluded).To(BeFalse())
 			Expect(processes).To(BeEmpty())
 		})
@@ -258,6 +263,213 @@ var _ = Describe("remove_process_groups", func() {
 		})
 	})
 
+	When("removing a process group with a PVC", func() {
+		var removedProcessGroup *fdbtypes.ProcessGroupStatus
+
+		BeforeEach(func() {
+			coordinators, err := clusterReconciler.getCoordinatorSet(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			removedProcessGroup = nil
+			for _, processGroup := range cluster.Status.ProcessGroups {
+				if _, ok := coordinators[processGroup.ProcessGroupID]; ok || !processGroup.ProcessClass.IsStateful() {
+					continue
+				}
+
+				removedProcessGroup = processGroup
+				break
+			}
+			Expect(removedProcessGroup).NotTo(BeNil())
+
+			marked, _ := fdbtypes.MarkProcessGroupForRemoval(cluster.Status.ProcessGroups, removedProcessGroup.ProcessGroupID, removedProcessGroup.ProcessClass, removedProcessGroup.Addresses[0])
+			Expect(marked).To(BeTrue())
+		})
+
+		getPVCs := func() ([]corev1.PersistentVolumeClaim, []corev1.PersistentVolumeClaim) {
+			pvcs := &corev1.PersistentVolumeClaimList{}
+			err := k8sClient.List(context.TODO(), pvcs, internal.GetSinglePodListOptions(cluster, removedProcessGroup.ProcessGroupID)...)
+			Expect(err).NotTo(HaveOccurred())
+
+			retainedPVCs := &corev1.PersistentVolumeClaimList{}
+			err = k8sClient.List(context.TODO(), retainedPVCs, client.InNamespace(cluster.Namespace), client.MatchingLabels{fdbtypes.RetainedFromClusterLabel: cluster.Name})
+			Expect(err).NotTo(HaveOccurred())
+
+			return pvcs.Items, retainedPVCs.Items
+		}
+
+		When("the process group was not excluded", func() {
+			It("should retain the PVC", func() {
+				Expect(result).To(BeNil())
+
+				pvcs, retainedPVCs := getPVCs()
+				Expect(pvcs).To(BeEmpty())
+				Expect(retainedPVCs).To(HaveLen(1))
+				Expect(retainedPVCs[0].Annotations[fdbtypes.RetainedProcessGroupIDAnnotation]).To(Equal(removedProcessGroup.ProcessGroupID))
+				Expect(retainedPVCs[0].OwnerReferences).To(BeEmpty())
+			})
+
+			When("the PVC retention policy is Delete", func() {
+				BeforeEach(func() {
+					policy := fdbtypes.ResourceRetentionPolicyDelete
+					cluster.Spec.DeletionOptions.PVCRetentionPolicy = &policy
+				})
+
+				It("should delete the PVC", func() {
+					Expect(result).To(BeNil())
+
+					pvcs, retainedPVCs := getPVCs()
+					Expect(pvcs).To(BeEmpty())
+					Expect(retainedPVCs).To(BeEmpty())
+				})
+			})
+		})
+
+		When("the process group was excluded", func() {
+			BeforeEach(func() {
+				removedProcessGroup.ExclusionTimestamp = time.Now().Unix()
+			})
+
+			It("should delete the PVC", func() {
+				Expect(result).To(BeNil())
+
+				pvcs, retainedPVCs := getPVCs()
+				Expect(pvcs).To(BeEmpty())
+				Expect(retainedPVCs).To(BeEmpty())
+			})
+
+			When("the PVC retention policy is Retain", func() {
+				BeforeEach(func() {
+					policy := fdbtypes.ResourceRetentionPolicyRetain
+					cluster.Spec.DeletionOptions.PVCRetentionPolicy = &policy
+				})
+
+				It("should retain the PVC", func() {
+					Expect(result).To(BeNil())
+
+					pvcs, retainedPVCs := getPVCs()
+					Expect(pvcs).To(BeEmpty())
+					Expect(retainedPVCs).To(HaveLen(1))
+				})
+			})
+		})
+
+		When("the PVC was retained", func() {
+			JustBeforeEach(func() {
+				Expect(result).To(BeNil())
+				result = addProcessGroups{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should not reuse the process group ID", func() {
+				Expect(result).To(BeNil())
+				Expect(fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, removedProcessGroup.ProcessGroupID)).To(BeNil())
+			})
+		})
+	})
+
+	When("removing process groups in multiple zones", func() {
+		var removedProcessGroupIDs []string
+
+		BeforeEach(func() {
+			coordinators, err := clusterReconciler.getCoordinatorSet(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			removedProcessGroupIDs = nil
+			for _, processGroup := range cluster.Status.ProcessGroups {
+				if _, ok := coordinators[processGroup.ProcessGroupID]; ok {
+					continue
+				}
+
+				marked, _ := fdbtypes.MarkProcessGroupForRemoval(cluster.Status.ProcessGroups, processGroup.ProcessGroupID, processGroup.ProcessClass, processGroup.Addresses[0])
+				Expect(marked).To(BeTrue())
+				removedProcessGroupIDs = append(removedProcessGroupIDs, processGroup.ProcessGroupID)
+				if len(removedProcessGroupIDs) == 2 {
+					break
+				}
+			}
+			Expect(removedProcessGroupIDs).To(HaveLen(2))
+		})
+
+		When("the number of zones is not limited", func() {
+			It("should remove all process groups", func() {
+				Expect(result).To(BeNil())
+				for _, processGroup := range cluster.Status.ProcessGroups {
+					Expect(removedProcessGroupIDs).NotTo(ContainElement(processGroup.ProcessGroupID))
+				}
+			})
+		})
+
+		When("only one zone can have unavailable pods", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.MaxZonesWithUnavailablePods = pointer.Int(1)
+			})
+
+			It("should only remove the process group in the first zone", func() {
+				Expect(result).NotTo(BeNil())
+				Expect(result.message).To(Equal("Waiting for removals in other zones to complete"))
+
+				remainingProcessGroupIDs := make([]string, 0, len(cluster.Status.ProcessGroups))
+				for _, processGroup := range cluster.Status.ProcessGroups {
+					remainingProcessGroupIDs = append(remainingProcessGroupIDs, processGroup.ProcessGroupID)
+				}
+				Expect(remainingProcessGroupIDs).NotTo(ContainElement(removedProcessGroupIDs[0]))
+				Expect(remainingProcessGroupIDs).To(ContainElement(removedProcessGroupIDs[1]))
+			})
+		})
+	})
+
+	When("the pod of a process group that is being removed is terminating", func() {
+		var processGroup *fdbtypes.ProcessGroupStatus
+
+		BeforeEach(func() {
+			processGroup = fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-4")
+			Expect(processGroup).NotTo(BeNil())
+			processGroup.Remove = true
+
+			adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+			adminClient.MockMissingProcessGroup(processGroup.ProcessGroupID, true)
+
+			pods := &corev1.PodList{}
+			err = k8sClient.List(context.TODO(), pods, internal.GetSinglePodListOptions(cluster, processGroup.ProcessGroupID)...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(HaveLen(1))
+			err = k8sClient.MockStuckTermination(&pods.Items[0], true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should use the recorded zone of the process group", func() {
+			Expect(processGroup.FaultDomain).To(Equal("operator-test-1-storage-4"))
+
+			processGroupZones, zonesInProgress, err := clusterReconciler.getRemovalZones(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(processGroupZones).To(HaveKeyWithValue("storage-4", "operator-test-1-storage-4"))
+			Expect(zonesInProgress).To(Equal(map[string]bool{"operator-test-1-storage-4": true}))
+		})
+	})
+
+	DescribeTable("filtering removals by zone",
+		func(zonesInProgress map[string]bool, maxZones int, expectedRemovals []string, expectedDeferred []string) {
+			processGroupZones := map[string]string{
+				"storage-1": "zone-a",
+				"storage-2": "zone-a",
+				"storage-3": "zone-b",
+				"storage-4": "zone-c",
+			}
+
+			removals, deferred := filterRemovalsByZone([]string{"storage-1", "storage-2", "storage-3", "storage-4"}, processGroupZones, zonesInProgress, maxZones)
+			Expect(removals).To(Equal(expectedRemovals))
+			Expect(deferred).To(Equal(expectedDeferred))
+		},
+		Entry("with one zone and no removals in progress",
+			map[string]bool{}, 1, []string{"storage-1", "storage-2"}, []string{"storage-3", "storage-4"}),
+		Entry("with two zones and no removals in progress",
+			map[string]bool{}, 2, []string{"storage-1", "storage-2", "storage-3"}, []string{"storage-4"}),
+		Entry("with one zone and a removal in progress in another zone",
+			map[string]bool{"zone-c": true}, 1, []string{"storage-4"}, []string{"storage-1", "storage-2", "storage-3"}),
+		Entry("with two zones and a removal in progress",
+			map[string]bool{"zone-b": true}, 2, []string{"storage-1", "storage-2", "storage-3"}, []string{"storage-4"}),
+	)
+
 	AfterEach(func() {
 		k8sClient.Clear()
 	})
@@ -0,0 +1,101 @@
+/*
+ * remove_services_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"k8s.io/utils/pointer"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("remove_services", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: cluster.Namespace,
+				Name:      cluster.Name,
+			},
+		}
+		err = k8sClient.Create(context.TODO(), service)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = removeServices{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	When("the headless service is disabled", func() {
+		BeforeEach(func() {
+			cluster.Spec.Routing.HeadlessService = pointer.Bool(false)
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should delete the headless service", func() {
+			err = k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, &corev1.Service{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		When("the service was already deleted", func() {
+			JustBeforeEach(func() {
+				requeue = removeServices{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+		})
+	})
+
+	When("the headless service is enabled", func() {
+		BeforeEach(func() {
+			cluster.Spec.Routing.HeadlessService = pointer.Bool(true)
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should keep the headless service", func() {
+			err = k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name}, &corev1.Service{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})
@@ -43,7 +43,7 @@ func (c replaceFailedProcessGroups) reconcile(r *FoundationDBClusterReconciler,
 	}
 	defer adminClient.Close()
 
-	if chooseNewRemovals(cluster, adminClient) {
+	if chooseNewRemovals(context, cluster, adminClient) {
 		err := r.Status().Update(context, cluster)
 		if err != nil {
 			return &requeue{curError: err}
@@ -57,8 +57,8 @@ func (c replaceFailedProcessGroups) reconcile(r *FoundationDBClusterReconciler,
 
 // chooseNewRemovals flags failed processes groups for removal and returns an indicator
 // of whether any processes were thus flagged.
-func chooseNewRemovals(cluster *fdbtypes.FoundationDBCluster, adminClient fdbadminclient.AdminClient) bool {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "replaceFailedProcessGroups")
+func chooseNewRemovals(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, adminClient fdbadminclient.AdminClient) bool {
+	logger := getLogger(context, cluster, "replaceFailedProcessGroups")
 	if !*cluster.Spec.AutomationOptions.Replacements.Enabled {
 		return false
 	}
@@ -83,18 +83,21 @@ func chooseNewRemovals(cluster *fdbtypes.FoundationDBCluster, adminClient fdbadm
 		}
 
 		needsReplacement, missingTime := processGroupStatus.NeedsReplacement(*cluster.Spec.AutomationOptions.Replacements.FailureDetectionTimeSeconds)
+		if !needsReplacement && cluster.GetReplaceUndesiredProcesses() {
+			needsReplacement, missingTime = processGroupStatus.IsUndesired(*cluster.Spec.AutomationOptions.Replacements.FailureDetectionTimeSeconds)
+		}
 		if needsReplacement && *cluster.Spec.AutomationOptions.Replacements.Enabled {
 			if len(processGroupStatus.Addresses) == 0 {
 				// Only replace process groups without an address if the cluster has the desired fault tolerance
 				// and is available.
-				hasDesiredFaultTolerance, err := internal.HasDesiredFaultTolerance(adminClient, cluster)
+				hasDesiredFaultTolerance, err := internal.HasDesiredFaultTolerance(adminClient, context, cluster)
 				if err != nil {
-					log.Error(err, "Could not fetch if cluster has desired fault tolerance")
+					logger.Error(err, "Could not fetch if cluster has desired fault tolerance")
 					continue
 				}
 
 				if !hasDesiredFaultTolerance {
-					log.Info(
+					logger.Info(
 						"Skip instance with missing address",
 						"processGroupID", processGroupStatus.ProcessGroupID,
 						"failureTime", time.Unix(missingTime, 0).UTC().String())
@@ -105,7 +108,7 @@ func chooseNewRemovals(cluster *fdbtypes.FoundationDBCluster, adminClient fdbadm
 				// The assumption here is that this is safe since we assume that the process group was never scheduled onto any node
 				// otherwise the process group should have an address associated.
 				processGroupStatus.ExclusionSkipped = true
-				log.Info(
+				logger.Info(
 					"Replace instance with missing address",
 					"processGroupID", processGroupStatus.ProcessGroupID,
 					"failureTime", time.Unix(missingTime, 0).UTC().String())
@@ -57,7 +57,7 @@ var _ = Describe("replace_failed_process_groups", func() {
 		adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(adminClient).NotTo(BeNil())
-		result = chooseNewRemovals(cluster, adminClient)
+		result = chooseNewRemovals(context.TODO(), cluster, adminClient)
 	})
 
 	Context("with no missing processes", func() {
@@ -276,6 +276,56 @@ var _ = Describe("replace_failed_process_groups", func() {
 		})
 	})
 
+	Context("with a process group on a node with a taint that needs replacement", func() {
+		BeforeEach(func() {
+			processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-2")
+			processGroup.ProcessGroupConditions = append(processGroup.ProcessGroupConditions, &fdbtypes.ProcessGroupCondition{
+				ProcessGroupConditionType: fdbtypes.NodeTaintReplacing,
+				Timestamp:                 time.Now().Unix(),
+			})
+		})
+
+		It("should return true", func() {
+			Expect(result).To(BeTrue())
+		})
+
+		It("should mark the process group for removal", func() {
+			Expect(getRemovedProcessGroupIDs(cluster)).To(Equal([]string{"storage-2"}))
+		})
+	})
+
+	Context("with a process that has reported IO errors for a long time", func() {
+		BeforeEach(func() {
+			processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-2")
+			processGroup.ProcessGroupConditions = append(processGroup.ProcessGroupConditions, &fdbtypes.ProcessGroupCondition{
+				ProcessGroupConditionType: fdbtypes.ProcessHasIOError,
+				Timestamp:                 time.Now().Add(-1 * time.Hour).Unix(),
+			})
+		})
+
+		It("should return false", func() {
+			Expect(result).To(BeFalse())
+		})
+
+		It("should not mark the process group for removal", func() {
+			Expect(getRemovedProcessGroupIDs(cluster)).To(Equal([]string{}))
+		})
+
+		Context("with the replacement of undesired processes enabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.ProcessHealthChecks.ReplaceUndesiredProcesses = pointer.Bool(true)
+			})
+
+			It("should return true", func() {
+				Expect(result).To(BeTrue())
+			})
+
+			It("should mark the process group for removal", func() {
+				Expect(getRemovedProcessGroupIDs(cluster)).To(Equal([]string{"storage-2"}))
+			})
+		})
+	})
+
 	Context("with a process that has had an incorrect pod spec for a long time", func() {
 		BeforeEach(func() {
 			processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-2")
@@ -42,7 +42,7 @@ type replaceMisconfiguredProcessGroups struct{}
 
 // reconcile runs the reconciler's work.
 func (c replaceMisconfiguredProcessGroups) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "replaceMisconfiguredProcessGroups")
+	logger := getLogger(context, cluster, "replaceMisconfiguredProcessGroups")
 	hasNewRemovals := false
 
 	pvcs := &corev1.PersistentVolumeClaimList{}
@@ -69,7 +69,7 @@ func (c replaceMisconfiguredProcessGroups) reconcile(r *FoundationDBClusterRecon
 		pod, hasPod := podMap[processGroup.ProcessGroupID]
 
 		if hasPVC {
-			needsPVCRemoval, err := instanceNeedsRemovalForPVC(cluster, pvc)
+			needsPVCRemoval, err := instanceNeedsRemovalForPVC(r, context, cluster, pvc)
 			if err != nil {
 				return &requeue{curError: err}
 			}
@@ -90,7 +90,7 @@ func (c replaceMisconfiguredProcessGroups) reconcile(r *FoundationDBClusterRecon
 			continue
 		}
 
-		needsRemoval, err := instanceNeedsRemoval(cluster, pod, processGroup)
+		needsRemoval, err := instanceNeedsRemoval(context, cluster, pod, processGroup)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -113,9 +113,9 @@ func (c replaceMisconfiguredProcessGroups) reconcile(r *FoundationDBClusterRecon
 	return nil
 }
 
-func instanceNeedsRemovalForPVC(cluster *fdbtypes.FoundationDBCluster, pvc corev1.PersistentVolumeClaim) (bool, error) {
+func instanceNeedsRemovalForPVC(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, pvc corev1.PersistentVolumeClaim) (bool, error) {
 	instanceID := internal.GetProcessGroupIDFromMeta(cluster, pvc.ObjectMeta)
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "pvc", pvc.Name, "processGroupID", instanceID, "reconciler", "replaceMisconfiguredProcessGroups")
+	logger := getLogger(context, cluster, "replaceMisconfiguredProcessGroups").WithValues("pvc", pvc.Name, "processGroupID", instanceID)
 
 	ownedByCluster := !cluster.ShouldFilterOnOwnerReferences()
 	if !ownedByCluster {
@@ -131,12 +131,7 @@ func instanceNeedsRemovalForPVC(cluster *fdbtypes.FoundationDBCluster, pvc corev
 		return false, nil
 	}
 
-	_, idNum, err := podmanager.ParseProcessGroupID(instanceID)
-	if err != nil {
-		return false, err
-	}
-	processClass := internal.GetProcessClassFromMeta(cluster, pvc.ObjectMeta)
-	desiredPVC, err := internal.GetPvc(cluster, processClass, idNum)
+	desiredPVC, err := getDesiredPVC(cluster, pvc)
 	if err != nil {
 		return false, err
 	}
@@ -146,6 +141,17 @@ func instanceNeedsRemovalForPVC(cluster *fdbtypes.FoundationDBCluster, pvc corev
 	}
 
 	if pvc.Annotations[fdbtypes.LastSpecKey] != pvcHash {
+		// Volumes that only need more storage are expanded in place when the
+		// storage class supports it.
+		canExpand, err := pvcCanBeExpanded(r, context, pvc, desiredPVC)
+		if err != nil {
+			return false, err
+		}
+		if canExpand {
+			logger.Info("PVC will be expanded instead of replacing the instance")
+			return false, nil
+		}
+
 		logger.Info("Replace instance",
 			"reason", fmt.Sprintf("PVC spec has changed from %s to %s", pvcHash, pvc.Annotations[fdbtypes.LastSpecKey]))
 		return true, nil
@@ -159,14 +165,14 @@ func instanceNeedsRemovalForPVC(cluster *fdbtypes.FoundationDBCluster, pvc corev
 	return false, nil
 }
 
-func instanceNeedsRemoval(cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod, processGroupStatus *fdbtypes.ProcessGroupStatus) (bool, error) {
+func instanceNeedsRemoval(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod, processGroupStatus *fdbtypes.ProcessGroupStatus) (bool, error) {
 	if pod == nil {
 		return false, nil
 	}
 
 	processGroupID := podmanager.GetProcessGroupID(cluster, pod)
 
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "processGroupID", processGroupID, "reconciler", "replaceMisconfiguredProcessGroups")
+	logger := getLogger(context, cluster, "replaceMisconfiguredProcessGroups").WithValues("processGroupID", processGroupID)
 
 	if processGroupStatus == nil {
 		return false, fmt.Errorf("unknown instance %s in replace_misconfigured_pods", processGroupID)
@@ -217,6 +223,20 @@ func instanceNeedsRemoval(cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod
 		}
 	}
 
+	if processClass.IsLogProcess() {
+		// Replace the instance if the log servers differ
+		logServersPerPod, err := internal.GetLogServersPerPodForPod(pod)
+		if err != nil {
+			return false, err
+		}
+
+		if logServersPerPod != cluster.GetLogServersPerPod() {
+			logger.Info("Replace instance",
+				"reason", fmt.Sprintf("logServersPerPod has changed from %d to %d", logServersPerPod, cluster.GetLogServersPerPod()))
+			return true, nil
+		}
+	}
+
 	expectedNodeSelector := cluster.GetProcessSettings(processClass).PodTemplate.Spec.NodeSelector
 	if !equality.Semantic.DeepEqual(pod.Spec.NodeSelector, expectedNodeSelector) {
 		logger.Info("Replace instance",
@@ -224,7 +244,7 @@ func instanceNeedsRemoval(cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod
 		return true, nil
 	}
 
-	if cluster.Spec.UpdatePodsByReplacement {
+	if cluster.GetPodUpdateStrategy() == fdbtypes.PodUpdateStrategyReplacement {
 		specHash, err := internal.GetPodSpecHash(cluster, processClass, idNum, nil)
 		if err != nil {
 			return false, err
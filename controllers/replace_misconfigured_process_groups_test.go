@@ -21,6 +21,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
@@ -29,6 +30,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -77,7 +79,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 	Describe("Check instance", func() {
 		Context("when instance has no Pod", func() {
 			It("should not need removal", func() {
-				needsRemoval, err := instanceNeedsRemoval(cluster, nil, nil)
+				needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, nil, nil)
 				Expect(needsRemoval).To(BeFalse())
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -85,7 +87,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 
 		Context("when processGroupStatus is missing", func() {
 			It("should return an error", func() {
-				needsRemoval, err := instanceNeedsRemoval(cluster, pod, nil)
+				needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, nil)
 				Expect(needsRemoval).To(BeFalse())
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal(fmt.Sprintf("unknown instance %s in replace_misconfigured_pods", instanceName)))
@@ -98,7 +100,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 					ProcessGroupID: instanceName,
 					Remove:         true,
 				}
-				needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+				needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 				Expect(needsRemoval).To(BeFalse())
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -110,13 +112,13 @@ var _ = Describe("replace_misconfigured_pods", func() {
 					ProcessGroupID: instanceName,
 					Remove:         false,
 				}
-				needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+				needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 				Expect(needsRemoval).To(BeFalse())
 				Expect(err).NotTo(HaveOccurred())
 
 				// Change the instance ID should trigger a removal
 				cluster.Spec.InstanceIDPrefix = "test"
-				needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+				needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 				Expect(needsRemoval).To(BeTrue())
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -128,13 +130,13 @@ var _ = Describe("replace_misconfigured_pods", func() {
 					ProcessGroupID: instanceName,
 					Remove:         false,
 				}
-				needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+				needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 				Expect(needsRemoval).To(BeFalse())
 				Expect(err).NotTo(HaveOccurred())
 
 				ipSource := fdbtypes.PublicIPSourceService
 				cluster.Spec.Routing.PublicIPSource = &ipSource
-				needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+				needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 				Expect(needsRemoval).To(BeTrue())
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -155,12 +157,12 @@ var _ = Describe("replace_misconfigured_pods", func() {
 			ipSource := fdbtypes.PublicIPSourceService
 			cluster.Spec.Routing.PublicIPSource = &ipSource
 
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
 			cluster.Spec.Routing.PublicIPSource = nil
-			needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeTrue())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -172,13 +174,13 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				ProcessGroupID: instanceName,
 				Remove:         false,
 			}
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
 			ipSource := fdbtypes.PublicIPSourcePod
 			cluster.Spec.Routing.PublicIPSource = &ipSource
-			needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -190,12 +192,12 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				ProcessGroupID: instanceName,
 				Remove:         false,
 			}
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
 			cluster.Spec.StorageServersPerPod = 2
-			needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeTrue())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -217,12 +219,56 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				ProcessGroupID: instanceName,
 				Remove:         false,
 			}
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
 			cluster.Spec.StorageServersPerPod = 2
-			needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
+			Expect(needsRemoval).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the logServersPerPod is changed for a log class instance", func() {
+		It("should need a removal", func() {
+			pod.ObjectMeta = metav1.ObjectMeta{
+				Labels: map[string]string{
+					fdbtypes.FDBProcessGroupIDLabel:    fmt.Sprintf("%s-1337", fdbtypes.ProcessClassLog),
+					fdbtypes.FDBProcessClassLabel:      string(fdbtypes.ProcessClassLog),
+					internal.OldFDBProcessGroupIDLabel: fmt.Sprintf("%s-1337", fdbtypes.ProcessClassLog),
+					internal.OldFDBProcessClassLabel:   string(fdbtypes.ProcessClassLog),
+				},
+				Annotations: map[string]string{},
+			}
+
+			status := &fdbtypes.ProcessGroupStatus{
+				ProcessGroupID: instanceName,
+				Remove:         false,
+			}
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
+			Expect(needsRemoval).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.LogServersPerPod = 2
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
+			Expect(needsRemoval).To(BeTrue())
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+
+	Context("when the logServersPerPod is changed for a storage class instance", func() {
+		It("should not need a removal", func() {
+			status := &fdbtypes.ProcessGroupStatus{
+				ProcessGroupID: instanceName,
+				Remove:         false,
+			}
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
+			Expect(needsRemoval).To(BeFalse())
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.LogServersPerPod = 2
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -234,14 +280,14 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				ProcessGroupID: instanceName,
 				Remove:         false,
 			}
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 
 			cluster.Spec.Processes[fdbtypes.ProcessClassGeneral].PodTemplate.Spec.NodeSelector = map[string]string{
 				"dummy": "test",
 			}
-			needsRemoval, err = instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err = instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeTrue())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -256,7 +302,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				ProcessGroupID: instanceName,
 				Remove:         false,
 			}
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -275,7 +321,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			cluster.Spec.UpdatePodsByReplacement = true
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeTrue())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -286,7 +332,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 			pvc, err := internal.GetPvc(cluster, fdbtypes.ProcessClassStorage, 1)
 			Expect(err).NotTo(HaveOccurred())
 			pvc.Name = "Test-storage"
-			needsRemoval, err := instanceNeedsRemovalForPVC(cluster, *pvc)
+			needsRemoval, err := instanceNeedsRemovalForPVC(clusterReconciler, context.TODO(), cluster, *pvc)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(needsRemoval).To(BeTrue())
 		})
@@ -296,7 +342,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 		It("should not need a removal", func() {
 			pvc, err := internal.GetPvc(cluster, fdbtypes.ProcessClassStorage, 1)
 			Expect(err).NotTo(HaveOccurred())
-			needsRemoval, err := instanceNeedsRemovalForPVC(cluster, *pvc)
+			needsRemoval, err := instanceNeedsRemovalForPVC(clusterReconciler, context.TODO(), cluster, *pvc)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(needsRemoval).To(BeFalse())
 		})
@@ -307,12 +353,85 @@ var _ = Describe("replace_misconfigured_pods", func() {
 			pvc, err := internal.GetPvc(cluster, fdbtypes.ProcessClassStorage, 1)
 			Expect(err).NotTo(HaveOccurred())
 			pvc.Annotations[fdbtypes.LastSpecKey] = "1"
-			needsRemoval, err := instanceNeedsRemovalForPVC(cluster, *pvc)
+			needsRemoval, err := instanceNeedsRemovalForPVC(clusterReconciler, context.TODO(), cluster, *pvc)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(needsRemoval).To(BeTrue())
 		})
 	})
 
+	When("the requested storage of the PVC is increased", func() {
+		var pvc *corev1.PersistentVolumeClaim
+		var allowVolumeExpansion bool
+
+		BeforeEach(func() {
+			storageClassName := "fdb-storage"
+			cluster.Spec.Processes[fdbtypes.ProcessClassGeneral] = fdbtypes.ProcessSettings{
+				PodTemplate: &corev1.PodTemplateSpec{},
+				VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						StorageClassName: &storageClassName,
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceStorage: resource.MustParse("128G"),
+							},
+						},
+					},
+				},
+			}
+
+			pvc, err = internal.GetPvc(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.Processes[fdbtypes.ProcessClassGeneral].VolumeClaimTemplate.Spec.Resources.Requests[corev1.ResourceStorage] = resource.MustParse("256G")
+		})
+
+		JustBeforeEach(func() {
+			storageClass := &storagev1.StorageClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: "fdb-storage"},
+				AllowVolumeExpansion: &allowVolumeExpansion,
+			}
+			err = k8sClient.Create(context.TODO(), storageClass)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("the storage class allows volume expansion", func() {
+			BeforeEach(func() {
+				allowVolumeExpansion = true
+			})
+
+			It("should not need a removal", func() {
+				needsRemoval, err := instanceNeedsRemovalForPVC(clusterReconciler, context.TODO(), cluster, *pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(needsRemoval).To(BeFalse())
+			})
+
+			When("the storage class of the PVC is changed as well", func() {
+				BeforeEach(func() {
+					otherStorageClassName := "other-storage"
+					cluster.Spec.Processes[fdbtypes.ProcessClassGeneral].VolumeClaimTemplate.Spec.StorageClassName = &otherStorageClassName
+				})
+
+				It("should need a removal", func() {
+					needsRemoval, err := instanceNeedsRemovalForPVC(clusterReconciler, context.TODO(), cluster, *pvc)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(needsRemoval).To(BeTrue())
+				})
+			})
+		})
+
+		When("the storage class does not allow volume expansion", func() {
+			BeforeEach(func() {
+				allowVolumeExpansion = false
+			})
+
+			It("should need a removal", func() {
+				needsRemoval, err := instanceNeedsRemovalForPVC(clusterReconciler, context.TODO(), cluster, *pvc)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(needsRemoval).To(BeTrue())
+			})
+		})
+	})
+
 	Context("when the memory resources are changed", func() {
 		var status *fdbtypes.ProcessGroupStatus
 		var pod *corev1.Pod
@@ -327,7 +446,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				Remove:         false,
 			}
 
-			needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+			needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 			Expect(needsRemoval).To(BeFalse())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -350,7 +469,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeTrue())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -368,7 +487,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should not need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeFalse())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -386,7 +505,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeTrue())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -404,7 +523,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should not need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeFalse())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -425,7 +544,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeTrue())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -450,7 +569,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should not need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeFalse())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -468,7 +587,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should not need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeFalse())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -486,7 +605,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should not need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeFalse())
 					Expect(err).NotTo(HaveOccurred())
 				})
@@ -504,7 +623,7 @@ var _ = Describe("replace_misconfigured_pods", func() {
 				})
 
 				It("should not need a removal", func() {
-					needsRemoval, err := instanceNeedsRemoval(cluster, pod, status)
+					needsRemoval, err := instanceNeedsRemoval(context.TODO(), cluster, pod, status)
 					Expect(needsRemoval).To(BeFalse())
 					Expect(err).NotTo(HaveOccurred())
 				})
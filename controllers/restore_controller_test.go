@@ -28,6 +28,7 @@ import (
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"golang.org/x/net/context"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 func reloadRestore(backup *fdbtypes.FoundationDBRestore) error {
@@ -79,10 +80,79 @@ var _ = Describe("restore_controller", func() {
 
 		Context("when reconciling a new restore", func() {
 			It("should start a restore", func() {
-				status, err := adminClient.GetRestoreStatus()
+				status, err := adminClient.GetRestoreStatus(context.TODO())
 				Expect(err).NotTo(HaveOccurred())
 				Expect(status).To(Equal("blobstore://test@test-service/test-backup?bucket=fdb-backups\n"))
 			})
+
+			It("should restore to the latest version", func() {
+				Expect(adminClient.restoreTargetVersion).To(BeNil())
+				Expect(restore.Status.TargetVersion).To(BeNil())
+			})
+		})
+	})
+
+	Describe("Reconciliation with a target version", func() {
+		var result reconcile.Result
+
+		BeforeEach(func() {
+			err = k8sClient.Create(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := reconcileCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+
+			adminClient.backupDescriptions = map[string]fdbtypes.FoundationDBBackupDescription{
+				restore.Spec.BackupURL: {
+					URL:                restore.Spec.BackupURL,
+					Restorable:         true,
+					MinRestorablePoint: &fdbtypes.FoundationDBBackupDescriptionVersion{Version: 1000},
+					MaxRestorablePoint: &fdbtypes.FoundationDBBackupDescriptionVersion{Version: 2000},
+				},
+			}
+		})
+
+		JustBeforeEach(func() {
+			err = k8sClient.Create(context.TODO(), restore)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err = reconcileObject(restoreReconciler, restore.ObjectMeta, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = reloadRestore(restore)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("the target version is restorable", func() {
+			BeforeEach(func() {
+				version := int64(1500)
+				restore.Spec.TargetVersion = &version
+			})
+
+			It("should start a restore at the target version", func() {
+				Expect(result.Requeue).To(BeFalse())
+				Expect(restore.Status.Running).To(BeTrue())
+				Expect(adminClient.restoreTargetVersion).To(Equal(restore.Spec.TargetVersion))
+				Expect(restore.Status.TargetVersion).To(Equal(restore.Spec.TargetVersion))
+			})
+		})
+
+		When("the target version is not restorable", func() {
+			BeforeEach(func() {
+				version := int64(2500)
+				restore.Spec.TargetVersion = &version
+			})
+
+			It("should not start a restore", func() {
+				Expect(result.Requeue).To(BeTrue())
+				Expect(restore.Status.Running).To(BeFalse())
+				Expect(restore.Status.Message).To(Equal("version 2500 is not restorable, the restorable versions in the backup are 1000 to 2000"))
+
+				status, err := adminClient.GetRestoreStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status).To(Equal("\n"))
+			})
 		})
 	})
 })
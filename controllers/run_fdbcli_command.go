@@ -0,0 +1,114 @@
+/*
+ * run_fdbcli_command.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// maxFdbcliOutputLength is the maximum length of the command output that is
+// stored in the config map, to stay well below the size limit for config maps.
+const maxFdbcliOutputLength = 512 * 1024
+
+// runFdbcliCommand provides a reconciliation step for running a one-off
+// fdbcli command that is requested through an annotation on the cluster.
+type runFdbcliCommand struct{}
+
+// reconcile runs the reconciler's work.
+func (runFdbcliCommand) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	command := strings.TrimSpace(cluster.Annotations[fdbtypes.FdbcliCommandAnnotation])
+	if command == "" {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "runFdbcliCommand")
+
+	// The command is only run once, so we remove the annotation before the
+	// command runs. Otherwise a failure to record the result would run the
+	// command again in the next reconciliation.
+	err := internal.RemoveAnnotation(r, context, cluster, fdbtypes.FdbcliCommandAnnotation)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	var output string
+	var commandErr error
+	if cluster.GetAllowFdbcliCommands() {
+		logger.Info("Running fdbcli command", "command", command)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "RunningFdbcliCommand", fmt.Sprintf("Running fdbcli command: %s", command))
+
+		adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+		defer adminClient.Close()
+
+		output, commandErr = adminClient.RunCommand(context, command)
+	} else {
+		logger.Info("Rejecting fdbcli command because fdbcli commands are disabled", "command", command)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "FdbcliCommandRejected",
+			fmt.Sprintf("fdbcli commands are disabled, not running command: %s", command))
+		commandErr = fmt.Errorf("fdbcli commands are disabled for this cluster")
+	}
+
+	err = internal.ApplyObject(r, context, getFdbcliOutputConfigMap(cluster, command, output, commandErr))
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
+
+// getFdbcliOutputConfigMap builds the config map that stores the result of
+// the last fdbcli command that was requested for the cluster.
+func getFdbcliOutputConfigMap(cluster *fdbtypes.FoundationDBCluster, command string, output string, commandErr error) *corev1.ConfigMap {
+	metadata := internal.GetObjectMetadata(cluster, nil, "", "")
+	metadata.Name = fmt.Sprintf("%s-fdbcli-output", cluster.Name)
+	metadata.OwnerReferences = internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+
+	if len(output) > maxFdbcliOutputLength {
+		output = output[:maxFdbcliOutputLength] + "..."
+	}
+
+	errorMessage := ""
+	if commandErr != nil {
+		errorMessage = commandErr.Error()
+	}
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metadata,
+		Data: map[string]string{
+			"command":   command,
+			"output":    output,
+			"error":     errorMessage,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+}
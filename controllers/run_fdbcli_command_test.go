@@ -0,0 +1,175 @@
+/*
+ * run_fdbcli_command_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("run_fdbcli_command", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+	configMapName := types.NamespacedName{Namespace: "my-ns", Name: "operator-test-1-fdbcli-output"}
+
+	getConfigMap := func() (*corev1.ConfigMap, error) {
+		configMap := &corev1.ConfigMap{}
+		err := k8sClient.Get(context.TODO(), configMapName, configMap)
+		return configMap, err
+	}
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+		adminClient.MockCommandOutput("status minimal", "The database is available.")
+
+		cluster.Spec.AutomationOptions.AllowFdbcliCommands = pointer.Bool(true)
+	})
+
+	JustBeforeEach(func() {
+		requeue = runFdbcliCommand{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	Context("without a command", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not run any commands", func() {
+			Expect(adminClient.ExecutedCommands).To(BeEmpty())
+		})
+
+		It("should not create the output config map", func() {
+			_, err = getConfigMap()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("with a command", func() {
+		BeforeEach(func() {
+			cluster.Annotations = map[string]string{
+				fdbtypes.FdbcliCommandAnnotation: "status minimal",
+			}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should run the command", func() {
+			Expect(adminClient.ExecutedCommands).To(Equal([]string{"status minimal"}))
+		})
+
+		It("should store the output in the config map", func() {
+			configMap, err := getConfigMap()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configMap.Data["command"]).To(Equal("status minimal"))
+			Expect(configMap.Data["output"]).To(Equal("The database is available."))
+			Expect(configMap.Data["error"]).To(BeEmpty())
+			Expect(configMap.Data["timestamp"]).NotTo(BeEmpty())
+		})
+
+		It("should remove the annotation", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Annotations).NotTo(HaveKey(fdbtypes.FdbcliCommandAnnotation))
+		})
+
+		It("should not persist the normalized spec", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Spec.AutomationOptions.AllowFdbcliCommands).To(BeNil())
+		})
+
+		It("should not run the command again in the next reconciliation", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			cluster.Spec.AutomationOptions.AllowFdbcliCommands = pointer.Bool(true)
+
+			requeue = runFdbcliCommand{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			Expect(requeue).To(BeNil())
+			Expect(adminClient.ExecutedCommands).To(Equal([]string{"status minimal"}))
+		})
+
+		Context("with a failing command", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("RunCommand", 1)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should store the error in the config map", func() {
+				configMap, err := getConfigMap()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Data["output"]).To(BeEmpty())
+				Expect(configMap.Data["error"]).To(Equal("mocked error in RunCommand"))
+			})
+		})
+
+		Context("with fdbcli commands disabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.AllowFdbcliCommands = nil
+			})
+
+			It("should not run the command", func() {
+				Expect(adminClient.ExecutedCommands).To(BeEmpty())
+			})
+
+			It("should store the rejection in the config map", func() {
+				configMap, err := getConfigMap()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Data["command"]).To(Equal("status minimal"))
+				Expect(configMap.Data["error"]).To(Equal("fdbcli commands are disabled for this cluster"))
+			})
+
+			It("should remove the annotation", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Annotations).NotTo(HaveKey(fdbtypes.FdbcliCommandAnnotation))
+			})
+		})
+	})
+})
@@ -0,0 +1,187 @@
+/*
+ * run_one_time_operations.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// runOneTimeOperations provides a reconciliation step for running one-time
+// operations that are requested through annotations on the cluster.
+type runOneTimeOperations struct{}
+
+// reconcile runs the reconciler's work.
+func (runOneTimeOperations) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "runOneTimeOperations")
+
+	if value, present := cluster.Annotations[fdbtypes.ReplaceProcessGroupsAnnotation]; present {
+		req := replaceProcessGroupsFromAnnotation(r, context, cluster, parseProcessGroupList(value), logger)
+		if req != nil {
+			return req
+		}
+	}
+
+	if value, present := cluster.Annotations[fdbtypes.RestartProcessesAnnotation]; present {
+		req := restartProcessesFromAnnotation(r, context, cluster, strings.TrimSpace(value), logger)
+		if req != nil {
+			return req
+		}
+	}
+
+	return nil
+}
+
+// parseProcessGroupList parses a comma-separated list of process group IDs
+// from an annotation value.
+func parseProcessGroupList(value string) []string {
+	var processGroupIDs []string
+	for _, processGroupID := range strings.Split(value, ",") {
+		processGroupID = strings.TrimSpace(processGroupID)
+		if processGroupID != "" {
+			processGroupIDs = append(processGroupIDs, processGroupID)
+		}
+	}
+
+	return processGroupIDs
+}
+
+// replaceProcessGroupsFromAnnotation marks the process groups for removal, so
+// they are replaced through the same steps as any other replacement.
+func replaceProcessGroupsFromAnnotation(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, processGroupIDs []string, logger logr.Logger) *requeue {
+	var marked []string
+	var unknown []string
+
+	for _, processGroupID := range processGroupIDs {
+		processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, processGroupID)
+		if processGroup == nil {
+			unknown = append(unknown, processGroupID)
+			continue
+		}
+
+		if processGroup.Remove {
+			continue
+		}
+
+		processGroup.Remove = true
+		marked = append(marked, processGroupID)
+	}
+
+	if len(unknown) > 0 {
+		logger.Info("Ignoring unknown process groups", "processGroupIDs", unknown)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "ProcessGroupsNotFound", fmt.Sprintf("Ignoring unknown process groups: %v", unknown))
+	}
+
+	if len(marked) > 0 {
+		logger.Info("Replacing process groups", "processGroupIDs", marked)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ReplacingProcessGroups", fmt.Sprintf("Replacing process groups: %v", marked))
+
+		err := r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return clearOneTimeOperation(r, context, cluster, fdbtypes.ReplaceProcessGroupsAnnotation)
+}
+
+// restartProcessesFromAnnotation marks the requested process groups, or all
+// process groups when the value is RestartAllProcesses, with the
+// RestartRequested condition. The processes are then restarted by the
+// bounceProcesses reconciler, with the same checks as any other bounce.
+//
+// Marking a process group again has no effect, so the annotation is only
+// removed after the status is updated.
+func restartProcessesFromAnnotation(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, value string, logger logr.Logger) *requeue {
+	enabled := cluster.Spec.AutomationOptions.KillProcesses
+	if enabled != nil && !*enabled {
+		logger.Info("Rejecting restart because killing processes is disabled")
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "RestartRejected", "Killing processes is disabled, not restarting processes")
+		return clearOneTimeOperation(r, context, cluster, fdbtypes.RestartProcessesAnnotation)
+	}
+
+	restartAll := value == fdbtypes.RestartAllProcesses
+	var processGroupIDs []string
+	if restartAll {
+		for _, processGroup := range cluster.Status.ProcessGroups {
+			processGroupIDs = append(processGroupIDs, processGroup.ProcessGroupID)
+		}
+	} else {
+		processGroupIDs = parseProcessGroupList(value)
+	}
+
+	var marked []string
+	var unknown []string
+	for _, processGroupID := range processGroupIDs {
+		processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, processGroupID)
+		if processGroup == nil {
+			unknown = append(unknown, processGroupID)
+			continue
+		}
+
+		if processGroup.Remove {
+			continue
+		}
+
+		processGroup.UpdateCondition(fdbtypes.RestartRequested, true, cluster.Status.ProcessGroups, processGroupID)
+		marked = append(marked, processGroupID)
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		logger.Info("Ignoring unknown process groups", "processGroupIDs", unknown)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "ProcessGroupsNotFound", fmt.Sprintf("Ignoring unknown process groups: %v", unknown))
+	}
+
+	if len(marked) > 0 {
+		logger.Info("Requesting restart of process groups", "processGroupIDs", marked)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "RestartingProcesses", fmt.Sprintf("Requesting restart of process groups: %v", marked))
+
+		err := r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return clearOneTimeOperation(r, context, cluster, fdbtypes.RestartProcessesAnnotation)
+}
+
+// clearOneTimeOperation removes the annotation for a one-time operation from
+// the cluster, so the operation is not run again.
+//
+// This only patches the annotation, so the normalized spec is not written
+// back to the cluster.
+func clearOneTimeOperation(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, annotation string) *requeue {
+	err := internal.RemoveAnnotation(r, context, cluster, annotation)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
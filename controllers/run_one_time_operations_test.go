@@ -0,0 +1,249 @@
+/*
+ * run_one_time_operations_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("run_one_time_operations", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = runOneTimeOperations{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	Context("without any annotations", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not kill any processes", func() {
+			Expect(adminClient.KilledAddresses).To(BeEmpty())
+		})
+
+		It("should not mark any process groups for removal", func() {
+			for _, processGroup := range cluster.Status.ProcessGroups {
+				Expect(processGroup.Remove).To(BeFalse())
+			}
+		})
+	})
+
+	When("replacing process groups", func() {
+		BeforeEach(func() {
+			cluster.Annotations = map[string]string{
+				fdbtypes.ReplaceProcessGroupsAnnotation: "storage-1, log-1,unknown-1",
+			}
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.AutomationOptions.KillProcesses = pointer.Bool(true)
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should mark the known process groups for removal", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			removals := make([]string, 0, 2)
+			for _, processGroup := range cluster.Status.ProcessGroups {
+				if processGroup.Remove {
+					removals = append(removals, processGroup.ProcessGroupID)
+				}
+			}
+			Expect(removals).To(ConsistOf("storage-1", "log-1"))
+		})
+
+		It("should remove the annotation", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Annotations).NotTo(HaveKey(fdbtypes.ReplaceProcessGroupsAnnotation))
+		})
+
+		It("should not persist the normalized spec", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Spec.AutomationOptions.KillProcesses).To(BeNil())
+		})
+	})
+
+	When("restarting all processes", func() {
+		BeforeEach(func() {
+			cluster.Annotations = map[string]string{
+				fdbtypes.RestartProcessesAnnotation: fdbtypes.RestartAllProcesses,
+			}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not kill any processes directly", func() {
+			Expect(adminClient.KilledAddresses).To(BeEmpty())
+		})
+
+		It("should request a restart of all process groups", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.RestartRequested, false)).To(HaveLen(len(cluster.Status.ProcessGroups)))
+		})
+
+		It("should remove the annotation", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Annotations).NotTo(HaveKey(fdbtypes.RestartProcessesAnnotation))
+		})
+
+		When("the bounce runs", func() {
+			JustBeforeEach(func() {
+				requeue = bounceProcesses{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should kill all processes", func() {
+				Expect(requeue).To(BeNil())
+				status, err := adminClient.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(adminClient.KilledAddresses).To(HaveLen(len(status.Cluster.Processes)))
+			})
+
+			It("should clear the restart requests", func() {
+				Expect(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.RestartRequested, false)).To(BeEmpty())
+			})
+		})
+	})
+
+	When("restarting specific process groups", func() {
+		BeforeEach(func() {
+			cluster.Annotations = map[string]string{
+				fdbtypes.RestartProcessesAnnotation: "storage-1,unknown-1",
+			}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should only request a restart of the known process groups", func() {
+			Expect(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.RestartRequested, false)).To(ConsistOf("storage-1"))
+			Expect(adminClient.KilledAddresses).To(BeEmpty())
+		})
+
+		It("should remove the annotation", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Annotations).NotTo(HaveKey(fdbtypes.RestartProcessesAnnotation))
+		})
+
+		When("the annotation is handled again", func() {
+			JustBeforeEach(func() {
+				requeue = runOneTimeOperations{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should only request the restart once", func() {
+				Expect(requeue).To(BeNil())
+				processGroup := fdbtypes.FindProcessGroupByID(cluster.Status.ProcessGroups, "storage-1")
+				Expect(processGroup.ProcessGroupConditions).To(HaveLen(1))
+			})
+		})
+
+		When("the bounce runs", func() {
+			JustBeforeEach(func() {
+				requeue = bounceProcesses{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should only kill the processes of the process groups", func() {
+				status, err := adminClient.GetStatus(context.TODO())
+				Expect(err).NotTo(HaveOccurred())
+
+				var addresses []string
+				for _, process := range status.Cluster.Processes {
+					if process.Locality["instance_id"] == "storage-1" {
+						addresses = append(addresses, process.Address.String())
+					}
+				}
+				Expect(addresses).To(HaveLen(1))
+				Expect(adminClient.KilledAddresses).To(Equal(addresses))
+			})
+
+			When("the cluster has not been up for the minimum uptime", func() {
+				BeforeEach(func() {
+					cluster.Spec.MinimumUptimeSecondsForBounce = 100000
+				})
+
+				It("should not kill any processes", func() {
+					Expect(requeue).NotTo(BeNil())
+					Expect(adminClient.KilledAddresses).To(BeEmpty())
+					Expect(fdbtypes.FilterByCondition(cluster.Status.ProcessGroups, fdbtypes.RestartRequested, false)).To(ConsistOf("storage-1"))
+				})
+			})
+		})
+
+		When("killing processes is disabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.AutomationOptions.KillProcesses = pointer.Bool(false)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should not kill any processes", func() {
+				Expect(adminClient.KilledAddresses).To(BeEmpty())
+			})
+
+			It("should remove the annotation", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Annotations).NotTo(HaveKey(fdbtypes.RestartProcessesAnnotation))
+			})
+		})
+	})
+})
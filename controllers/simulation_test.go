@@ -0,0 +1,401 @@
+/*
+ * simulation_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// simulationResult describes the outcome of a simulated reconciliation.
+type simulationResult struct {
+	// Rounds provides the number of reconciliation rounds that were run.
+	Rounds int
+
+	// Reconciled indicates whether the cluster was fully reconciled.
+	Reconciled bool
+
+	// Errors provides the errors that were returned by the reconciler.
+	Errors []error
+}
+
+// simulateReconciliation drives the cluster reconciler until the latest
+// generation of the cluster is fully reconciled, or until the number of rounds
+// is exhausted. Errors from the reconciler are recorded instead of aborting
+// the simulation, so that failures injected through the mock admin client can
+// be retried the same way the controller manager would retry them.
+func simulateReconciliation(cluster *fdbtypes.FoundationDBCluster, maxRounds int) (simulationResult, error) {
+	result := simulationResult{}
+
+	for result.Rounds < maxRounds {
+		result.Rounds++
+
+		reconcileResult, reconcileErr := reconcileObject(clusterReconciler, cluster.ObjectMeta, 0)
+		if reconcileErr != nil {
+			result.Errors = append(result.Errors, reconcileErr)
+		}
+
+		generations, err := reloadClusterGenerations(cluster)
+		if err != nil {
+			return result, err
+		}
+
+		if reconcileErr == nil && !reconcileResult.Requeue && generations.Reconciled == cluster.ObjectMeta.Generation && generations.HasPendingRemoval == 0 {
+			result.Reconciled = true
+			return result, nil
+		}
+	}
+
+	return result, nil
+}
+
+var _ = Describe("simulation", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var result simulationResult
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	// expectErrors checks that every error from the simulation is one of
+	// the injected errors, so other failures in the reconciler do not go
+	// unnoticed.
+	expectErrors := func(count int, message string) {
+		Expect(result.Errors).To(HaveLen(count))
+		for _, simulationErr := range result.Errors {
+			Expect(simulationErr).To(MatchError(ContainSubstring(message)))
+		}
+	}
+
+	getPods := func() []corev1.Pod {
+		pods := &corev1.PodList{}
+		err := k8sClient.List(context.TODO(), pods, getListOptions(cluster)...)
+		Expect(err).NotTo(HaveOccurred())
+		return pods.Items
+	}
+
+	When("shrinking the cluster", func() {
+		BeforeEach(func() {
+			cluster.Spec.ProcessCounts.Storage = 3
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("with failing exclusions", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("ExcludeInstances", 2)
+				result, err = simulateReconciliation(cluster, 10)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeTrue())
+				expectErrors(2, "mocked error in ExcludeInstances")
+			})
+
+			It("should remove the pod", func() {
+				Expect(getProcessClassMap(cluster, getPods())[fdbtypes.ProcessClassStorage]).To(Equal(3))
+			})
+
+			It("should re-include the removed process", func() {
+				Expect(adminClient.ExcludedAddresses).To(BeNil())
+				Expect(adminClient.ReincludedAddresses).To(HaveLen(1))
+			})
+		})
+
+		Context("with timeouts on exclusions", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandTimeout("ExcludeInstances", 2)
+				result, err = simulateReconciliation(cluster, 10)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeTrue())
+				expectErrors(2, "mocked timeout in ExcludeInstances")
+			})
+
+			It("should remove the pod", func() {
+				Expect(getProcessClassMap(cluster, getPods())[fdbtypes.ProcessClassStorage]).To(Equal(3))
+			})
+		})
+
+		Context("with data movement", func() {
+			BeforeEach(func() {
+				adminClient.MockDataMovement(3)
+				result, err = simulateReconciliation(cluster, 3)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not reconcile the cluster while the data is moving", func() {
+				Expect(result.Reconciled).To(BeFalse())
+				Expect(result.Errors).To(BeEmpty())
+			})
+
+			It("should keep the pod while the data is moving", func() {
+				Expect(getProcessClassMap(cluster, getPods())[fdbtypes.ProcessClassStorage]).To(Equal(4))
+			})
+
+			It("should keep the process excluded while the data is moving", func() {
+				Expect(adminClient.ExcludedAddresses).To(HaveLen(1))
+			})
+
+			When("the data movement completes", func() {
+				BeforeEach(func() {
+					result, err = simulateReconciliation(cluster, 10)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should reconcile the cluster", func() {
+					Expect(result.Reconciled).To(BeTrue())
+					Expect(result.Errors).To(BeEmpty())
+				})
+
+				It("should remove the pod", func() {
+					Expect(getProcessClassMap(cluster, getPods())[fdbtypes.ProcessClassStorage]).To(Equal(3))
+				})
+
+				It("should re-include the removed process", func() {
+					Expect(adminClient.ExcludedAddresses).To(BeNil())
+					Expect(adminClient.ReincludedAddresses).To(HaveLen(1))
+				})
+			})
+		})
+
+		Context("with stuck exclusions", func() {
+			BeforeEach(func() {
+				adminClient.MockStuckExclusions(true)
+				result, err = simulateReconciliation(cluster, 5)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeFalse())
+				Expect(result.Rounds).To(Equal(5))
+				Expect(result.Errors).To(BeEmpty())
+			})
+
+			It("should keep the pod", func() {
+				Expect(getProcessClassMap(cluster, getPods())[fdbtypes.ProcessClassStorage]).To(Equal(4))
+			})
+
+			It("should keep the process excluded", func() {
+				Expect(adminClient.ExcludedAddresses).To(HaveLen(1))
+			})
+
+			When("the exclusions complete", func() {
+				BeforeEach(func() {
+					adminClient.MockStuckExclusions(false)
+					result, err = simulateReconciliation(cluster, 10)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should reconcile the cluster", func() {
+					Expect(result.Reconciled).To(BeTrue())
+					Expect(result.Errors).To(BeEmpty())
+				})
+
+				It("should remove the pod", func() {
+					Expect(getProcessClassMap(cluster, getPods())[fdbtypes.ProcessClassStorage]).To(Equal(3))
+				})
+			})
+		})
+	})
+
+	When("upgrading the cluster", func() {
+		BeforeEach(func() {
+			cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("with failing kills", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("KillInstances", 2)
+				result, err = simulateReconciliation(cluster, 10)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeTrue())
+				expectErrors(2, "mocked error in KillInstances")
+			})
+
+			It("should update the running version", func() {
+				Expect(cluster.Status.RunningVersion).To(Equal(fdbtypes.Versions.NextMajorVersion.String()))
+			})
+
+			It("should update the image on the pods", func() {
+				for _, pod := range getPods() {
+					Expect(pod.Spec.Containers[0].Image).To(Equal(fmt.Sprintf("foundationdb/foundationdb:%s", fdbtypes.Versions.NextMajorVersion.String())))
+				}
+			})
+		})
+
+		Context("with a failing status", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("GetStatus", 3)
+				result, err = simulateReconciliation(cluster, 10)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeTrue())
+				expectErrors(3, "mocked error in GetStatus")
+			})
+
+			It("should update the running version", func() {
+				Expect(cluster.Status.RunningVersion).To(Equal(fdbtypes.Versions.NextMajorVersion.String()))
+			})
+		})
+	})
+
+	When("replacing a coordinator", func() {
+		var originalConnectionString string
+		var replacedProcessGroup string
+
+		BeforeEach(func() {
+			originalConnectionString = cluster.Status.ConnectionString
+
+			connectionString, err := fdbtypes.ParseConnectionString(originalConnectionString)
+			Expect(err).NotTo(HaveOccurred())
+			coordinator, err := fdbtypes.ParseProcessAddress(connectionString.Coordinators[0])
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, pod := range getPods() {
+				if pod.Status.PodIP == coordinator.IPAddress.String() {
+					replacedProcessGroup = pod.Labels[fdbtypes.FDBProcessGroupIDLabel]
+					break
+				}
+			}
+			Expect(replacedProcessGroup).NotTo(BeEmpty())
+
+			cluster.Spec.InstancesToRemove = []string{replacedProcessGroup}
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		Context("with a failing coordinator change", func() {
+			BeforeEach(func() {
+				adminClient.MockCommandError("ChangeCoordinators", 1)
+				result, err = simulateReconciliation(cluster, 10)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeTrue())
+				expectErrors(1, "mocked error in ChangeCoordinators")
+			})
+
+			It("should change the connection string", func() {
+				Expect(cluster.Status.ConnectionString).NotTo(Equal(originalConnectionString))
+			})
+
+			It("should remove the process group", func() {
+				for _, pod := range getPods() {
+					Expect(pod.Labels[fdbtypes.FDBProcessGroupIDLabel]).NotTo(Equal(replacedProcessGroup))
+				}
+			})
+		})
+
+		Context("with stale coordinators", func() {
+			BeforeEach(func() {
+				adminClient.MockStaleCoordinators(originalConnectionString)
+				result, err = simulateReconciliation(cluster, 5)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeFalse())
+				Expect(result.Errors).To(BeEmpty())
+			})
+
+			It("should change the connection string in the cluster status", func() {
+				Expect(cluster.Status.ConnectionString).NotTo(Equal(originalConnectionString))
+			})
+
+			When("the coordinators are up to date", func() {
+				BeforeEach(func() {
+					adminClient.MockStaleCoordinators("")
+					result, err = simulateReconciliation(cluster, 10)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should reconcile the cluster", func() {
+					Expect(result.Reconciled).To(BeTrue())
+					Expect(result.Errors).To(BeEmpty())
+				})
+
+				It("should remove the process group", func() {
+					for _, pod := range getPods() {
+						Expect(pod.Labels[fdbtypes.FDBProcessGroupIDLabel]).NotTo(Equal(replacedProcessGroup))
+					}
+				})
+			})
+		})
+
+		Context("with a frozen status", func() {
+			BeforeEach(func() {
+				err = adminClient.MockFrozenStatus()
+				Expect(err).NotTo(HaveOccurred())
+				result, err = simulateReconciliation(cluster, 5)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not reconcile the cluster", func() {
+				Expect(result.Reconciled).To(BeFalse())
+				Expect(result.Errors).To(BeEmpty())
+			})
+
+			When("the status is unfrozen", func() {
+				BeforeEach(func() {
+					adminClient.UnfreezeStatus()
+					result, err = simulateReconciliation(cluster, 10)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should reconcile the cluster", func() {
+					Expect(result.Reconciled).To(BeTrue())
+					Expect(result.Errors).To(BeEmpty())
+				})
+
+				It("should change the connection string", func() {
+					Expect(cluster.Status.ConnectionString).NotTo(Equal(originalConnectionString))
+				})
+			})
+		})
+	})
+})
@@ -32,7 +32,7 @@ type startBackup struct {
 
 // reconcile runs the reconciler's work.
 func (s startBackup) reconcile(r *FoundationDBBackupReconciler, context ctx.Context, backup *fdbtypes.FoundationDBBackup) *requeue {
-	if !backup.ShouldRun() || (backup.Status.BackupDetails != nil && backup.Status.BackupDetails.Running) {
+	if !backup.NeedsStart() {
 		return nil
 	}
 
@@ -42,7 +42,7 @@ func (s startBackup) reconcile(r *FoundationDBBackupReconciler, context ctx.Cont
 	}
 	defer adminClient.Close()
 
-	err = adminClient.StartBackup(backup.BackupURL(), backup.SnapshotPeriodSeconds())
+	err = adminClient.StartBackup(context, backup.BackupURL(), backup.SnapshotPeriodSeconds(), backup.GetBackupMode())
 	if err != nil {
 		return &requeue{curError: err}
 	}
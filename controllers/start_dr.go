@@ -0,0 +1,51 @@
+/*
+ * start_dr.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// startDR provides a reconciliation step for starting a new DR replication.
+type startDR struct {
+}
+
+// reconcile runs the reconciler's work.
+func (s startDR) reconcile(r *FoundationDBDRReconciler, context ctx.Context, dr *fdbtypes.FoundationDBDR) *requeue {
+	if dr.Status.DRDetails != nil && dr.Status.DRDetails.Running {
+		return nil
+	}
+
+	adminClient, sourceConnectionString, err := r.adminClientForDR(context, dr, dr.Spec.SourceClusterName, dr.Spec.DestinationClusterName)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	err = adminClient.StartDR(context, sourceConnectionString, dr.DRTag())
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
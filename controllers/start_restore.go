@@ -23,8 +23,10 @@ package controllers
 import (
 	ctx "context"
 	"strings"
+	"time"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // startRestore provides a reconciliation step for starting a new restore.
@@ -39,18 +41,44 @@ func (s startRestore) reconcile(r *FoundationDBRestoreReconciler, context ctx.Co
 	}
 	defer adminClient.Close()
 
-	status, err := adminClient.GetRestoreStatus()
+	status, err := adminClient.GetRestoreStatus(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
 
 	if len(strings.TrimSpace(status)) == 0 {
-		err = adminClient.StartRestore(restore.Spec.BackupURL, restore.Spec.KeyRanges)
+		var targetVersion *int64
+		if restore.HasTarget() {
+			description, err := adminClient.DescribeBackup(context, restore.Spec.BackupURL)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			version, err := restore.GetTargetVersion(description)
+			if err != nil {
+				// The backup can still become restorable at the target if it
+				// is running, so we check it again later.
+				r.Recorder.Event(restore, corev1.EventTypeWarning, "InvalidRestoreTarget", err.Error())
+				if restore.Status.Message != err.Error() {
+					restore.Status.Message = err.Error()
+					updateErr := r.Status().Update(context, restore)
+					if updateErr != nil {
+						return &requeue{curError: updateErr}
+					}
+				}
+				return &requeue{message: err.Error(), delay: time.Minute}
+			}
+			targetVersion = &version
+		}
+
+		err = adminClient.StartRestore(context, restore.Spec.BackupURL, restore.Spec.KeyRanges, targetVersion)
 		if err != nil {
 			return &requeue{curError: err}
 		}
 
 		restore.Status.Running = true
+		restore.Status.TargetVersion = targetVersion
+		restore.Status.Message = ""
 		err = r.Status().Update(context, restore)
 		if err != nil {
 			return &requeue{curError: err}
@@ -0,0 +1,173 @@
+/*
+ * start_seed_restore.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"strings"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// startSeedRestore provides a reconciliation step for restoring the seed
+// backup into a new cluster.
+type startSeedRestore struct{}
+
+// reconcile runs the reconciler's work.
+func (startSeedRestore) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "startSeedRestore")
+
+	// The backup agents for the restore are removed once the seed backup is
+	// removed from the spec.
+	if cluster.Spec.SeedBackup == nil && cluster.Status.SeedRestore != "" {
+		err := removeSeedBackupAgents(r, context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		return nil
+	}
+
+	// The restore needs a configured database, so we wait until the initial
+	// configuration is done.
+	if !cluster.NeedsSeedRestore() || !cluster.Status.Configured {
+		return nil
+	}
+
+	// The seed backup can only be restored into a new database, because a
+	// restore into a database that was configured without it would overwrite
+	// the existing data.
+	if !cluster.Status.SeedRestorePending {
+		logger.Info("Not restoring seed backup into a database that is already configured")
+		return &requeue{message: "Cannot restore the seed backup into a database that is already configured", delayedRequeue: true}
+	}
+
+	restore := getSeedRestore(cluster)
+	logger.Info("Creating restore for seed backup", "restore", restore.Name, "backupURL", restore.Spec.BackupURL)
+	r.Recorder.Event(cluster, corev1.EventTypeNormal, "StartingSeedRestore", fmt.Sprintf("Restoring backup %s", restore.Spec.BackupURL))
+
+	// The restore is run by backup agents, so the operator runs them through
+	// a stopped backup for the cluster.
+	err := r.Create(context, getSeedBackupAgents(cluster))
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return &requeue{curError: err}
+	}
+
+	err = r.Create(context, restore)
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return &requeue{curError: err}
+	}
+
+	cluster.Status.SeedRestore = restore.Name
+	cluster.Status.SeedRestorePending = false
+	err = r.Status().Update(context, cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
+
+// getSeedRestore builds the restore for the seed backup of a cluster.
+func getSeedRestore(cluster *fdbtypes.FoundationDBCluster) *fdbtypes.FoundationDBRestore {
+	seedBackup := cluster.Spec.SeedBackup.DeepCopy()
+
+	return &fdbtypes.FoundationDBRestore{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getSeedRestoreName(cluster),
+			OwnerReferences: internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta),
+		},
+		Spec: fdbtypes.FoundationDBRestoreSpec{
+			DestinationClusterName: cluster.Name,
+			BackupURL:              seedBackup.BackupURL,
+			KeyRanges:              seedBackup.KeyRanges,
+			TargetVersion:          seedBackup.TargetVersion,
+			TargetTimestamp:        seedBackup.TargetTimestamp,
+		},
+	}
+}
+
+// getSeedBackupAgents builds the backup that runs the backup agents for the
+// restore of the seed backup of a cluster. The backup is stopped, so the
+// agents do not start a backup of the new cluster.
+func getSeedBackupAgents(cluster *fdbtypes.FoundationDBCluster) *fdbtypes.FoundationDBBackup {
+	seedBackup := cluster.Spec.SeedBackup.DeepCopy()
+
+	// The account name is only used to start a backup, so it is taken from
+	// the URL of the seed backup.
+	accountName := strings.SplitN(strings.TrimPrefix(seedBackup.BackupURL, "blobstore://"), "/", 2)[0]
+
+	return &fdbtypes.FoundationDBBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:       cluster.Namespace,
+			Name:            getSeedRestoreName(cluster),
+			OwnerReferences: internal.BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta),
+		},
+		Spec: fdbtypes.FoundationDBBackupSpec{
+			Version:               cluster.Spec.Version,
+			ClusterName:           cluster.Name,
+			BackupState:           fdbtypes.BackupStateStopped,
+			AccountName:           accountName,
+			AgentCount:            seedBackup.AgentCount,
+			PodTemplateSpec:       seedBackup.PodTemplateSpec,
+			BlobCredentialsSecret: seedBackup.BlobCredentialsSecret,
+		},
+	}
+}
+
+// getSeedRestoreName returns the name of the restore for the seed backup. The
+// backup that runs the backup agents for the restore uses the same name.
+func getSeedRestoreName(cluster *fdbtypes.FoundationDBCluster) string {
+	return fmt.Sprintf("%s-seed", cluster.Name)
+}
+
+// removeSeedBackupAgents removes the backup that runs the backup agents for
+// the restore of the seed backup, if the operator created it.
+func removeSeedBackupAgents(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) error {
+	backup := &fdbtypes.FoundationDBBackup{}
+	err := r.Get(context, client.ObjectKey{Namespace: cluster.Namespace, Name: getSeedRestoreName(cluster)}, backup)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !metav1.IsControlledBy(backup, cluster) {
+		return nil
+	}
+
+	getLogger(context, cluster, "startSeedRestore").Info("Removing the backup agents for the seed restore", "backup", backup.Name)
+	err = r.Delete(context, backup)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
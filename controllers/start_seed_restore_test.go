@@ -0,0 +1,187 @@
+/*
+ * start_seed_restore_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("start_seed_restore", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var err error
+	restoreName := types.NamespacedName{Namespace: "my-ns", Name: "operator-test-1-seed"}
+
+	getRestore := func() (*fdbtypes.FoundationDBRestore, error) {
+		restore := &fdbtypes.FoundationDBRestore{}
+		err := k8sClient.Get(context.TODO(), restoreName, restore)
+		return restore, err
+	}
+
+	getBackupAgents := func() (*fdbtypes.FoundationDBBackup, error) {
+		backup := &fdbtypes.FoundationDBBackup{}
+		err := k8sClient.Get(context.TODO(), restoreName, backup)
+		return backup, err
+	}
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+	})
+
+	JustBeforeEach(func() {
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		_, err = reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("without a seed backup", func() {
+		It("should not create a restore", func() {
+			_, err = getRestore()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			Expect(cluster.Status.SeedRestore).To(BeEmpty())
+		})
+	})
+
+	Context("with a seed backup", func() {
+		BeforeEach(func() {
+			version := int64(1000)
+			cluster.Spec.SeedBackup = &fdbtypes.SeedBackup{
+				BackupURL:             "blobstore://test@test-service/production?bucket=fdb-backups",
+				TargetVersion:         &version,
+				AgentCount:            pointer.Int(3),
+				BlobCredentialsSecret: "blob-credentials",
+			}
+		})
+
+		It("should create a restore for the backup", func() {
+			restore, err := getRestore()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(restore.Spec).To(Equal(fdbtypes.FoundationDBRestoreSpec{
+				DestinationClusterName: cluster.Name,
+				BackupURL:              "blobstore://test@test-service/production?bucket=fdb-backups",
+				TargetVersion:          cluster.Spec.SeedBackup.TargetVersion,
+			}))
+			Expect(restore.OwnerReferences).To(HaveLen(1))
+			Expect(restore.OwnerReferences[0].UID).To(Equal(cluster.UID))
+		})
+
+		It("should store the restore in the status", func() {
+			Expect(cluster.Status.SeedRestore).To(Equal("operator-test-1-seed"))
+		})
+
+		It("should create a stopped backup that runs the backup agents", func() {
+			backup, err := getBackupAgents()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(backup.Spec).To(Equal(fdbtypes.FoundationDBBackupSpec{
+				Version:               cluster.Spec.Version,
+				ClusterName:           cluster.Name,
+				BackupState:           fdbtypes.BackupStateStopped,
+				AccountName:           "test@test-service",
+				AgentCount:            pointer.Int(3),
+				BlobCredentialsSecret: "blob-credentials",
+			}))
+			Expect(backup.OwnerReferences).To(HaveLen(1))
+			Expect(backup.OwnerReferences[0].UID).To(Equal(cluster.UID))
+		})
+
+		When("the seed backup is removed from the spec", func() {
+			JustBeforeEach(func() {
+				cluster.Spec.SeedBackup = nil
+				err = k8sClient.Update(context.TODO(), cluster)
+				Expect(err).NotTo(HaveOccurred())
+
+				requeue := startSeedRestore{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should remove the backup agents", func() {
+				_, err = getBackupAgents()
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("should keep the restore", func() {
+				_, err = getRestore()
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		When("the restore is deleted", func() {
+			JustBeforeEach(func() {
+				restore, err := getRestore()
+				Expect(err).NotTo(HaveOccurred())
+				err = k8sClient.Delete(context.TODO(), restore)
+				Expect(err).NotTo(HaveOccurred())
+
+				requeue := startSeedRestore{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should not create the restore again", func() {
+				_, err = getRestore()
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		It("should clear the pending seed restore", func() {
+			Expect(cluster.Status.SeedRestorePending).To(BeFalse())
+		})
+	})
+
+	Context("with a seed backup added to a configured cluster", func() {
+		var requeue *requeue
+
+		JustBeforeEach(func() {
+			cluster.Spec.SeedBackup = &fdbtypes.SeedBackup{
+				BackupURL: "blobstore://test@test-service/production?bucket=fdb-backups",
+			}
+			err = k8sClient.Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			requeue = startSeedRestore{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		})
+
+		It("should reject the seed backup", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.message).To(Equal("Cannot restore the seed backup into a database that is already configured"))
+			Expect(requeue.delayedRequeue).To(BeTrue())
+		})
+
+		It("should not create a restore", func() {
+			_, err = getRestore()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			Expect(cluster.Status.SeedRestore).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,301 @@
+/*
+ * status_cache.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
+)
+
+// statusCacheEntry describes a cached database status.
+type statusCacheEntry struct {
+	status    *fdbtypes.FoundationDBStatus
+	timestamp time.Time
+}
+
+// statusCache caches the database status of the clusters for a short time,
+// so the subreconcilers do not have to fetch the full status every time.
+type statusCache struct {
+	// ttl defines how long a cached status can be used.
+	ttl time.Duration
+
+	// now provides the current time. This can be replaced in tests.
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[string]statusCacheEntry
+}
+
+// get returns the cached status for a cluster, or nil if there is no status
+// that is recent enough.
+func (cache *statusCache) get(key string) *fdbtypes.FoundationDBStatus {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	entry, present := cache.entries[key]
+	if !present || cache.now().Sub(entry.timestamp) >= cache.ttl {
+		return nil
+	}
+
+	return entry.status.DeepCopy()
+}
+
+// set stores the status for a cluster in the cache. This also drops the
+// expired entries, so the cache does not keep the status of clusters that
+// are no longer reconciled.
+func (cache *statusCache) set(key string, status *fdbtypes.FoundationDBStatus) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	now := cache.now()
+	for entryKey, entry := range cache.entries {
+		if now.Sub(entry.timestamp) >= cache.ttl {
+			delete(cache.entries, entryKey)
+		}
+	}
+
+	cache.entries[key] = statusCacheEntry{status: status.DeepCopy(), timestamp: now}
+}
+
+// invalidate removes the cached status for a cluster.
+func (cache *statusCache) invalidate(key string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+
+	delete(cache.entries, key)
+}
+
+// statusCacheInvalidator is implemented by the client providers that cache
+// the database status.
+type statusCacheInvalidator interface {
+	// invalidateClusterStatus removes the cached status for a cluster.
+	invalidateClusterStatus(key client.ObjectKey)
+}
+
+// cachingDatabaseClientProvider provides admin clients that cache the
+// database status.
+type cachingDatabaseClientProvider struct {
+	DatabaseClientProvider
+	cache *statusCache
+}
+
+// invalidateClusterStatus removes the cached status for a cluster.
+func (provider cachingDatabaseClientProvider) invalidateClusterStatus(key client.ObjectKey) {
+	provider.cache.invalidate(key.String())
+}
+
+// NewCachingDatabaseClientProvider creates a provider for admin clients that
+// cache the database status of each cluster for the given TTL. The cached
+// status is invalidated when an admin client changes the database, and when
+// the reconciler adds or removes processes or deletes the cluster. A TTL of zero disables
+// the cache.
+func NewCachingDatabaseClientProvider(provider DatabaseClientProvider, ttl time.Duration) DatabaseClientProvider {
+	if ttl <= 0 {
+		return provider
+	}
+
+	return cachingDatabaseClientProvider{
+		DatabaseClientProvider: provider,
+		cache: &statusCache{
+			ttl:     ttl,
+			now:     time.Now,
+			entries: make(map[string]statusCacheEntry),
+		},
+	}
+}
+
+// GetAdminClient generates a client for performing administrative actions
+// against the database.
+func (provider cachingDatabaseClientProvider) GetAdminClient(cluster *fdbtypes.FoundationDBCluster, kubernetesClient client.Client) (fdbadminclient.AdminClient, error) {
+	adminClient, err := provider.DatabaseClientProvider.GetAdminClient(cluster, kubernetesClient)
+	if err != nil {
+		return nil, err
+	}
+
+	return cachingAdminClient{
+		AdminClient: adminClient,
+		cache:       provider.cache,
+		key:         client.ObjectKeyFromObject(cluster).String(),
+	}, nil
+}
+
+// cachingAdminClient provides an admin client that serves the database status
+// from the cache, and invalidates the cache after changing the database.
+type cachingAdminClient struct {
+	fdbadminclient.AdminClient
+	cache *statusCache
+	key   string
+}
+
+// GetStatus gets the database's status, from the cache if possible.
+func (client cachingAdminClient) GetStatus(ctx context.Context) (*fdbtypes.FoundationDBStatus, error) {
+	status := client.cache.get(client.key)
+	if status != nil {
+		return status, nil
+	}
+
+	status, err := client.AdminClient.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	client.cache.set(client.key, status)
+	return status, nil
+}
+
+// invalidateStatus removes the cached status after a change to the database.
+// The status is invalidated even if the change failed, because a failed
+// command may still have changed the database.
+func (client cachingAdminClient) invalidateStatus(err error) error {
+	client.cache.invalidate(client.key)
+	return err
+}
+
+// ConfigureDatabase sets the database configuration.
+func (client cachingAdminClient) ConfigureDatabase(ctx context.Context, configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error {
+	return client.invalidateStatus(client.AdminClient.ConfigureDatabase(ctx, configuration, newDatabase))
+}
+
+// ExcludeInstances starts evacuating processes so that they can be removed
+// from the database.
+func (client cachingAdminClient) ExcludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
+	return client.invalidateStatus(client.AdminClient.ExcludeInstances(ctx, addresses))
+}
+
+// IncludeInstances removes processes from the exclusion list and allows
+// them to take on roles again.
+func (client cachingAdminClient) IncludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
+	return client.invalidateStatus(client.AdminClient.IncludeInstances(ctx, addresses))
+}
+
+// ExcludeLocalities starts evacuating all processes of the given localities.
+func (client cachingAdminClient) ExcludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error {
+	return client.invalidateStatus(client.AdminClient.ExcludeLocalities(ctx, localities))
+}
+
+// IncludeLocalities removes the given localities from the exclusion list.
+func (client cachingAdminClient) IncludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error {
+	return client.invalidateStatus(client.AdminClient.IncludeLocalities(ctx, localities))
+}
+
+// KillInstances restarts processes
+func (client cachingAdminClient) KillInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
+	return client.invalidateStatus(client.AdminClient.KillInstances(ctx, addresses))
+}
+
+// ChangeCoordinators changes the coordinator set
+func (client cachingAdminClient) ChangeCoordinators(ctx context.Context, addresses []fdbtypes.ProcessAddress) (string, error) {
+	connectionString, err := client.AdminClient.ChangeCoordinators(ctx, addresses)
+	return connectionString, client.invalidateStatus(err)
+}
+
+// StartBackup starts a new backup.
+func (client cachingAdminClient) StartBackup(ctx context.Context, url string, snapshotPeriodSeconds int, mode fdbtypes.BackupMode) error {
+	return client.invalidateStatus(client.AdminClient.StartBackup(ctx, url, snapshotPeriodSeconds, mode))
+}
+
+// StopBackup stops a backup.
+func (client cachingAdminClient) StopBackup(ctx context.Context, url string) error {
+	return client.invalidateStatus(client.AdminClient.StopBackup(ctx, url))
+}
+
+// PauseBackups pauses the backups.
+func (client cachingAdminClient) PauseBackups(ctx context.Context) error {
+	return client.invalidateStatus(client.AdminClient.PauseBackups(ctx))
+}
+
+// ResumeBackups resumes the backups.
+func (client cachingAdminClient) ResumeBackups(ctx context.Context) error {
+	return client.invalidateStatus(client.AdminClient.ResumeBackups(ctx))
+}
+
+// ModifyBackup modifies the configuration of the backup.
+func (client cachingAdminClient) ModifyBackup(ctx context.Context, snapshotPeriodSeconds int) error {
+	return client.invalidateStatus(client.AdminClient.ModifyBackup(ctx, snapshotPeriodSeconds))
+}
+
+// ExpireBackup deletes old data from a backup.
+func (client cachingAdminClient) ExpireBackup(ctx context.Context, url string, policy fdbtypes.BackupExpirationPolicy) error {
+	return client.invalidateStatus(client.AdminClient.ExpireBackup(ctx, url, policy))
+}
+
+// StartRestore starts a new restore.
+func (client cachingAdminClient) StartRestore(ctx context.Context, url string, keyRanges []fdbtypes.FoundationDBKeyRange, targetVersion *int64) error {
+	return client.invalidateStatus(client.AdminClient.StartRestore(ctx, url, keyRanges, targetVersion))
+}
+
+// StartDR starts DR from the source cluster into this cluster.
+func (client cachingAdminClient) StartDR(ctx context.Context, sourceConnectionString string, tag string) error {
+	return client.invalidateStatus(client.AdminClient.StartDR(ctx, sourceConnectionString, tag))
+}
+
+// SwitchDR switches the direction of the DR.
+func (client cachingAdminClient) SwitchDR(ctx context.Context, sourceConnectionString string, tag string) error {
+	return client.invalidateStatus(client.AdminClient.SwitchDR(ctx, sourceConnectionString, tag))
+}
+
+// RunCommand runs an fdbcli command against the database.
+func (client cachingAdminClient) RunCommand(ctx context.Context, command string) (string, error) {
+	output, err := client.AdminClient.RunCommand(ctx, command)
+	return output, client.invalidateStatus(err)
+}
+
+// ThrottleTag sets a manual throttle on a tag for the given duration.
+func (client cachingAdminClient) ThrottleTag(ctx context.Context, throttle fdbtypes.TagThrottle, duration time.Duration) error {
+	return client.invalidateStatus(client.AdminClient.ThrottleTag(ctx, throttle, duration))
+}
+
+// UnthrottleTag removes the manual throttles on a tag.
+func (client cachingAdminClient) UnthrottleTag(ctx context.Context, tag string) error {
+	return client.invalidateStatus(client.AdminClient.UnthrottleTag(ctx, tag))
+}
+
+// SetTagQuota sets the throughput quota of a tag.
+func (client cachingAdminClient) SetTagQuota(ctx context.Context, quota fdbtypes.TagQuota) error {
+	return client.invalidateStatus(client.AdminClient.SetTagQuota(ctx, quota))
+}
+
+// ClearTagQuota removes the throughput quota of a tag.
+func (client cachingAdminClient) ClearTagQuota(ctx context.Context, tag string) error {
+	return client.invalidateStatus(client.AdminClient.ClearTagQuota(ctx, tag))
+}
+
+// SetKnobs sets the given knobs in the configuration database.
+func (client cachingAdminClient) SetKnobs(ctx context.Context, knobs map[string]string) error {
+	return client.invalidateStatus(client.AdminClient.SetKnobs(ctx, knobs))
+}
+
+// ClearKnobs removes the given knobs from the configuration database.
+func (client cachingAdminClient) ClearKnobs(ctx context.Context, names []string) error {
+	return client.invalidateStatus(client.AdminClient.ClearKnobs(ctx, names))
+}
+
+// SetConsistencyCheckSuspended suspends or resumes the consistency check in
+// the database.
+func (client cachingAdminClient) SetConsistencyCheckSuspended(ctx context.Context, suspended bool) error {
+	return client.invalidateStatus(client.AdminClient.SetConsistencyCheckSuspended(ctx, suspended))
+}
@@ -0,0 +1,149 @@
+/*
+ * status_cache_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("status_cache", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var mockAdmin *mockAdminClient
+	var adminClient fdbadminclient.AdminClient
+	var provider cachingDatabaseClientProvider
+	var now time.Time
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err := setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		mockAdmin, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Now()
+		provider = NewCachingDatabaseClientProvider(mockDatabaseClientProvider{}, 10*time.Second).(cachingDatabaseClientProvider)
+		provider.cache.now = func() time.Time { return now }
+
+		adminClient, err = provider.GetAdminClient(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = adminClient.GetStatus(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+
+		// Any status that is not served from the cache will fail from now on.
+		mockAdmin.MockCommandError("GetStatus", 1)
+	})
+
+	It("should serve the status from the cache", func() {
+		status, err := adminClient.GetStatus(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Client.DatabaseStatus.Available).To(BeTrue())
+	})
+
+	It("should return a copy of the cached status", func() {
+		status, err := adminClient.GetStatus(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		status.Client.DatabaseStatus.Available = false
+
+		status, err = adminClient.GetStatus(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Client.DatabaseStatus.Available).To(BeTrue())
+	})
+
+	When("the TTL has passed", func() {
+		BeforeEach(func() {
+			now = now.Add(10 * time.Second)
+		})
+
+		It("should fetch the status again", func() {
+			_, err := adminClient.GetStatus(context.TODO())
+			Expect(err).To(MatchError("mocked error in GetStatus"))
+		})
+	})
+
+	When("the database was changed", func() {
+		BeforeEach(func() {
+			err := adminClient.ExcludeInstances(context.TODO(), []fdbtypes.ProcessAddress{{IPAddress: net.ParseIP("1.1.1.1"), Port: 4501}})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should fetch the status again", func() {
+			_, err := adminClient.GetStatus(context.TODO())
+			Expect(err).To(MatchError("mocked error in GetStatus"))
+		})
+	})
+
+	When("a backup was expired", func() {
+		BeforeEach(func() {
+			err := adminClient.ExpireBackup(context.TODO(), "blobstore://test@test-service/test-backup", fdbtypes.BackupExpirationPolicy{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should fetch the status again", func() {
+			_, err := adminClient.GetStatus(context.TODO())
+			Expect(err).To(MatchError("mocked error in GetStatus"))
+		})
+	})
+
+	When("a sub-reconciler changed processes", func() {
+		BeforeEach(func() {
+			reconciler := createTestClusterReconciler()
+			reconciler.DatabaseClientProvider = provider
+			Expect(changesProcesses(addPods{})).To(BeTrue())
+			Expect(changesProcesses(removeProcessGroups{})).To(BeTrue())
+			reconciler.invalidateStatusCache(client.ObjectKeyFromObject(cluster))
+		})
+
+		It("should fetch the status again", func() {
+			_, err := adminClient.GetStatus(context.TODO())
+			Expect(err).To(MatchError("mocked error in GetStatus"))
+		})
+	})
+
+	When("the status of another cluster is cached after the TTL has passed", func() {
+		BeforeEach(func() {
+			now = now.Add(10 * time.Second)
+			provider.cache.set("other/cluster", &fdbtypes.FoundationDBStatus{})
+		})
+
+		It("should drop the expired status", func() {
+			Expect(provider.cache.entries).To(HaveLen(1))
+			Expect(provider.cache.entries).To(HaveKey("other/cluster"))
+		})
+	})
+
+	When("the TTL is zero", func() {
+		It("should not cache the status", func() {
+			Expect(NewCachingDatabaseClientProvider(mockDatabaseClientProvider{}, 0)).To(Equal(mockDatabaseClientProvider{}))
+		})
+	})
+})
@@ -42,7 +42,7 @@ func (s stopBackup) reconcile(r *FoundationDBBackupReconciler, context ctx.Conte
 	}
 	defer adminClient.Close()
 
-	err = adminClient.StopBackup(backup.BackupURL())
+	err = adminClient.StopBackup(context, backup.BackupURL())
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -40,6 +40,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest/printer"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -54,6 +55,7 @@ var k8sClient *mockclient.MockClient
 var clusterReconciler *FoundationDBClusterReconciler
 var backupReconciler *FoundationDBBackupReconciler
 var restoreReconciler *FoundationDBRestoreReconciler
+var drReconciler *FoundationDBDRReconciler
 
 func TestAPIs(t *testing.T) {
 	RegisterFailHandler(Fail)
@@ -83,6 +85,7 @@ var _ = BeforeSuite(func(done Done) {
 		Recorder:               k8sClient,
 		InSimulation:           true,
 		DatabaseClientProvider: mockDatabaseClientProvider{},
+		SecretReader:           k8sClient,
 	}
 
 	restoreReconciler = &FoundationDBRestoreReconciler{
@@ -92,6 +95,14 @@ var _ = BeforeSuite(func(done Done) {
 		DatabaseClientProvider: mockDatabaseClientProvider{},
 	}
 
+	drReconciler = &FoundationDBDRReconciler{
+		Client:                 k8sClient,
+		Log:                    ctrl.Log.WithName("controllers").WithName("FoundationDBDR"),
+		Recorder:               k8sClient,
+		InSimulation:           true,
+		DatabaseClientProvider: mockDatabaseClientProvider{},
+	}
+
 	close(done)
 }, 60)
 
@@ -102,6 +113,7 @@ var _ = AfterSuite(func() {
 
 var _ = AfterEach(func() {
 	k8sClient.Clear()
+	clusterReconciler.Recorder.(*auditEventRecorder).clearPendingEntries()
 	clearMockAdminClients()
 	clearMockLockClients()
 })
@@ -132,6 +144,10 @@ func reconcileRestore(restore *fdbtypes.FoundationDBRestore) (reconcile.Result,
 	return reconcileObject(restoreReconciler, restore.ObjectMeta, 20)
 }
 
+func reconcileDR(dr *fdbtypes.FoundationDBDR) (reconcile.Result, error) {
+	return reconcileObject(drReconciler, dr.ObjectMeta, 20)
+}
+
 func reconcileObject(reconciler reconcile.Reconciler, metadata metav1.ObjectMeta, requeueLimit int) (reconcile.Result, error) {
 	attempts := requeueLimit + 1
 	result := reconcile.Result{Requeue: true}
@@ -178,13 +194,22 @@ func setupClusterForTest(cluster *fdbtypes.FoundationDBCluster) error {
 }
 
 func createTestClusterReconciler() *FoundationDBClusterReconciler {
+	recorder := NewAuditEventRecorder(k8sClient, k8sClient, k8sClient)
 	return &FoundationDBClusterReconciler{
-		Client:                 k8sClient,
+		Client:                 NewAuditClient(k8sClient, recorder),
 		Log:                    ctrl.Log.WithName("controllers").WithName("FoundationDBCluster"),
-		Recorder:               k8sClient,
+		Recorder:               recorder,
 		InSimulation:           true,
 		PodLifecycleManager:    podmanager.StandardPodLifecycleManager{},
 		PodClientProvider:      internal.NewMockFdbPodClient,
 		DatabaseClientProvider: mockDatabaseClientProvider{},
 	}
 }
+
+// clearPendingEntries drops the audit log entries that have not been written
+// yet, so they do not leak into the next test.
+func (recorder *auditEventRecorder) clearPendingEntries() {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+	recorder.pendingEntries = make(map[client.ObjectKey][]internal.AuditLogEntry)
+}
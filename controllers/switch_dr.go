@@ -0,0 +1,61 @@
+/*
+ * switch_dr.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// switchDR provides a reconciliation step for switching a running DR
+// replication over to the cluster that is the destination in the status.
+type switchDR struct {
+}
+
+// reconcile runs the reconciler's work.
+func (s switchDR) reconcile(r *FoundationDBDRReconciler, context ctx.Context, dr *fdbtypes.FoundationDBDR) *requeue {
+	if !dr.NeedsSwitch() {
+		return nil
+	}
+
+	details := dr.Status.DRDetails
+	if !details.CompleteCopy {
+		return &requeue{message: fmt.Sprintf("Waiting for %s to contain a complete copy of %s", details.DestinationClusterName, details.SourceClusterName)}
+	}
+
+	adminClient, sourceConnectionString, err := r.adminClientForDR(context, dr, details.SourceClusterName, details.DestinationClusterName)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	r.Recorder.Event(dr, corev1.EventTypeNormal, "SwitchingDR", fmt.Sprintf("Switching DR from %s to %s", details.SourceClusterName, details.DestinationClusterName))
+	err = adminClient.SwitchDR(context, sourceConnectionString, dr.DRTag())
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
@@ -46,7 +46,7 @@ func (s toggleBackupPaused) reconcile(r *FoundationDBBackupReconciler, context c
 		}
 		defer adminClient.Close()
 
-		err = adminClient.PauseBackups()
+		err = adminClient.PauseBackups(context)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -58,7 +58,7 @@ func (s toggleBackupPaused) reconcile(r *FoundationDBBackupReconciler, context c
 		}
 		defer adminClient.Close()
 
-		err = adminClient.ResumeBackups()
+		err = adminClient.ResumeBackups(context)
 		if err != nil {
 			return &requeue{curError: err}
 		}
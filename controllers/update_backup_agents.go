@@ -23,7 +23,6 @@ package controllers
 import (
 	ctx "context"
 	"fmt"
-	"reflect"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
@@ -55,7 +54,17 @@ func (u updateBackupAgents) reconcile(r *FoundationDBBackupReconciler, context c
 		}
 	}
 
-	deployment, err := internal.GetBackupDeployment(backup)
+	credentials, err := r.getBlobCredentials(context, backup)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			message := fmt.Sprintf("Waiting for blob credentials secret %s", backup.Spec.BlobCredentialsSecret)
+			r.Recorder.Event(backup, corev1.EventTypeWarning, "MissingBlobCredentials", message)
+			return &requeue{message: message, delayedRequeue: true}
+		}
+		return &requeue{curError: err}
+	}
+
+	deployment, err := internal.GetBackupDeployment(backup, credentials)
 	if err != nil {
 		r.Recorder.Event(backup, corev1.EventTypeWarning, "GetBackupDeployment", err.Error())
 		return &requeue{curError: err}
@@ -66,17 +75,14 @@ func (u updateBackupAgents) reconcile(r *FoundationDBBackupReconciler, context c
 	}
 
 	if needCreation && deployment != nil {
-		err = r.Create(context, deployment)
+		err = internal.ApplyObject(r, context, deployment)
 		if err != nil {
 			return &requeue{curError: err}
 		}
 	}
 	if !needCreation && deployment != nil {
-		annotationChange := mergeAnnotations(&existingDeployment.ObjectMeta, deployment.ObjectMeta)
-		deployment.ObjectMeta.Annotations = existingDeployment.ObjectMeta.Annotations
-
-		if annotationChange || !reflect.DeepEqual(existingDeployment.ObjectMeta.Labels, deployment.ObjectMeta.Labels) {
-			err = r.Update(context, deployment)
+		if !metadataMatches(existingDeployment.ObjectMeta, deployment.ObjectMeta) {
+			err = internal.ApplyObject(r, context, deployment)
 			if err != nil {
 				return &requeue{curError: err}
 			}
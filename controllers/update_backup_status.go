@@ -28,6 +28,7 @@ import (
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -39,6 +40,7 @@ type updateBackupStatus struct{}
 func (s updateBackupStatus) reconcile(r *FoundationDBBackupReconciler, context ctx.Context, backup *fdbtypes.FoundationDBBackup) *requeue {
 	status := fdbtypes.FoundationDBBackupStatus{}
 	status.Generations.Reconciled = backup.Status.Generations.Reconciled
+	status.LastExpirationTime = backup.Status.LastExpirationTime
 
 	backupDeployments := &appsv1.DeploymentList{}
 	err := r.List(context, backupDeployments, client.InNamespace(backup.Namespace), client.MatchingLabels(map[string]string{fdbtypes.BackupDeploymentLabel: string(backup.ObjectMeta.UID)}))
@@ -46,7 +48,14 @@ func (s updateBackupStatus) reconcile(r *FoundationDBBackupReconciler, context c
 		return &requeue{curError: err}
 	}
 
-	desiredBackupDeployment, err := internal.GetBackupDeployment(backup)
+	// A missing secret is reported by the updateBackupAgents reconciler, so we
+	// only compare the deployment against a spec without the credentials.
+	credentials, err := r.getBlobCredentials(context, backup)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return &requeue{curError: err}
+	}
+
+	desiredBackupDeployment, err := internal.GetBackupDeployment(backup, credentials)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -82,7 +91,7 @@ func (s updateBackupStatus) reconcile(r *FoundationDBBackupReconciler, context c
 	}
 	defer adminClient.Close()
 
-	liveStatus, err := adminClient.GetBackupStatus()
+	liveStatus, err := adminClient.GetBackupStatus(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -91,6 +100,7 @@ func (s updateBackupStatus) reconcile(r *FoundationDBBackupReconciler, context c
 		URL:                   liveStatus.DestinationURL,
 		Running:               liveStatus.Status.Running,
 		Paused:                liveStatus.BackupAgentsPaused,
+		Completed:             liveStatus.Status.Completed,
 		SnapshotPeriodSeconds: liveStatus.SnapshotIntervalSeconds,
 	}
 
@@ -0,0 +1,141 @@
+/*
+ * update_client_config.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// updateClientConfig provides a reconciliation step for publishing the
+// config map and secret that client applications use to connect to the
+// cluster.
+type updateClientConfig struct{}
+
+// reconcile runs the reconciler's work.
+func (updateClientConfig) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "updateClientConfig")
+
+	// Wait until the cluster has a connection string, so client applications
+	// never see an empty cluster file.
+	if cluster.ShouldPublishClientConfig() && cluster.Status.ConnectionString == "" {
+		return nil
+	}
+
+	configMapName := fmt.Sprintf("%s-client-config", cluster.Name)
+	existingConfigMap := &corev1.ConfigMap{}
+	configMapExists := true
+	err := r.Get(context, client.ObjectKey{Namespace: cluster.Namespace, Name: configMapName}, existingConfigMap)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return &requeue{curError: err}
+		}
+		configMapExists = false
+	}
+
+	configMap := internal.GetClientConfigMap(cluster)
+	if configMapExists && !metav1.IsControlledBy(existingConfigMap, cluster) {
+		// The config map belongs to someone else, so we must neither
+		// overwrite it nor delete it.
+		if configMap != nil {
+			message := fmt.Sprintf("Config map %s is not controlled by the cluster", configMapName)
+			logger.Info(message)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "ClientConfigConflict", message)
+		}
+	} else if configMap == nil {
+		if configMapExists {
+			logger.Info("Deleting client config map")
+			err = r.Delete(context, existingConfigMap)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+	} else if !configMapExists || !equality.Semantic.DeepEqual(existingConfigMap.Data, configMap.Data) || !metadataMatches(existingConfigMap.ObjectMeta, configMap.ObjectMeta) {
+		logger.Info("Updating client config map")
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "UpdatingClientConfig", fmt.Sprintf("Updating config map %s", configMapName))
+		err = internal.ApplyObject(r, context, configMap)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	var sourceSecret *corev1.Secret
+	if cluster.ShouldPublishClientConfig() && cluster.Spec.ClientConfig.TLSSecretName != "" {
+		sourceSecret = &corev1.Secret{}
+		err = r.Get(context, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.ClientConfig.TLSSecretName}, sourceSecret)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				message := fmt.Sprintf("Waiting for client TLS secret %s", cluster.Spec.ClientConfig.TLSSecretName)
+				logger.Info(message)
+				r.Recorder.Event(cluster, corev1.EventTypeWarning, "MissingClientTLSSecret", message)
+				return &requeue{message: message, delayedRequeue: true}
+			}
+			return &requeue{curError: err}
+		}
+	}
+
+	secretName := fmt.Sprintf("%s-client-tls", cluster.Name)
+	existingSecret := &corev1.Secret{}
+	secretExists := true
+	err = r.Get(context, client.ObjectKey{Namespace: cluster.Namespace, Name: secretName}, existingSecret)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return &requeue{curError: err}
+		}
+		secretExists = false
+	}
+
+	secret := internal.GetClientTLSSecret(cluster, sourceSecret)
+	if secretExists && !metav1.IsControlledBy(existingSecret, cluster) {
+		if secret != nil {
+			message := fmt.Sprintf("Secret %s is not controlled by the cluster", secretName)
+			logger.Info(message)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "ClientConfigConflict", message)
+		}
+	} else if secret == nil {
+		if secretExists {
+			logger.Info("Deleting client TLS secret")
+			err = r.Delete(context, existingSecret)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+	} else if !secretExists || !equality.Semantic.DeepEqual(existingSecret.Data, secret.Data) || !metadataMatches(existingSecret.ObjectMeta, secret.ObjectMeta) {
+		logger.Info("Updating client TLS secret")
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "UpdatingClientConfig", fmt.Sprintf("Updating secret %s", secretName))
+		err = internal.ApplyObject(r, context, secret)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
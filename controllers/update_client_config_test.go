@@ -0,0 +1,217 @@
+/*
+ * update_client_config_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("update_client_config", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var err error
+	var requeue *requeue
+	configMapName := types.NamespacedName{Namespace: "my-ns", Name: "operator-test-1-client-config"}
+	secretName := types.NamespacedName{Namespace: "my-ns", Name: "operator-test-1-client-tls"}
+
+	getConfigMap := func() (*corev1.ConfigMap, error) {
+		configMap := &corev1.ConfigMap{}
+		err := k8sClient.Get(context.TODO(), configMapName, configMap)
+		return configMap, err
+	}
+
+	getSecret := func() (*corev1.Secret, error) {
+		secret := &corev1.Secret{}
+		err := k8sClient.Get(context.TODO(), secretName, secret)
+		return secret, err
+	}
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateClientConfig{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	Context("with the client config disabled", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not create the config map", func() {
+			_, err = getConfigMap()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("with a config map that is not controlled by the cluster", func() {
+		BeforeEach(func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Namespace: configMapName.Namespace, Name: configMapName.Name},
+				Data:       map[string]string{"cluster-file": "other"},
+			}
+			err = k8sClient.Create(context.TODO(), configMap)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not delete the config map", func() {
+			configMap, err := getConfigMap()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configMap.Data).To(Equal(map[string]string{"cluster-file": "other"}))
+		})
+
+		When("the client config is enabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.ClientConfig.Enabled = pointer.Bool(true)
+			})
+
+			It("should not update the config map", func() {
+				configMap, err := getConfigMap()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Data).To(Equal(map[string]string{"cluster-file": "other"}))
+			})
+		})
+	})
+
+	Context("with the client config enabled", func() {
+		BeforeEach(func() {
+			cluster.Spec.ClientConfig.Enabled = pointer.Bool(true)
+			cluster.Spec.TrustedCAs = []string{"test-ca"}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should create the config map with the cluster file", func() {
+			configMap, err := getConfigMap()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configMap.Data).To(Equal(map[string]string{
+				"cluster-file": cluster.Status.ConnectionString,
+				"ca-file":      "test-ca",
+			}))
+		})
+
+		It("should not create the TLS secret", func() {
+			_, err = getSecret()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+
+		Context("with a new connection string", func() {
+			JustBeforeEach(func() {
+				cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+				requeue = updateClientConfig{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should update the cluster file", func() {
+				configMap, err := getConfigMap()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(configMap.Data["cluster-file"]).To(Equal("operator-test:asdfasf@127.0.0.1:4501"))
+			})
+		})
+
+		Context("when disabling the client config again", func() {
+			JustBeforeEach(func() {
+				cluster.Spec.ClientConfig.Enabled = nil
+				requeue = updateClientConfig{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should delete the config map", func() {
+				_, err = getConfigMap()
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		Context("with a TLS secret", func() {
+			BeforeEach(func() {
+				cluster.Spec.ClientConfig.TLSSecretName = "client-certs"
+			})
+
+			Context("when the secret does not exist", func() {
+				It("should requeue", func() {
+					Expect(requeue).NotTo(BeNil())
+					Expect(requeue.message).To(Equal("Waiting for client TLS secret client-certs"))
+					Expect(requeue.delayedRequeue).To(BeTrue())
+				})
+
+				It("should create the config map", func() {
+					_, err = getConfigMap()
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+
+			Context("when the secret exists", func() {
+				BeforeEach(func() {
+					source := &corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Namespace: "my-ns", Name: "client-certs"},
+						Data: map[string][]byte{
+							corev1.TLSCertKey:       []byte("test-cert"),
+							corev1.TLSPrivateKeyKey: []byte("test-key"),
+						},
+					}
+					err = k8sClient.Create(context.TODO(), source)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should not requeue", func() {
+					Expect(requeue).To(BeNil())
+				})
+
+				It("should create the TLS secret", func() {
+					secret, err := getSecret()
+					Expect(err).NotTo(HaveOccurred())
+					Expect(secret.Type).To(Equal(corev1.SecretTypeTLS))
+					Expect(secret.Data).To(Equal(map[string][]byte{
+						corev1.TLSCertKey:       []byte("test-cert"),
+						corev1.TLSPrivateKeyKey: []byte("test-key"),
+						"ca.crt":                []byte("test-ca"),
+					}))
+				})
+			})
+		})
+	})
+})
@@ -22,7 +22,6 @@ package controllers
 
 import (
 	ctx "context"
-	"reflect"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 
@@ -44,12 +43,12 @@ func (u updateConfigMap) reconcile(r *FoundationDBClusterReconciler, context ctx
 	if err != nil {
 		return &requeue{curError: err}
 	}
-	logger := log.WithValues("namespace", configMap.Namespace, "cluster", cluster.Name, "name", configMap.Name, "reconciler", "UpdateConfigMap")
+	logger := getLogger(context, cluster, "updateConfigMap").WithValues("name", configMap.Name)
 	existing := &corev1.ConfigMap{}
 	err = r.Get(context, types.NamespacedName{Namespace: configMap.Namespace, Name: configMap.Name}, existing)
 	if err != nil && k8serrors.IsNotFound(err) {
 		logger.Info("Creating config map")
-		err = r.Create(context, configMap)
+		err = internal.ApplyObject(r, context, configMap)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -58,21 +57,13 @@ func (u updateConfigMap) reconcile(r *FoundationDBClusterReconciler, context ctx
 		return &requeue{curError: err}
 	}
 
-	metadataCorrect := true
-	if !reflect.DeepEqual(existing.ObjectMeta.Labels, configMap.ObjectMeta.Labels) {
-		existing.ObjectMeta.Labels = configMap.ObjectMeta.Labels
-		metadataCorrect = false
-	}
-
-	if mergeAnnotations(&existing.ObjectMeta, configMap.ObjectMeta) {
-		metadataCorrect = false
-	}
-
-	if !equality.Semantic.DeepEqual(existing.Data, configMap.Data) || !metadataCorrect {
+	// Only the labels and annotations that the operator sets are compared, so
+	// metadata that other controllers add to the config map does not cause an
+	// update.
+	if !equality.Semantic.DeepEqual(existing.Data, configMap.Data) || !metadataMatches(existing.ObjectMeta, configMap.ObjectMeta) {
 		logger.Info("Updating config map")
 		r.Recorder.Event(cluster, corev1.EventTypeNormal, "UpdatingConfigMap", "")
-		existing.Data = configMap.Data
-		err = r.Update(context, existing)
+		err = internal.ApplyObject(r, context, configMap)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -0,0 +1,92 @@
+/*
+ * update_config_map_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("updateConfigMap", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateConfigMap{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	getConfigMap := func() *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{}
+		err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: "operator-test-1-config"}, configMap)
+		Expect(err).NotTo(HaveOccurred())
+		return configMap
+	}
+
+	Context("with a reconciled cluster", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+	})
+
+	When("another controller has changed the metadata", func() {
+		BeforeEach(func() {
+			configMap := getConfigMap()
+			configMap.ObjectMeta.Labels["injected-label"] = "true"
+			configMap.ObjectMeta.Annotations = map[string]string{"injected-annotation": "true"}
+			err = k8sClient.Update(context.TODO(), configMap)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.Processes[fdbtypes.ProcessClassGeneral] = fdbtypes.ProcessSettings{
+				CustomParameters: &[]string{"knob_test=1"},
+			}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should update the data", func() {
+			configMap := getConfigMap()
+			Expect(configMap.Data["fdbmonitor-conf-storage"]).To(ContainSubstring("knob_test=1"))
+		})
+
+		It("should keep the metadata from the other controller", func() {
+			configMap := getConfigMap()
+			Expect(configMap.ObjectMeta.Labels).To(HaveKeyWithValue("injected-label", "true"))
+			Expect(configMap.ObjectMeta.Annotations).To(HaveKeyWithValue("injected-annotation", "true"))
+		})
+	})
+})
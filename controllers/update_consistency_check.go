@@ -0,0 +1,245 @@
+/*
+ * update_consistency_check.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// updateConsistencyCheck provides a reconciliation step for running the
+// consistency checker, and for suspending and resuming the consistency check
+// based on the scheduled windows.
+type updateConsistencyCheck struct{}
+
+// reconcile runs the reconciler's work.
+func (updateConsistencyCheck) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	if !cluster.ShouldManageConsistencyCheck() && cluster.Status.ConsistencyCheck == nil {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "updateConsistencyCheck")
+
+	checkerAvailable, err := updateConsistencyCheckDeployment(r, context, cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	var checkStatus *fdbtypes.ConsistencyCheckStatus
+	if cluster.ShouldManageConsistencyCheck() {
+		checkStatus, err = getConsistencyCheckStatus(cluster, time.Now())
+		if err != nil {
+			return &requeue{curError: err}
+		}
+		checkStatus.CheckerAvailable = checkerAvailable
+	}
+
+	// Once the operator stops managing the consistency check, the check is
+	// resumed so it is not left suspended forever.
+	suspend := checkStatus != nil && !checkStatus.Active
+
+	suspended, err := adminClient.GetConsistencyCheckSuspended(context)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if suspended != suspend {
+		if suspend {
+			logger.Info("Suspending consistency check")
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, "SuspendingConsistencyCheck", "Suspending the consistency check")
+		} else {
+			logger.Info("Resuming consistency check")
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, "ResumingConsistencyCheck", "Resuming the consistency check")
+		}
+
+		err = adminClient.SetConsistencyCheckSuspended(context, suspend)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if !consistencyCheckStatusMatches(cluster.Status.ConsistencyCheck, checkStatus) {
+		cluster.Status.ConsistencyCheck = checkStatus
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
+
+// updateConsistencyCheckDeployment creates, updates or deletes the deployment
+// for the consistency checker, and returns whether the checker is available.
+func updateConsistencyCheckDeployment(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (bool, error) {
+	logger := getLogger(context, cluster, "updateConsistencyCheck")
+	deploymentName := fmt.Sprintf("%s-consistency-check", cluster.Name)
+	existingDeployment := &appsv1.Deployment{}
+	needCreation := false
+
+	err := r.Get(context, client.ObjectKey{Name: deploymentName, Namespace: cluster.Namespace}, existingDeployment)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return false, err
+		}
+		needCreation = true
+	}
+
+	deployment, err := internal.GetConsistencyCheckDeployment(cluster)
+	if err != nil {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "GetConsistencyCheckDeployment", err.Error())
+		return false, err
+	}
+
+	if deployment == nil {
+		if !needCreation {
+			logger.Info("Deleting consistency check deployment")
+			err = r.Delete(context, existingDeployment)
+		}
+		return false, err
+	}
+
+	if needCreation {
+		logger.Info("Creating consistency check deployment")
+		return false, internal.ApplyObject(r, context, deployment)
+	}
+
+	if !metadataMatches(existingDeployment.ObjectMeta, deployment.ObjectMeta) {
+		logger.Info("Updating consistency check deployment")
+		err = internal.ApplyObject(r, context, deployment)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return existingDeployment.Status.AvailableReplicas > 0, nil
+}
+
+// getConsistencyCheckStatus determines the state of the consistency check
+// windows at the given time, based on the windows in the cluster status.
+func getConsistencyCheckStatus(cluster *fdbtypes.FoundationDBCluster, now time.Time) (*fdbtypes.ConsistencyCheckStatus, error) {
+	checkStatus := &fdbtypes.ConsistencyCheckStatus{}
+	if cluster.Status.ConsistencyCheck != nil {
+		*checkStatus = *cluster.Status.ConsistencyCheck
+	}
+
+	windowStart := time.Unix(checkStatus.LastWindowStartTimestamp, 0)
+	if checkStatus.Active {
+		if !now.Before(windowStart.Add(cluster.GetConsistencyCheckWindow())) {
+			checkStatus.LastWindowOutcome = fdbtypes.ConsistencyCheckOutcomeCompleted
+		} else if cluster.ShouldSuspendConsistencyCheck() {
+			checkStatus.LastWindowOutcome = fdbtypes.ConsistencyCheckOutcomeInterrupted
+		} else {
+			return checkStatus, nil
+		}
+
+		checkStatus.Active = false
+		checkStatus.LastWindowEndTimestamp = now.Unix()
+		checkStatus.CompletedWindows++
+
+		return checkStatus, nil
+	}
+
+	if cluster.ShouldSuspendConsistencyCheck() {
+		return checkStatus, nil
+	}
+
+	scheduledStart, err := cluster.GetConsistencyCheckScheduledStart(now)
+	if err != nil {
+		return nil, err
+	}
+
+	// With a start time, a window is only started within its scheduled
+	// time, so a missed window is not made up later.
+	if !scheduledStart.IsZero() {
+		if checkStatus.LastWindowStartTimestamp < scheduledStart.Unix() && now.Before(scheduledStart.Add(cluster.GetConsistencyCheckWindow())) {
+			checkStatus.Active = true
+			checkStatus.LastWindowStartTimestamp = scheduledStart.Unix()
+		}
+
+		return checkStatus, nil
+	}
+
+	if checkStatus.LastWindowStartTimestamp == 0 || !now.Before(windowStart.Add(cluster.GetConsistencyCheckInterval())) {
+		checkStatus.Active = true
+		checkStatus.LastWindowStartTimestamp = now.Unix()
+	}
+
+	return checkStatus, nil
+}
+
+// getConsistencyCheckRequeueDelay returns the time until the current
+// consistency check window ends or the next one starts.
+func getConsistencyCheckRequeueDelay(cluster *fdbtypes.FoundationDBCluster, now time.Time) time.Duration {
+	checkStatus := cluster.Status.ConsistencyCheck
+	if checkStatus == nil || cluster.ShouldSuspendConsistencyCheck() {
+		return 0
+	}
+
+	windowStart := time.Unix(checkStatus.LastWindowStartTimestamp, 0)
+	var transition time.Time
+	if checkStatus.Active {
+		transition = windowStart.Add(cluster.GetConsistencyCheckWindow())
+	} else {
+		scheduledStart, err := cluster.GetConsistencyCheckScheduledStart(now)
+		if err != nil {
+			return 0
+		}
+
+		if scheduledStart.IsZero() {
+			transition = windowStart.Add(cluster.GetConsistencyCheckInterval())
+		} else {
+			transition = scheduledStart.Add(cluster.GetConsistencyCheckInterval())
+		}
+	}
+
+	delay := transition.Sub(now)
+	if delay < time.Second {
+		return time.Second
+	}
+
+	return delay
+}
+
+// consistencyCheckStatusMatches determines if two consistency check statuses
+// are equal.
+func consistencyCheckStatusMatches(current *fdbtypes.ConsistencyCheckStatus, desired *fdbtypes.ConsistencyCheckStatus) bool {
+	if current == nil || desired == nil {
+		return current == desired
+	}
+
+	return *current == *desired
+}
@@ -0,0 +1,377 @@
+/*
+ * update_consistency_check_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("update_consistency_check", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateConsistencyCheck{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	isSuspended := func() bool {
+		suspended, err := adminClient.GetConsistencyCheckSuspended(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		return suspended
+	}
+
+	Context("with the consistency check unmanaged", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not suspend the consistency check", func() {
+			Expect(isSuspended()).To(BeFalse())
+		})
+
+		It("should not set the status", func() {
+			Expect(cluster.Status.ConsistencyCheck).To(BeNil())
+		})
+	})
+
+	Context("with the consistency check managed", func() {
+		BeforeEach(func() {
+			cluster.Spec.ConsistencyCheck.Enabled = pointer.Bool(true)
+		})
+
+		Context("without a previous window", func() {
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should start a window", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.ConsistencyCheck).NotTo(BeNil())
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeTrue())
+				Expect(cluster.Status.ConsistencyCheck.LastWindowStartTimestamp).To(BeNumerically("~", time.Now().Unix(), 5))
+			})
+
+			It("should not suspend the consistency check", func() {
+				Expect(isSuspended()).To(BeFalse())
+			})
+		})
+
+		Context("with an active window that has ended", func() {
+			BeforeEach(func() {
+				cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+					Active:                   true,
+					LastWindowStartTimestamp: time.Now().Add(-2 * time.Hour).Unix(),
+				}
+			})
+
+			It("should end the window", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeFalse())
+				Expect(cluster.Status.ConsistencyCheck.LastWindowEndTimestamp).To(BeNumerically("~", time.Now().Unix(), 5))
+				Expect(cluster.Status.ConsistencyCheck.CompletedWindows).To(Equal(1))
+				Expect(cluster.Status.ConsistencyCheck.LastWindowOutcome).To(Equal(fdbtypes.ConsistencyCheckOutcomeCompleted))
+			})
+
+			It("should suspend the consistency check", func() {
+				Expect(isSuspended()).To(BeTrue())
+			})
+		})
+
+		Context("with an active window that has not ended", func() {
+			BeforeEach(func() {
+				cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+					Active:                   true,
+					LastWindowStartTimestamp: time.Now().Add(-10 * time.Minute).Unix(),
+				}
+			})
+
+			It("should keep the window active", func() {
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeTrue())
+			})
+
+			It("should not suspend the consistency check", func() {
+				Expect(isSuspended()).To(BeFalse())
+			})
+
+			Context("with the consistency check suspended in the spec", func() {
+				BeforeEach(func() {
+					cluster.Spec.ConsistencyCheck.Suspend = pointer.Bool(true)
+				})
+
+				It("should end the window", func() {
+					Expect(cluster.Status.ConsistencyCheck.Active).To(BeFalse())
+					Expect(cluster.Status.ConsistencyCheck.CompletedWindows).To(Equal(1))
+					Expect(cluster.Status.ConsistencyCheck.LastWindowOutcome).To(Equal(fdbtypes.ConsistencyCheckOutcomeInterrupted))
+				})
+
+				It("should suspend the consistency check", func() {
+					Expect(isSuspended()).To(BeTrue())
+				})
+			})
+		})
+
+		Context("with a previous window within the interval", func() {
+			BeforeEach(func() {
+				cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+					LastWindowStartTimestamp: time.Now().Add(-2 * time.Hour).Unix(),
+					LastWindowEndTimestamp:   time.Now().Add(-1 * time.Hour).Unix(),
+					CompletedWindows:         1,
+				}
+			})
+
+			It("should not start a window", func() {
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeFalse())
+			})
+
+			It("should suspend the consistency check", func() {
+				Expect(isSuspended()).To(BeTrue())
+			})
+		})
+
+		Context("with a previous window outside the interval", func() {
+			BeforeEach(func() {
+				cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+					LastWindowStartTimestamp: time.Now().Add(-25 * time.Hour).Unix(),
+					LastWindowEndTimestamp:   time.Now().Add(-24 * time.Hour).Unix(),
+					CompletedWindows:         1,
+				}
+				err = adminClient.SetConsistencyCheckSuspended(context.TODO(), true)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should start a window", func() {
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeTrue())
+				Expect(cluster.Status.ConsistencyCheck.LastWindowStartTimestamp).To(BeNumerically("~", time.Now().Unix(), 5))
+				Expect(cluster.Status.ConsistencyCheck.CompletedWindows).To(Equal(1))
+			})
+
+			It("should resume the consistency check", func() {
+				Expect(isSuspended()).To(BeFalse())
+			})
+		})
+	})
+
+	Context("with a start time for the consistency check", func() {
+		var scheduledStart time.Time
+
+		BeforeEach(func() {
+			cluster.Spec.ConsistencyCheck.Enabled = pointer.Bool(true)
+		})
+
+		Context("within the scheduled window", func() {
+			BeforeEach(func() {
+				cluster.Spec.ConsistencyCheck.StartTime = pointer.String(time.Now().UTC().Add(-10 * time.Minute).Format("15:04"))
+				scheduledStart, err = cluster.GetConsistencyCheckScheduledStart(time.Now())
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should start the scheduled window", func() {
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeTrue())
+				Expect(cluster.Status.ConsistencyCheck.LastWindowStartTimestamp).To(Equal(scheduledStart.Unix()))
+			})
+
+			It("should not suspend the consistency check", func() {
+				Expect(isSuspended()).To(BeFalse())
+			})
+
+			Context("with the scheduled window already completed", func() {
+				BeforeEach(func() {
+					cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+						LastWindowStartTimestamp: scheduledStart.Unix(),
+						LastWindowEndTimestamp:   time.Now().Unix(),
+						CompletedWindows:         1,
+						LastWindowOutcome:        fdbtypes.ConsistencyCheckOutcomeInterrupted,
+					}
+				})
+
+				It("should not start the window again", func() {
+					Expect(cluster.Status.ConsistencyCheck.Active).To(BeFalse())
+				})
+
+				It("should suspend the consistency check", func() {
+					Expect(isSuspended()).To(BeTrue())
+				})
+			})
+		})
+
+		Context("outside of the scheduled window", func() {
+			BeforeEach(func() {
+				cluster.Spec.ConsistencyCheck.StartTime = pointer.String(time.Now().UTC().Add(-2 * time.Hour).Format("15:04"))
+			})
+
+			It("should not start a window", func() {
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeFalse())
+				Expect(cluster.Status.ConsistencyCheck.LastWindowStartTimestamp).To(BeZero())
+			})
+
+			It("should suspend the consistency check", func() {
+				Expect(isSuspended()).To(BeTrue())
+			})
+		})
+	})
+
+	Context("with the consistency checker", func() {
+		var deployment *appsv1.Deployment
+
+		BeforeEach(func() {
+			cluster.Spec.ConsistencyCheck.Enabled = pointer.Bool(true)
+			deployment = &appsv1.Deployment{}
+		})
+
+		getDeployment := func() error {
+			return k8sClient.Get(context.TODO(), client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Name + "-consistency-check"}, deployment)
+		}
+
+		It("should create the deployment", func() {
+			Expect(getDeployment()).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
+				"-r", "consistencycheck",
+				"-C", "/var/dynamic-conf/fdb.cluster",
+				"--logdir", "/var/log/fdb-trace-logs",
+			}))
+		})
+
+		It("should not report the checker as available yet", func() {
+			Expect(cluster.Status.ConsistencyCheck.CheckerAvailable).To(BeFalse())
+		})
+
+		When("the deployment is available", func() {
+			BeforeEach(func() {
+				requeue = updateConsistencyCheck{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).To(BeNil())
+				Expect(getDeployment()).To(Succeed())
+				deployment.Status.AvailableReplicas = 1
+				Expect(k8sClient.Status().Update(context.TODO(), deployment)).To(Succeed())
+			})
+
+			It("should report the checker as available", func() {
+				Expect(cluster.Status.ConsistencyCheck.CheckerAvailable).To(BeTrue())
+			})
+		})
+
+		When("the checker is disabled", func() {
+			BeforeEach(func() {
+				requeue = updateConsistencyCheck{}.reconcile(clusterReconciler, context.TODO(), cluster)
+				Expect(requeue).To(BeNil())
+				cluster.Spec.ConsistencyCheck.RunChecker = pointer.Bool(false)
+			})
+
+			It("should delete the deployment", func() {
+				Expect(k8serrors.IsNotFound(getDeployment())).To(BeTrue())
+			})
+
+			It("should keep managing the suspension", func() {
+				Expect(cluster.Status.ConsistencyCheck.Active).To(BeTrue())
+			})
+		})
+	})
+
+	Context("when the consistency check is no longer managed", func() {
+		BeforeEach(func() {
+			cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+				LastWindowStartTimestamp: time.Now().Add(-2 * time.Hour).Unix(),
+			}
+			err = adminClient.SetConsistencyCheckSuspended(context.TODO(), true)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should resume the consistency check", func() {
+			Expect(isSuspended()).To(BeFalse())
+		})
+
+		It("should clear the status", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.ConsistencyCheck).To(BeNil())
+		})
+	})
+
+	Describe("getConsistencyCheckRequeueDelay", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now()
+			cluster.Spec.ConsistencyCheck.Enabled = pointer.Bool(true)
+		})
+
+		It("should return the time until the active window ends", func() {
+			cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+				Active:                   true,
+				LastWindowStartTimestamp: now.Add(-10 * time.Minute).Unix(),
+			}
+			Expect(getConsistencyCheckRequeueDelay(cluster, now)).To(BeNumerically("~", 50*time.Minute, time.Second))
+		})
+
+		It("should return the time until the next window starts", func() {
+			cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{
+				LastWindowStartTimestamp: now.Add(-2 * time.Hour).Unix(),
+			}
+			Expect(getConsistencyCheckRequeueDelay(cluster, now)).To(BeNumerically("~", 22*time.Hour, time.Second))
+		})
+
+		It("should return the time until the next scheduled window starts", func() {
+			cluster.Spec.ConsistencyCheck.StartTime = pointer.String(now.UTC().Add(-2 * time.Hour).Format("15:04"))
+			cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{}
+			Expect(getConsistencyCheckRequeueDelay(cluster, now)).To(BeNumerically("~", 22*time.Hour, time.Minute))
+		})
+
+		It("should not requeue while the consistency check is suspended", func() {
+			cluster.Spec.ConsistencyCheck.Suspend = pointer.Bool(true)
+			cluster.Status.ConsistencyCheck = &fdbtypes.ConsistencyCheckStatus{}
+			Expect(getConsistencyCheckRequeueDelay(cluster, now)).To(Equal(time.Duration(0)))
+		})
+	})
+})
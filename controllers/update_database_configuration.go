@@ -28,6 +28,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 )
 
 // updateDatabaseConfiguration provides a reconciliation step for changing the
@@ -36,7 +37,7 @@ type updateDatabaseConfiguration struct{}
 
 // reconcile runs the reconciler's work.
 func (u updateDatabaseConfiguration) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updateDatabaseConfiguration")
+	logger := getLogger(context, cluster, "updateDatabaseConfiguration")
 	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
 
 	if err != nil {
@@ -44,12 +45,23 @@ func (u updateDatabaseConfiguration) reconcile(r *FoundationDBClusterReconciler,
 	}
 	defer adminClient.Close()
 
+	if cluster.Spec.PrimaryDataCenter != "" && !cluster.Spec.DatabaseConfiguration.HasMainDataCenter(cluster.Spec.PrimaryDataCenter) {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidPrimaryDataCenter",
+			fmt.Sprintf("Primary data center %s is not the main data center of any region", cluster.Spec.PrimaryDataCenter))
+		return &requeue{message: fmt.Sprintf("Primary data center %s is not the main data center of any region", cluster.Spec.PrimaryDataCenter), delayedRequeue: true}
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
 	desiredConfiguration := cluster.DesiredDatabaseConfiguration()
 	desiredConfiguration.RoleCounts.Storage = 0
 	needsChange := false
 	var currentConfiguration fdbtypes.DatabaseConfiguration
 
-	status, err := adminClient.GetStatus()
+	status, err := adminClient.GetStatus(context)
 	if err != nil {
 		return &requeue{curError: err}
 	}
@@ -98,23 +110,32 @@ func (u updateDatabaseConfiguration) reconcile(r *FoundationDBClusterReconciler,
 		}
 
 		if !initialConfig {
-			hasLock, err := r.takeLock(cluster,
+			hasLock, err := r.takeLock(context, cluster,
 				fmt.Sprintf("reconfiguring the database to `%s`", configurationString))
 			if !hasLock {
 				return &requeue{curError: err}
 			}
 		}
 
+		// The list of storage engines is not complete, so the database
+		// decides whether it supports an engine that the operator does not
+		// know about.
+		if nextConfiguration.StorageEngine != "" && !version.SupportsStorageEngine(nextConfiguration.StorageEngine) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "UnknownStorageEngine",
+				fmt.Sprintf("Storage engine %s is not known to be supported in version %s", nextConfiguration.StorageEngine, version))
+		}
+
 		logger.Info("Configuring database")
 		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ConfiguringDatabase",
 			fmt.Sprintf("Setting database configuration to `%s`", configurationString),
 		)
-		err = adminClient.ConfigureDatabase(nextConfiguration, initialConfig)
+		err = adminClient.ConfigureDatabase(context, nextConfiguration, initialConfig)
 		if err != nil {
 			return &requeue{curError: err}
 		}
 		if initialConfig {
 			cluster.Status.Configured = true
+			cluster.Status.SeedRestorePending = cluster.Spec.SeedBackup != nil
 			err = r.Status().Update(context, cluster)
 			if err != nil {
 				return &requeue{curError: err}
@@ -127,6 +148,32 @@ func (u updateDatabaseConfiguration) reconcile(r *FoundationDBClusterReconciler,
 			logger.Info("Requeuing for next stage of database configuration change")
 			return &requeue{message: "Requeuing for next stage of database configuration change"}
 		}
+
+		// The status was fetched before the configuration change, so the
+		// failover is checked in the next reconciliation.
+		if cluster.Spec.PrimaryDataCenter != "" {
+			return &requeue{message: fmt.Sprintf("Waiting for data center %s to become the primary", cluster.Spec.PrimaryDataCenter), delayedRequeue: true}
+		}
+
+		return nil
+	}
+
+	if cluster.Spec.PrimaryDataCenter != "" {
+		activePrimary := internal.GetActivePrimaryDataCenter(status)
+		if activePrimary != cluster.Spec.PrimaryDataCenter || status.Cluster.RecoveryState.Name != "fully_recovered" {
+			logger.Info("Waiting for data center failover", "primaryDataCenter", cluster.Spec.PrimaryDataCenter, "activePrimaryDataCenter", activePrimary, "recoveryState", status.Cluster.RecoveryState.Name)
+			return &requeue{message: fmt.Sprintf("Waiting for data center %s to become the primary, current primary is %s and recovery state is %s", cluster.Spec.PrimaryDataCenter, activePrimary, status.Cluster.RecoveryState.Name), delayedRequeue: true}
+		}
+
+		if cluster.Status.ActivePrimaryDataCenter != activePrimary {
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, "DataCenterFailover",
+				fmt.Sprintf("Data center %s is now the primary", activePrimary))
+			cluster.Status.ActivePrimaryDataCenter = activePrimary
+			err = r.Status().Update(context, cluster)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
 	}
 
 	return nil
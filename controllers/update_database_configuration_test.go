@@ -0,0 +1,256 @@
+/*
+ * update_database_configuration_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("updateDatabaseConfiguration", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateDatabaseConfiguration{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	Context("with a reconciled cluster", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not set the storage wiggle settings", func() {
+			Expect(adminClient.DatabaseConfiguration.PerpetualStorageWiggle).To(BeNil())
+			Expect(adminClient.DatabaseConfiguration.StorageMigrationType).To(BeEmpty())
+		})
+	})
+
+	When("enabling a gradual storage migration", func() {
+		BeforeEach(func() {
+			cluster.Spec.DatabaseConfiguration.PerpetualStorageWiggle = pointer.Int(1)
+			cluster.Spec.DatabaseConfiguration.StorageMigrationType = fdbtypes.StorageMigrationTypeGradual
+		})
+
+		When("the version supports the storage wiggle", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+			})
+
+			It("should configure the database", func() {
+				Expect(requeue).To(BeNil())
+				Expect(adminClient.DatabaseConfiguration.PerpetualStorageWiggle).To(Equal(pointer.Int(1)))
+				Expect(adminClient.DatabaseConfiguration.StorageMigrationType).To(Equal(fdbtypes.StorageMigrationTypeGradual))
+			})
+
+			When("the database reports the defaults for unset settings", func() {
+				BeforeEach(func() {
+					cluster.Spec.DatabaseConfiguration.StorageMigrationType = ""
+
+					configuration := cluster.DesiredDatabaseConfiguration()
+					configuration.StorageMigrationType = fdbtypes.StorageMigrationTypeDisabled
+					err = adminClient.ConfigureDatabase(context.TODO(), configuration, false)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should not reconfigure the database", func() {
+					Expect(requeue).To(BeNil())
+					Expect(adminClient.DatabaseConfiguration.StorageMigrationType).To(Equal(fdbtypes.StorageMigrationTypeDisabled))
+				})
+			})
+		})
+
+		When("the version does not support the storage wiggle", func() {
+			It("should not configure the storage wiggle", func() {
+				Expect(requeue).To(BeNil())
+				Expect(adminClient.DatabaseConfiguration.PerpetualStorageWiggle).To(BeNil())
+				Expect(adminClient.DatabaseConfiguration.StorageMigrationType).To(BeEmpty())
+			})
+		})
+	})
+
+	When("changing the storage engine", func() {
+		BeforeEach(func() {
+			cluster.Spec.DatabaseConfiguration.StorageEngine = "ssd-rocksdb-experimental"
+		})
+
+		When("the operator does not know the storage engine for the version", func() {
+			It("should configure the database", func() {
+				Expect(requeue).To(BeNil())
+				Expect(adminClient.DatabaseConfiguration.StorageEngine).To(Equal("ssd-rocksdb-experimental"))
+			})
+
+			It("should record a warning event", func() {
+				Expect(getStorageEngineEvents(cluster)).To(HaveLen(1))
+			})
+		})
+
+		When("the operator knows the storage engine for the version", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+			})
+
+			It("should configure the database", func() {
+				Expect(requeue).To(BeNil())
+				Expect(adminClient.DatabaseConfiguration.StorageEngine).To(Equal("ssd-rocksdb-experimental"))
+			})
+
+			It("should not record a warning event", func() {
+				Expect(getStorageEngineEvents(cluster)).To(BeEmpty())
+			})
+		})
+	})
+
+	When("failing over to another data center", func() {
+		BeforeEach(func() {
+			cluster.Spec.DatabaseConfiguration.Regions = []fdbtypes.Region{
+				{
+					DataCenters: []fdbtypes.DataCenter{
+						{ID: "dc1", Priority: 1},
+						{ID: "dc3", Priority: 1, Satellite: 1},
+					},
+				},
+				{
+					DataCenters: []fdbtypes.DataCenter{
+						{ID: "dc2", Priority: 0},
+						{ID: "dc3", Priority: 1, Satellite: 1},
+					},
+				},
+			}
+			err = adminClient.ConfigureDatabase(context.TODO(), cluster.DesiredDatabaseConfiguration(), false)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.PrimaryDataCenter = "dc2"
+		})
+
+		JustBeforeEach(func() {
+			// Priority changes are rolled out in multiple stages.
+			for requeue != nil && requeue.curError == nil && !requeue.delayedRequeue {
+				requeue = updateDatabaseConfiguration{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			}
+		})
+
+		It("should change the region priorities", func() {
+			Expect(adminClient.DatabaseConfiguration.Regions).To(Equal([]fdbtypes.Region{
+				{
+					DataCenters: []fdbtypes.DataCenter{
+						{ID: "dc2", Priority: 1},
+						{ID: "dc3", Priority: 1, Satellite: 1},
+					},
+				},
+				{
+					DataCenters: []fdbtypes.DataCenter{
+						{ID: "dc1", Priority: 0},
+						{ID: "dc3", Priority: 1, Satellite: 1},
+					},
+				},
+			}))
+		})
+
+		It("should requeue to wait for the failover", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.delayedRequeue).To(BeTrue())
+			Expect(requeue.message).To(Equal("Waiting for data center dc2 to become the primary"))
+		})
+
+		When("the database has failed over", func() {
+			JustBeforeEach(func() {
+				requeue = updateDatabaseConfiguration{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should update the status", func() {
+				_, err = reloadCluster(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(cluster.Status.ActivePrimaryDataCenter).To(Equal("dc2"))
+			})
+		})
+
+		When("the database has not failed over yet", func() {
+			BeforeEach(func() {
+				adminClient.activePrimaryDC = "dc1"
+			})
+
+			JustBeforeEach(func() {
+				requeue = updateDatabaseConfiguration{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.delayedRequeue).To(BeTrue())
+				Expect(requeue.message).To(Equal("Waiting for data center dc2 to become the primary, current primary is dc1 and recovery state is fully_recovered"))
+			})
+		})
+
+		When("the primary data center is not part of the regions", func() {
+			BeforeEach(func() {
+				cluster.Spec.PrimaryDataCenter = "dc3"
+			})
+
+			It("should requeue", func() {
+				Expect(requeue).NotTo(BeNil())
+				Expect(requeue.message).To(Equal("Primary data center dc3 is not the main data center of any region"))
+			})
+
+			It("should not change the region priorities", func() {
+				Expect(adminClient.DatabaseConfiguration.Regions[0].DataCenters[0].ID).To(Equal("dc1"))
+			})
+		})
+	})
+})
+
+// getStorageEngineEvents returns the events about unknown storage engines
+// that were recorded for the cluster.
+func getStorageEngineEvents(cluster *fdbtypes.FoundationDBCluster) []corev1.Event {
+	events := &corev1.EventList{}
+	Expect(k8sClient.List(context.TODO(), events)).To(Succeed())
+
+	matchingEvents := []corev1.Event{}
+	for _, event := range events.Items {
+		if event.InvolvedObject.UID == cluster.ObjectMeta.UID && event.Reason == "UnknownStorageEngine" {
+			matchingEvents = append(matchingEvents, event)
+		}
+	}
+
+	return matchingEvents
+}
@@ -0,0 +1,182 @@
+/*
+ * update_database_knobs.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+)
+
+// databaseKnobCheckInterval is the interval in which the operator reads the
+// database knobs back from the configuration database to detect drift.
+const databaseKnobCheckInterval = 15 * time.Minute
+
+// updateDatabaseKnobs provides a reconciliation step for applying the
+// database knobs from the cluster spec to the configuration database.
+type updateDatabaseKnobs struct{}
+
+// reconcile runs the reconciler's work.
+func (updateDatabaseKnobs) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	if len(cluster.Spec.DatabaseKnobs) == 0 && len(cluster.Status.ManagedDatabaseKnobs) == 0 {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "updateDatabaseKnobs")
+
+	// The knobs are passed to fdbcli as part of a single command string, so
+	// they must be validated before the operator builds the command.
+	err := internal.ValidateDatabaseKnobs(cluster)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	runningVersion := cluster.Status.RunningVersion
+	if runningVersion == "" {
+		runningVersion = cluster.Spec.Version
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(runningVersion)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !version.SupportsConfigurationDatabase() {
+		return &requeue{message: fmt.Sprintf("Version %s does not support database knobs", version), delayedRequeue: true}
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	managedKnobs := make([]string, 0, len(cluster.Spec.DatabaseKnobs))
+	for name := range cluster.Spec.DatabaseKnobs {
+		managedKnobs = append(managedKnobs, name)
+	}
+	sort.Strings(managedKnobs)
+
+	previouslyManaged := make(map[string]bool, len(cluster.Status.ManagedDatabaseKnobs))
+	knobNames := append([]string{}, managedKnobs...)
+	for _, name := range cluster.Status.ManagedDatabaseKnobs {
+		previouslyManaged[name] = true
+		if _, present := cluster.Spec.DatabaseKnobs[name]; !present {
+			knobNames = append(knobNames, name)
+		}
+	}
+
+	currentKnobs, err := adminClient.GetKnobs(context, knobNames)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	knobsToSet := make(map[string]string)
+	for _, name := range managedKnobs {
+		desired := cluster.Spec.DatabaseKnobs[name]
+		current, present := currentKnobs[name]
+		if present && current == desired {
+			continue
+		}
+
+		if previouslyManaged[name] {
+			logger.Info("Database knob has drifted", "knob", name, "current", current, "desired", desired)
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "DatabaseKnobDrift",
+				fmt.Sprintf("Knob %s has value %q in the database, expected %q", name, current, desired))
+		}
+
+		knobsToSet[name] = desired
+	}
+
+	var knobsToClear []string
+	for _, name := range cluster.Status.ManagedDatabaseKnobs {
+		if _, desired := cluster.Spec.DatabaseKnobs[name]; desired {
+			continue
+		}
+
+		if _, present := currentKnobs[name]; present {
+			knobsToClear = append(knobsToClear, name)
+		}
+	}
+
+	if len(knobsToSet) > 0 || len(knobsToClear) > 0 {
+		hasLock, err := r.takeLock(context, cluster, "updating database knobs")
+		if !hasLock {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(knobsToSet) > 0 {
+		logger.Info("Setting database knobs", "knobs", knobsToSet)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "SettingDatabaseKnobs", fmt.Sprintf("Setting %v", knobsToSet))
+		err = adminClient.SetKnobs(context, knobsToSet)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(knobsToClear) > 0 {
+		logger.Info("Clearing database knobs", "knobs", knobsToClear)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ClearingDatabaseKnobs", fmt.Sprintf("Clearing %v", knobsToClear))
+		err = adminClient.ClearKnobs(context, knobsToClear)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(managedKnobs) == 0 {
+		managedKnobs = nil
+	}
+
+	if !equality.Semantic.DeepEqual(cluster.Status.ManagedDatabaseKnobs, managedKnobs) {
+		cluster.Status.ManagedDatabaseKnobs = managedKnobs
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(knobsToSet) == 0 {
+		return nil
+	}
+
+	// Read the knobs back to make sure the configuration transaction was
+	// committed.
+	currentKnobs, err = adminClient.GetKnobs(context, managedKnobs)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	for name, desired := range knobsToSet {
+		if currentKnobs[name] != desired {
+			return &requeue{message: fmt.Sprintf("Waiting for database knob %s to be set", name), delayedRequeue: true}
+		}
+	}
+
+	return nil
+}
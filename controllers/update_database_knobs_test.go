@@ -0,0 +1,199 @@
+/*
+ * update_database_knobs_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("update_database_knobs", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+	var expectedError string
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		expectedError = ""
+		cluster.Status.RunningVersion = "7.1.0"
+		cluster.Spec.DatabaseKnobs = map[string]string{
+			"min_trace_severity": "10",
+			"max_trace_lines":    "1000",
+		}
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateDatabaseKnobs{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil && expectedError == "" {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	Context("with new knobs", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should set the knobs", func() {
+			Expect(adminClient.knobs).To(Equal(map[string]string{
+				"min_trace_severity": "10",
+				"max_trace_lines":    "1000",
+			}))
+		})
+
+		It("should record the managed knobs in the status", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.ManagedDatabaseKnobs).To(Equal([]string{"max_trace_lines", "min_trace_severity"}))
+		})
+	})
+
+	Context("with a knob that has drifted", func() {
+		BeforeEach(func() {
+			adminClient.knobs = map[string]string{
+				"min_trace_severity": "20",
+				"max_trace_lines":    "1000",
+			}
+			cluster.Status.ManagedDatabaseKnobs = []string{"max_trace_lines", "min_trace_severity"}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should reset the knob", func() {
+			Expect(adminClient.knobs["min_trace_severity"]).To(Equal("10"))
+		})
+	})
+
+	Context("with a knob that was removed from the spec", func() {
+		BeforeEach(func() {
+			adminClient.knobs = map[string]string{
+				"min_trace_severity": "10",
+				"max_trace_lines":    "1000",
+				"old_knob":           "1",
+			}
+			cluster.Status.ManagedDatabaseKnobs = []string{"max_trace_lines", "min_trace_severity", "old_knob"}
+			err = k8sClient.Status().Update(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+			cluster.Spec.DatabaseKnobs = map[string]string{
+				"min_trace_severity": "10",
+				"max_trace_lines":    "1000",
+			}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should clear the knob", func() {
+			Expect(adminClient.knobs).To(Equal(map[string]string{
+				"min_trace_severity": "10",
+				"max_trace_lines":    "1000",
+			}))
+		})
+
+		It("should remove the knob from the status", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.ManagedDatabaseKnobs).To(Equal([]string{"max_trace_lines", "min_trace_severity"}))
+		})
+	})
+
+	Context("with a knob that is not managed by the operator", func() {
+		BeforeEach(func() {
+			adminClient.knobs = map[string]string{"other_knob": "1"}
+		})
+
+		It("should keep the knob", func() {
+			Expect(adminClient.knobs).To(HaveKeyWithValue("other_knob", "1"))
+		})
+	})
+
+	When("the version does not support the configuration database", func() {
+		BeforeEach(func() {
+			cluster.Status.RunningVersion = fdbtypes.Versions.NextMajorVersion.String()
+		})
+
+		It("should requeue", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.delayedRequeue).To(BeTrue())
+			Expect(requeue.message).To(Equal("Version 7.0.0 does not support database knobs"))
+		})
+
+		It("should not set the knobs", func() {
+			Expect(adminClient.knobs).To(BeEmpty())
+		})
+	})
+
+	When("a knob value contains another fdbcli command", func() {
+		BeforeEach(func() {
+			cluster.Spec.DatabaseKnobs["min_trace_severity"] = "10; configure single"
+			expectedError = "found the following databaseKnobs violations:\ninvalid value for databaseKnob min_trace_severity: \"10; configure single\""
+		})
+
+		It("should return an error", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.curError).To(MatchError(expectedError))
+		})
+
+		It("should not set the knobs", func() {
+			Expect(adminClient.knobs).To(BeEmpty())
+		})
+	})
+
+	When("a knob name is invalid", func() {
+		BeforeEach(func() {
+			cluster.Spec.DatabaseKnobs["max_trace_lines 10; configure"] = "single"
+			expectedError = "found the following databaseKnobs violations:\ninvalid databaseKnob name: max_trace_lines 10; configure"
+		})
+
+		It("should return an error", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.curError).To(MatchError(expectedError))
+		})
+
+		It("should not set the knobs", func() {
+			Expect(adminClient.knobs).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,89 @@
+/*
+ * update_dr_agents.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	corev1 "k8s.io/api/core/v1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateDRAgents provides a reconciliation step for updating the
+// deployment for the DR agents.
+type updateDRAgents struct{}
+
+// reconcile runs the reconciler's work.
+func (u updateDRAgents) reconcile(r *FoundationDBDRReconciler, context ctx.Context, dr *fdbtypes.FoundationDBDR) *requeue {
+	deploymentName := fmt.Sprintf("%s-dr-agents", dr.ObjectMeta.Name)
+	existingDeployment := &appsv1.Deployment{}
+	needCreation := false
+
+	err := r.Get(context, client.ObjectKey{Name: deploymentName, Namespace: dr.Namespace}, existingDeployment)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			needCreation = true
+		} else {
+			return &requeue{curError: err}
+		}
+	}
+
+	deployment, err := internal.GetDRDeployment(dr)
+	if err != nil {
+		r.Recorder.Event(dr, corev1.EventTypeWarning, "GetDRDeployment", err.Error())
+		return &requeue{curError: err}
+	}
+
+	if deployment != nil && deployment.ObjectMeta.Name != deploymentName {
+		return &requeue{curError: fmt.Errorf("inconsistent deployment names: %s != %s", deployment.ObjectMeta.Name, deploymentName)}
+	}
+
+	if needCreation && deployment != nil {
+		err = internal.ApplyObject(r, context, deployment)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+	if !needCreation && deployment != nil {
+		if !metadataMatches(existingDeployment.ObjectMeta, deployment.ObjectMeta) {
+			err = internal.ApplyObject(r, context, deployment)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+	}
+
+	if !needCreation && deployment == nil {
+		err = r.Delete(context, existingDeployment)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+	return nil
+}
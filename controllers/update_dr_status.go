@@ -0,0 +1,144 @@
+/*
+ * update_dr_status.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"reflect"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateDRStatus provides a reconciliation step for updating the status in the
+// CRD.
+type updateDRStatus struct{}
+
+// reconcile runs the reconciler's work.
+func (s updateDRStatus) reconcile(r *FoundationDBDRReconciler, context ctx.Context, dr *fdbtypes.FoundationDBDR) *requeue {
+	status := fdbtypes.FoundationDBDRStatus{}
+	status.Generations.Reconciled = dr.Status.Generations.Reconciled
+
+	drDeployments := &appsv1.DeploymentList{}
+	err := r.List(context, drDeployments, client.InNamespace(dr.Namespace), client.MatchingLabels(map[string]string{fdbtypes.DRDeploymentLabel: string(dr.ObjectMeta.UID)}))
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	desiredDRDeployment, err := internal.GetDRDeployment(dr)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if len(drDeployments.Items) == 1 && desiredDRDeployment != nil {
+		drDeployment := drDeployments.Items[0]
+		status.AgentCount = int(drDeployment.Status.ReadyReplicas)
+		if status.AgentCount > int(drDeployment.Status.UpdatedReplicas) {
+			status.AgentCount = int(drDeployment.Status.UpdatedReplicas)
+		}
+		generationsMatch := drDeployment.Status.ObservedGeneration == drDeployment.ObjectMeta.Generation
+
+		annotationChange := mergeAnnotations(&drDeployment.ObjectMeta, desiredDRDeployment.ObjectMeta)
+
+		metadataMatch := !annotationChange &&
+			reflect.DeepEqual(drDeployment.ObjectMeta.Labels, desiredDRDeployment.ObjectMeta.Labels)
+
+		status.DeploymentConfigured = generationsMatch && metadataMatch
+
+		if r.InSimulation {
+			status.AgentCount = int(*drDeployment.Spec.Replicas)
+			status.DeploymentConfigured = metadataMatch
+		}
+	} else if len(drDeployments.Items) == 0 && desiredDRDeployment == nil {
+		status.DeploymentConfigured = true
+	} else {
+		status.DeploymentConfigured = false
+	}
+
+	// We check the direction from the spec first. If the replication is not
+	// running in that direction, we check if it is running in the opposite
+	// direction, which means that we have to switch it.
+	sourceClusterName := dr.Spec.SourceClusterName
+	destinationClusterName := dr.Spec.DestinationClusterName
+	liveStatus, err := getLiveDRStatus(r, context, dr, sourceClusterName, destinationClusterName)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !liveStatus.Running {
+		reverseStatus, err := getLiveDRStatus(r, context, dr, destinationClusterName, sourceClusterName)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		if reverseStatus.Running {
+			liveStatus = reverseStatus
+			sourceClusterName, destinationClusterName = destinationClusterName, sourceClusterName
+		}
+	}
+
+	status.DRDetails = &fdbtypes.FoundationDBDRStatusDRDetails{
+		SourceClusterName:      sourceClusterName,
+		DestinationClusterName: destinationClusterName,
+		Running:                liveStatus.Running,
+		CompleteCopy:           liveStatus.CompleteCopy,
+	}
+
+	if liveStatus.Running {
+		status.DRDetails.Lag = &metav1.Duration{Duration: time.Duration(liveStatus.SecondsBehind * float64(time.Second))}
+	}
+
+	originalStatus := dr.Status.DeepCopy()
+
+	dr.Status = status
+
+	_, err = dr.CheckReconciliation()
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !reflect.DeepEqual(dr.Status, *originalStatus) {
+		err = r.Status().Update(context, dr)
+		if err != nil {
+			log.Error(err, "Error updating DR status", "namespace", dr.Namespace, "dr", dr.Name)
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
+
+// getLiveDRStatus fetches the live status of the DR replication between the
+// given clusters.
+func getLiveDRStatus(r *FoundationDBDRReconciler, context ctx.Context, dr *fdbtypes.FoundationDBDR, sourceClusterName string, destinationClusterName string) (*fdbtypes.FoundationDBLiveDRStatus, error) {
+	adminClient, sourceConnectionString, err := r.adminClientForDR(context, dr, sourceClusterName, destinationClusterName)
+	if err != nil {
+		return nil, err
+	}
+	defer adminClient.Close()
+
+	return adminClient.GetDRStatus(context, sourceConnectionString, dr.DRTag())
+}
@@ -21,6 +21,11 @@
 package controllers
 
 import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"k8s.io/apimachinery/pkg/types"
+
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -247,5 +252,87 @@ var _ = Describe("Update labels", func() {
 				},
 			},
 		),
+		Entry("Missing labels on the current metadata",
+			testCase{
+				pod: &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							fdbtypes.LastSpecKey: "1",
+						},
+					},
+				},
+				metadata: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						fdbtypes.LastSpecKey: "1",
+					},
+					Labels: map[string]string{
+						fdbtypes.FDBProcessClassLabel: "log",
+					},
+				},
+				expected: false,
+				expectedMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						fdbtypes.LastSpecKey: "1",
+					},
+					Labels: map[string]string{
+						fdbtypes.FDBProcessClassLabel: "log",
+					},
+				},
+			},
+		),
 	)
 })
+
+var _ = Describe("updateLabels", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateLabels{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	When("another controller has injected a container", func() {
+		var podName string
+
+		BeforeEach(func() {
+			pods := &corev1.PodList{}
+			err = k8sClient.List(context.TODO(), pods, internal.GetPodListOptions(cluster, fdbtypes.ProcessClassStorage, "")...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).NotTo(BeEmpty())
+
+			pod := pods.Items[0]
+			podName = pod.Name
+			pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{Name: "injected", Image: "test/injected:1.0.0"})
+			pod.ObjectMeta.Annotations["test/injected"] = "true"
+			err = k8sClient.Update(context.TODO(), &pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			cluster.Spec.LabelConfig.ResourceLabels = map[string]string{"fdb-test-label": "true"}
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should update the labels and keep the injected container", func() {
+			pod := &corev1.Pod{}
+			err = k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: podName}, pod)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.ObjectMeta.Labels).To(HaveKeyWithValue("fdb-test-label", "true"))
+			Expect(pod.ObjectMeta.Annotations).To(HaveKeyWithValue("test/injected", "true"))
+
+			containerNames := make([]string, 0, len(pod.Spec.Containers))
+			for _, container := range pod.Spec.Containers {
+				containerNames = append(containerNames, container.Name)
+			}
+			Expect(containerNames).To(ContainElement("injected"))
+		})
+	})
+})
@@ -0,0 +1,95 @@
+/*
+ * update_metrics_exporter.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	corev1 "k8s.io/api/core/v1"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateMetricsExporter provides a reconciliation step for updating the
+// deployment for the metrics exporter.
+type updateMetricsExporter struct{}
+
+// reconcile runs the reconciler's work.
+func (u updateMetricsExporter) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	logger := getLogger(context, cluster, "updateMetricsExporter")
+	deploymentName := fmt.Sprintf("%s-metrics-exporter", cluster.Name)
+	existingDeployment := &appsv1.Deployment{}
+	needCreation := false
+
+	err := r.Get(context, client.ObjectKey{Name: deploymentName, Namespace: cluster.Namespace}, existingDeployment)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			needCreation = true
+		} else {
+			return &requeue{curError: err}
+		}
+	}
+
+	deployment, err := internal.GetMetricsExporterDeployment(cluster)
+	if err != nil {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "GetMetricsExporterDeployment", err.Error())
+		return &requeue{curError: err}
+	}
+
+	if deployment != nil && deployment.ObjectMeta.Name != deploymentName {
+		return &requeue{curError: fmt.Errorf("inconsistent deployment names: %s != %s", deployment.ObjectMeta.Name, deploymentName)}
+	}
+
+	if needCreation && deployment != nil {
+		logger.Info("Creating metrics exporter deployment")
+		err = internal.ApplyObject(r, context, deployment)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if !needCreation && deployment != nil {
+		if !metadataMatches(existingDeployment.ObjectMeta, deployment.ObjectMeta) {
+			logger.Info("Updating metrics exporter deployment")
+			err = internal.ApplyObject(r, context, deployment)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+	}
+
+	if !needCreation && deployment == nil {
+		logger.Info("Deleting metrics exporter deployment")
+		err = r.Delete(context, existingDeployment)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+/*
+ * update_metrics_exporter_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("updateMetricsExporter", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateMetricsExporter{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	getDeployment := func() (*appsv1.Deployment, error) {
+		deployment := &appsv1.Deployment{}
+		err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: "operator-test-1-metrics-exporter"}, deployment)
+		return deployment, err
+	}
+
+	enableExporter := func(image string) {
+		enabled := true
+		cluster.Spec.MetricsExporter = fdbtypes.MetricsExporterConfig{
+			Enabled: &enabled,
+			PodTemplateSpec: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:  "exporter",
+						Image: image,
+					}},
+				},
+			},
+		}
+	}
+
+	Context("with the exporter disabled", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not create a deployment", func() {
+			_, err := getDeployment()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("with the exporter enabled", func() {
+		BeforeEach(func() {
+			enableExporter("test/exporter:1.0.0")
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should create the deployment", func() {
+			deployment, err := getDeployment()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("test/exporter:1.0.0"))
+		})
+
+		When("the exporter image changes", func() {
+			JustBeforeEach(func() {
+				Expect(requeue).To(BeNil())
+				enableExporter("test/exporter:1.1.0")
+				requeue = updateMetricsExporter{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should update the deployment", func() {
+				deployment, err := getDeployment()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("test/exporter:1.1.0"))
+			})
+		})
+
+		When("the exporter is disabled again", func() {
+			JustBeforeEach(func() {
+				Expect(requeue).To(BeNil())
+				cluster.Spec.MetricsExporter.Enabled = nil
+				requeue = updateMetricsExporter{}.reconcile(clusterReconciler, context.TODO(), cluster)
+			})
+
+			It("should not requeue", func() {
+				Expect(requeue).To(BeNil())
+			})
+
+			It("should delete the deployment", func() {
+				_, err := getDeployment()
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+	})
+
+	Context("with the exporter enabled without an image", func() {
+		BeforeEach(func() {
+			enableExporter("")
+		})
+
+		It("should requeue with an error", func() {
+			Expect(requeue).NotTo(BeNil())
+			Expect(requeue.curError).To(HaveOccurred())
+		})
+
+		It("should not create a deployment", func() {
+			_, err := getDeployment()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
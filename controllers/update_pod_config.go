@@ -37,7 +37,7 @@ type updatePodConfig struct{}
 
 // reconcile runs the reconciler's work.
 func (updatePodConfig) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updatePodConfig")
+	logger := getLogger(context, cluster, "updatePodConfig")
 	configMap, err := internal.GetConfigMap(cluster)
 	if err != nil {
 		return &requeue{curError: err}
@@ -74,16 +74,16 @@ func (updatePodConfig) reconcile(r *FoundationDBClusterReconciler, context ctx.C
 			continue
 		}
 
-		serverPerPod, err := internal.GetStorageServersPerPodForPod(pod)
+		processClass, err := podmanager.GetProcessClass(cluster, pod)
 		if err != nil {
-			curLogger.Error(err, "Error when receiving storage server per Pod")
+			curLogger.Error(err, "Error when fetching process class from Pod")
 			errs = append(errs, err)
 			continue
 		}
 
-		processClass, err := podmanager.GetProcessClass(cluster, pod)
+		serverPerPod, err := internal.GetServersPerPodForPod(pod, processClass)
 		if err != nil {
-			curLogger.Error(err, "Error when fetching process class from Pod")
+			curLogger.Error(err, "Error when receiving servers per Pod")
 			errs = append(errs, err)
 			continue
 		}
@@ -39,7 +39,7 @@ type updatePods struct{}
 
 // reconcile runs the reconciler's work.
 func (updatePods) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updatePods")
+	logger := getLogger(context, cluster, "updatePods")
 
 	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
 	if err != nil {
@@ -116,11 +116,23 @@ func (updatePods) reconcile(r *FoundationDBClusterReconciler, context ctx.Contex
 	}
 
 	if len(updates) > 0 {
-		if cluster.Spec.UpdatePodsByReplacement {
+		if cluster.GetPodUpdateStrategy() == fdbtypes.PodUpdateStrategyReplacement {
 			logger.Info("Requeuing reconciliation to replace pods")
 			return &requeue{message: "Requeueing reconciliation to replace pods"}
 		}
 
+		if cluster.GetPodUpdateStrategy() == fdbtypes.PodUpdateStrategyManual && !cluster.PodUpdatesApproved() {
+			logger.Info("Pod updates require approval", "generation", cluster.ObjectMeta.Generation)
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, "NeedsPodUpdateApproval",
+				fmt.Sprintf("Spec requires recreating some pods, set the %s annotation to %d to approve the update", fdbtypes.ApprovePodUpdatesAnnotation, cluster.ObjectMeta.Generation))
+			cluster.Status.Generations.NeedsPodDeletion = cluster.ObjectMeta.Generation
+			err = r.Status().Update(context, cluster)
+			if err != nil {
+				logger.Error(err, "Error updating cluster status")
+			}
+			return &requeue{message: "Pod updates require approval"}
+		}
+
 		var enabled = cluster.Spec.AutomationOptions.DeletePods
 		if enabled != nil && !*enabled {
 			r.Recorder.Event(cluster, corev1.EventTypeNormal,
@@ -149,7 +161,7 @@ func (updatePods) reconcile(r *FoundationDBClusterReconciler, context ctx.Contex
 			return &requeue{message: "Reconciliation requires deleting pods, but deletion is not currently safe", delay: podSchedulingDelayDuration}
 		}
 
-		hasLock, err := r.takeLock(cluster, "updating pods")
+		hasLock, err := r.takeLock(context, cluster, "updating pods")
 		if !hasLock {
 			return &requeue{curError: err}
 		}
@@ -39,7 +39,7 @@ type updateSidecarVersions struct{}
 
 // reconcile runs the reconciler's work.
 func (updateSidecarVersions) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updateSidecarVersions")
+	logger := getLogger(context, cluster, "updateSidecarVersions")
 	pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, "", "")...)
 	if err != nil {
 		return &requeue{curError: err}
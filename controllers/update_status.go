@@ -25,6 +25,7 @@ import (
 	"fmt"
 	"reflect"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/podmanager"
@@ -35,6 +36,8 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -47,17 +50,44 @@ type updateStatus struct{}
 
 // reconcile runs the reconciler's work.
 func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updateStatus")
+	logger := getLogger(context, cluster, "updateStatus")
 	originalStatus := cluster.Status.DeepCopy()
 	status := fdbtypes.FoundationDBClusterStatus{}
 	status.Generations.Reconciled = cluster.Status.Generations.Reconciled
 
-	// Initialize with the current desired storage servers per Pod
+	// The ports are recorded once, so that later changes to the routing
+	// config can be rejected.
+	status.ProcessPorts = cluster.Status.ProcessPorts
+	status.ReconciliationProgress = cluster.Status.ReconciliationProgress
+	if status.ProcessPorts == nil {
+		status.ProcessPorts = &fdbtypes.ProcessPortsStatus{
+			BasePort:   cluster.GetBasePort(),
+			PortStride: cluster.GetPortStride(),
+		}
+	}
+
+	// The pending exclusion is only cleared by the subreconciler that issues
+	// it.
+	status.PendingExclusion = cluster.Status.PendingExclusion
+
+	// Initialize with the current desired storage and log servers per Pod
 	status.StorageServersPerDisk = []int{cluster.GetStorageServersPerPod()}
+	status.LogServersPerDisk = []int{cluster.GetLogServersPerPod()}
 
 	var databaseStatus *fdbtypes.FoundationDBStatus
 	processMap := make(map[string][]fdbtypes.FoundationDBStatusProcessInfo)
 
+	// Once a cluster was observed, the operator keeps treating it as an
+	// external database, see validateObserveOnly.
+	status.ObservedOnly = cluster.Status.ObservedOnly || cluster.Spec.ObserveOnly
+
+	// The operator does not bootstrap clusters that it only observes, so the
+	// connection is based on the seed connection string.
+	if cluster.Spec.ObserveOnly && cluster.Status.ConnectionString == "" {
+		cluster.Status.ConnectionString = cluster.Spec.SeedConnectionString
+		cluster.Status.RunningVersion = cluster.Spec.Version
+	}
+
 	if cluster.Status.ConnectionString == "" {
 		databaseStatus = &fdbtypes.FoundationDBStatus{
 			Cluster: fdbtypes.FoundationDBStatusClusterInfo{
@@ -67,7 +97,7 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 			},
 		}
 	} else {
-		version, connectionString, err := tryConnectionOptions(cluster, r)
+		version, connectionString, err := tryConnectionOptions(context, cluster, r)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -80,10 +110,29 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 		}
 		defer adminClient.Close()
 
-		databaseStatus, err = adminClient.GetStatus()
+		databaseStatus, err = adminClient.GetStatus(context)
 		if err != nil {
 			return &requeue{curError: err}
 		}
+
+		databaseMetrics.set(client.ObjectKeyFromObject(cluster), databaseStatus, internal.GetStatusSummary(databaseStatus, time.Now()))
+
+		// The coordinators can be changed outside of the operator, so the
+		// connection string that the database stores is the source of truth.
+		if databaseStatus.Client.DatabaseStatus.Available {
+			liveConnectionString, err := adminClient.GetLiveConnectionString(context)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			liveConnectionString = strings.TrimSpace(liveConnectionString)
+			if liveConnectionString != "" && liveConnectionString != cluster.Status.ConnectionString {
+				logger.Info("Updating connection string from the database", "connectionString", liveConnectionString, "previousConnectionString", cluster.Status.ConnectionString)
+				r.Recorder.Event(cluster, corev1.EventTypeNormal, "ConnectionStringDrift",
+					fmt.Sprintf("The coordinators were changed in the database, updating the connection string from %s to %s", cluster.Status.ConnectionString, liveConnectionString))
+				cluster.Status.ConnectionString = liveConnectionString
+			}
+		}
 	}
 
 	for _, process := range databaseStatus.Cluster.Processes {
@@ -98,6 +147,9 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 	status.HasListenIPsForAllPods = cluster.NeedsExplicitListenAddress()
 	status.DatabaseConfiguration = databaseStatus.Cluster.DatabaseConfiguration.NormalizeConfiguration()
 	cluster.ClearMissingVersionFlags(&status.DatabaseConfiguration)
+	if len(status.DatabaseConfiguration.Regions) > 0 {
+		status.ActivePrimaryDataCenter = internal.GetActivePrimaryDataCenter(databaseStatus)
+	}
 	status.Configured = cluster.Status.Configured || (databaseStatus.Client.DatabaseStatus.Available && databaseStatus.Cluster.Layers.Error != "configurationMissing")
 
 	if cluster.Spec.MainContainer.EnableTLS {
@@ -119,6 +171,22 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 		status.Health.Healthy = databaseStatus.Client.DatabaseStatus.Healthy
 		status.Health.FullReplication = databaseStatus.Cluster.FullReplication
 		status.Health.DataMovementPriority = databaseStatus.Cluster.Data.MovingData.HighestPriority
+		if status.Health.Available {
+			version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+
+			if version.HasZoneFaultToleranceInStatus() {
+				faultTolerance := internal.GetFaultTolerance(databaseStatus)
+				status.Health.FaultTolerance = &faultTolerance
+			}
+		}
+		status.StorageWiggle = getStorageWiggleStatus(databaseStatus)
+
+		if cluster.GetRoleCountRecommendationsEnabled() {
+			status.RecommendedRoleCounts = internal.GetRecommendedRoleCounts(cluster, databaseStatus, cluster.Status.RecommendedRoleCounts)
+		}
 	}
 
 	cluster.Status.RequiredAddresses = status.RequiredAddresses
@@ -203,6 +271,14 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 		status.ConnectionString = cluster.Spec.SeedConnectionString
 	}
 
+	status.ManagedTagThrottles = cluster.Status.ManagedTagThrottles
+	status.ManagedTagQuotas = cluster.Status.ManagedTagQuotas
+	status.ManagedDatabaseKnobs = cluster.Status.ManagedDatabaseKnobs
+	status.ConsistencyCheck = cluster.Status.ConsistencyCheck
+	status.SeedRestore = cluster.Status.SeedRestore
+	status.SeedRestorePending = cluster.Status.SeedRestorePending
+	status.LocalityExclusions = cluster.Status.LocalityExclusions
+
 	if cluster.Spec.PendingRemovals != nil {
 		for podName, address := range cluster.Spec.PendingRemovals {
 			pods := &corev1.PodList{}
@@ -259,7 +335,7 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 			coordinatorStatus[coordinator.Address.String()] = false
 		}
 
-		coordinatorsValid, _, err := checkCoordinatorValidity(cluster, databaseStatus, coordinatorStatus)
+		coordinatorsValid, _, err := checkCoordinatorValidity(context, cluster, databaseStatus, coordinatorStatus)
 		if err != nil {
 			return &requeue{curError: err}
 		}
@@ -282,21 +358,54 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 		status.Locks.DenyList = denyList
 	}
 
-	// Sort the storage servers per disk to prevent a reordering to issue a new reconcile loop.
+	// Sort the storage and log servers per disk to prevent a reordering to issue a new reconcile loop.
 	sort.Ints(status.StorageServersPerDisk)
+	sort.Ints(status.LogServersPerDisk)
 	// Sort ProcessGroups by ProcessGroupID otherwise this can result in an endless loop when the
 	// order changes.
 	sort.SliceStable(status.ProcessGroups, func(i, j int) bool {
 		return status.ProcessGroups[i].ProcessGroupID < status.ProcessGroups[j].ProcessGroupID
 	})
 
+	// The process counts of an observed cluster are not managed by the
+	// operator.
+	if !cluster.Spec.ObserveOnly {
+		desiredCounts, err := cluster.GetProcessCountsWithDefaults()
+		if err != nil {
+			return &requeue{curError: err}
+		}
+
+		status.DesiredProcessGroups = desiredCounts.Total()
+	}
+
+	for _, processGroup := range status.ProcessGroups {
+		if !processGroup.Remove && len(processGroup.ProcessGroupConditions) == 0 {
+			status.ReadyProcessGroups++
+		}
+
+		if !processGroup.Remove && isOutdated(processGroup) {
+			status.OutdatedProcessGroups++
+			status.OutdatedProcessGroupIDs = append(status.OutdatedProcessGroupIDs, processGroup.ProcessGroupID)
+		}
+	}
+
+	// Keep the existing conditions so that their transition times are
+	// preserved.
+	status.Conditions = cluster.Status.Conditions
+
 	cluster.Status = status
 
-	_, err = cluster.CheckReconciliation(log)
-	if err != nil {
-		return &requeue{curError: err}
+	if !cluster.Spec.ObserveOnly {
+		_, err = cluster.CheckReconciliation(logger)
+		if err != nil {
+			return &requeue{curError: err}
+		}
 	}
 
+	wasStalled := meta.IsStatusConditionTrue(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+	setClusterConditions(cluster)
+	recordReconciliationStalled(r, context, cluster, wasStalled)
+
 	// See: https://github.com/kubernetes-sigs/kubebuilder/issues/592
 	// If we use the default reflect.DeepEqual method it will be recreating the
 	// status multiple times because the pointers are different.
@@ -311,6 +420,247 @@ func (updateStatus) reconcile(r *FoundationDBClusterReconciler, context ctx.Cont
 	return nil
 }
 
+// getStorageWiggleStatus builds the summary of the perpetual storage wiggle
+// from the database status. This returns nil if the wiggle is disabled.
+func getStorageWiggleStatus(databaseStatus *fdbtypes.FoundationDBStatus) *fdbtypes.StorageWiggleStatus {
+	perpetualStorageWiggle := databaseStatus.Cluster.DatabaseConfiguration.PerpetualStorageWiggle
+	if perpetualStorageWiggle == nil || *perpetualStorageWiggle == 0 {
+		return nil
+	}
+
+	wiggler := databaseStatus.Cluster.StorageWiggler
+	return &fdbtypes.StorageWiggleStatus{
+		FinishedRounds:           wiggler.Primary.FinishedRound,
+		FinishedWiggles:          wiggler.Primary.FinishedWiggle,
+		LastRoundFinishTimestamp: int64(wiggler.Primary.LastRoundFinishTimestamp),
+		WigglingAddresses:        wiggler.WiggleServerAddresses,
+	}
+}
+
+// setClusterConditions updates the conditions in the cluster status based on
+// the rest of the status. The transition time of a condition only changes when
+// its status changes.
+func setClusterConditions(cluster *fdbtypes.FoundationDBCluster) {
+	setCondition := func(conditionType string, value bool, reason string, message string) {
+		conditionStatus := metav1.ConditionFalse
+		if value {
+			conditionStatus = metav1.ConditionTrue
+		}
+
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: cluster.ObjectMeta.Generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	if cluster.Status.Health.Available {
+		setCondition(fdbtypes.ClusterConditionAvailable, true, "DatabaseAvailable", "The database is available")
+	} else {
+		setCondition(fdbtypes.ClusterConditionAvailable, false, "DatabaseUnavailable", "The database is unavailable")
+	}
+
+	reconciled := false
+	reconciledReason := "ReconciliationPending"
+	reconciledMessage := fmt.Sprintf("Generation %d is not reconciled", cluster.ObjectMeta.Generation)
+	if cluster.Spec.ObserveOnly {
+		reconciledReason = "ObserveOnly"
+		reconciledMessage = "The cluster is only observed by the operator"
+	} else if cluster.Status.Generations.Reconciled == cluster.ObjectMeta.Generation {
+		reconciled = true
+		reconciledReason = "Reconciled"
+		reconciledMessage = fmt.Sprintf("Reconciled generation %d", cluster.ObjectMeta.Generation)
+	} else if pendingStates := getPendingReconciliationStates(cluster); len(pendingStates) > 0 {
+		reconciledMessage = fmt.Sprintf("%s, pending: %s", reconciledMessage, strings.Join(pendingStates, ", "))
+	}
+
+	// The reconciliation stalled timeout is measured from the transition time
+	// of this condition, so a cluster that is no longer only observed starts
+	// with a new transition time.
+	reconciledCondition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+	if reconciledCondition != nil && reconciledCondition.Reason != reconciledReason {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+	}
+	setCondition(fdbtypes.ClusterConditionFullyReconciled, reconciled, reconciledReason, reconciledMessage)
+
+	setReconciliationStalledCondition(cluster)
+
+	removals := 0
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove {
+			removals++
+		}
+	}
+
+	if removals > 0 {
+		setCondition(fdbtypes.ClusterConditionReplacingInstances, true, "ProcessGroupsPendingRemoval",
+			fmt.Sprintf("%d process group(s) are marked for removal", removals))
+	} else {
+		setCondition(fdbtypes.ClusterConditionReplacingInstances, false, "NoProcessGroupsPendingRemoval", "No process groups are marked for removal")
+	}
+
+	if cluster.Status.RunningVersion != "" && cluster.Status.RunningVersion != cluster.Spec.Version {
+		setCondition(fdbtypes.ClusterConditionUpgradeInProgress, true, "VersionMismatch",
+			fmt.Sprintf("Changing the version from %s to %s", cluster.Status.RunningVersion, cluster.Spec.Version))
+	} else {
+		setCondition(fdbtypes.ClusterConditionUpgradeInProgress, false, "VersionMatches",
+			fmt.Sprintf("Running version %s", cluster.Spec.Version))
+	}
+
+	if cluster.Spec.ObserveOnly {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, fdbtypes.ClusterConditionConfigurationPending)
+	} else if !cluster.Status.Configured {
+		setCondition(fdbtypes.ClusterConditionConfigurationPending, true, "DatabaseNotConfigured", "The database has not been configured yet")
+	} else if cluster.Status.Generations.NeedsConfigurationChange != 0 {
+		setCondition(fdbtypes.ClusterConditionConfigurationPending, true, "ConfigurationChangePending",
+			"The database configuration does not match the spec")
+	} else {
+		setCondition(fdbtypes.ClusterConditionConfigurationPending, false, "ConfigurationMatches",
+			"The database configuration matches the spec")
+	}
+
+	if cluster.Status.Health.FaultTolerance == nil {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, fdbtypes.ClusterConditionFaultTolerance)
+	} else if *cluster.Status.Health.FaultTolerance > 0 || cluster.DesiredFaultTolerance() == 0 {
+		setCondition(fdbtypes.ClusterConditionFaultTolerance, true, "FaultToleranceAvailable",
+			fmt.Sprintf("The cluster can lose %d fault domain(s) without losing data or availability", *cluster.Status.Health.FaultTolerance))
+	} else {
+		setCondition(fdbtypes.ClusterConditionFaultTolerance, false, "NoFaultTolerance",
+			"The cluster cannot lose any fault domain without losing data or availability")
+	}
+
+	// The QuotaExceeded condition is set when creating a resource fails, and
+	// is resolved once all process groups have their pods and PVCs.
+	quotaCondition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionQuotaExceeded)
+	if quotaCondition != nil && quotaCondition.Status == metav1.ConditionTrue && !hasMissingResources(cluster) {
+		setCondition(fdbtypes.ClusterConditionQuotaExceeded, false, "ResourcesCreated", "All pods and PVCs have been created")
+	}
+
+	// The DatabaseUnavailable condition is set when the operator skips changes
+	// to the database, and is resolved once the database is available again.
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, fdbtypes.ClusterConditionDatabaseUnavailable) && cluster.Status.Health.Available {
+		setCondition(fdbtypes.ClusterConditionDatabaseUnavailable, false, "DatabaseAvailable", "The database is available")
+	}
+}
+
+// setReconciliationStalledCondition sets the ReconciliationStalled condition
+// once the FullyReconciled condition has been false for longer than the
+// reconciliation stalled timeout. The condition is only added to the status
+// when the reconciliation stalls, and is set to false once the cluster
+// converges again.
+func setReconciliationStalledCondition(cluster *fdbtypes.FoundationDBCluster) {
+	if cluster.Spec.ObserveOnly {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+		return
+	}
+
+	reconciledCondition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+	timeout := cluster.GetReconciliationStalledTimeout()
+	if reconciledCondition != nil && reconciledCondition.Status == metav1.ConditionFalse && time.Since(reconciledCondition.LastTransitionTime.Time) >= timeout {
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               fdbtypes.ClusterConditionReconciliationStalled,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: cluster.ObjectMeta.Generation,
+			Reason:             "ReconciliationTimeoutExceeded",
+			Message: fmt.Sprintf("Generation %d has not been reconciled since %s, which is longer than %s",
+				cluster.ObjectMeta.Generation, reconciledCondition.LastTransitionTime.UTC().Format(time.RFC3339), timeout),
+		})
+		return
+	}
+
+	if meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled) == nil {
+		return
+	}
+
+	reason := "Reconciled"
+	message := fmt.Sprintf("Reconciled generation %d", cluster.ObjectMeta.Generation)
+	if reconciledCondition == nil || reconciledCondition.Status != metav1.ConditionTrue {
+		reason = "ReconciliationPending"
+		message = fmt.Sprintf("Generation %d has not been reconciled for less than %s", cluster.ObjectMeta.Generation, timeout)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               fdbtypes.ClusterConditionReconciliationStalled,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: cluster.ObjectMeta.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+}
+
+// setReconciliationPendingCondition sets the FullyReconciled condition to
+// false when it was set for an earlier generation of the cluster. This starts
+// the reconciliation stalled timeout for a new generation, even if the
+// reconciliation fails before the status is updated.
+func setReconciliationPendingCondition(cluster *fdbtypes.FoundationDBCluster) {
+	if cluster.Spec.ObserveOnly {
+		return
+	}
+
+	reconciledCondition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+	if reconciledCondition != nil && (reconciledCondition.Status == metav1.ConditionFalse || reconciledCondition.ObservedGeneration == cluster.ObjectMeta.Generation) {
+		return
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               fdbtypes.ClusterConditionFullyReconciled,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: cluster.ObjectMeta.Generation,
+		Reason:             "ReconciliationPending",
+		Message:            fmt.Sprintf("Generation %d is not reconciled", cluster.ObjectMeta.Generation),
+	})
+}
+
+// recordReconciliationStalled records an event when the
+// ReconciliationStalled condition has become true.
+func recordReconciliationStalled(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, wasStalled bool) {
+	stalledCondition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+	if wasStalled || stalledCondition == nil || stalledCondition.Status != metav1.ConditionTrue {
+		return
+	}
+
+	logger := getLogger(context, cluster, "reconciliationStalled")
+	logger.Info("Reconciliation is stalled", "generation", cluster.ObjectMeta.Generation, "reconciled", cluster.Status.Generations.Reconciled)
+	r.Recorder.Event(cluster, corev1.EventTypeWarning, "ReconciliationStalled", stalledCondition.Message)
+}
+
+// hasMissingResources checks whether any process group that is not marked
+// for removal is missing its pod or PVC.
+func hasMissingResources(cluster *fdbtypes.FoundationDBCluster) bool {
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.Remove {
+			continue
+		}
+
+		if processGroup.GetConditionTime(fdbtypes.MissingPod) != nil || processGroup.GetConditionTime(fdbtypes.MissingPVC) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getPendingReconciliationStates returns the names of the generation fields
+// that block the reconciliation of the current generation.
+func getPendingReconciliationStates(cluster *fdbtypes.FoundationDBCluster) []string {
+	var states []string
+
+	generations := reflect.ValueOf(cluster.Status.Generations)
+	generationsType := generations.Type()
+	for i := 0; i < generations.NumField(); i++ {
+		name := strings.Split(generationsType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "reconciled" || generations.Field(i).Int() != cluster.ObjectMeta.Generation {
+			continue
+		}
+
+		states = append(states, name)
+	}
+
+	return states
+}
+
 // containsAll determines if one map contains all the keys and matching values
 // from another map.
 func containsAll(current map[string]string, desired map[string]string) bool {
@@ -336,11 +686,24 @@ func optionList(options ...string) []string {
 	return values
 }
 
+// isOutdated determines whether a process group is running with an outdated
+// configuration, pod spec or command line.
+func isOutdated(processGroup *fdbtypes.ProcessGroupStatus) bool {
+	for _, conditionType := range []fdbtypes.ProcessGroupConditionType{fdbtypes.IncorrectConfigMap, fdbtypes.IncorrectPodSpec, fdbtypes.IncorrectCommandLine} {
+		if processGroup.GetConditionTime(conditionType) != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
 // tryConnectionOptions attempts to connect with all the combinations of
 // versions and connection strings for this cluster and returns the set that
-// allow connecting to the cluster.
-func tryConnectionOptions(cluster *fdbtypes.FoundationDBCluster, r *FoundationDBClusterReconciler) (string, string, error) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updateStatus")
+// allow connecting to the cluster. The versions of the client libraries are
+// tried after the running version and the version from the spec.
+func tryConnectionOptions(context ctx.Context, cluster *fdbtypes.FoundationDBCluster, r *FoundationDBClusterReconciler) (string, string, error) {
+	logger := getLogger(context, cluster, "updateStatus")
 	versions := optionList(cluster.Status.RunningVersion, cluster.Spec.Version)
 	connectionStrings := optionList(cluster.Status.ConnectionString, cluster.Spec.SeedConnectionString)
 
@@ -351,6 +714,11 @@ func tryConnectionOptions(cluster *fdbtypes.FoundationDBCluster, r *FoundationDB
 		return originalVersion, originalConnectionString, nil
 	}
 
+	// If none of the known versions work, fall back to the versions of the
+	// available client libraries, e.g. when the cluster was upgraded outside
+	// of the operator.
+	versions = optionList(append(versions, r.ClientLibraryVersions...)...)
+
 	logger.Info("Trying connection options",
 		"version", versions, "connectionString", connectionStrings)
 
@@ -370,7 +738,7 @@ func tryConnectionOptions(cluster *fdbtypes.FoundationDBCluster, r *FoundationDB
 			}
 			defer adminClient.Close()
 
-			activeConnectionString, err := adminClient.GetConnectionString()
+			activeConnectionString, err := adminClient.GetConnectionString(context)
 			if err == nil {
 				logger.Info("Chose connection option",
 					"version", version, "connectionString", activeConnectionString)
@@ -384,8 +752,8 @@ func tryConnectionOptions(cluster *fdbtypes.FoundationDBCluster, r *FoundationDB
 }
 
 // checkAndSetProcessStatus checks the status of the Process and if missing or incorrect add it to the related status field
-func checkAndSetProcessStatus(r *FoundationDBClusterReconciler, cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod, processMap map[string][]fdbtypes.FoundationDBStatusProcessInfo, processNumber int, processCount int, processGroupStatus *fdbtypes.ProcessGroupStatus) error {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updateStatus")
+func checkAndSetProcessStatus(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod, processMap map[string][]fdbtypes.FoundationDBStatusProcessInfo, processNumber int, processCount int, processGroupStatus *fdbtypes.ProcessGroupStatus) error {
+	logger := getLogger(context, cluster, "updateStatus")
 	processID := processGroupStatus.ProcessGroupID
 
 	if processCount > 1 {
@@ -399,12 +767,31 @@ func checkAndSetProcessStatus(r *FoundationDBClusterReconciler, cluster *fdbtype
 		return nil
 	}
 
+	// The zone is recorded so that it is still known once the processes
+	// stop reporting, e.g. when the pod is removed.
+	zone := processStatus[0].Locality[fdbtypes.FDBLocalityZoneIDKey]
+	if zone != "" {
+		processGroupStatus.FaultDomain = zone
+	}
+
 	podClient, message := r.getPodClient(cluster, pod)
 	if podClient == nil {
 		logger.Info("Unable to build pod client", "processGroupID", processGroupStatus.ProcessGroupID, "message", message)
 		return nil
 	}
 
+	// The restart options are not part of the command line, so the pod
+	// records the options that fdbmonitor used when the processes were last
+	// restarted.
+	restartOptionsHash, err := internal.GetProcessRestartOptionsHash(cluster)
+	if err != nil {
+		return err
+	}
+	restartOptionsCorrect := pod.ObjectMeta.Annotations[fdbtypes.LastRestartOptionsKey] == restartOptionsHash
+	if !restartOptionsCorrect {
+		logger.Info("IncorrectProcess", "expectedRestartOptions", restartOptionsHash, "restartOptions", pod.ObjectMeta.Annotations[fdbtypes.LastRestartOptionsKey], "processGroupID", processGroupStatus.ProcessGroupID)
+	}
+
 	correct := false
 	for _, process := range processStatus {
 		commandLine, err := internal.GetStartCommand(cluster, processGroupStatus.ProcessClass, podClient, processNumber, processCount)
@@ -425,10 +812,10 @@ func checkAndSetProcessStatus(r *FoundationDBClusterReconciler, cluster *fdbtype
 		}
 
 		// If the `EmptyMonitorConf` is set, the commandline is by definition wrong since there should be no running processes.
-		correct = commandLine == process.CommandLine && versionMatch && !cluster.Spec.Buggify.EmptyMonitorConf
+		correct = internal.CommandLineMatches(commandLine, process.CommandLine) && versionMatch && restartOptionsCorrect && !cluster.Spec.Buggify.EmptyMonitorConf
 
 		if !correct {
-			log.Info("IncorrectProcess", "expected", commandLine, "got", process.CommandLine, "expectedVersion", cluster.Spec.Version, "version", process.Version, "processGroupID", processGroupStatus.ProcessGroupID)
+			logger.Info("IncorrectProcess", "expected", commandLine, "got", process.CommandLine, "expectedVersion", cluster.Spec.Version, "version", process.Version, "processGroupID", processGroupStatus.ProcessGroupID)
 		}
 	}
 
@@ -452,6 +839,8 @@ func validateProcessGroups(r *FoundationDBClusterReconciler, context ctx.Context
 		processGroup.UpdateCondition(fdbtypes.IncorrectCommandLine, false, nil, "")
 	}
 
+	nodes := make(map[string]*corev1.Node)
+
 	for _, processGroup := range processGroups {
 		pods, err := r.PodLifecycleManager.GetPods(r, cluster, context, internal.GetPodListOptions(cluster, processGroup.ProcessClass, processGroup.ProcessGroupID)...)
 		if err != nil {
@@ -475,23 +864,20 @@ func validateProcessGroups(r *FoundationDBClusterReconciler, context ctx.Context
 		pod := pods[0]
 
 		processGroup.AddAddresses(podmanager.GetPublicIPs(pod), processGroup.Remove || !status.Health.Available)
-		processCount := 1
 
 		if processGroup.Remove && pod.ObjectMeta.DeletionTimestamp != nil {
 			processGroup.UpdateCondition(fdbtypes.ResourcesTerminating, true, processGroups, processGroup.ProcessGroupID)
 		}
 
 		// Even the instance will be removed we need to keep the config around.
-		// Set the processCount for the instance specific storage servers per pod
-		if processGroup.ProcessClass == fdbtypes.ProcessClassStorage {
-			processCount, err = internal.GetStorageServersPerPodForPod(pod)
-			if err != nil {
-				return processGroups, err
-			}
-
-			status.AddStorageServerPerDisk(processCount)
+		// Set the processCount for the instance specific storage or log servers per pod
+		processCount, err := internal.GetServersPerPodForPod(pod, processGroup.ProcessClass)
+		if err != nil {
+			return processGroups, err
 		}
 
+		status.AddServersPerDisk(processCount, processGroup.ProcessClass)
+
 		if isBeingRemoved {
 			processGroup.Remove = true
 			// Check if we should skip exclusion for the process group
@@ -500,10 +886,17 @@ func validateProcessGroups(r *FoundationDBClusterReconciler, context ctx.Context
 			continue
 		}
 
+		err = updateNodeTaintConditions(r, context, cluster, pod, processGroup, nodes)
+		if err != nil {
+			return processGroups, err
+		}
+
 		if pod.ObjectMeta.DeletionTimestamp == nil && status.HasListenIPsForAllPods {
 			hasPodIP := false
 			for _, container := range pod.Spec.Containers {
-				if container.Name == "foundationdb-kubernetes-sidecar" {
+				// Pods using the unified image provide the environment for
+				// the processes through the main container.
+				if container.Name == "foundationdb-kubernetes-sidecar" || (container.Name == "foundationdb" && cluster.UseUnifiedImage()) {
 					for _, env := range container.Env {
 						if env.Name == "FDB_POD_IP" {
 							hasPodIP = true
@@ -518,12 +911,14 @@ func validateProcessGroups(r *FoundationDBClusterReconciler, context ctx.Context
 
 		// In theory we could also support multiple processes per pod for different classes
 		for i := 1; i <= processCount; i++ {
-			err := checkAndSetProcessStatus(r, cluster, pod, processMap, i, processCount, processGroup)
+			err := checkAndSetProcessStatus(r, context, cluster, pod, processMap, i, processCount, processGroup)
 			if err != nil {
 				return processGroups, err
 			}
 		}
 
+		updateProcessHealthConditions(cluster, processMap, processCount, processGroup)
+
 		configMapHash, err := internal.GetDynamicConfHash(configMap, processGroup.ProcessClass, processCount)
 		if err != nil {
 			return processGroups, err
@@ -546,7 +941,7 @@ func validateProcessGroups(r *FoundationDBClusterReconciler, context ctx.Context
 // validateProcessGroup runs specific checks for the status of an instance.
 // returns failing, incorrect, error
 func validateProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod, configMapHash string, processGroupStatus *fdbtypes.ProcessGroupStatus) (bool, error) {
-	logger := log.WithValues("namespace", cluster.Namespace, "cluster", cluster.Name, "reconciler", "updateStatus")
+	logger := getLogger(context, cluster, "updateStatus")
 	processGroupStatus.UpdateCondition(fdbtypes.MissingPod, pod == nil, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
 	if pod == nil {
 		return false, nil
@@ -606,6 +1001,10 @@ func validateProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context,
 		}
 	}
 
+	processGroupStatus.UpdateCondition(fdbtypes.PodTerminating, pod.ObjectMeta.DeletionTimestamp != nil, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+	processGroupStatus.UpdateCondition(fdbtypes.PodUnschedulable, podIsUnschedulable(pod), cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+	processGroupStatus.UpdateCondition(fdbtypes.PodCrashLooping, podIsCrashLooping(pod), cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+
 	if pod.Status.Phase == corev1.PodPending {
 		processGroupStatus.UpdateCondition(fdbtypes.PodPending, true, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
 		return needsSidecarConfInConfigMap, nil
@@ -626,8 +1025,9 @@ func validateProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context,
 		failing = true
 
 		// Only recreate the Pod if it is already 5 minutes up (just to prevent to recreate the Pod multiple times
-		// and give the cluster some time to get the kubelet up
-		if pod.Status.Reason == "NodeAffinity" && pod.CreationTimestamp.Add(5*time.Minute).Before(time.Now()) {
+		// and give the cluster some time to get the kubelet up. A skipped cluster is under manual control, so
+		// we leave the Pod in place.
+		if !cluster.Spec.Skip && pod.Status.Reason == "NodeAffinity" && pod.CreationTimestamp.Add(5*time.Minute).Before(time.Now()) {
 			logger.Info("Delete Pod that is stuck in NodeAffinity",
 				"processGroupID", processGroupStatus.ProcessGroupID)
 
@@ -644,6 +1044,145 @@ func validateProcessGroup(r *FoundationDBClusterReconciler, context ctx.Context,
 	return needsSidecarConfInConfigMap, nil
 }
 
+// updateNodeTaintConditions sets the conditions for taints on the node of the
+// pod that match the taint replacement options. The nodes map caches the
+// nodes that have already been fetched.
+func updateNodeTaintConditions(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod, processGroupStatus *fdbtypes.ProcessGroupStatus, nodes map[string]*corev1.Node) error {
+	options := cluster.Spec.AutomationOptions.Replacements.TaintReplacementOptions
+
+	var node *corev1.Node
+	if len(options) > 0 && pod.Spec.NodeName != "" {
+		var ok bool
+		node, ok = nodes[pod.Spec.NodeName]
+		if !ok {
+			node = &corev1.Node{}
+			err := r.Get(context, client.ObjectKey{Name: pod.Spec.NodeName}, node)
+			if err != nil {
+				if k8serrors.IsForbidden(err) {
+					getLogger(context, cluster, "updateStatus").Info("Could not fetch node to check for taints", "node", pod.Spec.NodeName)
+				} else if !k8serrors.IsNotFound(err) {
+					return err
+				}
+				node = nil
+			}
+			nodes[pod.Spec.NodeName] = node
+		}
+	}
+
+	taintDetected := false
+	if node != nil {
+		for _, taint := range node.Spec.Taints {
+			for _, option := range options {
+				if option.Matches(taint.Key) {
+					taintDetected = true
+				}
+			}
+		}
+	}
+
+	processGroupStatus.UpdateCondition(fdbtypes.NodeTaintDetected, taintDetected, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+
+	taintReplacing := false
+	detectionTime := processGroupStatus.GetConditionTime(fdbtypes.NodeTaintDetected)
+	if taintDetected && detectionTime != nil {
+		now := time.Now().Unix()
+		for _, taint := range node.Spec.Taints {
+			// Taints only have a timestamp when they have the NoExecute effect,
+			// for all other taints we use the time when the operator detected
+			// the taint.
+			taintTime := *detectionTime
+			if taint.TimeAdded != nil {
+				taintTime = taint.TimeAdded.Unix()
+			}
+
+			for _, option := range options {
+				if option.Matches(taint.Key) && now-taintTime >= int64(option.DurationInSeconds) {
+					taintReplacing = true
+				}
+			}
+		}
+	}
+
+	processGroupStatus.UpdateCondition(fdbtypes.NodeTaintReplacing, taintReplacing, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+
+	return nil
+}
+
+// ioErrorMessages contains the names of the process messages that indicate
+// that a process has problems with its disk.
+var ioErrorMessages = map[string]bool{
+	"io_error":        true,
+	"io_timeout":      true,
+	"file_open_error": true,
+}
+
+// updateProcessHealthConditions sets the conditions for processes that are
+// running but report IO errors, a high durability lag or low disk space in
+// the database status.
+func updateProcessHealthConditions(cluster *fdbtypes.FoundationDBCluster, processMap map[string][]fdbtypes.FoundationDBStatusProcessInfo, processCount int, processGroupStatus *fdbtypes.ProcessGroupStatus) {
+	hasIOError := false
+	isLagging := false
+	hasLowDiskSpace := false
+
+	if cluster.GetProcessHealthChecksEnabled() {
+		maxLag := cluster.GetMaxDurabilityLag().Seconds()
+		minimumFreeDisk := cluster.GetMinimumFreeDiskPercentage()
+
+		for processNumber := 1; processNumber <= processCount; processNumber++ {
+			processID := processGroupStatus.ProcessGroupID
+			if processCount > 1 {
+				processID = fmt.Sprintf("%s-%d", processID, processNumber)
+			}
+
+			for _, process := range processMap[processID] {
+				for _, message := range process.Messages {
+					if ioErrorMessages[message.Name] {
+						hasIOError = true
+					}
+				}
+
+				for _, role := range process.Roles {
+					if role.Role == string(fdbtypes.ProcessRoleStorage) && role.DurabilityLag.Seconds > maxLag {
+						isLagging = true
+					}
+				}
+
+				if process.Disk.TotalBytes > 0 && process.Disk.FreeBytes*100 < process.Disk.TotalBytes*int64(minimumFreeDisk) {
+					hasLowDiskSpace = true
+				}
+			}
+		}
+	}
+
+	processGroupStatus.UpdateCondition(fdbtypes.ProcessHasIOError, hasIOError, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+	processGroupStatus.UpdateCondition(fdbtypes.ProcessIsLagging, isLagging, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+	processGroupStatus.UpdateCondition(fdbtypes.ProcessHasLowDiskSpace, hasLowDiskSpace, cluster.Status.ProcessGroups, processGroupStatus.ProcessGroupID)
+}
+
+// podIsUnschedulable checks if the scheduler was not able to find a node
+// for the pod.
+func podIsUnschedulable(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled {
+			return condition.Status == corev1.ConditionFalse && condition.Reason == corev1.PodReasonUnschedulable
+		}
+	}
+
+	return false
+}
+
+// podIsCrashLooping checks if any container of the pod is in
+// CrashLoopBackOff.
+func podIsCrashLooping(pod *corev1.Pod) bool {
+	for _, container := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+		if container.State.Waiting != nil && container.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func removeDuplicateConditions(status fdbtypes.FoundationDBClusterStatus) {
 	for _, processGroupStatus := range status.ProcessGroups {
 		conditionTimes := make(map[fdbtypes.ProcessGroupConditionType]int64, len(processGroupStatus.ProcessGroupConditions))
@@ -0,0 +1,93 @@
+/*
+ * update_status_summary.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// updateStatusSummary provides a reconciliation step for publishing a summary
+// of the database status into a ConfigMap.
+type updateStatusSummary struct{}
+
+// reconcile runs the reconciler's work.
+func (u updateStatusSummary) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	interval := cluster.GetStatusSummaryInterval()
+	if interval == 0 || !cluster.Status.Configured {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "updateStatusSummary")
+
+	existing := &corev1.ConfigMap{}
+	err := r.Get(context, types.NamespacedName{Namespace: cluster.Namespace, Name: internal.GetStatusSummaryConfigMapName(cluster)}, existing)
+	needCreation := false
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return &requeue{curError: err}
+		}
+		needCreation = true
+	}
+
+	if !needCreation {
+		summary, err := internal.ParseStatusSummary(existing)
+		if err == nil && time.Since(summary.Timestamp.Time) < interval {
+			return nil
+		}
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	status, err := adminClient.GetStatus(context)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	configMap, err := internal.GetStatusSummaryConfigMap(cluster, status, time.Now())
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if needCreation {
+		logger.Info("Creating status summary config map")
+	} else {
+		logger.V(1).Info("Updating status summary config map")
+	}
+
+	err = internal.ApplyObject(r, context, configMap)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+ * update_status_summary_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+var _ = Describe("updateStatusSummary", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var requeue *requeue
+	var err error
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = setupClusterForTest(cluster)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateStatusSummary{}.reconcile(clusterReconciler, context.TODO(), cluster)
+	})
+
+	getSummaryConfigMap := func() (*corev1.ConfigMap, error) {
+		configMap := &corev1.ConfigMap{}
+		err := k8sClient.Get(context.TODO(), types.NamespacedName{Namespace: cluster.Namespace, Name: internal.GetStatusSummaryConfigMapName(cluster)}, configMap)
+		return configMap, err
+	}
+
+	Context("without a status summary interval", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should not create a config map", func() {
+			_, err := getSummaryConfigMap()
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Context("with a status summary interval", func() {
+		BeforeEach(func() {
+			interval := 60
+			cluster.Spec.StatusSummaryIntervalSeconds = &interval
+		})
+
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should create the config map", func() {
+			configMap, err := getSummaryConfigMap()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configMap.OwnerReferences).To(HaveLen(1))
+
+			summary, err := internal.ParseStatusSummary(configMap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary.Available).To(BeTrue())
+			Expect(summary.Processes).To(HaveLen(len(cluster.Status.ProcessGroups)))
+		})
+
+		When("a recent summary exists", func() {
+			var timestamp metav1.Time
+
+			BeforeEach(func() {
+				configMap, err := internal.GetStatusSummaryConfigMap(cluster, &fdbtypes.FoundationDBStatus{}, time.Now().Add(-10*time.Second))
+				Expect(err).NotTo(HaveOccurred())
+				err = k8sClient.Create(context.TODO(), configMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				summary, err := internal.ParseStatusSummary(configMap)
+				Expect(err).NotTo(HaveOccurred())
+				timestamp = summary.Timestamp
+			})
+
+			It("should not update the summary", func() {
+				configMap, err := getSummaryConfigMap()
+				Expect(err).NotTo(HaveOccurred())
+				summary, err := internal.ParseStatusSummary(configMap)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.Timestamp).To(Equal(timestamp))
+				Expect(summary.Available).To(BeFalse())
+			})
+		})
+
+		When("an outdated summary exists", func() {
+			BeforeEach(func() {
+				configMap, err := internal.GetStatusSummaryConfigMap(cluster, &fdbtypes.FoundationDBStatus{}, time.Now().Add(-10*time.Minute))
+				Expect(err).NotTo(HaveOccurred())
+				err = k8sClient.Create(context.TODO(), configMap)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should update the summary", func() {
+				configMap, err := getSummaryConfigMap()
+				Expect(err).NotTo(HaveOccurred())
+				summary, err := internal.ParseStatusSummary(configMap)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(summary.Available).To(BeTrue())
+				Expect(time.Since(summary.Timestamp.Time)).To(BeNumerically("<", time.Minute))
+			})
+		})
+	})
+})
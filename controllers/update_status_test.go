@@ -22,6 +22,8 @@ package controllers
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/podmanager"
 
@@ -29,9 +31,15 @@ import (
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var _ = Describe("update_status", func() {
@@ -64,7 +72,7 @@ var _ = Describe("update_status", func() {
 		})
 
 		JustBeforeEach(func() {
-			databaseStatus, err := adminClient.GetStatus()
+			databaseStatus, err := adminClient.GetStatus(context.TODO())
 			Expect(err).NotTo(HaveOccurred())
 			processMap = make(map[string][]fdbtypes.FoundationDBStatusProcessInfo)
 			for _, process := range databaseStatus.Cluster.Processes {
@@ -362,8 +370,302 @@ var _ = Describe("update_status", func() {
 				Expect(pendingCount).To(BeNumerically("==", 1))
 			})
 		})
+
+		When("a Pod is unschedulable", func() {
+			var unschedulableProcessGroup string
+
+			BeforeEach(func() {
+				unschedulableProcessGroup = podmanager.GetProcessGroupID(cluster, pods[0])
+				pods[0].Status.Phase = corev1.PodPending
+				pods[0].Status.Conditions = []corev1.PodCondition{
+					{
+						Type:   corev1.PodScheduled,
+						Status: corev1.ConditionFalse,
+						Reason: corev1.PodReasonUnschedulable,
+					},
+				}
+				err = k8sClient.Update(context.TODO(), pods[0])
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should mark the process group as Pod unschedulable", func() {
+				processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				unschedulableCount := 0
+				for _, processGroup := range processGroupStatus {
+					if processGroup.GetConditionTime(fdbtypes.PodUnschedulable) != nil {
+						Expect(processGroup.ProcessGroupID).To(Equal(unschedulableProcessGroup))
+						unschedulableCount++
+					}
+				}
+
+				Expect(unschedulableCount).To(BeNumerically("==", 1))
+			})
+		})
+
+		When("a Pod is crash looping", func() {
+			var crashLoopingProcessGroup string
+
+			BeforeEach(func() {
+				crashLoopingProcessGroup = podmanager.GetProcessGroupID(cluster, pods[0])
+				pods[0].Status.ContainerStatuses = []corev1.ContainerStatus{
+					{
+						Name: "foundationdb",
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+						},
+					},
+				}
+				err = k8sClient.Update(context.TODO(), pods[0])
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should mark the process group as Pod crash looping", func() {
+				processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				crashLoopingCount := 0
+				for _, processGroup := range processGroupStatus {
+					if processGroup.GetConditionTime(fdbtypes.PodCrashLooping) != nil {
+						Expect(processGroup.ProcessGroupID).To(Equal(crashLoopingProcessGroup))
+						Expect(processGroup.GetConditionTime(fdbtypes.PodFailing)).NotTo(BeNil())
+						crashLoopingCount++
+					}
+				}
+
+				Expect(crashLoopingCount).To(BeNumerically("==", 1))
+			})
+		})
+
+		When("the node of a Pod is tainted", func() {
+			var taintedProcessGroup string
+			var taintAge time.Duration
+
+			BeforeEach(func() {
+				taintAge = 0
+				cluster.Spec.AutomationOptions.Replacements.TaintReplacementOptions = []fdbtypes.TaintReplacementOption{
+					{Key: "example.com/hardware-failure", DurationInSeconds: 600},
+				}
+			})
+
+			JustBeforeEach(func() {
+				taintedProcessGroup = podmanager.GetProcessGroupID(cluster, pods[0])
+				node := &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: "tainted-node"},
+					Spec: corev1.NodeSpec{
+						Taints: []corev1.Taint{
+							{
+								Key:       "example.com/hardware-failure",
+								Effect:    corev1.TaintEffectNoExecute,
+								TimeAdded: &metav1.Time{Time: time.Now().Add(-taintAge)},
+							},
+						},
+					},
+				}
+				err = k8sClient.Create(context.TODO(), node)
+				Expect(err).NotTo(HaveOccurred())
+
+				pods[0].Spec.NodeName = node.Name
+				err = k8sClient.Update(context.TODO(), pods[0])
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should mark the process group with a detected taint", func() {
+				processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, processGroup := range processGroupStatus {
+					if processGroup.ProcessGroupID == taintedProcessGroup {
+						Expect(processGroup.GetConditionTime(fdbtypes.NodeTaintDetected)).NotTo(BeNil())
+					} else {
+						Expect(processGroup.GetConditionTime(fdbtypes.NodeTaintDetected)).To(BeNil())
+					}
+					Expect(processGroup.GetConditionTime(fdbtypes.NodeTaintReplacing)).To(BeNil())
+				}
+			})
+
+			When("the taint has been present for longer than the duration", func() {
+				BeforeEach(func() {
+					taintAge = time.Hour
+				})
+
+				It("should mark the process group for replacement", func() {
+					processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+					Expect(err).NotTo(HaveOccurred())
+
+					replacingCount := 0
+					for _, processGroup := range processGroupStatus {
+						if processGroup.GetConditionTime(fdbtypes.NodeTaintReplacing) != nil {
+							Expect(processGroup.ProcessGroupID).To(Equal(taintedProcessGroup))
+							replacingCount++
+						}
+					}
+
+					Expect(replacingCount).To(BeNumerically("==", 1))
+				})
+			})
+
+			When("the taint does not match the taint replacement options", func() {
+				BeforeEach(func() {
+					cluster.Spec.AutomationOptions.Replacements.TaintReplacementOptions = []fdbtypes.TaintReplacementOption{
+						{Key: "example.com/other-taint"},
+					}
+				})
+
+				It("should not mark the process group", func() {
+					processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+					Expect(err).NotTo(HaveOccurred())
+
+					for _, processGroup := range processGroupStatus {
+						Expect(processGroup.GetConditionTime(fdbtypes.NodeTaintDetected)).To(BeNil())
+					}
+				})
+			})
+		})
+
+		When("a process reports problems in the status", func() {
+			BeforeEach(func() {
+				adminClient.MockProcessMessages("storage-1", "io_error")
+				adminClient.MockDurabilityLag("storage-2", 600)
+				adminClient.MockDiskInfo("storage-3", 1, 100)
+			})
+
+			It("should not mark the process groups", func() {
+				processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, processGroup := range processGroupStatus {
+					Expect(processGroup.GetConditionTime(fdbtypes.ProcessHasIOError)).To(BeNil())
+					Expect(processGroup.GetConditionTime(fdbtypes.ProcessIsLagging)).To(BeNil())
+					Expect(processGroup.GetConditionTime(fdbtypes.ProcessHasLowDiskSpace)).To(BeNil())
+				}
+			})
+
+			When("the process health checks are enabled", func() {
+				BeforeEach(func() {
+					cluster.Spec.AutomationOptions.ProcessHealthChecks.Enabled = pointer.Bool(true)
+				})
+
+				It("should mark the process groups with the matching conditions", func() {
+					processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fdbtypes.FilterByCondition(processGroupStatus, fdbtypes.ProcessHasIOError, false)).To(Equal([]string{"storage-1"}))
+					Expect(fdbtypes.FilterByCondition(processGroupStatus, fdbtypes.ProcessIsLagging, false)).To(Equal([]string{"storage-2"}))
+					Expect(fdbtypes.FilterByCondition(processGroupStatus, fdbtypes.ProcessHasLowDiskSpace, false)).To(Equal([]string{"storage-3"}))
+				})
+
+				When("the thresholds are above the reported values", func() {
+					BeforeEach(func() {
+						cluster.Spec.AutomationOptions.ProcessHealthChecks.MaxDurabilityLagSeconds = pointer.Int(900)
+						cluster.Spec.AutomationOptions.ProcessHealthChecks.MinimumFreeDiskPercentage = pointer.Int(0)
+					})
+
+					It("should only mark the process group with the IO error", func() {
+						processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+						Expect(err).NotTo(HaveOccurred())
+
+						Expect(fdbtypes.FilterByCondition(processGroupStatus, fdbtypes.ProcessHasIOError, false)).To(Equal([]string{"storage-1"}))
+						Expect(fdbtypes.FilterByCondition(processGroupStatus, fdbtypes.ProcessIsLagging, false)).To(BeEmpty())
+						Expect(fdbtypes.FilterByCondition(processGroupStatus, fdbtypes.ProcessHasLowDiskSpace, false)).To(BeEmpty())
+					})
+				})
+			})
+		})
+
+		When("a Pod is stuck in terminating", func() {
+			var terminatingProcessGroup string
+
+			BeforeEach(func() {
+				terminatingProcessGroup = podmanager.GetProcessGroupID(cluster, pods[0])
+				err = k8sClient.MockStuckTermination(pods[0], true)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should mark the process group as Pod terminating", func() {
+				processGroupStatus, err := validateProcessGroups(clusterReconciler, context.TODO(), cluster, &cluster.Status, processMap, configMap)
+				Expect(err).NotTo(HaveOccurred())
+
+				terminatingCount := 0
+				for _, processGroup := range processGroupStatus {
+					if processGroup.GetConditionTime(fdbtypes.PodTerminating) != nil {
+						Expect(processGroup.ProcessGroupID).To(Equal(terminatingProcessGroup))
+						terminatingCount++
+					}
+				}
+
+				Expect(terminatingCount).To(BeNumerically("==", 1))
+			})
+		})
+	})
+
+	Describe("tryConnectionOptions", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var reconciler FoundationDBClusterReconciler
+		var version string
+		var connectionString string
+
+		BeforeEach(func() {
+			cluster = internal.CreateDefaultCluster()
+			err := k8sClient.Create(context.TODO(), cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := reconcileCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Requeue).To(BeFalse())
+
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Fail the connection with the running version and the version
+			// from the spec.
+			cluster.Spec.Version = fdbtypes.Versions.NextPatchVersion.String()
+			adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+			Expect(err).NotTo(HaveOccurred())
+			adminClient.MockCommandError("GetConnectionString", 2)
+
+			reconciler = *clusterReconciler
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			version, connectionString, err = tryConnectionOptions(context.TODO(), cluster, &reconciler)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should keep the running version", func() {
+			Expect(version).To(Equal(fdbtypes.Versions.Default.String()))
+			Expect(connectionString).To(Equal(cluster.Status.ConnectionString))
+		})
+
+		When("client libraries for other versions are available", func() {
+			BeforeEach(func() {
+				reconciler.ClientLibraryVersions = []string{"6.3.10", fdbtypes.Versions.Default.String()}
+			})
+
+			It("should choose the version of the client library", func() {
+				Expect(version).To(Equal("6.3.10"))
+				Expect(connectionString).To(Equal(cluster.Status.ConnectionString))
+			})
+		})
 	})
 
+	DescribeTable("checking if a process group is outdated",
+		func(conditions []fdbtypes.ProcessGroupConditionType, expected bool) {
+			processGroup := fdbtypes.NewProcessGroupStatus("storage-1", fdbtypes.ProcessClassStorage, nil)
+			for _, condition := range conditions {
+				processGroup.UpdateCondition(condition, true, nil, "")
+			}
+			Expect(isOutdated(processGroup)).To(Equal(expected))
+		},
+		Entry("without conditions", nil, false),
+		Entry("with an incorrect config map", []fdbtypes.ProcessGroupConditionType{fdbtypes.IncorrectConfigMap}, true),
+		Entry("with an incorrect pod spec", []fdbtypes.ProcessGroupConditionType{fdbtypes.IncorrectPodSpec}, true),
+		Entry("with an incorrect command line", []fdbtypes.ProcessGroupConditionType{fdbtypes.IncorrectCommandLine}, true),
+		Entry("with a missing process", []fdbtypes.ProcessGroupConditionType{fdbtypes.MissingProcesses}, false),
+	)
+
 	Describe("Reconcile", func() {
 		var cluster *fdbtypes.FoundationDBCluster
 		var err error
@@ -398,6 +700,36 @@ var _ = Describe("update_status", func() {
 			Expect(cluster.Status.Generations.Reconciled).To(Equal(cluster.ObjectMeta.Generation))
 		})
 
+		It("should record the ports of the processes", func() {
+			Expect(cluster.Status.ProcessPorts).To(Equal(&fdbtypes.ProcessPortsStatus{
+				BasePort:   fdbtypes.DefaultBasePort,
+				PortStride: fdbtypes.DefaultPortStride,
+			}))
+		})
+
+		It("should translate the database status into metrics", func() {
+			entry, ok := databaseMetrics.get(client.ObjectKeyFromObject(cluster))
+			Expect(ok).To(BeTrue())
+			Expect(entry.roleCounts).To(HaveKeyWithValue("coordinator", 3))
+			Expect(entry.summary.Processes).To(HaveLen(len(cluster.Status.ProcessGroups)))
+
+			metricChannel := make(chan prometheus.Metric, 1000)
+			collectMetrics(metricChannel, cluster)
+			close(metricChannel)
+
+			metricNames := map[string]int{}
+			for metric := range metricChannel {
+				metricNames[metric.Desc().String()]++
+			}
+
+			Expect(metricNames).To(HaveKeyWithValue(descDatabaseStatusTimestamp.String(), 1))
+			Expect(metricNames).To(HaveKeyWithValue(descDatabaseLag.String(), 2))
+			Expect(metricNames).To(HaveKeyWithValue(descDatabaseTransactions.String(), 3))
+			Expect(metricNames).To(HaveKeyWithValue(descDatabaseOperations.String(), 2))
+			Expect(metricNames).To(HaveKeyWithValue(descProcessGroupDisk.String(), 2*len(cluster.Status.ProcessGroups)))
+			Expect(metricNames).To(HaveKey(descDatabaseRoles.String()))
+		})
+
 		When("enabling an explicit listen address", func() {
 			BeforeEach(func() {
 				enabled := false
@@ -416,5 +748,327 @@ var _ = Describe("update_status", func() {
 				})
 			})
 		})
+
+		When("the coordinators were changed outside of the operator", func() {
+			var liveConnectionString string
+
+			BeforeEach(func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+
+				connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+				err = connectionString.GenerateNewGenerationID()
+				Expect(err).NotTo(HaveOccurred())
+				liveConnectionString = connectionString.String()
+				adminClient.MockLiveConnectionString(liveConnectionString)
+			})
+
+			It("should update the connection string from the database", func() {
+				Expect(cluster.Status.ConnectionString).To(Equal(liveConnectionString))
+			})
+		})
+
+		It("should not report the storage wiggle", func() {
+			Expect(cluster.Status.StorageWiggle).To(BeNil())
+		})
+
+		When("the perpetual storage wiggle is enabled", func() {
+			BeforeEach(func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+
+				configuration := cluster.DesiredDatabaseConfiguration()
+				configuration.PerpetualStorageWiggle = pointer.Int(1)
+				err = adminClient.ConfigureDatabase(context.TODO(), configuration, false)
+				Expect(err).NotTo(HaveOccurred())
+
+				adminClient.MockStorageWiggler(fdbtypes.FoundationDBStatusStorageWiggler{
+					WiggleServerAddresses: []string{"1.1.1.1:4501"},
+					Primary: fdbtypes.FoundationDBStatusStorageWiggleMetrics{
+						FinishedRound:            2,
+						FinishedWiggle:           9,
+						LastRoundFinishTimestamp: 1634000000.5,
+					},
+				})
+			})
+
+			It("should report the wiggle progress", func() {
+				Expect(cluster.Status.StorageWiggle).To(Equal(&fdbtypes.StorageWiggleStatus{
+					FinishedRounds:           2,
+					FinishedWiggles:          9,
+					LastRoundFinishTimestamp: 1634000000,
+					WigglingAddresses:        []string{"1.1.1.1:4501"},
+				}))
+			})
+		})
+
+		It("should set the conditions", func() {
+			Expect(getConditionStatuses(cluster)).To(Equal(map[string]metav1.ConditionStatus{
+				fdbtypes.ClusterConditionAvailable:            metav1.ConditionTrue,
+				fdbtypes.ClusterConditionFullyReconciled:      metav1.ConditionTrue,
+				fdbtypes.ClusterConditionReplacingInstances:   metav1.ConditionFalse,
+				fdbtypes.ClusterConditionUpgradeInProgress:    metav1.ConditionFalse,
+				fdbtypes.ClusterConditionConfigurationPending: metav1.ConditionFalse,
+				fdbtypes.ClusterConditionFaultTolerance:       metav1.ConditionTrue,
+			}))
+
+			condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Reason).To(Equal("Reconciled"))
+			Expect(condition.ObservedGeneration).To(Equal(cluster.ObjectMeta.Generation))
+		})
+
+		When("the status is updated again", func() {
+			var originalConditions []metav1.Condition
+
+			BeforeEach(func() {
+				originalConditions = cluster.Status.DeepCopy().Conditions
+				Expect(originalConditions).NotTo(BeEmpty())
+			})
+
+			It("should keep the transition times", func() {
+				Expect(cluster.Status.Conditions).To(Equal(originalConditions))
+			})
+		})
+
+		It("should report the process group counts", func() {
+			desiredCounts, err := cluster.GetProcessCountsWithDefaults()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.DesiredProcessGroups).To(Equal(desiredCounts.Total()))
+			Expect(cluster.Status.ReadyProcessGroups).To(Equal(desiredCounts.Total()))
+			Expect(cluster.Status.OutdatedProcessGroups).To(Equal(0))
+			Expect(cluster.Status.OutdatedProcessGroupIDs).To(BeEmpty())
+		})
+
+		When("the quota condition is set", func() {
+			BeforeEach(func() {
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					Type:    fdbtypes.ClusterConditionQuotaExceeded,
+					Status:  metav1.ConditionTrue,
+					Reason:  "QuotaExceeded",
+					Message: "Failed to create pods",
+				})
+			})
+
+			It("should resolve the condition once all resources exist", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionQuotaExceeded)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("ResourcesCreated"))
+			})
+		})
+
+		When("the reconciliation has been pending for longer than the timeout", func() {
+			BeforeEach(func() {
+				cluster.Spec.InstancesToRemove = []string{"storage-1"}
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					Type:               fdbtypes.ClusterConditionFullyReconciled,
+					Status:             metav1.ConditionFalse,
+					Reason:             "ReconciliationPending",
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+				})
+			})
+
+			It("should mark the reconciliation as stalled", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("ReconciliationTimeoutExceeded"))
+				Expect(condition.Message).To(HaveSuffix("which is longer than 1h0m0s"))
+			})
+
+			It("should record an event", func() {
+				events := &corev1.EventList{}
+				err = k8sClient.List(context.TODO(), events)
+				Expect(err).NotTo(HaveOccurred())
+				matchingEvents := []corev1.Event{}
+				for _, event := range events.Items {
+					if event.InvolvedObject.UID == cluster.ObjectMeta.UID && event.Reason == "ReconciliationStalled" {
+						matchingEvents = append(matchingEvents, event)
+					}
+				}
+				Expect(matchingEvents).To(HaveLen(1))
+				Expect(matchingEvents[0].Type).To(Equal(corev1.EventTypeWarning))
+			})
+
+			When("the timeout is longer than the pending time", func() {
+				BeforeEach(func() {
+					timeout := 3 * 3600
+					cluster.Spec.AutomationOptions.ReconciliationStalledTimeoutSeconds = &timeout
+				})
+
+				It("should not add the condition", func() {
+					Expect(meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)).To(BeNil())
+				})
+			})
+		})
+
+		When("the cluster is no longer only observed", func() {
+			BeforeEach(func() {
+				cluster.Spec.InstancesToRemove = []string{"storage-1"}
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					Type:               fdbtypes.ClusterConditionFullyReconciled,
+					Status:             metav1.ConditionFalse,
+					Reason:             "ObserveOnly",
+					LastTransitionTime: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+				})
+			})
+
+			It("should reset the transition time", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Reason).To(Equal("ReconciliationPending"))
+				Expect(condition.LastTransitionTime.Time).To(BeTemporally("~", time.Now(), time.Minute))
+			})
+
+			It("should not mark the reconciliation as stalled", func() {
+				Expect(meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)).To(BeNil())
+			})
+		})
+
+		When("the stalled condition is set", func() {
+			BeforeEach(func() {
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					Type:    fdbtypes.ClusterConditionReconciliationStalled,
+					Status:  metav1.ConditionTrue,
+					Reason:  "ReconciliationTimeoutExceeded",
+					Message: "Generation 1 has not been reconciled",
+				})
+			})
+
+			It("should resolve the condition once the cluster is reconciled", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReconciliationStalled)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("Reconciled"))
+			})
+		})
+
+		When("the knobs are changed", func() {
+			BeforeEach(func() {
+				cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{fdbtypes.ProcessClassGeneral: {CustomParameters: &[]string{"knob_disable_posix_kernel_aio=1"}}}
+			})
+
+			It("should count the process groups with outdated config", func() {
+				Expect(cluster.Status.OutdatedProcessGroups).To(Equal(cluster.Status.DesiredProcessGroups))
+				Expect(cluster.Status.OutdatedProcessGroupIDs).To(HaveLen(cluster.Status.DesiredProcessGroups))
+				Expect(cluster.Status.OutdatedProcessGroupIDs).To(ContainElement("storage-1"))
+				Expect(cluster.Status.ReadyProcessGroups).To(Equal(0))
+			})
+		})
+
+		When("a process group is marked for removal", func() {
+			BeforeEach(func() {
+				cluster.Spec.InstancesToRemove = []string{"storage-1"}
+			})
+
+			It("should not count the process group as ready", func() {
+				Expect(cluster.Status.ReadyProcessGroups).To(Equal(cluster.Status.DesiredProcessGroups - 1))
+			})
+
+			It("should report the replacement", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionReplacingInstances)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Message).To(Equal("1 process group(s) are marked for removal"))
+			})
+
+			It("should report the pending reconciliation", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFullyReconciled)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("ReconciliationPending"))
+				Expect(condition.Message).To(ContainSubstring("needsShrink"))
+			})
+		})
+
+		When("the version is changed", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.NextPatchVersion.String()
+			})
+
+			It("should report the upgrade", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionUpgradeInProgress)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Message).To(Equal(fmt.Sprintf("Changing the version from %s to %s", fdbtypes.Versions.Default, fdbtypes.Versions.NextPatchVersion)))
+			})
+		})
+
+		When("the database configuration is changed", func() {
+			BeforeEach(func() {
+				cluster.Spec.DatabaseConfiguration.RedundancyMode = fdbtypes.RedundancyModeTriple
+			})
+
+			It("should report the pending configuration", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionConfigurationPending)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+				Expect(condition.Reason).To(Equal("ConfigurationChangePending"))
+			})
+		})
+
+		When("the database is unavailable", func() {
+			BeforeEach(func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				adminClient.MockDatabaseUnavailable(true)
+			})
+
+			AfterEach(func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				adminClient.MockDatabaseUnavailable(false)
+			})
+
+			It("should report the database as unavailable", func() {
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionAvailable)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("DatabaseUnavailable"))
+			})
+
+			It("should not report the fault tolerance", func() {
+				Expect(cluster.Status.Health.FaultTolerance).To(BeNil())
+				Expect(meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFaultTolerance)).To(BeNil())
+			})
+		})
+
+		It("should report the fault tolerance", func() {
+			Expect(cluster.Status.Health.FaultTolerance).To(Equal(pointer.Int(cluster.DesiredFaultTolerance())))
+		})
+
+		When("the cluster has no remaining fault tolerance", func() {
+			BeforeEach(func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				adminClient.maxZoneFailuresWithoutLosingData = pointer.Int(0)
+			})
+
+			AfterEach(func() {
+				adminClient, err := newMockAdminClientUncast(cluster, k8sClient)
+				Expect(err).NotTo(HaveOccurred())
+				adminClient.maxZoneFailuresWithoutLosingData = nil
+			})
+
+			It("should report the missing fault tolerance", func() {
+				Expect(cluster.Status.Health.FaultTolerance).To(Equal(pointer.Int(0)))
+				condition := meta.FindStatusCondition(cluster.Status.Conditions, fdbtypes.ClusterConditionFaultTolerance)
+				Expect(condition).NotTo(BeNil())
+				Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("NoFaultTolerance"))
+			})
+		})
 	})
 })
+
+// getConditionStatuses returns the status of each condition of the cluster.
+func getConditionStatuses(cluster *fdbtypes.FoundationDBCluster) map[string]metav1.ConditionStatus {
+	statuses := make(map[string]metav1.ConditionStatus, len(cluster.Status.Conditions))
+	for _, condition := range cluster.Status.Conditions {
+		statuses[condition.Type] = condition.Status
+	}
+
+	return statuses
+}
@@ -0,0 +1,135 @@
+/*
+ * update_tag_quotas.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// updateTagQuotas provides a reconciliation step for applying the tag quotas
+// from the cluster spec to the database.
+type updateTagQuotas struct{}
+
+// reconcile runs the reconciler's work.
+func (updateTagQuotas) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	if len(cluster.Spec.TagQuotas) == 0 && len(cluster.Status.ManagedTagQuotas) == 0 {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "updateTagQuotas")
+
+	runningVersion := cluster.Status.RunningVersion
+	if runningVersion == "" {
+		runningVersion = cluster.Spec.Version
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(runningVersion)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !version.HasTagQuotas() {
+		logger.Info("Tag quotas are not supported in the running version", "version", runningVersion)
+		return nil
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	managedTags := make([]string, 0, len(cluster.Spec.TagQuotas))
+	desiredTags := make(map[string]bool, len(cluster.Spec.TagQuotas))
+	for _, quota := range cluster.Spec.TagQuotas {
+		managedTags = append(managedTags, quota.Tag)
+		desiredTags[quota.Tag] = true
+	}
+
+	tags := append([]string{}, managedTags...)
+	for _, tag := range cluster.Status.ManagedTagQuotas {
+		if !desiredTags[tag] {
+			tags = append(tags, tag)
+		}
+	}
+
+	currentQuotas, err := adminClient.GetTagQuotas(context, tags)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	quotasByTag := make(map[string]fdbtypes.TagQuota, len(currentQuotas))
+	for _, quota := range currentQuotas {
+		quotasByTag[quota.Tag] = quota
+	}
+
+	for _, quota := range cluster.Spec.TagQuotas {
+		current, present := quotasByTag[quota.Tag]
+		if present && current == quota {
+			continue
+		}
+
+		logger.Info("Setting tag quota", "tag", quota.Tag, "reservedThroughput", quota.ReservedThroughput, "totalThroughput", quota.TotalThroughput)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "SettingTagQuota",
+			fmt.Sprintf("Setting quota of tag %s to %d reserved and %d total bytes per second", quota.Tag, quota.ReservedThroughput, quota.TotalThroughput))
+		err = adminClient.SetTagQuota(context, quota)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	for _, tag := range cluster.Status.ManagedTagQuotas {
+		if desiredTags[tag] {
+			continue
+		}
+
+		if _, present := quotasByTag[tag]; !present {
+			continue
+		}
+
+		logger.Info("Clearing tag quota", "tag", tag)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "ClearingTagQuota", fmt.Sprintf("Clearing quota of tag %s", tag))
+		err = adminClient.ClearTagQuota(context, tag)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(managedTags) == 0 {
+		managedTags = nil
+	}
+
+	if !equality.Semantic.DeepEqual(cluster.Status.ManagedTagQuotas, managedTags) {
+		cluster.Status.ManagedTagQuotas = managedTags
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
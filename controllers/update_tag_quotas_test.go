@@ -0,0 +1,129 @@
+/*
+ * update_tag_quotas_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("update_tag_quotas", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster.Status.RunningVersion = "7.2.0"
+		cluster.Spec.TagQuotas = []fdbtypes.TagQuota{
+			{Tag: "noisy", TotalThroughput: 5000},
+			{Tag: "reader", ReservedThroughput: 1000, TotalThroughput: 2000},
+		}
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateTagQuotas{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	getQuotas := func(tags ...string) []fdbtypes.TagQuota {
+		quotas, err := adminClient.GetTagQuotas(context.TODO(), tags)
+		Expect(err).NotTo(HaveOccurred())
+		return quotas
+	}
+
+	Context("with new tag quotas", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should set the quotas", func() {
+			Expect(getQuotas("noisy", "reader")).To(Equal(cluster.Spec.TagQuotas))
+		})
+
+		It("should record the managed tags", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.ManagedTagQuotas).To(Equal([]string{"noisy", "reader"}))
+		})
+	})
+
+	Context("with a quota that has drifted", func() {
+		BeforeEach(func() {
+			err = adminClient.SetTagQuota(context.TODO(), fdbtypes.TagQuota{Tag: "noisy", TotalThroughput: 100})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should update the quota", func() {
+			Expect(getQuotas("noisy")).To(Equal([]fdbtypes.TagQuota{{Tag: "noisy", TotalThroughput: 5000}}))
+		})
+	})
+
+	Context("with a tag that was removed from the spec", func() {
+		BeforeEach(func() {
+			err = adminClient.SetTagQuota(context.TODO(), fdbtypes.TagQuota{Tag: "old", TotalThroughput: 100})
+			Expect(err).NotTo(HaveOccurred())
+			err = adminClient.SetTagQuota(context.TODO(), fdbtypes.TagQuota{Tag: "manual", TotalThroughput: 100})
+			Expect(err).NotTo(HaveOccurred())
+			cluster.Status.ManagedTagQuotas = []string{"noisy", "old"}
+		})
+
+		It("should clear the quota for the managed tag", func() {
+			Expect(getQuotas("old")).To(BeEmpty())
+		})
+
+		It("should keep the quota that was set manually", func() {
+			Expect(getQuotas("manual")).To(Equal([]fdbtypes.TagQuota{{Tag: "manual", TotalThroughput: 100}}))
+		})
+	})
+
+	Context("with a version that does not support tag quotas", func() {
+		BeforeEach(func() {
+			cluster.Status.RunningVersion = "7.1.0"
+		})
+
+		It("should not set any quotas", func() {
+			Expect(getQuotas("noisy", "reader")).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,148 @@
+/*
+ * update_tag_throttles.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	ctx "context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// tagThrottleDuration is the duration of the tag throttles that the operator
+// sets. The operator renews the throttles once half of the duration has
+// passed, so the throttles expire on their own if the operator stops
+// managing the cluster.
+const tagThrottleDuration = time.Hour
+
+// updateTagThrottles provides a reconciliation step for applying the tag
+// throttles from the cluster spec to the database.
+type updateTagThrottles struct{}
+
+// reconcile runs the reconciler's work.
+func (updateTagThrottles) reconcile(r *FoundationDBClusterReconciler, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) *requeue {
+	if len(cluster.Spec.TagThrottles) == 0 && len(cluster.Status.ManagedTagThrottles) == 0 {
+		return nil
+	}
+
+	logger := getLogger(context, cluster, "updateTagThrottles")
+
+	runningVersion := cluster.Status.RunningVersion
+	if runningVersion == "" {
+		runningVersion = cluster.Spec.Version
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(runningVersion)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	if !version.HasTagThrottling() {
+		logger.Info("Tag throttling is not supported in the running version", "version", runningVersion)
+		return nil
+	}
+
+	adminClient, err := r.getDatabaseClientProvider().GetAdminClient(cluster, r)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+	defer adminClient.Close()
+
+	currentThrottles, err := adminClient.GetTagThrottles(context)
+	if err != nil {
+		return &requeue{curError: err}
+	}
+
+	manualThrottles := make(map[string]fdbtypes.TagThrottleStatus, len(currentThrottles))
+	for _, throttle := range currentThrottles {
+		if throttle.Manual {
+			manualThrottles[throttle.Tag] = throttle
+		}
+	}
+
+	managedTags := make([]string, 0, len(cluster.Spec.TagThrottles))
+	desiredTags := make(map[string]bool, len(cluster.Spec.TagThrottles))
+	for _, throttle := range cluster.Spec.TagThrottles {
+		managedTags = append(managedTags, throttle.Tag)
+		desiredTags[throttle.Tag] = true
+
+		current, present := manualThrottles[throttle.Tag]
+		if present && current.Rate == throttle.Rate && current.Priority == throttle.GetPriority() &&
+			time.Duration(current.ExpirationSeconds)*time.Second > tagThrottleDuration/2 {
+			continue
+		}
+
+		// A throttle with a different priority would stay active next to
+		// the new throttle, so we have to remove it first.
+		if present && current.Priority != throttle.GetPriority() {
+			err = adminClient.UnthrottleTag(context, throttle.Tag)
+			if err != nil {
+				return &requeue{curError: err}
+			}
+		}
+
+		logger.Info("Throttling tag", "tag", throttle.Tag, "rate", throttle.Rate, "priority", throttle.GetPriority())
+		if !present || current.Rate != throttle.Rate || current.Priority != throttle.GetPriority() {
+			r.Recorder.Event(cluster, corev1.EventTypeNormal, "ThrottlingTag",
+				fmt.Sprintf("Throttling tag %s to %d transactions per second", throttle.Tag, throttle.Rate))
+		}
+
+		err = adminClient.ThrottleTag(context, throttle, tagThrottleDuration)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	for _, tag := range cluster.Status.ManagedTagThrottles {
+		if desiredTags[tag] {
+			continue
+		}
+
+		if _, present := manualThrottles[tag]; !present {
+			continue
+		}
+
+		logger.Info("Removing tag throttle", "tag", tag)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "UnthrottlingTag", fmt.Sprintf("Removing throttle on tag %s", tag))
+		err = adminClient.UnthrottleTag(context, tag)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	if len(managedTags) == 0 {
+		managedTags = nil
+	}
+
+	if !equality.Semantic.DeepEqual(cluster.Status.ManagedTagThrottles, managedTags) {
+		cluster.Status.ManagedTagThrottles = managedTags
+		err = r.Status().Update(context, cluster)
+		if err != nil {
+			return &requeue{curError: err}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,154 @@
+/*
+ * update_tag_throttles_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("update_tag_throttles", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var adminClient *mockAdminClient
+	var err error
+	var requeue *requeue
+
+	BeforeEach(func() {
+		cluster = internal.CreateDefaultCluster()
+		err = k8sClient.Create(context.TODO(), cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		result, err := reconcileCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Requeue).To(BeFalse())
+
+		generation, err := reloadCluster(cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generation).To(Equal(int64(1)))
+
+		adminClient, err = newMockAdminClientUncast(cluster, k8sClient)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster.Status.RunningVersion = "6.3.12"
+		cluster.Spec.TagThrottles = []fdbtypes.TagThrottle{
+			{Tag: "noisy", Rate: 100},
+			{Tag: "batch", Rate: 10, Priority: fdbtypes.TagThrottlePriorityBatch},
+		}
+	})
+
+	JustBeforeEach(func() {
+		requeue = updateTagThrottles{}.reconcile(clusterReconciler, context.TODO(), cluster)
+		if requeue != nil {
+			Expect(requeue.curError).NotTo(HaveOccurred())
+		}
+	})
+
+	getThrottles := func() []fdbtypes.TagThrottle {
+		statuses, err := adminClient.GetTagThrottles(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		throttles := make([]fdbtypes.TagThrottle, 0, len(statuses))
+		for _, status := range statuses {
+			throttles = append(throttles, status.TagThrottle)
+		}
+		return throttles
+	}
+
+	Context("with new tag throttles", func() {
+		It("should not requeue", func() {
+			Expect(requeue).To(BeNil())
+		})
+
+		It("should throttle the tags", func() {
+			Expect(getThrottles()).To(Equal([]fdbtypes.TagThrottle{
+				{Tag: "batch", Rate: 10, Priority: fdbtypes.TagThrottlePriorityBatch},
+				{Tag: "noisy", Rate: 100, Priority: fdbtypes.TagThrottlePriorityDefault},
+			}))
+		})
+
+		It("should record the managed tags", func() {
+			_, err = reloadCluster(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster.Status.ManagedTagThrottles).To(Equal([]string{"noisy", "batch"}))
+		})
+	})
+
+	Context("with a changed rate", func() {
+		BeforeEach(func() {
+			err = adminClient.ThrottleTag(context.TODO(), fdbtypes.TagThrottle{Tag: "noisy", Rate: 50}, tagThrottleDuration)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should update the throttle", func() {
+			Expect(getThrottles()).To(ContainElement(fdbtypes.TagThrottle{Tag: "noisy", Rate: 100, Priority: fdbtypes.TagThrottlePriorityDefault}))
+		})
+	})
+
+	Context("with a throttle that is about to expire", func() {
+		BeforeEach(func() {
+			err = adminClient.ThrottleTag(context.TODO(), fdbtypes.TagThrottle{Tag: "noisy", Rate: 100}, 10*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should renew the throttle", func() {
+			statuses, err := adminClient.GetTagThrottles(context.TODO())
+			Expect(err).NotTo(HaveOccurred())
+			for _, status := range statuses {
+				Expect(status.ExpirationSeconds).To(BeNumerically(">", 30*60))
+			}
+		})
+	})
+
+	Context("with a tag that was removed from the spec", func() {
+		BeforeEach(func() {
+			err = adminClient.ThrottleTag(context.TODO(), fdbtypes.TagThrottle{Tag: "old", Rate: 1}, tagThrottleDuration)
+			Expect(err).NotTo(HaveOccurred())
+			err = adminClient.ThrottleTag(context.TODO(), fdbtypes.TagThrottle{Tag: "manual", Rate: 1}, tagThrottleDuration)
+			Expect(err).NotTo(HaveOccurred())
+			cluster.Status.ManagedTagThrottles = []string{"old"}
+		})
+
+		It("should remove the throttle for the managed tag", func() {
+			for _, throttle := range getThrottles() {
+				Expect(throttle.Tag).NotTo(Equal("old"))
+			}
+		})
+
+		It("should keep the throttle that was set manually", func() {
+			Expect(getThrottles()).To(ContainElement(fdbtypes.TagThrottle{Tag: "manual", Rate: 1, Priority: fdbtypes.TagThrottlePriorityDefault}))
+		})
+	})
+
+	Context("with a version that does not support tag throttling", func() {
+		BeforeEach(func() {
+			cluster.Status.RunningVersion = fdbtypes.Versions.Default.String()
+		})
+
+		It("should not throttle any tags", func() {
+			Expect(getThrottles()).To(BeEmpty())
+		})
+	})
+})
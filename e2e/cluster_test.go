@@ -5,6 +5,7 @@ import (
 	"io"
 	"log"
 	"math/rand"
+	"os"
 	"strconv"
 	"time"
 
@@ -26,6 +27,26 @@ import (
 var letterRunes = []rune("abcdefghijklmnopqrstuvwxyz123456789")
 var failed = false
 
+// fdbVersion is the version of FoundationDB that the test clusters are
+// created with. It can be set through the FDB_VERSION environment variable.
+var fdbVersion = getEnvOrDefault("FDB_VERSION", "6.2.30")
+
+// upgradeVersion is the version of FoundationDB that the upgrade tests
+// upgrade the test clusters to. It can be set through the
+// FDB_UPGRADE_VERSION environment variable.
+var upgradeVersion = getEnvOrDefault("FDB_UPGRADE_VERSION", "6.3.15")
+
+// getEnvOrDefault returns the value of the environment variable, or the
+// default value if the variable is not set.
+func getEnvOrDefault(name string, defaultValue string) string {
+	value, present := os.LookupEnv(name)
+	if !present || value == "" {
+		return defaultValue
+	}
+
+	return value
+}
+
 // RandStringRunes randomly generates a string of length n
 func randStringRunes(n int) string {
 	b := make([]rune, n)
@@ -127,7 +148,7 @@ var _ = Describe("[e2e] cluster tests", func() {
 					Namespace: namespace,
 				},
 				Spec: fdbtypes.FoundationDBClusterSpec{
-					Version: "6.2.30",
+					Version: fdbVersion,
 					FaultDomain: fdbtypes.FoundationDBClusterFaultDomain{
 						Key: "foundationdb.org/none",
 					},
@@ -213,6 +234,86 @@ var _ = Describe("[e2e] cluster tests", func() {
 				clusterReconciled(runtimeClient, testCluster, kubeClient)
 			})
 		})
+
+		When("scaling the storage processes", func() {
+			BeforeEach(func() {
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				updateCluster(runtimeClient, testCluster, func(cluster *fdbtypes.FoundationDBCluster) {
+					cluster.Spec.ProcessCounts.Storage = 2
+				})
+			})
+
+			It("should add and remove the process groups", func() {
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				Expect(activeProcessGroupIDs(getCluster(runtimeClient, testCluster), fdbtypes.ProcessClassStorage)).To(HaveLen(2))
+				Expect(clusterPods(kubeClient, testCluster)).To(HaveLen(2))
+
+				By("scaling the storage processes back down")
+				updateCluster(runtimeClient, testCluster, func(cluster *fdbtypes.FoundationDBCluster) {
+					cluster.Spec.ProcessCounts.Storage = 1
+				})
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				Expect(activeProcessGroupIDs(getCluster(runtimeClient, testCluster), fdbtypes.ProcessClassStorage)).To(HaveLen(1))
+				Eventually(func() int {
+					return len(clusterPods(kubeClient, testCluster))
+				}, 300*time.Second, 1*time.Second).Should(Equal(1))
+			})
+		})
+
+		When("upgrading the cluster", func() {
+			BeforeEach(func() {
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				updateCluster(runtimeClient, testCluster, func(cluster *fdbtypes.FoundationDBCluster) {
+					cluster.Spec.Version = upgradeVersion
+				})
+			})
+
+			It("should run the new version", func() {
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				Expect(getCluster(runtimeClient, testCluster).Status.RunningVersion).To(Equal(upgradeVersion))
+
+				for _, pod := range clusterPods(kubeClient, testCluster) {
+					for _, container := range pod.Spec.Containers {
+						if container.Name != "foundationdb" {
+							continue
+						}
+
+						Expect(container.Image).To(HaveSuffix(":" + upgradeVersion))
+					}
+				}
+			})
+		})
+
+		When("replacing a process group", func() {
+			var removedID string
+
+			BeforeEach(func() {
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				processGroupIDs := activeProcessGroupIDs(getCluster(runtimeClient, testCluster), fdbtypes.ProcessClassStorage)
+				Expect(processGroupIDs).NotTo(BeEmpty())
+				removedID = processGroupIDs[0]
+
+				updateCluster(runtimeClient, testCluster, func(cluster *fdbtypes.FoundationDBCluster) {
+					cluster.Spec.InstancesToRemove = append(cluster.Spec.InstancesToRemove, removedID)
+				})
+			})
+
+			It("should replace the process group with a new one", func() {
+				clusterReconciled(runtimeClient, testCluster, kubeClient)
+				cluster := getCluster(runtimeClient, testCluster)
+				processGroupIDs := activeProcessGroupIDs(cluster, fdbtypes.ProcessClassStorage)
+				Expect(processGroupIDs).To(HaveLen(1))
+				Expect(processGroupIDs).NotTo(ContainElement(removedID))
+
+				Eventually(func() []string {
+					var podProcessGroupIDs []string
+					for _, pod := range clusterPods(kubeClient, testCluster) {
+						podProcessGroupIDs = append(podProcessGroupIDs, pod.Labels[fdbtypes.FDBProcessGroupIDLabel])
+					}
+					return podProcessGroupIDs
+				}, 300*time.Second, 1*time.Second).Should(ConsistOf(processGroupIDs))
+			})
+		})
 	})
 
 	AfterEach(func() {
@@ -271,3 +372,62 @@ func clusterReconciled(runtimeClient client.Client, testCluster *fdbtypes.Founda
 		return false
 	}, 300*time.Second, 1*time.Second).Should(BeTrue())
 }
+
+// getCluster fetches the current state of the test cluster.
+func getCluster(runtimeClient client.Client, testCluster *fdbtypes.FoundationDBCluster) *fdbtypes.FoundationDBCluster {
+	cluster := &fdbtypes.FoundationDBCluster{}
+	err := runtimeClient.Get(context.Background(), client.ObjectKey{
+		Name:      testCluster.Name,
+		Namespace: testCluster.Namespace,
+	}, cluster)
+	Expect(err).NotTo(HaveOccurred())
+
+	return cluster
+}
+
+// updateCluster applies the changes from the update function to the current
+// state of the test cluster.
+func updateCluster(runtimeClient client.Client, testCluster *fdbtypes.FoundationDBCluster, update func(cluster *fdbtypes.FoundationDBCluster)) {
+	Eventually(func() error {
+		cluster := getCluster(runtimeClient, testCluster)
+		patch := client.MergeFrom(cluster.DeepCopy())
+		update(cluster)
+		return runtimeClient.Patch(context.Background(), cluster, patch)
+	}).ShouldNot(HaveOccurred())
+}
+
+// activeProcessGroupIDs returns the IDs of the process groups of a process
+// class that are not marked for removal in the cluster status.
+func activeProcessGroupIDs(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) []string {
+	var processGroupIDs []string
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if processGroup.ProcessClass != processClass || processGroup.Remove {
+			continue
+		}
+
+		processGroupIDs = append(processGroupIDs, processGroup.ProcessGroupID)
+	}
+
+	return processGroupIDs
+}
+
+// clusterPods returns the pods of the test cluster that are not being deleted.
+func clusterPods(kubeClient *kubernetes.Clientset, testCluster *fdbtypes.FoundationDBCluster) []corev1.Pod {
+	pods, err := kubeClient.CoreV1().Pods(testCluster.Namespace).List(context.Background(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{
+			fdbtypes.FDBClusterLabel: testCluster.Name,
+		}).String()},
+	)
+	Expect(err).NotTo(HaveOccurred())
+
+	var activePods []corev1.Pod
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		activePods = append(activePods, pod)
+	}
+
+	return activePods
+}
@@ -58,6 +58,7 @@ func parseMaxCommandOutput() int {
 
 var exclusionLinePattern = regexp.MustCompile("(?m)^ +(.*)$")
 var protocolVersionRegex = regexp.MustCompile(`(?m)^protocol (\w+)$`)
+var drLagRegex = regexp.MustCompile(`The DR is ([0-9.]+) seconds behind`)
 
 // cliAdminClient provides an implementation of the admin interface using the
 // FDB CLI.
@@ -65,30 +66,46 @@ type cliAdminClient struct {
 	// Cluster is the reference to the cluster model.
 	Cluster *fdbtypes.FoundationDBCluster
 
-	// clusterFilePath is the path to the temp file containing the cluster file
-	// for this session.
+	// clusterFilePath is the path to the cluster file for the cluster. This
+	// file is shared by all clients for the cluster.
 	clusterFilePath string
 }
 
 // NewCliAdminClient generates an Admin client for a cluster
 func NewCliAdminClient(cluster *fdbtypes.FoundationDBCluster, _ client.Client) (fdbadminclient.AdminClient, error) {
-	clusterFile, err := os.CreateTemp("", "")
+	clusterFilePath, err := ensureClusterFile(cluster)
 	if err != nil {
 		return nil, err
 	}
+
+	return &cliAdminClient{Cluster: cluster, clusterFilePath: clusterFilePath}, nil
+}
+
+// createClusterFile writes the connection string into a temp file in the
+// cluster file directory and returns the path of that file.
+func createClusterFile(connectionString string) (string, error) {
+	err := os.MkdirAll(ClusterFileDir, 0700)
+	if err != nil {
+		return "", err
+	}
+
+	clusterFile, err := os.CreateTemp(ClusterFileDir, "*.cluster.tmp")
+	if err != nil {
+		return "", err
+	}
 	clusterFilePath := clusterFile.Name()
 
 	defer clusterFile.Close()
-	_, err = clusterFile.WriteString(cluster.Status.ConnectionString)
+	_, err = clusterFile.WriteString(connectionString)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	err = clusterFile.Close()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	return &cliAdminClient{Cluster: cluster, clusterFilePath: clusterFilePath}, nil
+	return clusterFilePath, nil
 }
 
 // cliCommand describes a command that we are running against FDB.
@@ -104,6 +121,10 @@ type cliCommand struct {
 
 	// args provides alternative arguments in place of the exec command.
 	args []string
+
+	// timeout provides the time in seconds after which the operator stops
+	// the command. If this is 0, the operator uses the default CLI timeout.
+	timeout int
 }
 
 // hasTimeoutArg determines whether a command accepts a timeout argument.
@@ -123,6 +144,9 @@ func (command cliCommand) getClusterFileFlag() string {
 	if command.binary == "fdbrestore" {
 		return "--dest_cluster_file"
 	}
+	if command.binary == "fdbdr" {
+		return "-d"
+	}
 	return "-C"
 }
 
@@ -133,7 +157,7 @@ func getBinaryPath(binaryName string, version string) string {
 }
 
 // runCommand executes a command in the CLI.
-func (client *cliAdminClient) runCommand(command cliCommand) (string, error) {
+func (client *cliAdminClient) runCommand(ctx context.Context, command cliCommand) (string, error) {
 	version := command.version
 	if version == "" {
 		version = client.Cluster.Status.RunningVersion
@@ -149,6 +173,9 @@ func (client *cliAdminClient) runCommand(command cliCommand) (string, error) {
 
 	binary := getBinaryPath(binaryName, version)
 	hardTimeout := DefaultCLITimeout
+	if command.timeout > 0 {
+		hardTimeout = command.timeout
+	}
 	args := make([]string, 0, 9)
 	args = append(args, command.args...)
 	if len(args) == 0 {
@@ -174,7 +201,7 @@ func (client *cliAdminClient) runCommand(command cliCommand) (string, error) {
 	} else {
 		args = append(args, "--logdir", os.Getenv("FDB_NETWORK_OPTION_TRACE_ENABLE"))
 	}
-	timeoutContext, cancelFunction := context.WithTimeout(context.Background(), time.Second*time.Duration(hardTimeout))
+	timeoutContext, cancelFunction := context.WithTimeout(ctx, time.Second*time.Duration(hardTimeout))
 	defer cancelFunction()
 	execCommand := exec.CommandContext(timeoutContext, binary, args...)
 
@@ -202,16 +229,16 @@ func (client *cliAdminClient) runCommand(command cliCommand) (string, error) {
 }
 
 // GetStatus gets the database's status
-func (client *cliAdminClient) GetStatus() (*fdbtypes.FoundationDBStatus, error) {
+func (client *cliAdminClient) GetStatus(ctx context.Context) (*fdbtypes.FoundationDBStatus, error) {
 	adminClientMutex.Lock()
 	defer adminClientMutex.Unlock()
 	// This will call directly the database and fetch the status information
 	// from the system key space.
-	return getStatusFromDB(client.Cluster)
+	return getStatusFromDB(ctx, client.Cluster)
 }
 
 // ConfigureDatabase sets the database configuration
-func (client *cliAdminClient) ConfigureDatabase(configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error {
+func (client *cliAdminClient) ConfigureDatabase(ctx context.Context, configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error {
 	configurationString, err := configuration.GetConfigurationString()
 	if err != nil {
 		return err
@@ -221,45 +248,33 @@ func (client *cliAdminClient) ConfigureDatabase(configuration fdbtypes.DatabaseC
 		configurationString = "new " + configurationString
 	}
 
-	_, err = client.runCommand(cliCommand{command: fmt.Sprintf("configure %s", configurationString)})
+	_, err = client.runCommand(ctx, cliCommand{command: fmt.Sprintf("configure %s", configurationString)})
 	return err
 }
 
 // ExcludeInstances starts evacuating processes so that they can be removed
 // from the database.
-func (client *cliAdminClient) ExcludeInstances(addresses []fdbtypes.ProcessAddress) error {
+func (client *cliAdminClient) ExcludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
 	if len(addresses) == 0 {
 		return nil
 	}
 
-	version, err := fdbtypes.ParseFdbVersion(client.Cluster.Spec.Version)
+	command, err := internal.GetExcludeCommand(client.Cluster, addresses)
 	if err != nil {
 		return err
 	}
 
-	if version.HasNonBlockingExcludes(client.Cluster.GetUseNonBlockingExcludes()) {
-		_, err = client.runCommand(cliCommand{
-			command: fmt.Sprintf(
-				"exclude no_wait %s",
-				fdbtypes.ProcessAddressesString(addresses, " "),
-			)})
-	} else {
-		_, err = client.runCommand(cliCommand{
-			command: fmt.Sprintf(
-				"exclude %s",
-				fdbtypes.ProcessAddressesString(addresses, " "),
-			)})
-	}
+	_, err = client.runCommand(ctx, cliCommand{command: command})
 	return err
 }
 
 // IncludeInstances removes processes from the exclusion list and allows
 // them to take on roles again.
-func (client *cliAdminClient) IncludeInstances(addresses []fdbtypes.ProcessAddress) error {
+func (client *cliAdminClient) IncludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
 	if len(addresses) == 0 {
 		return nil
 	}
-	_, err := client.runCommand(cliCommand{command: fmt.Sprintf(
+	_, err := client.runCommand(ctx, cliCommand{command: fmt.Sprintf(
 		"include %s",
 		fdbtypes.ProcessAddressesString(addresses, " "),
 	)})
@@ -268,8 +283,8 @@ func (client *cliAdminClient) IncludeInstances(addresses []fdbtypes.ProcessAddre
 
 // GetExclusions gets a list of the addresses currently excluded from the
 // database.
-func (client *cliAdminClient) GetExclusions() ([]fdbtypes.ProcessAddress, error) {
-	output, err := client.runCommand(cliCommand{command: "exclude"})
+func (client *cliAdminClient) GetExclusions(ctx context.Context) ([]fdbtypes.ProcessAddress, error) {
+	output, err := client.runCommand(ctx, cliCommand{command: "exclude"})
 	if err != nil {
 		return nil, err
 	}
@@ -288,19 +303,45 @@ func (client *cliAdminClient) GetExclusions() ([]fdbtypes.ProcessAddress, error)
 	return exclusions, nil
 }
 
+// ExcludeLocalities starts evacuating all processes of the given localities.
+func (client *cliAdminClient) ExcludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error {
+	if len(localities) == 0 {
+		return nil
+	}
+
+	command, err := internal.GetExcludeLocalitiesCommand(client.Cluster, localities)
+	if err != nil {
+		return err
+	}
+
+	_, err = client.runCommand(ctx, cliCommand{command: command})
+	return err
+}
+
+// IncludeLocalities removes the given localities from the exclusion list and
+// allows their processes to take on roles again.
+func (client *cliAdminClient) IncludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error {
+	if len(localities) == 0 {
+		return nil
+	}
+
+	_, err := client.runCommand(ctx, cliCommand{command: internal.GetIncludeLocalitiesCommand(localities)})
+	return err
+}
+
 // CanSafelyRemove checks whether it is safe to remove processes from the
 // cluster
 //
 // The list returned by this method will be the addresses that are *not*
 // safe to remove.
-func (client *cliAdminClient) CanSafelyRemove(addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error) {
+func (client *cliAdminClient) CanSafelyRemove(ctx context.Context, addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error) {
 	version, err := fdbtypes.ParseFdbVersion(client.Cluster.Spec.Version)
 	if err != nil {
 		return nil, err
 	}
 
 	if version.HasNonBlockingExcludes(client.Cluster.GetUseNonBlockingExcludes()) {
-		output, err := client.runCommand(cliCommand{command: fmt.Sprintf(
+		output, err := client.runCommand(ctx, cliCommand{command: fmt.Sprintf(
 			"exclude no_wait %s",
 			fdbtypes.ProcessAddressesString(addresses, " "),
 		)})
@@ -318,7 +359,7 @@ func (client *cliAdminClient) CanSafelyRemove(addresses []fdbtypes.ProcessAddres
 
 		return remaining, nil
 	}
-	_, err = client.runCommand(cliCommand{command: fmt.Sprintf(
+	_, err = client.runCommand(ctx, cliCommand{command: fmt.Sprintf(
 		"exclude %s",
 		fdbtypes.ProcessAddressesString(addresses, " "),
 	)})
@@ -333,6 +374,12 @@ func parseExclusionOutput(output string) map[string]string {
 	matches := regex.FindAllStringSubmatch(output, -1)
 	for _, match := range matches {
 		address := match[1]
+		// Normalize the address, so that it matches the string representation
+		// of the ProcessAddress e.g. for IPv6 addresses.
+		pAddr, err := fdbtypes.ParseProcessAddress(address)
+		if err == nil {
+			address = pAddr.String()
+		}
 		status := match[len(match)-1]
 		if strings.Contains(status, "Successfully excluded") {
 			results[address] = "Success"
@@ -348,11 +395,11 @@ func parseExclusionOutput(output string) map[string]string {
 }
 
 // KillInstances restarts processes
-func (client *cliAdminClient) KillInstances(addresses []fdbtypes.ProcessAddress) error {
+func (client *cliAdminClient) KillInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error {
 	if len(addresses) == 0 {
 		return nil
 	}
-	_, err := client.runCommand(cliCommand{command: fmt.Sprintf(
+	_, err := client.runCommand(ctx, cliCommand{command: fmt.Sprintf(
 		"kill; kill %s; status",
 		fdbtypes.ProcessAddressesStringWithoutFlags(addresses, " "),
 	)})
@@ -360,8 +407,8 @@ func (client *cliAdminClient) KillInstances(addresses []fdbtypes.ProcessAddress)
 }
 
 // ChangeCoordinators changes the coordinator set
-func (client *cliAdminClient) ChangeCoordinators(addresses []fdbtypes.ProcessAddress) (string, error) {
-	_, err := client.runCommand(cliCommand{command: fmt.Sprintf(
+func (client *cliAdminClient) ChangeCoordinators(ctx context.Context, addresses []fdbtypes.ProcessAddress) (string, error) {
+	_, err := client.runCommand(ctx, cliCommand{command: fmt.Sprintf(
 		"coordinators %s",
 		fdbtypes.ProcessAddressesString(addresses, " "),
 	)})
@@ -382,8 +429,8 @@ func (client *cliAdminClient) ChangeCoordinators(addresses []fdbtypes.ProcessAdd
 }
 
 // GetConnectionString fetches the latest connection string.
-func (client *cliAdminClient) GetConnectionString() (string, error) {
-	output, err := client.runCommand(cliCommand{command: "status minimal"})
+func (client *cliAdminClient) GetConnectionString(ctx context.Context) (string, error) {
+	output, err := client.runCommand(ctx, cliCommand{command: "status minimal"})
 	if err != nil {
 		return "", err
 	}
@@ -404,9 +451,15 @@ func (client *cliAdminClient) GetConnectionString() (string, error) {
 	return connectionString.String(), nil
 }
 
+// GetLiveConnectionString reads the connection string that the database
+// stores in the \xff/coordinators key.
+func (client *cliAdminClient) GetLiveConnectionString(ctx context.Context) (string, error) {
+	return getConnectionStringFromDB(ctx, client.Cluster)
+}
+
 // VersionSupported reports whether we can support a cluster with a given
 // version.
-func (client *cliAdminClient) VersionSupported(versionString string) (bool, error) {
+func (client *cliAdminClient) VersionSupported(ctx context.Context, versionString string) (bool, error) {
 	version, err := fdbtypes.ParseFdbVersion(versionString)
 	if err != nil {
 		return false, err
@@ -429,8 +482,8 @@ func (client *cliAdminClient) VersionSupported(versionString string) (bool, erro
 
 // GetProtocolVersion determines the protocol version that is used by a
 // version of FDB.
-func (client *cliAdminClient) GetProtocolVersion(version string) (string, error) {
-	output, err := client.runCommand(cliCommand{args: []string{"--version"}, version: version})
+func (client *cliAdminClient) GetProtocolVersion(ctx context.Context, version string) (string, error) {
+	output, err := client.runCommand(ctx, cliCommand{args: []string{"--version"}, version: version})
 	if err != nil {
 		return "", err
 	}
@@ -444,25 +497,43 @@ func (client *cliAdminClient) GetProtocolVersion(version string) (string, error)
 	return protocolVersionMatch[1], nil
 }
 
+// GetConnectedClients lists the client versions and protocol versions of the
+// clients that are connected to the database.
+func (client *cliAdminClient) GetConnectedClients(ctx context.Context) ([]fdbtypes.FoundationDBStatusSupportedVersion, error) {
+	status, err := client.GetStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return status.Cluster.Clients.SupportedVersions, nil
+}
+
 // StartBackup starts a new backup.
-func (client *cliAdminClient) StartBackup(url string, snapshotPeriodSeconds int) error {
-	_, err := client.runCommand(cliCommand{
+func (client *cliAdminClient) StartBackup(ctx context.Context, url string, snapshotPeriodSeconds int, mode fdbtypes.BackupMode) error {
+	args := []string{
+		"start",
+		"-d",
+		url,
+		"-s",
+		fmt.Sprintf("%d", snapshotPeriodSeconds),
+	}
+
+	// Without the -z flag the backup stops once the first snapshot is
+	// complete.
+	if mode != fdbtypes.BackupModeSnapshot {
+		args = append(args, "-z")
+	}
+
+	_, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbbackup",
-		args: []string{
-			"start",
-			"-d",
-			url,
-			"-s",
-			fmt.Sprintf("%d", snapshotPeriodSeconds),
-			"-z",
-		},
+		args:   args,
 	})
 	return err
 }
 
 // StopBackup stops a backup.
-func (client *cliAdminClient) StopBackup(url string) error {
-	_, err := client.runCommand(cliCommand{
+func (client *cliAdminClient) StopBackup(ctx context.Context, url string) error {
+	_, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbbackup",
 		args: []string{
 			"discontinue",
@@ -472,8 +543,8 @@ func (client *cliAdminClient) StopBackup(url string) error {
 }
 
 // PauseBackups pauses the backups.
-func (client *cliAdminClient) PauseBackups() error {
-	_, err := client.runCommand(cliCommand{
+func (client *cliAdminClient) PauseBackups(ctx context.Context) error {
+	_, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbbackup",
 		args: []string{
 			"pause",
@@ -483,8 +554,8 @@ func (client *cliAdminClient) PauseBackups() error {
 }
 
 // ResumeBackups resumes the backups.
-func (client *cliAdminClient) ResumeBackups() error {
-	_, err := client.runCommand(cliCommand{
+func (client *cliAdminClient) ResumeBackups(ctx context.Context) error {
+	_, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbbackup",
 		args: []string{
 			"resume",
@@ -494,8 +565,8 @@ func (client *cliAdminClient) ResumeBackups() error {
 }
 
 // ModifyBackup updates the backup parameters.
-func (client *cliAdminClient) ModifyBackup(snapshotPeriodSeconds int) error {
-	_, err := client.runCommand(cliCommand{
+func (client *cliAdminClient) ModifyBackup(ctx context.Context, snapshotPeriodSeconds int) error {
+	_, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbbackup",
 		args: []string{
 			"modify",
@@ -507,8 +578,8 @@ func (client *cliAdminClient) ModifyBackup(snapshotPeriodSeconds int) error {
 }
 
 // GetBackupStatus gets the status of the current backup.
-func (client *cliAdminClient) GetBackupStatus() (*fdbtypes.FoundationDBLiveBackupStatus, error) {
-	statusString, err := client.runCommand(cliCommand{
+func (client *cliAdminClient) GetBackupStatus(ctx context.Context) (*fdbtypes.FoundationDBLiveBackupStatus, error) {
+	statusString, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbbackup",
 		args: []string{
 			"status",
@@ -521,7 +592,7 @@ func (client *cliAdminClient) GetBackupStatus() (*fdbtypes.FoundationDBLiveBacku
 	}
 
 	status := &fdbtypes.FoundationDBLiveBackupStatus{}
-	statusString, err = removeWarningsInJSON(statusString)
+	statusString, err = internal.RemoveWarningsInJSON(statusString)
 	if err != nil {
 		return nil, err
 	}
@@ -534,14 +605,72 @@ func (client *cliAdminClient) GetBackupStatus() (*fdbtypes.FoundationDBLiveBacku
 	return status, nil
 }
 
+// ExpireBackup deletes old data from a backup based on the expiration
+// policy.
+func (client *cliAdminClient) ExpireBackup(ctx context.Context, url string, policy fdbtypes.BackupExpirationPolicy) error {
+	args := []string{
+		"expire",
+		"-d",
+		url,
+		"--delete_before_days",
+		fmt.Sprintf("%d", policy.DeleteBeforeDays),
+	}
+
+	if policy.MinRestorableDays != nil {
+		args = append(args, "--min_restorable_days", fmt.Sprintf("%d", *policy.MinRestorableDays))
+	}
+
+	_, err := client.runCommand(ctx, cliCommand{
+		binary:  "fdbbackup",
+		args:    args,
+		timeout: BackupExpirationTimeout,
+	})
+	return err
+}
+
+// DescribeBackup describes the data in the backup at the URL.
+func (client *cliAdminClient) DescribeBackup(ctx context.Context, url string) (*fdbtypes.FoundationDBBackupDescription, error) {
+	descriptionString, err := client.runCommand(ctx, cliCommand{
+		binary: "fdbbackup",
+		args: []string{
+			"describe",
+			"-d",
+			url,
+			"--version_timestamps",
+			"--json",
+		},
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	description := &fdbtypes.FoundationDBBackupDescription{}
+	descriptionString, err = internal.RemoveWarningsInJSON(descriptionString)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(descriptionString), description)
+	if err != nil {
+		return nil, err
+	}
+
+	return description, nil
+}
+
 // StartRestore starts a new restore.
-func (client *cliAdminClient) StartRestore(url string, keyRanges []fdbtypes.FoundationDBKeyRange) error {
+func (client *cliAdminClient) StartRestore(ctx context.Context, url string, keyRanges []fdbtypes.FoundationDBKeyRange, targetVersion *int64) error {
 	args := []string{
 		"start",
 		"-r",
 		url,
 	}
 
+	if targetVersion != nil {
+		args = append(args, "-v", strconv.FormatInt(*targetVersion, 10))
+	}
+
 	if keyRanges != nil {
 		keyRangeString := ""
 		for _, keyRange := range keyRanges {
@@ -552,7 +681,7 @@ func (client *cliAdminClient) StartRestore(url string, keyRanges []fdbtypes.Foun
 		}
 		args = append(args, "-k", keyRangeString)
 	}
-	_, err := client.runCommand(cliCommand{
+	_, err := client.runCommand(ctx, cliCommand{
 		binary: "fdbrestore",
 		args:   args,
 	})
@@ -560,8 +689,8 @@ func (client *cliAdminClient) StartRestore(url string, keyRanges []fdbtypes.Foun
 }
 
 // GetRestoreStatus gets the status of the current restore.
-func (client *cliAdminClient) GetRestoreStatus() (string, error) {
-	return client.runCommand(cliCommand{
+func (client *cliAdminClient) GetRestoreStatus(ctx context.Context) (string, error) {
+	return client.runCommand(ctx, cliCommand{
 		binary: "fdbrestore",
 		args: []string{
 			"status",
@@ -569,30 +698,187 @@ func (client *cliAdminClient) GetRestoreStatus() (string, error) {
 	})
 }
 
-// Close cleans up any pending resources.
-func (client *cliAdminClient) Close() error {
-	err := os.Remove(client.clusterFilePath)
+// runDRCommand executes a fdbdr command with this cluster as the destination
+// and the cluster with the provided connection string as the source.
+func (client *cliAdminClient) runDRCommand(ctx context.Context, sourceConnectionString string, args ...string) (string, error) {
+	sourceClusterFilePath, err := createClusterFile(sourceConnectionString)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	defer os.Remove(sourceClusterFilePath)
+
+	return client.runCommand(ctx, cliCommand{
+		binary: "fdbdr",
+		args:   append(args, "-s", sourceClusterFilePath),
+	})
+}
+
+// StartDR starts a new DR replication from the cluster with the provided
+// connection string into this cluster.
+func (client *cliAdminClient) StartDR(ctx context.Context, sourceConnectionString string, tag string) error {
+	_, err := client.runDRCommand(ctx, sourceConnectionString, "start", "-t", tag)
+	return err
+}
+
+// SwitchDR switches the DR replication from the cluster with the provided
+// connection string over to this cluster.
+func (client *cliAdminClient) SwitchDR(ctx context.Context, sourceConnectionString string, tag string) error {
+	_, err := client.runDRCommand(ctx, sourceConnectionString, "switch", "-t", tag)
+	return err
+}
+
+// GetDRStatus gets the status of the DR replication from the cluster with
+// the provided connection string into this cluster.
+func (client *cliAdminClient) GetDRStatus(ctx context.Context, sourceConnectionString string, tag string) (*fdbtypes.FoundationDBLiveDRStatus, error) {
+	output, err := client.runDRCommand(ctx, sourceConnectionString, "status", "-t", tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseDRStatusOutput(output)
 }
 
-func removeWarningsInJSON(jsonString string) (string, error) {
-	idx := strings.Index(jsonString, "{")
-	if idx == -1 {
-		return "", fmt.Errorf("the JSON string doesn't contain a starting '{'")
+// parseDRStatusOutput parses the output of the fdbdr status command.
+func parseDRStatusOutput(output string) (*fdbtypes.FoundationDBLiveDRStatus, error) {
+	status := &fdbtypes.FoundationDBLiveDRStatus{}
+	status.CompleteCopy = strings.Contains(output, "is a complete copy of the primary database")
+	status.Running = status.CompleteCopy || strings.Contains(output, "is NOT a complete copy of the primary database")
+
+	matches := drLagRegex.FindStringSubmatch(output)
+	if matches != nil {
+		secondsBehind, err := strconv.ParseFloat(matches[1], 64)
+		if err != nil {
+			return nil, err
+		}
+		status.SecondsBehind = secondsBehind
 	}
 
-	return strings.TrimSpace(jsonString[idx:]), nil
+	return status, nil
+}
+
+// Close cleans up any pending resources.
+func (client *cliAdminClient) Close() error {
+	// The cluster file is shared with the other clients for the cluster, and
+	// is removed by the cluster file cleanup once the cluster is deleted.
+	return nil
 }
 
 // GetCoordinatorSet gets the current coordinators from the status
-func (client *cliAdminClient) GetCoordinatorSet() (map[string]struct{}, error) {
-	status, err := getStatusFromDB(client.Cluster)
+func (client *cliAdminClient) GetCoordinatorSet(ctx context.Context) (map[string]struct{}, error) {
+	status, err := getStatusFromDB(ctx, client.Cluster)
 	if err != nil {
 		return nil, err
 	}
 
 	return internal.GetCoordinatorsFromStatus(status), nil
 }
+
+// RunCommand runs an arbitrary fdbcli command and returns its output.
+func (client *cliAdminClient) RunCommand(ctx context.Context, command string) (string, error) {
+	return client.runCommand(ctx, cliCommand{command: command})
+}
+
+// GetTagThrottles lists the tag throttles that are active in the database.
+func (client *cliAdminClient) GetTagThrottles(ctx context.Context) ([]fdbtypes.TagThrottleStatus, error) {
+	output, err := client.runCommand(ctx, cliCommand{command: "throttle list throttled 1000"})
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.ParseTagThrottles(output)
+}
+
+// ThrottleTag sets a manual throttle on a tag for the given duration.
+func (client *cliAdminClient) ThrottleTag(ctx context.Context, throttle fdbtypes.TagThrottle, duration time.Duration) error {
+	_, err := client.runCommand(ctx, cliCommand{command: internal.GetThrottleTagCommand(throttle, duration)})
+	return err
+}
+
+// UnthrottleTag removes the manual throttles on a tag.
+func (client *cliAdminClient) UnthrottleTag(ctx context.Context, tag string) error {
+	_, err := client.runCommand(ctx, cliCommand{command: fmt.Sprintf("throttle off manual tag %s", tag)})
+	return err
+}
+
+// GetTagQuotas reads the throughput quotas of the given tags.
+func (client *cliAdminClient) GetTagQuotas(ctx context.Context, tags []string) ([]fdbtypes.TagQuota, error) {
+	if len(tags) == 0 {
+		return []fdbtypes.TagQuota{}, nil
+	}
+
+	output, err := client.runCommand(ctx, cliCommand{command: internal.GetGetTagQuotasCommand(tags)})
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.ParseTagQuotas(tags, output)
+}
+
+// SetTagQuota sets the throughput quota of a tag.
+func (client *cliAdminClient) SetTagQuota(ctx context.Context, quota fdbtypes.TagQuota) error {
+	_, err := client.runCommand(ctx, cliCommand{command: internal.GetSetTagQuotaCommand(quota)})
+	return err
+}
+
+// ClearTagQuota removes the throughput quota of a tag.
+func (client *cliAdminClient) ClearTagQuota(ctx context.Context, tag string) error {
+	_, err := client.runCommand(ctx, cliCommand{command: fmt.Sprintf("quota clear %s", tag)})
+	return err
+}
+
+// GetKnobs reads the given knobs from the configuration database.
+func (client *cliAdminClient) GetKnobs(ctx context.Context, names []string) (map[string]string, error) {
+	if len(names) == 0 {
+		return map[string]string{}, nil
+	}
+
+	output, err := client.runCommand(ctx, cliCommand{command: internal.GetGetKnobsCommand(names)})
+	if err != nil {
+		return nil, err
+	}
+
+	return internal.ParseKnobs(output), nil
+}
+
+// SetKnobs sets the given knobs in the configuration database.
+func (client *cliAdminClient) SetKnobs(ctx context.Context, knobs map[string]string) error {
+	if len(knobs) == 0 {
+		return nil
+	}
+
+	_, err := client.runCommand(ctx, cliCommand{command: internal.GetSetKnobsCommand(knobs)})
+	return err
+}
+
+// ClearKnobs removes the given knobs from the configuration database.
+func (client *cliAdminClient) ClearKnobs(ctx context.Context, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	_, err := client.runCommand(ctx, cliCommand{command: internal.GetClearKnobsCommand(names)})
+	return err
+}
+
+// GetConsistencyCheckSuspended determines whether the consistency check is
+// suspended in the database.
+func (client *cliAdminClient) GetConsistencyCheckSuspended(ctx context.Context) (bool, error) {
+	output, err := client.runCommand(ctx, cliCommand{command: "consistencycheck"})
+	if err != nil {
+		return false, err
+	}
+
+	return internal.ParseConsistencyCheckSuspended(output)
+}
+
+// SetConsistencyCheckSuspended suspends or resumes the consistency check in
+// the database.
+func (client *cliAdminClient) SetConsistencyCheckSuspended(ctx context.Context, suspended bool) error {
+	command := "consistencycheck on"
+	if suspended {
+		command = "consistencycheck off"
+	}
+
+	_, err := client.runCommand(ctx, cliCommand{command: command})
+	return err
+}
@@ -21,8 +21,7 @@
 package fdbclient
 
 import (
-	"fmt"
-
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -96,55 +95,47 @@ var _ = Describe("admin_client_test", func() {
 					"10.1.56.56:4500": "Success",
 				}))
 			})
+
+			It("should handle IPv6 addresses in the output", func() {
+				output := "  2001:db8:0:0::1(Whole machine)  ---- Successfully excluded. It is now safe to remove this process from the cluster.\n" +
+					"  [2001:db8::2]:4500  ---- WARNING: Exclusion in progress! It is not safe to remove this process from the cluster\n" +
+					"  [2001:db8::3]:4500:tls  ---- WARNING: Missing from cluster! Be sure that you excluded the correct processes" +
+					" before removing them from the cluster!\n"
+				results := parseExclusionOutput(output)
+				Expect(results).To(Equal(map[string]string{
+					"2001:db8::1":            "Success",
+					"[2001:db8::2]:4500":     "In Progress",
+					"[2001:db8::3]:4500:tls": "Missing",
+				}))
+			})
 		})
 	})
 
-	When("Removing warnings in JSON", func() {
-		type testCase struct {
-			input       string
-			expected    string
-			expectedErr error
-		}
-
-		DescribeTable("Test remove warnings in JSON string",
-			func(tc testCase) {
-				result, err := removeWarningsInJSON(tc.input)
-				// We need the if statement to make ginkgo happy:
-				//   Refusing to compare <nil> to <nil>.
-				//   Be explicit and use BeNil() instead.
-				//   This is to avoid mistakes where both sides of an assertion are erroneously uninitialized.
-				// ¯\_(ツ)_/¯
-				if tc.expectedErr == nil {
-					Expect(err).To(BeNil())
-				} else {
-					Expect(err).To(Equal(tc.expectedErr))
-				}
-				Expect(result).To(Equal(tc.expected))
+	DescribeTable("parsing the DR status",
+		func(output string, expected fdbtypes.FoundationDBLiveDRStatus) {
+			status, err := parseDRStatusOutput(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*status).To(Equal(expected))
+		},
+		Entry("with a complete copy",
+			"The DR on tag `default' is a complete copy of the primary database.\n\nThe DR is 0.250000 seconds behind.\n",
+			fdbtypes.FoundationDBLiveDRStatus{
+				Running:       true,
+				CompleteCopy:  true,
+				SecondsBehind: 0.25,
 			},
-			Entry("Valid JSON without warning",
-				testCase{
-					input:       "{}",
-					expected:    "{}",
-					expectedErr: nil,
-				},
-			),
-			Entry("Valid JSON with warning",
-				testCase{
-					input: `
- # Warning Slow response
- 
- {}`,
-					expected:    "{}",
-					expectedErr: nil,
-				},
-			),
-			Entry("Invalid JSON",
-				testCase{
-					input:       "}",
-					expected:    "",
-					expectedErr: fmt.Errorf("the JSON string doesn't contain a starting '{'"),
-				},
-			),
-		)
-	})
+		),
+		Entry("with an incomplete copy",
+			"The DR on tag `default' is NOT a complete copy of the primary database.\n\nThe DR is 12.500000 seconds behind.\n",
+			fdbtypes.FoundationDBLiveDRStatus{
+				Running:       true,
+				CompleteCopy:  false,
+				SecondsBehind: 12.5,
+			},
+		),
+		Entry("without a running DR",
+			"No previous DRs found.\n",
+			fdbtypes.FoundationDBLiveDRStatus{},
+		),
+	)
 })
@@ -21,10 +21,14 @@
 package fdbclient
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
@@ -36,29 +40,40 @@ import (
 
 var log = logf.Log.WithName("fdbclient")
 
-const (
-	defaultTransactionTimeout int64 = 5000
-)
-
 // DefaultCLITimeout is the default timeout for CLI commands.
 var DefaultCLITimeout = 10
 
-// getFDBDatabase opens an FDB database. The result will be cached for
-// subsequent calls, based on the cluster namespace and name.
-func getFDBDatabase(cluster *fdbtypes.FoundationDBCluster) (fdb.Database, error) {
-	clusterFile, err := os.CreateTemp("", "")
-	if err != nil {
-		return fdb.Database{}, err
-	}
+// BackupExpirationTimeout is the timeout for expiring old data from a backup
+// in seconds. Expiring data can take much longer than other CLI commands,
+// since it deletes the files from the backup store.
+var BackupExpirationTimeout = 3600
 
-	defer clusterFile.Close()
-	clusterFilePath := clusterFile.Name()
+// DefaultTransactionTimeout is the default timeout for transactions in
+// milliseconds.
+var DefaultTransactionTimeout int64 = 5000
 
-	_, err = clusterFile.WriteString(cluster.Status.ConnectionString)
+// ClusterFileDir is the directory where the operator writes the cluster files
+// for the clusters it manages.
+var ClusterFileDir = filepath.Join(os.TempDir(), "fdb")
+
+// ensureClusterFile writes the current connection string of the cluster into
+// the cluster file for the cluster, and returns the path of that file.
+func ensureClusterFile(cluster *fdbtypes.FoundationDBCluster) (string, error) {
+	clusterFilePath := internal.GetClusterFilePath(ClusterFileDir, cluster)
+	err := internal.WriteClusterFile(clusterFilePath, cluster.Status.ConnectionString)
 	if err != nil {
-		return fdb.Database{}, err
+		return "", err
 	}
-	err = clusterFile.Close()
+
+	return clusterFilePath, nil
+}
+
+// getFDBDatabase opens an FDB database. The result will be cached for
+// subsequent calls, based on the cluster namespace and name.
+func getFDBDatabase(cluster *fdbtypes.FoundationDBCluster) (fdb.Database, error) {
+	// The cluster file is rewritten when the connection string changes, so
+	// the cached database picks up the new coordinators.
+	clusterFilePath, err := ensureClusterFile(cluster)
 	if err != nil {
 		return fdb.Database{}, err
 	}
@@ -68,7 +83,7 @@ func getFDBDatabase(cluster *fdbtypes.FoundationDBCluster) (fdb.Database, error)
 		return fdb.Database{}, err
 	}
 
-	err = database.Options().SetTransactionTimeout(defaultTransactionTimeout)
+	err = database.Options().SetTransactionTimeout(DefaultTransactionTimeout)
 	if err != nil {
 		return fdb.Database{}, err
 	}
@@ -76,8 +91,56 @@ func getFDBDatabase(cluster *fdbtypes.FoundationDBCluster) (fdb.Database, error)
 	return database, nil
 }
 
+// transact runs a transaction against the database, limiting it to the given
+// timeout in milliseconds or the deadline of the context, whichever is
+// shorter. The transaction is cancelled once the context is done.
+func transact(ctx context.Context, database fdb.Database, timeout int64, f func(fdb.Transaction) (interface{}, error)) (interface{}, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline).Milliseconds()
+		if remaining <= 0 {
+			return nil, context.DeadlineExceeded
+		}
+
+		if remaining < timeout {
+			timeout = remaining
+		}
+	}
+
+	result, err := database.Transact(func(transaction fdb.Transaction) (interface{}, error) {
+		err := ctx.Err()
+		if err != nil {
+			return nil, err
+		}
+
+		err = transaction.Options().SetTimeout(timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				transaction.Cancel()
+			case <-done:
+			}
+		}()
+
+		return f(transaction)
+	})
+
+	// Report the cancellation of the context rather than the error of the
+	// cancelled transaction.
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return result, err
+}
+
 // getStatusFromDB gets the database's status directly from the system key
-func getStatusFromDB(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error) {
+func getStatusFromDB(ctx context.Context, cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error) {
 	log.Info("Fetch status from FDB", "namespace", cluster.Namespace, "cluster", cluster.Name)
 	statusKey := "\xff\xff/status/json"
 
@@ -86,16 +149,12 @@ func getStatusFromDB(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.Foundatio
 		return nil, err
 	}
 
-	result, err := database.Transact(func(transaction fdb.Transaction) (interface{}, error) {
+	// Wait default timeout seconds to receive status for larger clusters.
+	result, err := transact(ctx, database, int64(DefaultCLITimeout*1000), func(transaction fdb.Transaction) (interface{}, error) {
 		err := transaction.Options().SetAccessSystemKeys()
 		if err != nil {
 			return nil, err
 		}
-		// Wait default timeout seconds to receive status for larger clusters.
-		err = transaction.Options().SetTimeout(int64(DefaultCLITimeout * 1000))
-		if err != nil {
-			return nil, err
-		}
 
 		statusBytes := transaction.Get(fdb.Key(statusKey)).MustGet()
 		if len(statusBytes) == 0 {
@@ -123,6 +182,34 @@ func getStatusFromDB(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.Foundatio
 	return status, err
 }
 
+// getConnectionStringFromDB reads the connection string that the database
+// stores for its coordinators.
+func getConnectionStringFromDB(ctx context.Context, cluster *fdbtypes.FoundationDBCluster) (string, error) {
+	database, err := getFDBDatabase(cluster)
+	if err != nil {
+		return "", err
+	}
+
+	result, err := transact(ctx, database, DefaultTransactionTimeout, func(transaction fdb.Transaction) (interface{}, error) {
+		err := transaction.Options().SetAccessSystemKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		return transaction.Get(fdb.Key("\xff/coordinators")).Get()
+	})
+	if err != nil {
+		return "", err
+	}
+
+	connectionStringBytes, ok := result.([]byte)
+	if !ok {
+		return "", fmt.Errorf("could not cast result into byte slice")
+	}
+
+	return string(connectionStringBytes), nil
+}
+
 type realDatabaseClientProvider struct{}
 
 // GetLockClient generates a client for working with locks through the database.
@@ -0,0 +1,180 @@
+/*
+ * network_options.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fdbclient
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/apple/foundationdb/bindings/go/src/fdb"
+)
+
+// clientLibraryRegex matches the names of the client libraries in the
+// external client directory, e.g. libfdb_c_6.3.10.so.
+var clientLibraryRegex = regexp.MustCompile(`^libfdb_c_(\d+\.\d+\.\d+)\.so$`)
+
+// NetworkOptions defines the client network options that the operator uses
+// for its own connections to the databases.
+type NetworkOptions struct {
+	// TLSCertificateFile defines the path to the client certificate.
+	TLSCertificateFile string
+
+	// TLSKeyFile defines the path to the key for the client certificate.
+	TLSKeyFile string
+
+	// TLSCAFile defines the path to the CA bundle that is used to verify
+	// the server certificates.
+	TLSCAFile string
+
+	// TLSVerifyPeers defines the rules for verifying the server
+	// certificates.
+	TLSVerifyPeers string
+
+	// TraceDir defines the directory for the client trace logs.
+	TraceDir string
+
+	// TraceFormat defines the format of the client trace logs.
+	TraceFormat string
+
+	// Knobs defines the client knobs, in the form name=value.
+	Knobs []string
+
+	// ExternalClientDirectory defines the directory that contains the client
+	// libraries for the other versions of FoundationDB.
+	ExternalClientDirectory string
+}
+
+// getEnvironment returns the environment variables for the network options
+// that are set.
+func (options NetworkOptions) getEnvironment() map[string]string {
+	env := map[string]string{}
+	addVariable := func(name string, value string) {
+		if value != "" {
+			env[name] = value
+		}
+	}
+
+	addVariable("FDB_TLS_CERTIFICATE_FILE", options.TLSCertificateFile)
+	addVariable("FDB_TLS_KEY_FILE", options.TLSKeyFile)
+	addVariable("FDB_TLS_CA_FILE", options.TLSCAFile)
+	addVariable("FDB_TLS_VERIFY_PEERS", options.TLSVerifyPeers)
+	addVariable("FDB_NETWORK_OPTION_TRACE_ENABLE", options.TraceDir)
+	addVariable("FDB_NETWORK_OPTION_TRACE_FORMAT", options.TraceFormat)
+	addVariable("FDB_NETWORK_OPTION_EXTERNAL_CLIENT_DIRECTORY", options.ExternalClientDirectory)
+
+	return env
+}
+
+// validateKnob checks that a knob has the form name=value.
+func validateKnob(knob string) error {
+	components := strings.SplitN(knob, "=", 2)
+	if len(components) != 2 || strings.TrimSpace(components[0]) == "" {
+		return fmt.Errorf("invalid knob %s, expected name=value", knob)
+	}
+
+	return nil
+}
+
+// SetupNetworkOptions applies the network options. This must be called
+// before the first database is opened, because the client only reads the
+// network options when it starts the network.
+func SetupNetworkOptions(options NetworkOptions) error {
+	// The TLS and trace options are passed through the environment, so the
+	// fdbcli commands that the operator runs use the same options as the
+	// client library.
+	for name, value := range options.getEnvironment() {
+		err := os.Setenv(name, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, knob := range options.Knobs {
+		err := validateKnob(knob)
+		if err != nil {
+			return err
+		}
+
+		err = fdb.Options().SetKnob(knob)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetClientLibraryVersions returns the versions of the client libraries in
+// the directory, with the newest version first. The multi-version client
+// loads every library in the directory, but only libraries named
+// libfdb_c_<version>.so are reported here.
+//
+// The operator runs fdbcli from the <major>.<minor> subdirectory of the
+// binary directory, so a version is only returned if that subdirectory has
+// an fdbcli binary. If the binary directory is empty, all versions are
+// returned.
+func GetClientLibraryVersions(directory string, binaryDirectory string) ([]string, error) {
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]fdbtypes.FdbVersion, 0, len(entries))
+	for _, entry := range entries {
+		match := clientLibraryRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, err := fdbtypes.ParseFdbVersion(match[1])
+		if err != nil {
+			return nil, err
+		}
+
+		if binaryDirectory != "" {
+			_, err = os.Stat(path.Join(binaryDirectory, fmt.Sprintf("%d.%d", version.Major, version.Minor), "fdbcli"))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return !versions[j].IsAtLeast(versions[i])
+	})
+
+	result := make([]string, 0, len(versions))
+	for _, version := range versions {
+		result = append(result, version.String())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,116 @@
+/*
+ * network_options_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fdbclient
+
+import (
+	"os"
+	"path"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("network_options", func() {
+	It("should only set the environment for the options that are set", func() {
+		options := NetworkOptions{
+			TLSCertificateFile: "/tmp/fdb-certs/tls.crt",
+			TLSKeyFile:         "/tmp/fdb-certs/tls.key",
+			TraceDir:           "/var/log/fdb",
+		}
+
+		Expect(options.getEnvironment()).To(Equal(map[string]string{
+			"FDB_TLS_CERTIFICATE_FILE":        "/tmp/fdb-certs/tls.crt",
+			"FDB_TLS_KEY_FILE":                "/tmp/fdb-certs/tls.key",
+			"FDB_NETWORK_OPTION_TRACE_ENABLE": "/var/log/fdb",
+		}))
+	})
+
+	It("should not set any environment without options", func() {
+		Expect(NetworkOptions{}.getEnvironment()).To(BeEmpty())
+	})
+
+	When("listing the client library versions", func() {
+		var directory string
+
+		BeforeEach(func() {
+			var err error
+			directory, err = os.MkdirTemp("", "fdb-client-libraries")
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, name := range []string{"libfdb_c_6.2.30.so", "libfdb_c_7.0.0.so", "libfdb_c_6.10.1.so", "libfdb_c_6.3.10.so", "libfdb_c_6.1.so", "libfdb_c.so"} {
+				err = os.WriteFile(path.Join(directory, name), []byte{}, 0600)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			// The binaries use the same layout as in the operator image,
+			// with one subdirectory for each minor version. There are no
+			// binaries for 6.3.
+			for _, version := range []string{"6.2", "6.10", "7.0"} {
+				err = os.MkdirAll(path.Join(directory, version), 0700)
+				Expect(err).NotTo(HaveOccurred())
+				err = os.WriteFile(path.Join(directory, version, "fdbcli"), []byte{}, 0700)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			// The init containers put the binaries for a full version into
+			// a directory with the version as its name.
+			err = os.MkdirAll(path.Join(directory, "6.3.10", "bin", "6.3.10"), 0700)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(directory)).To(Succeed())
+		})
+
+		It("should return the versions of the versioned libraries that have binaries with the newest version first", func() {
+			versions, err := GetClientLibraryVersions(directory, directory)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(Equal([]string{"7.0.0", "6.10.1", "6.2.30"}))
+		})
+
+		It("should return all versioned libraries without a binary directory", func() {
+			versions, err := GetClientLibraryVersions(directory, "")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(Equal([]string{"7.0.0", "6.10.1", "6.3.10", "6.2.30"}))
+		})
+
+		It("should return an error for a missing directory", func() {
+			_, err := GetClientLibraryVersions(path.Join(directory, "missing"), directory)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	DescribeTable("validating a knob",
+		func(knob string, valid bool) {
+			err := validateKnob(knob)
+			if valid {
+				Expect(err).NotTo(HaveOccurred())
+			} else {
+				Expect(err).To(HaveOccurred())
+			}
+		},
+		Entry("with a name and a value", "min_trace_severity=10", true),
+		Entry("with an empty value", "min_trace_severity=", true),
+		Entry("without a value", "min_trace_severity", false),
+		Entry("without a name", "=10", false),
+	)
+})
@@ -0,0 +1,146 @@
+/*
+ * apply_helper.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	ctx "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// FieldOwner provides the field manager name that the operator uses when it
+// applies changes to resources.
+const FieldOwner = "fdb-kubernetes-operator"
+
+// ApplyObject creates or updates an object through a server-side apply. The
+// operator will only take ownership of the fields that are set in the object,
+// so changes that other controllers make to other fields are preserved.
+func ApplyObject(r client.Client, context ctx.Context, object client.Object) error {
+	gvk, err := apiutil.GVKForObject(object, r.Scheme())
+	if err != nil {
+		return err
+	}
+
+	object.GetObjectKind().SetGroupVersionKind(gvk)
+	object.SetResourceVersion("")
+	object.SetManagedFields(nil)
+
+	return r.Patch(context, object, client.Apply, client.FieldOwner(FieldOwner), client.ForceOwnership)
+}
+
+// PatchMetadata updates the labels and annotations of an object through a
+// JSON merge patch. The patch only contains the labels and annotations that
+// differ from the current object, so labels, annotations and other fields
+// that other controllers manage are preserved. The patch fails with a
+// conflict if the object has changed since it was read.
+func PatchMetadata(r client.Client, context ctx.Context, object client.Object) error {
+	current, ok := reflect.New(reflect.TypeOf(object).Elem()).Interface().(client.Object)
+	if !ok {
+		return fmt.Errorf("could not create an object of type %T", object)
+	}
+
+	err := r.Get(context, client.ObjectKeyFromObject(object), current)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFromWithOptions(current.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	current.SetResourceVersion(object.GetResourceVersion())
+	current.SetLabels(object.GetLabels())
+	current.SetAnnotations(object.GetAnnotations())
+
+	err = r.Patch(context, current, patch)
+	if err != nil {
+		return err
+	}
+
+	object.SetResourceVersion(current.GetResourceVersion())
+	return nil
+}
+
+// RemoveAnnotation removes an annotation from an object through a JSON merge
+// patch, so no other fields of the object are changed. This also removes the
+// annotation from the passed object.
+func RemoveAnnotation(r client.Client, context ctx.Context, object client.Object, key string) error {
+	patched, ok := object.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("could not copy object %s/%s", object.GetNamespace(), object.GetName())
+	}
+
+	patchData, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{
+				key: nil,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = r.Patch(context, patched, client.RawPatch(types.MergePatchType, patchData))
+	if err != nil {
+		return err
+	}
+
+	annotations := object.GetAnnotations()
+	delete(annotations, key)
+	object.SetAnnotations(annotations)
+	return nil
+}
+
+// UpdateFinalizer adds or removes a finalizer on an object through a JSON
+// merge patch, so no other fields of the object are changed. The patch is
+// based on the current finalizers of the object and fails with a conflict if
+// they change concurrently, so finalizers that other controllers manage are
+// preserved. This also updates the finalizers of the passed object.
+func UpdateFinalizer(r client.Client, context ctx.Context, object client.Object, finalizer string, present bool) error {
+	current, ok := reflect.New(reflect.TypeOf(object).Elem()).Interface().(client.Object)
+	if !ok {
+		return fmt.Errorf("could not create an object of type %T", object)
+	}
+
+	err := r.Get(context, client.ObjectKeyFromObject(object), current)
+	if err != nil {
+		return err
+	}
+
+	patch := client.MergeFromWithOptions(current.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	if present {
+		controllerutil.AddFinalizer(current, finalizer)
+	} else {
+		controllerutil.RemoveFinalizer(current, finalizer)
+	}
+
+	err = r.Patch(context, current, patch)
+	if err != nil {
+		return err
+	}
+
+	object.SetFinalizers(current.GetFinalizers())
+	return nil
+}
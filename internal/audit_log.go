@@ -0,0 +1,117 @@
+/*
+ * audit_log.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AuditLogKey defines the key name for the entries in the audit log
+// ConfigMap.
+const AuditLogKey = "audit.log"
+
+// AuditLogEntry describes an action that the operator took on a cluster.
+type AuditLogEntry struct {
+	// Timestamp provides the time of the action in RFC 3339 format.
+	Timestamp string `json:"timestamp"`
+
+	// Generation provides the generation of the cluster spec that the
+	// operator was reconciling.
+	Generation int64 `json:"generation"`
+
+	// Actor identifies the operator instance that took the action.
+	Actor string `json:"actor"`
+
+	// Action provides the reason of the event that the operator emitted for
+	// the action, or the kind of change that the operator made to a
+	// resource.
+	Action string `json:"action"`
+
+	// Message provides the details of the action.
+	Message string `json:"message,omitempty"`
+
+	// Outcome provides the result of the action, which is either
+	// AuditLogOutcomeSucceeded or AuditLogOutcomeFailed.
+	Outcome string `json:"outcome,omitempty"`
+
+	// Error provides the error of a failed action.
+	Error string `json:"error,omitempty"`
+}
+
+const (
+	// AuditLogOutcomeSucceeded describes an action that completed
+	// successfully.
+	AuditLogOutcomeSucceeded = "Succeeded"
+
+	// AuditLogOutcomeFailed describes an action that failed.
+	AuditLogOutcomeFailed = "Failed"
+)
+
+// GetAuditLogConfigMapName returns the name of the config map with the audit
+// log for a cluster.
+func GetAuditLogConfigMapName(cluster *v1beta1.FoundationDBCluster) string {
+	return fmt.Sprintf("%s-audit-log", cluster.Name)
+}
+
+// GetAuditLogConfigMap builds an empty config map for the audit log of a
+// cluster.
+func GetAuditLogConfigMap(cluster *v1beta1.FoundationDBCluster) *corev1.ConfigMap {
+	metadata := GetObjectMetadata(cluster, nil, "", "")
+	metadata.Name = GetAuditLogConfigMapName(cluster)
+	metadata.OwnerReferences = BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metadata,
+		Data:       map[string]string{AuditLogKey: ""},
+	}
+}
+
+// AppendAuditLogEntry adds an entry to the audit log in the config map. The
+// entries are stored as one JSON object per line, and the oldest entries are
+// removed once there are more than maxEntries.
+func AppendAuditLogEntry(configMap *corev1.ConfigMap, entry AuditLogEntry, maxEntries int) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	if configMap.Data[AuditLogKey] != "" {
+		lines = strings.Split(strings.TrimSuffix(configMap.Data[AuditLogKey], "\n"), "\n")
+	}
+
+	lines = append(lines, string(line))
+	if len(lines) > maxEntries {
+		lines = lines[len(lines)-maxEntries:]
+	}
+
+	if configMap.Data == nil {
+		configMap.Data = make(map[string]string, 1)
+	}
+	configMap.Data[AuditLogKey] = strings.Join(lines, "\n") + "\n"
+
+	return nil
+}
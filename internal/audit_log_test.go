@@ -0,0 +1,59 @@
+/*
+ * audit_log_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("audit_log", func() {
+	var configMap *corev1.ConfigMap
+
+	BeforeEach(func() {
+		configMap = GetAuditLogConfigMap(CreateDefaultCluster())
+	})
+
+	It("should build the config map", func() {
+		Expect(configMap.Name).To(Equal("operator-test-1-audit-log"))
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+		Expect(configMap.Data).To(Equal(map[string]string{AuditLogKey: ""}))
+	})
+
+	It("should append the entries", func() {
+		Expect(AppendAuditLogEntry(configMap, AuditLogEntry{Timestamp: "2021-01-01T00:00:00Z", Generation: 1, Actor: "operator-1", Action: "ExcludingProcesses", Message: "Excluding [1.1.1.1:4501]"}, 10)).To(Succeed())
+		Expect(AppendAuditLogEntry(configMap, AuditLogEntry{Timestamp: "2021-01-01T00:01:00Z", Generation: 2, Actor: "operator-1", Action: "UpdatingConfigMap"}, 10)).To(Succeed())
+		Expect(configMap.Data[AuditLogKey]).To(Equal(`{"timestamp":"2021-01-01T00:00:00Z","generation":1,"actor":"operator-1","action":"ExcludingProcesses","message":"Excluding [1.1.1.1:4501]"}
+{"timestamp":"2021-01-01T00:01:00Z","generation":2,"actor":"operator-1","action":"UpdatingConfigMap"}
+`))
+	})
+
+	It("should remove the oldest entries", func() {
+		for _, action := range []string{"AddingProcesses", "ExcludingProcesses", "RemovingProcesses"} {
+			Expect(AppendAuditLogEntry(configMap, AuditLogEntry{Action: action}, 2)).To(Succeed())
+		}
+
+		Expect(configMap.Data[AuditLogKey]).To(Equal(`{"timestamp":"","generation":0,"actor":"","action":"ExcludingProcesses"}
+{"timestamp":"","generation":0,"actor":"","action":"RemovingProcesses"}
+`))
+	})
+})
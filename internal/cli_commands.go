@@ -0,0 +1,294 @@
+/*
+ * cli_commands.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// GetExcludeCommand builds the fdbcli command to exclude the given addresses.
+// This will use a non-blocking exclude if the cluster is configured for it.
+func GetExcludeCommand(cluster *fdbtypes.FoundationDBCluster, addresses []fdbtypes.ProcessAddress) (string, error) {
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return "", err
+	}
+
+	if version.HasNonBlockingExcludes(cluster.GetUseNonBlockingExcludes()) {
+		return fmt.Sprintf("exclude no_wait %s", fdbtypes.ProcessAddressesString(addresses, " ")), nil
+	}
+
+	return fmt.Sprintf("exclude %s", fdbtypes.ProcessAddressesString(addresses, " ")), nil
+}
+
+// GetExcludeLocalitiesCommand builds the fdbcli command to exclude all
+// processes of the given localities. This always uses a non-blocking exclude,
+// since evacuating a whole locality can take much longer than a single
+// fdbcli command is allowed to run.
+func GetExcludeLocalitiesCommand(cluster *fdbtypes.FoundationDBCluster, localities []fdbtypes.LocalityExclusion) (string, error) {
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return "", err
+	}
+
+	if !version.SupportsLocalityBasedExclusions() {
+		return "", fmt.Errorf("version %s does not support locality-based exclusions", version)
+	}
+
+	return fmt.Sprintf("exclude no_wait %s", getLocalitiesString(localities)), nil
+}
+
+// GetIncludeLocalitiesCommand builds the fdbcli command to include all
+// processes of the given localities.
+func GetIncludeLocalitiesCommand(localities []fdbtypes.LocalityExclusion) string {
+	return fmt.Sprintf("include %s", getLocalitiesString(localities))
+}
+
+// getLocalitiesString formats the localities in the format that fdbcli uses.
+func getLocalitiesString(localities []fdbtypes.LocalityExclusion) string {
+	localityStrings := make([]string, 0, len(localities))
+	for _, locality := range localities {
+		localityStrings = append(localityStrings, locality.String())
+	}
+
+	return strings.Join(localityStrings, " ")
+}
+
+// RemoveWarningsInJSON removes any warnings that fdbcli or fdbbackup print
+// before the JSON output.
+func RemoveWarningsInJSON(jsonString string) (string, error) {
+	idx := strings.Index(jsonString, "{")
+	if idx == -1 {
+		return "", fmt.Errorf("the JSON string doesn't contain a starting '{'")
+	}
+
+	return strings.TrimSpace(jsonString[idx:]), nil
+}
+
+// GetThrottleTagCommand builds the fdbcli command to throttle a tag for the
+// given duration.
+func GetThrottleTagCommand(throttle fdbtypes.TagThrottle, duration time.Duration) string {
+	return fmt.Sprintf("throttle on tag %s %d %ds %s", throttle.Tag, throttle.Rate, int(duration.Seconds()), throttle.GetPriority())
+}
+
+// ParseTagThrottles parses the output of the throttle list command in fdbcli.
+func ParseTagThrottles(output string) ([]fdbtypes.TagThrottleStatus, error) {
+	throttles := make([]fdbtypes.TagThrottleStatus, 0)
+	for _, line := range strings.Split(output, "\n") {
+		columns := strings.Split(line, "|")
+		// Depending on the version the output contains a column for the
+		// reason of the throttle, so the tag is always read from the last
+		// column.
+		if len(columns) < 5 {
+			continue
+		}
+
+		rate, err := strconv.Atoi(strings.TrimSpace(columns[0]))
+		if err != nil {
+			// This is the header of the table.
+			continue
+		}
+
+		expiration, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(columns[1]), "s"))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse expiration of tag throttle: %s", line)
+		}
+
+		throttles = append(throttles, fdbtypes.TagThrottleStatus{
+			TagThrottle: fdbtypes.TagThrottle{
+				Tag:      strings.TrimSpace(columns[len(columns)-1]),
+				Rate:     rate,
+				Priority: fdbtypes.TagThrottlePriority(strings.TrimSpace(columns[2])),
+			},
+			ExpirationSeconds: expiration,
+			Manual:            strings.TrimSpace(columns[3]) == "manual",
+		})
+	}
+
+	return throttles, nil
+}
+
+// GetGetTagQuotasCommand builds the fdbcli command to read the reserved and
+// total throughput quotas of the given tags.
+func GetGetTagQuotasCommand(tags []string) string {
+	commands := make([]string, 0, 2*len(tags))
+	for _, tag := range tags {
+		commands = append(commands,
+			fmt.Sprintf("quota get %s reserved_throughput", tag),
+			fmt.Sprintf("quota get %s total_throughput", tag),
+		)
+	}
+
+	return strings.Join(commands, "; ")
+}
+
+// emptyTagQuotaValue is the output of the quota get command in fdbcli for a
+// tag without a quota.
+const emptyTagQuotaValue = "<empty>"
+
+// ParseTagQuotas parses the output of the quota get commands built by
+// GetGetTagQuotasCommand. Tags without a quota are not part of the result.
+func ParseTagQuotas(tags []string, output string) ([]fdbtypes.TagQuota, error) {
+	values := make([]string, 0, 2*len(tags))
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		values = append(values, line)
+	}
+
+	if len(values) != 2*len(tags) {
+		return nil, fmt.Errorf("could not parse tag quotas: expected %d values, got output %q", 2*len(tags), output)
+	}
+
+	quotas := make([]fdbtypes.TagQuota, 0, len(tags))
+	for index, tag := range tags {
+		reserved, total := values[2*index], values[2*index+1]
+		if reserved == emptyTagQuotaValue && total == emptyTagQuotaValue {
+			continue
+		}
+
+		quota := fdbtypes.TagQuota{Tag: tag}
+		for _, value := range []struct {
+			raw    string
+			target *int64
+		}{{reserved, &quota.ReservedThroughput}, {total, &quota.TotalThroughput}} {
+			if value.raw == emptyTagQuotaValue {
+				continue
+			}
+
+			parsed, err := strconv.ParseInt(value.raw, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse quota of tag %s: %s", tag, value.raw)
+			}
+			*value.target = parsed
+		}
+
+		quotas = append(quotas, quota)
+	}
+
+	return quotas, nil
+}
+
+// GetSetTagQuotaCommand builds the fdbcli command to set the quota of a tag.
+// The total throughput is set first, so the reserved throughput never
+// exceeds it.
+func GetSetTagQuotaCommand(quota fdbtypes.TagQuota) string {
+	return fmt.Sprintf("quota set %s total_throughput %d; quota set %s reserved_throughput %d",
+		quota.Tag, quota.TotalThroughput, quota.Tag, quota.ReservedThroughput)
+}
+
+// consistencyCheckStatePrefix is the prefix of the line in the output of the
+// consistencycheck command that reports whether the check is suspended.
+const consistencyCheckStatePrefix = "ConsistencyCheckIsSuspended:"
+
+// ParseConsistencyCheckSuspended parses the output of the consistencycheck
+// command in fdbcli to determine whether the consistency check is suspended.
+func ParseConsistencyCheckSuspended(output string) (bool, error) {
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, consistencyCheckStatePrefix) {
+			continue
+		}
+
+		suspended, err := strconv.ParseBool(strings.TrimSpace(strings.TrimPrefix(line, consistencyCheckStatePrefix)))
+		if err != nil {
+			return false, fmt.Errorf("could not parse consistency check state: %s", line)
+		}
+
+		return suspended, nil
+	}
+
+	return false, fmt.Errorf("could not find consistency check state in output: %s", output)
+}
+
+// GetSetKnobsCommand builds the fdbcli command to set the knobs in the
+// configuration database. All knobs are set in a single configuration
+// transaction, so the processes pick up the new values at the same time.
+func GetSetKnobsCommand(knobs map[string]string) string {
+	names := make([]string, 0, len(knobs))
+	for name := range knobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	commands := make([]string, 0, len(names)+2)
+	commands = append(commands, "begin")
+	for _, name := range names {
+		commands = append(commands, fmt.Sprintf("setknob %s %s", name, knobs[name]))
+	}
+	commands = append(commands, "commit")
+
+	return strings.Join(commands, "; ")
+}
+
+// GetClearKnobsCommand builds the fdbcli command to clear the knobs from the
+// configuration database.
+func GetClearKnobsCommand(names []string) string {
+	commands := make([]string, 0, len(names)+2)
+	commands = append(commands, "begin")
+	for _, name := range names {
+		commands = append(commands, fmt.Sprintf("clearknob %s", name))
+	}
+	commands = append(commands, "commit")
+
+	return strings.Join(commands, "; ")
+}
+
+// GetGetKnobsCommand builds the fdbcli command to read the knobs from the
+// configuration database.
+func GetGetKnobsCommand(names []string) string {
+	commands := make([]string, 0, len(names))
+	for _, name := range names {
+		commands = append(commands, fmt.Sprintf("getknob %s", name))
+	}
+
+	return strings.Join(commands, "; ")
+}
+
+// knobValueRegex matches the lines in the output of the getknob command in
+// fdbcli for knobs that are set.
+var knobValueRegex = regexp.MustCompile("^`([^']+)' is `(.*)'$")
+
+// ParseKnobs parses the output of the getknob commands in fdbcli. Knobs that
+// are not set in the configuration database are not part of the result.
+func ParseKnobs(output string) map[string]string {
+	knobs := make(map[string]string)
+	for _, line := range strings.Split(output, "\n") {
+		components := knobValueRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if components == nil {
+			continue
+		}
+
+		knobs[components[1]] = components[2]
+	}
+
+	return knobs
+}
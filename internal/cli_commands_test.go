@@ -0,0 +1,263 @@
+/*
+ * cli_commands_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cli_commands", func() {
+	When("building the exclude command", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var addresses []fdbtypes.ProcessAddress
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+			addresses = []fdbtypes.ProcessAddress{
+				{IPAddress: net.ParseIP("1.1.1.1")},
+				{IPAddress: net.ParseIP("1.1.1.2")},
+			}
+		})
+
+		It("should use a blocking exclude by default", func() {
+			command, err := GetExcludeCommand(cluster, addresses)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal("exclude 1.1.1.1 1.1.1.2"))
+		})
+
+		When("non-blocking excludes are enabled", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+				useNonBlockingExcludes := true
+				cluster.Spec.AutomationOptions.UseNonBlockingExcludes = &useNonBlockingExcludes
+			})
+
+			It("should use a non-blocking exclude", func() {
+				command, err := GetExcludeCommand(cluster, addresses)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(command).To(Equal("exclude no_wait 1.1.1.1 1.1.1.2"))
+			})
+
+			When("the version does not support non-blocking excludes", func() {
+				BeforeEach(func() {
+					cluster.Spec.Version = fdbtypes.Versions.Default.String()
+				})
+
+				It("should use a blocking exclude", func() {
+					command, err := GetExcludeCommand(cluster, addresses)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(command).To(Equal("exclude 1.1.1.1 1.1.1.2"))
+				})
+			})
+		})
+	})
+
+	When("building the locality exclusion commands", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var localities []fdbtypes.LocalityExclusion
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+			cluster.Spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+			localities = []fdbtypes.LocalityExclusion{
+				{Key: fdbtypes.FDBLocalityZoneIDKey, Value: "zone-a"},
+				{Key: fdbtypes.FDBLocalityDCIDKey, Value: "dc1"},
+			}
+		})
+
+		It("should use a non-blocking exclude", func() {
+			command, err := GetExcludeLocalitiesCommand(cluster, localities)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal("exclude no_wait locality_zoneid:zone-a locality_dcid:dc1"))
+		})
+
+		It("should include the localities", func() {
+			Expect(GetIncludeLocalitiesCommand(localities)).To(Equal("include locality_zoneid:zone-a locality_dcid:dc1"))
+		})
+
+		When("the version does not support locality-based exclusions", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.Default.String()
+			})
+
+			It("should return an error", func() {
+				_, err := GetExcludeLocalitiesCommand(cluster, localities)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	When("Removing warnings in JSON", func() {
+		type testCase struct {
+			input       string
+			expected    string
+			expectedErr error
+		}
+
+		DescribeTable("Test remove warnings in JSON string",
+			func(tc testCase) {
+				result, err := RemoveWarningsInJSON(tc.input)
+				// We need the if statement to make ginkgo happy:
+				//   Refusing to compare <nil> to <nil>.
+				//   Be explicit and use BeNil() instead.
+				//   This is to avoid mistakes where both sides of an assertion are erroneously uninitialized.
+				// ¯\_(ツ)_/¯
+				if tc.expectedErr == nil {
+					Expect(err).To(BeNil())
+				} else {
+					Expect(err).To(Equal(tc.expectedErr))
+				}
+				Expect(result).To(Equal(tc.expected))
+			},
+			Entry("Valid JSON without warning",
+				testCase{
+					input:       "{}",
+					expected:    "{}",
+					expectedErr: nil,
+				},
+			),
+			Entry("Valid JSON with warning",
+				testCase{
+					input: `
+ # Warning Slow response
+ 
+ {}`,
+					expected:    "{}",
+					expectedErr: nil,
+				},
+			),
+			Entry("Invalid JSON",
+				testCase{
+					input:       "}",
+					expected:    "",
+					expectedErr: fmt.Errorf("the JSON string doesn't contain a starting '{'"),
+				},
+			),
+		)
+	})
+
+	When("parsing the tag throttles", func() {
+		It("should parse the throttled tags", func() {
+			output := `Throttled tags:
+
+  Rate (txn/s) | Expiration (s) | Priority  | Type   | Reason     |Tag
+ --------------+----------------+-----------+--------+------------+------
+           100 |          3581s |   default | manual |     manual |noisy
+            50 |           120s |     batch |   auto |  busy_read |reader
+`
+			throttles, err := ParseTagThrottles(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(throttles).To(Equal([]fdbtypes.TagThrottleStatus{
+				{
+					TagThrottle:       fdbtypes.TagThrottle{Tag: "noisy", Rate: 100, Priority: fdbtypes.TagThrottlePriorityDefault},
+					ExpirationSeconds: 3581,
+					Manual:            true,
+				},
+				{
+					TagThrottle:       fdbtypes.TagThrottle{Tag: "reader", Rate: 50, Priority: fdbtypes.TagThrottlePriorityBatch},
+					ExpirationSeconds: 120,
+					Manual:            false,
+				},
+			}))
+		})
+
+		It("should handle the output without throttled tags", func() {
+			throttles, err := ParseTagThrottles("There are no throttled tags\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(throttles).To(BeEmpty())
+		})
+	})
+
+	Describe("parsing the consistency check state", func() {
+		It("should parse a suspended consistency check", func() {
+			suspended, err := ParseConsistencyCheckSuspended("ConsistencyCheckIsSuspended: true\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suspended).To(BeTrue())
+		})
+
+		It("should parse a running consistency check", func() {
+			suspended, err := ParseConsistencyCheckSuspended("ConsistencyCheckIsSuspended: false\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(suspended).To(BeFalse())
+		})
+
+		It("should return an error for unexpected output", func() {
+			_, err := ParseConsistencyCheckSuspended("ERROR: Unknown command\n")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("managing the database knobs", func() {
+		It("should set all knobs in one transaction", func() {
+			Expect(GetSetKnobsCommand(map[string]string{"min_trace_severity": "10", "max_trace_lines": "1000"})).To(Equal("begin; setknob max_trace_lines 1000; setknob min_trace_severity 10; commit"))
+		})
+
+		It("should clear all knobs in one transaction", func() {
+			Expect(GetClearKnobsCommand([]string{"max_trace_lines", "min_trace_severity"})).To(Equal("begin; clearknob max_trace_lines; clearknob min_trace_severity; commit"))
+		})
+
+		It("should read all knobs", func() {
+			Expect(GetGetKnobsCommand([]string{"max_trace_lines", "min_trace_severity"})).To(Equal("getknob max_trace_lines; getknob min_trace_severity"))
+		})
+
+		It("should parse the knobs that are set", func() {
+			output := "`max_trace_lines' is `1000'\n`min_trace_severity' is not found\n"
+			Expect(ParseKnobs(output)).To(Equal(map[string]string{"max_trace_lines": "1000"}))
+		})
+	})
+
+	Describe("managing the tag quotas", func() {
+		It("should read the quotas of all tags", func() {
+			Expect(GetGetTagQuotasCommand([]string{"noisy", "reader"})).To(Equal("quota get noisy reserved_throughput; quota get noisy total_throughput; quota get reader reserved_throughput; quota get reader total_throughput"))
+		})
+
+		It("should set the total throughput before the reserved throughput", func() {
+			quota := fdbtypes.TagQuota{Tag: "noisy", ReservedThroughput: 1000, TotalThroughput: 5000}
+			Expect(GetSetTagQuotaCommand(quota)).To(Equal("quota set noisy total_throughput 5000; quota set noisy reserved_throughput 1000"))
+		})
+
+		It("should parse the quotas that are set", func() {
+			quotas, err := ParseTagQuotas([]string{"noisy", "reader", "writer"}, "1000\n5000\n<empty>\n<empty>\n<empty>\n2000\n")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(quotas).To(Equal([]fdbtypes.TagQuota{
+				{Tag: "noisy", ReservedThroughput: 1000, TotalThroughput: 5000},
+				{Tag: "writer", TotalThroughput: 2000},
+			}))
+		})
+
+		It("should return an error for unexpected output", func() {
+			_, err := ParseTagQuotas([]string{"noisy"}, "ERROR: Unknown command\n")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	It("should build the throttle command", func() {
+		throttle := fdbtypes.TagThrottle{Tag: "noisy", Rate: 100}
+		Expect(GetThrottleTagCommand(throttle, time.Hour)).To(Equal("throttle on tag noisy 100 3600s default"))
+	})
+})
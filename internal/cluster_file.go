@@ -0,0 +1,137 @@
+/*
+ * cluster_file.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// GetClusterFilePath returns the path of the cluster file that the operator
+// uses to connect to a cluster.
+func GetClusterFilePath(dir string, cluster *fdbtypes.FoundationDBCluster) string {
+	// Namespaces and names cannot contain underscores, so the file name is
+	// unique for every cluster.
+	return filepath.Join(dir, fmt.Sprintf("%s_%s.cluster", cluster.Namespace, cluster.Name))
+}
+
+// WriteClusterFile writes the connection string into the cluster file at the
+// given path. The file is replaced atomically, so processes that read the
+// file never see a partial connection string. If the file already contains
+// the connection string it is left untouched.
+func WriteClusterFile(path string, connectionString string) error {
+	current, err := os.ReadFile(path)
+	if err == nil && strings.TrimSpace(string(current)) == strings.TrimSpace(connectionString) {
+		return nil
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	dir := filepath.Dir(path)
+	err = os.MkdirAll(dir, 0700)
+	if err != nil {
+		return err
+	}
+
+	tempFile, err := os.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tempFilePath := tempFile.Name()
+	// After the rename the temp file no longer exists, so this only cleans up
+	// after a failed write.
+	defer os.Remove(tempFilePath)
+
+	_, err = tempFile.WriteString(connectionString)
+	if err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFilePath, path)
+}
+
+// shouldRemoveClusterFile determines whether a file in the cluster file
+// directory is no longer needed.
+func shouldRemoveClusterFile(info os.FileInfo, activeFiles map[string]bool, now time.Time, minFileAge time.Duration) bool {
+	if info.IsDir() || activeFiles[info.Name()] {
+		return false
+	}
+
+	// Files that were modified recently can belong to a cluster that was
+	// created after the clusters were listed, or to a command that is still
+	// running.
+	return !info.ModTime().Add(minFileAge).After(now)
+}
+
+// CleanupClusterFiles removes the files in the cluster file directory that do
+// not belong to any of the given clusters, like the cluster files for deleted
+// clusters.
+func CleanupClusterFiles(dir string, clusters []fdbtypes.FoundationDBCluster, minFileAge time.Duration) error {
+	activeFiles := make(map[string]bool, len(clusters))
+	for index := range clusters {
+		activeFiles[filepath.Base(GetClusterFilePath(dir, &clusters[index]))] = true
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	deletedCnt := 0
+	now := time.Now()
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		if !shouldRemoveClusterFile(info, activeFiles, now, minFileAge) {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(dir, info.Name()))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		deletedCnt++
+	}
+
+	log.V(1).Info("Cleanup stale cluster files", "clusterFileDir", dir, "deleted files", deletedCnt)
+	return nil
+}
@@ -0,0 +1,126 @@
+/*
+ * cluster_file_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cluster_file", func() {
+	var dir string
+	var cluster *fdbtypes.FoundationDBCluster
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "cluster-files")
+		Expect(err).NotTo(HaveOccurred())
+		cluster = CreateDefaultCluster()
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	listFiles := func() []string {
+		entries, err := os.ReadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+
+	It("should build the path from the namespace and name", func() {
+		Expect(GetClusterFilePath(dir, cluster)).To(Equal(filepath.Join(dir, "my-ns_operator-test-1.cluster")))
+	})
+
+	When("writing the cluster file", func() {
+		var path string
+
+		BeforeEach(func() {
+			path = GetClusterFilePath(filepath.Join(dir, "nested"), cluster)
+			Expect(WriteClusterFile(path, "test:abcd@127.0.0.1:4501")).To(Succeed())
+		})
+
+		It("should create the directory and the file", func() {
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("test:abcd@127.0.0.1:4501"))
+		})
+
+		It("should replace the file when the connection string changes", func() {
+			Expect(WriteClusterFile(path, "test:efgh@127.0.0.2:4501")).To(Succeed())
+			content, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("test:efgh@127.0.0.2:4501"))
+		})
+
+		It("should not leave any temp files behind", func() {
+			Expect(WriteClusterFile(path, "test:efgh@127.0.0.2:4501")).To(Succeed())
+			entries, err := os.ReadDir(filepath.Dir(path))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+
+		It("should not modify the file when the connection string is unchanged", func() {
+			oldTime := time.Now().Add(-time.Hour)
+			Expect(os.Chtimes(path, oldTime, oldTime)).To(Succeed())
+			Expect(WriteClusterFile(path, "test:abcd@127.0.0.1:4501")).To(Succeed())
+			info, err := os.Stat(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.ModTime().Unix()).To(Equal(oldTime.Unix()))
+		})
+	})
+
+	When("cleaning up cluster files", func() {
+		BeforeEach(func() {
+			deletedCluster := CreateDefaultCluster()
+			deletedCluster.Name = "deleted-cluster"
+			recentCluster := CreateDefaultCluster()
+			recentCluster.Name = "recent-cluster"
+
+			Expect(WriteClusterFile(GetClusterFilePath(dir, cluster), "test:abcd@127.0.0.1:4501")).To(Succeed())
+			Expect(WriteClusterFile(GetClusterFilePath(dir, deletedCluster), "test:abcd@127.0.0.1:4501")).To(Succeed())
+			Expect(WriteClusterFile(GetClusterFilePath(dir, recentCluster), "test:abcd@127.0.0.1:4501")).To(Succeed())
+
+			oldTime := time.Now().Add(-time.Hour)
+			Expect(os.Chtimes(GetClusterFilePath(dir, cluster), oldTime, oldTime)).To(Succeed())
+			Expect(os.Chtimes(GetClusterFilePath(dir, deletedCluster), oldTime, oldTime)).To(Succeed())
+
+			Expect(CleanupClusterFiles(dir, []fdbtypes.FoundationDBCluster{*cluster}, 10*time.Minute)).To(Succeed())
+		})
+
+		It("should only remove the old files for deleted clusters", func() {
+			Expect(listFiles()).To(ConsistOf("my-ns_operator-test-1.cluster", "my-ns_recent-cluster.cluster"))
+		})
+	})
+
+	It("should ignore a missing directory during the cleanup", func() {
+		Expect(CleanupClusterFiles(filepath.Join(dir, "missing"), nil, time.Minute)).To(Succeed())
+	})
+})
@@ -0,0 +1,71 @@
+/*
+ * concurrency.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import "sync"
+
+// RunConcurrently runs the tasks with at most maxConcurrency tasks running at
+// the same time. A maxConcurrency of 1 or less runs the tasks one after
+// another, and stops at the first error. Otherwise all tasks are run, and the
+// error of the first failed task in the list is returned. An error caused by
+// an exceeded resource quota is preferred over the other errors, so callers
+// can still detect it when other tasks failed as well.
+func RunConcurrently(maxConcurrency int, tasks []func() error) error {
+	if maxConcurrency <= 1 {
+		for _, task := range tasks {
+			err := task()
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	errs := make([]error, len(tasks))
+	slots := make(chan struct{}, maxConcurrency)
+	var wg sync.WaitGroup
+
+	for index, task := range tasks {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(index int, task func() error) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[index] = task()
+		}(index, task)
+	}
+
+	wg.Wait()
+
+	var firstErr error
+	for _, err := range errs {
+		if IsQuotaExceeded(err) {
+			return err
+		}
+
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,102 @@
+/*
+ * concurrency_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"sync"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("concurrency", func() {
+	var lock sync.Mutex
+	var running, maxRunning, completed int
+
+	BeforeEach(func() {
+		running = 0
+		maxRunning = 0
+		completed = 0
+	})
+
+	buildTasks := func(count int, failing map[int]bool) []func() error {
+		tasks := make([]func() error, 0, count)
+		for index := 0; index < count; index++ {
+			index := index
+			tasks = append(tasks, func() error {
+				lock.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				lock.Unlock()
+
+				defer func() {
+					lock.Lock()
+					running--
+					completed++
+					lock.Unlock()
+				}()
+
+				if failing[index] {
+					return fmt.Errorf("task %d failed", index)
+				}
+
+				return nil
+			})
+		}
+
+		return tasks
+	}
+
+	It("should run all tasks with the maximum concurrency", func() {
+		Expect(RunConcurrently(3, buildTasks(10, nil))).To(Succeed())
+		Expect(completed).To(Equal(10))
+		Expect(maxRunning).To(BeNumerically("<=", 3))
+	})
+
+	It("should return the error of the first failed task", func() {
+		Expect(RunConcurrently(3, buildTasks(10, map[int]bool{4: true, 7: true}))).To(MatchError("task 4 failed"))
+		Expect(completed).To(Equal(10))
+	})
+
+	It("should prefer the error of a task that exceeded a quota", func() {
+		tasks := buildTasks(10, map[int]bool{4: true})
+		quotaErr := k8serrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "storage-8", fmt.Errorf("exceeded quota: compute-resources, requested: pods=1, used: pods=10, limited: pods=10"))
+		tasks[8] = func() error {
+			return quotaErr
+		}
+
+		err := RunConcurrently(3, tasks)
+		Expect(err).To(Equal(quotaErr))
+		Expect(IsQuotaExceeded(err)).To(BeTrue())
+	})
+
+	It("should stop at the first error when running the tasks sequentially", func() {
+		Expect(RunConcurrently(1, buildTasks(10, map[int]bool{4: true, 7: true}))).To(MatchError("task 4 failed"))
+		Expect(completed).To(Equal(5))
+		Expect(maxRunning).To(Equal(1))
+	})
+})
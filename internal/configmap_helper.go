@@ -33,6 +33,10 @@ import (
 const (
 	// ClusterFileKey defines the key name in the ConfigMap
 	ClusterFileKey = "cluster-file"
+
+	// BlobCredentialsKey defines the key name for the credentials file in the
+	// blob credentials secret of a backup.
+	BlobCredentialsKey = "credentials"
 )
 
 // GetConfigMap builds a config map for a cluster's dynamic config
@@ -43,16 +47,9 @@ func GetConfigMap(cluster *v1beta1.FoundationDBCluster) (*corev1.ConfigMap, erro
 	data[ClusterFileKey] = connectionString
 	data["running-version"] = cluster.Status.RunningVersion
 
-	var caFile strings.Builder
-	for _, ca := range cluster.Spec.TrustedCAs {
-		if caFile.Len() > 0 {
-			caFile.WriteString("\n")
-		}
-		caFile.WriteString(ca)
-	}
-
-	if caFile.Len() > 0 {
-		data["ca-file"] = caFile.String()
+	caFile := getTrustedCAFile(cluster)
+	if caFile != "" {
+		data["ca-file"] = caFile
 	}
 
 	desiredCountStruct, err := cluster.GetProcessCountsWithDefaults()
@@ -63,26 +60,24 @@ func GetConfigMap(cluster *v1beta1.FoundationDBCluster) (*corev1.ConfigMap, erro
 
 	for processClass, count := range desiredCounts {
 		if count > 0 {
+			serversPerDisk := []int{1}
 			if processClass == v1beta1.ProcessClassStorage {
-				storageServersPerDisk := cluster.Status.StorageServersPerDisk
-				// If the status field is not initialized we fallback to only the specified count
-				// in the cluster spec. This should only happen in the initial phase of a new cluster.
-				if len(cluster.Status.StorageServersPerDisk) == 0 {
-					storageServersPerDisk = []int{cluster.GetStorageServersPerPod()}
-				}
+				serversPerDisk = cluster.Status.StorageServersPerDisk
+			} else if processClass.IsLogProcess() {
+				serversPerDisk = cluster.Status.LogServersPerDisk
+			}
 
-				for _, serversPerPod := range storageServersPerDisk {
-					err := setMonitorConfForFilename(cluster, data, GetConfigMapMonitorConfEntry(processClass, serversPerPod), connectionString, processClass, serversPerPod)
-					if err != nil {
-						return nil, err
-					}
-				}
-				continue
+			// If the status field is not initialized we fallback to only the specified count
+			// in the cluster spec. This should only happen in the initial phase of a new cluster.
+			if len(serversPerDisk) == 0 {
+				serversPerDisk = []int{cluster.GetDesiredServersPerPod(processClass)}
 			}
 
-			err := setMonitorConfForFilename(cluster, data, GetConfigMapMonitorConfEntry(processClass, 1), connectionString, processClass, 1)
-			if err != nil {
-				return nil, err
+			for _, serversPerPod := range serversPerDisk {
+				err := setMonitorConfForFilename(cluster, data, GetConfigMapMonitorConfEntry(processClass, serversPerPod), connectionString, processClass, serversPerPod)
+				if err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
@@ -152,6 +147,76 @@ func GetConfigMap(cluster *v1beta1.FoundationDBCluster) (*corev1.ConfigMap, erro
 	}, nil
 }
 
+// GetClientConfigMap builds the config map with the cluster file for client
+// applications. This will return nil if the client config is not enabled.
+func GetClientConfigMap(cluster *v1beta1.FoundationDBCluster) *corev1.ConfigMap {
+	if !cluster.ShouldPublishClientConfig() {
+		return nil
+	}
+
+	data := map[string]string{
+		ClusterFileKey: cluster.Status.ConnectionString,
+	}
+
+	caFile := getTrustedCAFile(cluster)
+	if caFile != "" {
+		data["ca-file"] = caFile
+	}
+
+	metadata := GetObjectMetadata(cluster, nil, "", "")
+	metadata.Name = fmt.Sprintf("%s-client-config", cluster.Name)
+	metadata.OwnerReferences = BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metadata,
+		Data:       data,
+	}
+}
+
+// GetClientTLSSecret builds the secret with the TLS files for client
+// applications, based on the secret that is referenced in the client config.
+// This will return nil if the client config is not enabled or has no TLS
+// secret.
+func GetClientTLSSecret(cluster *v1beta1.FoundationDBCluster, source *corev1.Secret) *corev1.Secret {
+	if !cluster.ShouldPublishClientConfig() || cluster.Spec.ClientConfig.TLSSecretName == "" || source == nil {
+		return nil
+	}
+
+	data := map[string][]byte{
+		corev1.TLSCertKey:       source.Data[corev1.TLSCertKey],
+		corev1.TLSPrivateKeyKey: source.Data[corev1.TLSPrivateKeyKey],
+	}
+
+	caFile := getTrustedCAFile(cluster)
+	if caFile != "" {
+		data["ca.crt"] = []byte(caFile)
+	}
+
+	metadata := GetObjectMetadata(cluster, nil, "", "")
+	metadata.Name = fmt.Sprintf("%s-client-tls", cluster.Name)
+	metadata.OwnerReferences = BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+
+	return &corev1.Secret{
+		ObjectMeta: metadata,
+		Type:       corev1.SecretTypeTLS,
+		Data:       data,
+	}
+}
+
+// getTrustedCAFile builds the contents of the CA file from the trusted CAs
+// of the cluster.
+func getTrustedCAFile(cluster *v1beta1.FoundationDBCluster) string {
+	var caFile strings.Builder
+	for _, ca := range cluster.Spec.TrustedCAs {
+		if caFile.Len() > 0 {
+			caFile.WriteString("\n")
+		}
+		caFile.WriteString(ca)
+	}
+
+	return caFile.String()
+}
+
 func getConfigMapMetadata(cluster *v1beta1.FoundationDBCluster) metav1.ObjectMeta {
 	var metadata metav1.ObjectMeta
 	if cluster.Spec.ConfigMap != nil {
@@ -170,6 +235,21 @@ func getConfigMapMetadata(cluster *v1beta1.FoundationDBCluster) metav1.ObjectMet
 }
 
 func setMonitorConfForFilename(cluster *v1beta1.FoundationDBCluster, data map[string]string, filename string, connectionString string, processClass v1beta1.ProcessClass, serversPerPod int) error {
+	if cluster.UseUnifiedImage() {
+		configuration, err := GetMonitorProcessConfiguration(cluster, processClass, serversPerPod)
+		if err != nil {
+			return err
+		}
+
+		configurationData, err := json.Marshal(configuration)
+		if err != nil {
+			return err
+		}
+
+		data[GetConfigMapMonitorConfigurationEntry(processClass, serversPerPod)] = string(configurationData)
+		return nil
+	}
+
 	if connectionString == "" {
 		data[filename] = ""
 	} else {
@@ -192,6 +272,12 @@ func GetConfigMapMonitorConfEntry(pClass v1beta1.ProcessClass, serversPerPod int
 	return fmt.Sprintf("fdbmonitor-conf-%s", pClass)
 }
 
+// GetConfigMapMonitorConfigurationEntry returns the specific key for the
+// process configuration of the kubernetes monitor in the ConfigMap
+func GetConfigMapMonitorConfigurationEntry(pClass v1beta1.ProcessClass, serversPerPod int) string {
+	return fmt.Sprintf("%s-json", GetConfigMapMonitorConfEntry(pClass, serversPerPod))
+}
+
 // GetDynamicConfHash gets a hash of the data from the config map holding the
 // cluster's dynamic conf.
 //
@@ -200,6 +286,7 @@ func GetDynamicConfHash(configMap *corev1.ConfigMap, pClass v1beta1.ProcessClass
 	fields := []string{
 		ClusterFileKey,
 		GetConfigMapMonitorConfEntry(pClass, serversPerPod),
+		GetConfigMapMonitorConfigurationEntry(pClass, serversPerPod),
 		"running-version",
 		"ca-file",
 		"sidecar-conf",
@@ -23,6 +23,7 @@ package internal
 import (
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -92,6 +93,14 @@ func NormalizeClusterSpec(cluster *fdbtypes.FoundationDBCluster, options Depreca
 		cluster.Spec.NextInstanceID = 0
 	}
 
+	if cluster.Spec.UpdatePodsByReplacement {
+		if cluster.Spec.PodUpdateStrategy == nil {
+			strategy := fdbtypes.PodUpdateStrategyReplacement
+			cluster.Spec.PodUpdateStrategy = &strategy
+		}
+		cluster.Spec.UpdatePodsByReplacement = false
+	}
+
 	if cluster.Spec.PodLabels != nil {
 		updatePodTemplates(&cluster.Spec, func(template *v1.PodTemplateSpec) {
 			mergeLabels(&template.ObjectMeta, cluster.Spec.PodLabels)
@@ -248,6 +257,51 @@ func NormalizeClusterSpec(cluster *fdbtypes.FoundationDBCluster, options Depreca
 		}
 	}
 
+	err := ValidateDatabaseKnobs(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateBinaryChecksums(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateProcessPorts(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = validateObserveOnly(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = validateProcessClasses(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = validateDatabaseConfiguration(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = validateProcessRestartOptions(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = validateExcludedLocalities(cluster)
+	if err != nil {
+		return err
+	}
+
+	err = validateTags(cluster)
+	if err != nil {
+		return err
+	}
+
 	if !options.OnlyShowChanges {
 		// Set up resource requirements for the main container.
 		updatePodTemplates(&cluster.Spec, func(template *v1.PodTemplateSpec) {
@@ -277,7 +331,11 @@ func NormalizeClusterSpec(cluster *fdbtypes.FoundationDBCluster, options Depreca
 			cluster.Spec.AutomationOptions.Replacements.FailureDetectionTimeSeconds = &duration
 		}
 
-		cluster.Spec.MainContainer.ImageConfigs = append(cluster.Spec.MainContainer.ImageConfigs, fdbtypes.ImageConfig{BaseImage: "foundationdb/foundationdb"})
+		mainImage := "foundationdb/foundationdb"
+		if cluster.UseUnifiedImage() {
+			mainImage = "foundationdb/fdb-kubernetes-monitor"
+		}
+		cluster.Spec.MainContainer.ImageConfigs = append(cluster.Spec.MainContainer.ImageConfigs, fdbtypes.ImageConfig{BaseImage: mainImage})
 		cluster.Spec.SidecarContainer.ImageConfigs = append(cluster.Spec.SidecarContainer.ImageConfigs, fdbtypes.ImageConfig{BaseImage: "foundationdb/foundationdb-kubernetes-sidecar", TagSuffix: "-1"})
 	}
 
@@ -558,6 +616,154 @@ func ensureContainerPresent(containers []corev1.Container, name string, insertIn
 	return newContainers, insertIndex
 }
 
+// validateObserveOnly ensures that a cluster that is only observed provides
+// the connection string of the existing database, and that a cluster that
+// was only observed is not switched to being managed by the operator.
+func validateObserveOnly(cluster *fdbtypes.FoundationDBCluster) error {
+	if !cluster.Spec.ObserveOnly {
+		// The operator would otherwise create pods for the existing
+		// database, apply its configuration and move the coordinators.
+		if cluster.Status.ObservedOnly {
+			return fmt.Errorf("observeOnly cannot be disabled for a cluster that the operator has only observed")
+		}
+
+		return nil
+	}
+
+	if cluster.Spec.SeedConnectionString == "" && cluster.Status.ConnectionString == "" {
+		return fmt.Errorf("observeOnly requires the seedConnectionString of the existing database")
+	}
+
+	return nil
+}
+
+// validateProcessRestartOptions ensures that the process restart options are
+// only used with the split image, since the kubernetes monitor in the unified
+// image does not read them.
+func validateProcessRestartOptions(cluster *fdbtypes.FoundationDBCluster) error {
+	if cluster.UseUnifiedImage() && cluster.Spec.ProcessRestartOptions != (fdbtypes.ProcessRestartOptions{}) {
+		return fmt.Errorf("processRestartOptions are not supported with the unified image")
+	}
+
+	return nil
+}
+
+// localityValueRegex matches the locality values that can be passed to the
+// exclude command in fdbcli.
+var localityValueRegex = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// validateExcludedLocalities ensures that the excluded localities only
+// contain characters that are safe to pass to fdbcli.
+func validateExcludedLocalities(cluster *fdbtypes.FoundationDBCluster) error {
+	violations := make([]string, 0)
+	for _, exclusion := range cluster.Spec.ExcludedLocalities {
+		if !localityValueRegex.MatchString(exclusion.Value) {
+			violations = append(violations, fmt.Sprintf("invalid value for excluded locality %s: %q", exclusion.Key, exclusion.Value))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found the following excludedLocalities violations:\n%s", strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
+
+// tagRegex matches the transaction tags that can be passed to the throttle
+// and quota commands in fdbcli.
+var tagRegex = regexp.MustCompile(`^[a-zA-Z0-9_\-]+$`)
+
+// validateTags ensures that the tags of the tag throttles and tag quotas only
+// contain characters that are safe to pass to fdbcli.
+func validateTags(cluster *fdbtypes.FoundationDBCluster) error {
+	violations := make([]string, 0)
+	for _, throttle := range cluster.Spec.TagThrottles {
+		if !tagRegex.MatchString(throttle.Tag) {
+			violations = append(violations, fmt.Sprintf("invalid tag in tagThrottles: %q", throttle.Tag))
+		}
+	}
+
+	for _, quota := range cluster.Spec.TagQuotas {
+		if !tagRegex.MatchString(quota.Tag) {
+			violations = append(violations, fmt.Sprintf("invalid tag in tagQuotas: %q", quota.Tag))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found the following tag violations:\n%s", strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
+
+// validateProcessClasses ensures that the version of the cluster supports
+// the process classes in the process counts.
+func validateProcessClasses(cluster *fdbtypes.FoundationDBCluster) error {
+	counts := cluster.Spec.ProcessCounts
+	if counts.CommitProxy <= 0 && counts.GrvProxy <= 0 {
+		return nil
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return err
+	}
+
+	if !version.HasGrvProxies() {
+		return fmt.Errorf("process classes %s and %s are not supported in version %s", fdbtypes.ProcessClassCommitProxy, fdbtypes.ProcessClassGrvProxy, version)
+	}
+
+	return nil
+}
+
+// validDatabaseConfigurationValues defines the values that the database
+// configuration in the spec can use. The CRD does not restrict these values,
+// because the database configuration type is shared with the status.
+var validDatabaseConfigurationValues = map[string][]string{
+	"redundancy_mode":           {"single", "double", "triple"},
+	"storage_engine":            {"ssd", "ssd-1", "ssd-2", "memory", "memory-1", "memory-2", "memory-radixtree-beta", "ssd-redwood-experimental", "ssd-redwood-1-experimental", "ssd-rocksdb-experimental"},
+	"storage_migration_type":    {"disabled", "aggressive", "gradual"},
+	"satellite_redundancy_mode": {"one_satellite_single", "one_satellite_double", "one_satellite_triple", "two_satellite_safe", "two_satellite_fast"},
+}
+
+// validateDatabaseConfiguration ensures that the database configuration in
+// the spec only uses values that the operator knows.
+func validateDatabaseConfiguration(cluster *fdbtypes.FoundationDBCluster) error {
+	configuration := cluster.Spec.DatabaseConfiguration
+	violations := make([]string, 0)
+
+	checkValue := func(key string, value string) {
+		if value == "" {
+			return
+		}
+
+		for _, validValue := range validDatabaseConfigurationValues[key] {
+			if value == validValue {
+				return
+			}
+		}
+
+		violations = append(violations, fmt.Sprintf("%s has an unsupported value: %s", key, value))
+	}
+
+	checkValue("redundancy_mode", string(configuration.RedundancyMode))
+	checkValue("storage_engine", configuration.StorageEngine)
+	checkValue("storage_migration_type", string(configuration.StorageMigrationType))
+	for _, region := range configuration.Regions {
+		checkValue("satellite_redundancy_mode", region.SatelliteRedundancyMode)
+	}
+
+	if configuration.UsableRegions < 0 || configuration.UsableRegions > 2 {
+		violations = append(violations, fmt.Sprintf("usable_regions has an unsupported value: %d", configuration.UsableRegions))
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found the following databaseConfiguration violations:\n%s", strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
+
 // ValidateCustomParameters ensures that no duplicate values are set and that no
 // protected/forbidden parameters are set. Theoretically we could also check if FDB
 // supports the given parameter.
@@ -587,3 +793,154 @@ func ValidateCustomParameters(customParameters []string) error {
 
 	return nil
 }
+
+// databaseKnobNameRegex matches the valid names for database knobs.
+var databaseKnobNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// ValidateDatabaseKnobs ensures that the database knobs have valid names and
+// values, and that they are not also set through the custom parameters.
+func ValidateDatabaseKnobs(cluster *fdbtypes.FoundationDBCluster) error {
+	if len(cluster.Spec.DatabaseKnobs) == 0 {
+		return nil
+	}
+
+	customParameters := make(map[string]bool)
+	for _, setting := range cluster.Spec.Processes {
+		if setting.CustomParameters == nil {
+			continue
+		}
+
+		for _, parameter := range *setting.CustomParameters {
+			customParameters[strings.TrimSpace(strings.Split(parameter, "=")[0])] = true
+		}
+	}
+
+	names := make([]string, 0, len(cluster.Spec.DatabaseKnobs))
+	for name := range cluster.Spec.DatabaseKnobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	violations := make([]string, 0)
+	for _, name := range names {
+		value := cluster.Spec.DatabaseKnobs[name]
+		if !databaseKnobNameRegex.MatchString(name) || strings.HasPrefix(name, "knob_") {
+			violations = append(violations, fmt.Sprintf("invalid databaseKnob name: %s", name))
+		}
+
+		if value == "" || strings.ContainsAny(value, " \t\n;\"") {
+			violations = append(violations, fmt.Sprintf("invalid value for databaseKnob %s: %q", name, value))
+		}
+
+		if customParameters["knob_"+name] {
+			violations = append(violations, fmt.Sprintf("databaseKnob %s is also set as customParameter knob_%s", name, name))
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found the following databaseKnobs violations:\n%s", strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
+
+// binaryChecksumRegex matches a hex-encoded SHA-256 checksum.
+var binaryChecksumRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidateBinaryChecksums ensures that the binary checksums have valid
+// versions, binary names and checksums.
+func ValidateBinaryChecksums(cluster *fdbtypes.FoundationDBCluster) error {
+	if len(cluster.Spec.BinaryChecksums) == 0 {
+		return nil
+	}
+
+	versions := make([]string, 0, len(cluster.Spec.BinaryChecksums))
+	for version := range cluster.Spec.BinaryChecksums {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+
+	violations := make([]string, 0)
+	for _, version := range versions {
+		_, err := fdbtypes.ParseFdbVersion(version)
+		if err != nil {
+			violations = append(violations, fmt.Sprintf("invalid binaryChecksums version: %s", version))
+		}
+
+		checksums := cluster.Spec.BinaryChecksums[version]
+		binaries := make([]string, 0, len(checksums))
+		for binary := range checksums {
+			binaries = append(binaries, binary)
+		}
+		sort.Strings(binaries)
+
+		for _, binary := range binaries {
+			if binary == "" || strings.ContainsAny(binary, "/ ") {
+				violations = append(violations, fmt.Sprintf("invalid binary name for version %s: %q", version, binary))
+			}
+
+			if !binaryChecksumRegex.MatchString(checksums[binary]) {
+				violations = append(violations, fmt.Sprintf("invalid checksum for binary %s in version %s: %q", binary, version, checksums[binary]))
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found the following binaryChecksums violations:\n%s", strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
+
+// ValidateProcessPorts ensures that the ports of the processes are valid port
+// numbers, do not conflict with the port of the sidecar, and match the ports
+// that the processes of an existing cluster use.
+func ValidateProcessPorts(cluster *fdbtypes.FoundationDBCluster) error {
+	recordedPorts := cluster.Status.ProcessPorts
+	if recordedPorts != nil && (recordedPorts.BasePort != cluster.GetBasePort() || recordedPorts.PortStride != cluster.GetPortStride()) {
+		return fmt.Errorf("basePort and portStride cannot be changed after the cluster is created, the processes use the base port %d and the port stride %d",
+			recordedPorts.BasePort, recordedPorts.PortStride)
+	}
+
+	if cluster.Spec.Routing.BasePort == nil && cluster.Spec.Routing.PortStride == nil {
+		return nil
+	}
+
+	counts, err := cluster.GetProcessCountsWithDefaults()
+	if err != nil {
+		return err
+	}
+
+	reservedPorts := map[int]string{SidecarPort: "sidecar"}
+
+	processClasses := make([]string, 0, len(counts.Map()))
+	for processClass, count := range counts.Map() {
+		if count > 0 {
+			processClasses = append(processClasses, string(processClass))
+		}
+	}
+	sort.Strings(processClasses)
+
+	violations := make([]string, 0)
+	for _, processClass := range processClasses {
+		serversPerPod := cluster.GetDesiredServersPerPod(fdbtypes.ProcessClass(processClass))
+		for processNumber := 1; processNumber <= serversPerPod; processNumber++ {
+			for _, tls := range []bool{true, false} {
+				port := cluster.GetProcessPortForClass(fdbtypes.ProcessClass(processClass), processNumber, tls)
+				if port > 65535 {
+					violations = append(violations, fmt.Sprintf("port %d of process class %s is not a valid port", port, processClass))
+				}
+
+				if reserved, ok := reservedPorts[port]; ok {
+					violations = append(violations, fmt.Sprintf("port %d of process class %s conflicts with the %s port", port, processClass, reserved))
+				}
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("found the following port violations:\n%s", strings.Join(violations, "\n"))
+	}
+
+	return nil
+}
@@ -32,9 +32,123 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
 )
 
 var _ = Describe("[internal] deprecations", func() {
+	When("Providing database knobs", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+
+		BeforeEach(func() {
+			cluster = &fdbtypes.FoundationDBCluster{}
+		})
+
+		It("should accept valid knobs", func() {
+			cluster.Spec.DatabaseKnobs = map[string]string{"min_trace_severity": "10"}
+			Expect(ValidateDatabaseKnobs(cluster)).To(Succeed())
+		})
+
+		It("should reject invalid names and values", func() {
+			cluster.Spec.DatabaseKnobs = map[string]string{"knob_max_trace_lines": "1000", "min_trace_severity": "1 0"}
+			Expect(ValidateDatabaseKnobs(cluster)).To(MatchError("found the following databaseKnobs violations:\ninvalid databaseKnob name: knob_max_trace_lines\ninvalid value for databaseKnob min_trace_severity: \"1 0\""))
+		})
+
+		It("should reject values that contain other fdbcli commands", func() {
+			cluster.Spec.DatabaseKnobs = map[string]string{"min_trace_severity": "10;configure single", "max_trace_lines; status": "1000"}
+			Expect(ValidateDatabaseKnobs(cluster)).To(MatchError("found the following databaseKnobs violations:\ninvalid databaseKnob name: max_trace_lines; status\ninvalid value for databaseKnob min_trace_severity: \"10;configure single\""))
+		})
+
+		It("should reject knobs that are also set as custom parameters", func() {
+			cluster.Spec.DatabaseKnobs = map[string]string{"min_trace_severity": "10"}
+			cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{
+				fdbtypes.ProcessClassGeneral: {CustomParameters: &[]string{"knob_min_trace_severity=20"}},
+			}
+			Expect(ValidateDatabaseKnobs(cluster)).To(MatchError("found the following databaseKnobs violations:\ndatabaseKnob min_trace_severity is also set as customParameter knob_min_trace_severity"))
+		})
+	})
+
+	When("Providing process ports", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+		})
+
+		It("should accept the default ports", func() {
+			Expect(ValidateProcessPorts(cluster)).To(Succeed())
+		})
+
+		It("should reject ports that conflict with the sidecar", func() {
+			basePort := 8078
+			cluster.Spec.Routing.BasePort = &basePort
+			cluster.Spec.StorageServersPerPod = 2
+			Expect(ValidateProcessPorts(cluster)).To(MatchError(ContainSubstring("port 8080 of process class storage conflicts with the sidecar port")))
+		})
+
+		It("should accept ports that match the port of the metrics exporter", func() {
+			enabled := true
+			basePort := 9090
+			cluster.Spec.MetricsExporter.Enabled = &enabled
+			cluster.Spec.Routing.BasePort = &basePort
+			Expect(ValidateProcessPorts(cluster)).To(Succeed())
+		})
+
+		When("the ports of the processes are recorded in the status", func() {
+			BeforeEach(func() {
+				cluster.Status.ProcessPorts = &fdbtypes.ProcessPortsStatus{
+					BasePort:   fdbtypes.DefaultBasePort,
+					PortStride: fdbtypes.DefaultPortStride,
+				}
+			})
+
+			It("should accept the recorded ports", func() {
+				Expect(ValidateProcessPorts(cluster)).To(Succeed())
+			})
+
+			It("should reject a change of the base port", func() {
+				basePort := 4600
+				cluster.Spec.Routing.BasePort = &basePort
+				Expect(ValidateProcessPorts(cluster)).To(MatchError("basePort and portStride cannot be changed after the cluster is created, the processes use the base port 4500 and the port stride 2"))
+			})
+
+			It("should reject a change of the port stride", func() {
+				portStride := 4
+				cluster.Spec.Routing.PortStride = &portStride
+				Expect(ValidateProcessPorts(cluster)).To(MatchError(ContainSubstring("cannot be changed after the cluster is created")))
+			})
+		})
+
+		It("should reject ports above the valid port range", func() {
+			basePort := 65534
+			cluster.Spec.Routing.BasePort = &basePort
+			cluster.Spec.StorageServersPerPod = 2
+			Expect(ValidateProcessPorts(cluster)).To(MatchError(ContainSubstring("port 65536 of process class storage is not a valid port")))
+		})
+	})
+
+	When("Providing binary checksums", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+
+		BeforeEach(func() {
+			cluster = &fdbtypes.FoundationDBCluster{}
+		})
+
+		It("should accept valid checksums", func() {
+			cluster.Spec.BinaryChecksums = map[string]fdbtypes.BinaryChecksums{
+				"6.2.20": {"fdbserver": strings.Repeat("ab", 32)},
+			}
+			Expect(ValidateBinaryChecksums(cluster)).To(Succeed())
+		})
+
+		It("should reject invalid versions, binary names and checksums", func() {
+			cluster.Spec.BinaryChecksums = map[string]fdbtypes.BinaryChecksums{
+				"6.2":    {"fdbserver": strings.Repeat("ab", 32)},
+				"6.2.20": {"bin/fdbserver": strings.Repeat("ab", 32), "fdbcli": "abc"},
+			}
+			Expect(ValidateBinaryChecksums(cluster)).To(MatchError("found the following binaryChecksums violations:\ninvalid binaryChecksums version: 6.2\ninvalid binary name for version 6.2.20: \"bin/fdbserver\"\ninvalid checksum for binary fdbcli in version 6.2.20: \"abc\""))
+		})
+	})
+
 	When("Providing a custom parameter", func() {
 		type testCase struct {
 			Input              []string
@@ -95,6 +209,173 @@ var _ = Describe("[internal] deprecations", func() {
 			spec = &cluster.Spec
 		})
 
+		Context("with a storage engine that the operator does not know for the version", func() {
+			BeforeEach(func() {
+				spec.DatabaseConfiguration.StorageEngine = "ssd-rocksdb-experimental"
+			})
+
+			It("should not return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with a storage engine that the version supports", func() {
+			BeforeEach(func() {
+				spec.DatabaseConfiguration.StorageEngine = "ssd-2"
+			})
+
+			It("should not return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with a storage engine that the operator does not know", func() {
+			BeforeEach(func() {
+				spec.DatabaseConfiguration.StorageEngine = "ssd-unknown"
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("found the following databaseConfiguration violations:\nstorage_engine has an unsupported value: ssd-unknown"))
+			})
+		})
+
+		Context("with an unknown redundancy mode and usable regions", func() {
+			BeforeEach(func() {
+				spec.DatabaseConfiguration.RedundancyMode = "quadruple"
+				spec.DatabaseConfiguration.UsableRegions = 3
+			})
+
+			It("should return an error for both values", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("found the following databaseConfiguration violations:\nredundancy_mode has an unsupported value: quadruple\nusable_regions has an unsupported value: 3"))
+			})
+		})
+
+		Context("with process restart options in the unified image", func() {
+			BeforeEach(func() {
+				imageType := fdbtypes.ImageTypeUnified
+				spec.ImageType = &imageType
+				spec.ProcessRestartOptions.RestartBackoff = pointer.Int(2)
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("processRestartOptions are not supported with the unified image"))
+			})
+		})
+
+		Context("with an excluded locality that contains another fdbcli command", func() {
+			BeforeEach(func() {
+				spec.ExcludedLocalities = []fdbtypes.LocalityExclusion{
+					{Key: "zoneid", Value: "zone-1"},
+					{Key: "zoneid", Value: "zone-2; configure single"},
+				}
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("found the following excludedLocalities violations:\ninvalid value for excluded locality zoneid: \"zone-2; configure single\""))
+			})
+		})
+
+		Context("with tags that contain other fdbcli commands", func() {
+			BeforeEach(func() {
+				spec.TagThrottles = []fdbtypes.TagThrottle{
+					{Tag: "batch_jobs", Rate: 10},
+					{Tag: "a;configure", Rate: 10},
+				}
+				spec.TagQuotas = []fdbtypes.TagQuota{
+					{Tag: "import-jobs"},
+					{Tag: "a b", TotalThroughput: 100},
+				}
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("found the following tag violations:\ninvalid tag in tagThrottles: \"a;configure\"\ninvalid tag in tagQuotas: \"a b\""))
+			})
+		})
+
+		Context("with an observed cluster without a seed connection string", func() {
+			BeforeEach(func() {
+				spec.ObserveOnly = true
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("observeOnly requires the seedConnectionString of the existing database"))
+			})
+		})
+
+		Context("with an observed cluster with a seed connection string", func() {
+			BeforeEach(func() {
+				spec.ObserveOnly = true
+				spec.SeedConnectionString = "existing:abcd@127.0.0.1:4500"
+			})
+
+			It("should not return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
+		Context("with a cluster that was only observed", func() {
+			BeforeEach(func() {
+				cluster.Status.ObservedOnly = true
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("observeOnly cannot be disabled for a cluster that the operator has only observed"))
+			})
+
+			When("the cluster is still observed", func() {
+				BeforeEach(func() {
+					spec.ObserveOnly = true
+					spec.SeedConnectionString = "existing:abcd@127.0.0.1:4500"
+				})
+
+				It("should not return an error", func() {
+					err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+		})
+
+		Context("with commit proxy processes in a version without GRV proxies", func() {
+			BeforeEach(func() {
+				spec.ProcessCounts.CommitProxy = 2
+			})
+
+			It("should return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("process classes commit_proxy and grv_proxy are not supported in version 6.2.20"))
+			})
+		})
+
+		Context("with commit proxy processes in a version with GRV proxies", func() {
+			BeforeEach(func() {
+				spec.Version = fdbtypes.Versions.NextMajorVersion.String()
+				spec.ProcessCounts.CommitProxy = 2
+			})
+
+			It("should not return an error", func() {
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+
 		Describe("deprecations", func() {
 			JustBeforeEach(func() {
 				err := NormalizeClusterSpec(cluster, DeprecationOptions{})
@@ -373,6 +654,17 @@ var _ = Describe("[internal] deprecations", func() {
 				})
 			})
 
+			Context("with the UpdatePodsByReplacement field", func() {
+				BeforeEach(func() {
+					spec.UpdatePodsByReplacement = true
+				})
+
+				It("sets the pod update strategy", func() {
+					Expect(*spec.PodUpdateStrategy).To(Equal(fdbtypes.PodUpdateStrategyReplacement))
+					Expect(spec.UpdatePodsByReplacement).To(BeFalse())
+				})
+			})
+
 			Context("with a custom value for the StorageClass field", func() {
 				BeforeEach(func() {
 					storageClass := "ebs"
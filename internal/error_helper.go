@@ -23,8 +23,19 @@ package internal
 import (
 	"errors"
 	"net"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+// quotaErrorMessages contains the parts of the messages that the resource
+// quota and limit range admission plugins use when they reject a resource.
+var quotaErrorMessages = []string{
+	"exceeded quota",
+	"failed quota",
+	"usage per",
+}
+
 // IsNetworkError returns true if the network is a network error net.Error
 func IsNetworkError(err error) bool {
 	for err != nil {
@@ -37,3 +48,21 @@ func IsNetworkError(err error) bool {
 
 	return false
 }
+
+// IsQuotaExceeded returns true if the error was caused by the creation of a
+// resource that was rejected because it would exceed a resource quota or
+// violate a limit range in the namespace.
+func IsQuotaExceeded(err error) bool {
+	if !k8serrors.IsForbidden(err) {
+		return false
+	}
+
+	message := err.Error()
+	for _, quotaErrorMessage := range quotaErrorMessages {
+		if strings.Contains(message, quotaErrorMessage) {
+			return true
+		}
+	}
+
+	return false
+}
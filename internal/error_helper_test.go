@@ -27,6 +27,8 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 var _ = Describe("Internal error helper", func() {
@@ -62,4 +64,49 @@ var _ = Describe("Internal error helper", func() {
 				}),
 		)
 	})
+
+	When("checking if an error is caused by a resource quota", func() {
+		type testCase struct {
+			err      error
+			expected bool
+		}
+
+		podResource := schema.GroupResource{Resource: "pods"}
+
+		DescribeTable("parse the error",
+			func(tc testCase) {
+				Expect(IsQuotaExceeded(tc.err)).To(Equal(tc.expected))
+			},
+			Entry("simple error",
+				testCase{
+					err:      fmt.Errorf("exceeded quota"),
+					expected: false,
+				}),
+			Entry("exceeded quota",
+				testCase{
+					err:      k8serrors.NewForbidden(podResource, "storage-1", fmt.Errorf("exceeded quota: compute-resources, requested: pods=1, used: pods=10, limited: pods=10")),
+					expected: true,
+				}),
+			Entry("missing limits for a quota",
+				testCase{
+					err:      k8serrors.NewForbidden(podResource, "storage-1", fmt.Errorf("failed quota: compute-resources: must specify limits.cpu")),
+					expected: true,
+				}),
+			Entry("limit range violation",
+				testCase{
+					err:      k8serrors.NewForbidden(podResource, "storage-1", fmt.Errorf("maximum cpu usage per Container is 1, but limit is 2")),
+					expected: true,
+				}),
+			Entry("missing permissions",
+				testCase{
+					err:      k8serrors.NewForbidden(podResource, "storage-1", fmt.Errorf("User \"test\" cannot create resource \"pods\"")),
+					expected: false,
+				}),
+			Entry("wrapped quota error",
+				testCase{
+					err:      fmt.Errorf("test : %w", k8serrors.NewForbidden(podResource, "storage-1", fmt.Errorf("exceeded quota: compute-resources"))),
+					expected: true,
+				}),
+		)
+	})
 })
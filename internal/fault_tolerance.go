@@ -21,6 +21,8 @@
 package internal
 
 import (
+	ctx "context"
+
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"github.com/FoundationDB/fdb-kubernetes-operator/pkg/fdbadminclient"
 )
@@ -32,7 +34,7 @@ func hasDesiredFaultTolerance(expectedFaultTolerance int, maxZoneFailuresWithout
 }
 
 // HasDesiredFaultTolerance checks if the cluster has the desired fault tolerance.
-func HasDesiredFaultTolerance(adminClient fdbadminclient.AdminClient, cluster *fdbtypes.FoundationDBCluster) (bool, error) {
+func HasDesiredFaultTolerance(adminClient fdbadminclient.AdminClient, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (bool, error) {
 	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
 	if err != nil {
 		return false, err
@@ -42,7 +44,7 @@ func HasDesiredFaultTolerance(adminClient fdbadminclient.AdminClient, cluster *f
 		return true, nil
 	}
 
-	status, err := adminClient.GetStatus()
+	status, err := adminClient.GetStatus(context)
 	if err != nil {
 		return false, err
 	}
@@ -68,3 +70,35 @@ func HasDesiredFaultTolerance(adminClient fdbadminclient.AdminClient, cluster *f
 		status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData,
 		status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability), nil
 }
+
+// GetFaultTolerance returns the number of fault domains the cluster can lose
+// without losing data or availability, based on the database status.
+func GetFaultTolerance(status *fdbtypes.FoundationDBStatus) int {
+	faultTolerance := status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData
+	if status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability < faultTolerance {
+		faultTolerance = status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability
+	}
+
+	return faultTolerance
+}
+
+// HasMinimumFaultTolerance checks if the cluster can lose at least one more
+// fault domain without losing data or availability. Clusters whose redundancy
+// mode does not tolerate any failures always meet this, and so do clusters
+// where the status does not report the fault tolerance.
+func HasMinimumFaultTolerance(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus) (bool, error) {
+	if cluster.DesiredFaultTolerance() == 0 {
+		return true, nil
+	}
+
+	version, err := fdbtypes.ParseFdbVersion(cluster.Spec.Version)
+	if err != nil {
+		return false, err
+	}
+
+	if !version.HasZoneFaultToleranceInStatus() || !status.Client.DatabaseStatus.Available {
+		return true, nil
+	}
+
+	return GetFaultTolerance(status) > 0, nil
+}
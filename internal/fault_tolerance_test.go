@@ -21,6 +21,7 @@
 package internal
 
 import (
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -65,4 +66,43 @@ var _ = Describe("fault_tolerance", func() {
 				}),
 		)
 	})
+
+	When("checking if the cluster has the minimum fault tolerance", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var status *fdbtypes.FoundationDBStatus
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+			status = &fdbtypes.FoundationDBStatus{}
+			status.Client.DatabaseStatus.Available = true
+			status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData = 1
+			status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability = 1
+		})
+
+		It("should return the lower of the data and availability fault tolerance", func() {
+			status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingData = 2
+			Expect(GetFaultTolerance(status)).To(Equal(1))
+		})
+
+		It("should have the minimum fault tolerance when the cluster can lose a fault domain", func() {
+			Expect(HasMinimumFaultTolerance(cluster, status)).To(BeTrue())
+		})
+
+		It("should not have the minimum fault tolerance when the cluster cannot lose a fault domain", func() {
+			status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability = 0
+			Expect(HasMinimumFaultTolerance(cluster, status)).To(BeFalse())
+		})
+
+		It("should have the minimum fault tolerance when the redundancy mode has no fault tolerance", func() {
+			cluster.Spec.DatabaseConfiguration.RedundancyMode = fdbtypes.RedundancyModeSingle
+			status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability = 0
+			Expect(HasMinimumFaultTolerance(cluster, status)).To(BeTrue())
+		})
+
+		It("should have the minimum fault tolerance when the database is unavailable", func() {
+			status.Client.DatabaseStatus.Available = false
+			status.Cluster.FaultTolerance.MaxZoneFailuresWithoutLosingAvailability = 0
+			Expect(HasMinimumFaultTolerance(cluster, status)).To(BeTrue())
+		})
+	})
 })
@@ -42,3 +42,23 @@ func GetCoordinatorsFromStatus(status *fdbtypes.FoundationDBStatus) map[string]s
 
 	return coordinators
 }
+
+// GetActivePrimaryDataCenter gets the data center that is currently serving
+// as the primary from the status. Older versions of FDB do not report the
+// active primary data center, so this falls back to the data center of the
+// process with the master role.
+func GetActivePrimaryDataCenter(status *fdbtypes.FoundationDBStatus) string {
+	if status.Cluster.ActivePrimaryDC != "" {
+		return status.Cluster.ActivePrimaryDC
+	}
+
+	for _, pInfo := range status.Cluster.Processes {
+		for _, roleInfo := range pInfo.Roles {
+			if roleInfo.Role == string(fdbtypes.ProcessRoleMaster) {
+				return pInfo.Locality[fdbtypes.FDBLocalityDCIDKey]
+			}
+		}
+	}
+
+	return ""
+}
@@ -106,4 +106,61 @@ var _ = Describe("Internal FoundationDBStatus", func() {
 				}),
 		)
 	})
+
+	When("getting the active primary data center", func() {
+		type testCase struct {
+			status   *fdbtypes.FoundationDBStatus
+			expected string
+		}
+
+		DescribeTable("parse the status",
+			func(tc testCase) {
+				Expect(GetActivePrimaryDataCenter(tc.status)).To(Equal(tc.expected))
+			},
+			Entry("empty status",
+				testCase{
+					status:   &fdbtypes.FoundationDBStatus{},
+					expected: "",
+				}),
+			Entry("with the active primary data center in the status",
+				testCase{
+					status: &fdbtypes.FoundationDBStatus{
+						Cluster: fdbtypes.FoundationDBStatusClusterInfo{
+							ActivePrimaryDC: "dc2",
+						},
+					},
+					expected: "dc2",
+				}),
+			Entry("with a master process",
+				testCase{
+					status: &fdbtypes.FoundationDBStatus{
+						Cluster: fdbtypes.FoundationDBStatusClusterInfo{
+							Processes: map[string]fdbtypes.FoundationDBStatusProcessInfo{
+								"foo": {
+									Locality: map[string]string{
+										fdbtypes.FDBLocalityDCIDKey: "dc1",
+									},
+									Roles: []fdbtypes.FoundationDBStatusProcessRoleInfo{
+										{
+											Role: "storage",
+										},
+									},
+								},
+								"bar": {
+									Locality: map[string]string{
+										fdbtypes.FDBLocalityDCIDKey: "dc3",
+									},
+									Roles: []fdbtypes.FoundationDBStatusProcessRoleInfo{
+										{
+											Role: "master",
+										},
+									},
+								},
+							},
+						},
+					},
+					expected: "dc3",
+				}),
+		)
+	})
 })
@@ -0,0 +1,194 @@
+/*
+ * kubernetes_monitor.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MonitorConfigurationFile defines the name of the file that the
+	// kubernetes monitor reads the process configuration from.
+	MonitorConfigurationFile = "config.json"
+
+	// unifiedImageBinaryPath defines the path of the fdbserver binary in the
+	// unified image.
+	unifiedImageBinaryPath = "/usr/bin/fdbserver"
+)
+
+// ProcessConfiguration models the configuration that the kubernetes monitor
+// uses to start the fdbserver processes in a pod.
+type ProcessConfiguration struct {
+	// Version defines the version of FoundationDB the processes should run.
+	Version string `json:"version"`
+
+	// ServerCount defines the number of fdbserver processes the monitor
+	// should start.
+	ServerCount int `json:"serverCount"`
+
+	// BinaryPath defines the path to the fdbserver binary.
+	BinaryPath string `json:"binaryPath,omitempty"`
+
+	// Arguments defines the arguments for the fdbserver processes.
+	Arguments []Argument `json:"arguments,omitempty"`
+}
+
+// ArgumentType defines how an argument is generated.
+type ArgumentType string
+
+const (
+	// ValueArgumentType defines an argument with a fixed value.
+	ValueArgumentType ArgumentType = "Value"
+
+	// ConcatenateArgumentType defines an argument that concatenates the
+	// values of a list of arguments.
+	ConcatenateArgumentType ArgumentType = "Concatenate"
+
+	// EnvironmentArgumentType defines an argument that takes its value from
+	// an environment variable.
+	EnvironmentArgumentType ArgumentType = "Environment"
+
+	// ProcessNumberArgumentType defines an argument that is calculated from
+	// the number of the process in the pod.
+	ProcessNumberArgumentType ArgumentType = "ProcessNumber"
+
+	// IPListArgumentType defines an argument that takes an IP address from a
+	// comma-separated list of addresses in an environment variable. IPv6
+	// addresses are enclosed in brackets so they can be used in a process
+	// address.
+	IPListArgumentType ArgumentType = "IPList"
+)
+
+// Argument models an argument to a fdbserver process.
+type Argument struct {
+	// ArgumentType determines how the value of the argument is generated.
+	// The default is Value.
+	ArgumentType ArgumentType `json:"type,omitempty"`
+
+	// Value defines the value for a Value argument.
+	Value string `json:"value,omitempty"`
+
+	// Values defines the arguments that are concatenated for a Concatenate
+	// argument.
+	Values []Argument `json:"values,omitempty"`
+
+	// Source defines the name of the environment variable for an Environment
+	// argument.
+	Source string `json:"source,omitempty"`
+
+	// Multiplier defines the value that the process number is multiplied
+	// with for a ProcessNumber argument. If this is not set the process
+	// number is not multiplied.
+	Multiplier int `json:"multiplier,omitempty"`
+
+	// Offset defines the value that is added to the process number for a
+	// ProcessNumber argument.
+	Offset int `json:"offset,omitempty"`
+
+	// IPFamily defines the family of the address that is chosen for an
+	// IPList argument. If this is not set, the first address in the list is
+	// used.
+	IPFamily int `json:"ipFamily,omitempty"`
+}
+
+// GenerateArgument generates the value of an argument for a process.
+func (argument Argument) GenerateArgument(processNumber int, env map[string]string) (string, error) {
+	switch argument.ArgumentType {
+	case "", ValueArgumentType:
+		return argument.Value, nil
+	case ConcatenateArgumentType:
+		var result strings.Builder
+		for _, childArgument := range argument.Values {
+			value, err := childArgument.GenerateArgument(processNumber, env)
+			if err != nil {
+				return "", err
+			}
+			result.WriteString(value)
+		}
+		return result.String(), nil
+	case EnvironmentArgumentType:
+		value, ok := env[argument.Source]
+		if !ok {
+			return "", fmt.Errorf("missing environment variable %s", argument.Source)
+		}
+		return value, nil
+	case ProcessNumberArgumentType:
+		multiplier := argument.Multiplier
+		if multiplier == 0 {
+			multiplier = 1
+		}
+		return strconv.Itoa(processNumber*multiplier + argument.Offset), nil
+	case IPListArgumentType:
+		value, ok := env[argument.Source]
+		if !ok {
+			return "", fmt.Errorf("missing environment variable %s", argument.Source)
+		}
+		return chooseIPAddress(value, argument.IPFamily)
+	default:
+		return "", fmt.Errorf("unsupported argument type %s", argument.ArgumentType)
+	}
+}
+
+// chooseIPAddress chooses the first address of an IP family from a
+// comma-separated list of addresses.
+func chooseIPAddress(addresses string, family int) (string, error) {
+	for _, address := range strings.Split(addresses, ",") {
+		ip := net.ParseIP(strings.Trim(strings.TrimSpace(address), "[]"))
+		if ip == nil {
+			return "", fmt.Errorf("failed to parse IP address %s", address)
+		}
+
+		isIPv4 := ip.To4() != nil
+		if (family == 4 && !isIPv4) || (family == 6 && isIPv4) {
+			continue
+		}
+
+		if isIPv4 {
+			return ip.String(), nil
+		}
+
+		return fmt.Sprintf("[%s]", ip.String()), nil
+	}
+
+	return "", fmt.Errorf("no IP address of family %d in %s", family, addresses)
+}
+
+// GenerateArguments generates the command line for a process, starting with
+// the binary path.
+func (configuration ProcessConfiguration) GenerateArguments(processNumber int, env map[string]string) ([]string, error) {
+	results := make([]string, 0, len(configuration.Arguments)+1)
+	if configuration.BinaryPath != "" {
+		results = append(results, configuration.BinaryPath)
+	}
+
+	for _, argument := range configuration.Arguments {
+		result, err := argument.GenerateArgument(processNumber, env)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,387 @@
+/*
+ * kubernetes_monitor_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"encoding/json"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("kubernetes_monitor", func() {
+	env := map[string]string{
+		"FDB_PUBLIC_IP":   "192.168.0.1",
+		"FDB_INSTANCE_ID": "storage-1",
+	}
+
+	DescribeTable("generating an argument",
+		func(argument Argument, processNumber int, expected string) {
+			result, err := argument.GenerateArgument(processNumber, env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("with a value",
+			Argument{Value: "storage"}, 1, "storage"),
+		Entry("with an explicit value type",
+			Argument{ArgumentType: ValueArgumentType, Value: "storage"}, 1, "storage"),
+		Entry("with an environment variable",
+			Argument{ArgumentType: EnvironmentArgumentType, Source: "FDB_INSTANCE_ID"}, 1, "storage-1"),
+		Entry("with the process number",
+			Argument{ArgumentType: ProcessNumberArgumentType}, 2, "2"),
+		Entry("with the process number with a multiplier and an offset",
+			Argument{ArgumentType: ProcessNumberArgumentType, Multiplier: 2, Offset: 4499}, 2, "4503"),
+		Entry("with a concatenation",
+			Argument{ArgumentType: ConcatenateArgumentType, Values: []Argument{
+				{ArgumentType: EnvironmentArgumentType, Source: "FDB_PUBLIC_IP"},
+				{Value: ":"},
+				{ArgumentType: ProcessNumberArgumentType, Multiplier: 2, Offset: 4499},
+			}}, 1, "192.168.0.1:4501"),
+		Entry("with an IP address",
+			Argument{ArgumentType: IPListArgumentType, Source: "FDB_PUBLIC_IP"}, 1, "192.168.0.1"),
+	)
+
+	DescribeTable("choosing an IP address",
+		func(addresses string, family int, expected string) {
+			result, err := chooseIPAddress(addresses, family)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(expected))
+		},
+		Entry("with an IPv6 address",
+			"fd00::1,10.1.0.1", 0, "[fd00::1]"),
+		Entry("with an IPv4 address of a family",
+			"fd00::1,10.1.0.1", 4, "10.1.0.1"),
+		Entry("with an IPv6 address of a family",
+			"10.1.0.1,fd00::1", 6, "[fd00::1]"),
+		Entry("with an address in brackets",
+			"[fd00::1]", 6, "[fd00::1]"),
+	)
+
+	When("the list does not contain an IP address of the family", func() {
+		It("should return an error", func() {
+			_, err := Argument{ArgumentType: IPListArgumentType, Source: "FDB_PUBLIC_IP", IPFamily: 6}.GenerateArgument(1, env)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the environment variable is missing", func() {
+		It("should return an error", func() {
+			_, err := Argument{ArgumentType: EnvironmentArgumentType, Source: "FDB_ZONE_ID"}.GenerateArgument(1, env)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("the argument type is unknown", func() {
+		It("should return an error", func() {
+			_, err := Argument{ArgumentType: "Random"}.GenerateArgument(1, env)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("generating the arguments for a process", func() {
+		It("should start with the binary path", func() {
+			configuration := ProcessConfiguration{
+				BinaryPath: "/usr/bin/fdbserver",
+				Arguments: []Argument{
+					{Value: "--class=storage"},
+					{ArgumentType: ConcatenateArgumentType, Values: []Argument{
+						{Value: "--datadir=/var/fdb/data/"},
+						{ArgumentType: ProcessNumberArgumentType},
+					}},
+				},
+			}
+
+			arguments, err := configuration.GenerateArguments(2, env)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(Equal([]string{"/usr/bin/fdbserver", "--class=storage", "--datadir=/var/fdb/data/2"}))
+		})
+	})
+
+	When("generating the process configuration with the host network", func() {
+		It("should use the port range of the process class", func() {
+			cluster := CreateDefaultCluster()
+			enabled := true
+			cluster.Spec.Routing.UseHostNetwork = &enabled
+			cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+			cluster.Status.RequiredAddresses.NonTLS = true
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuration, err := GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassLog, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			arguments, err := configuration.GenerateArguments(1, map[string]string{
+				"FDB_PUBLIC_IP":   "192.168.0.1",
+				"FDB_INSTANCE_ID": "log-1",
+				"FDB_MACHINE_ID":  "machine-1",
+				"FDB_ZONE_ID":     "zone-1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(ContainElement("--public_address=192.168.0.1:4601"))
+		})
+	})
+
+	When("generating the process configuration with a base port and a port stride", func() {
+		It("should use the base port and the port stride", func() {
+			cluster := CreateDefaultCluster()
+			basePort := 5000
+			stride := 4
+			cluster.Spec.Routing.BasePort = &basePort
+			cluster.Spec.Routing.PortStride = &stride
+			cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+			cluster.Status.RequiredAddresses.NonTLS = true
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuration, err := GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 2)
+			Expect(err).NotTo(HaveOccurred())
+
+			arguments, err := configuration.GenerateArguments(2, map[string]string{
+				"FDB_PUBLIC_IP":   "192.168.0.1",
+				"FDB_INSTANCE_ID": "storage-1",
+				"FDB_MACHINE_ID":  "machine-1",
+				"FDB_ZONE_ID":     "zone-1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(ContainElement("--public_address=192.168.0.1:5005"))
+		})
+	})
+
+	When("generating the process configuration with node ports", func() {
+		It("should advertise the node ports", func() {
+			cluster := CreateDefaultCluster()
+			source := fdbtypes.PublicIPSourceService
+			serviceType := corev1.ServiceTypeNodePort
+			cluster.Spec.Routing.PublicIPSource = &source
+			cluster.Spec.Routing.PublicServiceType = &serviceType
+			cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+			cluster.Status.RequiredAddresses.TLS = true
+			cluster.Status.RequiredAddresses.NonTLS = true
+			cluster.Status.HasListenIPsForAllPods = true
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuration, err := GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			arguments, err := configuration.GenerateArguments(1, map[string]string{
+				"FDB_PUBLIC_IP":     "192.168.0.1",
+				"FDB_POD_IP":        "10.1.0.1",
+				"FDB_NODE_PORT":     "30001",
+				"FDB_NODE_PORT_TLS": "30002",
+				"FDB_INSTANCE_ID":   "storage-1",
+				"FDB_MACHINE_ID":    "machine-1",
+				"FDB_ZONE_ID":       "zone-1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(ContainElement("--public_address=192.168.0.1:30002:tls,192.168.0.1:30001"))
+			Expect(arguments).To(ContainElement("--listen_address=10.1.0.1:4500:tls,10.1.0.1:4501"))
+		})
+	})
+
+	When("generating the process configuration for an IPv6 pod", func() {
+		It("should enclose the addresses in brackets", func() {
+			cluster := CreateDefaultCluster()
+			family := 6
+			cluster.Spec.Routing.PodIPFamily = &family
+			cluster.Status.ConnectionString = "operator-test:asdfasf@[fd00::2]:4501"
+			cluster.Status.RequiredAddresses.TLS = true
+			cluster.Status.RequiredAddresses.NonTLS = true
+			cluster.Status.HasListenIPsForAllPods = true
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuration, err := GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			arguments, err := configuration.GenerateArguments(1, map[string]string{
+				"FDB_PUBLIC_IP":   "10.1.0.1,fd00::1",
+				"FDB_POD_IP":      "10.1.0.1,fd00::1",
+				"FDB_INSTANCE_ID": "storage-1",
+				"FDB_MACHINE_ID":  "machine-1",
+				"FDB_ZONE_ID":     "zone-1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(ContainElement("--public_address=[fd00::1]:4500:tls,[fd00::1]:4501"))
+		})
+	})
+
+	When("generating the process configuration with database knobs", func() {
+		It("should enable the configuration database", func() {
+			cluster := CreateDefaultCluster()
+			cluster.Spec.Version = "7.1.0"
+			cluster.Spec.DatabaseKnobs = map[string]string{"max_trace_lines": "1000000"}
+			cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+			cluster.Status.RequiredAddresses.NonTLS = true
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuration, err := GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			arguments, err := configuration.GenerateArguments(1, map[string]string{
+				"FDB_PUBLIC_IP":   "192.168.0.1",
+				"FDB_INSTANCE_ID": "storage-1",
+				"FDB_MACHINE_ID":  "machine-1",
+				"FDB_ZONE_ID":     "zone-1",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(ContainElement("--config_db=simple"))
+		})
+	})
+
+	Describe("the annotation pod client", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var pod *corev1.Pod
+		var client *realFdbPodAnnotationClient
+		var configuration ProcessConfiguration
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+			imageType := fdbtypes.ImageTypeUnified
+			cluster.Spec.ImageType = &imageType
+			cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			pod = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "operator-test-1-storage-1", Namespace: "my-ns"}}
+			client = &realFdbPodAnnotationClient{Cluster: cluster, Pod: pod}
+
+			configuration, err = GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("the pod has no configuration annotation", func() {
+			It("should not match the configuration", func() {
+				data, err := json.Marshal(configuration)
+				Expect(err).NotTo(HaveOccurred())
+				match, err := client.CheckHash(MonitorConfigurationFile, string(data))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(match).To(BeFalse())
+			})
+
+			It("should treat other files as up to date", func() {
+				match, err := client.CheckHash("fdb.cluster", cluster.Status.ConnectionString)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(match).To(BeTrue())
+			})
+		})
+
+		When("the pod has the current configuration", func() {
+			BeforeEach(func() {
+				data, err := json.Marshal(configuration)
+				Expect(err).NotTo(HaveOccurred())
+				pod.Annotations = map[string]string{fdbtypes.CurrentConfigurationAnnotation: string(data)}
+			})
+
+			It("should match the configuration", func() {
+				data, err := json.Marshal(configuration)
+				Expect(err).NotTo(HaveOccurred())
+				match, err := client.CheckHash(MonitorConfigurationFile, string(data))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(match).To(BeTrue())
+			})
+
+			It("should not match a different configuration", func() {
+				configuration.ServerCount = 2
+				data, err := json.Marshal(configuration)
+				Expect(err).NotTo(HaveOccurred())
+				match, err := client.CheckHash(MonitorConfigurationFile, string(data))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(match).To(BeFalse())
+			})
+		})
+
+		When("the pod has the environment annotation", func() {
+			BeforeEach(func() {
+				pod.Annotations = map[string]string{fdbtypes.EnvironmentAnnotation: `{"FDB_PUBLIC_IP":"192.168.0.1","FDB_INSTANCE_ID":"storage-1"}`}
+			})
+
+			It("should return the substitutions", func() {
+				substitutions, err := client.GetVariableSubstitutions()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(substitutions).To(Equal(env))
+			})
+		})
+
+		When("the pod has no environment annotation", func() {
+			It("should return an error", func() {
+				_, err := client.GetVariableSubstitutions()
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	When("generating the process configuration with environment variables in the custom parameters", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var configuration ProcessConfiguration
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+			cluster.Status.ConnectionString = "operator-test:asdfasf@127.0.0.1:4501"
+			cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{fdbtypes.ProcessClassGeneral: {
+				CustomParameters: &[]string{"tls_password = $FDB_TLS_PASSWORD", "knob_custom_path=/var/$FDB_INSTANCE_ID/data"},
+			}}
+			err := NormalizeClusterSpec(cluster, DeprecationOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			configuration, err = GetMonitorProcessConfiguration(cluster, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should resolve the variables from the environment", func() {
+			arguments, err := configuration.GenerateArguments(1, map[string]string{
+				"FDB_PUBLIC_IP":    "192.168.0.1",
+				"FDB_INSTANCE_ID":  "storage-1",
+				"FDB_MACHINE_ID":   "machine-1",
+				"FDB_ZONE_ID":      "zone-1",
+				"FDB_TLS_PASSWORD": "secret",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(arguments).To(ContainElement("--tls_password=secret"))
+			Expect(arguments).To(ContainElement("--knob_custom_path=/var/storage-1/data"))
+		})
+
+		It("should return the referenced variables", func() {
+			Expect(getCustomParameterVariables(cluster, fdbtypes.ProcessClassStorage)).To(Equal([]string{"FDB_TLS_PASSWORD", "FDB_INSTANCE_ID"}))
+		})
+	})
+
+	DescribeTable("matching a command line",
+		func(expected string, actual string, match bool) {
+			Expect(CommandLineMatches(expected, actual)).To(Equal(match))
+		},
+		Entry("with an identical command line",
+			"/usr/bin/fdbserver --class=storage", "/usr/bin/fdbserver --class=storage", true),
+		Entry("with a different command line",
+			"/usr/bin/fdbserver --class=storage", "/usr/bin/fdbserver --class=log", false),
+		Entry("with an unresolved variable",
+			"/usr/bin/fdbserver --class=storage --tls_password=$FDB_TLS_PASSWORD", "/usr/bin/fdbserver --class=storage --tls_password=secret", true),
+		Entry("with an unresolved variable and a different argument",
+			"/usr/bin/fdbserver --class=storage --tls_password=$FDB_TLS_PASSWORD", "/usr/bin/fdbserver --class=log --tls_password=secret", false),
+		Entry("with an unresolved variable that spans several arguments",
+			"/usr/bin/fdbserver --tls_password=$FDB_TLS_PASSWORD", "/usr/bin/fdbserver --tls_password=secret --class=log", false),
+	)
+})
@@ -29,8 +29,16 @@ import (
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 )
 
+// configurationDatabaseType is the type of configuration database that the
+// fdbserver processes use when the operator manages database knobs.
+const configurationDatabaseType = "simple"
+
 // GetStartCommand builds the expected start command for an instance.
 func GetStartCommand(cluster *fdbtypes.FoundationDBCluster, processCless fdbtypes.ProcessClass, podClient FdbPodClient, processNumber int, processCount int) (string, error) {
+	if cluster.UseUnifiedImage() {
+		return getUnifiedStartCommand(cluster, processCless, podClient, processNumber, processCount)
+	}
+
 	lines, err := getStartCommandLines(cluster, processCless, podClient, processNumber, processCount)
 	if err != nil {
 		return "", err
@@ -53,6 +61,40 @@ func GetStartCommand(cluster *fdbtypes.FoundationDBCluster, processCless fdbtype
 	return command, nil
 }
 
+// getUnifiedStartCommand builds the expected start command for an instance
+// that runs in the unified image.
+func getUnifiedStartCommand(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, podClient FdbPodClient, processNumber int, processCount int) (string, error) {
+	configuration, err := GetMonitorProcessConfiguration(cluster, processClass, processCount)
+	if err != nil {
+		return "", err
+	}
+
+	if podClient == nil {
+		return "", fmt.Errorf("cannot generate the start command for the unified image without a pod client")
+	}
+
+	substitutions, err := podClient.GetVariableSubstitutions()
+	if err != nil {
+		return "", err
+	}
+
+	// The pod does not report the variables that are only used in custom
+	// parameters, like secrets, so these stay unresolved in the expected
+	// command line.
+	for _, variable := range getCustomParameterVariables(cluster, processClass) {
+		if _, ok := substitutions[variable]; !ok {
+			substitutions[variable] = "$" + variable
+		}
+	}
+
+	arguments, err := configuration.GenerateArguments(processNumber, substitutions)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join(arguments, " "), nil
+}
+
 // GetMonitorConf builds the monitor conf template
 func GetMonitorConf(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, podClient FdbPodClient, serversPerPod int) (string, error) {
 	if cluster.Status.ConnectionString == "" {
@@ -60,11 +102,7 @@ func GetMonitorConf(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes
 	}
 
 	confLines := make([]string, 0, 20)
-	confLines = append(confLines,
-		"[general]",
-		"kill_on_configuration_change = false",
-		"restart_delay = 60",
-	)
+	confLines = append(confLines, getMonitorConfGeneralLines(cluster)...)
 
 	// Don't instantiate any servers if the `EmptyMonitorConf` buggify option is engaged.
 	if !cluster.Spec.Buggify.EmptyMonitorConf {
@@ -81,6 +119,331 @@ func GetMonitorConf(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes
 	return strings.Join(confLines, "\n"), nil
 }
 
+// getMonitorConfGeneralLines builds the general section of the monitor conf.
+func getMonitorConfGeneralLines(cluster *fdbtypes.FoundationDBCluster) []string {
+	options := cluster.Spec.ProcessRestartOptions
+	lines := []string{
+		"[general]",
+		"kill_on_configuration_change = false",
+		fmt.Sprintf("restart_delay = %d", cluster.GetRestartDelaySeconds()),
+	}
+
+	if options.InitialRestartDelaySeconds != nil {
+		lines = append(lines, fmt.Sprintf("initial_restart_delay = %d", *options.InitialRestartDelaySeconds))
+	}
+
+	if options.RestartBackoff != nil {
+		lines = append(lines, fmt.Sprintf("restart_backoff = %d", *options.RestartBackoff))
+	}
+
+	if options.RestartDelayResetIntervalSeconds != nil {
+		lines = append(lines, fmt.Sprintf("restart_delay_reset_interval = %d", *options.RestartDelayResetIntervalSeconds))
+	}
+
+	return lines
+}
+
+// GetProcessRestartOptionsHash returns the hash of the process restart options
+// in the cluster spec, or an empty string if no options are set.
+func GetProcessRestartOptionsHash(cluster *fdbtypes.FoundationDBCluster) (string, error) {
+	if cluster.Spec.ProcessRestartOptions == (fdbtypes.ProcessRestartOptions{}) {
+		return "", nil
+	}
+
+	return GetJSONHash(cluster.Spec.ProcessRestartOptions)
+}
+
+// GetMonitorProcessConfiguration builds the process configuration for the
+// kubernetes monitor in the unified image. The arguments are sorted by name,
+// so that the generated command line matches the one from GetStartCommand.
+func GetMonitorProcessConfiguration(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, serversPerPod int) (ProcessConfiguration, error) {
+	versionString := cluster.Status.RunningVersion
+	if versionString == "" {
+		versionString = cluster.Spec.Version
+	}
+
+	configuration := ProcessConfiguration{
+		Version:    versionString,
+		BinaryPath: unifiedImageBinaryPath,
+	}
+
+	if cluster.Status.ConnectionString == "" || cluster.Spec.Buggify.EmptyMonitorConf {
+		return configuration, nil
+	}
+
+	configuration.ServerCount = serversPerPod
+
+	logGroup := cluster.Spec.LogGroup
+	if logGroup == "" {
+		logGroup = cluster.Name
+	}
+
+	zoneVariable := "FDB_ZONE_ID"
+	if strings.HasPrefix(cluster.Spec.FaultDomain.ValueFrom, "$") {
+		zoneVariable = strings.TrimPrefix(cluster.Spec.FaultDomain.ValueFrom, "$")
+	}
+
+	arguments := map[string]Argument{
+		"cluster_file":         {Value: "/var/fdb/data/fdb.cluster"},
+		"seed_cluster_file":    {Value: "/var/dynamic-conf/fdb.cluster"},
+		"public_address":       getPublicAddressListArgument(cluster, processClass),
+		"class":                {Value: string(processClass)},
+		"logdir":               {Value: cluster.GetTraceLogDirectory()},
+		"loggroup":             {Value: logGroup},
+		"locality_instance_id": {ArgumentType: EnvironmentArgumentType, Source: "FDB_INSTANCE_ID"},
+		"locality_machineid":   {ArgumentType: EnvironmentArgumentType, Source: "FDB_MACHINE_ID"},
+		"locality_zoneid":      {ArgumentType: EnvironmentArgumentType, Source: zoneVariable},
+	}
+
+	if cluster.Spec.TraceLogs.Format != "" {
+		arguments["trace_format"] = Argument{Value: cluster.Spec.TraceLogs.Format}
+	}
+
+	if cluster.Spec.TraceLogs.RollSizeBytes != nil {
+		arguments["logsize"] = Argument{Value: fmt.Sprintf("%d", *cluster.Spec.TraceLogs.RollSizeBytes)}
+	}
+
+	if cluster.Spec.TraceLogs.MaxLogsSizeBytes != nil {
+		arguments["maxlogssize"] = Argument{Value: fmt.Sprintf("%d", *cluster.Spec.TraceLogs.MaxLogsSizeBytes)}
+	}
+
+	if serversPerPod <= 1 {
+		arguments["datadir"] = Argument{Value: "/var/fdb/data"}
+	} else {
+		arguments["datadir"] = Argument{ArgumentType: ConcatenateArgumentType, Values: []Argument{
+			{Value: "/var/fdb/data/"},
+			{ArgumentType: ProcessNumberArgumentType},
+		}}
+		arguments["locality_process_id"] = Argument{ArgumentType: ConcatenateArgumentType, Values: []Argument{
+			{ArgumentType: EnvironmentArgumentType, Source: "FDB_INSTANCE_ID"},
+			{Value: "-"},
+			{ArgumentType: ProcessNumberArgumentType},
+		}}
+	}
+
+	if cluster.Spec.DataCenter != "" {
+		arguments["locality_dcid"] = Argument{Value: cluster.Spec.DataCenter}
+	}
+
+	if cluster.Spec.DataHall != "" {
+		arguments["locality_data_hall"] = Argument{Value: cluster.Spec.DataHall}
+	}
+
+	if cluster.Spec.MainContainer.PeerVerificationRules != "" {
+		arguments["tls_verify_peers"] = Argument{Value: cluster.Spec.MainContainer.PeerVerificationRules}
+	}
+
+	if cluster.NeedsExplicitListenAddress() && cluster.Status.HasListenIPsForAllPods {
+		arguments["listen_address"] = getAddressListArgument(cluster, processClass, "FDB_POD_IP")
+	}
+
+	if cluster.UsesConfigurationDatabase() {
+		arguments["config_db"] = Argument{Value: configurationDatabaseType}
+	}
+
+	podSettings := cluster.GetProcessSettings(processClass)
+
+	if podSettings.CustomParameters != nil {
+		regex := regexp.MustCompile(`^(\w+)\s*=\s*(.*)`)
+		for _, customParameter := range *podSettings.CustomParameters {
+			components := regex.FindStringSubmatch(customParameter)
+			if components == nil {
+				return configuration, fmt.Errorf("invalid custom parameter %s", customParameter)
+			}
+			arguments[components[1]] = getCustomParameterArgument(components[2])
+		}
+	}
+
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	configuration.Arguments = make([]Argument, 0, len(names))
+	for _, name := range names {
+		configuration.Arguments = append(configuration.Arguments, Argument{ArgumentType: ConcatenateArgumentType, Values: []Argument{
+			{Value: fmt.Sprintf("--%s=", name)},
+			arguments[name],
+		}})
+	}
+
+	return configuration, nil
+}
+
+// customParameterVariableRegex matches the environment variables that are
+// referenced in a custom parameter.
+var customParameterVariableRegex = regexp.MustCompile(`\$(\w+)`)
+
+// getCustomParameterArgument builds the argument for the value of a custom
+// parameter. Environment variables in the value are resolved by the
+// kubernetes monitor, the same way fdbmonitor resolves them.
+func getCustomParameterArgument(value string) Argument {
+	matches := customParameterVariableRegex.FindAllStringSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return Argument{Value: value}
+	}
+
+	values := make([]Argument, 0, 2*len(matches)+1)
+	start := 0
+	for _, match := range matches {
+		if match[0] > start {
+			values = append(values, Argument{Value: value[start:match[0]]})
+		}
+		values = append(values, Argument{ArgumentType: EnvironmentArgumentType, Source: value[match[2]:match[3]]})
+		start = match[1]
+	}
+
+	if start < len(value) {
+		values = append(values, Argument{Value: value[start:]})
+	}
+
+	return Argument{ArgumentType: ConcatenateArgumentType, Values: values}
+}
+
+// getCustomParameterVariables returns the environment variables that are
+// referenced in the custom parameters for a process class.
+func getCustomParameterVariables(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) []string {
+	podSettings := cluster.GetProcessSettings(processClass)
+	if podSettings.CustomParameters == nil {
+		return nil
+	}
+
+	variables := make([]string, 0)
+	for _, customParameter := range *podSettings.CustomParameters {
+		for _, match := range customParameterVariableRegex.FindAllStringSubmatch(customParameter, -1) {
+			variables = append(variables, match[1])
+		}
+	}
+
+	return variables
+}
+
+// CommandLineMatches determines if the command line of a running process
+// matches the expected command line. Environment variables that are still
+// present in the expected command line could not be resolved by the
+// operator, because they are only known to the process, so they match any
+// value.
+func CommandLineMatches(expected string, actual string) bool {
+	matches := customParameterVariableRegex.FindAllStringIndex(expected, -1)
+	if len(matches) == 0 {
+		return expected == actual
+	}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	start := 0
+	for _, match := range matches {
+		pattern.WriteString(regexp.QuoteMeta(expected[start:match[0]]))
+		pattern.WriteString(`\S*`)
+		start = match[1]
+	}
+	pattern.WriteString(regexp.QuoteMeta(expected[start:]))
+	pattern.WriteString("$")
+
+	regex, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return false
+	}
+
+	return regex.MatchString(actual)
+}
+
+// getPublicAddressListArgument builds the argument for the public addresses
+// of a process. Processes that use node ports advertise the node port from
+// their environment instead of the port they listen on.
+func getPublicAddressListArgument(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) Argument {
+	if !cluster.UsesNodePorts(processClass) {
+		return getAddressListArgument(cluster, processClass, "FDB_PUBLIC_IP")
+	}
+
+	addresses := make([]Argument, 0, 8)
+	addAddress := func(tls bool) {
+		if len(addresses) > 0 {
+			addresses = append(addresses, Argument{Value: ","})
+		}
+
+		portVariable := "FDB_NODE_PORT"
+		if tls {
+			portVariable = "FDB_NODE_PORT_TLS"
+		}
+
+		addresses = append(addresses,
+			getIPArgument(cluster, "FDB_PUBLIC_IP"),
+			Argument{Value: ":"},
+			Argument{ArgumentType: EnvironmentArgumentType, Source: portVariable},
+		)
+
+		if tls {
+			addresses = append(addresses, Argument{Value: ":tls"})
+		}
+	}
+
+	if cluster.Status.RequiredAddresses.TLS {
+		addAddress(true)
+	}
+
+	if cluster.Status.RequiredAddresses.NonTLS {
+		addAddress(false)
+	}
+
+	return Argument{ArgumentType: ConcatenateArgumentType, Values: addresses}
+}
+
+// getAddressListArgument builds the argument for the addresses of a process
+// with the IP from the provided environment variable.
+func getAddressListArgument(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, ipVariable string) Argument {
+	addresses := make([]Argument, 0, 8)
+	addAddress := func(tls bool) {
+		if len(addresses) > 0 {
+			addresses = append(addresses, Argument{Value: ","})
+		}
+
+		// The port is calculated the same way as in GetProcessPortForClass.
+		portStride := cluster.GetPortStride()
+		offset := cluster.GetProcessPortForClass(processClass, 1, tls) - portStride
+
+		addresses = append(addresses,
+			getIPArgument(cluster, ipVariable),
+			Argument{Value: ":"},
+			Argument{ArgumentType: ProcessNumberArgumentType, Multiplier: portStride, Offset: offset},
+		)
+
+		if tls {
+			addresses = append(addresses, Argument{Value: ":tls"})
+		}
+	}
+
+	if cluster.Status.RequiredAddresses.TLS {
+		addAddress(true)
+	}
+
+	if cluster.Status.RequiredAddresses.NonTLS {
+		addAddress(false)
+	}
+
+	return Argument{ArgumentType: ConcatenateArgumentType, Values: addresses}
+}
+
+// getIPArgument builds the argument for the IP address of a process from the
+// provided environment variable. The pod IPs contain an address for every
+// IP family, so these are filtered by the podIPFamily.
+func getIPArgument(cluster *fdbtypes.FoundationDBCluster, ipVariable string) Argument {
+	argument := Argument{ArgumentType: IPListArgumentType, Source: ipVariable}
+
+	family := cluster.Spec.Routing.PodIPFamily
+	if family == nil {
+		return argument
+	}
+
+	source := cluster.GetPublicIPSource()
+	if ipVariable == "FDB_POD_IP" || (source != fdbtypes.PublicIPSourceService && source != fdbtypes.PublicIPSourceNode) {
+		argument.IPFamily = *family
+	}
+
+	return argument
+}
+
 func getStartCommandLines(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, podClient FdbPodClient, processNumber int, processCount int) ([]string, error) {
 	confLines := make([]string, 0, 20)
 
@@ -125,11 +488,23 @@ func getStartCommandLines(cluster *fdbtypes.FoundationDBCluster, processClass fd
 		fmt.Sprintf("command = %s/fdbserver", binaryDir),
 		"cluster_file = /var/fdb/data/fdb.cluster",
 		"seed_cluster_file = /var/dynamic-conf/fdb.cluster",
-		fmt.Sprintf("public_address = %s", fdbtypes.ProcessAddressesString(cluster.GetFullAddressList("$FDB_PUBLIC_IP", false, processNumber), ",")),
+		fmt.Sprintf("public_address = %s", getPublicAddressList(cluster, processClass, processNumber)),
 		fmt.Sprintf("class = %s", processClass),
-		"logdir = /var/log/fdb-trace-logs",
+		fmt.Sprintf("logdir = %s", cluster.GetTraceLogDirectory()),
 		fmt.Sprintf("loggroup = %s", logGroup))
 
+	if cluster.Spec.TraceLogs.Format != "" {
+		confLines = append(confLines, fmt.Sprintf("trace_format = %s", cluster.Spec.TraceLogs.Format))
+	}
+
+	if cluster.Spec.TraceLogs.RollSizeBytes != nil {
+		confLines = append(confLines, fmt.Sprintf("logsize = %d", *cluster.Spec.TraceLogs.RollSizeBytes))
+	}
+
+	if cluster.Spec.TraceLogs.MaxLogsSizeBytes != nil {
+		confLines = append(confLines, fmt.Sprintf("maxlogssize = %d", *cluster.Spec.TraceLogs.MaxLogsSizeBytes))
+	}
+
 	if processCount <= 1 {
 		confLines = append(confLines, "datadir = /var/fdb/data")
 	} else {
@@ -154,7 +529,11 @@ func getStartCommandLines(cluster *fdbtypes.FoundationDBCluster, processClass fd
 	}
 
 	if cluster.NeedsExplicitListenAddress() && cluster.Status.HasListenIPsForAllPods {
-		confLines = append(confLines, fmt.Sprintf("listen_address = %s", fdbtypes.ProcessAddressesString(cluster.GetFullAddressList("$FDB_POD_IP", false, processNumber), ",")))
+		confLines = append(confLines, fmt.Sprintf("listen_address = %s", fdbtypes.ProcessAddressesString(cluster.GetFullAddressList("$FDB_POD_IP", false, processClass, processNumber), ",")))
+	}
+
+	if cluster.UsesConfigurationDatabase() {
+		confLines = append(confLines, fmt.Sprintf("config_db = %s", configurationDatabaseType))
 	}
 
 	podSettings := cluster.GetProcessSettings(processClass)
@@ -170,3 +549,23 @@ func getStartCommandLines(cluster *fdbtypes.FoundationDBCluster, processClass fd
 	}
 	return confLines, nil
 }
+
+// getPublicAddressList builds the list of public addresses for a process in
+// the monitor conf. Processes that use node ports advertise the node port
+// from their environment instead of the port they listen on.
+func getPublicAddressList(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, processNumber int) string {
+	if !cluster.UsesNodePorts(processClass) {
+		return fdbtypes.ProcessAddressesString(cluster.GetFullAddressList("$FDB_PUBLIC_IP", false, processClass, processNumber), ",")
+	}
+
+	addresses := make([]string, 0, 2)
+	if cluster.Status.RequiredAddresses.TLS {
+		addresses = append(addresses, "$FDB_PUBLIC_IP:$FDB_NODE_PORT_TLS:tls")
+	}
+
+	if cluster.Status.RequiredAddresses.NonTLS {
+		addresses = append(addresses, "$FDB_PUBLIC_IP:$FDB_NODE_PORT")
+	}
+
+	return strings.Join(addresses, ",")
+}
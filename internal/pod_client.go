@@ -32,6 +32,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"reflect"
 	"strings"
 	"time"
 
@@ -44,6 +45,9 @@ const (
 	// MockUnreachableAnnotation defines if a Pod should be unreachable. This annotation
 	// is currently only used for testing cases.
 	MockUnreachableAnnotation = "foundationdb.org/mock-unreachable"
+
+	// SidecarPort defines the port on which the sidecar serves its API.
+	SidecarPort = 8080
 )
 
 // FdbPodClient provides methods for working with a FoundationDB pod
@@ -60,6 +64,10 @@ type FdbPodClient interface {
 	// CheckHash checks whether a file in the sidecar has the expected contents.
 	CheckHash(filename string, contents string) (bool, error)
 
+	// GetFileHash returns the hex-encoded SHA-256 checksum of a file in the
+	// sidecar.
+	GetFileHash(filename string) (string, error)
+
 	// GenerateMonitorConf updates the monitor conf file for a pod
 	GenerateMonitorConf() error
 
@@ -93,6 +101,11 @@ func NewFdbPodClient(cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod) (Fd
 	if pod.Status.PodIP == "" {
 		return nil, fmt.Errorf("waiting for pod %s/%s/%s to be assigned an IP", cluster.Namespace, cluster.Name, pod.Name)
 	}
+
+	if cluster.UseUnifiedImage() {
+		return &realFdbPodAnnotationClient{Cluster: cluster, Pod: pod}, nil
+	}
+
 	for _, container := range pod.Status.ContainerStatuses {
 		if container.Name == "foundationdb-kubernetes-sidecar" && !container.Ready {
 			return nil, fmt.Errorf("waiting for pod %s/%s/%s to be ready", cluster.Namespace, cluster.Name, pod.Name)
@@ -172,7 +185,7 @@ func (client *realFdbPodClient) makeRequest(method string, path string) (string,
 		protocol = "https"
 	}
 
-	url := fmt.Sprintf("%s://%s:8080/%s", protocol, client.getListenIP(), path)
+	url := fmt.Sprintf("%s://%s:%d/%s", protocol, client.getListenIP(), SidecarPort, path)
 	switch method {
 	case http.MethodGet:
 		// We assume that a get request should be relative fast.
@@ -224,6 +237,17 @@ func (client *realFdbPodClient) CheckHash(filename string, contents string) (boo
 	return strings.Compare(expectedHashString, response) == 0, nil
 }
 
+// GetFileHash returns the hex-encoded SHA-256 checksum of a file in the
+// sidecar.
+func (client *realFdbPodClient) GetFileHash(filename string) (string, error) {
+	response, err := client.makeRequest("GET", fmt.Sprintf("check_hash/%s", filename))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(response), nil
+}
+
 // GenerateMonitorConf updates the monitor conf file for a pod
 func (client *realFdbPodClient) GenerateMonitorConf() error {
 	_, err := client.makeRequest("POST", "copy_monitor_conf")
@@ -249,6 +273,94 @@ func (client *realFdbPodClient) GetVariableSubstitutions() (map[string]string, e
 	return substitutions, err
 }
 
+// realFdbPodAnnotationClient provides a client for pods that use the unified
+// image. The kubernetes monitor in these pods reports its state through
+// annotations on the pod, so this client does not connect to the pod.
+type realFdbPodAnnotationClient struct {
+	// Cluster is the cluster the pod belongs to.
+	Cluster *fdbtypes.FoundationDBCluster
+
+	// Pod is the pod we are checking.
+	Pod *corev1.Pod
+}
+
+// GetCluster returns the cluster associated with a client
+func (client *realFdbPodAnnotationClient) GetCluster() *fdbtypes.FoundationDBCluster {
+	return client.Cluster
+}
+
+// GetPod returns the pod associated with a client
+func (client *realFdbPodAnnotationClient) GetPod() *corev1.Pod {
+	return client.Pod
+}
+
+// IsPresent checks whether a file in the pod is present. The unified image
+// contains all binaries, so this always returns true.
+func (client *realFdbPodAnnotationClient) IsPresent(filename string) (bool, error) {
+	return true, nil
+}
+
+// CheckHash checks whether a file in the pod has the expected contents. For
+// the process configuration this compares the contents with the
+// configuration the kubernetes monitor has loaded. All other files are
+// mounted from the ConfigMap.
+func (client *realFdbPodAnnotationClient) CheckHash(filename string, contents string) (bool, error) {
+	if filename != MonitorConfigurationFile {
+		return true, nil
+	}
+
+	currentData, ok := client.Pod.Annotations[fdbtypes.CurrentConfigurationAnnotation]
+	if !ok {
+		return false, nil
+	}
+
+	currentConfiguration := ProcessConfiguration{}
+	err := json.Unmarshal([]byte(currentData), &currentConfiguration)
+	if err != nil {
+		return false, err
+	}
+
+	desiredConfiguration := ProcessConfiguration{}
+	err = json.Unmarshal([]byte(contents), &desiredConfiguration)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(currentConfiguration, desiredConfiguration), nil
+}
+
+// GetFileHash returns the checksum of a file in the pod. The unified image
+// does not copy binaries into a shared volume, so this is not supported.
+func (client *realFdbPodAnnotationClient) GetFileHash(filename string) (string, error) {
+	return "", fmt.Errorf("checking the hash of %s is not supported with the unified image", filename)
+}
+
+// GenerateMonitorConf updates the monitor conf file for a pod. The kubernetes
+// monitor reads the configuration directly from the ConfigMap, so this is a
+// no-op.
+func (client *realFdbPodAnnotationClient) GenerateMonitorConf() error {
+	return nil
+}
+
+// CopyFiles copies the files from the config map to the shared dynamic conf
+// volume. The ConfigMap is mounted directly, so this is a no-op.
+func (client *realFdbPodAnnotationClient) CopyFiles() error {
+	return nil
+}
+
+// GetVariableSubstitutions gets the current keys and values that this
+// instance will substitute into its process arguments.
+func (client *realFdbPodAnnotationClient) GetVariableSubstitutions() (map[string]string, error) {
+	environmentData, ok := client.Pod.Annotations[fdbtypes.EnvironmentAnnotation]
+	if !ok {
+		return nil, fmt.Errorf("pod %s/%s does not have the %s annotation", client.Pod.Namespace, client.Pod.Name, fdbtypes.EnvironmentAnnotation)
+	}
+
+	substitutions := map[string]string{}
+	err := json.Unmarshal([]byte(environmentData), &substitutions)
+	return substitutions, err
+}
+
 // MockFdbPodClient provides a mock connection to a pod
 type mockFdbPodClient struct {
 	Cluster *fdbtypes.FoundationDBCluster
@@ -280,6 +392,14 @@ func (client *mockFdbPodClient) CheckHash(filename string, contents string) (boo
 	return true, nil
 }
 
+// GetFileHash returns the hex-encoded SHA-256 checksum of a file in the
+// sidecar. The mock returns the checksum of the file name, so tests can
+// configure matching checksums.
+func (client *mockFdbPodClient) GetFileHash(filename string) (string, error) {
+	hash := sha256.Sum256([]byte(filename))
+	return hex.EncodeToString(hash[:]), nil
+}
+
 // GenerateMonitorConf updates the monitor conf file for a pod
 func (client *mockFdbPodClient) GenerateMonitorConf() error {
 	return nil
@@ -339,6 +459,28 @@ func CheckDynamicFilePresent(client FdbPodClient, filename string) (bool, error)
 	return present, err
 }
 
+// CheckDynamicFileChecksum checks whether a file in the dynamic conf has the
+// expected hex-encoded SHA-256 checksum.
+func CheckDynamicFileChecksum(client FdbPodClient, filename string, checksum string) (bool, error) {
+	hash, err := client.GetFileHash(filename)
+	if err != nil {
+		return false, err
+	}
+
+	if hash != checksum {
+		log.Info("Checksum mismatch for file",
+			"namespace", client.GetCluster().Namespace,
+			"cluster", client.GetCluster().Name,
+			"pod", client.GetPod().Name,
+			"file", filename,
+			"expected", checksum,
+			"actual", hash)
+		return false, nil
+	}
+
+	return true, nil
+}
+
 // GetVariableSubstitutions gets the current keys and values that this
 // instance will substitute into its monitor conf.
 func (client *mockFdbPodClient) GetVariableSubstitutions() (map[string]string, error) {
@@ -351,6 +493,14 @@ func (client *mockFdbPodClient) GetVariableSubstitutions() (map[string]string, e
 	}
 
 	ipString := GetPublicIPsForPod(client.Pod)[0]
+	if (client.Cluster.GetPublicIPSource() == fdbtypes.PublicIPSourceNode || UsesNodePorts(client.Pod)) && client.Pod.Status.HostIP != "" {
+		ipString = client.Pod.Status.HostIP
+	}
+
+	if UsesNodePorts(client.Pod) {
+		substitutions["FDB_NODE_PORT"] = client.Pod.Annotations[fdbtypes.NodePortAnnotation]
+		substitutions["FDB_NODE_PORT_TLS"] = client.Pod.Annotations[fdbtypes.NodePortTLSAnnotation]
+	}
 	substitutions["FDB_PUBLIC_IP"] = ipString
 	if ipString != "" {
 		ip := net.ParseIP(ipString)
@@ -363,6 +513,19 @@ func (client *mockFdbPodClient) GetVariableSubstitutions() (map[string]string, e
 		}
 	}
 
+	if client.Cluster.NeedsExplicitListenAddress() && client.Pod.Status.PodIP != "" {
+		podIP := net.ParseIP(client.Pod.Status.PodIP)
+		if podIP == nil {
+			return nil, fmt.Errorf("failed to parse IP from pod: %s", client.Pod.Status.PodIP)
+		}
+
+		if podIP.To4() == nil {
+			substitutions["FDB_POD_IP"] = fmt.Sprintf("[%s]", podIP.String())
+		} else {
+			substitutions["FDB_POD_IP"] = podIP.String()
+		}
+	}
+
 	if client.Cluster.Spec.FaultDomain.Key == "foundationdb.org/none" {
 		substitutions["FDB_MACHINE_ID"] = client.Pod.Name
 		substitutions["FDB_ZONE_ID"] = client.Pod.Name
@@ -21,9 +21,14 @@
 package internal
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 var _ = Describe("pod_client", func() {
@@ -58,4 +63,30 @@ var _ = Describe("pod_client", func() {
 			Expect(podHasSidecarTLS(pod)).To(BeTrue())
 		})
 	})
+
+	When("checking the checksum of a file", func() {
+		var client FdbPodClient
+		var checksum string
+
+		BeforeEach(func() {
+			var err error
+			client, err = NewMockFdbPodClient(cluster, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "storage-1"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			hash := sha256.Sum256([]byte("bin/6.2.20/fdbserver"))
+			checksum = hex.EncodeToString(hash[:])
+		})
+
+		It("should match the expected checksum", func() {
+			match, err := CheckDynamicFileChecksum(client, "bin/6.2.20/fdbserver", checksum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(match).To(BeTrue())
+		})
+
+		It("should not match a different checksum", func() {
+			match, err := CheckDynamicFileChecksum(client, "bin/6.2.20/fdbcli", checksum)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(match).To(BeFalse())
+		})
+	})
 })
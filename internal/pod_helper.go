@@ -174,6 +174,52 @@ func BuildOwnerReference(ownerType metav1.TypeMeta, ownerMetadata metav1.ObjectM
 	}}
 }
 
+// ReleaseFromCluster removes the labels that the operator uses to find the
+// resources of a cluster and the owner reference to the cluster from an
+// object, and marks the object as retained from the cluster.
+func ReleaseFromCluster(cluster *fdbtypes.FoundationDBCluster, object metav1.Object) {
+	labels := object.GetLabels()
+	processGroupID := labels[cluster.GetProcessGroupIDLabel()]
+
+	for key := range cluster.Spec.LabelConfig.MatchLabels {
+		delete(labels, key)
+	}
+	for key := range cluster.Spec.LabelConfig.ResourceLabels {
+		delete(labels, key)
+	}
+	for _, key := range cluster.Spec.LabelConfig.ProcessClassLabels {
+		delete(labels, key)
+	}
+	for _, key := range cluster.Spec.LabelConfig.ProcessGroupIDLabels {
+		delete(labels, key)
+	}
+	delete(labels, cluster.GetProcessClassLabel())
+	delete(labels, cluster.GetProcessGroupIDLabel())
+
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[fdbtypes.RetainedFromClusterLabel] = cluster.Name
+	object.SetLabels(labels)
+
+	if processGroupID != "" {
+		annotations := object.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		annotations[fdbtypes.RetainedProcessGroupIDAnnotation] = processGroupID
+		object.SetAnnotations(annotations)
+	}
+
+	ownerReferences := make([]metav1.OwnerReference, 0, len(object.GetOwnerReferences()))
+	for _, ownerReference := range object.GetOwnerReferences() {
+		if ownerReference.UID != cluster.UID {
+			ownerReferences = append(ownerReferences, ownerReference)
+		}
+	}
+	object.SetOwnerReferences(ownerReferences)
+}
+
 // GetSinglePodListOptions returns the listOptions to list a single Pod
 func GetSinglePodListOptions(cluster *fdbtypes.FoundationDBCluster, instanceID string) []client.ListOption {
 	return []client.ListOption{client.InNamespace(cluster.ObjectMeta.Namespace), client.MatchingLabels(GetPodMatchLabels(cluster, "", instanceID))}
@@ -23,6 +23,7 @@ package internal
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -49,7 +50,8 @@ func GetInstanceID(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.
 	return fmt.Sprintf("%s-%s-%d", cluster.Name, processClassSanitizationPattern.ReplaceAllString(string(processClass), "-"), idNum), instanceID
 }
 
-func generateServicePorts(processesPerPod int) []corev1.ServicePort {
+func generateServicePorts(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) []corev1.ServicePort {
+	processesPerPod := cluster.GetDesiredServersPerPod(processClass)
 	ports := make([]corev1.ServicePort, 0, processesPerPod*2)
 
 	for i := 1; i <= processesPerPod; i++ {
@@ -65,10 +67,10 @@ func generateServicePorts(processesPerPod int) []corev1.ServicePort {
 
 		ports = append(ports, corev1.ServicePort{
 			Name: tlsPortName,
-			Port: int32(fdbtypes.GetProcessPort(i, true)),
+			Port: int32(cluster.GetProcessPortForClass(processClass, i, true)),
 		}, corev1.ServicePort{
 			Name: nonTlSPortName,
-			Port: int32(fdbtypes.GetProcessPort(i, false)),
+			Port: int32(cluster.GetProcessPortForClass(processClass, i, false)),
 		})
 	}
 
@@ -80,26 +82,41 @@ func GetService(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 	name, id := GetInstanceID(cluster, processClass, idNum)
 
 	owner := BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
-	metadata := GetObjectMetadata(cluster, nil, processClass, id)
+	metadata := GetObjectMetadata(cluster, cluster.Spec.Routing.ServiceMetadata, processClass, id)
 	metadata.Name = name
 	metadata.OwnerReferences = owner
 
-	processesPerPod := 1
-	if processClass == fdbtypes.ProcessClassStorage {
-		processesPerPod = cluster.GetStorageServersPerPod()
-	}
-
 	return &corev1.Service{
 		ObjectMeta: metadata,
 		Spec: corev1.ServiceSpec{
-			Type:                     corev1.ServiceTypeClusterIP,
-			Ports:                    generateServicePorts(processesPerPod),
+			Type:                     cluster.GetPublicServiceTypeForClass(processClass),
+			Ports:                    generateServicePorts(cluster, processClass),
 			PublishNotReadyAddresses: true,
 			Selector:                 GetPodMatchLabels(cluster, "", id),
+			IPFamilies:               getServiceIPFamilies(cluster),
 		},
 	}, nil
 }
 
+// getServiceIPFamilies returns the IP families for the services of a cluster
+// based on the PodIPFamily. If no PodIPFamily is defined the cluster default
+// will be used.
+func getServiceIPFamilies(cluster *fdbtypes.FoundationDBCluster) []corev1.IPFamily {
+	family := cluster.Spec.Routing.PodIPFamily
+	if family == nil {
+		return nil
+	}
+
+	switch *family {
+	case 4:
+		return []corev1.IPFamily{corev1.IPv4Protocol}
+	case 6:
+		return []corev1.IPFamily{corev1.IPv6Protocol}
+	default:
+		return nil
+	}
+}
+
 // GetPod builds a pod for a new instance
 func GetPod(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, idNum int) (*corev1.Pod, error) {
 	name, id := GetInstanceID(cluster, processClass, idNum)
@@ -115,9 +132,17 @@ func GetPod(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Process
 		return nil, err
 	}
 
+	restartOptionsHash, err := GetProcessRestartOptionsHash(cluster)
+	if err != nil {
+		return nil, err
+	}
+
 	metadata := GetPodMetadata(cluster, processClass, id, specHash)
 	metadata.Name = name
 	metadata.OwnerReferences = owner
+	if restartOptionsHash != "" {
+		metadata.Annotations[fdbtypes.LastRestartOptionsKey] = restartOptionsHash
+	}
 
 	return &corev1.Pod{
 		ObjectMeta: metadata,
@@ -142,6 +167,15 @@ func GetImage(image string, configs []fdbtypes.ImageConfig, versionString string
 	return fdbtypes.SelectImageConfig(configs, versionString).Image(), nil
 }
 
+// withDefaultImageConfig returns a copy of the image configs with the default
+// config appended, so that the default only applies to the fields that are
+// not defined by the user provided configs.
+func withDefaultImageConfig(configs []fdbtypes.ImageConfig, defaultConfig fdbtypes.ImageConfig) []fdbtypes.ImageConfig {
+	result := make([]fdbtypes.ImageConfig, 0, len(configs)+1)
+	result = append(result, configs...)
+	return append(result, defaultConfig)
+}
+
 // GetPodSpec builds a pod spec for a FoundationDB pod
 func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, idNum int) (*corev1.PodSpec, error) {
 	processSettings := cluster.GetProcessSettings(processClass)
@@ -169,14 +203,34 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 		return nil, fmt.Errorf("could not create main container")
 	}
 
-	if sidecarContainer == nil {
+	useUnifiedImage := cluster.UseUnifiedImage()
+
+	if sidecarContainer == nil && !useUnifiedImage {
 		return nil, fmt.Errorf("could not create sidecar container")
 	}
 
-	if initContainer == nil {
+	if initContainer == nil && !useUnifiedImage {
 		return nil, fmt.Errorf("could not create init container")
 	}
 
+	if cluster.UseHostNetwork() {
+		if !useUnifiedImage {
+			return nil, fmt.Errorf("the host network is only supported with the unified image")
+		}
+
+		if !processClass.HasHostNetworkPortRange() {
+			return nil, fmt.Errorf("process class %s does not support the host network", processClass)
+		}
+
+		if cluster.GetDesiredServersPerPod(processClass) > cluster.GetMaxHostNetworkServersPerPod() {
+			return nil, fmt.Errorf("process class %s cannot run more than %d processes per pod with the host network", processClass, cluster.GetMaxHostNetworkServersPerPod())
+		}
+	}
+
+	if cluster.UsesNodePorts(processClass) && cluster.GetDesiredServersPerPod(processClass) > 1 {
+		return nil, fmt.Errorf("process class %s cannot run more than one process per pod with node ports", processClass)
+	}
+
 	podName, instanceID := GetInstanceID(cluster, processClass, idNum)
 
 	versionString := cluster.Status.RunningVersion
@@ -207,25 +261,36 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 		logGroup = cluster.Name
 	}
 
-	mainContainer.Command = []string{"sh", "-c"}
-
-	args := "fdbmonitor --conffile /var/dynamic-conf/fdbmonitor.conf" +
-		" --lockfile /var/dynamic-conf/fdbmonitor.lockfile" +
-		" --loggroup " + logGroup +
-		" >> /var/log/fdb-trace-logs/fdbmonitor-$(date '+%Y-%m-%d').log 2>&1"
+	traceLogDirectory := cluster.GetTraceLogDirectory()
 
+	crashLoop := false
 	for _, crashLoopInstanceID := range cluster.Spec.Buggify.CrashLoop {
 		if instanceID == crashLoopInstanceID || crashLoopInstanceID == "*" {
-			args = "crash-loop"
+			crashLoop = true
 		}
 	}
-	mainContainer.Args = []string{args}
 
-	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
-		corev1.VolumeMount{Name: "data", MountPath: "/var/fdb/data"},
-		corev1.VolumeMount{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
-		corev1.VolumeMount{Name: "fdb-trace-logs", MountPath: "/var/log/fdb-trace-logs"},
-	)
+	if useUnifiedImage {
+		configureMainContainerForUnifiedImage(cluster, mainContainer, processClass, instanceID, crashLoop)
+	} else {
+		mainContainer.Command = []string{"sh", "-c"}
+
+		args := "fdbmonitor --conffile /var/dynamic-conf/fdbmonitor.conf" +
+			" --lockfile /var/dynamic-conf/fdbmonitor.lockfile" +
+			" --loggroup " + logGroup +
+			" >> " + traceLogDirectory + "/fdbmonitor-$(date '+%Y-%m-%d').log 2>&1"
+
+		if crashLoop {
+			args = "crash-loop"
+		}
+		mainContainer.Args = []string{args}
+
+		mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+			corev1.VolumeMount{Name: "data", MountPath: "/var/fdb/data"},
+			corev1.VolumeMount{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
+			corev1.VolumeMount{Name: "fdb-trace-logs", MountPath: traceLogDirectory},
+		)
+	}
 
 	var readOnlyRootFilesystem = true
 	if mainContainer.SecurityContext == nil {
@@ -236,18 +301,34 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 		mainContainer.SecurityContext.ReadOnlyRootFilesystem = &readOnlyRootFilesystem
 	}
 
-	err = configureSidecarContainerForCluster(cluster, initContainer, true, instanceID, processSettings.GetAllowTagOverride())
-	if err != nil {
-		return nil, err
-	}
+	// The container that holds the process environment also holds the
+	// number of servers per pod.
+	serversPerPodContainer := mainContainer
+	if !useUnifiedImage {
+		err = configureSidecarContainerForCluster(cluster, initContainer, true, processClass, instanceID, processSettings.GetAllowTagOverride())
+		if err != nil {
+			return nil, err
+		}
 
-	err = configureSidecarContainerForCluster(cluster, sidecarContainer, false, instanceID, processSettings.GetAllowTagOverride())
-	if err != nil {
-		return nil, err
+		err = configureSidecarContainerForCluster(cluster, sidecarContainer, false, processClass, instanceID, processSettings.GetAllowTagOverride())
+		if err != nil {
+			return nil, err
+		}
+
+		if version.PrefersCommandLineArgumentsInSidecar() {
+			passCustomParameterVariables(cluster, processClass, mainContainer, initContainer)
+			passCustomParameterVariables(cluster, processClass, mainContainer, sidecarContainer)
+		}
+
+		serversPerPodContainer = sidecarContainer
 	}
 
 	if processClass == fdbtypes.ProcessClassStorage && cluster.GetStorageServersPerPod() > 1 {
-		sidecarContainer.Env = append(sidecarContainer.Env, corev1.EnvVar{Name: "STORAGE_SERVERS_PER_POD", Value: fmt.Sprintf("%d", cluster.GetStorageServersPerPod())})
+		serversPerPodContainer.Env = append(serversPerPodContainer.Env, corev1.EnvVar{Name: "STORAGE_SERVERS_PER_POD", Value: fmt.Sprintf("%d", cluster.GetStorageServersPerPod())})
+	}
+
+	if processClass.IsLogProcess() && cluster.GetLogServersPerPod() > 1 {
+		serversPerPodContainer.Env = append(serversPerPodContainer.Env, corev1.EnvVar{Name: "LOG_SERVERS_PER_POD", Value: fmt.Sprintf("%d", cluster.GetLogServersPerPod())})
 	}
 
 	var mainVolumeSource corev1.VolumeSource
@@ -265,21 +346,24 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 		mainVolumeSource.EmptyDir = &corev1.EmptyDirVolumeSource{}
 	}
 
-	monitorConf := fmt.Sprintf("fdbmonitor-conf-%s", processClass)
-	if processClass == fdbtypes.ProcessClassStorage && cluster.GetStorageServersPerPod() > 1 {
-		monitorConf = fmt.Sprintf("fdbmonitor-conf-%s-density-%d", processClass, cluster.GetStorageServersPerPod())
-	}
-
-	configMapItems := []corev1.KeyToPath{
-		{Key: monitorConf, Path: "fdbmonitor.conf"},
-		{Key: ClusterFileKey, Path: "fdb.cluster"},
+	var configMapItems []corev1.KeyToPath
+	if useUnifiedImage {
+		configMapItems = []corev1.KeyToPath{
+			{Key: GetConfigMapMonitorConfigurationEntry(processClass, cluster.GetDesiredServersPerPod(processClass)), Path: MonitorConfigurationFile},
+			{Key: ClusterFileKey, Path: "fdb.cluster"},
+		}
+	} else {
+		configMapItems = []corev1.KeyToPath{
+			{Key: GetConfigMapMonitorConfEntry(processClass, cluster.GetDesiredServersPerPod(processClass)), Path: "fdbmonitor.conf"},
+			{Key: ClusterFileKey, Path: "fdb.cluster"},
+		}
 	}
 
 	if useCustomCAs {
 		configMapItems = append(configMapItems, corev1.KeyToPath{Key: "ca-file", Path: "ca.pem"})
 	}
 
-	if !version.PrefersCommandLineArgumentsInSidecar() {
+	if !version.PrefersCommandLineArgumentsInSidecar() && !useUnifiedImage {
 		configMapItems = append(configMapItems, corev1.KeyToPath{Key: "sidecar-conf", Path: "config.json"})
 	}
 
@@ -292,20 +376,75 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 
 	volumes := []corev1.Volume{
 		{Name: "data", VolumeSource: mainVolumeSource},
-		{Name: "dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
-		{Name: "config-map", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+	}
+
+	// The kubernetes monitor reads its configuration directly from the
+	// ConfigMap, so there are no files that have to be copied.
+	if !useUnifiedImage {
+		volumes = append(volumes, corev1.Volume{Name: "dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}})
+	}
+
+	volumes = append(volumes,
+		corev1.Volume{Name: "config-map", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
 			LocalObjectReference: corev1.LocalObjectReference{Name: configMapRefName},
 			Items:                configMapItems,
 		}}},
-		{Name: "fdb-trace-logs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
-	}
+		corev1.Volume{Name: "fdb-trace-logs", VolumeSource: cluster.GetTraceLogVolumeSource()},
+	)
 
 	faultDomainKey := cluster.Spec.FaultDomain.Key
 	if faultDomainKey == "" {
 		faultDomainKey = "kubernetes.io/hostname"
 	}
 
-	if faultDomainKey != "foundationdb.org/none" && faultDomainKey != "foundationdb.org/kubernetes-cluster" {
+	spreadMode := cluster.GetFaultDomainSpreadMode()
+	if faultDomainKey != "foundationdb.org/none" && faultDomainKey != "foundationdb.org/kubernetes-cluster" && spreadMode != fdbtypes.FaultDomainSpreadModeNone {
+		labelSelectors := make(map[string]string, len(cluster.Spec.LabelConfig.MatchLabels)+1)
+		for key, value := range cluster.Spec.LabelConfig.MatchLabels {
+			labelSelectors[key] = value
+		}
+
+		processClassLabel := cluster.GetProcessClassLabel()
+		labelSelectors[processClassLabel] = string(processClass)
+
+		if spreadMode == fdbtypes.FaultDomainSpreadModeTopologySpread {
+			podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints,
+				corev1.TopologySpreadConstraint{
+					MaxSkew:           1,
+					TopologyKey:       faultDomainKey,
+					WhenUnsatisfiable: corev1.ScheduleAnyway,
+					LabelSelector:     &metav1.LabelSelector{MatchLabels: labelSelectors},
+				})
+		} else {
+			if podSpec.Affinity == nil {
+				podSpec.Affinity = &corev1.Affinity{}
+			}
+
+			if podSpec.Affinity.PodAntiAffinity == nil {
+				podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+			}
+
+			term := corev1.PodAffinityTerm{
+				TopologyKey:   faultDomainKey,
+				LabelSelector: &metav1.LabelSelector{MatchLabels: labelSelectors},
+			}
+
+			if spreadMode == fdbtypes.FaultDomainSpreadModeRequired {
+				podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution, term)
+			} else {
+				podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+					corev1.WeightedPodAffinityTerm{
+						Weight:          1,
+						PodAffinityTerm: term,
+					})
+			}
+		}
+	}
+
+	if cluster.UseHostNetwork() {
+		podSpec.HostNetwork = true
+		podSpec.DNSPolicy = corev1.DNSClusterFirstWithHostNet
+
 		if podSpec.Affinity == nil {
 			podSpec.Affinity = &corev1.Affinity{}
 		}
@@ -318,16 +457,39 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 		for key, value := range cluster.Spec.LabelConfig.MatchLabels {
 			labelSelectors[key] = value
 		}
+		labelSelectors[cluster.GetProcessClassLabel()] = string(processClass)
+
+		// Pods of the same process class use the same ports, so they must
+		// not run on the same node.
+		podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			corev1.PodAffinityTerm{
+				TopologyKey:   "kubernetes.io/hostname",
+				LabelSelector: &metav1.LabelSelector{MatchLabels: labelSelectors},
+			})
+	}
 
-		processClassLabel := cluster.GetProcessClassLabel()
-		labelSelectors[processClassLabel] = string(processClass)
+	if cluster.UsesNodePorts(processClass) {
+		if podSpec.Affinity == nil {
+			podSpec.Affinity = &corev1.Affinity{}
+		}
+
+		if podSpec.Affinity.PodAntiAffinity == nil {
+			podSpec.Affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+		}
 
-		podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(podSpec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
-			corev1.WeightedPodAffinityTerm{
-				Weight: 1,
-				PodAffinityTerm: corev1.PodAffinityTerm{
-					TopologyKey:   faultDomainKey,
-					LabelSelector: &metav1.LabelSelector{MatchLabels: labelSelectors},
+		// The processes that use node ports advertise the IP of their node,
+		// and the operator excludes processes by their IP, so two of them
+		// must not run on the same node.
+		podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution = append(podSpec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution,
+			corev1.PodAffinityTerm{
+				TopologyKey: "kubernetes.io/hostname",
+				LabelSelector: &metav1.LabelSelector{
+					MatchLabels: cluster.Spec.LabelConfig.MatchLabels,
+					MatchExpressions: []metav1.LabelSelectorRequirement{{
+						Key:      cluster.GetProcessClassLabel(),
+						Operator: metav1.LabelSelectorOpIn,
+						Values:   getNodePortProcessClasses(cluster, processClass),
+					}},
 				},
 			})
 	}
@@ -358,8 +520,23 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 		}
 	}
 
-	replaceContainers(podSpec.InitContainers, initContainer)
-	replaceContainers(podSpec.Containers, mainContainer, sidecarContainer)
+	override, hasOverride := cluster.Spec.ProcessGroupOverrides[instanceID]
+	if hasOverride {
+		applyProcessGroupOverride(podSpec, mainContainer, override)
+	}
+
+	if useUnifiedImage {
+		replaceContainers(podSpec.Containers, mainContainer)
+		podSpec.Containers = removeContainers(podSpec.Containers, "foundationdb-kubernetes-sidecar")
+		podSpec.InitContainers = removeContainers(podSpec.InitContainers, "foundationdb-kubernetes-init")
+	} else {
+		replaceContainers(podSpec.InitContainers, initContainer)
+		replaceContainers(podSpec.Containers, mainContainer, sidecarContainer)
+	}
+
+	if cluster.Spec.TraceLogs.LogForwarder != nil {
+		podSpec.Containers = append(podSpec.Containers, getLogForwarderContainer(cluster))
+	}
 
 	podSpec.Volumes = append(podSpec.Volumes, volumes...)
 
@@ -373,26 +550,153 @@ func GetPodSpec(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.Pro
 	return podSpec, nil
 }
 
+// applyProcessGroupOverride applies the settings for a single process group
+// to its pod spec.
+func applyProcessGroupOverride(podSpec *corev1.PodSpec, mainContainer *corev1.Container, override fdbtypes.ProcessGroupOverride) {
+	if override.NodeName != "" {
+		if podSpec.NodeSelector == nil {
+			podSpec.NodeSelector = make(map[string]string, 1)
+		}
+		podSpec.NodeSelector[corev1.LabelHostname] = override.NodeName
+	}
+
+	if override.Resources == nil {
+		return
+	}
+
+	for name, quantity := range override.Resources.Requests {
+		if mainContainer.Resources.Requests == nil {
+			mainContainer.Resources.Requests = corev1.ResourceList{}
+		}
+		mainContainer.Resources.Requests[name] = quantity
+	}
+
+	for name, quantity := range override.Resources.Limits {
+		if mainContainer.Resources.Limits == nil {
+			mainContainer.Resources.Limits = corev1.ResourceList{}
+		}
+		mainContainer.Resources.Limits[name] = quantity
+	}
+}
+
+// configureMainContainerForUnifiedImage sets up the main container to run the
+// kubernetes monitor, which starts the fdbserver processes based on the
+// process configuration in the ConfigMap.
+func configureMainContainerForUnifiedImage(cluster *fdbtypes.FoundationDBCluster, mainContainer *corev1.Container, processClass fdbtypes.ProcessClass, instanceID string, crashLoop bool) {
+	traceLogDirectory := cluster.GetTraceLogDirectory()
+
+	if crashLoop {
+		mainContainer.Command = []string{"sh", "-c"}
+		mainContainer.Args = []string{"crash-loop"}
+	} else {
+		mainContainer.Command = []string{"/usr/bin/fdb-kubernetes-monitor"}
+		mainContainer.Args = []string{
+			"--input-dir", "/var/dynamic-conf",
+			"--input-file", MonitorConfigurationFile,
+			"--log-path", traceLogDirectory + "/monitor.log",
+		}
+	}
+
+	extendEnv(mainContainer, getProcessEnvironment(cluster, processClass, instanceID)...)
+	extendEnv(mainContainer,
+		corev1.EnvVar{Name: "FDB_POD_NAME", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		}},
+		corev1.EnvVar{Name: "FDB_POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+		}},
+	)
+
+	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+		corev1.VolumeMount{Name: "data", MountPath: "/var/fdb/data"},
+		corev1.VolumeMount{Name: "config-map", MountPath: "/var/dynamic-conf"},
+		corev1.VolumeMount{Name: "fdb-trace-logs", MountPath: traceLogDirectory},
+	)
+}
+
+// getLogForwarderContainer builds the container that ships the trace logs of
+// the FoundationDB processes.
+func getLogForwarderContainer(cluster *fdbtypes.FoundationDBCluster) corev1.Container {
+	forwarder := cluster.Spec.TraceLogs.LogForwarder.DeepCopy()
+	traceLogDirectory := cluster.GetTraceLogDirectory()
+
+	extendEnv(forwarder, corev1.EnvVar{Name: "FDB_TRACE_LOG_DIR", Value: traceLogDirectory})
+	if cluster.Spec.TraceLogs.Format != "" {
+		extendEnv(forwarder, corev1.EnvVar{Name: "FDB_TRACE_LOG_FORMAT", Value: cluster.Spec.TraceLogs.Format})
+	}
+
+	forwarder.VolumeMounts = append(forwarder.VolumeMounts,
+		corev1.VolumeMount{Name: "fdb-trace-logs", MountPath: traceLogDirectory, ReadOnly: true},
+	)
+
+	return *forwarder
+}
+
 // configureSidecarContainerForCluster sets up a sidecar container for a sidecar
 // in the FDB cluster.
-func configureSidecarContainerForCluster(cluster *fdbtypes.FoundationDBCluster, container *corev1.Container, initMode bool, instanceID string, allowOverride bool) error {
+func configureSidecarContainerForCluster(cluster *fdbtypes.FoundationDBCluster, container *corev1.Container, initMode bool, processClass fdbtypes.ProcessClass, instanceID string, allowOverride bool) error {
 	versionString := cluster.Status.RunningVersion
 	if versionString == "" {
 		versionString = cluster.Spec.Version
 	}
 
-	return configureSidecarContainer(container, initMode, instanceID, versionString, cluster, allowOverride)
+	return configureSidecarContainer(container, initMode, processClass, instanceID, versionString, cluster, nil, allowOverride)
+}
+
+// passCustomParameterVariables passes the environment variables that the
+// custom parameters take from the main container to a sidecar container, so
+// the sidecar can substitute them when it writes the monitor conf.
+func passCustomParameterVariables(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, mainContainer *corev1.Container, container *corev1.Container) {
+	substituted := make(map[string]bool, len(cluster.Spec.SidecarVariables))
+	for _, variable := range cluster.Spec.SidecarVariables {
+		substituted[variable] = true
+	}
+
+	for _, variable := range getCustomParameterVariables(cluster, processClass) {
+		if substituted[variable] {
+			continue
+		}
+
+		for _, envVar := range mainContainer.Env {
+			if envVar.Name != variable {
+				continue
+			}
+
+			extendEnv(container, envVar)
+			container.Args = append(container.Args, "--substitute-variable", variable)
+			substituted[variable] = true
+			break
+		}
+	}
 }
 
 // configureSidecarContainerForBackup sets up a sidecar container for the init
 // container for a backup process.
 func configureSidecarContainerForBackup(backup *fdbtypes.FoundationDBBackup, container *corev1.Container) error {
-	return configureSidecarContainer(container, true, "", backup.Spec.Version, nil, backup.Spec.GetAllowTagOverride())
+	return configureSidecarContainer(container, true, "", "", backup.Spec.Version, nil, backup.Spec.SidecarImageConfigs, backup.Spec.GetAllowTagOverride())
+}
+
+// configureSidecarContainerForDR sets up a foundationdb-kubernetes-sidecar
+// container that copies the cluster file for one side of a DR replication.
+// The prefix determines which cluster file the container copies.
+func configureSidecarContainerForDR(dr *fdbtypes.FoundationDBDR, container *corev1.Container, prefix string) error {
+	err := configureSidecarContainer(container, true, "", "", dr.Spec.Version, nil, dr.Spec.SidecarImageConfigs, dr.Spec.GetAllowTagOverride())
+	if err != nil {
+		return err
+	}
+
+	for index, mount := range container.VolumeMounts {
+		if mount.Name == "config-map" || mount.Name == "dynamic-conf" {
+			container.VolumeMounts[index].Name = fmt.Sprintf("%s-%s", prefix, mount.Name)
+		}
+	}
+
+	return nil
 }
 
 // configureSidecarContainer sets up a foundationdb-kubernetes-sidecar
 // container.
-func configureSidecarContainer(container *corev1.Container, initMode bool, instanceID string, versionString string, optionalCluster *fdbtypes.FoundationDBCluster, allowOverride bool) error {
+func configureSidecarContainer(container *corev1.Container, initMode bool, processClass fdbtypes.ProcessClass, instanceID string, versionString string, optionalCluster *fdbtypes.FoundationDBCluster, imageConfigs []fdbtypes.ImageConfig, allowOverride bool) error {
 	version, err := fdbtypes.ParseFdbVersion(versionString)
 	if err != nil {
 		return err
@@ -441,41 +745,17 @@ func configureSidecarContainer(container *corev1.Container, initMode bool, insta
 	if optionalCluster != nil {
 		cluster := optionalCluster
 
-		publicIPSource := cluster.Spec.Routing.PublicIPSource
-		usePublicIPFromService := publicIPSource != nil && *publicIPSource == fdbtypes.PublicIPSourceService
-
-		var publicIPKey string
-		if usePublicIPFromService {
-			publicIPKey = fmt.Sprintf("metadata.annotations['%s']", fdbtypes.PublicIPAnnotation)
-		} else {
-			family := cluster.Spec.Routing.PodIPFamily
-			if family == nil {
-				publicIPKey = "status.podIP"
-			} else {
-				publicIPKey = "status.podIPs"
-				sidecarArgs = append(sidecarArgs, "--public-ip-family")
-				sidecarArgs = append(sidecarArgs, fmt.Sprint(*family))
-			}
+		source := cluster.GetPublicIPSource()
+		family := cluster.Spec.Routing.PodIPFamily
+		if source != fdbtypes.PublicIPSourceService && source != fdbtypes.PublicIPSourceNode && family != nil {
+			sidecarArgs = append(sidecarArgs, "--public-ip-family")
+			sidecarArgs = append(sidecarArgs, fmt.Sprint(*family))
 		}
-		sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_PUBLIC_IP", ValueFrom: &corev1.EnvVarSource{
-			FieldRef: &corev1.ObjectFieldSelector{FieldPath: publicIPKey},
-		}})
 
-		if cluster.NeedsExplicitListenAddress() {
-			podIPKey := ""
-			family := cluster.Spec.Routing.PodIPFamily
-			if family == nil {
-				podIPKey = "status.podIP"
-			} else {
-				podIPKey = "status.podIPs"
-			}
-			sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_POD_IP", ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{FieldPath: podIPKey},
-			}})
+		sidecarEnv = append(sidecarEnv, getProcessEnvironment(cluster, processClass, instanceID)...)
 
-			if version.PrefersCommandLineArgumentsInSidecar() {
-				sidecarArgs = append(sidecarArgs, "--substitute-variable", "FDB_POD_IP")
-			}
+		if cluster.NeedsExplicitListenAddress() && version.PrefersCommandLineArgumentsInSidecar() {
+			sidecarArgs = append(sidecarArgs, "--substitute-variable", "FDB_POD_IP")
 		}
 
 		if version.PrefersCommandLineArgumentsInSidecar() {
@@ -485,50 +765,18 @@ func configureSidecarContainer(container *corev1.Container, initMode bool, insta
 			if !version.HasInstanceIDInSidecarSubstitutions() {
 				sidecarArgs = append(sidecarArgs, "--substitute-variable", "FDB_INSTANCE_ID")
 			}
-		}
-
-		faultDomainKey := cluster.Spec.FaultDomain.Key
-		if faultDomainKey == "" {
-			faultDomainKey = "kubernetes.io/hostname"
-		}
-
-		faultDomainSource := cluster.Spec.FaultDomain.ValueFrom
-		if faultDomainSource == "" {
-			faultDomainSource = "spec.nodeName"
-		}
-
-		if faultDomainKey == "foundationdb.org/none" {
-			sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
-			}})
-			sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_ZONE_ID", ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
-			}})
-		} else if faultDomainKey == "foundationdb.org/kubernetes-cluster" {
-			sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
-			}})
-			sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_ZONE_ID", Value: cluster.Spec.FaultDomain.Value})
-		} else {
-			sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
-				FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
-			}})
-			if !strings.HasPrefix(faultDomainSource, "$") {
-				sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_ZONE_ID", ValueFrom: &corev1.EnvVarSource{
-					FieldRef: &corev1.ObjectFieldSelector{FieldPath: faultDomainSource},
-				}})
+			if cluster.UsesNodePorts(processClass) {
+				sidecarArgs = append(sidecarArgs, "--substitute-variable", "FDB_NODE_PORT", "--substitute-variable", "FDB_NODE_PORT_TLS")
 			}
 		}
 
-		sidecarEnv = append(sidecarEnv, corev1.EnvVar{Name: "FDB_INSTANCE_ID", Value: instanceID})
-
 		if !initMode && *cluster.Spec.SidecarContainer.EnableLivenessProbe && container.LivenessProbe == nil {
 			// We can't use a HTTP handler here since the server
 			// requires a client certificate
 			container.LivenessProbe = &corev1.Probe{
 				Handler: corev1.Handler{
 					TCPSocket: &corev1.TCPSocketAction{
-						Port: intstr.IntOrString{IntVal: 8080},
+						Port: intstr.IntOrString{IntVal: SidecarPort},
 					},
 				},
 				TimeoutSeconds:   1,
@@ -541,7 +789,7 @@ func configureSidecarContainer(container *corev1.Container, initMode bool, insta
 			container.ReadinessProbe = &corev1.Probe{
 				Handler: corev1.Handler{
 					TCPSocket: &corev1.TCPSocketAction{
-						Port: intstr.IntOrString{IntVal: 8080},
+						Port: intstr.IntOrString{IntVal: SidecarPort},
 					},
 				},
 			}
@@ -559,7 +807,7 @@ func configureSidecarContainer(container *corev1.Container, initMode bool, insta
 	if optionalCluster != nil {
 		overrides = optionalCluster.Spec.SidecarContainer
 	} else {
-		overrides.ImageConfigs = []fdbtypes.ImageConfig{{BaseImage: "foundationdb/foundationdb-kubernetes-sidecar", TagSuffix: "-1"}}
+		overrides.ImageConfigs = withDefaultImageConfig(imageConfigs, fdbtypes.ImageConfig{BaseImage: "foundationdb/foundationdb-kubernetes-sidecar", TagSuffix: "-1"})
 	}
 
 	if overrides.EnableTLS && !initMode {
@@ -600,6 +848,108 @@ func configureSidecarContainer(container *corev1.Container, initMode bool, insta
 	return nil
 }
 
+// getProcessEnvironment returns the environment variables that are used in
+// the arguments of the fdbserver processes.
+func getProcessEnvironment(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass, instanceID string) []corev1.EnvVar {
+	env := make([]corev1.EnvVar, 0, 7)
+
+	var publicIPKey string
+	switch cluster.GetPublicIPSource() {
+	case fdbtypes.PublicIPSourceService:
+		if cluster.UsesNodePorts(processClass) {
+			publicIPKey = "status.hostIP"
+		} else {
+			publicIPKey = fmt.Sprintf("metadata.annotations['%s']", fdbtypes.PublicIPAnnotation)
+		}
+	case fdbtypes.PublicIPSourceNode:
+		publicIPKey = "status.hostIP"
+	default:
+		if cluster.Spec.Routing.PodIPFamily == nil {
+			publicIPKey = "status.podIP"
+		} else {
+			publicIPKey = "status.podIPs"
+		}
+	}
+	env = append(env, corev1.EnvVar{Name: "FDB_PUBLIC_IP", ValueFrom: &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{FieldPath: publicIPKey},
+	}})
+
+	if cluster.UsesNodePorts(processClass) {
+		env = append(env, corev1.EnvVar{Name: "FDB_NODE_PORT", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: fmt.Sprintf("metadata.annotations['%s']", fdbtypes.NodePortAnnotation)},
+		}}, corev1.EnvVar{Name: "FDB_NODE_PORT_TLS", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: fmt.Sprintf("metadata.annotations['%s']", fdbtypes.NodePortTLSAnnotation)},
+		}})
+	}
+
+	if cluster.NeedsExplicitListenAddress() {
+		podIPKey := ""
+		if cluster.Spec.Routing.PodIPFamily == nil {
+			podIPKey = "status.podIP"
+		} else {
+			podIPKey = "status.podIPs"
+		}
+		env = append(env, corev1.EnvVar{Name: "FDB_POD_IP", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: podIPKey},
+		}})
+	}
+
+	faultDomainKey := cluster.Spec.FaultDomain.Key
+	if faultDomainKey == "" {
+		faultDomainKey = "kubernetes.io/hostname"
+	}
+
+	faultDomainSource := cluster.Spec.FaultDomain.ValueFrom
+	if faultDomainSource == "" {
+		faultDomainSource = "spec.nodeName"
+	}
+
+	if faultDomainKey == "foundationdb.org/none" {
+		env = append(env, corev1.EnvVar{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		}})
+		env = append(env, corev1.EnvVar{Name: "FDB_ZONE_ID", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+		}})
+	} else if faultDomainKey == "foundationdb.org/kubernetes-cluster" {
+		env = append(env, corev1.EnvVar{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+		}})
+		env = append(env, corev1.EnvVar{Name: "FDB_ZONE_ID", Value: cluster.Spec.FaultDomain.Value})
+	} else {
+		env = append(env, corev1.EnvVar{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
+			FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+		}})
+		if !strings.HasPrefix(faultDomainSource, "$") {
+			env = append(env, corev1.EnvVar{Name: "FDB_ZONE_ID", ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{FieldPath: faultDomainSource},
+			}})
+		}
+	}
+
+	return append(env, corev1.EnvVar{Name: "FDB_INSTANCE_ID", Value: instanceID})
+}
+
+// getNodePortProcessClasses returns the process classes of a cluster that
+// use node ports, including the given process class.
+func getNodePortProcessClasses(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) []string {
+	classes := []string{string(processClass)}
+
+	counts, err := cluster.GetProcessCountsWithDefaults()
+	if err != nil {
+		return classes
+	}
+
+	for countClass, count := range counts.Map() {
+		if count > 0 && countClass != processClass && cluster.UsesNodePorts(countClass) {
+			classes = append(classes, string(countClass))
+		}
+	}
+	sort.Strings(classes)
+
+	return classes
+}
+
 // usePvc determines whether we should attach a PVC to a pod.
 func usePvc(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes.ProcessClass) bool {
 	var storage *resource.Quantity
@@ -675,6 +1025,17 @@ func replaceContainers(containers []corev1.Container, newContainers ...*corev1.C
 	}
 }
 
+// removeContainers removes the containers with the given name from a list.
+func removeContainers(containers []corev1.Container, name string) []corev1.Container {
+	result := make([]corev1.Container, 0, len(containers))
+	for _, container := range containers {
+		if container.Name != name {
+			result = append(result, container)
+		}
+	}
+	return result
+}
+
 // extendEnv adds environment variables to an existing environment, unless
 // environment variables with the same name are already present.
 func extendEnv(container *corev1.Container, env ...corev1.EnvVar) {
@@ -692,7 +1053,10 @@ func extendEnv(container *corev1.Container, env ...corev1.EnvVar) {
 }
 
 // GetBackupDeployment builds a deployment for backup agents for a cluster.
-func GetBackupDeployment(backup *fdbtypes.FoundationDBBackup) (*appsv1.Deployment, error) {
+//
+// The credentials are the contents of the blob credentials secret of the
+// backup, or nil if the backup does not have a blob credentials secret.
+func GetBackupDeployment(backup *fdbtypes.FoundationDBBackup, credentials *corev1.Secret) (*appsv1.Deployment, error) {
 	agentCount := int32(backup.GetDesiredAgentCount())
 	if agentCount == 0 {
 		return nil, nil
@@ -750,7 +1114,7 @@ func GetBackupDeployment(backup *fdbtypes.FoundationDBBackup) (*appsv1.Deploymen
 		podTemplate.Spec.Containers = containers
 	}
 
-	image, err := GetImage(mainContainer.Image, []fdbtypes.ImageConfig{{BaseImage: "foundationdb/foundationdb"}}, backup.Spec.Version, backup.Spec.GetAllowTagOverride())
+	image, err := GetImage(mainContainer.Image, withDefaultImageConfig(backup.Spec.ImageConfigs, fdbtypes.ImageConfig{BaseImage: "foundationdb/foundationdb"}), backup.Spec.Version, backup.Spec.GetAllowTagOverride())
 	if err != nil {
 		return nil, err
 	}
@@ -782,6 +1146,13 @@ func GetBackupDeployment(backup *fdbtypes.FoundationDBBackup) (*appsv1.Deploymen
 		corev1.VolumeMount{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
 	)
 
+	if backup.Spec.BlobCredentialsSecret != "" {
+		extendEnv(mainContainer, corev1.EnvVar{Name: "FDB_BLOB_CREDENTIALS", Value: fmt.Sprintf("/var/blob-credentials/%s", BlobCredentialsKey)})
+		mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+			corev1.VolumeMount{Name: "blob-credentials", MountPath: "/var/blob-credentials", ReadOnly: true},
+		)
+	}
+
 	if mainContainer.Resources.Requests == nil {
 		mainContainer.Resources.Requests = corev1.ResourceList{
 			"cpu":    resource.MustParse("1"),
@@ -825,6 +1196,33 @@ func GetBackupDeployment(backup *fdbtypes.FoundationDBBackup) (*appsv1.Deploymen
 		},
 	)
 
+	if backup.Spec.BlobCredentialsSecret != "" {
+		podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes, corev1.Volume{
+			Name: "blob-credentials",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: backup.Spec.BlobCredentialsSecret,
+				Items: []corev1.KeyToPath{
+					{Key: BlobCredentialsKey, Path: BlobCredentialsKey},
+				},
+			}},
+		})
+
+		// The backup agents only read the credentials when they start, so we
+		// store a hash of the credentials on the pods to roll the deployment
+		// when the credentials change.
+		if credentials != nil {
+			credentialsHash, err := GetJSONHash(credentials.Data[BlobCredentialsKey])
+			if err != nil {
+				return nil, err
+			}
+
+			if podTemplate.ObjectMeta.Annotations == nil {
+				podTemplate.ObjectMeta.Annotations = make(map[string]string, 1)
+			}
+			podTemplate.ObjectMeta.Annotations[fdbtypes.BlobCredentialsHashAnnotation] = credentialsHash
+		}
+	}
+
 	deployment.Spec.Template = *podTemplate
 
 	specHash, err := GetJSONHash(deployment.Spec)
@@ -837,23 +1235,473 @@ func GetBackupDeployment(backup *fdbtypes.FoundationDBBackup) (*appsv1.Deploymen
 	return deployment, nil
 }
 
-// GetStorageServersPerPodForPod returns the value of STORAGE_SERVERS_PER_POD from the sidecar or 1
-func GetStorageServersPerPodForPod(pod *corev1.Pod) (int, error) {
-	// If not specified we will default to 1
-	storageServersPerPod := 1
-	if pod == nil {
-		return storageServersPerPod, nil
-	}
-
-	for _, container := range pod.Spec.Containers {
-		for _, env := range container.Env {
-			if env.Name == "STORAGE_SERVERS_PER_POD" {
-				return strconv.Atoi(env.Value)
-			}
-		}
+// GetDRDeployment builds a deployment for the DR agents of a DR replication.
+func GetDRDeployment(dr *fdbtypes.FoundationDBDR) (*appsv1.Deployment, error) {
+	agentCount := int32(dr.GetDesiredAgentCount())
+	if agentCount == 0 {
+		return nil, nil
+	}
+	deploymentName := fmt.Sprintf("%s-dr-agents", dr.ObjectMeta.Name)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   dr.ObjectMeta.Namespace,
+			Name:        deploymentName,
+			Annotations: map[string]string{},
+			Labels:      map[string]string{},
+		},
+	}
+	deployment.Spec.Replicas = &agentCount
+	deployment.ObjectMeta.OwnerReferences = BuildOwnerReference(dr.TypeMeta, dr.ObjectMeta)
+
+	if dr.Spec.DRDeploymentMetadata != nil {
+		for key, value := range dr.Spec.DRDeploymentMetadata.Labels {
+			deployment.ObjectMeta.Labels[key] = value
+		}
+		for key, value := range dr.Spec.DRDeploymentMetadata.Annotations {
+			deployment.ObjectMeta.Annotations[key] = value
+		}
+	}
+	deployment.ObjectMeta.Labels[fdbtypes.DRDeploymentLabel] = string(dr.ObjectMeta.UID)
+
+	var podTemplate *corev1.PodTemplateSpec
+	if dr.Spec.PodTemplateSpec != nil {
+		podTemplate = dr.Spec.PodTemplateSpec.DeepCopy()
+	} else {
+		podTemplate = &corev1.PodTemplateSpec{}
+	}
+
+	var mainContainer *corev1.Container
+	var sourceInitContainer *corev1.Container
+	var destinationInitContainer *corev1.Container
+
+	for index, container := range podTemplate.Spec.Containers {
+		if container.Name == "foundationdb" {
+			mainContainer = &podTemplate.Spec.Containers[index]
+		}
+	}
+
+	if mainContainer == nil {
+		containers := []corev1.Container{
+			{Name: "foundationdb"},
+		}
+		containers = append(containers, podTemplate.Spec.Containers...)
+		mainContainer = &containers[0]
+		podTemplate.Spec.Containers = containers
+	}
+
+	image, err := GetImage(mainContainer.Image, withDefaultImageConfig(dr.Spec.ImageConfigs, fdbtypes.ImageConfig{BaseImage: "foundationdb/foundationdb"}), dr.Spec.Version, dr.Spec.GetAllowTagOverride())
+	if err != nil {
+		return nil, err
+	}
+	mainContainer.Image = image
+	mainContainer.Command = []string{"dr_agent"}
+	args := []string{
+		"--log", "--logdir", "/var/log/fdb-trace-logs",
+		"-s", "/var/source-dynamic-conf/fdb.cluster",
+		"-d", "/var/destination-dynamic-conf/fdb.cluster",
+	}
+
+	if len(dr.Spec.CustomParameters) > 0 {
+		err := ValidateCustomParameters(dr.Spec.CustomParameters)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, customParameter := range dr.Spec.CustomParameters {
+			args = append(args, fmt.Sprintf("--%s", customParameter))
+		}
+	}
+
+	mainContainer.Args = args
+
+	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+		corev1.VolumeMount{Name: "logs", MountPath: "/var/log/fdb-trace-logs"},
+		corev1.VolumeMount{Name: "source-dynamic-conf", MountPath: "/var/source-dynamic-conf"},
+		corev1.VolumeMount{Name: "destination-dynamic-conf", MountPath: "/var/destination-dynamic-conf"},
+	)
+
+	if mainContainer.Resources.Requests == nil {
+		mainContainer.Resources.Requests = corev1.ResourceList{
+			"cpu":    resource.MustParse("1"),
+			"memory": resource.MustParse("1Gi"),
+		}
+	}
+
+	if mainContainer.Resources.Limits == nil {
+		mainContainer.Resources.Limits = mainContainer.Resources.Requests
+	}
+
+	for index, container := range podTemplate.Spec.InitContainers {
+		if container.Name == "foundationdb-kubernetes-init-source" {
+			sourceInitContainer = &podTemplate.Spec.InitContainers[index]
+		}
+		if container.Name == "foundationdb-kubernetes-init-destination" {
+			destinationInitContainer = &podTemplate.Spec.InitContainers[index]
+		}
+	}
+
+	if sourceInitContainer == nil {
+		podTemplate.Spec.InitContainers = append(podTemplate.Spec.InitContainers, corev1.Container{Name: "foundationdb-kubernetes-init-source"})
+	}
+
+	if destinationInitContainer == nil {
+		podTemplate.Spec.InitContainers = append(podTemplate.Spec.InitContainers, corev1.Container{Name: "foundationdb-kubernetes-init-destination"})
+	}
+
+	for index, container := range podTemplate.Spec.InitContainers {
+		if container.Name == "foundationdb-kubernetes-init-source" {
+			err = configureSidecarContainerForDR(dr, &podTemplate.Spec.InitContainers[index], "source")
+		} else if container.Name == "foundationdb-kubernetes-init-destination" {
+			err = configureSidecarContainerForDR(dr, &podTemplate.Spec.InitContainers[index], "destination")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if podTemplate.ObjectMeta.Labels == nil {
+		podTemplate.ObjectMeta.Labels = make(map[string]string, 1)
+	}
+	podTemplate.ObjectMeta.Labels["foundationdb.org/deployment-name"] = deployment.ObjectMeta.Name
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{
+		"foundationdb.org/deployment-name": deployment.ObjectMeta.Name,
+	}}
+
+	podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes,
+		corev1.Volume{Name: "logs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		corev1.Volume{Name: "source-dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		corev1.Volume{Name: "destination-dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		corev1.Volume{
+			Name: "source-config-map",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf("%s-config", dr.Spec.SourceClusterName)},
+				Items: []corev1.KeyToPath{
+					{Key: ClusterFileKey, Path: "fdb.cluster"},
+				},
+			}},
+		},
+		corev1.Volume{
+			Name: "destination-config-map",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf("%s-config", dr.Spec.DestinationClusterName)},
+				Items: []corev1.KeyToPath{
+					{Key: ClusterFileKey, Path: "fdb.cluster"},
+				},
+			}},
+		},
+	)
+
+	deployment.Spec.Template = *podTemplate
+
+	specHash, err := GetJSONHash(deployment.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment.ObjectMeta.Annotations[fdbtypes.LastSpecKey] = specHash
+
+	return deployment, nil
+}
+
+// MetricsExporterPort defines the default port on which the metrics exporter
+// serves metrics.
+const MetricsExporterPort = 9090
+
+// getMetricsExporterPort returns the port on which the metrics exporter serves
+// metrics, taken from the port named metrics in the exporter container.
+func getMetricsExporterPort(cluster *fdbtypes.FoundationDBCluster) int32 {
+	if cluster.Spec.MetricsExporter.PodTemplateSpec != nil {
+		for _, container := range cluster.Spec.MetricsExporter.PodTemplateSpec.Spec.Containers {
+			if container.Name != "exporter" {
+				continue
+			}
+
+			for _, port := range container.Ports {
+				if port.Name == "metrics" {
+					return port.ContainerPort
+				}
+			}
+		}
+	}
+
+	return MetricsExporterPort
+}
+
+// GetMetricsExporterDeployment builds a deployment for the metrics exporter
+// of a cluster.
+func GetMetricsExporterDeployment(cluster *fdbtypes.FoundationDBCluster) (*appsv1.Deployment, error) {
+	if !cluster.ShouldRunMetricsExporter() {
+		return nil, nil
+	}
+
+	deploymentName := fmt.Sprintf("%s-metrics-exporter", cluster.Name)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: GetObjectMetadata(cluster, nil, "", ""),
+	}
+	deployment.ObjectMeta.Name = deploymentName
+	deployment.ObjectMeta.OwnerReferences = BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+	if deployment.ObjectMeta.Annotations == nil {
+		deployment.ObjectMeta.Annotations = map[string]string{}
+	}
+	deployment.ObjectMeta.Labels[fdbtypes.MetricsExporterDeploymentLabel] = string(cluster.ObjectMeta.UID)
+
+	replicas := int32(1)
+	deployment.Spec.Replicas = &replicas
+
+	var podTemplate *corev1.PodTemplateSpec
+	if cluster.Spec.MetricsExporter.PodTemplateSpec != nil {
+		podTemplate = cluster.Spec.MetricsExporter.PodTemplateSpec.DeepCopy()
+	} else {
+		podTemplate = &corev1.PodTemplateSpec{}
+	}
+
+	var mainContainer *corev1.Container
+	var initContainer *corev1.Container
+
+	for index, container := range podTemplate.Spec.Containers {
+		if container.Name == "exporter" {
+			mainContainer = &podTemplate.Spec.Containers[index]
+		}
+	}
+
+	if mainContainer == nil || mainContainer.Image == "" {
+		return nil, fmt.Errorf("the metrics exporter requires a container named exporter with an image")
+	}
+
+	extendEnv(mainContainer, corev1.EnvVar{Name: "FDB_CLUSTER_FILE", Value: "/var/dynamic-conf/fdb.cluster"})
+
+	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+		corev1.VolumeMount{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
+	)
+
+	metricsPort := getMetricsExporterPort(cluster)
+	hasMetricsPort := false
+	for _, port := range mainContainer.Ports {
+		if port.Name == "metrics" {
+			hasMetricsPort = true
+		}
+	}
+
+	if !hasMetricsPort {
+		mainContainer.Ports = append(mainContainer.Ports, corev1.ContainerPort{Name: "metrics", ContainerPort: metricsPort})
+	}
+
+	for index, container := range podTemplate.Spec.InitContainers {
+		if container.Name == "foundationdb-kubernetes-init" {
+			initContainer = &podTemplate.Spec.InitContainers[index]
+		}
+	}
+
+	if initContainer == nil {
+		podTemplate.Spec.InitContainers = append(podTemplate.Spec.InitContainers, corev1.Container{Name: "foundationdb-kubernetes-init"})
+		initContainer = &podTemplate.Spec.InitContainers[len(podTemplate.Spec.InitContainers)-1]
+	}
+
+	versionString := cluster.Status.RunningVersion
+	if versionString == "" {
+		versionString = cluster.Spec.Version
+	}
+
+	err := configureSidecarContainer(initContainer, true, "", "", versionString, nil, cluster.Spec.SidecarContainer.ImageConfigs, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if podTemplate.ObjectMeta.Labels == nil {
+		podTemplate.ObjectMeta.Labels = make(map[string]string, 1)
+	}
+	podTemplate.ObjectMeta.Labels["foundationdb.org/deployment-name"] = deployment.ObjectMeta.Name
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{
+		"foundationdb.org/deployment-name": deployment.ObjectMeta.Name,
+	}}
+
+	if podTemplate.ObjectMeta.Annotations == nil {
+		podTemplate.ObjectMeta.Annotations = make(map[string]string, 2)
+	}
+	podTemplate.ObjectMeta.Annotations["prometheus.io/scrape"] = "true"
+	podTemplate.ObjectMeta.Annotations["prometheus.io/port"] = fmt.Sprintf("%d", metricsPort)
+
+	podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes,
+		corev1.Volume{Name: "dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		corev1.Volume{
+			Name: "config-map",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: getConfigMapMetadata(cluster).Name},
+				Items: []corev1.KeyToPath{
+					{Key: ClusterFileKey, Path: "fdb.cluster"},
+				},
+			}},
+		},
+	)
+
+	deployment.Spec.Template = *podTemplate
+
+	specHash, err := GetJSONHash(deployment.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment.ObjectMeta.Annotations[fdbtypes.LastSpecKey] = specHash
+
+	return deployment, nil
+}
+
+// GetConsistencyCheckDeployment builds a deployment for the consistency
+// checker for a cluster.
+func GetConsistencyCheckDeployment(cluster *fdbtypes.FoundationDBCluster) (*appsv1.Deployment, error) {
+	if !cluster.ShouldRunConsistencyChecker() {
+		return nil, nil
+	}
+
+	deploymentName := fmt.Sprintf("%s-consistency-check", cluster.Name)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: GetObjectMetadata(cluster, nil, "", ""),
+	}
+	deployment.ObjectMeta.Name = deploymentName
+	deployment.ObjectMeta.OwnerReferences = BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+	if deployment.ObjectMeta.Annotations == nil {
+		deployment.ObjectMeta.Annotations = map[string]string{}
+	}
+	deployment.ObjectMeta.Labels[fdbtypes.ConsistencyCheckDeploymentLabel] = string(cluster.ObjectMeta.UID)
+
+	replicas := int32(1)
+	deployment.Spec.Replicas = &replicas
+
+	var podTemplate *corev1.PodTemplateSpec
+	if cluster.Spec.ConsistencyCheck.PodTemplateSpec != nil {
+		podTemplate = cluster.Spec.ConsistencyCheck.PodTemplateSpec.DeepCopy()
+	} else {
+		podTemplate = &corev1.PodTemplateSpec{}
+	}
+
+	var mainContainer *corev1.Container
+	var initContainer *corev1.Container
+
+	podTemplate.Spec.Containers, _ = ensureContainerPresent(podTemplate.Spec.Containers, "foundationdb", 0)
+	for index, container := range podTemplate.Spec.Containers {
+		if container.Name == "foundationdb" {
+			mainContainer = &podTemplate.Spec.Containers[index]
+		}
+	}
+
+	versionString := cluster.Status.RunningVersion
+	if versionString == "" {
+		versionString = cluster.Spec.Version
+	}
+
+	image, err := GetImage(mainContainer.Image, cluster.Spec.MainContainer.ImageConfigs, versionString, false)
+	if err != nil {
+		return nil, err
+	}
+	mainContainer.Image = image
+
+	traceLogDirectory := cluster.GetTraceLogDirectory()
+	mainContainer.Command = []string{"fdbserver"}
+	mainContainer.Args = []string{
+		"-r", "consistencycheck",
+		"-C", "/var/dynamic-conf/fdb.cluster",
+		"--logdir", traceLogDirectory,
+	}
+
+	extendEnv(mainContainer, corev1.EnvVar{Name: "FDB_CLUSTER_FILE", Value: "/var/dynamic-conf/fdb.cluster"})
+
+	mainContainer.VolumeMounts = append(mainContainer.VolumeMounts,
+		corev1.VolumeMount{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
+		corev1.VolumeMount{Name: "fdb-trace-logs", MountPath: traceLogDirectory},
+	)
+
+	for index, container := range podTemplate.Spec.InitContainers {
+		if container.Name == "foundationdb-kubernetes-init" {
+			initContainer = &podTemplate.Spec.InitContainers[index]
+		}
+	}
+
+	if initContainer == nil {
+		podTemplate.Spec.InitContainers = append(podTemplate.Spec.InitContainers, corev1.Container{Name: "foundationdb-kubernetes-init"})
+		initContainer = &podTemplate.Spec.InitContainers[len(podTemplate.Spec.InitContainers)-1]
+	}
+
+	err = configureSidecarContainer(initContainer, true, "", "", versionString, nil, cluster.Spec.SidecarContainer.ImageConfigs, false)
+	if err != nil {
+		return nil, err
 	}
 
-	return storageServersPerPod, nil
+	if podTemplate.ObjectMeta.Labels == nil {
+		podTemplate.ObjectMeta.Labels = make(map[string]string, 1)
+	}
+	podTemplate.ObjectMeta.Labels["foundationdb.org/deployment-name"] = deployment.ObjectMeta.Name
+	deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{
+		"foundationdb.org/deployment-name": deployment.ObjectMeta.Name,
+	}}
+
+	podTemplate.Spec.Volumes = append(podTemplate.Spec.Volumes,
+		corev1.Volume{Name: "dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		corev1.Volume{Name: "fdb-trace-logs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		corev1.Volume{
+			Name: "config-map",
+			VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: getConfigMapMetadata(cluster).Name},
+				Items: []corev1.KeyToPath{
+					{Key: ClusterFileKey, Path: "fdb.cluster"},
+				},
+			}},
+		},
+	)
+
+	deployment.Spec.Template = *podTemplate
+
+	specHash, err := GetJSONHash(deployment.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment.ObjectMeta.Annotations[fdbtypes.LastSpecKey] = specHash
+
+	return deployment, nil
+}
+
+// GetStorageServersPerPodForPod returns the value of STORAGE_SERVERS_PER_POD from the sidecar or 1
+func GetStorageServersPerPodForPod(pod *corev1.Pod) (int, error) {
+	return getServersPerPodFromEnv(pod, "STORAGE_SERVERS_PER_POD")
+}
+
+// GetLogServersPerPodForPod returns the value of LOG_SERVERS_PER_POD from the sidecar or 1
+func GetLogServersPerPodForPod(pod *corev1.Pod) (int, error) {
+	return getServersPerPodFromEnv(pod, "LOG_SERVERS_PER_POD")
+}
+
+// GetServersPerPodForPod returns the number of fdbserver processes running in
+// the Pod for the given process class.
+func GetServersPerPodForPod(pod *corev1.Pod, pClass fdbtypes.ProcessClass) (int, error) {
+	if pClass == fdbtypes.ProcessClassStorage {
+		return GetStorageServersPerPodForPod(pod)
+	}
+
+	if pClass.IsLogProcess() {
+		return GetLogServersPerPodForPod(pod)
+	}
+
+	return 1, nil
+}
+
+// getServersPerPodFromEnv returns the value of the provided environment
+// variable from the containers of the Pod or 1 if the variable is not set.
+func getServersPerPodFromEnv(pod *corev1.Pod, name string) (int, error) {
+	// If not specified we will default to 1
+	serversPerPod := 1
+	if pod == nil {
+		return serversPerPod, nil
+	}
+
+	for _, container := range pod.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == name {
+				return strconv.Atoi(env.Value)
+			}
+		}
+	}
+
+	return serversPerPod, nil
 }
 
 // GetPodMetadata returns the metadata for a specific Pod
@@ -869,6 +1717,15 @@ func GetPodMetadata(cluster *fdbtypes.FoundationDBCluster, processClass fdbtypes
 
 	metadata := GetObjectMetadata(cluster, customMetadata, processClass, id)
 
+	// The labels of a process group cannot replace the labels that identify
+	// the pod.
+	podLabels := GetPodLabels(cluster, processClass, id)
+	for label, value := range cluster.Spec.ProcessGroupOverrides[id].Labels {
+		if _, reserved := podLabels[label]; !reserved {
+			metadata.Labels[label] = value
+		}
+	}
+
 	if metadata.Annotations == nil {
 		metadata.Annotations = make(map[string]string)
 	}
@@ -899,5 +1756,14 @@ func GetObjectMetadata(cluster *fdbtypes.FoundationDBCluster, base *metav1.Objec
 		metadata.Labels[label] = value
 	}
 
+	if len(cluster.Spec.LabelConfig.ResourceAnnotations) > 0 {
+		if metadata.Annotations == nil {
+			metadata.Annotations = make(map[string]string, len(cluster.Spec.LabelConfig.ResourceAnnotations))
+		}
+		for annotation, value := range cluster.Spec.LabelConfig.ResourceAnnotations {
+			metadata.Annotations[annotation] = value
+		}
+	}
+
 	return *metadata
 }
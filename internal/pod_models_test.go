@@ -100,6 +100,75 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with a process group override", func() {
+			BeforeEach(func() {
+				cluster.Spec.ProcessGroupOverrides = map[string]fdbtypes.ProcessGroupOverride{
+					"storage-1": {
+						NodeName: "node-a",
+						Labels: map[string]string{
+							"hot-instance":                  "true",
+							fdbtypes.FDBProcessGroupIDLabel: "storage-9",
+						},
+						Resources: &corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse("4Gi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse("8Gi"),
+							},
+						},
+					},
+				}
+			})
+
+			When("building the overridden process group", func() {
+				BeforeEach(func() {
+					pod, err = GetPod(cluster, fdbtypes.ProcessClassStorage, 1)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should add the custom labels without replacing the identifying labels", func() {
+					Expect(pod.ObjectMeta.Labels).To(Equal(map[string]string{
+						OldFDBClusterLabel:              cluster.Name,
+						fdbtypes.FDBClusterLabel:        cluster.Name,
+						OldFDBProcessClassLabel:         string(fdbtypes.ProcessClassStorage),
+						fdbtypes.FDBProcessClassLabel:   string(fdbtypes.ProcessClassStorage),
+						OldFDBProcessGroupIDLabel:       "storage-1",
+						fdbtypes.FDBProcessGroupIDLabel: "storage-1",
+						"hot-instance":                  "true",
+					}))
+				})
+
+				It("should pin the pod to the node", func() {
+					Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{
+						corev1.LabelHostname: "node-a",
+					}))
+				})
+
+				It("should merge the resources into the main container", func() {
+					mainContainer := pod.Spec.Containers[0]
+					Expect(mainContainer.Name).To(Equal("foundationdb"))
+					Expect(*mainContainer.Resources.Requests.Cpu()).To(Equal(resource.MustParse("1")))
+					Expect(*mainContainer.Resources.Requests.Memory()).To(Equal(resource.MustParse("4Gi")))
+					Expect(*mainContainer.Resources.Limits.Cpu()).To(Equal(resource.MustParse("1")))
+					Expect(*mainContainer.Resources.Limits.Memory()).To(Equal(resource.MustParse("8Gi")))
+				})
+			})
+
+			When("building another process group", func() {
+				BeforeEach(func() {
+					pod, err = GetPod(cluster, fdbtypes.ProcessClassStorage, 2)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should not apply the override", func() {
+					Expect(pod.ObjectMeta.Labels).NotTo(HaveKey("hot-instance"))
+					Expect(pod.Spec.NodeSelector).To(BeEmpty())
+					Expect(*pod.Spec.Containers[0].Resources.Requests.Memory()).To(Equal(resource.MustParse("1Gi")))
+				})
+			})
+		})
+
 		Context("with a cluster controller instance", func() {
 			BeforeEach(func() {
 				pod, err = GetPod(cluster, fdbtypes.ProcessClassClusterController, 1)
@@ -171,6 +240,27 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with custom resource annotations", func() {
+			BeforeEach(func() {
+				cluster.Spec.LabelConfig.ResourceAnnotations = map[string]string{
+					"fdb-resource-annotation": "value1",
+				}
+
+				pod, err = GetPod(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should add the annotations to the metadata", func() {
+				hash, err := GetPodSpecHash(cluster, ProcessClassFromLabels(cluster, pod.Labels), 1, &pod.Spec)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(pod.ObjectMeta.Annotations).To(Equal(map[string]string{
+					"fdb-resource-annotation":            "value1",
+					"foundationdb.org/last-applied-spec": hash,
+					"foundationdb.org/public-ip-source":  "pod",
+				}))
+			})
+		})
+
 		Context("with custom labels", func() {
 			BeforeEach(func() {
 				cluster = CreateDefaultCluster()
@@ -859,6 +949,35 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with a basic log instance with multiple log servers per disk", func() {
+			BeforeEach(func() {
+				cluster.Spec.LogServersPerPod = 2
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassLog, 1)
+			})
+
+			It("should set the log servers per Pod in the sidecar container", func() {
+				sidecarContainer := spec.Containers[1]
+				Expect(sidecarContainer.Name).To(Equal("foundationdb-kubernetes-sidecar"))
+				Expect(sidecarContainer.Env).To(ContainElement(corev1.EnvVar{Name: "LOG_SERVERS_PER_POD", Value: "2"}))
+				Expect(sidecarContainer.Env).NotTo(ContainElement(WithTransform(func(env corev1.EnvVar) string {
+					return env.Name
+				}, Equal("STORAGE_SERVERS_PER_POD"))))
+			})
+
+			It("should use the density specific monitor conf", func() {
+				Expect(spec.Volumes[2]).To(Equal(corev1.Volume{
+					Name: "config-map",
+					VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf("%s-config", cluster.Name)},
+						Items: []corev1.KeyToPath{
+							{Key: "fdbmonitor-conf-log-density-2", Path: "fdbmonitor.conf"},
+							{Key: ClusterFileKey, Path: "fdb.cluster"},
+						},
+					}},
+				}))
+			})
+		})
+
 		Context("with a the public IP from the pod", func() {
 			BeforeEach(func() {
 				var source = fdbtypes.PublicIPSourcePod
@@ -991,6 +1110,34 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with the public IP from the node", func() {
+			BeforeEach(func() {
+				var source = fdbtypes.PublicIPSourceNode
+				cluster.Spec.Routing.PublicIPSource = &source
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+			})
+
+			It("should have the environment variables for the IPs in the sidecar container", func() {
+				sidecarEnv := GetEnvVars(spec.Containers[1])
+				Expect(sidecarEnv["FDB_PUBLIC_IP"]).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.hostIP"))
+				Expect(sidecarEnv["FDB_POD_IP"]).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_POD_IP"].ValueFrom).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_POD_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.podIP"))
+			})
+
+			It("should have the environment variables for the IPs in the init container", func() {
+				sidecarEnv := GetEnvVars(spec.InitContainers[0])
+				Expect(sidecarEnv["FDB_PUBLIC_IP"]).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.hostIP"))
+				Expect(sidecarEnv["FDB_POD_IP"]).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_POD_IP"].ValueFrom).NotTo(BeNil())
+				Expect(sidecarEnv["FDB_POD_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.podIP"))
+			})
+		})
+
 		Context("with a the public IP from the service", func() {
 			BeforeEach(func() {
 				var source = fdbtypes.PublicIPSourceService
@@ -1060,6 +1207,69 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with node ports as public service type", func() {
+			BeforeEach(func() {
+				var source = fdbtypes.PublicIPSourceService
+				var serviceType = corev1.ServiceTypeNodePort
+				cluster.Spec.Routing.PublicIPSource = &source
+				cluster.Spec.Routing.PublicServiceType = &serviceType
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should use the node IP and the node ports from the annotations", func() {
+				sidecarEnv := GetEnvVars(spec.Containers[1])
+				Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.hostIP"))
+				Expect(sidecarEnv["FDB_POD_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("status.podIP"))
+				Expect(sidecarEnv["FDB_NODE_PORT"].ValueFrom.FieldRef.FieldPath).To(Equal("metadata.annotations['foundationdb.org/node-port']"))
+				Expect(sidecarEnv["FDB_NODE_PORT_TLS"].ValueFrom.FieldRef.FieldPath).To(Equal("metadata.annotations['foundationdb.org/node-port-tls']"))
+			})
+
+			It("should substitute the node ports in the sidecar", func() {
+				Expect(spec.Containers[1].Args).To(ContainElements("FDB_NODE_PORT", "FDB_NODE_PORT_TLS"))
+			})
+
+			It("should not run two pods that use node ports on the same node", func() {
+				Expect(spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(ContainElement(corev1.PodAffinityTerm{
+					TopologyKey: "kubernetes.io/hostname",
+					LabelSelector: &metav1.LabelSelector{
+						MatchLabels: cluster.Spec.LabelConfig.MatchLabels,
+						MatchExpressions: []metav1.LabelSelectorRequirement{{
+							Key:      cluster.GetProcessClassLabel(),
+							Operator: metav1.LabelSelectorOpIn,
+							Values:   []string{"cluster_controller", "log", "stateless", "storage"},
+						}},
+					},
+				}))
+			})
+
+			When("only the coordinators are exposed", func() {
+				BeforeEach(func() {
+					scope := fdbtypes.PublicServiceScopeCoordinators
+					cluster.Spec.Routing.PublicServiceScope = &scope
+					spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStateless, 1)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should use the service IP for the processes that are not eligible as coordinators", func() {
+					sidecarEnv := GetEnvVars(spec.Containers[1])
+					Expect(sidecarEnv["FDB_PUBLIC_IP"].ValueFrom.FieldRef.FieldPath).To(Equal("metadata.annotations['foundationdb.org/public-ip']"))
+					Expect(sidecarEnv).NotTo(HaveKey("FDB_NODE_PORT"))
+				})
+			})
+
+			When("running multiple processes per pod", func() {
+				BeforeEach(func() {
+					cluster.Spec.StorageServersPerPod = 2
+					spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				})
+
+				It("should return an error", func() {
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
 		Context("with a headless service", func() {
 			BeforeEach(func() {
 				var enabled = true
@@ -1280,6 +1490,71 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with the required spread mode", func() {
+			BeforeEach(func() {
+				mode := fdbtypes.FaultDomainSpreadModeRequired
+				cluster.Spec.FaultDomain = fdbtypes.FoundationDBClusterFaultDomain{SpreadMode: &mode}
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+			})
+
+			It("should set a required pod anti-affinity", func() {
+				Expect(spec.Affinity).To(Equal(&corev1.Affinity{
+					PodAntiAffinity: &corev1.PodAntiAffinity{
+						RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{
+							{
+								TopologyKey: "kubernetes.io/hostname",
+								LabelSelector: &metav1.LabelSelector{
+									MatchLabels: map[string]string{
+										OldFDBClusterLabel:      cluster.Name,
+										OldFDBProcessClassLabel: string(fdbtypes.ProcessClassStorage),
+									},
+								},
+							},
+						},
+					},
+				}))
+				Expect(spec.TopologySpreadConstraints).To(BeEmpty())
+			})
+		})
+
+		Context("with the topology spread mode", func() {
+			BeforeEach(func() {
+				mode := fdbtypes.FaultDomainSpreadModeTopologySpread
+				cluster.Spec.FaultDomain = fdbtypes.FoundationDBClusterFaultDomain{SpreadMode: &mode}
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+			})
+
+			It("should set a topology spread constraint", func() {
+				Expect(spec.Affinity).To(BeNil())
+				Expect(spec.TopologySpreadConstraints).To(Equal([]corev1.TopologySpreadConstraint{
+					{
+						MaxSkew:           1,
+						TopologyKey:       "kubernetes.io/hostname",
+						WhenUnsatisfiable: corev1.ScheduleAnyway,
+						LabelSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								OldFDBClusterLabel:      cluster.Name,
+								OldFDBProcessClassLabel: string(fdbtypes.ProcessClassStorage),
+							},
+						},
+					},
+				}))
+			})
+		})
+
+		Context("with the spread mode disabled", func() {
+			BeforeEach(func() {
+				mode := fdbtypes.FaultDomainSpreadModeNone
+				cluster.Spec.FaultDomain = fdbtypes.FoundationDBClusterFaultDomain{SpreadMode: &mode}
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+			})
+
+			It("should not set any spreading rules", func() {
+				Expect(spec.Affinity).To(BeNil())
+				Expect(spec.TopologySpreadConstraints).To(BeEmpty())
+			})
+		})
+
 		Context("with cross-Kubernetes replication", func() {
 			BeforeEach(func() {
 				cluster.Spec.FaultDomain = fdbtypes.FoundationDBClusterFaultDomain{
@@ -1740,6 +2015,51 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with a custom parameter that takes a secret from the environment", func() {
+			BeforeEach(func() {
+				cluster.Spec.Version = fdbtypes.Versions.WithCommandLineVariablesForSidecar.String()
+				cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{fdbtypes.ProcessClassGeneral: {
+					CustomParameters: &[]string{"tls_password=$FDB_TLS_PASSWORD", "locality_test=$FDB_ZONE_ID"},
+					PodTemplate: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name: "foundationdb",
+								Env: []corev1.EnvVar{{Name: "FDB_TLS_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+									SecretKeyRef: &corev1.SecretKeySelector{
+										LocalObjectReference: corev1.LocalObjectReference{Name: "fdb-tls"},
+										Key:                  "password",
+									},
+								}}},
+							}},
+						},
+					},
+				}}
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should pass the variable to the init container", func() {
+				initContainer := spec.InitContainers[0]
+				Expect(initContainer.Args).To(ContainElement("--init-mode"))
+				Expect(initContainer.Args[len(initContainer.Args)-2:]).To(Equal([]string{"--substitute-variable", "FDB_TLS_PASSWORD"}))
+				Expect(initContainer.Env).To(ContainElement(spec.Containers[0].Env[0]))
+			})
+
+			It("should pass the variable to the sidecar", func() {
+				sidecarContainer := spec.Containers[1]
+				Expect(sidecarContainer.Args[len(sidecarContainer.Args)-2:]).To(Equal([]string{"--substitute-variable", "FDB_TLS_PASSWORD"}))
+				Expect(sidecarContainer.Args).NotTo(ContainElement("FDB_ZONE_ID"))
+				Expect(sidecarContainer.Env).To(ContainElement(corev1.EnvVar{Name: "FDB_TLS_PASSWORD", ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "fdb-tls"},
+						Key:                  "password",
+					},
+				}}))
+			})
+		})
+
 		Context("with command line arguments for the sidecar", func() {
 			BeforeEach(func() {
 				cluster.Spec.Version = fdbtypes.Versions.WithCommandLineVariablesForSidecar.String()
@@ -2082,86 +2402,372 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
-		Context("when setting an image with a tag with override", func() {
+		Context("with custom trace log settings", func() {
 			BeforeEach(func() {
-				allowTagOverride := true
-				processSetting := cluster.Spec.Processes[fdbtypes.ProcessClassGeneral]
-				processSetting.PodTemplate = &corev1.PodTemplateSpec{
-					Spec: corev1.PodSpec{
-						Containers: []corev1.Container{
-							{
-								Name:  "foundationdb",
-								Image: "foundationdb/foundationdb:peperoni",
-							},
-							{
-								Name:  "foundationdb-kubernetes-sidecar",
-								Image: "foundationdb/foundationdb:peperoni",
-							},
-						},
-						InitContainers: []corev1.Container{
-							{
-								Name:  "foundationdb-kubernetes-init",
-								Image: "foundationdb/foundationdb:peperoni",
-							},
-						},
+				cluster.Spec.TraceLogs = fdbtypes.TraceLogConfig{
+					Directory: "/var/log/fdb",
+					Format:    "json",
+					LogForwarder: &corev1.Container{
+						Name:  "log-forwarder",
+						Image: "test/log-forwarder:1.0.0",
 					},
 				}
-				processSetting.AllowTagOverride = &allowTagOverride
-				cluster.Spec.Processes[fdbtypes.ProcessClassGeneral] = processSetting
-
-				err = NormalizeClusterSpec(cluster, DeprecationOptions{UseFutureDefaults: false, OnlyShowChanges: true})
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
-			It("should set the image name with tag", func() {
-				spec, err := GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
-				Expect(err).NotTo(HaveOccurred())
+			It("should write the trace logs to the custom directory", func() {
+				mainContainer := spec.Containers[0]
+				Expect(mainContainer.Name).To(Equal("foundationdb"))
+				Expect(mainContainer.Args).To(Equal([]string{
+					"fdbmonitor --conffile /var/dynamic-conf/fdbmonitor.conf" +
+						" --lockfile /var/dynamic-conf/fdbmonitor.lockfile" +
+						" --loggroup " + cluster.Name +
+						" >> /var/log/fdb/fdbmonitor-$(date '+%Y-%m-%d').log 2>&1",
+				}))
+				Expect(mainContainer.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "fdb-trace-logs", MountPath: "/var/log/fdb"}))
+			})
 
-				for _, c := range spec.Containers {
-					Expect(c.Image).To(Equal("foundationdb/foundationdb:peperoni"))
-				}
+			It("should add the log forwarder container", func() {
+				Expect(len(spec.Containers)).To(Equal(3))
+				forwarder := spec.Containers[2]
+				Expect(forwarder.Name).To(Equal("log-forwarder"))
+				Expect(forwarder.Image).To(Equal("test/log-forwarder:1.0.0"))
+				Expect(forwarder.Env).To(Equal([]corev1.EnvVar{
+					{Name: "FDB_TRACE_LOG_DIR", Value: "/var/log/fdb"},
+					{Name: "FDB_TRACE_LOG_FORMAT", Value: "json"},
+				}))
+				Expect(forwarder.VolumeMounts).To(Equal([]corev1.VolumeMount{
+					{Name: "fdb-trace-logs", MountPath: "/var/log/fdb", ReadOnly: true},
+				}))
 			})
 		})
-	})
 
-	Describe("GetService", func() {
-		var service *corev1.Service
+		Context("with a trace log volume size limit", func() {
+			var sizeLimit resource.Quantity
 
-		Context("with a basic storage instance", func() {
 			BeforeEach(func() {
-				service, err = GetService(cluster, fdbtypes.ProcessClassStorage, 1)
+				sizeLimit = resource.MustParse("2Gi")
+				cluster.Spec.TraceLogs.VolumeSizeLimit = &sizeLimit
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
-			It("should set the metadata on the service", func() {
-				Expect(service.Namespace).To(Equal("my-ns"))
-				Expect(service.Name).To(Equal(fmt.Sprintf("%s-storage-1", cluster.Name)))
-				Expect(service.ObjectMeta.Labels).To(Equal(map[string]string{
-					OldFDBClusterLabel:              cluster.Name,
-					fdbtypes.FDBClusterLabel:        cluster.Name,
-					OldFDBProcessClassLabel:         string(fdbtypes.ProcessClassStorage),
-					fdbtypes.FDBProcessClassLabel:   string(fdbtypes.ProcessClassStorage),
-					OldFDBProcessGroupIDLabel:       "storage-1",
-					fdbtypes.FDBProcessGroupIDLabel: "storage-1",
+			It("should limit the size of the trace log volume", func() {
+				Expect(spec.Volumes).To(ContainElement(corev1.Volume{
+					Name:         "fdb-trace-logs",
+					VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{SizeLimit: &sizeLimit}},
 				}))
 			})
+		})
 
-			It("should set the spec on the service", func() {
-				Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
-
-				Expect(len(service.Spec.Ports)).To(Equal(2))
-				Expect(service.Spec.Ports[0].Name).To(Equal("tls"))
-				Expect(service.Spec.Ports[0].Port).To(Equal(int32(4500)))
-				Expect(service.Spec.Ports[1].Name).To(Equal("non-tls"))
-				Expect(service.Spec.Ports[1].Port).To(Equal(int32(4501)))
+		Context("with a trace log volume claim template", func() {
+			var template *corev1.PersistentVolumeClaimTemplate
 
-				Expect(service.Spec.Selector).To(Equal(map[string]string{
-					OldFDBClusterLabel:        cluster.Name,
-					OldFDBProcessGroupIDLabel: "storage-1",
+			BeforeEach(func() {
+				template = &corev1.PersistentVolumeClaimTemplate{
+					Spec: corev1.PersistentVolumeClaimSpec{
+						AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+						},
+					},
+				}
+				cluster.Spec.TraceLogs.VolumeClaimTemplate = template
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should use an ephemeral volume for the trace logs", func() {
+				Expect(spec.Volumes).To(ContainElement(corev1.Volume{
+					Name:         "fdb-trace-logs",
+					VolumeSource: corev1.VolumeSource{Ephemeral: &corev1.EphemeralVolumeSource{VolumeClaimTemplate: template}},
+				}))
+			})
+
+			It("should keep the data volume", func() {
+				Expect(spec.Volumes[0].Name).To(Equal("data"))
+				Expect(spec.Volumes[0].PersistentVolumeClaim).NotTo(BeNil())
+			})
+		})
+
+		Context("with the unified image", func() {
+			BeforeEach(func() {
+				cluster = CreateDefaultCluster()
+				imageType := fdbtypes.ImageTypeUnified
+				cluster.Spec.ImageType = &imageType
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should only have the main container", func() {
+				Expect(len(spec.Containers)).To(Equal(1))
+				Expect(spec.Containers[0].Name).To(Equal("foundationdb"))
+				Expect(len(spec.InitContainers)).To(Equal(0))
+			})
+
+			It("should run the kubernetes monitor in the main container", func() {
+				mainContainer := spec.Containers[0]
+				Expect(mainContainer.Image).To(Equal(fmt.Sprintf("foundationdb/fdb-kubernetes-monitor:%s", cluster.Spec.Version)))
+				Expect(mainContainer.Command).To(Equal([]string{"/usr/bin/fdb-kubernetes-monitor"}))
+				Expect(mainContainer.Args).To(Equal([]string{
+					"--input-dir", "/var/dynamic-conf",
+					"--input-file", "config.json",
+					"--log-path", "/var/log/fdb-trace-logs/monitor.log",
+				}))
+			})
+
+			It("should provide the process environment in the main container", func() {
+				mainContainer := spec.Containers[0]
+				Expect(mainContainer.Env).To(Equal([]corev1.EnvVar{
+					{Name: "FDB_CLUSTER_FILE", Value: "/var/dynamic-conf/fdb.cluster"},
+					{Name: "FDB_PUBLIC_IP", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "status.podIP"},
+					}},
+					{Name: "FDB_MACHINE_ID", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+					}},
+					{Name: "FDB_ZONE_ID", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+					}},
+					{Name: "FDB_INSTANCE_ID", Value: "storage-1"},
+					{Name: "FDB_POD_NAME", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"},
+					}},
+					{Name: "FDB_POD_NAMESPACE", ValueFrom: &corev1.EnvVarSource{
+						FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+					}},
+				}))
+			})
+
+			It("should mount the config map in the main container", func() {
+				mainContainer := spec.Containers[0]
+				Expect(mainContainer.VolumeMounts).To(Equal([]corev1.VolumeMount{
+					{Name: "data", MountPath: "/var/fdb/data"},
+					{Name: "config-map", MountPath: "/var/dynamic-conf"},
+					{Name: "fdb-trace-logs", MountPath: "/var/log/fdb-trace-logs"},
+				}))
+			})
+
+			It("should have the process configuration in the config map volume", func() {
+				Expect(len(spec.Volumes)).To(Equal(3))
+				Expect(spec.Volumes[0].Name).To(Equal("data"))
+				Expect(spec.Volumes[1]).To(Equal(corev1.Volume{
+					Name: "config-map",
+					VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: fmt.Sprintf("%s-config", cluster.Name)},
+						Items: []corev1.KeyToPath{
+							{Key: "fdbmonitor-conf-storage-json", Path: "config.json"},
+							{Key: "cluster-file", Path: "fdb.cluster"},
+						},
+					}},
+				}))
+				Expect(spec.Volumes[2].Name).To(Equal("fdb-trace-logs"))
+			})
+
+			Context("with multiple storage servers per pod", func() {
+				BeforeEach(func() {
+					cluster.Spec.StorageServersPerPod = 2
+					spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should set the servers per pod in the main container", func() {
+					Expect(spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "STORAGE_SERVERS_PER_POD", Value: "2"}))
+				})
+			})
+
+			Context("with an instance that is crash looping", func() {
+				BeforeEach(func() {
+					cluster.Spec.Buggify.CrashLoop = []string{"storage-1"}
+					spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should have a crash loop arg", func() {
+					mainContainer := spec.Containers[0]
+					Expect(mainContainer.Command).To(Equal([]string{"sh", "-c"}))
+					Expect(mainContainer.Args).To(Equal([]string{"crash-loop"}))
+				})
+			})
+		})
+
+		Context("with the host network", func() {
+			BeforeEach(func() {
+				enabled := true
+				cluster.Spec.Routing.UseHostNetwork = &enabled
+			})
+
+			It("should not support the split image", func() {
+				_, err = GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).To(HaveOccurred())
+			})
+
+			Context("with the unified image", func() {
+				BeforeEach(func() {
+					imageType := fdbtypes.ImageTypeUnified
+					cluster.Spec.ImageType = &imageType
+					spec, err = GetPodSpec(cluster, fdbtypes.ProcessClassLog, 1)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should use the host network", func() {
+					Expect(spec.HostNetwork).To(BeTrue())
+					Expect(spec.DNSPolicy).To(Equal(corev1.DNSClusterFirstWithHostNet))
+				})
+
+				It("should prevent pods of the same process class from running on the same node", func() {
+					Expect(spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).To(Equal([]corev1.PodAffinityTerm{
+						{
+							TopologyKey: "kubernetes.io/hostname",
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+								OldFDBClusterLabel:      cluster.Name,
+								OldFDBProcessClassLabel: string(fdbtypes.ProcessClassLog),
+							}},
+						},
+					}))
+				})
+
+				It("should build a pod for every process class", func() {
+					processClasses := []fdbtypes.ProcessClass{
+						fdbtypes.ProcessClassStorage, fdbtypes.ProcessClassLog, fdbtypes.ProcessClassTransaction,
+						fdbtypes.ProcessClassStateless, fdbtypes.ProcessClassGeneral, fdbtypes.ProcessClassClusterController,
+						fdbtypes.ProcessClassCoordinator, fdbtypes.ProcessClassTest, fdbtypes.ProcessClassCommitProxy,
+						fdbtypes.ProcessClassGrvProxy, "resolution", "tester", "proxy", "master", "router", "fast_restore",
+						"data_distributor", "ratekeeper", "storage_cache", "backup", "resolver",
+					}
+
+					for _, processClass := range processClasses {
+						_, err = GetPodSpec(cluster, processClass, 1)
+						Expect(err).NotTo(HaveOccurred(), string(processClass))
+					}
+				})
+
+				It("should not support custom process classes", func() {
+					_, err = GetPodSpec(cluster, fdbtypes.ProcessClass("custom"), 1)
+					Expect(err).To(HaveOccurred())
+				})
+			})
+		})
+
+		Context("when setting an image with a tag with override", func() {
+			BeforeEach(func() {
+				allowTagOverride := true
+				processSetting := cluster.Spec.Processes[fdbtypes.ProcessClassGeneral]
+				processSetting.PodTemplate = &corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "foundationdb",
+								Image: "foundationdb/foundationdb:peperoni",
+							},
+							{
+								Name:  "foundationdb-kubernetes-sidecar",
+								Image: "foundationdb/foundationdb:peperoni",
+							},
+						},
+						InitContainers: []corev1.Container{
+							{
+								Name:  "foundationdb-kubernetes-init",
+								Image: "foundationdb/foundationdb:peperoni",
+							},
+						},
+					},
+				}
+				processSetting.AllowTagOverride = &allowTagOverride
+				cluster.Spec.Processes[fdbtypes.ProcessClassGeneral] = processSetting
+
+				err = NormalizeClusterSpec(cluster, DeprecationOptions{UseFutureDefaults: false, OnlyShowChanges: true})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should set the image name with tag", func() {
+				spec, err := GetPodSpec(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+
+				for _, c := range spec.Containers {
+					Expect(c.Image).To(Equal("foundationdb/foundationdb:peperoni"))
+				}
+			})
+		})
+	})
+
+	Describe("GetService", func() {
+		var service *corev1.Service
+
+		Context("with a basic storage instance", func() {
+			BeforeEach(func() {
+				service, err = GetService(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should set the metadata on the service", func() {
+				Expect(service.Namespace).To(Equal("my-ns"))
+				Expect(service.Name).To(Equal(fmt.Sprintf("%s-storage-1", cluster.Name)))
+				Expect(service.ObjectMeta.Labels).To(Equal(map[string]string{
+					OldFDBClusterLabel:              cluster.Name,
+					fdbtypes.FDBClusterLabel:        cluster.Name,
+					OldFDBProcessClassLabel:         string(fdbtypes.ProcessClassStorage),
+					fdbtypes.FDBProcessClassLabel:   string(fdbtypes.ProcessClassStorage),
+					OldFDBProcessGroupIDLabel:       "storage-1",
+					fdbtypes.FDBProcessGroupIDLabel: "storage-1",
+				}))
+			})
+
+			It("should set the spec on the service", func() {
+				Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+
+				Expect(len(service.Spec.Ports)).To(Equal(2))
+				Expect(service.Spec.Ports[0].Name).To(Equal("tls"))
+				Expect(service.Spec.Ports[0].Port).To(Equal(int32(4500)))
+				Expect(service.Spec.Ports[1].Name).To(Equal("non-tls"))
+				Expect(service.Spec.Ports[1].Port).To(Equal(int32(4501)))
+
+				Expect(service.Spec.Selector).To(Equal(map[string]string{
+					OldFDBClusterLabel:        cluster.Name,
+					OldFDBProcessGroupIDLabel: "storage-1",
 				}))
 			})
 		})
 
+		Context("with a load balancer as public service type", func() {
+			BeforeEach(func() {
+				serviceType := corev1.ServiceTypeLoadBalancer
+				cluster.Spec.Routing.PublicServiceType = &serviceType
+				service, err = GetService(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should create a load balancer service", func() {
+				Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+			})
+		})
+
+		Context("with only the coordinators exposed through node ports", func() {
+			BeforeEach(func() {
+				serviceType := corev1.ServiceTypeNodePort
+				scope := fdbtypes.PublicServiceScopeCoordinators
+				cluster.Spec.Routing.PublicServiceType = &serviceType
+				cluster.Spec.Routing.PublicServiceScope = &scope
+			})
+
+			It("should create a node port service for the coordinator candidates", func() {
+				service, err = GetService(cluster, fdbtypes.ProcessClassLog, 1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeNodePort))
+			})
+
+			It("should create a cluster IP service for the other processes", func() {
+				service, err = GetService(cluster, fdbtypes.ProcessClassStateless, 1)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(service.Spec.Type).To(Equal(corev1.ServiceTypeClusterIP))
+			})
+		})
+
 		Context("with custom resource labels", func() {
 			BeforeEach(func() {
 				cluster.Spec.LabelConfig = fdbtypes.LabelConfig{
@@ -2205,6 +2811,56 @@ var _ = Describe("pod_models", func() {
 				}))
 			})
 		})
+
+		Context("with custom service metadata", func() {
+			BeforeEach(func() {
+				cluster.Spec.Routing.ServiceMetadata = &metav1.ObjectMeta{
+					Labels:      map[string]string{"fdb-service-label": "value1"},
+					Annotations: map[string]string{"fdb-service-annotation": "value2"},
+				}
+				cluster.Spec.LabelConfig.ResourceAnnotations = map[string]string{
+					"fdb-resource-annotation": "value3",
+				}
+
+				service, err = GetService(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should set the metadata on the service", func() {
+				Expect(service.Name).To(Equal(fmt.Sprintf("%s-storage-1", cluster.Name)))
+				Expect(service.ObjectMeta.Labels).To(HaveKeyWithValue("fdb-service-label", "value1"))
+				Expect(service.ObjectMeta.Labels).To(HaveKeyWithValue(fdbtypes.FDBProcessGroupIDLabel, "storage-1"))
+				Expect(service.ObjectMeta.Annotations).To(Equal(map[string]string{
+					"fdb-service-annotation":  "value2",
+					"fdb-resource-annotation": "value3",
+				}))
+			})
+		})
+
+		Context("with the IPv6 pod IP family", func() {
+			BeforeEach(func() {
+				family := 6
+				cluster.Spec.Routing.PodIPFamily = &family
+
+				service, err = GetService(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should use the IPv6 family for the service", func() {
+				Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol}))
+			})
+		})
+
+		Context("without a pod IP family", func() {
+			BeforeEach(func() {
+				service, err = GetService(cluster, fdbtypes.ProcessClassStorage, 1)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should use the default IP family of the Kubernetes cluster", func() {
+				Expect(service.Spec.IPFamilies).To(BeNil())
+			})
+		})
 	})
 
 	Describe("GetPvc", func() {
@@ -2486,6 +3142,27 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with custom service metadata", func() {
+			BeforeEach(func() {
+				cluster.Spec.Routing.ServiceMetadata = &metav1.ObjectMeta{
+					Labels:      map[string]string{"fdb-service-label": "value1"},
+					Annotations: map[string]string{"fdb-service-annotation": "value2"},
+				}
+			})
+
+			It("should set the metadata on the service", func() {
+				Expect(service.ObjectMeta.Name).To(Equal("operator-test-1"))
+				Expect(service.ObjectMeta.Labels).To(Equal(map[string]string{
+					OldFDBClusterLabel:       "operator-test-1",
+					fdbtypes.FDBClusterLabel: "operator-test-1",
+					"fdb-service-label":      "value1",
+				}))
+				Expect(service.ObjectMeta.Annotations).To(Equal(map[string]string{
+					"fdb-service-annotation": "value2",
+				}))
+			})
+		})
+
 		Context("with the headless service disabled", func() {
 			BeforeEach(func() {
 				enabled = false
@@ -2518,7 +3195,7 @@ var _ = Describe("pod_models", func() {
 
 		Context("with a basic deployment", func() {
 			BeforeEach(func() {
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(deployment).NotTo(BeNil())
 			})
@@ -2663,7 +3340,7 @@ var _ = Describe("pod_models", func() {
 						},
 					},
 				}
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(deployment).NotTo(BeNil())
 			})
@@ -2720,7 +3397,7 @@ var _ = Describe("pod_models", func() {
 						"fdb-test": "test-value",
 					},
 				}
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(deployment).NotTo(BeNil())
 			})
@@ -2733,10 +3410,51 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with a blob credentials secret", func() {
+			var credentials *corev1.Secret
+
+			BeforeEach(func() {
+				backup.Spec.BlobCredentialsSecret = "blob-credentials"
+				credentials = &corev1.Secret{
+					Data: map[string][]byte{
+						BlobCredentialsKey: []byte("credentials-1"),
+					},
+				}
+				deployment, err = GetBackupDeployment(backup, credentials)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment).NotTo(BeNil())
+			})
+
+			It("should mount the secret", func() {
+				Expect(deployment.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+					Name: "blob-credentials",
+					VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+						SecretName: "blob-credentials",
+						Items: []corev1.KeyToPath{
+							{Key: "credentials", Path: "credentials"},
+						},
+					}},
+				}))
+				container := deployment.Spec.Template.Spec.Containers[0]
+				Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "blob-credentials", MountPath: "/var/blob-credentials", ReadOnly: true}))
+				Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "FDB_BLOB_CREDENTIALS", Value: "/var/blob-credentials/credentials"}))
+			})
+
+			It("should change the pod template when the credentials change", func() {
+				originalHash := deployment.Spec.Template.ObjectMeta.Annotations[fdbtypes.BlobCredentialsHashAnnotation]
+				Expect(originalHash).NotTo(BeEmpty())
+
+				credentials.Data[BlobCredentialsKey] = []byte("credentials-2")
+				deployment, err = GetBackupDeployment(backup, credentials)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment.Spec.Template.ObjectMeta.Annotations[fdbtypes.BlobCredentialsHashAnnotation]).NotTo(Equal(originalHash))
+			})
+		})
+
 		Context("with a nil agent count", func() {
 			BeforeEach(func() {
 				backup.Spec.AgentCount = nil
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -2750,7 +3468,7 @@ var _ = Describe("pod_models", func() {
 			BeforeEach(func() {
 				agentCount := 0
 				backup.Spec.AgentCount = &agentCount
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -2772,7 +3490,7 @@ var _ = Describe("pod_models", func() {
 						}},
 					},
 				}
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 			})
 
@@ -2788,7 +3506,7 @@ var _ = Describe("pod_models", func() {
 		Context("with the sidecar require-not-empty field", func() {
 			BeforeEach(func() {
 				backup.Spec.Version = fdbtypes.Versions.WithSidecarCrashOnEmpty.String()
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(deployment).NotTo(BeNil())
 			})
@@ -2815,7 +3533,7 @@ var _ = Describe("pod_models", func() {
 		Context("without the sidecar require-not-empty field", func() {
 			BeforeEach(func() {
 				backup.Spec.Version = fdbtypes.Versions.WithoutSidecarCrashOnEmpty.String()
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(deployment).NotTo(BeNil())
 			})
@@ -2837,10 +3555,38 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 
+		Context("with custom image configs", func() {
+			BeforeEach(func() {
+				backup.Spec.ImageConfigs = []fdbtypes.ImageConfig{
+					{BaseImage: "registry.example.com/foundationdb/foundationdb"},
+				}
+				backup.Spec.SidecarImageConfigs = []fdbtypes.ImageConfig{
+					{BaseImage: "registry.example.com/foundationdb/foundationdb-kubernetes-sidecar", TagSuffix: "-2"},
+					{Version: "1.0.0", Tag: "ignored"},
+				}
+				deployment, err = GetBackupDeployment(backup, nil)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment).NotTo(BeNil())
+			})
+
+			It("should use the custom image for the backup agent", func() {
+				Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal(fmt.Sprintf("registry.example.com/foundationdb/foundationdb:%s", cluster.Spec.Version)))
+			})
+
+			It("should use the custom image for the init container", func() {
+				Expect(deployment.Spec.Template.Spec.InitContainers[0].Image).To(Equal(fmt.Sprintf("registry.example.com/foundationdb/foundationdb-kubernetes-sidecar:%s-2", cluster.Spec.Version)))
+			})
+
+			It("should not modify the image configs in the spec", func() {
+				Expect(backup.Spec.ImageConfigs).To(HaveLen(1))
+				Expect(backup.Spec.SidecarImageConfigs).To(HaveLen(2))
+			})
+		})
+
 		Context("with customParameters", func() {
 			BeforeEach(func() {
 				backup.Spec.CustomParameters = []string{"customParameter=1337"}
-				deployment, err = GetBackupDeployment(backup)
+				deployment, err = GetBackupDeployment(backup, nil)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(deployment).NotTo(BeNil())
 			})
@@ -2861,6 +3607,336 @@ var _ = Describe("pod_models", func() {
 		})
 	})
 
+	Describe("GetDRDeployment", func() {
+		var dr *fdbtypes.FoundationDBDR
+		var destination *fdbtypes.FoundationDBCluster
+		var deployment *appsv1.Deployment
+
+		BeforeEach(func() {
+			destination = CreateDefaultCluster()
+			destination.Name = "operator-test-2"
+			dr = CreateDefaultDR(cluster, destination)
+			Expect(dr.Name).To(Equal("operator-test-1"))
+		})
+
+		Context("with a basic deployment", func() {
+			BeforeEach(func() {
+				deployment, err = GetDRDeployment(dr)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment).NotTo(BeNil())
+			})
+
+			It("should set the metadata for the deployment", func() {
+				Expect(deployment.ObjectMeta.Name).To(Equal("operator-test-1-dr-agents"))
+				Expect(len(deployment.ObjectMeta.OwnerReferences)).To(Equal(1))
+				Expect(deployment.ObjectMeta.Labels).To(Equal(map[string]string{
+					"foundationdb.org/dr-for": string(dr.ObjectMeta.UID),
+				}))
+				Expect(deployment.ObjectMeta.Annotations).To(HaveKey("foundationdb.org/last-applied-spec"))
+			})
+
+			It("should set the replication factor to the specified agent count", func() {
+				Expect(deployment.Spec.Replicas).NotTo(BeNil())
+				Expect(*deployment.Spec.Replicas).To(Equal(int32(3)))
+			})
+
+			It("should have one container and two init containers", func() {
+				Expect(len(deployment.Spec.Template.Spec.Containers)).To(Equal(1))
+				Expect(len(deployment.Spec.Template.Spec.InitContainers)).To(Equal(2))
+				Expect(deployment.Spec.Template.Spec.InitContainers[0].Name).To(Equal("foundationdb-kubernetes-init-source"))
+				Expect(deployment.Spec.Template.Spec.InitContainers[1].Name).To(Equal("foundationdb-kubernetes-init-destination"))
+			})
+
+			It("should have the default volumes", func() {
+				Expect(deployment.Spec.Template.Spec.Volumes).To(Equal([]corev1.Volume{
+					{Name: "logs", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					{Name: "source-dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					{Name: "destination-dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+					{
+						Name: "source-config-map",
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "operator-test-1-config"},
+							Items: []corev1.KeyToPath{
+								{Key: ClusterFileKey, Path: "fdb.cluster"},
+							},
+						}},
+					},
+					{
+						Name: "destination-config-map",
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "operator-test-2-config"},
+							Items: []corev1.KeyToPath{
+								{Key: ClusterFileKey, Path: "fdb.cluster"},
+							},
+						}},
+					},
+				}))
+			})
+
+			It("should set the image and command for the DR agent", func() {
+				container := deployment.Spec.Template.Spec.Containers[0]
+				Expect(container.Name).To(Equal("foundationdb"))
+				Expect(container.Image).To(Equal(fmt.Sprintf("foundationdb/foundationdb:%s", cluster.Spec.Version)))
+				Expect(container.Command).To(Equal([]string{"dr_agent"}))
+				Expect(container.Args).To(Equal([]string{
+					"--log",
+					"--logdir",
+					"/var/log/fdb-trace-logs",
+					"-s",
+					"/var/source-dynamic-conf/fdb.cluster",
+					"-d",
+					"/var/destination-dynamic-conf/fdb.cluster",
+				}))
+			})
+
+			It("should mount the cluster files for both clusters", func() {
+				container := deployment.Spec.Template.Spec.InitContainers[0]
+				Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{
+					{Name: "source-config-map", MountPath: "/var/input-files"},
+					{Name: "source-dynamic-conf", MountPath: "/var/output-files"},
+				}))
+
+				container = deployment.Spec.Template.Spec.InitContainers[1]
+				Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{
+					{Name: "destination-config-map", MountPath: "/var/input-files"},
+					{Name: "destination-dynamic-conf", MountPath: "/var/output-files"},
+				}))
+			})
+		})
+
+		Context("with custom image configs", func() {
+			BeforeEach(func() {
+				dr.Spec.ImageConfigs = []fdbtypes.ImageConfig{
+					{BaseImage: "registry.example.com/foundationdb/foundationdb", Tag: "custom"},
+				}
+				dr.Spec.SidecarImageConfigs = []fdbtypes.ImageConfig{
+					{BaseImage: "registry.example.com/foundationdb/foundationdb-kubernetes-sidecar"},
+				}
+				deployment, err = GetDRDeployment(dr)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should use the custom image for the DR agent", func() {
+				Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("registry.example.com/foundationdb/foundationdb:custom"))
+			})
+
+			It("should use the custom image for the init containers", func() {
+				for _, container := range deployment.Spec.Template.Spec.InitContainers {
+					Expect(container.Image).To(Equal(fmt.Sprintf("registry.example.com/foundationdb/foundationdb-kubernetes-sidecar:%s-1", dr.Spec.Version)))
+				}
+			})
+		})
+
+		Context("with an agent count of zero", func() {
+			BeforeEach(func() {
+				agentCount := 0
+				dr.Spec.AgentCount = &agentCount
+				deployment, err = GetDRDeployment(dr)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not create a deployment", func() {
+				Expect(deployment).To(BeNil())
+			})
+		})
+	})
+
+	Describe("GetMetricsExporterDeployment", func() {
+		var deployment *appsv1.Deployment
+
+		Context("with the exporter disabled", func() {
+			BeforeEach(func() {
+				deployment, err = GetMetricsExporterDeployment(cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not create a deployment", func() {
+				Expect(deployment).To(BeNil())
+			})
+		})
+
+		Context("with the exporter enabled", func() {
+			BeforeEach(func() {
+				enabled := true
+				cluster.Spec.MetricsExporter = fdbtypes.MetricsExporterConfig{
+					Enabled: &enabled,
+					PodTemplateSpec: &corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{
+								Name:  "exporter",
+								Image: "test/exporter:1.0.0",
+							}},
+						},
+					},
+				}
+			})
+
+			Context("with a basic deployment", func() {
+				BeforeEach(func() {
+					deployment, err = GetMetricsExporterDeployment(cluster)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(deployment).NotTo(BeNil())
+				})
+
+				It("should set the metadata for the deployment", func() {
+					Expect(deployment.ObjectMeta.Name).To(Equal("operator-test-1-metrics-exporter"))
+					Expect(len(deployment.ObjectMeta.OwnerReferences)).To(Equal(1))
+					Expect(deployment.ObjectMeta.Labels).To(Equal(map[string]string{
+						fdbtypes.FDBClusterLabel:                cluster.Name,
+						"fdb-cluster-name":                      cluster.Name,
+						"foundationdb.org/metrics-exporter-for": string(cluster.ObjectMeta.UID),
+					}))
+					Expect(deployment.ObjectMeta.Annotations).To(HaveKey("foundationdb.org/last-applied-spec"))
+				})
+
+				It("should run a single replica", func() {
+					Expect(deployment.Spec.Replicas).NotTo(BeNil())
+					Expect(*deployment.Spec.Replicas).To(Equal(int32(1)))
+				})
+
+				It("should have one container and one init container", func() {
+					Expect(len(deployment.Spec.Template.Spec.Containers)).To(Equal(1))
+					Expect(len(deployment.Spec.Template.Spec.InitContainers)).To(Equal(1))
+					Expect(deployment.Spec.Template.Spec.InitContainers[0].Name).To(Equal("foundationdb-kubernetes-init"))
+				})
+
+				It("should configure the exporter container", func() {
+					container := deployment.Spec.Template.Spec.Containers[0]
+					Expect(container.Image).To(Equal("test/exporter:1.0.0"))
+					Expect(container.Env).To(Equal([]corev1.EnvVar{
+						{Name: "FDB_CLUSTER_FILE", Value: "/var/dynamic-conf/fdb.cluster"},
+					}))
+					Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{
+						{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
+					}))
+					Expect(container.Ports).To(Equal([]corev1.ContainerPort{
+						{Name: "metrics", ContainerPort: MetricsExporterPort},
+					}))
+				})
+
+				It("should add the scrape annotations to the pod", func() {
+					Expect(deployment.Spec.Template.ObjectMeta.Annotations).To(Equal(map[string]string{
+						"prometheus.io/scrape": "true",
+						"prometheus.io/port":   "9090",
+					}))
+				})
+
+				It("should have the default volumes", func() {
+					Expect(deployment.Spec.Template.Spec.Volumes).To(Equal([]corev1.Volume{
+						{Name: "dynamic-conf", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+						{
+							Name: "config-map",
+							VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+								LocalObjectReference: corev1.LocalObjectReference{Name: "operator-test-1-config"},
+								Items: []corev1.KeyToPath{
+									{Key: ClusterFileKey, Path: "fdb.cluster"},
+								},
+							}},
+						},
+					}))
+				})
+			})
+
+			Context("with a custom metrics port", func() {
+				BeforeEach(func() {
+					cluster.Spec.MetricsExporter.PodTemplateSpec.Spec.Containers[0].Ports = []corev1.ContainerPort{
+						{Name: "metrics", ContainerPort: 8080},
+					}
+					deployment, err = GetMetricsExporterDeployment(cluster)
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("should keep the custom port", func() {
+					Expect(deployment.Spec.Template.Spec.Containers[0].Ports).To(Equal([]corev1.ContainerPort{
+						{Name: "metrics", ContainerPort: 8080},
+					}))
+					Expect(deployment.Spec.Template.ObjectMeta.Annotations["prometheus.io/port"]).To(Equal("8080"))
+				})
+			})
+
+			Context("without an exporter image", func() {
+				BeforeEach(func() {
+					cluster.Spec.MetricsExporter.PodTemplateSpec.Spec.Containers[0].Image = ""
+					deployment, err = GetMetricsExporterDeployment(cluster)
+				})
+
+				It("should return an error", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(deployment).To(BeNil())
+				})
+			})
+		})
+	})
+
+	Describe("GetConsistencyCheckDeployment", func() {
+		var deployment *appsv1.Deployment
+
+		Context("with the consistency check unmanaged", func() {
+			BeforeEach(func() {
+				deployment, err = GetConsistencyCheckDeployment(cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not create a deployment", func() {
+				Expect(deployment).To(BeNil())
+			})
+		})
+
+		Context("with the consistency check managed", func() {
+			BeforeEach(func() {
+				enabled := true
+				cluster.Spec.ConsistencyCheck.Enabled = &enabled
+				deployment, err = GetConsistencyCheckDeployment(cluster)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(deployment).NotTo(BeNil())
+			})
+
+			It("should set the metadata for the deployment", func() {
+				Expect(deployment.ObjectMeta.Name).To(Equal("operator-test-1-consistency-check"))
+				Expect(len(deployment.ObjectMeta.OwnerReferences)).To(Equal(1))
+				Expect(deployment.ObjectMeta.Labels).To(HaveKeyWithValue("foundationdb.org/consistency-check-for", string(cluster.ObjectMeta.UID)))
+				Expect(deployment.ObjectMeta.Annotations).To(HaveKey("foundationdb.org/last-applied-spec"))
+			})
+
+			It("should run the consistency checker", func() {
+				Expect(len(deployment.Spec.Template.Spec.Containers)).To(Equal(1))
+				container := deployment.Spec.Template.Spec.Containers[0]
+				Expect(container.Name).To(Equal("foundationdb"))
+				Expect(container.Image).To(Equal(fmt.Sprintf("foundationdb/foundationdb:%s", cluster.Spec.Version)))
+				Expect(container.Command).To(Equal([]string{"fdbserver"}))
+				Expect(container.Args).To(Equal([]string{
+					"-r", "consistencycheck",
+					"-C", "/var/dynamic-conf/fdb.cluster",
+					"--logdir", "/var/log/fdb-trace-logs",
+				}))
+				Expect(container.VolumeMounts).To(Equal([]corev1.VolumeMount{
+					{Name: "dynamic-conf", MountPath: "/var/dynamic-conf"},
+					{Name: "fdb-trace-logs", MountPath: "/var/log/fdb-trace-logs"},
+				}))
+			})
+
+			It("should copy the cluster file in an init container", func() {
+				Expect(len(deployment.Spec.Template.Spec.InitContainers)).To(Equal(1))
+				Expect(deployment.Spec.Template.Spec.InitContainers[0].Name).To(Equal("foundationdb-kubernetes-init"))
+			})
+		})
+
+		Context("with the checker disabled", func() {
+			BeforeEach(func() {
+				enabled := true
+				runChecker := false
+				cluster.Spec.ConsistencyCheck.Enabled = &enabled
+				cluster.Spec.ConsistencyCheck.RunChecker = &runChecker
+				deployment, err = GetConsistencyCheckDeployment(cluster)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should not create a deployment", func() {
+				Expect(deployment).To(BeNil())
+			})
+		})
+	})
+
 	Context("Get image for container", func() {
 		type testCase struct {
 			imageName     string
@@ -2927,7 +4003,7 @@ var _ = Describe("pod_models", func() {
 
 			DescribeTable("should return the correct image",
 				func(input testCase, expected string) {
-					err = configureSidecarContainerForCluster(cluster, input.container, input.initMode, input.instanceID, input.allowOverride)
+					err = configureSidecarContainerForCluster(cluster, input.container, input.initMode, fdbtypes.ProcessClassStorage, input.instanceID, input.allowOverride)
 					if input.hasError {
 						Expect(err).To(HaveOccurred())
 					} else {
@@ -3057,4 +4133,42 @@ var _ = Describe("pod_models", func() {
 			})
 		})
 	})
+
+	DescribeTable("getting the servers per Pod for a process class",
+		func(pod *corev1.Pod, pClass fdbtypes.ProcessClass, expected int) {
+			serversPerPod, err := GetServersPerPodForPod(pod, pClass)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serversPerPod).To(Equal(expected))
+		},
+		Entry("storage Pod with multiple storage servers",
+			&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Env: []corev1.EnvVar{{Name: "STORAGE_SERVERS_PER_POD", Value: "2"}},
+			}}}},
+			fdbtypes.ProcessClassStorage,
+			2),
+		Entry("log Pod with multiple log servers",
+			&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Env: []corev1.EnvVar{{Name: "LOG_SERVERS_PER_POD", Value: "3"}},
+			}}}},
+			fdbtypes.ProcessClassLog,
+			3),
+		Entry("transaction Pod with multiple log servers",
+			&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Env: []corev1.EnvVar{{Name: "LOG_SERVERS_PER_POD", Value: "2"}},
+			}}}},
+			fdbtypes.ProcessClassTransaction,
+			2),
+		Entry("log Pod without the log servers setting",
+			&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Env: []corev1.EnvVar{{Name: "STORAGE_SERVERS_PER_POD", Value: "2"}},
+			}}}},
+			fdbtypes.ProcessClassLog,
+			1),
+		Entry("stateless Pod",
+			&corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Env: []corev1.EnvVar{{Name: "LOG_SERVERS_PER_POD", Value: "2"}},
+			}}}},
+			fdbtypes.ProcessClassStateless,
+			1),
+	)
 })
@@ -0,0 +1,139 @@
+/*
+ * role_count_recommendations.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"math"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+)
+
+// GetRecommendedRoleCounts recommends the number of proxies, resolvers and
+// logs based on the load that the database status reports. The operator
+// recommends more processes for a role when its load is above the threshold,
+// and fewer processes when its load is below half of the threshold. Between
+// these thresholds the previous recommendation is kept, so that the
+// recommendations do not flap. The recommendations are never higher than the
+// maximum role counts, and a role is only recommended when the
+// recommendation is higher than the configured role count. This returns nil
+// if there is no recommendation.
+func GetRecommendedRoleCounts(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus, previous *fdbtypes.RoleCounts) *fdbtypes.RoleCounts {
+	previousCounts := fdbtypes.RoleCounts{}
+	if previous != nil {
+		previousCounts = *previous
+	}
+
+	roleCounts := make(map[fdbtypes.ProcessRole]int)
+	roleUsage := make(map[fdbtypes.ProcessRole]float64)
+	for _, process := range status.Cluster.Processes {
+		// A process can have multiple roles that count as the same role,
+		// e.g. a commit proxy and a GRV proxy, and its CPU usage must only
+		// be counted once for that role.
+		processRoles := make(map[fdbtypes.ProcessRole]bool, len(process.Roles))
+		for _, role := range process.Roles {
+			processRole := fdbtypes.ProcessRole(role.Role)
+			// Commit proxies and GRV proxies are counted as proxies, since
+			// the proxies role count covers both of them.
+			if processRole == fdbtypes.ProcessRoleCommitProxy || processRole == fdbtypes.ProcessRoleGrvProxy {
+				processRole = fdbtypes.ProcessRoleProxy
+			}
+
+			processRoles[processRole] = true
+		}
+
+		for processRole := range processRoles {
+			roleCounts[processRole]++
+			roleUsage[processRole] += process.CPU.UsageCores
+		}
+	}
+
+	configured := cluster.GetConfiguredRoleCounts()
+	maxCounts := cluster.GetMaxRoleCountsForRecommendations()
+	maxUsage := float64(cluster.GetMaxCPUUsagePercentageForRecommendations()) / 100
+
+	recommended := fdbtypes.RoleCounts{
+		Proxies:   getCountForUsage(roleCounts[fdbtypes.ProcessRoleProxy], roleUsage[fdbtypes.ProcessRoleProxy], maxUsage, previousCounts.Proxies),
+		Resolvers: getCountForUsage(roleCounts[fdbtypes.ProcessRoleResolver], roleUsage[fdbtypes.ProcessRoleResolver], maxUsage, previousCounts.Resolvers),
+		Logs:      getCountForQueueSize(roleCounts[fdbtypes.ProcessRoleLog], status.Cluster.Qos.WorstQueueBytesLogServer, cluster.GetMaxLogQueueBytesForRecommendations(), previousCounts.Logs),
+	}
+
+	recommended.Proxies = boundRecommendation(recommended.Proxies, configured.Proxies, maxCounts.Proxies)
+	recommended.Resolvers = boundRecommendation(recommended.Resolvers, configured.Resolvers, maxCounts.Resolvers)
+	recommended.Logs = boundRecommendation(recommended.Logs, configured.Logs, maxCounts.Logs)
+
+	if recommended == (fdbtypes.RoleCounts{}) {
+		return nil
+	}
+
+	return &recommended
+}
+
+// getCountForUsage returns the number of processes for a role that brings the
+// average CPU usage below the maximum usage. If the average usage is between
+// half of the maximum usage and the maximum usage, this returns the previous
+// recommendation.
+func getCountForUsage(count int, totalUsage float64, maxUsage float64, previous int) int {
+	if count == 0 {
+		return previous
+	}
+
+	averageUsage := totalUsage / float64(count)
+	if averageUsage <= maxUsage && averageUsage >= maxUsage/2 {
+		return previous
+	}
+
+	return int(math.Ceil(totalUsage / maxUsage))
+}
+
+// getCountForQueueSize returns the number of logs based on the queue size of
+// the worst log server. This recommends one more log if the queue is above
+// the maximum size and one less log if the queue is below half of the
+// maximum size. Otherwise this returns the previous recommendation.
+func getCountForQueueSize(count int, queueBytes int64, maxQueueBytes int64, previous int) int {
+	if count == 0 {
+		return previous
+	}
+
+	if queueBytes > maxQueueBytes {
+		return count + 1
+	}
+
+	if queueBytes < maxQueueBytes/2 {
+		return count - 1
+	}
+
+	return previous
+}
+
+// boundRecommendation limits a recommendation to the maximum count. This
+// returns 0 if the recommendation is not higher than the configured count,
+// since the configured count applies in that case.
+func boundRecommendation(recommendation int, configured int, maximum int) int {
+	if recommendation > maximum {
+		recommendation = maximum
+	}
+
+	if recommendation <= configured {
+		return 0
+	}
+
+	return recommendation
+}
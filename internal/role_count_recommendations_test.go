@@ -0,0 +1,193 @@
+/*
+ * role_count_recommendations_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("role_count_recommendations", func() {
+	// getStatus builds a status with one process per role, using the CPU
+	// usage of the role for every process.
+	getStatus := func(roles map[fdbtypes.ProcessRole][]float64, logQueueBytes int64) *fdbtypes.FoundationDBStatus {
+		status := &fdbtypes.FoundationDBStatus{
+			Cluster: fdbtypes.FoundationDBStatusClusterInfo{
+				Processes: make(map[string]fdbtypes.FoundationDBStatusProcessInfo),
+				Qos: fdbtypes.FoundationDBStatusQosInfo{
+					WorstQueueBytesLogServer: logQueueBytes,
+				},
+			},
+		}
+
+		for role, usages := range roles {
+			for index, usage := range usages {
+				status.Cluster.Processes[string(role)+string(rune('a'+index))] = fdbtypes.FoundationDBStatusProcessInfo{
+					Roles: []fdbtypes.FoundationDBStatusProcessRoleInfo{{Role: string(role)}},
+					CPU:   fdbtypes.FoundationDBStatusProcessCPUInfo{UsageCores: usage},
+				}
+			}
+		}
+
+		return status
+	}
+
+	type testCase struct {
+		roles         map[fdbtypes.ProcessRole][]float64
+		logQueueBytes int64
+		previous      *fdbtypes.RoleCounts
+		expected      *fdbtypes.RoleCounts
+	}
+
+	DescribeTable("should recommend the role counts",
+		func(tc testCase) {
+			cluster := &fdbtypes.FoundationDBCluster{}
+			Expect(GetRecommendedRoleCounts(cluster, getStatus(tc.roles, tc.logQueueBytes), tc.previous)).To(Equal(tc.expected))
+		},
+		Entry("with a database below the thresholds",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy:    {0.5, 0.6, 0.4},
+					fdbtypes.ProcessRoleResolver: {0.3},
+					fdbtypes.ProcessRoleLog:      {0.2, 0.2, 0.2},
+				},
+				logQueueBytes: 1000,
+				expected:      nil,
+			}),
+		Entry("with busy proxies",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy: {0.9, 0.9, 0.9},
+				},
+				expected: &fdbtypes.RoleCounts{Proxies: 4},
+			}),
+		Entry("with busy commit proxies and GRV proxies",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleCommitProxy: {0.9, 0.9},
+					fdbtypes.ProcessRoleGrvProxy:    {0.9},
+				},
+				expected: &fdbtypes.RoleCounts{Proxies: 4},
+			}),
+		Entry("with a busy resolver",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleResolver: {1.0},
+				},
+				expected: &fdbtypes.RoleCounts{Resolvers: 2},
+			}),
+		Entry("with a large log queue",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleLog: {0.2, 0.2, 0.2},
+				},
+				logQueueBytes: 2000000000,
+				expected:      &fdbtypes.RoleCounts{Logs: 4},
+			}),
+		Entry("with very busy proxies",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy: {1.0, 1.0, 1.0},
+				},
+				expected: &fdbtypes.RoleCounts{Proxies: 5},
+			}),
+		Entry("with proxies that need more than the maximum count",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy: {1.0, 1.0, 1.0, 1.0, 1.0},
+				},
+				previous: &fdbtypes.RoleCounts{Proxies: 5},
+				expected: &fdbtypes.RoleCounts{Proxies: 6},
+			}),
+		Entry("with a previous recommendation and a moderate load",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy: {0.5, 0.5, 0.5, 0.5},
+				},
+				previous: &fdbtypes.RoleCounts{Proxies: 4},
+				expected: &fdbtypes.RoleCounts{Proxies: 4},
+			}),
+		Entry("with a previous recommendation and a decreasing load",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy: {0.34, 0.34, 0.34, 0.34, 0.34, 0.34, 0.34},
+				},
+				previous: &fdbtypes.RoleCounts{Proxies: 6},
+				expected: &fdbtypes.RoleCounts{Proxies: 4},
+			}),
+		Entry("with a previous recommendation and a database below the thresholds",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleProxy: {0.1, 0.1, 0.1, 0.1},
+				},
+				previous: &fdbtypes.RoleCounts{Proxies: 4},
+				expected: nil,
+			}),
+		Entry("with a previous log recommendation and a small log queue",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleLog: {0.2, 0.2, 0.2, 0.2, 0.2},
+				},
+				logQueueBytes: 1000,
+				previous:      &fdbtypes.RoleCounts{Logs: 5},
+				expected:      &fdbtypes.RoleCounts{Logs: 4},
+			}),
+		Entry("with a previous log recommendation and a moderate log queue",
+			testCase{
+				roles: map[fdbtypes.ProcessRole][]float64{
+					fdbtypes.ProcessRoleLog: {0.2, 0.2, 0.2, 0.2, 0.2},
+				},
+				logQueueBytes: 700000000,
+				previous:      &fdbtypes.RoleCounts{Logs: 5},
+				expected:      &fdbtypes.RoleCounts{Logs: 5},
+			}),
+	)
+
+	When("a custom maximum is set", func() {
+		It("should not recommend more than the maximum", func() {
+			cluster := &fdbtypes.FoundationDBCluster{}
+			cluster.Spec.AutomationOptions.RoleCountRecommendations.MaxRoleCounts = fdbtypes.RoleCounts{Proxies: 4}
+			status := getStatus(map[fdbtypes.ProcessRole][]float64{
+				fdbtypes.ProcessRoleProxy: {1.0, 1.0, 1.0},
+			}, 0)
+			Expect(GetRecommendedRoleCounts(cluster, status, nil)).To(Equal(&fdbtypes.RoleCounts{Proxies: 4}))
+		})
+	})
+
+	When("a process has a commit proxy and a GRV proxy", func() {
+		It("should only count the process once", func() {
+			cluster := &fdbtypes.FoundationDBCluster{}
+			status := getStatus(map[fdbtypes.ProcessRole][]float64{
+				fdbtypes.ProcessRoleCommitProxy: {0.6, 0.6},
+			}, 0)
+			status.Cluster.Processes["shared"] = fdbtypes.FoundationDBStatusProcessInfo{
+				Roles: []fdbtypes.FoundationDBStatusProcessRoleInfo{
+					{Role: string(fdbtypes.ProcessRoleCommitProxy)},
+					{Role: string(fdbtypes.ProcessRoleGrvProxy)},
+				},
+				CPU: fdbtypes.FoundationDBStatusProcessCPUInfo{UsageCores: 0.6},
+			}
+			Expect(GetRecommendedRoleCounts(cluster, status, nil)).To(BeNil())
+		})
+	})
+})
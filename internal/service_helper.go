@@ -21,6 +21,8 @@
 package internal
 
 import (
+	"strconv"
+
 	"github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	v1 "k8s.io/api/core/v1"
 )
@@ -33,7 +35,7 @@ func GetHeadlessService(cluster *v1beta1.FoundationDBCluster) *v1.Service {
 	}
 
 	service := &v1.Service{
-		ObjectMeta: GetObjectMetadata(cluster, nil, "", ""),
+		ObjectMeta: GetObjectMetadata(cluster, cluster.Spec.Routing.ServiceMetadata, "", ""),
 	}
 	service.ObjectMeta.Name = cluster.ObjectMeta.Name
 	service.Spec.ClusterIP = "None"
@@ -41,3 +43,75 @@ func GetHeadlessService(cluster *v1beta1.FoundationDBCluster) *v1.Service {
 
 	return service
 }
+
+// GetPublicIPFromService returns the IP that the processes behind a service
+// should use as their public IP. For services of the type LoadBalancer this
+// is the IP of the first ingress, otherwise it is the cluster IP.
+func GetPublicIPFromService(service *v1.Service) string {
+	if service.Spec.Type == v1.ServiceTypeLoadBalancer {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return ingress.IP
+			}
+		}
+
+		return ""
+	}
+
+	return service.Spec.ClusterIP
+}
+
+// HasOnlyHostnameIngress determines whether the load balancer of a service
+// has ingress points with a hostname but none with an IP. Some cloud
+// providers only assign hostnames to their load balancers, which cannot be
+// used as the public address of a FoundationDB process.
+func HasOnlyHostnameIngress(service *v1.Service) bool {
+	if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+		return false
+	}
+
+	hasHostname := false
+	for _, ingress := range service.Status.LoadBalancer.Ingress {
+		if ingress.IP != "" {
+			return false
+		}
+
+		if ingress.Hostname != "" {
+			hasHostname = true
+		}
+	}
+
+	return hasHostname
+}
+
+// GetNodePortAnnotations returns the annotations with the node ports that a
+// pod advertises, based on the ports of its service. This returns false if
+// the service does not have a node port for every port of the process yet.
+func GetNodePortAnnotations(cluster *v1beta1.FoundationDBCluster, processClass v1beta1.ProcessClass, service *v1.Service) (map[string]string, bool) {
+	annotations := make(map[string]string, 2)
+	for _, port := range service.Spec.Ports {
+		if port.NodePort == 0 {
+			return nil, false
+		}
+
+		switch int(port.Port) {
+		case cluster.GetProcessPortForClass(processClass, 1, true):
+			annotations[v1beta1.NodePortTLSAnnotation] = strconv.Itoa(int(port.NodePort))
+		case cluster.GetProcessPortForClass(processClass, 1, false):
+			annotations[v1beta1.NodePortAnnotation] = strconv.Itoa(int(port.NodePort))
+		}
+	}
+
+	if len(annotations) != 2 {
+		return nil, false
+	}
+
+	return annotations, true
+}
+
+// UsesNodePorts determines whether a pod advertises the node ports of its
+// service.
+func UsesNodePorts(pod *v1.Pod) bool {
+	_, present := pod.ObjectMeta.Annotations[v1beta1.NodePortAnnotation]
+	return present
+}
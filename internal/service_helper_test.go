@@ -0,0 +1,111 @@
+/*
+ * service_helper_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+var _ = Describe("service_helper", func() {
+	getService := func(serviceType corev1.ServiceType, ingress ...corev1.LoadBalancerIngress) *corev1.Service {
+		return &corev1.Service{
+			Spec: corev1.ServiceSpec{
+				Type:      serviceType,
+				ClusterIP: "172.0.0.1",
+			},
+			Status: corev1.ServiceStatus{
+				LoadBalancer: corev1.LoadBalancerStatus{Ingress: ingress},
+			},
+		}
+	}
+
+	type testCase struct {
+		service              *corev1.Service
+		expectedIP           string
+		expectedOnlyHostname bool
+	}
+
+	DescribeTable("should get the public IP from the service",
+		func(tc testCase) {
+			Expect(GetPublicIPFromService(tc.service)).To(Equal(tc.expectedIP))
+			Expect(HasOnlyHostnameIngress(tc.service)).To(Equal(tc.expectedOnlyHostname))
+		},
+		Entry("with a ClusterIP service",
+			testCase{
+				service:    getService(corev1.ServiceTypeClusterIP),
+				expectedIP: "172.0.0.1",
+			}),
+		Entry("with a LoadBalancer service without an ingress",
+			testCase{
+				service:    getService(corev1.ServiceTypeLoadBalancer),
+				expectedIP: "",
+			}),
+		Entry("with a LoadBalancer service with an IP ingress",
+			testCase{
+				service:    getService(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{IP: "10.0.0.1"}),
+				expectedIP: "10.0.0.1",
+			}),
+		Entry("with a LoadBalancer service with a hostname ingress",
+			testCase{
+				service:              getService(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{Hostname: "lb.example.com"}),
+				expectedIP:           "",
+				expectedOnlyHostname: true,
+			}),
+		Entry("with a LoadBalancer service with a hostname and an IP ingress",
+			testCase{
+				service:    getService(corev1.ServiceTypeLoadBalancer, corev1.LoadBalancerIngress{Hostname: "lb.example.com"}, corev1.LoadBalancerIngress{IP: "10.0.0.2"}),
+				expectedIP: "10.0.0.2",
+			}),
+	)
+
+	When("getting the node port annotations", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var service *corev1.Service
+
+		BeforeEach(func() {
+			cluster = CreateDefaultCluster()
+			service = getService(corev1.ServiceTypeNodePort)
+			service.Spec.Ports = []corev1.ServicePort{
+				{Name: "tls", Port: 4500, NodePort: 30001},
+				{Name: "non-tls", Port: 4501, NodePort: 30002},
+			}
+		})
+
+		It("should return the node ports for the TLS and non-TLS address", func() {
+			annotations, hasNodePorts := GetNodePortAnnotations(cluster, fdbtypes.ProcessClassStorage, service)
+			Expect(hasNodePorts).To(BeTrue())
+			Expect(annotations).To(Equal(map[string]string{
+				fdbtypes.NodePortTLSAnnotation: "30001",
+				fdbtypes.NodePortAnnotation:    "30002",
+			}))
+		})
+
+		It("should not return node ports while a port has none", func() {
+			service.Spec.Ports[1].NodePort = 0
+			_, hasNodePorts := GetNodePortAnnotations(cluster, fdbtypes.ProcessClassStorage, service)
+			Expect(hasNodePorts).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,172 @@
+/*
+ * status_summary.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// StatusSummaryKey defines the key name of the status summary in the
+	// status ConfigMap.
+	StatusSummaryKey = "status.json"
+)
+
+// StatusSummary provides a trimmed summary of the database status that can be
+// consumed without the FoundationDB client libraries.
+type StatusSummary struct {
+	// Timestamp provides the time when the summary was taken.
+	Timestamp metav1.Time `json:"timestamp"`
+
+	// Generation provides the current generation of the transaction system.
+	Generation int `json:"generation"`
+
+	// Available indicates whether the database is available.
+	Available bool `json:"available"`
+
+	// Healthy indicates whether data distribution is healthy.
+	Healthy bool `json:"healthy"`
+
+	// FullReplication indicates whether the database is fully replicated.
+	FullReplication bool `json:"fullReplication"`
+
+	// DataState provides the name of the state of data distribution.
+	DataState string `json:"dataState,omitempty"`
+
+	// KVBytes provides the total Key Value Bytes in the database.
+	KVBytes int `json:"kvBytes"`
+
+	// MovingDataInFlightBytes provides how many bytes are being actively moved.
+	MovingDataInFlightBytes int `json:"movingDataInFlightBytes"`
+
+	// MovingDataInQueueBytes provides how many bytes are pending data
+	// movement.
+	MovingDataInQueueBytes int `json:"movingDataInQueueBytes"`
+
+	// DataLagSeconds provides the highest lag of any storage server behind
+	// the transaction logs.
+	DataLagSeconds float64 `json:"dataLagSeconds"`
+
+	// DurabilityLagSeconds provides the highest lag of any storage server in
+	// making data durable.
+	DurabilityLagSeconds float64 `json:"durabilityLagSeconds"`
+
+	// Processes provides a summary of every process, keyed by the process ID
+	// in the database status.
+	Processes map[string]ProcessSummary `json:"processes,omitempty"`
+}
+
+// ProcessSummary provides a trimmed summary of the status of a process.
+type ProcessSummary struct {
+	// ProcessGroupID provides the ID of the process group the process belongs
+	// to.
+	ProcessGroupID string `json:"processGroupID,omitempty"`
+
+	// Address provides the address of the process.
+	Address string `json:"address"`
+
+	// ProcessClass provides the process class of the process.
+	ProcessClass fdbtypes.ProcessClass `json:"class"`
+
+	// Excluded indicates whether the process is excluded.
+	Excluded bool `json:"excluded"`
+
+	// DiskFreeBytes provides the number of bytes that are free on the disk
+	// of the process.
+	DiskFreeBytes int64 `json:"diskFreeBytes"`
+
+	// DiskTotalBytes provides the size of the disk of the process in bytes.
+	DiskTotalBytes int64 `json:"diskTotalBytes"`
+}
+
+// GetStatusSummary builds a summary of the database status.
+func GetStatusSummary(status *fdbtypes.FoundationDBStatus, timestamp time.Time) StatusSummary {
+	summary := StatusSummary{
+		Timestamp:               metav1.NewTime(timestamp),
+		Generation:              status.Cluster.Generation,
+		Available:               status.Client.DatabaseStatus.Available,
+		Healthy:                 status.Cluster.Data.State.Healthy,
+		FullReplication:         status.Cluster.FullReplication,
+		DataState:               status.Cluster.Data.State.Name,
+		KVBytes:                 status.Cluster.Data.KVBytes,
+		MovingDataInFlightBytes: status.Cluster.Data.MovingData.InFlightBytes,
+		MovingDataInQueueBytes:  status.Cluster.Data.MovingData.InQueueBytes,
+		DataLagSeconds:          status.Cluster.Qos.WorstDataLagStorageServer.Seconds,
+		DurabilityLagSeconds:    status.Cluster.Qos.WorstDurabilityLagStorageServer.Seconds,
+		Processes:               make(map[string]ProcessSummary, len(status.Cluster.Processes)),
+	}
+
+	for processID, process := range status.Cluster.Processes {
+		summary.Processes[processID] = ProcessSummary{
+			ProcessGroupID: process.Locality[fdbtypes.FDBLocalityInstanceIDKey],
+			Address:        process.Address.String(),
+			ProcessClass:   process.ProcessClass,
+			Excluded:       process.Excluded,
+			DiskFreeBytes:  process.Disk.FreeBytes,
+			DiskTotalBytes: process.Disk.TotalBytes,
+		}
+	}
+
+	return summary
+}
+
+// GetStatusSummaryConfigMapName returns the name of the ConfigMap that holds
+// the status summary for a cluster.
+func GetStatusSummaryConfigMapName(cluster *fdbtypes.FoundationDBCluster) string {
+	return fmt.Sprintf("%s-status", cluster.Name)
+}
+
+// GetStatusSummaryConfigMap builds a config map holding a summary of the
+// database status.
+func GetStatusSummaryConfigMap(cluster *fdbtypes.FoundationDBCluster, status *fdbtypes.FoundationDBStatus, timestamp time.Time) (*corev1.ConfigMap, error) {
+	summary, err := json.Marshal(GetStatusSummary(status, timestamp))
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := GetObjectMetadata(cluster, nil, "", "")
+	metadata.Name = GetStatusSummaryConfigMapName(cluster)
+	metadata.OwnerReferences = BuildOwnerReference(cluster.TypeMeta, cluster.ObjectMeta)
+
+	return &corev1.ConfigMap{
+		ObjectMeta: metadata,
+		Data: map[string]string{
+			StatusSummaryKey: string(summary),
+		},
+	}, nil
+}
+
+// ParseStatusSummary reads the status summary from a config map.
+func ParseStatusSummary(configMap *corev1.ConfigMap) (*StatusSummary, error) {
+	summary := &StatusSummary{}
+	err := json.Unmarshal([]byte(configMap.Data[StatusSummaryKey]), summary)
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
@@ -0,0 +1,114 @@
+/*
+ * status_summary_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package internal
+
+import (
+	"net"
+	"time"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("status_summary", func() {
+	var cluster *fdbtypes.FoundationDBCluster
+	var status *fdbtypes.FoundationDBStatus
+	var timestamp time.Time
+
+	BeforeEach(func() {
+		cluster = CreateDefaultCluster()
+		timestamp = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+		status = &fdbtypes.FoundationDBStatus{
+			Client: fdbtypes.FoundationDBStatusLocalClientInfo{
+				DatabaseStatus: fdbtypes.FoundationDBStatusClientDBStatus{Available: true, Healthy: true},
+			},
+			Cluster: fdbtypes.FoundationDBStatusClusterInfo{
+				Generation:      4,
+				FullReplication: true,
+				Data: fdbtypes.FoundationDBStatusDataStatistics{
+					KVBytes:    1024,
+					MovingData: fdbtypes.FoundationDBStatusMovingData{InFlightBytes: 10, InQueueBytes: 20},
+					State:      fdbtypes.FoundationDBStatusDataState{Healthy: true, Name: "healthy"},
+				},
+				Qos: fdbtypes.FoundationDBStatusQosInfo{
+					WorstDataLagStorageServer:       fdbtypes.FoundationDBStatusLagInfo{Seconds: 1.5},
+					WorstDurabilityLagStorageServer: fdbtypes.FoundationDBStatusLagInfo{Seconds: 5.5},
+				},
+				Processes: map[string]fdbtypes.FoundationDBStatusProcessInfo{
+					"1": {
+						Address:      fdbtypes.ProcessAddress{IPAddress: net.ParseIP("1.1.1.1"), Port: 4501},
+						ProcessClass: fdbtypes.ProcessClassStorage,
+						Locality: map[string]string{
+							fdbtypes.FDBLocalityInstanceIDKey: "storage-1",
+						},
+						Disk: fdbtypes.FoundationDBStatusProcessDiskInfo{FreeBytes: 100, TotalBytes: 200},
+					},
+				},
+			},
+		}
+	})
+
+	When("building the status summary", func() {
+		It("should copy the values from the status", func() {
+			Expect(GetStatusSummary(status, timestamp)).To(Equal(StatusSummary{
+				Timestamp:               metav1.NewTime(timestamp),
+				Generation:              4,
+				Available:               true,
+				Healthy:                 true,
+				FullReplication:         true,
+				DataState:               "healthy",
+				KVBytes:                 1024,
+				MovingDataInFlightBytes: 10,
+				MovingDataInQueueBytes:  20,
+				DataLagSeconds:          1.5,
+				DurabilityLagSeconds:    5.5,
+				Processes: map[string]ProcessSummary{
+					"1": {
+						ProcessGroupID: "storage-1",
+						Address:        "1.1.1.1:4501",
+						ProcessClass:   fdbtypes.ProcessClassStorage,
+						DiskFreeBytes:  100,
+						DiskTotalBytes: 200,
+					},
+				},
+			}))
+		})
+	})
+
+	When("building the status summary config map", func() {
+		It("should store a summary that can be parsed again", func() {
+			configMap, err := GetStatusSummaryConfigMap(cluster, status, timestamp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configMap.Name).To(Equal("operator-test-1-status"))
+			Expect(configMap.Namespace).To(Equal("my-ns"))
+			Expect(len(configMap.OwnerReferences)).To(Equal(1))
+			Expect(configMap.Data).To(HaveKey(StatusSummaryKey))
+
+			summary, err := ParseStatusSummary(configMap)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summary.Timestamp.Time.Equal(timestamp)).To(BeTrue())
+			Expect(summary.Generation).To(Equal(4))
+			Expect(summary.Processes["1"].DiskFreeBytes).To(Equal(int64(100)))
+		})
+	})
+})
@@ -91,3 +91,22 @@ func CreateDefaultBackup(cluster *fdbtypes.FoundationDBCluster) *fdbtypes.Founda
 		Status: fdbtypes.FoundationDBBackupStatus{},
 	}
 }
+
+// CreateDefaultDR creates a DR replication from the source cluster into the
+// destination cluster for testing.
+func CreateDefaultDR(source *fdbtypes.FoundationDBCluster, destination *fdbtypes.FoundationDBCluster) *fdbtypes.FoundationDBDR {
+	agentCount := 3
+	return &fdbtypes.FoundationDBDR{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      source.Name,
+			Namespace: source.Namespace,
+		},
+		Spec: fdbtypes.FoundationDBDRSpec{
+			Version:                source.Spec.Version,
+			SourceClusterName:      source.Name,
+			DestinationClusterName: destination.Name,
+			AgentCount:             &agentCount,
+		},
+		Status: fdbtypes.FoundationDBDRStatus{},
+	}
+}
@@ -23,17 +23,21 @@ package cmd
 import (
 	ctx "context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -65,6 +69,11 @@ func newAnalyzeCmd(streams genericclioptions.IOStreams) *cobra.Command {
 				return err
 			}
 
+			crossCheck, err := cmd.Flags().GetBool("cross-check")
+			if err != nil {
+				return err
+			}
+
 			if flagNoColor {
 				color.NoColor = true
 			}
@@ -92,6 +101,18 @@ func newAnalyzeCmd(streams genericclioptions.IOStreams) *cobra.Command {
 				return err
 			}
 
+			var statusProvider func(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error)
+			if crossCheck {
+				clientSet, err := kubernetes.NewForConfig(config)
+				if err != nil {
+					return err
+				}
+
+				statusProvider = func(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error) {
+					return getStatus(config, clientSet, kubeClient, cluster, namespace)
+				}
+			}
+
 			var clusters []string
 			if allClusters {
 				var clusterList fdbtypes.FoundationDBClusterList
@@ -109,7 +130,7 @@ func newAnalyzeCmd(streams genericclioptions.IOStreams) *cobra.Command {
 
 			var errs []error
 			for _, cluster := range clusters {
-				err := analyzeCluster(cmd, kubeClient, cluster, namespace, autoFix, force)
+				err := analyzeCluster(cmd, kubeClient, cluster, namespace, autoFix, force, statusProvider)
 				if err != nil {
 					errs = append(errs, err)
 				}
@@ -137,6 +158,9 @@ kubectl fdb -n test-namespace analyze sample-cluster-1
 
 # Analyze the cluster "sample-cluster-1" in the current namespace and fixes issues
 kubectl fdb analyze --auto-fix sample-cluster-1
+
+# Analyze the cluster "sample-cluster-1" in the current namespace and compare the spec and the Pods with the FDB status
+kubectl fdb analyze --cross-check sample-cluster-1
 `,
 	}
 	cmd.SetOut(o.Out)
@@ -145,6 +169,7 @@ kubectl fdb analyze --auto-fix sample-cluster-1
 
 	cmd.Flags().Bool("auto-fix", false, "defines if the analyze tasks should try to fix the found issues (e.g. replace Pods).")
 	cmd.Flags().Bool("all-clusters", false, "defines all clusters in the given namespace should be analyzed.")
+	cmd.Flags().Bool("cross-check", false, "defines if the spec, the Pods and the FDB status should be checked for mismatches. This requires exec permissions on the Pods.")
 	// We might want to move this into the root cmd if we need this in multiple places
 	cmd.Flags().Bool("no-color", false, "Disable color output.")
 
@@ -192,7 +217,7 @@ func replaceFailedInstances(cluster *fdbtypes.FoundationDBCluster, instanceIDs [
 	}
 }
 
-func analyzeCluster(cmd *cobra.Command, kubeClient client.Client, clusterName string, namespace string, autoFix bool, force bool) error {
+func analyzeCluster(cmd *cobra.Command, kubeClient client.Client, clusterName string, namespace string, autoFix bool, force bool, statusProvider func(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error)) error {
 	foundIssues := false
 	cluster, err := loadCluster(kubeClient, namespace, clusterName)
 
@@ -322,6 +347,18 @@ func analyzeCluster(cmd *cobra.Command, kubeClient client.Client, clusterName st
 		foundIssues = true
 	}
 
+	// 5. Cross check the spec, the Pods and the FDB status
+	if statusProvider != nil {
+		crossCheckIssue, err := crossCheckCluster(cmd, cluster, pods.Items, statusProvider)
+		if err != nil {
+			return err
+		}
+
+		if crossCheckIssue {
+			foundIssues = true
+		}
+	}
+
 	// TODO: add more checks using the FDB status directly e.g. error message or something else or overloaded or matching versions
 	// We could add more auto fixes in the future.
 	if autoFix {
@@ -385,3 +422,134 @@ func filterDeletePods(replaceInstances []string, killPods []corev1.Pod) []corev1
 
 	return res
 }
+
+// crossCheckCluster compares the spec, the running Pods and the FDB status
+// and reports any mismatches between them.
+func crossCheckCluster(cmd *cobra.Command, cluster *fdbtypes.FoundationDBCluster, pods []corev1.Pod, statusProvider func(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error)) (bool, error) {
+	foundIssues := false
+
+	configMap, err := internal.GetConfigMap(cluster)
+	if err != nil {
+		return false, err
+	}
+
+	configHashIssue := false
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		processClass := internal.GetProcessClassFromMeta(cluster, pod.ObjectMeta)
+		serversPerPod, err := internal.GetServersPerPodForPod(&pod, processClass)
+		if err != nil {
+			return false, err
+		}
+
+		configMapHash, err := internal.GetDynamicConfHash(configMap, processClass, serversPerPod)
+		if err != nil {
+			return false, err
+		}
+
+		if pod.Annotations[fdbtypes.LastConfigMapKey] != configMapHash {
+			configHashIssue = true
+			statement := fmt.Sprintf("Pod %s/%s has an outdated config hash", pod.Namespace, pod.Name)
+			printStatement(cmd, statement, true)
+		}
+	}
+
+	if !configHashIssue {
+		printStatement(cmd, "Pods have the expected config hash", false)
+	} else {
+		foundIssues = true
+	}
+
+	status, err := statusProvider(cluster)
+	if err != nil {
+		printStatement(cmd, fmt.Sprintf("Could not fetch the FDB status: %s", err.Error()), true)
+		return true, nil
+	}
+
+	processesInStatus := make(map[string]bool, len(status.Cluster.Processes))
+	excludedProcesses := make(map[string]string)
+	for _, process := range status.Cluster.Processes {
+		processGroupID := process.Locality[fdbtypes.FDBLocalityInstanceIDKey]
+		processesInStatus[processGroupID] = true
+		if process.Excluded {
+			excludedProcesses[process.Address.String()] = processGroupID
+		}
+	}
+
+	processGroups := make(map[string]*fdbtypes.ProcessGroupStatus, len(cluster.Status.ProcessGroups))
+	missingProcessIssue := false
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		processGroups[processGroup.ProcessGroupID] = processGroup
+		if processGroup.Remove || processesInStatus[processGroup.ProcessGroupID] {
+			continue
+		}
+
+		missingProcessIssue = true
+		statement := fmt.Sprintf("ProcessGroup: %s is missing in the FDB status", processGroup.ProcessGroupID)
+		printStatement(cmd, statement, true)
+	}
+
+	if !missingProcessIssue {
+		printStatement(cmd, "ProcessGroups are all present in the FDB status", false)
+	} else {
+		foundIssues = true
+	}
+
+	excludedAddresses := make([]string, 0, len(excludedProcesses))
+	for address := range excludedProcesses {
+		excludedAddresses = append(excludedAddresses, address)
+	}
+	sort.Strings(excludedAddresses)
+
+	exclusionIssue := false
+	for _, address := range excludedAddresses {
+		processGroup, ok := processGroups[excludedProcesses[address]]
+		if ok && processGroup.Remove {
+			continue
+		}
+
+		exclusionIssue = true
+		statement := fmt.Sprintf("Process %s is excluded but not marked for removal", address)
+		printStatement(cmd, statement, true)
+	}
+
+	if !exclusionIssue {
+		printStatement(cmd, "No stale exclusions found", false)
+	} else {
+		foundIssues = true
+	}
+
+	connectionString, err := fdbtypes.ParseConnectionString(cluster.Status.ConnectionString)
+	if err != nil {
+		return false, err
+	}
+
+	expectedCoordinators := make([]string, 0, len(connectionString.Coordinators))
+	for _, coordinator := range connectionString.Coordinators {
+		address, err := fdbtypes.ParseProcessAddress(coordinator)
+		if err != nil {
+			return false, err
+		}
+		expectedCoordinators = append(expectedCoordinators, address.String())
+	}
+	sort.Strings(expectedCoordinators)
+
+	currentCoordinators := make([]string, 0, len(status.Client.Coordinators.Coordinators))
+	for _, coordinator := range status.Client.Coordinators.Coordinators {
+		currentCoordinators = append(currentCoordinators, coordinator.Address.String())
+	}
+	sort.Strings(currentCoordinators)
+
+	if equality.Semantic.DeepEqual(expectedCoordinators, currentCoordinators) {
+		printStatement(cmd, "Coordinators match the connection string", false)
+	} else {
+		foundIssues = true
+		statement := fmt.Sprintf("Coordinators in the FDB status %v do not match the connection string %v", currentCoordinators, expectedCoordinators)
+		printStatement(cmd, statement, true)
+	}
+
+	return foundIssues, nil
+}
@@ -115,7 +115,7 @@ var _ = Describe("[plugin] analyze cluster", func() {
 				inBuffer := bytes.Buffer{}
 
 				cmd := newAnalyzeCmd(genericclioptions.IOStreams{In: &inBuffer, Out: &outBuffer, ErrOut: &errBuffer})
-				err := analyzeCluster(cmd, kubeClient, clusterName, namespace, tc.AutoFix, tc.Force)
+				err := analyzeCluster(cmd, kubeClient, clusterName, namespace, tc.AutoFix, tc.Force, nil)
 
 				if err != nil && !tc.HasErrors {
 					Expect(err).To(HaveOccurred())
@@ -360,4 +360,171 @@ var _ = Describe("[plugin] analyze cluster", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 	})
+
+	When("cross checking the cluster", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+		var pods []corev1.Pod
+		var status *fdbtypes.FoundationDBStatus
+		var statusErr error
+		var outBuffer bytes.Buffer
+		var errBuffer bytes.Buffer
+		var foundIssues bool
+		var err error
+
+		BeforeEach(func() {
+			cluster = internal.CreateDefaultCluster()
+			cluster.Status.ConnectionString = "test:abcd@1.1.1.1:4501,1.1.1.2:4501,1.1.1.3:4501"
+			cluster.Status.RunningVersion = cluster.Spec.Version
+			cluster.Status.ProcessGroups = []*fdbtypes.ProcessGroupStatus{
+				{ProcessGroupID: "storage-1", ProcessClass: fdbtypes.ProcessClassStorage, Addresses: []string{"1.1.1.1"}},
+				{ProcessGroupID: "storage-2", ProcessClass: fdbtypes.ProcessClassStorage, Addresses: []string{"1.1.1.2"}},
+				{ProcessGroupID: "storage-3", ProcessClass: fdbtypes.ProcessClassStorage, Addresses: []string{"1.1.1.3"}},
+			}
+
+			configMap, err := internal.GetConfigMap(cluster)
+			Expect(err).NotTo(HaveOccurred())
+			configMapHash, err := internal.GetDynamicConfHash(configMap, fdbtypes.ProcessClassStorage, 1)
+			Expect(err).NotTo(HaveOccurred())
+
+			pods = make([]corev1.Pod, 0, len(cluster.Status.ProcessGroups))
+			status = &fdbtypes.FoundationDBStatus{
+				Cluster: fdbtypes.FoundationDBStatusClusterInfo{
+					Processes: map[string]fdbtypes.FoundationDBStatusProcessInfo{},
+				},
+			}
+
+			for _, processGroup := range cluster.Status.ProcessGroups {
+				pods = append(pods, corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      fmt.Sprintf("%s-%s", cluster.Name, processGroup.ProcessGroupID),
+						Namespace: cluster.Namespace,
+						Labels: map[string]string{
+							fdbtypes.FDBProcessClassLabel:   string(fdbtypes.ProcessClassStorage),
+							fdbtypes.FDBProcessGroupIDLabel: processGroup.ProcessGroupID,
+						},
+						Annotations: map[string]string{
+							fdbtypes.LastConfigMapKey: configMapHash,
+						},
+					},
+				})
+
+				address, err := fdbtypes.ParseProcessAddress(fmt.Sprintf("%s:4501", processGroup.Addresses[0]))
+				Expect(err).NotTo(HaveOccurred())
+
+				status.Cluster.Processes[processGroup.ProcessGroupID] = fdbtypes.FoundationDBStatusProcessInfo{
+					Address: address,
+					Locality: map[string]string{
+						fdbtypes.FDBLocalityInstanceIDKey: processGroup.ProcessGroupID,
+					},
+				}
+
+				status.Client.Coordinators.Coordinators = append(status.Client.Coordinators.Coordinators, fdbtypes.FoundationDBStatusCoordinator{
+					Address:   address,
+					Reachable: true,
+				})
+			}
+
+			statusErr = nil
+			outBuffer = bytes.Buffer{}
+			errBuffer = bytes.Buffer{}
+		})
+
+		JustBeforeEach(func() {
+			cmd := newAnalyzeCmd(genericclioptions.IOStreams{In: &bytes.Buffer{}, Out: &outBuffer, ErrOut: &errBuffer})
+			foundIssues, err = crossCheckCluster(cmd, cluster, pods, func(cluster *fdbtypes.FoundationDBCluster) (*fdbtypes.FoundationDBStatus, error) {
+				return status, statusErr
+			})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		When("the cluster is consistent", func() {
+			It("should not report any issues", func() {
+				Expect(foundIssues).To(BeFalse())
+				Expect(errBuffer.String()).To(BeEmpty())
+				Expect(strings.TrimSpace(outBuffer.String())).To(Equal(`✔ Pods have the expected config hash
+✔ ProcessGroups are all present in the FDB status
+✔ No stale exclusions found
+✔ Coordinators match the connection string`))
+			})
+		})
+
+		When("a Pod has an outdated config hash", func() {
+			BeforeEach(func() {
+				pods[0].Annotations[fdbtypes.LastConfigMapKey] = "outdated"
+			})
+
+			It("should report the Pod", func() {
+				Expect(foundIssues).To(BeTrue())
+				Expect(strings.TrimSpace(errBuffer.String())).To(Equal("✖ Pod my-ns/operator-test-1-storage-1 has an outdated config hash"))
+			})
+		})
+
+		When("a process is missing in the status", func() {
+			BeforeEach(func() {
+				delete(status.Cluster.Processes, "storage-2")
+			})
+
+			It("should report the process group", func() {
+				Expect(foundIssues).To(BeTrue())
+				Expect(strings.TrimSpace(errBuffer.String())).To(Equal("✖ ProcessGroup: storage-2 is missing in the FDB status"))
+			})
+
+			When("the process group is marked for removal", func() {
+				BeforeEach(func() {
+					cluster.Status.ProcessGroups[1].Remove = true
+				})
+
+				It("should not report any issues", func() {
+					Expect(foundIssues).To(BeFalse())
+					Expect(errBuffer.String()).To(BeEmpty())
+				})
+			})
+		})
+
+		When("a process is excluded", func() {
+			BeforeEach(func() {
+				process := status.Cluster.Processes["storage-3"]
+				process.Excluded = true
+				status.Cluster.Processes["storage-3"] = process
+			})
+
+			It("should report the stale exclusion", func() {
+				Expect(foundIssues).To(BeTrue())
+				Expect(strings.TrimSpace(errBuffer.String())).To(Equal("✖ Process 1.1.1.3:4501 is excluded but not marked for removal"))
+			})
+
+			When("the process group is marked for removal", func() {
+				BeforeEach(func() {
+					cluster.Status.ProcessGroups[2].Remove = true
+				})
+
+				It("should not report any issues", func() {
+					Expect(foundIssues).To(BeFalse())
+					Expect(errBuffer.String()).To(BeEmpty())
+				})
+			})
+		})
+
+		When("the coordinators do not match the connection string", func() {
+			BeforeEach(func() {
+				cluster.Status.ConnectionString = "test:abcd@1.1.1.1:4501,1.1.1.2:4501,1.1.1.4:4501"
+			})
+
+			It("should report the coordinators", func() {
+				Expect(foundIssues).To(BeTrue())
+				Expect(strings.TrimSpace(errBuffer.String())).To(ContainSubstring("✖ Coordinators in the FDB status [1.1.1.1:4501 1.1.1.2:4501 1.1.1.3:4501] do not match the connection string [1.1.1.1:4501 1.1.1.2:4501 1.1.1.4:4501]"))
+			})
+		})
+
+		When("the status cannot be fetched", func() {
+			BeforeEach(func() {
+				statusErr = fmt.Errorf("no usable pods found")
+			})
+
+			It("should report the error", func() {
+				Expect(foundIssues).To(BeTrue())
+				Expect(strings.TrimSpace(errBuffer.String())).To(Equal("✖ Could not fetch the FDB status: no usable pods found"))
+			})
+		})
+	})
 })
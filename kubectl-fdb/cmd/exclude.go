@@ -0,0 +1,194 @@
+/*
+ * exclude.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"log"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newExcludeCmd(streams genericclioptions.IOStreams) *cobra.Command {
+	o := newFDBOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "exclude",
+		Short: "Excludes instance(s) in a given FDB cluster.",
+		Long:  "Excludes instance(s) in a given FDB cluster without removing them from the cluster spec.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, err := cmd.Root().Flags().GetBool("force")
+			if err != nil {
+				return err
+			}
+			clusterName, err := cmd.Flags().GetString("fdb-cluster")
+			if err != nil {
+				return err
+			}
+			useInstanceID, err := cmd.Flags().GetBool("use-instance-id")
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				return cmd.Help()
+			}
+
+			config, err := o.configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+
+			scheme := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(scheme)
+			_ = fdbtypes.AddToScheme(scheme)
+			clientSet, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			kubeClient, err := client.New(config, client.Options{Scheme: scheme})
+			if err != nil {
+				return err
+			}
+
+			namespace, err := getNamespace(*o.configFlags.Namespace)
+			if err != nil {
+				return err
+			}
+
+			instances := args
+			if !useInstanceID {
+				instances, err = getInstanceIDsFromPod(kubeClient, clusterName, instances, namespace)
+				if err != nil {
+					return err
+				}
+			}
+
+			cluster, err := loadCluster(kubeClient, namespace, clusterName)
+			if err != nil {
+				return err
+			}
+
+			return excludeInstances(cmd, config, clientSet, kubeClient, cluster, namespace, instances, force)
+		},
+		Example: `
+# Exclude instances for a cluster in the current namespace
+kubectl fdb exclude -c cluster pod-1 pod-2
+
+# Exclude instances for a cluster in the namespace default
+kubectl fdb -n default exclude -c cluster pod-1 pod-2
+
+# Exclude instances for a cluster with the instance ID.
+# The instance ID of a Pod can be fetched with "kubectl get po -L foundationdb.org/fdb-process-group-id"
+kubectl fdb -n default exclude --use-instance-id -c cluster storage-1 storage-2
+`,
+	}
+
+	cmd.Flags().StringP("fdb-cluster", "c", "", "exclude instance(s) in the provided cluster.")
+	cmd.Flags().Bool("use-instance-id", false, "if set the plugin will use the instance ID to exclude it rather than the Pod(s) name.")
+	err := cmd.MarkFlagRequired("fdb-cluster")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.SetIn(o.In)
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// getExclusionAddresses gets the addresses for the given instances from the
+// cluster status.
+func getExclusionAddresses(cluster *fdbtypes.FoundationDBCluster, instances []string) ([]fdbtypes.ProcessAddress, error) {
+	instanceMap := make(map[string]bool, len(instances))
+	for _, instance := range instances {
+		instanceMap[instance] = true
+	}
+
+	addresses := make([]fdbtypes.ProcessAddress, 0, len(instances))
+	for _, processGroup := range cluster.Status.ProcessGroups {
+		if !instanceMap[processGroup.ProcessGroupID] {
+			continue
+		}
+
+		if len(processGroup.Addresses) == 0 {
+			return nil, fmt.Errorf("instance %s has no known addresses", processGroup.ProcessGroupID)
+		}
+
+		processGroupAddresses, err := fdbtypes.ParseProcessAddresses(processGroup.Addresses)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, processGroupAddresses...)
+		delete(instanceMap, processGroup.ProcessGroupID)
+	}
+
+	if len(instanceMap) > 0 {
+		missing := make([]string, 0, len(instanceMap))
+		for _, instance := range instances {
+			if instanceMap[instance] {
+				missing = append(missing, instance)
+			}
+		}
+		return nil, fmt.Errorf("could not find instances %v in cluster %s/%s", missing, cluster.Namespace, cluster.Name)
+	}
+
+	return addresses, nil
+}
+
+//nolint:interfacer // golint has a false-positive here -> `cmd` can be `github.com/hashicorp/go-retryablehttp.Logger`
+func excludeInstances(cmd *cobra.Command, restConfig *rest.Config, clientSet *kubernetes.Clientset, kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, namespace string, instances []string, force bool) error {
+	addresses, err := getExclusionAddresses(cluster, instances)
+	if err != nil {
+		return err
+	}
+
+	command, err := internal.GetExcludeCommand(cluster, addresses)
+	if err != nil {
+		return err
+	}
+
+	if !force {
+		confirmed := confirmAction(fmt.Sprintf("Exclude %v in cluster %s/%s with command %q", instances, namespace, cluster.Name, command))
+		if !confirmed {
+			return fmt.Errorf("user aborted the exclusion")
+		}
+	}
+
+	output, err := runFdbCliCommand(restConfig, clientSet, kubeClient, cluster, namespace, command)
+	if err != nil {
+		return err
+	}
+
+	cmd.Print(output)
+	return nil
+}
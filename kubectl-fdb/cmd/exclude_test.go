@@ -0,0 +1,109 @@
+/*
+ * exclude_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"net"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("[plugin] exclude command", func() {
+	When("getting the addresses to exclude", func() {
+		var cluster *fdbtypes.FoundationDBCluster
+
+		type testCase struct {
+			Instances         []string
+			ExpectedAddresses []fdbtypes.ProcessAddress
+			ExpectedError     string
+		}
+
+		BeforeEach(func() {
+			cluster = &fdbtypes.FoundationDBCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test",
+				},
+				Status: fdbtypes.FoundationDBClusterStatus{
+					ProcessGroups: []*fdbtypes.ProcessGroupStatus{
+						{
+							ProcessGroupID: "storage-1",
+							Addresses:      []string{"1.1.1.1"},
+						},
+						{
+							ProcessGroupID: "storage-2",
+							Addresses:      []string{"1.1.1.2", "1.1.2.2"},
+						},
+						{
+							ProcessGroupID: "storage-3",
+						},
+					},
+				},
+			}
+		})
+
+		DescribeTable("should return the addresses",
+			func(input testCase) {
+				addresses, err := getExclusionAddresses(cluster, input.Instances)
+				if input.ExpectedError != "" {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal(input.ExpectedError))
+					return
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(addresses).To(Equal(input.ExpectedAddresses))
+			},
+			Entry("Exclude a single instance",
+				testCase{
+					Instances: []string{"storage-1"},
+					ExpectedAddresses: []fdbtypes.ProcessAddress{
+						{IPAddress: net.ParseIP("1.1.1.1")},
+					},
+				}),
+			Entry("Exclude an instance with multiple addresses",
+				testCase{
+					Instances: []string{"storage-1", "storage-2"},
+					ExpectedAddresses: []fdbtypes.ProcessAddress{
+						{IPAddress: net.ParseIP("1.1.1.1")},
+						{IPAddress: net.ParseIP("1.1.1.2")},
+						{IPAddress: net.ParseIP("1.1.2.2")},
+					},
+				}),
+			Entry("Exclude an instance without addresses",
+				testCase{
+					Instances:     []string{"storage-3"},
+					ExpectedError: "instance storage-3 has no known addresses",
+				}),
+			Entry("Exclude a missing instance",
+				testCase{
+					Instances:     []string{"storage-1", "storage-4"},
+					ExpectedError: fmt.Sprintf("could not find instances %v in cluster test/test", []string{"storage-4"}),
+				}),
+		)
+	})
+})
@@ -110,40 +110,11 @@ func newExecCmd(streams genericclioptions.IOStreams) *cobra.Command {
 }
 
 func buildCommand(kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, context string, namespace string, commandArgs []string) (exec.Cmd, error) {
-	pods := &corev1.PodList{}
-
-	selector := labels.NewSelector()
-
-	err := internal.NormalizeClusterSpec(cluster, internal.DeprecationOptions{})
+	pod, err := chooseRandomPod(kubeClient, cluster, namespace)
 	if err != nil {
 		return exec.Cmd{}, err
 	}
 
-	for key, value := range cluster.Spec.LabelConfig.MatchLabels {
-		requirement, err := labels.NewRequirement(key, selection.Equals, []string{value})
-		if err != nil {
-			return exec.Cmd{}, nil
-		}
-		selector = selector.Add(*requirement)
-	}
-
-	processClassRequirement, err := labels.NewRequirement(cluster.GetProcessClassLabel(), selection.Exists, nil)
-	if err != nil {
-		return exec.Cmd{}, nil
-	}
-	selector = selector.Add(*processClassRequirement)
-
-	err = kubeClient.List(ctx.Background(), pods,
-		client.InNamespace(namespace),
-		client.MatchingLabelsSelector{Selector: selector},
-		client.MatchingFields{"status.phase": "Running"},
-	)
-	if err != nil {
-		return exec.Cmd{}, err
-	}
-	if len(pods.Items) == 0 {
-		return exec.Cmd{}, fmt.Errorf("no usable pods found for cluster %s", cluster.Name)
-	}
 	kubectlPath, err := exec.LookPath("kubectl")
 	if err != nil {
 		return exec.Cmd{}, err
@@ -154,9 +125,7 @@ func buildCommand(kubeClient client.Client, cluster *fdbtypes.FoundationDBCluste
 		args = append(args, "--context", context)
 	}
 
-	randomPodIndex := rand.Int31n(int32(len(pods.Items)))
-
-	args = append(args, "--namespace", namespace, "exec", "-it", pods.Items[randomPodIndex].Name)
+	args = append(args, "--namespace", namespace, "exec", "-it", pod.Name)
 	if len(commandArgs) > 0 {
 		args = append(args, "--")
 		args = append(args, commandArgs...)
@@ -182,3 +151,45 @@ func runExec(kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, co
 	}
 	return command.Run()
 }
+
+// chooseRandomPod picks a random running pod of the cluster.
+func chooseRandomPod(kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, namespace string) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+
+	selector := labels.NewSelector()
+
+	err := internal.NormalizeClusterSpec(cluster, internal.DeprecationOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range cluster.Spec.LabelConfig.MatchLabels {
+		requirement, err := labels.NewRequirement(key, selection.Equals, []string{value})
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*requirement)
+	}
+
+	processClassRequirement, err := labels.NewRequirement(cluster.GetProcessClassLabel(), selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	selector = selector.Add(*processClassRequirement)
+
+	err = kubeClient.List(ctx.Background(), pods,
+		client.InNamespace(namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+		client.MatchingFields{"status.phase": "Running"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(pods.Items) == 0 {
+		return nil, fmt.Errorf("no usable pods found for cluster %s", cluster.Name)
+	}
+
+	randomPodIndex := rand.Int31n(int32(len(pods.Items)))
+
+	return &pods.Items[randomPodIndex], nil
+}
@@ -0,0 +1,334 @@
+/*
+ * generate.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/utils/pointer"
+)
+
+const (
+	// topologyDevelopment describes a cluster that can run on a single node.
+	topologyDevelopment = "dev"
+	// topologyProduction describes a cluster with triple replication
+	// across nodes.
+	topologyProduction = "production"
+	// topologyMultiDC describes a cluster that spans two regions, with a
+	// satellite data center.
+	topologyMultiDC = "multi-dc"
+)
+
+// generateOptions describes the cluster spec that should be generated.
+type generateOptions struct {
+	topology     string
+	name         string
+	namespace    string
+	version      string
+	dataCenters  []string
+	withDefaults bool
+}
+
+func newGenerateCmd(streams genericclioptions.IOStreams) *cobra.Command {
+	o := newFDBOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generates an example cluster spec",
+		Long: `Generates an example cluster spec for a common topology.
+The supported topologies are dev, for a cluster that can run on a single node,
+production, for a cluster with triple replication across nodes, and multi-dc,
+for a cluster that spans two regions with a satellite data center.
+The multi-dc topology generates one cluster spec per data center. After the
+cluster in the first data center is created, the seedConnectionString of the
+other clusters must be set to its connection string.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			topology, err := cmd.Flags().GetString("topology")
+			if err != nil {
+				return err
+			}
+			name, err := cmd.Flags().GetString("name")
+			if err != nil {
+				return err
+			}
+			version, err := cmd.Flags().GetString("version")
+			if err != nil {
+				return err
+			}
+			dataCenters, err := cmd.Flags().GetStringSlice("data-centers")
+			if err != nil {
+				return err
+			}
+			withDefaults, err := cmd.Flags().GetBool("with-defaults")
+			if err != nil {
+				return err
+			}
+
+			clusters, err := generateClusters(generateOptions{
+				topology:     topology,
+				name:         name,
+				namespace:    *o.configFlags.Namespace,
+				version:      version,
+				dataCenters:  dataCenters,
+				withDefaults: withDefaults,
+			})
+			if err != nil {
+				return err
+			}
+
+			return printClusters(cmd, clusters)
+		},
+		Example: `
+# Generates a cluster spec that can run on a single node
+kubectl fdb generate --topology dev
+
+# Generates a cluster spec for production with a specific name and version
+kubectl fdb generate --topology production --name my-cluster --version 6.3.10
+
+# Generates the cluster specs for a multi-DC cluster
+kubectl fdb generate --topology multi-dc --data-centers east,west,central
+
+# Generates a cluster spec with all defaults that the operator fills in
+kubectl fdb generate --topology production --with-defaults`,
+	}
+
+	cmd.Flags().String("topology", topologyDevelopment, "The topology of the cluster, one of dev, production or multi-dc.")
+	cmd.Flags().String("name", "sample-cluster", "The name of the cluster.")
+	cmd.Flags().String("version", "6.2.30", "The version of FoundationDB.")
+	cmd.Flags().StringSlice("data-centers", []string{"dc1", "dc2", "dc3"}, "The data centers for the multi-dc topology: the primary, the remote and the satellite data center.")
+	cmd.Flags().Bool("with-defaults", false, "Whether the generated spec should contain the defaults that the operator fills in.")
+
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.SetIn(o.In)
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// generateClusters generates the cluster specs for the topology and
+// validates them with the same normalization that the operator runs.
+func generateClusters(options generateOptions) ([]*fdbtypes.FoundationDBCluster, error) {
+	var clusters []*fdbtypes.FoundationDBCluster
+
+	switch options.topology {
+	case topologyDevelopment:
+		clusters = []*fdbtypes.FoundationDBCluster{generateDevelopmentCluster(options)}
+	case topologyProduction:
+		clusters = []*fdbtypes.FoundationDBCluster{generateProductionCluster(options)}
+	case topologyMultiDC:
+		var err error
+		clusters, err = generateMultiDCClusters(options)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown topology %s, expected one of %s", options.topology, strings.Join([]string{topologyDevelopment, topologyProduction, topologyMultiDC}, ", "))
+	}
+
+	for _, cluster := range clusters {
+		normalized := cluster.DeepCopy()
+		err := internal.NormalizeClusterSpec(normalized, internal.DeprecationOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster spec for %s: %w", cluster.Name, err)
+		}
+
+		_, err = normalized.GetProcessCountsWithDefaults()
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster spec for %s: %w", cluster.Name, err)
+		}
+
+		if options.withDefaults {
+			cluster.Spec = normalized.Spec
+		}
+	}
+
+	return clusters, nil
+}
+
+// newGeneratedCluster creates a cluster with the metadata and version from
+// the options.
+func newGeneratedCluster(name string, options generateOptions) *fdbtypes.FoundationDBCluster {
+	return &fdbtypes.FoundationDBCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: fdbtypes.GroupVersion.String(),
+			Kind:       "FoundationDBCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: options.namespace,
+		},
+		Spec: fdbtypes.FoundationDBClusterSpec{
+			Version: options.version,
+		},
+	}
+}
+
+// getGeneralProcessSettings returns the process settings with the volume
+// size and the resources for the main container.
+func getGeneralProcessSettings(storage string, resources corev1.ResourceList) fdbtypes.ProcessSettings {
+	settings := fdbtypes.ProcessSettings{
+		VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: resource.MustParse(storage),
+					},
+				},
+			},
+		},
+	}
+
+	if resources != nil {
+		settings.PodTemplate = &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{
+					{
+						Name: "foundationdb",
+						Resources: corev1.ResourceRequirements{
+							Requests: resources,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return settings
+}
+
+// generateDevelopmentCluster generates a cluster with single replication
+// that can run all processes on a single node.
+func generateDevelopmentCluster(options generateOptions) *fdbtypes.FoundationDBCluster {
+	cluster := newGeneratedCluster(options.name, options)
+	cluster.Spec.FaultDomain = fdbtypes.FoundationDBClusterFaultDomain{
+		Key: "foundationdb.org/none",
+	}
+	cluster.Spec.DatabaseConfiguration = fdbtypes.DatabaseConfiguration{
+		RedundancyMode: fdbtypes.RedundancyModeSingle,
+	}
+	cluster.Spec.ProcessCounts = fdbtypes.ProcessCounts{
+		Stateless: -1,
+	}
+	cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{
+		fdbtypes.ProcessClassGeneral: getGeneralProcessSettings("16G", corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("250m"),
+			corev1.ResourceMemory: resource.MustParse("128Mi"),
+		}),
+	}
+
+	return cluster
+}
+
+// generateProductionCluster generates a cluster with triple replication and
+// automatic replacements.
+func generateProductionCluster(options generateOptions) *fdbtypes.FoundationDBCluster {
+	cluster := newGeneratedCluster(options.name, options)
+	cluster.Spec.DatabaseConfiguration = fdbtypes.DatabaseConfiguration{
+		RedundancyMode: fdbtypes.RedundancyModeTriple,
+	}
+	cluster.Spec.AutomationOptions.Replacements.Enabled = pointer.Bool(true)
+	cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{
+		fdbtypes.ProcessClassGeneral: getGeneralProcessSettings("128G", nil),
+	}
+
+	return cluster
+}
+
+// generateMultiDCClusters generates one cluster per data center for a
+// cluster that spans two regions. The first data center is the primary, the
+// second is the remote and the third is a satellite for both regions.
+func generateMultiDCClusters(options generateOptions) ([]*fdbtypes.FoundationDBCluster, error) {
+	if len(options.dataCenters) != 3 {
+		return nil, fmt.Errorf("the multi-dc topology requires 3 data centers, got %d", len(options.dataCenters))
+	}
+
+	primary, remote, satellite := options.dataCenters[0], options.dataCenters[1], options.dataCenters[2]
+	databaseConfiguration := fdbtypes.DatabaseConfiguration{
+		RedundancyMode: fdbtypes.RedundancyModeDouble,
+		UsableRegions:  2,
+		Regions: []fdbtypes.Region{
+			{
+				DataCenters: []fdbtypes.DataCenter{
+					{ID: primary, Priority: 1},
+					{ID: satellite, Priority: 1, Satellite: 1},
+				},
+				SatelliteLogs: 3,
+			},
+			{
+				DataCenters: []fdbtypes.DataCenter{
+					{ID: remote, Priority: 0},
+					{ID: satellite, Priority: 1, Satellite: 1},
+				},
+				SatelliteLogs: 3,
+			},
+		},
+	}
+
+	clusters := make([]*fdbtypes.FoundationDBCluster, 0, len(options.dataCenters))
+	for _, dataCenter := range options.dataCenters {
+		cluster := newGeneratedCluster(fmt.Sprintf("%s-%s", options.name, dataCenter), options)
+		cluster.Spec.DataCenter = dataCenter
+		cluster.Spec.InstanceIDPrefix = dataCenter
+		cluster.Spec.DatabaseConfiguration = *databaseConfiguration.DeepCopy()
+		cluster.Spec.AutomationOptions.Replacements.Enabled = pointer.Bool(true)
+		cluster.Spec.Processes = map[fdbtypes.ProcessClass]fdbtypes.ProcessSettings{
+			fdbtypes.ProcessClassGeneral: getGeneralProcessSettings("128G", nil),
+		}
+
+		// The satellite only runs the log processes for the satellite logs.
+		if dataCenter == satellite {
+			cluster.Spec.ProcessCounts = fdbtypes.ProcessCounts{
+				Storage:   -1,
+				Stateless: -1,
+				Log:       4,
+			}
+		}
+
+		clusters = append(clusters, cluster)
+	}
+
+	return clusters, nil
+}
+
+// printClusters prints the clusters as YAML documents.
+func printClusters(cmd *cobra.Command, clusters []*fdbtypes.FoundationDBCluster) error {
+	for _, cluster := range clusters {
+		clusterYAML, err := getMinimalYAML(cluster)
+		if err != nil {
+			return err
+		}
+
+		cmd.Println("---")
+		cmd.Print(string(clusterYAML))
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+/*
+ * generate_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"strings"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"sigs.k8s.io/yaml"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("[plugin] generate command", func() {
+	When("generating the cluster specs", func() {
+		type testCase struct {
+			options               generateOptions
+			expectedNames         []string
+			expectedRedundancy    fdbtypes.RedundancyMode
+			expectedUsableRegions int
+			expectedError         string
+		}
+
+		DescribeTable("should generate the cluster specs",
+			func(tc testCase) {
+				clusters, err := generateClusters(tc.options)
+				if tc.expectedError != "" {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal(tc.expectedError))
+					return
+				}
+				Expect(err).NotTo(HaveOccurred())
+
+				names := make([]string, 0, len(clusters))
+				for _, cluster := range clusters {
+					names = append(names, cluster.Name)
+					Expect(cluster.Namespace).To(Equal(tc.options.namespace))
+					Expect(cluster.Spec.Version).To(Equal(tc.options.version))
+					Expect(cluster.Spec.DatabaseConfiguration.RedundancyMode).To(Equal(tc.expectedRedundancy))
+					Expect(cluster.Spec.DatabaseConfiguration.UsableRegions).To(Equal(tc.expectedUsableRegions))
+					Expect(cluster.Spec.MainContainer.ImageConfigs != nil).To(Equal(tc.options.withDefaults))
+				}
+				Expect(names).To(Equal(tc.expectedNames))
+			},
+			Entry("dev topology",
+				testCase{
+					options: generateOptions{
+						topology:  topologyDevelopment,
+						name:      "sample",
+						namespace: "test",
+						version:   "6.2.30",
+					},
+					expectedNames:      []string{"sample"},
+					expectedRedundancy: fdbtypes.RedundancyModeSingle,
+				}),
+			Entry("production topology with defaults",
+				testCase{
+					options: generateOptions{
+						topology:     topologyProduction,
+						name:         "sample",
+						namespace:    "test",
+						version:      "6.3.10",
+						withDefaults: true,
+					},
+					expectedNames:      []string{"sample"},
+					expectedRedundancy: fdbtypes.RedundancyModeTriple,
+				}),
+			Entry("multi-dc topology",
+				testCase{
+					options: generateOptions{
+						topology:    topologyMultiDC,
+						name:        "sample",
+						version:     "6.2.30",
+						dataCenters: []string{"east", "west", "central"},
+					},
+					expectedNames:         []string{"sample-east", "sample-west", "sample-central"},
+					expectedRedundancy:    fdbtypes.RedundancyModeDouble,
+					expectedUsableRegions: 2,
+				}),
+			Entry("multi-dc topology with too few data centers",
+				testCase{
+					options: generateOptions{
+						topology:    topologyMultiDC,
+						name:        "sample",
+						version:     "6.2.30",
+						dataCenters: []string{"east", "west"},
+					},
+					expectedError: "the multi-dc topology requires 3 data centers, got 2",
+				}),
+			Entry("unknown topology",
+				testCase{
+					options: generateOptions{
+						topology: "unknown",
+						name:     "sample",
+						version:  "6.2.30",
+					},
+					expectedError: "unknown topology unknown, expected one of dev, production, multi-dc",
+				}),
+			Entry("invalid version",
+				testCase{
+					options: generateOptions{
+						topology: topologyProduction,
+						name:     "sample",
+						version:  "latest",
+					},
+					expectedError: "invalid cluster spec for sample: could not parse FDB version from latest",
+				}),
+		)
+	})
+
+	When("printing the cluster specs", func() {
+		It("should print one YAML document per cluster", func() {
+			outBuffer := bytes.Buffer{}
+			errBuffer := bytes.Buffer{}
+			inBuffer := bytes.Buffer{}
+
+			cmd := newGenerateCmd(genericclioptions.IOStreams{In: &inBuffer, Out: &outBuffer, ErrOut: &errBuffer})
+			clusters, err := generateClusters(generateOptions{
+				topology:    topologyMultiDC,
+				name:        "sample",
+				version:     "6.2.30",
+				dataCenters: []string{"dc1", "dc2", "dc3"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(printClusters(cmd, clusters)).NotTo(HaveOccurred())
+
+			documents := strings.Split(outBuffer.String(), "---\n")
+			Expect(documents).To(HaveLen(4))
+			Expect(documents[0]).To(BeEmpty())
+
+			cluster := &fdbtypes.FoundationDBCluster{}
+			Expect(yaml.Unmarshal([]byte(documents[3]), cluster)).NotTo(HaveOccurred())
+			Expect(cluster.Kind).To(Equal("FoundationDBCluster"))
+			Expect(cluster.Name).To(Equal("sample-dc3"))
+			Expect(cluster.Spec.DataCenter).To(Equal("dc3"))
+			Expect(cluster.Spec.ProcessCounts).To(Equal(fdbtypes.ProcessCounts{
+				Storage:   -1,
+				Stateless: -1,
+				Log:       4,
+			}))
+		})
+	})
+})
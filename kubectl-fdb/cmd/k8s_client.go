@@ -23,6 +23,7 @@ package cmd
 import (
 	"bytes"
 	ctx "context"
+	"fmt"
 
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
@@ -180,3 +181,19 @@ func getAllPodsFromClusterWithCondition(kubeClient client.Client, clusterName st
 
 	return processes, nil
 }
+
+// runFdbCliCommand runs an fdbcli command in a random pod of the cluster and
+// returns the output of the command.
+func runFdbCliCommand(restConfig *rest.Config, clientSet *kubernetes.Clientset, kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, namespace string, command string) (string, error) {
+	pod, err := chooseRandomPod(kubeClient, cluster, namespace)
+	if err != nil {
+		return "", err
+	}
+
+	stdout, stderr, err := executeCmd(restConfig, clientSet, pod.Name, namespace, fmt.Sprintf("fdbcli --exec '%s'", command))
+	if err != nil {
+		return "", fmt.Errorf("could not run fdbcli command %q: %w, stderr: %s", command, err, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
@@ -79,6 +79,10 @@ func NewRootCmd(streams genericclioptions.IOStreams) *cobra.Command {
 		newAnalyzeCmd(streams),
 		newDeprecationCmd(streams),
 		newFixCoordinatorIPsCmd(streams),
+		newStatusCmd(streams),
+		newExcludeCmd(streams),
+		newFdbCliCmd(streams),
+		newGenerateCmd(streams),
 	)
 
 	return cmd
@@ -0,0 +1,154 @@
+/*
+ * status.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
+	"github.com/FoundationDB/fdb-kubernetes-operator/internal"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newStatusCmd(streams genericclioptions.IOStreams) *cobra.Command {
+	o := newFDBOptions(streams)
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Fetches the status JSON of a given FDB cluster.",
+		Long:  "Fetches the status JSON of a given FDB cluster by running fdbcli in one of the cluster's pods.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			clusterName, err := cmd.Flags().GetString("fdb-cluster")
+			if err != nil {
+				return err
+			}
+
+			config, err := o.configFlags.ToRESTConfig()
+			if err != nil {
+				return err
+			}
+
+			scheme := runtime.NewScheme()
+			_ = clientgoscheme.AddToScheme(scheme)
+			_ = fdbtypes.AddToScheme(scheme)
+			clientSet, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return err
+			}
+
+			kubeClient, err := client.New(config, client.Options{Scheme: scheme})
+			if err != nil {
+				return err
+			}
+
+			namespace, err := getNamespace(*o.configFlags.Namespace)
+			if err != nil {
+				return err
+			}
+
+			cluster, err := loadCluster(kubeClient, namespace, clusterName)
+			if err != nil {
+				return err
+			}
+
+			output, err := runFdbCliCommand(config, clientSet, kubeClient, cluster, namespace, "status json")
+			if err != nil {
+				return err
+			}
+
+			status, err := formatStatusJSON(output)
+			if err != nil {
+				return err
+			}
+
+			cmd.Println(status)
+			return nil
+		},
+		Example: `
+# Fetch the status JSON for a cluster in the current namespace
+kubectl fdb status -c cluster
+
+# Fetch the status JSON for a cluster in the namespace default
+kubectl fdb -n default status -c cluster
+`,
+	}
+
+	cmd.Flags().StringP("fdb-cluster", "c", "", "fetch the status of the provided cluster.")
+	err := cmd.MarkFlagRequired("fdb-cluster")
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmd.SetOut(o.Out)
+	cmd.SetErr(o.ErrOut)
+	cmd.SetIn(o.In)
+
+	o.configFlags.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+// formatStatusJSON strips any warnings from the output of the status json
+// command and returns the indented JSON document.
+func formatStatusJSON(output string) (string, error) {
+	statusString, err := internal.RemoveWarningsInJSON(output)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	err = json.Indent(&buffer, []byte(statusString), "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("could not parse the status JSON: %w", err)
+	}
+
+	return buffer.String(), nil
+}
+
+// getStatus fetches the status of the cluster by running fdbcli in one of the
+// cluster's pods.
+func getStatus(restConfig *rest.Config, clientSet *kubernetes.Clientset, kubeClient client.Client, cluster *fdbtypes.FoundationDBCluster, namespace string) (*fdbtypes.FoundationDBStatus, error) {
+	output, err := runFdbCliCommand(restConfig, clientSet, kubeClient, cluster, namespace, "status json")
+	if err != nil {
+		return nil, err
+	}
+
+	statusString, err := internal.RemoveWarningsInJSON(output)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &fdbtypes.FoundationDBStatus{}
+	err = json.Unmarshal([]byte(statusString), status)
+	if err != nil {
+		return nil, err
+	}
+
+	return status, nil
+}
@@ -0,0 +1,71 @@
+/*
+ * status_test.go
+ *
+ * This source file is part of the FoundationDB open source project
+ *
+ * Copyright 2021 Apple Inc. and the FoundationDB project authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package cmd
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("[plugin] status command", func() {
+	When("formatting the status JSON", func() {
+		type testCase struct {
+			Output        string
+			Expected      string
+			ExpectedError string
+		}
+
+		DescribeTable("should format the status",
+			func(input testCase) {
+				status, err := formatStatusJSON(input.Output)
+				if input.ExpectedError != "" {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal(input.ExpectedError))
+					return
+				}
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(status).To(Equal(input.Expected))
+			},
+			Entry("Status without warnings",
+				testCase{
+					Output:   `{"client":{"database_status":{"available":true}}}`,
+					Expected: "{\n  \"client\": {\n    \"database_status\": {\n      \"available\": true\n    }\n  }\n}",
+				}),
+			Entry("Status with warnings",
+				testCase{
+					Output:   "WARNING: Long delay (Ctrl-C to interrupt)\r\n{\"cluster\":{}}\r\n",
+					Expected: "{\n  \"cluster\": {}\n}",
+				}),
+			Entry("Output without JSON",
+				testCase{
+					Output:        "ERROR: Could not communicate with a quorum of coordination servers",
+					ExpectedError: "the JSON string doesn't contain a starting '{'",
+				}),
+			Entry("Output with invalid JSON",
+				testCase{
+					Output:        `{"cluster":`,
+					ExpectedError: "could not parse the status JSON: unexpected end of JSON input",
+				}),
+		)
+	})
+})
@@ -26,7 +26,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -54,19 +56,31 @@ type MockClient struct {
 	// ipCounter provides monotonically incrementing IP addresses.
 	ipCounter int
 
+	// nodePortCounter provides monotonically incrementing node ports.
+	nodePortCounter int
+
 	// stuckTerminatingObjects tracks which objects should be stuck in terminating.
 	stuckTerminatingObjects map[string]map[string]bool
+
+	// appliedData tracks the last configuration that was applied for an
+	// object through a server-side apply.
+	appliedData map[string]map[string]map[string]interface{}
+
+	// createLock prevents concurrent creations from modifying the data at
+	// the same time.
+	createLock sync.Mutex
 }
 
 // Clear erases any mock data.
 func (client *MockClient) Clear() {
 	client.data = make(map[string]map[string]map[string]interface{})
 	client.stuckTerminatingObjects = nil
+	client.appliedData = nil
 }
 
 // Scheme returns the runtime Scheme
 func (client *MockClient) Scheme() *runtime.Scheme {
-	return nil
+	return scheme.Scheme
 }
 
 // RESTMapper returns the RESTMapper
@@ -126,6 +140,12 @@ func (client *MockClient) fillInMaps(kind string) {
 	if client.data[kind] == nil {
 		client.data[kind] = make(map[string]map[string]interface{})
 	}
+	if client.appliedData == nil {
+		client.appliedData = make(map[string]map[string]map[string]interface{})
+	}
+	if client.appliedData[kind] == nil {
+		client.appliedData[kind] = make(map[string]map[string]interface{})
+	}
 }
 
 // lookupJSONValue looks up a value in a generic JSON object.
@@ -265,6 +285,25 @@ func incrementGeneration(genericData map[string]interface{}) (float64, error) {
 	return generationNumber, nil
 }
 
+// incrementResourceVersion sets the resource version of a new object to one
+// more than the resource version of the existing object.
+func incrementResourceVersion(existingData map[string]interface{}, newData map[string]interface{}) error {
+	resourceVersion, err := lookupJSONString(existingData, "metadata", "resourceVersion")
+	if err != nil {
+		return err
+	}
+
+	versionNumber := 0
+	if resourceVersion != "" {
+		versionNumber, err = strconv.Atoi(resourceVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	return setJSONValue(newData, []string{"metadata", "resourceVersion"}, strconv.Itoa(versionNumber+1))
+}
+
 // setGeneration sets the generation field in an object's metadata.
 func setGeneration(genericData map[string]interface{}, generation float64) error {
 	return setJSONValue(genericData, []string{"metadata", "generation"}, generation)
@@ -281,6 +320,34 @@ func (client *MockClient) generateIP() string {
 	return fmt.Sprintf("192.168.%d.%d", client.ipCounter/256, client.ipCounter%256)
 }
 
+// allocateNodePorts sets a node port on every port of a service that does
+// not have one yet.
+func (client *MockClient) allocateNodePorts(genericObject map[string]interface{}) {
+	spec, isMap := genericObject["spec"].(map[string]interface{})
+	if !isMap {
+		return
+	}
+
+	ports, isList := spec["ports"].([]interface{})
+	if !isList {
+		return
+	}
+
+	for _, genericPort := range ports {
+		port, isMap := genericPort.(map[string]interface{})
+		if !isMap {
+			continue
+		}
+
+		if nodePort, present := port["nodePort"]; present && nodePort != float64(0) {
+			continue
+		}
+
+		client.nodePortCounter++
+		port["nodePort"] = 30000 + client.nodePortCounter
+	}
+}
+
 // checkPresence checks the presence of an object in the data.
 func (client *MockClient) checkPresence(kindKey string, objectKey string) error {
 	client.fillInMaps(kindKey)
@@ -295,8 +362,16 @@ func (client *MockClient) checkPresence(kindKey string, objectKey string) error
 	return nil
 }
 
-// Create creates a new object
+// Create creates a new object. It is safe to create objects concurrently.
 func (client *MockClient) Create(context ctx.Context, object ctrlClient.Object, options ...ctrlClient.CreateOption) error {
+	client.createLock.Lock()
+	defer client.createLock.Unlock()
+
+	return client.create(object)
+}
+
+// create creates a new object without taking the lock.
+func (client *MockClient) create(object ctrlClient.Object) error {
 	object.SetCreationTimestamp(metav1.Time{Time: time.Now()})
 
 	jsonData, err := json.Marshal(object)
@@ -325,6 +400,11 @@ func (client *MockClient) Create(context ctx.Context, object ctrlClient.Object,
 		return err
 	}
 
+	err = setJSONValue(genericObject, []string{"metadata", "resourceVersion"}, "1")
+	if err != nil {
+		return err
+	}
+
 	err = setUID(genericObject)
 	if err != nil {
 		return err
@@ -342,6 +422,15 @@ func (client *MockClient) Create(context ctx.Context, object ctrlClient.Object,
 				return err
 			}
 		}
+
+		serviceType, err := lookupJSONString(genericObject, "spec", "type")
+		if err != nil {
+			return err
+		}
+
+		if serviceType == string(corev1.ServiceTypeNodePort) {
+			client.allocateNodePorts(genericObject)
+		}
 	} else if kindKey == "/v1/Pod" {
 		v4Address := client.generatePodIPv4()
 		v6Address := client.generatePodIPv6()
@@ -466,7 +555,8 @@ func (client *MockClient) List(context ctx.Context, list ctrlClient.ObjectList,
 }
 
 // Delete deletes an object.
-// This does not support the options argument yet.
+// The only supported option is a grace period of 0, which removes objects
+// that are stuck in terminating.
 func (client *MockClient) Delete(context ctx.Context, object ctrlClient.Object, options ...ctrlClient.DeleteOption) error {
 	kindKey, err := buildKindKey(object)
 	if err != nil {
@@ -485,9 +575,21 @@ func (client *MockClient) Delete(context ctx.Context, object ctrlClient.Object,
 		return err
 	}
 
+	// Objects with finalizers are only marked for deletion, and are removed
+	// once the last finalizer is removed.
+	existingObject := client.data[kindKey][objectKey]
+	if hasFinalizers(existingObject) {
+		return markForDeletion(existingObject)
+	}
+
+	deleteOptions := &ctrlClient.DeleteOptions{}
+	deleteOptions.ApplyOptions(options)
+	forceDelete := deleteOptions.GracePeriodSeconds != nil && *deleteOptions.GracePeriodSeconds == 0
+
 	stuckTerminating := client.stuckTerminatingObjects != nil && client.stuckTerminatingObjects[kindKey] != nil && client.stuckTerminatingObjects[kindKey][objectKey]
-	if !stuckTerminating {
+	if !stuckTerminating || forceDelete {
 		delete(client.data[kindKey], objectKey)
+		delete(client.appliedData[kindKey], objectKey)
 	}
 
 	return nil
@@ -538,8 +640,29 @@ func (client *MockClient) Update(context ctx.Context, object ctrlClient.Object,
 		}
 	}
 
+	err = incrementResourceVersion(existingObject, newObject)
+	if err != nil {
+		return err
+	}
+
+	// The deletion timestamp cannot be removed once an object with
+	// finalizers has been marked for deletion.
+	if hasFinalizers(existingObject) && isMarkedForDeletion(existingObject) && !isMarkedForDeletion(newObject) {
+		err = setJSONValue(newObject, []string{"metadata", "deletionTimestamp"}, existingObject["metadata"].(map[string]interface{})["deletionTimestamp"])
+		if err != nil {
+			return err
+		}
+	}
+
 	client.data[kindKey][objectKey] = newObject
 
+	// Removing the last finalizer from an object that is marked for deletion
+	// completes the deletion.
+	if hasFinalizers(existingObject) && !hasFinalizers(newObject) && isMarkedForDeletion(newObject) {
+		delete(client.data[kindKey], objectKey)
+		delete(client.appliedData[kindKey], objectKey)
+	}
+
 	jsonData, err = json.Marshal(newObject)
 	if err != nil {
 		return err
@@ -552,10 +675,289 @@ func (client *MockClient) Update(context ctx.Context, object ctrlClient.Object,
 	return nil
 }
 
+// hasFinalizers determines whether an object has any finalizers.
+func hasFinalizers(genericData map[string]interface{}) bool {
+	finalizers, err := lookupJSONValue(genericData, []string{"metadata", "finalizers"}, nil)
+	if err != nil {
+		return false
+	}
+
+	finalizerList, ok := finalizers.([]interface{})
+	return ok && len(finalizerList) > 0
+}
+
+// isMarkedForDeletion determines whether an object has a deletion timestamp.
+func isMarkedForDeletion(genericData map[string]interface{}) bool {
+	deletionTimestamp, err := lookupJSONValue(genericData, []string{"metadata", "deletionTimestamp"}, nil)
+	return err == nil && deletionTimestamp != nil
+}
+
+// markForDeletion sets the deletion timestamp in an object's metadata, if it
+// is not already set.
+func markForDeletion(genericData map[string]interface{}) error {
+	if isMarkedForDeletion(genericData) {
+		return nil
+	}
+
+	return setJSONValue(genericData, []string{"metadata", "deletionTimestamp"}, time.Now().UTC().Format(time.RFC3339))
+}
+
 // Patch patches an object.
-// This is not yet implemented.
+// This only supports server-side apply patches and JSON merge patches.
 func (client *MockClient) Patch(context ctx.Context, object ctrlClient.Object, patch ctrlClient.Patch, options ...ctrlClient.PatchOption) error {
-	return fmt.Errorf("Not implemented")
+	switch patch.Type() {
+	case types.ApplyPatchType:
+		return client.apply(context, object, patch)
+	case types.MergePatchType:
+		return client.mergePatch(object, patch, false)
+	default:
+		return fmt.Errorf("Not implemented")
+	}
+}
+
+// apply performs a server-side apply. The applied object is merged into the
+// existing object, and lists in the applied object replace the existing
+// lists. Fields that were set in the last applied configuration and are
+// missing from the new one are removed from the object.
+func (client *MockClient) apply(context ctx.Context, object ctrlClient.Object, patch ctrlClient.Patch) error {
+	kindKey, err := buildKindKey(object)
+	if err != nil {
+		return err
+	}
+
+	client.fillInMaps(kindKey)
+
+	jsonData, err := patch.Data(object)
+	if err != nil {
+		return err
+	}
+
+	objectKey, err := buildJSONObjectKey(jsonData)
+	if err != nil {
+		return err
+	}
+
+	appliedObject := make(map[string]interface{})
+	err = json.Unmarshal(jsonData, &appliedObject)
+	if err != nil {
+		return err
+	}
+	delete(appliedObject, "status")
+
+	existingObject, present := client.data[kindKey][objectKey]
+	if !present {
+		err = client.Create(context, object)
+		if err != nil {
+			return err
+		}
+		client.appliedData[kindKey][objectKey] = appliedObject
+		return nil
+	}
+
+	existingData, err := json.Marshal(existingObject)
+	if err != nil {
+		return err
+	}
+
+	newObject := make(map[string]interface{})
+	err = json.Unmarshal(existingData, &newObject)
+	if err != nil {
+		return err
+	}
+
+	removeJSONFields(newObject, client.appliedData[kindKey][objectKey], appliedObject)
+	mergeJSONObjects(newObject, appliedObject)
+
+	err = incrementResourceVersion(existingObject, newObject)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(existingObject["spec"], newObject["spec"]) {
+		_, err = incrementGeneration(newObject)
+		if err != nil {
+			return err
+		}
+	}
+
+	client.data[kindKey][objectKey] = newObject
+	client.appliedData[kindKey][objectKey] = appliedObject
+
+	jsonData, err = json.Marshal(newObject)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, object)
+}
+
+// mergePatch applies a JSON merge patch to an existing object. When
+// patchStatus is set, this only patches the status, otherwise this patches
+// everything but the status.
+func (client *MockClient) mergePatch(object ctrlClient.Object, patch ctrlClient.Patch, patchStatus bool) error {
+	kindKey, err := buildKindKey(object)
+	if err != nil {
+		return err
+	}
+
+	client.fillInMaps(kindKey)
+
+	objectKey, err := buildRuntimeObjectKey(object)
+	if err != nil {
+		return err
+	}
+
+	err = client.checkPresence(kindKey, objectKey)
+	if err != nil {
+		return err
+	}
+
+	jsonData, err := patch.Data(object)
+	if err != nil {
+		return err
+	}
+
+	patchObject := make(map[string]interface{})
+	err = json.Unmarshal(jsonData, &patchObject)
+	if err != nil {
+		return err
+	}
+
+	if patchStatus {
+		patchObject = map[string]interface{}{"status": patchObject["status"]}
+	} else {
+		delete(patchObject, "status")
+	}
+
+	existingObject := client.data[kindKey][objectKey]
+	existingData, err := json.Marshal(existingObject)
+	if err != nil {
+		return err
+	}
+
+	newObject := make(map[string]interface{})
+	err = json.Unmarshal(existingData, &newObject)
+	if err != nil {
+		return err
+	}
+
+	patchResourceVersion, err := lookupJSONString(patchObject, "metadata", "resourceVersion")
+	if err != nil {
+		return err
+	}
+
+	existingResourceVersion, err := lookupJSONString(existingObject, "metadata", "resourceVersion")
+	if err != nil {
+		return err
+	}
+
+	if patchResourceVersion != "" && patchResourceVersion != existingResourceVersion {
+		return &k8serrors.StatusError{ErrStatus: metav1.Status{
+			Status:  "Failure",
+			Message: "Conflict",
+			Code:    409,
+			Reason:  metav1.StatusReasonConflict,
+		}}
+	}
+
+	applyMergePatch(newObject, patchObject)
+
+	err = incrementResourceVersion(existingObject, newObject)
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(existingObject["spec"], newObject["spec"]) {
+		_, err = incrementGeneration(newObject)
+		if err != nil {
+			return err
+		}
+	}
+
+	client.data[kindKey][objectKey] = newObject
+
+	// Removing the last finalizer from an object that is marked for deletion
+	// completes the deletion.
+	if hasFinalizers(existingObject) && !hasFinalizers(newObject) && isMarkedForDeletion(newObject) {
+		delete(client.data[kindKey], objectKey)
+		delete(client.appliedData[kindKey], objectKey)
+	}
+
+	jsonData, err = json.Marshal(newObject)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, object)
+}
+
+// applyMergePatch applies the values from a JSON merge patch to a target
+// object. Nested objects are merged recursively, and null values in the
+// patch remove the field from the target object.
+func applyMergePatch(target map[string]interface{}, patch map[string]interface{}) {
+	for key, value := range patch {
+		if value == nil {
+			delete(target, key)
+			continue
+		}
+
+		patchMap, isMap := value.(map[string]interface{})
+		if isMap {
+			targetMap, targetIsMap := target[key].(map[string]interface{})
+			if !targetIsMap {
+				targetMap = make(map[string]interface{})
+			}
+			applyMergePatch(targetMap, patchMap)
+			target[key] = targetMap
+			continue
+		}
+
+		target[key] = value
+	}
+}
+
+// mergeJSONObjects merges the values from an applied object into a target
+// object. Nested objects are merged recursively, and null values in the
+// applied object are ignored.
+func mergeJSONObjects(target map[string]interface{}, applied map[string]interface{}) {
+	for key, value := range applied {
+		if value == nil {
+			continue
+		}
+
+		appliedMap, isMap := value.(map[string]interface{})
+		targetMap, targetIsMap := target[key].(map[string]interface{})
+		if isMap && targetIsMap {
+			mergeJSONObjects(targetMap, appliedMap)
+			continue
+		}
+
+		target[key] = value
+	}
+}
+
+// removeJSONFields removes the fields from a target object that were set in
+// the previously applied object, but are not set in the newly applied
+// object.
+func removeJSONFields(target map[string]interface{}, previous map[string]interface{}, applied map[string]interface{}) {
+	for key, previousValue := range previous {
+		if previousValue == nil {
+			continue
+		}
+
+		appliedValue := applied[key]
+		if appliedValue == nil {
+			delete(target, key)
+			continue
+		}
+
+		previousMap, previousIsMap := previousValue.(map[string]interface{})
+		appliedMap, appliedIsMap := appliedValue.(map[string]interface{})
+		targetMap, targetIsMap := target[key].(map[string]interface{})
+		if previousIsMap && appliedIsMap && targetIsMap {
+			removeJSONFields(targetMap, previousMap, appliedMap)
+		}
+	}
 }
 
 // DeleteAllOf deletes all objects of the given type matching the given options.
@@ -640,15 +1042,24 @@ func (client MockStatusClient) Update(context ctx.Context, object ctrlClient.Obj
 	}
 
 	existingObject["status"] = newObject["status"]
+	err = incrementResourceVersion(existingObject, existingObject)
+	if err != nil {
+		return err
+	}
+
 	client.rawClient.data[kindKey][objectKey] = existingObject
 
 	return nil
 }
 
 // Patch patches an object's status.
-// This is not yet implemented.
+// This only supports JSON merge patches.
 func (client MockStatusClient) Patch(context ctx.Context, object ctrlClient.Object, patch ctrlClient.Patch, options ...ctrlClient.PatchOption) error {
-	return fmt.Errorf("not implemented")
+	if patch.Type() != types.MergePatchType {
+		return fmt.Errorf("not implemented")
+	}
+
+	return client.rawClient.mergePatch(object, patch, true)
 }
 
 // Status returns a writer for updating status.
@@ -156,6 +156,26 @@ var _ = Describe("[mock client]", func() {
 			Expect(service.ObjectMeta.Generation).To(Equal(int64(1)))
 			Expect(service.Spec.ClusterIP).To(Equal("None"))
 		})
+
+		It("should allocate node ports for a NodePort service", func() {
+			service := &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "service1",
+				},
+				Spec: corev1.ServiceSpec{
+					Type: corev1.ServiceTypeNodePort,
+					Ports: []corev1.ServicePort{
+						{Name: "tls", Port: 4500},
+						{Name: "non-tls", Port: 4501},
+					},
+				},
+			}
+			err := client.Create(context.TODO(), service)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.Spec.Ports[0].NodePort).To(Equal(int32(30001)))
+			Expect(service.Spec.Ports[1].NodePort).To(Equal(int32(30002)))
+		})
 	})
 
 	When("getting a missing object", func() {
@@ -226,6 +246,36 @@ var _ = Describe("[mock client]", func() {
 		})
 	})
 
+	When("deleting an object with a finalizer", func() {
+		It("should keep the object until the finalizer is removed", func() {
+			pod := createDummyPod()
+			pod.ObjectMeta.Finalizers = []string{"test-finalizer"}
+			err := client.Create(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			objectKey := types.NamespacedName{Namespace: "default", Name: "pod1"}
+			err = client.Delete(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			podCopy := &corev1.Pod{}
+			err = client.Get(context.TODO(), objectKey, podCopy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podCopy.ObjectMeta.DeletionTimestamp).NotTo(BeNil())
+
+			err = client.Update(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.ObjectMeta.DeletionTimestamp).NotTo(BeNil())
+
+			pod.ObjectMeta.Finalizers = nil
+			err = client.Update(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Get(context.TODO(), objectKey, podCopy)
+			Expect(err).To(HaveOccurred())
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
 	When("updating an object", func() {
 		It("the object should be updated", func() {
 			pod := createDummyPod()
@@ -253,6 +303,134 @@ var _ = Describe("[mock client]", func() {
 		})
 	})
 
+	When("applying an object", func() {
+		It("should create the object if it does not exist", func() {
+			pod := createDummyPod()
+			err := client.Patch(context.TODO(), pod, ctrlClient.Apply)
+			Expect(err).NotTo(HaveOccurred())
+
+			podCopy := &corev1.Pod{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "pod1"}, podCopy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podCopy.ObjectMeta.Labels).To(Equal(map[string]string{"app": "app1"}))
+			Expect(podCopy.ObjectMeta.Generation).To(Equal(int64(1)))
+		})
+
+		It("should merge the applied fields into the existing object", func() {
+			pod := createDummyPod()
+			err := client.Create(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			pod.ObjectMeta.Annotations = map[string]string{"injected": "true"}
+			err = client.Update(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			appliedPod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "pod1",
+					Labels: map[string]string{
+						"app2": "app2",
+					},
+				},
+			}
+			err = client.Patch(context.TODO(), appliedPod, ctrlClient.Apply)
+			Expect(err).NotTo(HaveOccurred())
+
+			podCopy := &corev1.Pod{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "pod1"}, podCopy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podCopy.ObjectMeta.Labels).To(Equal(map[string]string{"app": "app1", "app2": "app2"}))
+			Expect(podCopy.ObjectMeta.Annotations).To(Equal(map[string]string{"injected": "true"}))
+			Expect(len(podCopy.Spec.Containers)).To(Equal(1))
+			Expect(podCopy.ObjectMeta.Generation).To(Equal(int64(1)))
+		})
+
+		It("should remove fields that are no longer applied", func() {
+			pod := createDummyPod()
+			pod.ObjectMeta.Labels["app2"] = "app2"
+			err := client.Patch(context.TODO(), pod, ctrlClient.Apply)
+			Expect(err).NotTo(HaveOccurred())
+
+			pod = createDummyPod()
+			err = client.Patch(context.TODO(), pod, ctrlClient.Apply)
+			Expect(err).NotTo(HaveOccurred())
+
+			podCopy := &corev1.Pod{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "pod1"}, podCopy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podCopy.ObjectMeta.Labels).To(Equal(map[string]string{"app": "app1"}))
+		})
+
+		It("should return an error for other patch types", func() {
+			pod := createDummyPod()
+			err := client.Create(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = client.Patch(context.TODO(), pod, ctrlClient.RawPatch(types.JSONPatchType, []byte("[]")))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("merge patching an object", func() {
+		It("should only change the patched fields", func() {
+			pod := createDummyPod()
+			pod.ObjectMeta.Annotations = map[string]string{"injected": "true", "outdated": "true"}
+			err := client.Create(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			original := pod.DeepCopy()
+			pod.ObjectMeta.Labels["app2"] = "app2"
+			delete(pod.ObjectMeta.Annotations, "outdated")
+			pod.Status.HostIP = "foo"
+			err = client.Patch(context.TODO(), pod, ctrlClient.MergeFrom(original))
+			Expect(err).NotTo(HaveOccurred())
+
+			podCopy := &corev1.Pod{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "pod1"}, podCopy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podCopy.ObjectMeta.Labels).To(Equal(map[string]string{"app": "app1", "app2": "app2"}))
+			Expect(podCopy.ObjectMeta.Annotations).To(Equal(map[string]string{"injected": "true"}))
+			Expect(len(podCopy.Spec.Containers)).To(Equal(1))
+			Expect(podCopy.Status.HostIP).To(Equal(""))
+			Expect(podCopy.ObjectMeta.Generation).To(Equal(int64(1)))
+		})
+
+		It("should return a conflict if the object has changed", func() {
+			pod := createDummyPod()
+			err := client.Create(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			original := pod.DeepCopy()
+			pod.ObjectMeta.Annotations = map[string]string{"injected": "true"}
+			err = client.Update(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			patched := original.DeepCopy()
+			patched.ObjectMeta.Labels["app2"] = "app2"
+			err = client.Patch(context.TODO(), patched, ctrlClient.MergeFromWithOptions(original, ctrlClient.MergeFromWithOptimisticLock{}))
+			Expect(k8serrors.IsConflict(err)).To(BeTrue())
+		})
+
+		It("should only change the status through the status client", func() {
+			pod := createDummyPod()
+			err := client.Create(context.TODO(), pod)
+			Expect(err).NotTo(HaveOccurred())
+
+			original := pod.DeepCopy()
+			pod.ObjectMeta.Labels["app2"] = "app2"
+			pod.Status.HostIP = "foo"
+			err = client.Status().Patch(context.TODO(), pod, ctrlClient.MergeFrom(original))
+			Expect(err).NotTo(HaveOccurred())
+
+			podCopy := &corev1.Pod{}
+			err = client.Get(context.TODO(), types.NamespacedName{Namespace: "default", Name: "pod1"}, podCopy)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podCopy.ObjectMeta.Labels).To(Equal(map[string]string{"app": "app1"}))
+			Expect(podCopy.Status.HostIP).To(Equal("foo"))
+		})
+	})
+
 	When("updating the object status", func() {
 		It("should update the object status", func() {
 			pod := createDummyPod()
@@ -21,82 +21,167 @@
 package fdbadminclient
 
 import (
+	"context"
+	"time"
+
 	fdbtypes "github.com/FoundationDB/fdb-kubernetes-operator/api/v1beta1"
 )
 
 // AdminClient describes an interface for running administrative commands on a
-// cluster
+// cluster.
+//
+// All methods that talk to the database take a context. Implementations must
+// stop waiting for the database once the context is cancelled or its deadline
+// is exceeded, so that an unresponsive cluster cannot block a reconciliation
+// indefinitely.
 type AdminClient interface {
 	// GetStatus gets the database's status
-	GetStatus() (*fdbtypes.FoundationDBStatus, error)
+	GetStatus(ctx context.Context) (*fdbtypes.FoundationDBStatus, error)
 
 	// ConfigureDatabase sets the database configuration
-	ConfigureDatabase(configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error
+	ConfigureDatabase(ctx context.Context, configuration fdbtypes.DatabaseConfiguration, newDatabase bool) error
 
 	// ExcludeInstances starts evacuating processes so that they can be removed
 	// from the database.
-	ExcludeInstances(addresses []fdbtypes.ProcessAddress) error
+	ExcludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error
 
 	// IncludeInstances removes processes from the exclusion list and allows
 	// them to take on roles again.
-	IncludeInstances(addresses []fdbtypes.ProcessAddress) error
+	IncludeInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error
 
 	// GetExclusions gets a list of the addresses currently excluded from the
 	// database.
-	GetExclusions() ([]fdbtypes.ProcessAddress, error)
+	GetExclusions(ctx context.Context) ([]fdbtypes.ProcessAddress, error)
+
+	// ExcludeLocalities starts evacuating all processes of the given
+	// localities.
+	ExcludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error
+
+	// IncludeLocalities removes the given localities from the exclusion list
+	// and allows their processes to take on roles again.
+	IncludeLocalities(ctx context.Context, localities []fdbtypes.LocalityExclusion) error
 
 	// CanSafelyRemove checks whether it is safe to remove processes from the
 	// cluster.
 	//
 	// The list returned by this method will be the addresses that are *not*
 	// safe to remove.
-	CanSafelyRemove(addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error)
+	CanSafelyRemove(ctx context.Context, addresses []fdbtypes.ProcessAddress) ([]fdbtypes.ProcessAddress, error)
 
 	// KillProcesses restarts processes
-	KillInstances(addresses []fdbtypes.ProcessAddress) error
+	KillInstances(ctx context.Context, addresses []fdbtypes.ProcessAddress) error
 
 	// ChangeCoordinators changes the coordinator set
-	ChangeCoordinators(addresses []fdbtypes.ProcessAddress) (string, error)
+	ChangeCoordinators(ctx context.Context, addresses []fdbtypes.ProcessAddress) (string, error)
 
 	// GetConnectionString fetches the latest connection string.
-	GetConnectionString() (string, error)
+	GetConnectionString(ctx context.Context) (string, error)
+
+	// GetLiveConnectionString reads the connection string that the database
+	// stores in the \xff/coordinators key.
+	GetLiveConnectionString(ctx context.Context) (string, error)
 
 	// VersionSupported reports whether we can support a cluster with a given
 	// version.
-	VersionSupported(version string) (bool, error)
+	VersionSupported(ctx context.Context, version string) (bool, error)
 
 	// GetProtocolVersion determines the protocol version that is used by a
 	// version of FDB.
-	GetProtocolVersion(version string) (string, error)
+	GetProtocolVersion(ctx context.Context, version string) (string, error)
+
+	// GetConnectedClients lists the client versions and protocol versions
+	// of the clients that are connected to the database.
+	GetConnectedClients(ctx context.Context) ([]fdbtypes.FoundationDBStatusSupportedVersion, error)
 
 	// StartBackup starts a new backup.
-	StartBackup(url string, snapshotPeriodSeconds int) error
+	StartBackup(ctx context.Context, url string, snapshotPeriodSeconds int, mode fdbtypes.BackupMode) error
 
 	// StopBackup stops a backup.
-	StopBackup(url string) error
+	StopBackup(ctx context.Context, url string) error
 
 	// PauseBackups pauses the backups.
-	PauseBackups() error
+	PauseBackups(ctx context.Context) error
 
 	// ResumeBackups resumes the backups.
-	ResumeBackups() error
+	ResumeBackups(ctx context.Context) error
 
 	// ModifyBackup modifies the configuration of the backup.
-	ModifyBackup(int) error
+	ModifyBackup(ctx context.Context, snapshotPeriodSeconds int) error
 
 	// GetBackupStatus gets the status of the current backup.
-	GetBackupStatus() (*fdbtypes.FoundationDBLiveBackupStatus, error)
+	GetBackupStatus(ctx context.Context) (*fdbtypes.FoundationDBLiveBackupStatus, error)
+
+	// ExpireBackup deletes old data from a backup based on the expiration
+	// policy.
+	ExpireBackup(ctx context.Context, url string, policy fdbtypes.BackupExpirationPolicy) error
 
-	// StartRestore starts a new restore.
-	StartRestore(url string, keyRanges []fdbtypes.FoundationDBKeyRange) error
+	// DescribeBackup describes the data in the backup at the URL.
+	DescribeBackup(ctx context.Context, url string) (*fdbtypes.FoundationDBBackupDescription, error)
+
+	// StartRestore starts a new restore. If the target version is nil, the
+	// restore restores to the latest restorable version in the backup.
+	StartRestore(ctx context.Context, url string, keyRanges []fdbtypes.FoundationDBKeyRange, targetVersion *int64) error
 
 	// GetRestoreStatus gets the status of the current restore.
-	GetRestoreStatus() (string, error)
+	GetRestoreStatus(ctx context.Context) (string, error)
+
+	// StartDR starts a new DR replication from the cluster with the provided
+	// connection string into this cluster.
+	StartDR(ctx context.Context, sourceConnectionString string, tag string) error
+
+	// SwitchDR switches the DR replication from the cluster with the provided
+	// connection string over to this cluster.
+	SwitchDR(ctx context.Context, sourceConnectionString string, tag string) error
+
+	// GetDRStatus gets the status of the DR replication from the cluster with
+	// the provided connection string into this cluster.
+	GetDRStatus(ctx context.Context, sourceConnectionString string, tag string) (*fdbtypes.FoundationDBLiveDRStatus, error)
 
 	// Close shuts down any resources for the client once it is no longer
 	// needed.
 	Close() error
 
 	// GetCoordinatorSet returns a set of the current coordinators.
-	GetCoordinatorSet() (map[string]struct{}, error)
+	GetCoordinatorSet(ctx context.Context) (map[string]struct{}, error)
+
+	// RunCommand runs an arbitrary fdbcli command and returns its output.
+	RunCommand(ctx context.Context, command string) (string, error)
+
+	// GetTagThrottles lists the tag throttles that are active in the
+	// database.
+	GetTagThrottles(ctx context.Context) ([]fdbtypes.TagThrottleStatus, error)
+
+	// ThrottleTag sets a manual throttle on a tag for the given duration.
+	ThrottleTag(ctx context.Context, throttle fdbtypes.TagThrottle, duration time.Duration) error
+
+	// UnthrottleTag removes the manual throttles on a tag.
+	UnthrottleTag(ctx context.Context, tag string) error
+
+	// GetTagQuotas reads the throughput quotas of the given tags. Tags
+	// without a quota are not part of the result.
+	GetTagQuotas(ctx context.Context, tags []string) ([]fdbtypes.TagQuota, error)
+
+	// SetTagQuota sets the throughput quota of a tag.
+	SetTagQuota(ctx context.Context, quota fdbtypes.TagQuota) error
+
+	// ClearTagQuota removes the throughput quota of a tag.
+	ClearTagQuota(ctx context.Context, tag string) error
+
+	// GetKnobs reads the given knobs from the configuration database. Knobs
+	// that are not set are not part of the result.
+	GetKnobs(ctx context.Context, names []string) (map[string]string, error)
+
+	// SetKnobs sets the given knobs in the configuration database.
+	SetKnobs(ctx context.Context, knobs map[string]string) error
+
+	// ClearKnobs removes the given knobs from the configuration database.
+	ClearKnobs(ctx context.Context, names []string) error
+
+	// GetConsistencyCheckSuspended determines whether the consistency check
+	// is suspended in the database.
+	GetConsistencyCheckSuspended(ctx context.Context) (bool, error)
+
+	// SetConsistencyCheckSuspended suspends or resumes the consistency check
+	// in the database.
+	SetConsistencyCheckSuspended(ctx context.Context, suspended bool) error
 }
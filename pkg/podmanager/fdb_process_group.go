@@ -89,16 +89,31 @@ func GetPublicIPSource(pod *corev1.Pod) (fdbtypes.PublicIPSource, error) {
 	return fdbtypes.PublicIPSource(source), nil
 }
 
-// GetPublicIPs returns the public IP of a pod.
+// GetPublicIPs returns the public IP of a pod, based on the public IP source
+// that the pod was created with. This returns an empty list if the IP is not
+// known yet.
 func GetPublicIPs(pod *corev1.Pod) []string {
 	if pod == nil {
 		return []string{}
 	}
 
-	source := pod.ObjectMeta.Annotations[fdbtypes.PublicIPSourceAnnotation]
-	if source == "" || source == string(fdbtypes.PublicIPSourcePod) {
+	var ip string
+	switch fdbtypes.PublicIPSource(pod.ObjectMeta.Annotations[fdbtypes.PublicIPSourceAnnotation]) {
+	case "", fdbtypes.PublicIPSourcePod:
 		return internal.GetPublicIPsForPod(pod)
+	case fdbtypes.PublicIPSourceNode:
+		ip = pod.Status.HostIP
+	case fdbtypes.PublicIPSourceService:
+		if internal.UsesNodePorts(pod) {
+			ip = pod.Status.HostIP
+		} else {
+			ip = pod.ObjectMeta.Annotations[fdbtypes.PublicIPAnnotation]
+		}
+	}
+
+	if ip == "" {
+		return []string{}
 	}
 
-	return []string{pod.ObjectMeta.Annotations[fdbtypes.PublicIPAnnotation]}
+	return []string{ip}
 }
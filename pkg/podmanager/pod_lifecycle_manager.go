@@ -107,7 +107,7 @@ func (manager StandardPodLifecycleManager) DeletePod(r client.Client, context ct
 
 // CanDeletePods checks whether it is safe to delete Pods.
 func (manager StandardPodLifecycleManager) CanDeletePods(adminClient fdbadminclient.AdminClient, context ctx.Context, cluster *fdbtypes.FoundationDBCluster) (bool, error) {
-	return internal.HasDesiredFaultTolerance(adminClient, cluster)
+	return internal.HasDesiredFaultTolerance(adminClient, context, cluster)
 }
 
 // UpdatePods updates a list of Pods to match the latest specs.
@@ -128,8 +128,11 @@ func (manager StandardPodLifecycleManager) UpdateImageVersion(r client.Client, c
 }
 
 // UpdateMetadata updates an Pod's metadata.
+//
+// This only patches the labels and annotations, so changes that other
+// controllers make to the Pod are preserved.
 func (manager StandardPodLifecycleManager) UpdateMetadata(r client.Client, context ctx.Context, cluster *fdbtypes.FoundationDBCluster, pod *corev1.Pod) error {
-	return r.Update(context, pod)
+	return internal.PatchMetadata(r, context, pod)
 }
 
 // PodIsUpdated determines whether a Pod is up to date.
@@ -21,11 +21,13 @@
 package setup
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"strings"
 	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -55,8 +57,10 @@ type Options struct {
 	LeaderElectionID        string
 	LogFile                 string
 	CliTimeout              int
+	BackupExpirationTimeout int
 	DeprecationOptions      internal.DeprecationOptions
 	MaxConcurrentReconciles int
+	MaxConcurrentCreations  int
 	CleanUpOldLogFile       bool
 	LogFileMinAge           time.Duration
 	LogFileMaxSize          int
@@ -64,6 +68,14 @@ type Options struct {
 	MaxNumberOfOldLogFiles  int
 	CompressOldFiles        bool
 	PrintVersion            bool
+	ClusterFileDir          string
+	ClusterFileMinAge       time.Duration
+	TransactionTimeout      time.Duration
+	StatusCacheTTL          time.Duration
+	ConnectionStringCheck   time.Duration
+	NetworkOptions          fdbclient.NetworkOptions
+	Knobs                   string
+	EnableConversionWebhook bool
 }
 
 // BindFlags will parse the given flagset for the operator option flags
@@ -78,7 +90,9 @@ func (o *Options) BindFlags(fs *flag.FlagSet) {
 	)
 	fs.StringVar(&o.LogFile, "log-file", "", "The path to a file to write logs to.")
 	fs.IntVar(&o.CliTimeout, "cli-timeout", 10, "The timeout to use for CLI commands.")
+	fs.IntVar(&o.BackupExpirationTimeout, "backup-expiration-timeout", 3600, "The timeout in seconds to use for expiring old data from a backup.")
 	fs.IntVar(&o.MaxConcurrentReconciles, "max-concurrent-reconciles", 1, "Defines the maximum number of concurrent reconciles for all controllers.")
+	fs.IntVar(&o.MaxConcurrentCreations, "max-concurrent-resource-creations", 10, "Defines the maximum number of pods and PVCs that the operator creates at the same time for a cluster.")
 	fs.BoolVar(&o.CleanUpOldLogFile, "cleanup-old-cli-logs", true, "Defines if the operator should delete old fdbcli log files.")
 	fs.DurationVar(&o.LogFileMinAge, "log-file-min-age", 5*time.Minute, "Defines the minimum age of fdbcli log files before removing when \"--cleanup-old-cli-logs\" is set.")
 	fs.IntVar(&o.LogFileMaxAge, "log-file-max-age", 28, "Defines the maximum age to retain old operator log file in number of days.")
@@ -86,6 +100,20 @@ func (o *Options) BindFlags(fs *flag.FlagSet) {
 	fs.IntVar(&o.MaxNumberOfOldLogFiles, "max-old-log-files", 3, "Defines the maximum number of old operator log files to retain.")
 	fs.BoolVar(&o.CompressOldFiles, "compress", false, "Defines whether the rotated log files should be compressed using gzip or not.")
 	fs.BoolVar(&o.PrintVersion, "version", false, "Prints the version of the operator and exits.")
+	fs.StringVar(&o.ClusterFileDir, "cluster-file-dir", path.Join(os.TempDir(), "fdb"), "Defines the directory where the operator writes the cluster files for the clusters it manages.")
+	fs.DurationVar(&o.ClusterFileMinAge, "cluster-file-min-age", 10*time.Minute, "Defines the minimum age of cluster files for deleted clusters before removing them.")
+	fs.DurationVar(&o.TransactionTimeout, "transaction-timeout", 5*time.Second, "Defines the timeout for transactions that the operator runs against the databases.")
+	fs.DurationVar(&o.StatusCacheTTL, "status-cache-ttl", 0, "Defines how long the operator reuses the status of a database between reconciliations. The status is fetched again after the operator changes the database configuration, excludes, restarts or removes processes, or deletes pods. Changes that are made outside of the operator are only seen once the cached status expires. A value of 0 disables the cache.")
+	fs.DurationVar(&o.ConnectionStringCheck, "connection-string-check-interval", 0, "Defines how often the operator checks if the coordinators of a cluster were changed outside of the operator. A value of 0 disables the periodic check.")
+	fs.StringVar(&o.NetworkOptions.TLSCertificateFile, "tls-certificate-file", "", "Defines the path to the client certificate for connecting to the databases.")
+	fs.StringVar(&o.NetworkOptions.TLSKeyFile, "tls-key-file", "", "Defines the path to the key for the client certificate.")
+	fs.StringVar(&o.NetworkOptions.TLSCAFile, "tls-ca-file", "", "Defines the path to the CA bundle for verifying the server certificates.")
+	fs.StringVar(&o.NetworkOptions.TLSVerifyPeers, "tls-verify-peers", "", "Defines the rules for verifying the server certificates.")
+	fs.StringVar(&o.NetworkOptions.TraceDir, "client-trace-dir", "", "Defines the directory for the client trace logs. This overrides the FDB_NETWORK_OPTION_TRACE_ENABLE environment variable.")
+	fs.StringVar(&o.NetworkOptions.TraceFormat, "client-trace-format", "", "Defines the format of the client trace logs. This overrides the FDB_NETWORK_OPTION_TRACE_FORMAT environment variable.")
+	fs.StringVar(&o.Knobs, "client-knobs", "", "Defines a comma-separated list of client knobs in the form name=value.")
+	fs.StringVar(&o.NetworkOptions.ExternalClientDirectory, "external-client-directory", "", "Defines the directory with the client libraries for the other versions of FoundationDB. This overrides the FDB_NETWORK_OPTION_EXTERNAL_CLIENT_DIRECTORY environment variable.")
+	fs.BoolVar(&o.EnableConversionWebhook, "enable-conversion-webhook", false, "Defines if the operator should serve the conversion webhook for the FoundationDBCluster resource. This requires the webhook to be configured in the CRD.")
 }
 
 // StartManager will start the FoundationDB operator manager.
@@ -98,6 +126,7 @@ func StartManager(
 	clusterReconciler *controllers.FoundationDBClusterReconciler,
 	backupReconciler *controllers.FoundationDBBackupReconciler,
 	restoreReconciler *controllers.FoundationDBRestoreReconciler,
+	drReconciler *controllers.FoundationDBDRReconciler,
 	logr *log.DelegatingLogger,
 	watchedObjects ...client.Object) (manager.Manager, *os.File) {
 	var logWriter io.Writer
@@ -130,6 +159,20 @@ func StartManager(
 	klog.SetLogger(logger)
 
 	fdbclient.DefaultCLITimeout = operatorOpts.CliTimeout
+	fdbclient.BackupExpirationTimeout = operatorOpts.BackupExpirationTimeout
+	fdbclient.ClusterFileDir = operatorOpts.ClusterFileDir
+	fdbclient.DefaultTransactionTimeout = operatorOpts.TransactionTimeout.Milliseconds()
+
+	networkOptions := operatorOpts.NetworkOptions
+	if operatorOpts.Knobs != "" {
+		networkOptions.Knobs = strings.Split(operatorOpts.Knobs, ",")
+	}
+
+	err := fdbclient.SetupNetworkOptions(networkOptions)
+	if err != nil {
+		setupLog.Error(err, "unable to set up the client network options")
+		os.Exit(1)
+	}
 
 	options := ctrl.Options{
 		Scheme:             scheme,
@@ -156,10 +199,13 @@ func StartManager(
 	}
 
 	if clusterReconciler != nil {
-		clusterReconciler.Client = mgr.GetClient()
-		clusterReconciler.Recorder = mgr.GetEventRecorderFor("foundationdbcluster-controller")
+		clusterReconciler.Recorder = controllers.NewAuditEventRecorder(mgr.GetEventRecorderFor("foundationdbcluster-controller"), mgr.GetClient(), mgr.GetAPIReader())
+		clusterReconciler.Client = controllers.NewAuditClient(mgr.GetClient(), clusterReconciler.Recorder)
 		clusterReconciler.DeprecationOptions = operatorOpts.DeprecationOptions
-		clusterReconciler.DatabaseClientProvider = fdbclient.NewDatabaseClientProvider()
+		clusterReconciler.ClientLibraryVersions = getClientLibraryVersions()
+		clusterReconciler.MaxConcurrentResourceCreations = operatorOpts.MaxConcurrentCreations
+		clusterReconciler.ConnectionStringCheckInterval = operatorOpts.ConnectionStringCheck
+		clusterReconciler.DatabaseClientProvider = controllers.NewCachingDatabaseClientProvider(fdbclient.NewDatabaseClientProvider(), operatorOpts.StatusCacheTTL)
 		clusterReconciler.Log = logr.WithName("controllers").WithName("FoundationDBCluster")
 
 		if err := clusterReconciler.SetupWithManager(mgr, operatorOpts.MaxConcurrentReconciles, watchedObjects...); err != nil {
@@ -169,6 +215,18 @@ func StartManager(
 
 		if operatorOpts.MetricsAddr != "0" {
 			controllers.InitCustomMetrics(clusterReconciler)
+
+			if err := mgr.AddMetricsExtraHandler(controllers.ClusterHealthPath, controllers.NewClusterHealthHandler(clusterReconciler)); err != nil {
+				setupLog.Error(err, "unable to add cluster health endpoint")
+				os.Exit(1)
+			}
+		}
+
+		if operatorOpts.EnableConversionWebhook {
+			if err := ctrl.NewWebhookManagedBy(mgr).For(&v1beta1.FoundationDBCluster{}).Complete(); err != nil {
+				setupLog.Error(err, "unable to create webhook", "webhook", "FoundationDBCluster")
+				os.Exit(1)
+			}
 		}
 	}
 
@@ -176,6 +234,7 @@ func StartManager(
 		backupReconciler.Client = mgr.GetClient()
 		backupReconciler.Recorder = mgr.GetEventRecorderFor("foundationdbbackup-controller")
 		backupReconciler.DatabaseClientProvider = fdbclient.NewDatabaseClientProvider()
+		backupReconciler.SecretReader = mgr.GetAPIReader()
 		backupReconciler.Log = logr.WithName("controllers").WithName("FoundationDBBackup")
 
 		if err := backupReconciler.SetupWithManager(mgr, operatorOpts.MaxConcurrentReconciles); err != nil {
@@ -196,6 +255,18 @@ func StartManager(
 		}
 	}
 
+	if drReconciler != nil {
+		drReconciler.Client = mgr.GetClient()
+		drReconciler.Recorder = mgr.GetEventRecorderFor("foundationdbdr-controller")
+		drReconciler.DatabaseClientProvider = fdbclient.NewDatabaseClientProvider()
+		drReconciler.Log = logr.WithName("controllers").WithName("FoundationDBDR")
+
+		if err := drReconciler.SetupWithManager(mgr, operatorOpts.MaxConcurrentReconciles); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "FoundationDBDR")
+			os.Exit(1)
+		}
+	}
+
 	if operatorOpts.CleanUpOldLogFile {
 		setupLog.V(1).Info("setup log file cleaner", "LogFileMinAge", operatorOpts.LogFileMinAge.String())
 		ticker := time.NewTicker(operatorOpts.LogFileMinAge)
@@ -207,11 +278,59 @@ func StartManager(
 		}()
 	}
 
+	if clusterReconciler != nil {
+		setupLog.V(1).Info("setup cluster file cleaner", "ClusterFileDir", operatorOpts.ClusterFileDir, "ClusterFileMinAge", operatorOpts.ClusterFileMinAge.String())
+		ticker := time.NewTicker(operatorOpts.ClusterFileMinAge)
+		go func() {
+			for {
+				<-ticker.C
+				cleanupClusterFiles(mgr.GetAPIReader(), namespace, operatorOpts)
+			}
+		}()
+	}
+
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("setup manager")
 	return mgr, file
 }
 
+// cleanupClusterFiles removes the cluster files for clusters that no longer
+// exist.
+func cleanupClusterFiles(reader client.Reader, namespace string, operatorOpts Options) {
+	clusters := &v1beta1.FoundationDBClusterList{}
+	err := reader.List(context.Background(), clusters, client.InNamespace(namespace))
+	if err != nil {
+		setupLog.Error(err, "unable to list clusters for the cluster file cleanup")
+		return
+	}
+
+	err = internal.CleanupClusterFiles(operatorOpts.ClusterFileDir, clusters.Items, operatorOpts.ClusterFileMinAge)
+	if err != nil {
+		setupLog.Error(err, "unable to clean up cluster files")
+	}
+}
+
+// getClientLibraryVersions returns the versions of the client libraries in
+// the external client directory that have matching binaries in the binary
+// directory. Errors are only logged, since the operator can still manage
+// clusters that run the version of the local client.
+func getClientLibraryVersions() []string {
+	directory := os.Getenv("FDB_NETWORK_OPTION_EXTERNAL_CLIENT_DIRECTORY")
+	if directory == "" {
+		return nil
+	}
+
+	binaryDirectory := os.Getenv("FDB_BINARY_DIR")
+	versions, err := fdbclient.GetClientLibraryVersions(directory, binaryDirectory)
+	if err != nil {
+		setupLog.Error(err, "unable to list the client libraries", "directory", directory, "binaryDirectory", binaryDirectory)
+		return nil
+	}
+
+	setupLog.Info("Found client libraries", "directory", directory, "binaryDirectory", binaryDirectory, "versions", versions)
+	return versions
+}
+
 // MoveFDBBinaries moves FDB binaries that are pulled from setup containers into
 // the correct locations.
 func moveFDBBinaries() error {